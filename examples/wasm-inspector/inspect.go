@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/common/lease_set"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+// kinds of structure the inspector can parse
+const (
+	KIND_ROUTER_INFO = "routerinfo"
+	KIND_LEASE_SET   = "leaseset"
+)
+
+var ErrUnknownKind = errors.New("unknown structure kind")
+
+// leaseSetReport is the JSON shown for a LeaseSet
+type leaseSetReport struct {
+	Destination   string        `json:"destination"`
+	SignatureType int           `json:"signature_type"`
+	Leases        []leaseReport `json:"leases"`
+	// Verified is false if the signature does not match, VerifyError says why
+	Verified    bool   `json:"verified"`
+	VerifyError string `json:"verify_error,omitempty"`
+}
+
+type leaseReport struct {
+	Gateway    string `json:"gateway"`
+	TunnelID   uint32 `json:"tunnel_id"`
+	Expiration int64  `json:"expiration"`
+}
+
+// decodeInput accepts I2P base64, as found in netDb exports and addressbooks,
+// and ignores surrounding whitespace and line breaks.
+func decodeInput(input string) ([]byte, error) {
+	input = strings.Join(strings.Fields(input), "")
+	if input == "" {
+		return nil, errors.New("no input")
+	}
+	return base64.DecodeString(input)
+}
+
+// inspect parses data as the given kind and returns a JSON description of it.
+func inspect(kind string, data []byte) ([]byte, error) {
+	switch kind {
+	case KIND_ROUTER_INFO:
+		return inspectRouterInfo(data)
+	case KIND_LEASE_SET:
+		return inspectLeaseSet(data)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+	}
+}
+
+func inspectRouterInfo(data []byte) ([]byte, error) {
+	info, n, err := router_info.ParseRouterInfo(data)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(data) {
+		return nil, fmt.Errorf("%d bytes of trailing data after RouterInfo", len(data)-n)
+	}
+	return json.MarshalIndent(info, "", "  ")
+}
+
+func inspectLeaseSet(data []byte) ([]byte, error) {
+	leaseSet := lease_set.LeaseSet(data)
+	destination, err := leaseSet.Destination()
+	if err != nil {
+		return nil, err
+	}
+	sigType, err := leaseSet.SignatureType()
+	if err != nil {
+		return nil, err
+	}
+	leases, err := leaseSet.Leases()
+	if err != nil {
+		return nil, err
+	}
+	report := leaseSetReport{
+		Destination:   destination.Base32Address(),
+		SignatureType: sigType,
+		Leases:        make([]leaseReport, 0, len(leases)),
+	}
+	for _, lease := range leases {
+		gateway := lease.TunnelGateway()
+		report.Leases = append(report.Leases, leaseReport{
+			Gateway:    gateway.Base64(),
+			TunnelID:   lease.TunnelID(),
+			Expiration: lease.Date().Time().UnixMilli(),
+		})
+	}
+	if err := leaseSet.Verify(); err != nil {
+		report.VerifyError = err.Error()
+	} else {
+		report.Verified = true
+	}
+	return json.MarshalIndent(report, "", "  ")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectRouterInfo(t *testing.T) {
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	info, err := router_info.NewRouterInfoBuilder(identity, keys.SigningPrivateKey).
+		SetOption("router.version", "0.9.64").
+		Build()
+	require.NoError(t, err)
+	data, err := info.Bytes()
+	require.NoError(t, err)
+
+	// as pasted into the page, wrapped over several lines
+	encoded := base64.EncodeToString(data)
+	input := encoded[:100] + "\n" + encoded[100:]
+	decoded, err := decodeInput(input)
+	require.NoError(t, err)
+
+	out, err := inspect(KIND_ROUTER_INFO, decoded)
+	require.NoError(t, err)
+	var report map[string]any
+	require.NoError(t, json.Unmarshal(out, &report))
+	hash := info.IdentHash()
+	assert.Equal(t, hash.Base64(), report["ident_hash"])
+	assert.Equal(t, "0.9.64", report["version"])
+
+	_, err = inspect(KIND_ROUTER_INFO, append(decoded, 0))
+	assert.Error(t, err)
+}
+
+func TestInspectRejectsBadInput(t *testing.T) {
+	_, err := inspect("destination", []byte{0})
+	assert.ErrorIs(t, err, ErrUnknownKind)
+
+	_, err = inspect(KIND_LEASE_SET, make([]byte, 100))
+	assert.Error(t, err)
+
+	_, err = decodeInput("  \n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+//go:build !js || !wasm
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Outside the browser the inspector reads a raw or base64 structure from a
+// file, or stdin if the file is "-", so the parsing can be checked natively.
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintf(os.Stderr, "usage: %s %s|%s FILE\n", os.Args[0], KIND_ROUTER_INFO, KIND_LEASE_SET)
+		os.Exit(2)
+	}
+	var data []byte
+	var err error
+	if os.Args[2] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(os.Args[2])
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if decoded, err := decodeInput(string(data)); err == nil {
+		data = decoded
+	}
+	out, err := inspect(os.Args[1], data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
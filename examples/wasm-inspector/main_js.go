@@ -0,0 +1,29 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"syscall/js"
+)
+
+// i2pInspect(kind, base64) returns {json: string} or {error: string}
+func jsInspect(this js.Value, args []js.Value) any {
+	if len(args) != 2 {
+		return map[string]any{"error": "usage: i2pInspect(kind, base64)"}
+	}
+	data, err := decodeInput(args[1].String())
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	out, err := inspect(args[0].String(), data)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+	return map[string]any{"json": string(out)}
+}
+
+func main() {
+	js.Global().Set("i2pInspect", js.FuncOf(jsInspect))
+	// keep the exported function alive
+	select {}
+}
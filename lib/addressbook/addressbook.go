@@ -0,0 +1,118 @@
+// Package addressbook maps human readable .i2p host names to Destinations.
+// The entries are kept in a Store, so embedders can back the address book
+// with their own persistence: FileStore keeps a hosts.txt file, SQLStore a
+// table in an SQLite database, and any other Store can be plugged in.
+package addressbook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+var (
+	ErrInvalidName = errors.New("invalid host name")
+	ErrNotFound    = errors.New("host name not found")
+)
+
+// suffix of every host name in the address book
+const HOST_SUFFIX = ".i2p"
+
+// Entry is a host name and the I2P base64 of its Destination, the way
+// hosts.txt stores them.
+type Entry struct {
+	Name        string
+	Destination string
+}
+
+// Store persists address book entries. Names are normalized by the
+// AddressBook before they reach the store. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Get returns the entry for name, and false if there is none
+	Get(name string) (Entry, bool, error)
+	// Put adds the entry, replacing any entry with the same name
+	Put(entry Entry) error
+	// Delete removes the entry for name, if there is one
+	Delete(name string) error
+	// Entries returns every entry, in no particular order
+	Entries() ([]Entry, error)
+}
+
+// AddressBook resolves host names through a Store.
+type AddressBook struct {
+	store Store
+}
+
+// NewAddressBook returns an address book keeping its entries in store.
+func NewAddressBook(store Store) *AddressBook {
+	return &AddressBook{store: store}
+}
+
+// Lookup returns the Destination of the host name.
+func (book *AddressBook) Lookup(name string) (*destination.Destination, error) {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+	entry, ok, err := book.store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	dest, _, err := destination.ParseDestination(entry.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if dest == nil {
+		return nil, fmt.Errorf("%w: %s is stored as a base32 address", destination.ErrInvalidDestination, name)
+	}
+	return dest, nil
+}
+
+// Add maps the host name to dest, replacing an earlier mapping.
+func (book *AddressBook) Add(name string, dest destination.Destination) error {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return err
+	}
+	log.WithFields(logrus.Fields{
+		"name": name,
+		"b32":  dest.Base32Address(),
+	}).Debug("Adding address book entry")
+	return book.store.Put(Entry{Name: name, Destination: dest.Base64()})
+}
+
+// Remove removes the host name from the address book.
+func (book *AddressBook) Remove(name string) error {
+	name, err := NormalizeName(name)
+	if err != nil {
+		return err
+	}
+	return book.store.Delete(name)
+}
+
+// Entries returns every entry of the address book.
+func (book *AddressBook) Entries() ([]Entry, error) {
+	return book.store.Entries()
+}
+
+// NormalizeName lowercases a host name and checks it is a .i2p name. Base32
+// addresses are not names, they carry their hash and need no address book.
+func NormalizeName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasSuffix(name, HOST_SUFFIX) || len(name) == len(HOST_SUFFIX) ||
+		strings.HasSuffix(name, data.B32_SUFFIX) || strings.ContainsAny(name, "=# \t\r\n") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,75 @@
+package addressbook
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDestination(t *testing.T) destination.Destination {
+	identity, _, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	return destination.Destination{KeysAndCert: identity.KeysAndCert}
+}
+
+func TestNormalizeName(t *testing.T) {
+	name, err := NormalizeName(" Example.I2P ")
+	require.NoError(t, err)
+	assert.Equal(t, "example.i2p", name)
+	for _, invalid := range []string{"", ".i2p", "example.com", "a=b.i2p", "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p"} {
+		_, err := NormalizeName(invalid)
+		assert.ErrorIs(t, err, ErrInvalidName, invalid)
+	}
+}
+
+func TestFileStoreAddressBook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	book := NewAddressBook(store)
+
+	dest := newTestDestination(t)
+	require.NoError(t, book.Add("Example.i2p", dest))
+	found, err := book.Lookup("example.i2p")
+	require.NoError(t, err)
+	assert.Equal(t, dest.Hash(), found.Hash())
+
+	// the entries survive reopening the file
+	reopened, err := OpenFileStore(path)
+	require.NoError(t, err)
+	found, err = NewAddressBook(reopened).Lookup("EXAMPLE.i2p")
+	require.NoError(t, err)
+	assert.Equal(t, dest.Hash(), found.Hash())
+
+	require.NoError(t, book.Remove("example.i2p"))
+	_, err = book.Lookup("example.i2p")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestOpenFileStoreSkipsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	dest := newTestDestination(t)
+	content := "# comment\n\nnot an entry\nexample.com=abc\nexample.i2p=" + dest.Base64() + "\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Name: "example.i2p", Destination: dest.Base64()}}, entries)
+}
+
+func TestOpenFileStoreMissingFile(t *testing.T) {
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "missing", "hosts.txt"))
+	require.NoError(t, err)
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	require.NoError(t, store.Delete("example.i2p"))
+}
@@ -0,0 +1,152 @@
+package addressbook
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileStore keeps the address book in a hosts.txt file, one name=destination
+// line per entry. The file is read when the store is opened and rewritten,
+// atomically, on every change.
+type FileStore struct {
+	mutex   sync.RWMutex
+	path    string
+	entries map[string]string
+}
+
+// OpenFileStore opens the hosts.txt file at path. A missing file is an empty
+// address book, it is created on the first change.
+func OpenFileStore(path string) (*FileStore, error) {
+	store := &FileStore{
+		path:    path,
+		entries: make(map[string]string),
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.WithField("path", path).Debug("No address book file, starting empty")
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	skipped := 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, dest, ok := strings.Cut(line, "=")
+		if !ok {
+			skipped++
+			continue
+		}
+		if name, err = NormalizeName(name); err != nil {
+			skipped++
+			continue
+		}
+		store.entries[name] = strings.TrimSpace(dest)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"path":    path,
+		"entries": len(store.entries),
+		"skipped": skipped,
+	}).Debug("Read address book file")
+	return store, nil
+}
+
+// Get returns the entry for name.
+func (store *FileStore) Get(name string) (Entry, bool, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	dest, ok := store.entries[name]
+	return Entry{Name: name, Destination: dest}, ok, nil
+}
+
+// Put adds the entry and rewrites the file.
+func (store *FileStore) Put(entry Entry) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	previous, existed := store.entries[entry.Name]
+	store.entries[entry.Name] = entry.Destination
+	if err := store.write(); err != nil {
+		if existed {
+			store.entries[entry.Name] = previous
+		} else {
+			delete(store.entries, entry.Name)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes the entry and rewrites the file.
+func (store *FileStore) Delete(name string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	previous, existed := store.entries[name]
+	if !existed {
+		return nil
+	}
+	delete(store.entries, name)
+	if err := store.write(); err != nil {
+		store.entries[name] = previous
+		return err
+	}
+	return nil
+}
+
+// Entries returns every entry, sorted by name.
+func (store *FileStore) Entries() ([]Entry, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	return store.sorted(), nil
+}
+
+func (store *FileStore) sorted() []Entry {
+	entries := make([]Entry, 0, len(store.entries))
+	for name, dest := range store.entries {
+		entries = append(entries, Entry{Name: name, Destination: dest})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// write replaces the file with the entries. Callers must hold the write
+// lock.
+func (store *FileStore) write() error {
+	var buf bytes.Buffer
+	for _, entry := range store.sorted() {
+		buf.WriteString(entry.Name)
+		buf.WriteByte('=')
+		buf.WriteString(entry.Destination)
+		buf.WriteByte('\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o700); err != nil {
+		return err
+	}
+	tmp := store.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		log.WithError(err).Error("Failed to write address book file")
+		return err
+	}
+	if err := os.Rename(tmp, store.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package addressbook
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// SQLStore keeps the address book in a table of an SQLite database. The
+// embedder opens the database with the SQLite driver of its choice and hands
+// it over; the store does not import a driver itself.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// OpenSQLStore creates the address book table in db if needed.
+func OpenSQLStore(db *sql.DB) (*SQLStore, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS addressbook (
+		name TEXT PRIMARY KEY,
+		destination TEXT NOT NULL
+	)`); err != nil {
+		log.WithError(err).Error("Failed to create address book table")
+		return nil, err
+	}
+	return &SQLStore{db: db}, nil
+}
+
+// Get returns the entry for name.
+func (store *SQLStore) Get(name string) (Entry, bool, error) {
+	entry := Entry{Name: name}
+	err := store.db.QueryRow(`SELECT destination FROM addressbook WHERE name = ?`, name).Scan(&entry.Destination)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entry, false, nil
+	}
+	if err != nil {
+		return entry, false, err
+	}
+	return entry, true, nil
+}
+
+// Put adds the entry, replacing any entry with the same name.
+func (store *SQLStore) Put(entry Entry) error {
+	_, err := store.db.Exec(`INSERT INTO addressbook (name, destination) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET destination = excluded.destination`,
+		entry.Name, entry.Destination)
+	return err
+}
+
+// Delete removes the entry for name.
+func (store *SQLStore) Delete(name string) error {
+	_, err := store.db.Exec(`DELETE FROM addressbook WHERE name = ?`, name)
+	return err
+}
+
+// Entries returns every entry, sorted by name.
+func (store *SQLStore) Entries() ([]Entry, error) {
+	rows, err := store.db.Query(`SELECT name, destination FROM addressbook ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		if err := rows.Scan(&entry.Name, &entry.Destination); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
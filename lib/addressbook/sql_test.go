@@ -0,0 +1,56 @@
+package addressbook
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/util/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLStoreAddressBook(t *testing.T) {
+	db, state := sqltest.Open()
+	defer db.Close()
+	store, err := OpenSQLStore(db)
+	require.NoError(t, err)
+	// opening again keeps the existing table
+	_, err = OpenSQLStore(db)
+	require.NoError(t, err)
+	book := NewAddressBook(store)
+
+	dest := newTestDestination(t)
+	require.NoError(t, book.Add("Example.i2p", dest))
+	found, err := book.Lookup("example.i2p")
+	require.NoError(t, err)
+	assert.Equal(t, dest.Hash(), found.Hash())
+
+	require.NoError(t, book.Remove("example.i2p"))
+	_, err = book.Lookup("example.i2p")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Zero(t, state.Rows("addressbook"))
+}
+
+func TestSQLStoreEntries(t *testing.T) {
+	db, state := sqltest.Open()
+	defer db.Close()
+	store, err := OpenSQLStore(db)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put(Entry{Name: "zzz.i2p", Destination: "first"}))
+	require.NoError(t, store.Put(Entry{Name: "aaa.i2p", Destination: "second"}))
+	// a second put for the same name replaces the entry
+	require.NoError(t, store.Put(Entry{Name: "zzz.i2p", Destination: "third"}))
+	assert.Equal(t, 2, state.Rows("addressbook"))
+
+	entries, err := store.Entries()
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Name: "aaa.i2p", Destination: "second"}, {Name: "zzz.i2p", Destination: "third"}}, entries)
+
+	entry, ok, err := store.Get("zzz.i2p")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "third", entry.Destination)
+	_, ok, err = store.Get("missing.i2p")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,259 @@
+// Package clientauth decides who may use the I2CP and SAM client protocols
+// and the management API, and which interfaces their listeners may bind to,
+// so that exposing the client ports on a LAN does not hand anonymous control
+// of the router to everyone on it.
+package clientauth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Protocol is a client protocol a user may be allowed to use.
+type Protocol string
+
+const (
+	PROTOCOL_I2CP       Protocol = "I2CP"
+	PROTOCOL_SAM        Protocol = "SAM"
+	PROTOCOL_MANAGEMENT Protocol = "management"
+)
+
+// Option names the credentials are sent in. SAM 3.2 clients send them in
+// HELLO, I2CP clients in the GetDate options.
+const (
+	SAM_USER_OPTION      = "USER"
+	SAM_PASSWORD_OPTION  = "PASSWORD"
+	I2CP_USER_OPTION     = "i2cp.username"
+	I2CP_PASSWORD_OPTION = "i2cp.password"
+)
+
+var (
+	ErrAuthRequired     = errors.New("client authentication required")
+	ErrAuthFailed       = errors.New("client authentication failed")
+	ErrNotAuthorized    = errors.New("client user may not use this protocol")
+	ErrBindNotAllowed   = errors.New("client listener may not bind to this address")
+	ErrInvalidBindRange = errors.New("invalid allowed bind address")
+)
+
+// dummyHash is compared against for unknown users, so that a login takes
+// as long whether or not the user exists
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("go-i2p"), bcrypt.MinCost)
+
+// Authenticator checks client logins and listener addresses against the
+// client configuration.
+type Authenticator struct {
+	required bool
+	users    map[string]*config.ClientUser
+	allowed  []*net.IPNet
+	// tlsConfig wraps listeners in TLS if set
+	tlsConfig *tls.Config
+}
+
+// NewAuthenticator returns an Authenticator for cfg. A nil cfg allows
+// anonymous clients on loopback only.
+func NewAuthenticator(cfg *config.ClientConfig) (*Authenticator, error) {
+	auth := &Authenticator{users: make(map[string]*config.ClientUser)}
+	if cfg == nil {
+		return auth, nil
+	}
+	auth.required = cfg.AuthRequired
+	for _, user := range cfg.Users {
+		if user == nil || user.Name == "" {
+			continue
+		}
+		auth.users[user.Name] = user
+	}
+	for _, addr := range cfg.AllowedBindAddresses {
+		network, err := parseBindRange(addr)
+		if err != nil {
+			return nil, err
+		}
+		auth.allowed = append(auth.allowed, network)
+	}
+	if auth.required && len(auth.users) == 0 {
+		log.Warn("Client authentication is required but no client users are configured")
+	}
+	return auth, nil
+}
+
+// Required reports whether clients have to log in.
+func (auth *Authenticator) Required() bool {
+	return auth.required
+}
+
+// Authenticate checks a login for protocol. An empty username is an
+// anonymous client, which is only accepted if authentication is not required.
+func (auth *Authenticator) Authenticate(protocol Protocol, username, password string) error {
+	if username == "" {
+		if auth.required {
+			return ErrAuthRequired
+		}
+		return nil
+	}
+	user, ok := auth.users[username]
+	hash := dummyHash
+	if ok {
+		hash = []byte(user.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		log.WithFields(logrus.Fields{
+			"protocol": protocol,
+			"user":     username,
+		}).Warn("Client authentication failed")
+		return ErrAuthFailed
+	}
+	if !allows(user, protocol) {
+		log.WithFields(logrus.Fields{
+			"protocol": protocol,
+			"user":     username,
+		}).Warn("Client user is not allowed to use protocol")
+		return ErrNotAuthorized
+	}
+	return nil
+}
+
+// AuthenticateSAM checks the USER and PASSWORD options of a SAM 3.2 HELLO.
+func (auth *Authenticator) AuthenticateSAM(options map[string]string) error {
+	return auth.Authenticate(PROTOCOL_SAM, options[SAM_USER_OPTION], options[SAM_PASSWORD_OPTION])
+}
+
+// AuthenticateI2CP checks the i2cp.username and i2cp.password options of an
+// I2CP GetDate message.
+func (auth *Authenticator) AuthenticateI2CP(options map[string]string) error {
+	return auth.Authenticate(PROTOCOL_I2CP, options[I2CP_USER_OPTION], options[I2CP_PASSWORD_OPTION])
+}
+
+// RequireHTTP wraps handler so requests log in with HTTP basic
+// authentication as a user allowed to use protocol. Without credentials a
+// request is anonymous, which is only let through if authentication is not
+// required.
+func (auth *Authenticator) RequireHTTP(protocol Protocol, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		username, password, _ := req.BasicAuth()
+		err := auth.Authenticate(protocol, username, password)
+		switch {
+		case err == nil:
+			handler.ServeHTTP(w, req)
+		case errors.Is(err, ErrNotAuthorized):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			w.Header().Set("WWW-Authenticate", `Basic realm="go-i2p"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		}
+	})
+}
+
+// CheckBind returns ErrBindNotAllowed unless a client listener may bind to
+// addr, given as host:port. Loopback addresses are always allowed, anything
+// else has to be covered by the allowed bind addresses. Binding to all
+// interfaces needs 0.0.0.0 or :: to be allowed explicitly, which also allows
+// every address of that family.
+func (auth *Authenticator) CheckBind(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if host == "" {
+		ip = net.IPv6unspecified
+	}
+	if ip == nil {
+		return fmt.Errorf("%w: %s is not an IP address", ErrBindNotAllowed, host)
+	}
+	if ip.IsLoopback() {
+		return nil
+	}
+	for _, network := range auth.allowed {
+		// allowing all interfaces allows each of them too
+		if network.IP.IsUnspecified() && (network.IP.To4() == nil) == (ip.To4() == nil) {
+			return nil
+		}
+		if !ip.IsUnspecified() && network.Contains(ip) {
+			return nil
+		}
+	}
+	log.WithField("address", addr).Warn("Refusing to bind client listener")
+	return fmt.Errorf("%w: %s", ErrBindNotAllowed, addr)
+}
+
+// SetTLSConfig makes Listen serve TLS with tlsConfig, nil for plain TCP.
+func (auth *Authenticator) SetTLSConfig(tlsConfig *tls.Config) {
+	auth.tlsConfig = tlsConfig
+}
+
+// TLSConfig returns the TLS configuration listeners are wrapped in, or nil.
+func (auth *Authenticator) TLSConfig() *tls.Config {
+	return auth.tlsConfig
+}
+
+// Listen opens a TCP listener for a client protocol or the management API
+// after checking the address with CheckBind, wrapped in TLS if a TLS
+// configuration is set.
+func (auth *Authenticator) Listen(addr string) (net.Listener, error) {
+	if err := auth.CheckBind(addr); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if auth.tlsConfig != nil {
+		log.WithField("address", listener.Addr()).Debug("Serving client listener over TLS")
+		return tls.NewListener(listener, auth.tlsConfig), nil
+	}
+	return listener, nil
+}
+
+// HashPassword returns the hash to store as a ClientUser PasswordHash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// allows reports whether user may use protocol
+func allows(user *config.ClientUser, protocol Protocol) bool {
+	switch protocol {
+	case PROTOCOL_I2CP:
+		return user.I2CP
+	case PROTOCOL_SAM:
+		return user.SAM
+	case PROTOCOL_MANAGEMENT:
+		return user.Management
+	}
+	return false
+}
+
+// parseBindRange parses an address or CIDR range
+func parseBindRange(addr string) (*net.IPNet, error) {
+	addr = strings.TrimSpace(addr)
+	if strings.Contains(addr, "/") {
+		_, network, err := net.ParseCIDR(addr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidBindRange, addr)
+		}
+		return network, nil
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidBindRange, addr)
+	}
+	bits := 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,101 @@
+package clientauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAuthenticator(t *testing.T, required bool, allowed ...string) *Authenticator {
+	hash, err := HashPassword("hunter2")
+	require.NoError(t, err)
+	auth, err := NewAuthenticator(&config.ClientConfig{
+		AuthRequired:         required,
+		AllowedBindAddresses: allowed,
+		Users: []*config.ClientUser{
+			{Name: "alice", PasswordHash: hash, I2CP: true, SAM: true, Management: true},
+			{Name: "bob", PasswordHash: hash, SAM: true},
+		},
+	})
+	require.NoError(t, err)
+	return auth
+}
+
+func TestAuthenticate(t *testing.T) {
+	auth := newTestAuthenticator(t, true)
+
+	assert.NoError(t, auth.Authenticate(PROTOCOL_I2CP, "alice", "hunter2"))
+	assert.NoError(t, auth.AuthenticateSAM(map[string]string{"USER": "bob", "PASSWORD": "hunter2"}))
+	assert.ErrorIs(t, auth.AuthenticateI2CP(map[string]string{"i2cp.username": "bob", "i2cp.password": "hunter2"}), ErrNotAuthorized)
+	assert.ErrorIs(t, auth.Authenticate(PROTOCOL_SAM, "alice", "wrong"), ErrAuthFailed)
+	assert.ErrorIs(t, auth.Authenticate(PROTOCOL_SAM, "mallory", "hunter2"), ErrAuthFailed)
+	assert.ErrorIs(t, auth.AuthenticateSAM(map[string]string{}), ErrAuthRequired)
+
+	anonymous := newTestAuthenticator(t, false)
+	assert.NoError(t, anonymous.AuthenticateI2CP(map[string]string{}))
+	// a client that does send credentials still has to get them right
+	assert.ErrorIs(t, anonymous.Authenticate(PROTOCOL_SAM, "alice", "wrong"), ErrAuthFailed)
+}
+
+func TestCheckBind(t *testing.T) {
+	loopbackOnly, err := NewAuthenticator(nil)
+	require.NoError(t, err)
+	assert.NoError(t, loopbackOnly.CheckBind("127.0.0.1:7654"))
+	assert.NoError(t, loopbackOnly.CheckBind("[::1]:7656"))
+	assert.NoError(t, loopbackOnly.CheckBind("localhost:7656"))
+	assert.ErrorIs(t, loopbackOnly.CheckBind("192.168.1.10:7654"), ErrBindNotAllowed)
+	assert.ErrorIs(t, loopbackOnly.CheckBind(":7654"), ErrBindNotAllowed)
+	assert.ErrorIs(t, loopbackOnly.CheckBind("0.0.0.0:7654"), ErrBindNotAllowed)
+
+	lan := newTestAuthenticator(t, true, "192.168.1.0/24", "10.0.0.5")
+	assert.NoError(t, lan.CheckBind("192.168.1.10:7654"))
+	assert.NoError(t, lan.CheckBind("10.0.0.5:7656"))
+	assert.ErrorIs(t, lan.CheckBind("10.0.0.6:7656"), ErrBindNotAllowed)
+	assert.ErrorIs(t, lan.CheckBind("0.0.0.0:7654"), ErrBindNotAllowed)
+
+	everywhere := newTestAuthenticator(t, true, "0.0.0.0")
+	assert.NoError(t, everywhere.CheckBind("0.0.0.0:7654"))
+	assert.NoError(t, everywhere.CheckBind("192.168.1.10:7654"))
+	assert.ErrorIs(t, everywhere.CheckBind("[::]:7654"), ErrBindNotAllowed)
+
+	_, err = NewAuthenticator(&config.ClientConfig{AllowedBindAddresses: []string{"lan"}})
+	assert.ErrorIs(t, err, ErrInvalidBindRange)
+}
+
+func TestListen(t *testing.T) {
+	auth, err := NewAuthenticator(nil)
+	require.NoError(t, err)
+	listener, err := auth.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	listener.Close()
+
+	_, err = auth.Listen("192.0.2.1:0")
+	assert.ErrorIs(t, err, ErrBindNotAllowed)
+}
+
+func TestRequireHTTP(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	serve := func(auth *Authenticator, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/workers", nil)
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+		recorder := httptest.NewRecorder()
+		auth.RequireHTTP(PROTOCOL_MANAGEMENT, ok).ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	auth := newTestAuthenticator(t, true)
+	assert.Equal(t, http.StatusOK, serve(auth, "alice", "hunter2").Code)
+	assert.Equal(t, http.StatusForbidden, serve(auth, "bob", "hunter2").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(auth, "alice", "wrong").Code)
+	anonymous := serve(auth, "", "")
+	assert.Equal(t, http.StatusUnauthorized, anonymous.Code)
+	assert.NotEmpty(t, anonymous.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(t, http.StatusOK, serve(newTestAuthenticator(t, false), "", "").Code)
+}
@@ -17,6 +17,20 @@ import (
 
 var log = logger.GetGoI2PLogger()
 
+var (
+	ErrCertificateEmpty    = errors.New("error parsing certificate: certificate is empty")
+	ErrCertificateTooShort = errors.New("error parsing certificate: certificate is too short")
+	// ErrCertificateDataShort is the warning ReadCertificate returns along
+	// with a certificate whose payload is cut short; ReadCertificateStrict
+	// rejects it with ErrCertificateTruncated instead
+	ErrCertificateDataShort       = errors.New("certificate parsing warning: certificate data is shorter than specified by length")
+	ErrInvalidCertificateType     = errors.New("invalid certificate type")
+	ErrCertificatePayloadTooLong  = errors.New("payload too long")
+	ErrNullCertificatePayload     = errors.New("NULL certificates must have empty payload")
+	ErrUnexpectedCertificateType  = errors.New("unexpected certificate type")
+	ErrCertificatePayloadTooShort = errors.New("certificate payload too short")
+)
+
 // Certificate Types
 const (
 	CERT_NULL = iota
@@ -74,36 +88,56 @@ type Certificate struct {
 
 // RawBytes returns the entire certificate in []byte form, includes excess payload data.
 func (c *Certificate) RawBytes() []byte {
-	bytes := c.kind.Bytes()
+	// kind may be a slice of the buffer the certificate was read from, so it
+	// must not be appended to
+	bytes := append([]byte(nil), c.kind.Bytes()...)
 	bytes = append(bytes, c.len.Bytes()...)
 	bytes = append(bytes, c.payload...)
-	log.WithFields(logrus.Fields{
-		"raw_bytes_length": len(bytes),
-	}).Debug("Generated raw bytes for certificate")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"raw_bytes_length": len(bytes),
+		}).Trace("Generated raw bytes for certificate")
+	}
 	return bytes
 }
 
+// Clone returns a copy of the Certificate which does not alias the buffer it
+// was read from.
+func (c *Certificate) Clone() Certificate {
+	return Certificate{
+		kind:    c.kind.Clone(),
+		len:     c.len.Clone(),
+		payload: append([]byte(nil), c.payload...),
+	}
+}
+
 // ExcessBytes returns the excess bytes in a certificate found after the specified payload length.
 func (c *Certificate) ExcessBytes() []byte {
 	if len(c.payload) >= c.len.Int() {
 		excess := c.payload[c.len.Int():]
-		log.WithFields(logrus.Fields{
-			"excess_bytes_length": len(excess),
-		}).Debug("Found excess bytes in certificate")
+		if log.TraceEnabled() {
+			log.WithFields(logrus.Fields{
+				"excess_bytes_length": len(excess),
+			}).Trace("Found excess bytes in certificate")
+		}
 		return excess
 	}
-	log.Debug("No excess bytes found in certificate")
+	if log.TraceEnabled() {
+		log.Trace("No excess bytes found in certificate")
+	}
 	return nil
 }
 
 // Bytes returns the entire certificate in []byte form, trims payload to specified length.
 func (c *Certificate) Bytes() []byte {
-	bytes := c.kind.Bytes()
+	bytes := append([]byte(nil), c.kind.Bytes()...)
 	bytes = append(bytes, c.len.Bytes()...)
 	bytes = append(bytes, c.Data()...)
-	log.WithFields(logrus.Fields{
-		"bytes_length": len(bytes),
-	}).Debug("Generated bytes for certificate")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"bytes_length": len(bytes),
+		}).Trace("Generated bytes for certificate")
+	}
 	return bytes
 }
 
@@ -115,18 +149,22 @@ func (c *Certificate) length() (cert_len int) {
 // Type returns the Certificate type specified in the first byte of the Certificate,
 func (c *Certificate) Type() (cert_type int) {
 	cert_type = c.kind.Int()
-	log.WithFields(logrus.Fields{
-		"cert_type": cert_type,
-	}).Debug("Retrieved certificate type")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"cert_type": cert_type,
+		}).Trace("Retrieved certificate type")
+	}
 	return
 }
 
 // Length returns the payload length of a Certificate.
 func (c *Certificate) Length() (length int) {
 	length = c.len.Int()
-	log.WithFields(logrus.Fields{
-		"length": length,
-	}).Debug("Retrieved certificate length")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"length": length,
+		}).Trace("Retrieved certificate length")
+	}
 	return
 }
 
@@ -139,9 +177,11 @@ func (c *Certificate) Data() (data []byte) {
 	} else {
 		data = c.payload[0:lastElement]
 	}
-	log.WithFields(logrus.Fields{
-		"data_length": len(data),
-	}).Debug("Retrieved certificate data")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"data_length": len(data),
+		}).Trace("Retrieved certificate data")
+	}
 	return
 }
 
@@ -158,7 +198,7 @@ func readCertificate(data []byte) (certificate Certificate, err error) {
 			"certificate_bytes_length": len(data),
 			"reason":                   "too short (len < CERT_MIN_SIZE)" + fmt.Sprintf("%d", certificate.kind.Int()),
 		}).Error("invalid certificate, empty")
-		err = fmt.Errorf("error parsing certificate: certificate is empty")
+		err = ErrCertificateEmpty
 		return
 	case 1, 2:
 		certificate.kind = Integer(data[0 : len(data)-1])
@@ -168,7 +208,7 @@ func readCertificate(data []byte) (certificate Certificate, err error) {
 			"certificate_bytes_length": len(data),
 			"reason":                   "too short (len < CERT_MIN_SIZE)" + fmt.Sprintf("%d", certificate.kind.Int()),
 		}).Error("invalid certificate, too short")
-		err = fmt.Errorf("error parsing certificate: certificate is too short")
+		err = ErrCertificateTooShort
 		return
 	default:
 		certificate.kind = Integer(data[0:1])
@@ -176,7 +216,7 @@ func readCertificate(data []byte) (certificate Certificate, err error) {
 		payloadLength := len(data) - CERT_MIN_SIZE
 		certificate.payload = data[CERT_MIN_SIZE:]
 		if certificate.len.Int() > len(data)-CERT_MIN_SIZE {
-			err = fmt.Errorf("certificate parsing warning: certificate data is shorter than specified by length")
+			err = ErrCertificateDataShort
 			log.WithFields(logrus.Fields{
 				"at":                         "(Certificate) NewCertificate",
 				"certificate_bytes_length":   certificate.len.Int(),
@@ -196,19 +236,32 @@ func readCertificate(data []byte) (certificate Certificate, err error) {
 	}
 }
 
-// ReadCertificate creates a Certificate from []byte and returns any ExcessBytes at the end of the input.
-// returns err if the certificate could not be read.
-func ReadCertificate(data []byte) (certificate Certificate, remainder []byte, err error) {
-	certificate, err = readCertificate(data)
-	if err != nil && err.Error() == "certificate parsing warning: certificate data is longer than specified by length" {
+// ParseCertificate reads a Certificate from the start of data and returns it
+// with the number of bytes consumed. The Certificate aliases data unless
+// CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseCertificate(data []byte, opts ...ParseOption) (Certificate, int, error) {
+	certificate, err := readCertificate(ParseBuffer(data, opts...))
+	if errors.Is(err, ErrCertificateExcessBytes) {
 		log.Warn("Certificate data longer than specified length")
 		err = nil
 	}
-	remainder = certificate.ExcessBytes()
+	remainder := certificate.ExcessBytes()
 	log.WithFields(logrus.Fields{
 		"remainder_length": len(remainder),
 	}).Debug("Read certificate and extracted remainder")
-	return
+	return certificate, len(data) - len(remainder), err
+}
+
+// ReadCertificate creates a Certificate from []byte and returns any ExcessBytes at the end of the input.
+// returns err if the certificate could not be read. The Certificate aliases
+// data unless CopyOnParse is given.
+//
+// Deprecated: use ParseCertificate.
+func ReadCertificate(data []byte, opts ...ParseOption) (Certificate, []byte, error) {
+	certificate, _, err := ParseCertificate(data, opts...)
+	return certificate, certificate.ExcessBytes(), err
 }
 
 // NewCertificate creates a new Certificate with default NULL type
@@ -222,15 +275,15 @@ func NewCertificate() *Certificate {
 
 func NewCertificateDeux(certType int, payload []byte) (*Certificate, error) {
 	if certType < 0 || certType > 255 {
-		return nil, fmt.Errorf("invalid certificate type: %d", certType)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCertificateType, certType)
 	}
 	certTypeByte := byte(certType)
 
 	if len(payload) > 65535 {
-		return nil, fmt.Errorf("payload too long: %d bytes", len(payload))
+		return nil, fmt.Errorf("%w: %d bytes", ErrCertificatePayloadTooLong, len(payload))
 	}
 
-	_len, err := NewIntegerFromInt(len(payload), 2)
+	_len, err := NewIntegerFromIntChecked(len(payload), 2)
 	if err != nil {
 		panic(err)
 	}
@@ -255,14 +308,20 @@ func NewCertificateWithType(certType uint8, payload []byte) (*Certificate, error
 	case CERT_NULL, CERT_HASHCASH, CERT_HIDDEN, CERT_SIGNED, CERT_MULTIPLE, CERT_KEY:
 		// Valid type
 	default:
-		return nil, fmt.Errorf("invalid certificate type: %d", certType)
+		return nil, fmt.Errorf("%w: %d", ErrInvalidCertificateType, certType)
 	}
 
 	// For NULL certificates, payload should be empty
 	if certType == CERT_NULL && len(payload) > 0 {
-		return nil, errors.New("NULL certificates must have empty payload")
+		return nil, ErrNullCertificatePayload
+	}
+	if len(payload) > 0xffff {
+		return nil, fmt.Errorf("%w: %d bytes do not fit the length field", ErrCertificatePayloadTooLong, len(payload))
+	}
+	length, err := NewIntegerFromIntChecked(len(payload), 2)
+	if err != nil {
+		return nil, err
 	}
-	length, _ := NewIntegerFromInt(len(payload), 2)
 
 	cert := &Certificate{
 		kind:    Integer([]byte{certType}),
@@ -280,11 +339,24 @@ func NewCertificateWithType(certType uint8, payload []byte) (*Certificate, error
 
 func GetSignatureTypeFromCertificate(cert Certificate) (int, error) {
 	if cert.Type() != CERT_KEY {
-		return 0, fmt.Errorf("unexpected certificate type: %d", cert.Type())
+		return 0, fmt.Errorf("%w: %d", ErrUnexpectedCertificateType, cert.Type())
 	}
 	if len(cert.payload) < 2 {
-		return 0, fmt.Errorf("certificate payload too short to contain signature type")
+		return 0, fmt.Errorf("%w to contain signature type", ErrCertificatePayloadTooShort)
 	}
 	sigType := int(binary.BigEndian.Uint16(cert.payload[0:2]))
 	return sigType, nil
 }
+
+// GetCryptoTypeFromCertificate returns the crypto public key type of a key
+// certificate, which follows the signing key type in its payload.
+func GetCryptoTypeFromCertificate(cert Certificate) (int, error) {
+	if cert.Type() != CERT_KEY {
+		return 0, fmt.Errorf("%w: %d", ErrUnexpectedCertificateType, cert.Type())
+	}
+	if len(cert.payload) < 4 {
+		return 0, fmt.Errorf("%w to contain crypto type", ErrCertificatePayloadTooShort)
+	}
+	cryptoType := int(binary.BigEndian.Uint16(cert.payload[2:4]))
+	return cryptoType, nil
+}
@@ -0,0 +1,23 @@
+package certificate
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// ReadCertificateFrom reads a Certificate off r, reading exactly its bytes.
+func ReadCertificateFrom(r io.Reader) (certificate Certificate, err error) {
+	data := make([]byte, CERT_MIN_SIZE)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return
+	}
+	data = append(data, make([]byte, binary.BigEndian.Uint16(data[1:3]))...)
+	if _, err = io.ReadFull(r, data[CERT_MIN_SIZE:]); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	certificate, _, err = ParseCertificate(data)
+	return
+}
@@ -0,0 +1,24 @@
+package certificate
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCertificateFrom(t *testing.T) {
+	stream := bytes.NewReader([]byte{CERT_KEY, 0x00, 0x02, 0xaa, 0xbb, 0xcc})
+	certificate, err := ReadCertificateFrom(stream)
+	require.NoError(t, err)
+	assert.Equal(t, CERT_KEY, certificate.Type())
+	assert.Equal(t, []byte{0xaa, 0xbb}, certificate.Data())
+	assert.Equal(t, 1, stream.Len())
+
+	_, err = ReadCertificateFrom(bytes.NewReader([]byte{CERT_KEY, 0x00, 0x02, 0xaa}))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = ReadCertificateFrom(bytes.NewReader([]byte{CERT_NULL}))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
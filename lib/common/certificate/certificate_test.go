@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/go-i2p/go-i2p/lib/common/data"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +40,7 @@ func TestCertificateLengthErrWhenTooShort(t *testing.T) {
 	assert.Equal(cert_len, 0, "certificate.Length() did not return zero length for missing length data")
 	if assert.NotNil(err) {
 		assert.Equal("error parsing certificate: certificate is too short", err.Error(), "correct error message should be returned")
+		assert.ErrorIs(err, ErrCertificateTooShort)
 	}
 }
 
@@ -367,3 +369,61 @@ func TestCertificateSerializationDeserializationMaxPayload(t *testing.T) {
 	assert.Equal(originalCert.Length(), deserializedCert.Length(), "Certificate lengths should match")
 	assert.True(bytes.Equal(originalCert.Data(), deserializedCert.Data()), "Certificate payloads should match")
 }
+
+func TestGetCryptoTypeFromCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	cert, err := readCertificate([]byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04})
+	assert.Nil(err)
+	cryptoType, err := GetCryptoTypeFromCertificate(cert)
+	assert.Nil(err)
+	assert.Equal(4, cryptoType)
+
+	cert, _ = readCertificate([]byte{0x05, 0x00, 0x02, 0x00, 0x07})
+	_, err = GetCryptoTypeFromCertificate(cert)
+	assert.NotNil(err)
+
+	cert, _ = readCertificate([]byte{0x00, 0x00, 0x00})
+	_, err = GetCryptoTypeFromCertificate(cert)
+	assert.NotNil(err)
+}
+
+func TestCertificateDoesNotWriteToItsBuffer(t *testing.T) {
+	assert := assert.New(t)
+
+	buffer := []byte{0x05, 0x00, 0x02, 0xaa, 0xbb, 0xcc}
+	certificate, _, err := ReadCertificate(buffer)
+	assert.Nil(err)
+	clone := certificate.Clone()
+
+	// the transport reuses the buffer before the certificate is serialized
+	copy(buffer, []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06})
+	certificate.Bytes()
+	certificate.RawBytes()
+	assert.Equal([]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, buffer)
+	assert.Equal([]byte{0x05, 0x00, 0x02, 0xaa, 0xbb}, clone.Bytes())
+
+	copied, _, err := ReadCertificate([]byte{0x05, 0x00, 0x01, 0xaa}, data.CopyOnParse())
+	assert.Nil(err)
+	assert.Equal([]byte{0xaa}, copied.Data())
+}
+
+func TestParseCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	raw := []byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04, 0xaa, 0xbb}
+	certificate, consumed, err := ParseCertificate(raw)
+	assert.Nil(err)
+	assert.Equal(7, consumed)
+	assert.Equal(CERT_KEY, certificate.Type())
+	assert.Equal([]byte{0xaa, 0xbb}, raw[consumed:])
+
+	certificate, consumed, err = ParseCertificate(raw[:7], data.CopyOnParse())
+	assert.Nil(err)
+	assert.Equal(7, consumed)
+	assert.Equal(4, certificate.Length())
+
+	_, consumed, err = ParseCertificate(raw[:5])
+	assert.NotNil(err, "a payload shorter than its length should be reported")
+	assert.Empty(raw[:5][consumed:])
+}
@@ -0,0 +1,109 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNotMultipleCertificate = errors.New("certificate is not a MULTIPLE certificate")
+	ErrMultiplePayloadTooLong = errors.New("MULTIPLE certificate payload is longer than 65535 bytes")
+)
+
+// MultipleCertificate is the parsed payload of a CERT_MULTIPLE Certificate,
+// which is the contained certificates one after another.
+type MultipleCertificate struct {
+	certificates []Certificate
+}
+
+// ReadMultipleCertificate splits the payload of a CERT_MULTIPLE Certificate
+// into the certificates it contains. Contained MULTIPLE certificates are not
+// split further.
+func ReadMultipleCertificate(cert Certificate) (*MultipleCertificate, error) {
+	if cert.Type() != CERT_MULTIPLE {
+		return nil, fmt.Errorf("%w: type %d", ErrNotMultipleCertificate, cert.Type())
+	}
+	payload := cert.Data()
+	if len(payload) < cert.Length() {
+		return nil, fmt.Errorf("error parsing MULTIPLE certificate: %w", ErrCertificateTruncated)
+	}
+	multiple := &MultipleCertificate{}
+	for len(payload) > 0 {
+		contained, n, err := ParseCertificate(payload)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"at":     "ReadMultipleCertificate",
+				"index":  len(multiple.certificates),
+				"reason": err.Error(),
+			}).Error("error parsing MULTIPLE certificate")
+			return nil, fmt.Errorf("error parsing MULTIPLE certificate %d: %w", len(multiple.certificates), err)
+		}
+		// keep a copy without the rest of the payload as excess bytes
+		single, err := NewCertificateDeux(contained.Type(), contained.Data())
+		if err != nil {
+			return nil, err
+		}
+		multiple.certificates = append(multiple.certificates, *single)
+		payload = payload[n:]
+	}
+	log.WithField("count", len(multiple.certificates)).Debug("Read MULTIPLE certificate")
+	return multiple, nil
+}
+
+// NewMultipleCertificate returns a MultipleCertificate holding certs.
+func NewMultipleCertificate(certs ...Certificate) (*MultipleCertificate, error) {
+	multiple := &MultipleCertificate{certificates: append([]Certificate{}, certs...)}
+	if len(multiple.payload()) > 65535 {
+		return nil, ErrMultiplePayloadTooLong
+	}
+	return multiple, nil
+}
+
+// Len returns the number of contained certificates.
+func (multiple *MultipleCertificate) Len() int {
+	return len(multiple.certificates)
+}
+
+// Certificates returns the contained certificates in order.
+func (multiple *MultipleCertificate) Certificates() []Certificate {
+	return append([]Certificate{}, multiple.certificates...)
+}
+
+// Find returns the first contained certificate of certType.
+func (multiple *MultipleCertificate) Find(certType int) (Certificate, bool) {
+	for _, cert := range multiple.certificates {
+		if cert.Type() == certType {
+			return cert, true
+		}
+	}
+	return Certificate{}, false
+}
+
+// Certificate serializes the contained certificates back into a
+// CERT_MULTIPLE Certificate.
+func (multiple *MultipleCertificate) Certificate() (*Certificate, error) {
+	payload := multiple.payload()
+	if len(payload) > 65535 {
+		return nil, ErrMultiplePayloadTooLong
+	}
+	return NewCertificateWithType(CERT_MULTIPLE, payload)
+}
+
+// Bytes returns the CERT_MULTIPLE Certificate in []byte form.
+func (multiple *MultipleCertificate) Bytes() ([]byte, error) {
+	cert, err := multiple.Certificate()
+	if err != nil {
+		return nil, err
+	}
+	return cert.Bytes(), nil
+}
+
+func (multiple *MultipleCertificate) payload() []byte {
+	var payload []byte
+	for _, cert := range multiple.certificates {
+		payload = append(payload, cert.Bytes()...)
+	}
+	return payload
+}
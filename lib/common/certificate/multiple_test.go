@@ -0,0 +1,77 @@
+package certificate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMultipleCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{
+		CERT_MULTIPLE, 0x00, 0x0e,
+		CERT_KEY, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04,
+		CERT_HIDDEN, 0x00, 0x00,
+		CERT_SIGNED, 0x00, 0x01, 0xaa,
+	}
+	cert, remainder, err := ReadCertificate(append(data, 0xff))
+	require.NoError(t, err)
+	assert.Equal([]byte{0xff}, remainder)
+
+	multiple, err := ReadMultipleCertificate(cert)
+	require.NoError(t, err)
+	require.Equal(t, 3, multiple.Len())
+	certs := multiple.Certificates()
+	assert.Equal(CERT_KEY, certs[0].Type())
+	assert.Equal([]byte{0x00, 0x07, 0x00, 0x04}, certs[0].Data())
+	assert.Equal(CERT_HIDDEN, certs[1].Type())
+	assert.Empty(certs[1].Data())
+	assert.Equal(CERT_SIGNED, certs[2].Type())
+	assert.Empty(certs[2].ExcessBytes())
+
+	signed, ok := multiple.Find(CERT_SIGNED)
+	assert.True(ok)
+	assert.Equal([]byte{0xaa}, signed.Data())
+	_, ok = multiple.Find(CERT_HASHCASH)
+	assert.False(ok)
+
+	serialized, err := multiple.Bytes()
+	require.NoError(t, err)
+	assert.Equal(data, serialized)
+}
+
+func TestReadMultipleCertificateErrors(t *testing.T) {
+	cert, _, err := ReadCertificate([]byte{CERT_KEY, 0x00, 0x00})
+	require.NoError(t, err)
+	_, err = ReadMultipleCertificate(cert)
+	assert.ErrorIs(t, err, ErrNotMultipleCertificate)
+
+	// the second contained certificate claims more payload than there is
+	cert, _, err = ReadCertificate([]byte{CERT_MULTIPLE, 0x00, 0x06, CERT_HIDDEN, 0x00, 0x00, CERT_SIGNED, 0x00, 0x05})
+	require.NoError(t, err)
+	_, err = ReadMultipleCertificate(cert)
+	assert.Error(t, err)
+}
+
+func TestNewMultipleCertificate(t *testing.T) {
+	key, err := NewCertificateWithType(CERT_KEY, []byte{0x00, 0x07, 0x00, 0x04})
+	require.NoError(t, err)
+	null, err := NewCertificateWithType(CERT_NULL, nil)
+	require.NoError(t, err)
+	multiple, err := NewMultipleCertificate(*key, *null)
+	require.NoError(t, err)
+
+	cert, err := multiple.Certificate()
+	require.NoError(t, err)
+	assert.Equal(t, CERT_MULTIPLE, cert.Type())
+	parsed, err := ReadMultipleCertificate(*cert)
+	require.NoError(t, err)
+	assert.Equal(t, multiple.Certificates(), parsed.Certificates())
+
+	big, err := NewCertificateWithType(CERT_SIGNED, make([]byte, 40000))
+	require.NoError(t, err)
+	_, err = NewMultipleCertificate(*big, *big)
+	assert.ErrorIs(t, err, ErrMultiplePayloadTooLong)
+}
@@ -0,0 +1,55 @@
+package certificate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrCertificateTruncated   = errors.New("certificate payload shorter than its length")
+	ErrCertificateExcessBytes = errors.New("certificate payload longer than its length")
+)
+
+// LengthError reports a certificate whose payload does not match its length
+// field. It matches ErrCertificateTruncated or ErrCertificateExcessBytes
+// with errors.Is.
+type LengthError struct {
+	// Length is the payload length in the length field
+	Length int
+	// Available is the number of payload bytes present
+	Available int
+}
+
+func (err *LengthError) Error() string {
+	return fmt.Sprintf("%s: length %d, %d bytes", err.Unwrap(), err.Length, err.Available)
+}
+
+func (err *LengthError) Unwrap() error {
+	if err.Available < err.Length {
+		return ErrCertificateTruncated
+	}
+	return ErrCertificateExcessBytes
+}
+
+// ReadCertificateStrict reads a certificate which must fill data exactly.
+// Unlike ReadCertificate it does not return extra bytes as a remainder or
+// accept a short payload, both are a *LengthError. This is meant for
+// certificates received from other routers whose size is known, such as in a
+// DatabaseStore, where either is a sign of a malformed entry.
+func ReadCertificateStrict(data []byte) (certificate Certificate, err error) {
+	certificate, err = readCertificate(data)
+	if len(data) < CERT_MIN_SIZE {
+		return
+	}
+	if available := len(data) - CERT_MIN_SIZE; available != certificate.Length() {
+		err = &LengthError{Length: certificate.Length(), Available: available}
+		log.WithFields(logrus.Fields{
+			"type":      certificate.Type(),
+			"length":    certificate.Length(),
+			"available": available,
+		}).Warn("Rejecting certificate with mismatched length")
+	}
+	return
+}
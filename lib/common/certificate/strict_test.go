@@ -0,0 +1,30 @@
+package certificate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCertificateStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	certificate, err := ReadCertificateStrict([]byte{0x05, 0x00, 0x02, 0xaa, 0xbb})
+	assert.NoError(err)
+	assert.Equal([]byte{0xaa, 0xbb}, certificate.Data())
+
+	_, err = ReadCertificateStrict([]byte{0x05, 0x00, 0x04, 0xaa, 0xbb})
+	assert.ErrorIs(err, ErrCertificateTruncated)
+	var lengthErr *LengthError
+	if assert.True(errors.As(err, &lengthErr)) {
+		assert.Equal(4, lengthErr.Length)
+		assert.Equal(2, lengthErr.Available)
+	}
+
+	_, err = ReadCertificateStrict([]byte{0x00, 0x00, 0x00, 0xff})
+	assert.ErrorIs(err, ErrCertificateExcessBytes)
+
+	_, err = ReadCertificateStrict([]byte{0x00, 0x00})
+	assert.Error(err)
+}
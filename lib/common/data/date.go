@@ -2,7 +2,7 @@
 package data
 
 import (
-	"errors"
+	"encoding/binary"
 	"time"
 
 	"github.com/go-i2p/go-i2p/lib/util/logger"
@@ -50,14 +50,48 @@ func (date Date) Time() (date_time time.Time) {
 	return
 }
 
-// ReadDate creates a Date from []byte using the first DATE_SIZE bytes.
-// Any data after DATE_SIZE is returned as a remainder.
-func ReadDate(data []byte) (date Date, remainder []byte, err error) {
+// DateFromTime returns t as a Date, truncated to the millisecond. The zero
+// time and times before 1970 give the undefined zero Date.
+func DateFromTime(t time.Time) (date Date) {
+	if t.IsZero() || t.UnixMilli() < 0 {
+		return
+	}
+	binary.BigEndian.PutUint64(date[:], uint64(t.UnixMilli()))
+	return
+}
+
+// millis returns the date in milliseconds since the beginning of unix time
+func (date Date) millis() uint64 {
+	return binary.BigEndian.Uint64(date[:])
+}
+
+// IsZero reports whether the date is 0, undefined or null.
+func (date Date) IsZero() bool {
+	return date.millis() == 0
+}
+
+// Add returns the date plus d.
+func (date Date) Add(d time.Duration) Date {
+	return DateFromTime(time.UnixMilli(int64(date.millis())).Add(d))
+}
+
+// Before reports whether date is before other.
+func (date Date) Before(other Date) bool {
+	return date.millis() < other.millis()
+}
+
+// After reports whether date is after other.
+func (date Date) After(other Date) bool {
+	return date.millis() > other.millis()
+}
+
+// readDate reads a Date, returning the data after it
+func readDate(data []byte) (date Date, remainder []byte, err error) {
 	if len(data) < 8 {
 		log.WithFields(logrus.Fields{
 			"data": data,
 		}).Error("ReadDate: data is too short")
-		err = errors.New("ReadDate: data is too short")
+		err = ErrDateTooShort
 		return
 	}
 	copy(date[:], data[:8])
@@ -69,10 +103,29 @@ func ReadDate(data []byte) (date Date, remainder []byte, err error) {
 	return
 }
 
+// ParseDate reads a Date from the first DATE_SIZE bytes of data and returns it
+// with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseDate(data []byte) (Date, int, error) {
+	value, remainder, err := readDate(data)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadDate creates a Date from []byte using the first DATE_SIZE bytes.
+// Any data after DATE_SIZE is returned as a remainder.
+//
+// Deprecated: use ParseDate.
+func ReadDate(data []byte) (Date, []byte, error) {
+	return readDate(data)
+}
+
 // NewDate creates a new Date from []byte using ReadDate.
 // Returns a pointer to Date unlike ReadDate.
+//
+// Deprecated: use ParseDate.
 func NewDate(data []byte) (date *Date, remainder []byte, err error) {
-	objdate, remainder, err := ReadDate(data)
+	objdate, remainder, err := readDate(data)
 	if err != nil {
 		log.WithError(err).Error("Failed to create new Date")
 		return nil, remainder, err
@@ -2,6 +2,7 @@ package data
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -14,3 +15,45 @@ func TestTimeFromMilliseconds(t *testing.T) {
 
 	assert.Equal(int64(86400), go_time.Unix(), "Date.Time() did not parse time in milliseconds")
 }
+
+func TestDateFromTime(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	date := DateFromTime(now)
+	assert.Equal(now.Truncate(time.Millisecond).UnixMilli(), date.Time().UnixMilli())
+	assert.False(date.IsZero())
+
+	assert.Equal(Date{0x00, 0x00, 0x00, 0x00, 0x05, 0x26, 0x5c, 0x00}, DateFromTime(time.Unix(86400, 0)))
+	assert.True(DateFromTime(time.Time{}).IsZero(), "the zero time is the undefined date")
+	assert.True(DateFromTime(time.Unix(-1, 0)).IsZero(), "dates cannot be before 1970")
+}
+
+func TestDateArithmetic(t *testing.T) {
+	assert := assert.New(t)
+
+	date := DateFromTime(time.Unix(86400, 0))
+	later := date.Add(10 * time.Minute)
+	assert.Equal(int64(86400+600), later.Time().Unix())
+	assert.True(date.Before(later))
+	assert.True(later.After(date))
+	assert.False(date.After(date))
+	assert.False(date.Before(date))
+	assert.Equal(date, later.Add(-10*time.Minute))
+	// sub-millisecond durations are truncated
+	assert.Equal(date, date.Add(time.Microsecond))
+}
+
+func TestParseDate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x00, 0x00, 0x00, 0x00, 0x05, 0x26, 0x5c, 0x00, 0xff}
+	date, consumed, err := ParseDate(data)
+	assert.Nil(err)
+	assert.Equal(DATE_SIZE, consumed)
+	assert.Equal(int64(86400), date.Time().Unix())
+
+	_, consumed, err = ParseDate(data[:DATE_SIZE-1])
+	assert.ErrorIs(err, ErrDateTooShort)
+	assert.Empty(data[:DATE_SIZE-1][consumed:])
+}
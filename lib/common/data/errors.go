@@ -11,6 +11,17 @@ var (
 	ErrDataTooLong           = errors.New("string parsing warning: string contains data beyond length")
 	ErrLengthMismatch        = errors.New("error reading I2P string, length does not match data")
 	ErrMappingLengthMismatch = errors.New("warning parsing mapping: mapping length exceeds provided data")
+	ErrIntegerTooShort       = errors.New("error parsing integer: not enough data")
+	ErrIntegerOverflow       = errors.New("error creating integer: value out of range")
+	ErrInvalidUTF8           = errors.New("error parsing string: invalid UTF-8")
+	ErrStringTooLong         = errors.New("cannot store that much data in I2P string")
+	ErrMappingTooShort       = errors.New("error parsing mapping: zero length")
+	ErrMappingEmpty          = errors.New("mapping contained no data")
+	ErrMappingExcessData     = errors.New("warning parsing mapping: data exists beyond length of mapping")
+	ErrMappingValues         = errors.New("error parsing mapping values")
+	ErrMappingExpectedEquals = errors.New("mapping format violation, expected =")
+	ErrMappingExpectedEnd    = errors.New("mapping format violation, expected ;")
+	ErrDateTooShort          = errors.New("ReadDate: data is too short")
 )
 
 // WrapErrors compiles a slice of errors and returns them wrapped together as a single error.
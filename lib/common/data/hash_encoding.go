@@ -0,0 +1,66 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/common/base32"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+)
+
+// B32_SUFFIX is the top level domain of base32 addresses.
+const B32_SUFFIX = ".b32.i2p"
+
+// HASH_BASE32_LENGTH is the length of a Hash in base32 without padding,
+// HASH_BASE64_LENGTH its length in I2P base64 with padding.
+const (
+	HASH_BASE32_LENGTH = 52
+	HASH_BASE64_LENGTH = 44
+)
+
+var ErrInvalidHash = errors.New("invalid hash encoding")
+
+// Base64 returns the hash in the I2P base64 alphabet, as used in netDb
+// filenames and router consoles.
+func (h Hash) Base64() string {
+	return base64.EncodeToString(h[:])
+}
+
+// Base32 returns the hash in lowercase base32 without padding.
+func (h Hash) Base32() string {
+	return strings.TrimRight(base32.EncodeToString(h[:]), "=")
+}
+
+// Base32Address returns the .b32.i2p address of the hash.
+func (h Hash) Base32Address() string {
+	return h.Base32() + B32_SUFFIX
+}
+
+// ParseHashBase64 parses a hash in the I2P base64 alphabet.
+func ParseHashBase64(s string) (h Hash, err error) {
+	if len(s) != HASH_BASE64_LENGTH {
+		return h, fmt.Errorf("%w: %q", ErrInvalidHash, s)
+	}
+	decoded, err := base64.DecodeString(s)
+	if err != nil || len(decoded) != len(h) {
+		return h, fmt.Errorf("%w: %q", ErrInvalidHash, s)
+	}
+	copy(h[:], decoded)
+	return h, nil
+}
+
+// ParseHashBase32 parses a hash in base32, with or without the .b32.i2p
+// suffix. Case is ignored.
+func ParseHashBase32(s string) (h Hash, err error) {
+	encoded := strings.TrimSuffix(strings.ToLower(s), B32_SUFFIX)
+	if len(encoded) != HASH_BASE32_LENGTH {
+		return h, fmt.Errorf("%w: %q", ErrInvalidHash, s)
+	}
+	decoded, err := base32.DecodeString(encoded + "====")
+	if err != nil || len(decoded) != len(h) {
+		return h, fmt.Errorf("%w: %q", ErrInvalidHash, s)
+	}
+	copy(h[:], decoded)
+	return h, nil
+}
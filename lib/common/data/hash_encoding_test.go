@@ -0,0 +1,42 @@
+package data
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashEncodingRoundTrip(t *testing.T) {
+	h := HashData([]byte("go-i2p"))
+
+	b64 := h.Base64()
+	assert.Len(t, b64, HASH_BASE64_LENGTH)
+	assert.NotContains(t, b64, "+")
+	assert.NotContains(t, b64, "/")
+	parsed, err := ParseHashBase64(b64)
+	require.NoError(t, err)
+	assert.Equal(t, h, parsed)
+
+	b32 := h.Base32()
+	assert.Len(t, b32, HASH_BASE32_LENGTH)
+	assert.Equal(t, b32+".b32.i2p", h.Base32Address())
+	for _, s := range []string{b32, h.Base32Address(), strings.ToUpper(h.Base32Address())} {
+		parsed, err = ParseHashBase32(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, h, parsed, s)
+	}
+}
+
+func TestParseHashRejectsInvalid(t *testing.T) {
+	h := HashData([]byte("go-i2p"))
+	for _, s := range []string{"", h.Base64()[1:], strings.Replace(h.Base64(), h.Base64()[:1], "!", 1)} {
+		_, err := ParseHashBase64(s)
+		assert.ErrorIs(t, err, ErrInvalidHash, s)
+	}
+	for _, s := range []string{"", h.Base32()[1:], "1" + h.Base32()[1:], h.Base32() + ".i2p"} {
+		_, err := ParseHashBase32(s)
+		assert.ErrorIs(t, err, ErrInvalidHash, s)
+	}
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"encoding/binary"
+	"fmt"
 )
 
 // MAX_INTEGER_SIZE is the maximum length of an I2P integer in bytes.
@@ -33,28 +34,43 @@ func (i Integer) Int() int {
 	return intFromBytes(i.Bytes())
 }
 
+// ParseInteger reads an Integer of size bytes, at most MAX_INTEGER_SIZE,
+// from the start of data. It returns the Integer and the number of bytes
+// consumed, or ErrIntegerTooShort if data is shorter than the Integer.
+func ParseInteger(data []byte, size int) (Integer, int, error) {
+	if size > MAX_INTEGER_SIZE {
+		size = MAX_INTEGER_SIZE
+	}
+	if len(data) < size {
+		return nil, 0, ErrIntegerTooShort
+	}
+	return Integer(data[:size]), size, nil
+}
+
 // ReadInteger returns an Integer from a []byte of specified length.
 // The remaining bytes after the specified length are also returned.
+// If bytes is shorter than size, all of it is returned as a shorter Integer.
+//
+// Deprecated: use ParseInteger, which reports short data as an error.
 func ReadInteger(bytes []byte, size int) (Integer, []byte) {
 	if len(bytes) < size {
-		return bytes[:size], bytes[len(bytes):]
+		return bytes, bytes[len(bytes):]
 	}
 	return bytes[:size], bytes[size:]
 }
 
-// NewInteger creates a new Integer from []byte using ReadInteger.
+// NewInteger creates a new Integer from []byte.
 // Limits the length of the created Integer to MAX_INTEGER_SIZE.
-// Returns a pointer to Integer unlike ReadInteger.
+// Returns a pointer to Integer unlike ReadInteger, and ErrIntegerTooShort if
+// bytes is shorter than the Integer.
+//
+// Deprecated: use ParseInteger.
 func NewInteger(bytes []byte, size int) (integer *Integer, remainder []byte, err error) {
-	integerSize := MAX_INTEGER_SIZE
-	if size < MAX_INTEGER_SIZE {
-		integerSize = size
+	i, n, err := ParseInteger(bytes, size)
+	if err != nil {
+		return nil, nil, err
 	}
-	intBytes := bytes[:integerSize]
-	remainder = bytes[integerSize:]
-	i, _ := ReadInteger(intBytes, integerSize)
-	integer = &i
-	return
+	return &i, bytes[n:], nil
 }
 
 // NewIntegerFromInt creates a new Integer from a Go integer of a specified []byte length.
@@ -65,9 +81,62 @@ func NewIntegerFromInt(value int, size int) (integer *Integer, err error) {
 	if size < MAX_INTEGER_SIZE {
 		integerSize = size
 	}
-	objinteger, _, err := NewInteger(bytes[MAX_INTEGER_SIZE-integerSize:], integerSize)
-	integer = objinteger
-	return
+	objinteger, _, err := ParseInteger(bytes[MAX_INTEGER_SIZE-integerSize:], integerSize)
+	if err != nil {
+		return nil, err
+	}
+	return &objinteger, nil
+}
+
+// NewIntegerFromIntChecked creates a new Integer from a Go integer of a
+// specified []byte length like NewIntegerFromInt, but returns
+// ErrIntegerOverflow instead of truncating a value which is negative or does
+// not fit in size bytes.
+func NewIntegerFromIntChecked(value int, size int) (integer *Integer, err error) {
+	if size < 1 || size > MAX_INTEGER_SIZE {
+		return nil, fmt.Errorf("%w: %d byte integer", ErrIntegerOverflow, size)
+	}
+	if value < 0 || (size < MAX_INTEGER_SIZE && uint64(value) >= 1<<(8*size)) {
+		return nil, fmt.Errorf("%w: %d does not fit in %d bytes", ErrIntegerOverflow, value, size)
+	}
+	return NewIntegerFromInt(value, size)
+}
+
+// Uint64 returns the Integer as a uint64, which holds every 8 byte value
+// without the sign overflow of Int.
+func (i Integer) Uint64() uint64 {
+	number := i.Bytes()
+	if len(number) > MAX_INTEGER_SIZE {
+		number = number[len(number)-MAX_INTEGER_SIZE:]
+	}
+	var value uint64
+	for _, b := range number {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// Compare returns -1, 0 or 1 as i is less than, equal to or greater than
+// other. Integers of different lengths compare by value.
+func (i Integer) Compare(other Integer) int {
+	a, b := i.Uint64(), other.Uint64()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether i and other have the same value.
+func (i Integer) Equal(other Integer) bool {
+	return i.Compare(other) == 0
+}
+
+// Less reports whether i is less than other.
+func (i Integer) Less(other Integer) bool {
+	return i.Compare(other) < 0
 }
 
 // Interpret a slice of bytes from length 0 to length 8 as a big-endian
@@ -30,3 +30,75 @@ func TestIsZeroWithNoData(t *testing.T) {
 
 	assert.Equal(integer.Int(), 0, "Integer() did not correctly parse zero length byte slice")
 }
+
+func TestNewIntegerReportsShortData(t *testing.T) {
+	assert := assert.New(t)
+
+	integer, remainder, err := NewInteger([]byte{0x01, 0x02, 0x03}, 2)
+	assert.Nil(err)
+	assert.Equal(0x0102, integer.Int())
+	assert.Equal([]byte{0x03}, remainder)
+
+	_, _, err = NewInteger([]byte{0x01}, 2)
+	assert.ErrorIs(err, ErrIntegerTooShort)
+
+	short, remainder := ReadInteger([]byte{0x01}, 2)
+	assert.Equal(1, short.Int())
+	assert.Empty(remainder)
+}
+
+func TestNewIntegerFromIntChecked(t *testing.T) {
+	assert := assert.New(t)
+
+	integer, err := NewIntegerFromIntChecked(0xffff, 2)
+	assert.Nil(err)
+	assert.Equal([]byte{0xff, 0xff}, integer.Bytes())
+
+	integer, err = NewIntegerFromIntChecked(0, 1)
+	assert.Nil(err)
+	assert.Equal([]byte{0x00}, integer.Bytes())
+
+	for _, c := range []struct{ value, size int }{{0x10000, 2}, {256, 1}, {-1, 4}, {1, 0}, {1, 9}} {
+		_, err = NewIntegerFromIntChecked(c.value, c.size)
+		assert.ErrorIs(err, ErrIntegerOverflow, "%d in %d bytes", c.value, c.size)
+	}
+
+	// the unchecked constructor still truncates
+	integer, err = NewIntegerFromInt(0x10001, 2)
+	assert.Nil(err)
+	assert.Equal(1, integer.Int())
+}
+
+func TestIntegerCompare(t *testing.T) {
+	assert := assert.New(t)
+
+	one := Integer([]byte{0x01})
+	wideOne := Integer([]byte{0x00, 0x00, 0x01})
+	big := Integer([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff})
+
+	assert.True(one.Equal(wideOne))
+	assert.Equal(0, one.Compare(wideOne))
+	assert.True(one.Less(big), "values above MaxInt64 compare unsigned")
+	assert.Equal(1, big.Compare(one))
+	assert.Equal(uint64(0xffffffffffffffff), big.Uint64())
+	assert.Equal(uint64(0), Integer{}.Uint64())
+}
+
+func TestParseInteger(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x01, 0x02, 0x03}
+	integer, consumed, err := ParseInteger(data, 2)
+	assert.Nil(err)
+	assert.Equal(2, consumed)
+	assert.Equal(0x0102, integer.Int())
+
+	integer, consumed, err = ParseInteger(data, 3)
+	assert.Nil(err)
+	assert.Equal(len(data), consumed)
+	assert.Equal(0x010203, integer.Int())
+
+	_, consumed, err = ParseInteger(data, 4)
+	assert.ErrorIs(err, ErrIntegerTooShort)
+	assert.Equal(0, consumed)
+}
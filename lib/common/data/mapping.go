@@ -2,6 +2,7 @@ package data
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 )
@@ -37,6 +38,25 @@ val_string :: String
 ; :: A single byte containing ';'
 */
 
+var ErrMappingKeyOrder = errors.New("mapping keys out of order")
+
+// MappingOption changes how NewMapping parses a Mapping.
+type MappingOption func(*mappingOptions)
+
+type mappingOptions struct {
+	strict bool
+}
+
+// StrictMapping makes NewMapping reject a Mapping that is not in canonical
+// form, as floodfills must when validating published RouterInfos: any format
+// warning, a duplicate key, keys not sorted by their bytes or a key or value
+// which is not valid UTF-8 make it return a nil Mapping.
+func StrictMapping() MappingOption {
+	return func(options *mappingOptions) {
+		options.strict = true
+	}
+}
+
 // Mapping is the represenation of an I2P Mapping.
 //
 // https://geti2p.net/spec/common-structures#mapping
@@ -96,33 +116,56 @@ func (mapping *Mapping) HasDuplicateKeys() bool {
 	return false
 }
 
-// GoMapToMapping converts a Go map of unformatted strings to *Mapping.
+// CheckCanonical returns ErrDuplicateMappingKey or ErrMappingKeyOrder if the
+// keys of the mapping are not unique and sorted by their bytes, the form
+// signed structures require.
+func (mapping *Mapping) CheckCanonical() error {
+	values := mapping.Values()
+	for i := 1; i < len(values); i++ {
+		previous, _ := values[i-1][0].Data()
+		key, _ := values[i][0].Data()
+		switch {
+		case key == previous:
+			return fmt.Errorf("%w: %q", ErrDuplicateMappingKey, key)
+		case key < previous:
+			return fmt.Errorf("%w: %q after %q", ErrMappingKeyOrder, key, previous)
+		}
+	}
+	return nil
+}
+
+// CheckUTF8 returns a *UTF8Error naming the first key or value of the
+// mapping which is not valid UTF-8.
+func (mapping *Mapping) CheckUTF8() error {
+	for _, pair := range mapping.Values() {
+		for _, str := range pair {
+			if _, err := str.Data(ValidUTF8()); err != nil && errors.Is(err, ErrInvalidUTF8) {
+				return fmt.Errorf("%q: %w", str[1:], err)
+			}
+		}
+	}
+	return nil
+}
+
+// GoMapToMapping converts a Go map of unformatted strings to *Mapping, with
+// keys in canonical order. Returns an error if a string or the whole Mapping
+// is too long.
 func GoMapToMapping(gomap map[string]string) (mapping *Mapping, err error) {
 	log.WithFields(logrus.Fields{
 		"input_map_size": len(gomap),
 	}).Debug("Converting Go map to Mapping")
-	map_vals := MappingValues{}
+	builder := NewMappingBuilder()
 	for k, v := range gomap {
-		key_str, kerr := ToI2PString(k)
-		if kerr != nil {
-			log.WithError(kerr).Error("Failed to convert key to I2PString")
-			err = kerr
+		if err = builder.Set(k, v); err != nil {
+			log.WithError(err).Error("Failed to convert Go map to Mapping")
 			return
 		}
-		val_str, verr := ToI2PString(v)
-		if verr != nil {
-			log.WithError(verr).Error("Failed to convert value to I2PString")
-			err = verr
-			return
-		}
-		map_vals = append(
-			map_vals,
-			[2]I2PString{key_str, val_str},
-		)
 	}
-	mapping = ValuesToMapping(map_vals)
+	if mapping, err = builder.Build(); err != nil {
+		return
+	}
 	log.WithFields(logrus.Fields{
-		"mapping_size": len(map_vals),
+		"mapping_size": builder.Len(),
 	}).Debug("Successfully converted Go map to Mapping")
 	return
 }
@@ -130,7 +173,8 @@ func GoMapToMapping(gomap map[string]string) (mapping *Mapping, err error) {
 // Check if the string parsing error indicates that the Mapping
 // should no longer be parsed.
 func stopValueRead(err error) bool {
-	result := err.Error() == "error parsing string: zero length"
+	// errors carrying the message without wrapping the sentinel still count
+	result := errors.Is(err, ErrZeroLength) || err.Error() == ErrZeroLength.Error()
 	if result {
 		log.WithError(err).Debug("Stopping value read due to zero length error")
 	}
@@ -152,10 +196,9 @@ func beginsWith(bytes []byte, chr byte) bool {
 	return result
 }
 
-// ReadMapping returns Mapping from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error) {
+// readMapping reads a Mapping, returning the data after it and the errors
+// which occurred
+func readMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(bytes),
 	}).Debug("Reading Mapping from bytes")
@@ -164,7 +207,7 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 			"at":     "ReadMapping",
 			"reason": "zero length",
 		}).Warn("mapping format violation")
-		e := errors.New("zero length")
+		e := ErrMappingTooShort
 		err = append(err, e)
 		return
 	}
@@ -184,14 +227,14 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 			"expected_size": size.Int(),
 			"actual_size":   len(remainder),
 		}).Warn("mapping format violation: mapping length exceeds provided data")
-		e := errors.New("warning parsing mapping: mapping length exceeds provided data")
+		e := ErrMappingLengthMismatch
 		err = append(err, e)
 
 		// Use whatever data is available (recovery)
 		map_bytes := remainder
 		remainder = nil
 
-		vals, _, mappingValueErrs := ReadMappingValues(map_bytes, *size)
+		vals, _, mappingValueErrs := readMappingValues(map_bytes, *size)
 		err = append(err, mappingValueErrs...)
 		mapping.vals = vals
 		return
@@ -201,7 +244,7 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 	map_bytes := remainder[:size.Int()]
 	remainder = remainder[size.Int():]
 
-	vals, _, mappingValueErrs := ReadMappingValues(map_bytes, *size)
+	vals, _, mappingValueErrs := readMappingValues(map_bytes, *size)
 	err = append(err, mappingValueErrs...)
 	mapping.vals = vals
 	if len(mappingValueErrs) > 0 {
@@ -209,7 +252,7 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 			"at":     "ReadMapping",
 			"reason": "error parsing mapping values",
 		}).Warn("mapping format violation")
-		e := errors.New("error parsing mapping values")
+		e := ErrMappingValues
 		err = append(err, e)
 	}
 	if len(remainder) > 0 { // Handle extra bytes beyond mapping length
@@ -217,7 +260,7 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 			"expected_size": size.Int(),
 			"actual_size":   len(remainder),
 		}).Error("mapping format violation: data exists beyond length of mapping")
-		e := errors.New("warning parsing mapping: data exists beyond length of mapping")
+		e := ErrMappingExcessData
 		err = append(err, e)
 
 		// Slice the exact mapping bytes
@@ -225,7 +268,7 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 		map_bytes := remainder[:size.Int()]
 		remainder = remainder[size.Int():]
 
-		vals, _, mappingValueErrs := ReadMappingValues(map_bytes, *size)
+		vals, _, mappingValueErrs := readMappingValues(map_bytes, *size)
 		err = append(err, mappingValueErrs...)
 		mapping.vals = vals
 		*/
@@ -242,15 +285,34 @@ func ReadMapping(bytes []byte) (mapping Mapping, remainder []byte, err []error)
 	return
 }
 
-// NewMapping creates a new *Mapping from []byte using ReadMapping.
-// Returns a pointer to Mapping unlike ReadMapping.
-func NewMapping(bytes []byte) (values *Mapping, remainder []byte, err []error) {
+// newMapping reads a Mapping with opts, returning nil with StrictMapping if
+// any error occurred
+func newMapping(bytes []byte, opts ...MappingOption) (values *Mapping, remainder []byte, err []error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(bytes),
 	}).Debug("Creating new Mapping")
 
-	objvalues, remainder, err := ReadMapping(bytes)
+	var options mappingOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	objvalues, remainder, err := readMapping(bytes)
 	values = &objvalues
+	if options.strict {
+		if e := values.CheckCanonical(); e != nil {
+			err = append(err, e)
+		}
+		if e := values.CheckUTF8(); e != nil {
+			err = append(err, e)
+		}
+		if len(err) > 0 {
+			log.WithFields(logrus.Fields{
+				"at":          "NewMapping",
+				"error_count": len(err),
+			}).Warn("Rejecting non-canonical Mapping")
+			return nil, remainder, err
+		}
+	}
 
 	log.WithFields(logrus.Fields{
 		"values_count":     len(values.Values()),
@@ -259,3 +321,38 @@ func NewMapping(bytes []byte) (values *Mapping, remainder []byte, err []error) {
 	}).Debug("Finished creating new Mapping")
 	return
 }
+
+// ParseMapping reads a Mapping from the start of data and returns it with the
+// number of bytes consumed. The errors which occurred while parsing are
+// joined into one; the Mapping holds what could be read despite them, unless
+// StrictMapping is given, which rejects a Mapping with any error. Data after
+// the Mapping is reported as ErrMappingExcessData, pass only the Mapping to
+// avoid it.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseMapping(data []byte, opts ...MappingOption) (Mapping, int, error) {
+	mapping, remainder, errs := newMapping(data, opts...)
+	var value Mapping
+	if mapping != nil {
+		value = *mapping
+	}
+	return value, len(data) - len(remainder), errors.Join(errs...)
+}
+
+// ReadMapping returns Mapping from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing.
+//
+// Deprecated: use ParseMapping, which joins the errors into one.
+func ReadMapping(bytes []byte) (Mapping, []byte, []error) {
+	return readMapping(bytes)
+}
+
+// NewMapping creates a new *Mapping from []byte using ReadMapping.
+// Returns a pointer to Mapping unlike ReadMapping. With StrictMapping the
+// pointer is nil if any error occurred.
+//
+// Deprecated: use ParseMapping.
+func NewMapping(bytes []byte, opts ...MappingOption) (*Mapping, []byte, []error) {
+	return newMapping(bytes, opts...)
+}
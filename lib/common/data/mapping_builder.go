@@ -0,0 +1,71 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MAPPING_MAX_SIZE is the largest number of bytes a Mapping's 2 byte size
+// can describe.
+const MAPPING_MAX_SIZE = 65535
+
+var (
+	ErrMappingTooLarge     = errors.New("mapping exceeds 65535 bytes")
+	ErrDuplicateMappingKey = errors.New("duplicate key in mapping")
+)
+
+// MappingBuilder builds a Mapping in the canonical form signed structures
+// require: each key once, keys sorted by their bytes.
+type MappingBuilder struct {
+	pairs map[string][2]I2PString
+}
+
+// NewMappingBuilder returns an empty MappingBuilder.
+func NewMappingBuilder() *MappingBuilder {
+	return &MappingBuilder{pairs: make(map[string][2]I2PString)}
+}
+
+// Set adds key with value. It returns ErrDuplicateMappingKey if key is
+// already set, Delete it first to replace it, or an error if key or value
+// do not fit an I2PString.
+func (builder *MappingBuilder) Set(key, value string) error {
+	if _, ok := builder.pairs[key]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateMappingKey, key)
+	}
+	keyString, err := ToI2PString(key)
+	if err != nil {
+		return fmt.Errorf("mapping key %q: %w", key, err)
+	}
+	valueString, err := ToI2PString(value)
+	if err != nil {
+		return fmt.Errorf("mapping value of %q: %w", key, err)
+	}
+	builder.pairs[key] = [2]I2PString{keyString, valueString}
+	return nil
+}
+
+// Delete removes key, if set.
+func (builder *MappingBuilder) Delete(key string) {
+	delete(builder.pairs, key)
+}
+
+// Len returns the number of keys set.
+func (builder *MappingBuilder) Len() int {
+	return len(builder.pairs)
+}
+
+// Build returns the Mapping, or ErrMappingTooLarge if its pairs do not fit
+// in MAPPING_MAX_SIZE bytes.
+func (builder *MappingBuilder) Build() (*Mapping, error) {
+	keys := make([]string, 0, len(builder.pairs))
+	for key := range builder.pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make(MappingValues, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, builder.pairs[key])
+	}
+	return ValuesToMapping(values)
+}
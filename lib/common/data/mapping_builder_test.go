@@ -0,0 +1,60 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingBuilderSortsKeys(t *testing.T) {
+	builder := NewMappingBuilder()
+	require.NoError(t, builder.Set("b", "2"))
+	require.NoError(t, builder.Set("a", "1"))
+	require.NoError(t, builder.Set("B", "3"))
+	mapping, err := builder.Build()
+	require.NoError(t, err)
+
+	// byte order puts upper case first
+	expected := []byte{0x00, 0x12,
+		0x01, 'B', '=', 0x01, '3', ';',
+		0x01, 'a', '=', 0x01, '1', ';',
+		0x01, 'b', '=', 0x01, '2', ';',
+	}
+	assert.Equal(t, expected, mapping.Data())
+
+	read, _, errs := ReadMapping(mapping.Data())
+	assert.Empty(t, errs)
+	assert.Equal(t, mapping.Data(), read.Data())
+}
+
+func TestMappingBuilderRefusesDuplicates(t *testing.T) {
+	builder := NewMappingBuilder()
+	require.NoError(t, builder.Set("key", "one"))
+	assert.ErrorIs(t, builder.Set("key", "two"), ErrDuplicateMappingKey)
+
+	builder.Delete("key")
+	require.NoError(t, builder.Set("key", "two"))
+	mapping, err := builder.Build()
+	require.NoError(t, err)
+	value, ok := mapping.Lookup("key")
+	assert.True(t, ok)
+	assert.Equal(t, "two", value)
+}
+
+func TestMappingBuilderLimits(t *testing.T) {
+	builder := NewMappingBuilder()
+	assert.Error(t, builder.Set(strings.Repeat("k", 256), "v"))
+
+	value := strings.Repeat("v", 255)
+	for i := 0; i < 300; i++ {
+		require.NoError(t, builder.Set(fmt.Sprintf("key%03d", i), value))
+	}
+	_, err := builder.Build()
+	assert.ErrorIs(t, err, ErrMappingTooLarge)
+
+	_, err = GoMapToMapping(map[string]string{"k": strings.Repeat("v", 300)})
+	assert.Error(t, err)
+}
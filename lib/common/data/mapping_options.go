@@ -0,0 +1,89 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OptionError is returned when an option is set but its value cannot be
+// parsed as the requested type.
+type OptionError struct {
+	Key   string
+	Value string
+	Type  string
+	Err   error
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("option %s=%q is not a valid %s", e.Key, e.Value, e.Type)
+}
+
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// Lookup returns the value of the option named key and whether it is set.
+// A value which is not valid UTF-8 counts as not set.
+func (mapping *Mapping) Lookup(key string) (string, bool) {
+	if mapping == nil {
+		return "", false
+	}
+	for _, pair := range mapping.Values() {
+		k, err := pair[0].Data()
+		if err != nil || k != key {
+			continue
+		}
+		value, err := pair[1].Data(ValidUTF8())
+		if err != nil && err != ErrZeroLength {
+			if errors.Is(err, ErrInvalidUTF8) {
+				log.WithError(err).WithField("key", key).Warn("Ignoring option which is not valid UTF-8")
+			}
+			return "", false
+		}
+		return value, true
+	}
+	return "", false
+}
+
+// OptionString returns the value of the option named key, or def if it is
+// not set.
+func (mapping *Mapping) OptionString(key, def string) string {
+	if value, ok := mapping.Lookup(key); ok {
+		return value
+	}
+	return def
+}
+
+// OptionInt returns the option named key parsed as a decimal integer, or def
+// if it is not set. An *OptionError is returned along with def if the value
+// is not an integer.
+func (mapping *Mapping) OptionInt(key string, def int) (int, error) {
+	value, ok := mapping.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return def, &OptionError{Key: key, Value: value, Type: "integer", Err: err}
+	}
+	return i, nil
+}
+
+// OptionBool returns the option named key parsed as a boolean, or def if it
+// is not set. Like the Java router it accepts true and false in any case. An
+// *OptionError is returned along with def for any other value.
+func (mapping *Mapping) OptionBool(key string, def bool) (bool, error) {
+	value, ok := mapping.Lookup(key)
+	if !ok {
+		return def, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return def, &OptionError{Key: key, Value: value, Type: "boolean"}
+}
@@ -0,0 +1,54 @@
+package data
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappingTypedOptions(t *testing.T) {
+	assert := assert.New(t)
+	mapping, err := GoMapToMapping(map[string]string{
+		"name":    "router",
+		"count":   "42",
+		"enabled": "TRUE",
+		"bad":     "forty",
+	})
+	require.NoError(t, err)
+
+	assert.Equal("router", mapping.OptionString("name", "x"))
+	assert.Equal("x", mapping.OptionString("missing", "x"))
+
+	count, err := mapping.OptionInt("count", 0)
+	assert.NoError(err)
+	assert.Equal(42, count)
+	count, err = mapping.OptionInt("missing", 7)
+	assert.NoError(err)
+	assert.Equal(7, count)
+
+	count, err = mapping.OptionInt("bad", 7)
+	assert.Equal(7, count)
+	var optionErr *OptionError
+	if assert.True(errors.As(err, &optionErr)) {
+		assert.Equal("bad", optionErr.Key)
+		assert.Equal("forty", optionErr.Value)
+		assert.Equal("integer", optionErr.Type)
+	}
+
+	enabled, err := mapping.OptionBool("enabled", false)
+	assert.NoError(err)
+	assert.True(enabled)
+	enabled, err = mapping.OptionBool("bad", true)
+	assert.True(enabled)
+	assert.True(errors.As(err, &optionErr))
+}
+
+func TestNilMappingOptionsReturnDefaults(t *testing.T) {
+	var mapping *Mapping
+	assert.Equal(t, "x", mapping.OptionString("key", "x"))
+	i, err := mapping.OptionInt("key", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, i)
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// ReadMappingFrom reads a Mapping off r, reading exactly its bytes. The
+// errors ReadMapping returns are joined into one.
+func ReadMappingFrom(r io.Reader) (mapping Mapping, err error) {
+	data, err := ReadMappingBytes(r)
+	if err != nil {
+		return
+	}
+	if len(data) == 2 {
+		// ReadMapping rejects a mapping of only its size
+		size := Integer(data)
+		mapping.size = &size
+		mapping.vals = &MappingValues{}
+		return
+	}
+	mapping, _, errs := readMapping(data)
+	return mapping, errors.Join(errs...)
+}
+
+// ReadMappingBytes reads the bytes of a Mapping, its size and the pairs that
+// follow, off r without parsing them.
+func ReadMappingBytes(r io.Reader) ([]byte, error) {
+	data := make([]byte, 2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	data = append(data, make([]byte, binary.BigEndian.Uint16(data))...)
+	if _, err := io.ReadFull(r, data[2:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+// unexpectedEOF turns io.EOF part way through a structure into
+// io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,29 @@
+package data
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMappingFrom(t *testing.T) {
+	mapping, err := GoMapToMapping(map[string]string{"a": "b", "c": "d"})
+	require.NoError(t, err)
+	data := mapping.Data()
+
+	stream := bytes.NewReader(append(append([]byte(nil), data...), 0x01))
+	read, err := ReadMappingFrom(stream)
+	require.NoError(t, err)
+	assert.Equal(t, data, read.Data())
+	assert.Equal(t, 1, stream.Len())
+
+	empty, err := ReadMappingFrom(bytes.NewReader([]byte{0x00, 0x00}))
+	require.NoError(t, err)
+	assert.Empty(t, empty.Values())
+
+	_, err = ReadMappingFrom(bytes.NewReader(data[:len(data)-1]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
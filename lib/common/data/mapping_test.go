@@ -122,6 +122,46 @@ func TestReadMappingHasDuplicateKeys(t *testing.T) {
 	assert.Equal("mapping format violation, duplicate key in mapping", errs[0].Error(), "ReadMapping should throw an error when duplicate keys are present.")
 }
 
+func TestNewMappingStrict(t *testing.T) {
+	assert := assert.New(t)
+
+	canonical := []byte{0x00, 0x0c, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b, 0x01, 0x62, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, _, errs := NewMapping(canonical, StrictMapping())
+	assert.Empty(errs)
+	if assert.NotNil(mapping) {
+		assert.Len(mapping.Values(), 2)
+	}
+
+	duplicate := []byte{0x00, 0x0c, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, _, errs = NewMapping(duplicate, StrictMapping())
+	assert.Nil(mapping)
+	assert.True(containsError(errs, ErrDuplicateMappingKey), "strict NewMapping should reject duplicate keys")
+
+	unordered := []byte{0x00, 0x0c, 0x01, 0x62, 0x3d, 0x01, 0x62, 0x3b, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, _, errs = NewMapping(unordered, StrictMapping())
+	assert.Nil(mapping)
+	assert.True(containsError(errs, ErrMappingKeyOrder), "strict NewMapping should reject unsorted keys")
+
+	// without the option the mapping is returned as read
+	mapping, _, errs = NewMapping(unordered)
+	assert.Empty(errs)
+	assert.ErrorIs(mapping.CheckCanonical(), ErrMappingKeyOrder)
+
+	// format warnings are fatal too
+	mapping, _, errs = NewMapping([]byte{0x00, 0x06, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b, 0x00}, StrictMapping())
+	assert.Nil(mapping)
+	assert.NotEmpty(errs)
+}
+
+func containsError(errs []error, target error) bool {
+	for _, err := range errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 func TestGoMapToMappingProducesCorrectMapping(t *testing.T) {
 	assert := assert.New(t)
 
@@ -190,3 +230,42 @@ func TestBeginsWithCorrectWhenNil(t *testing.T) {
 
 	assert.Equal(false, beginsWith(slice, 0x41), "beginsWith() did not return false on empty slice")
 }
+
+func TestMappingRejectsInvalidUTF8(t *testing.T) {
+	assert := assert.New(t)
+
+	invalid := []byte{0x00, 0x0c, 0x01, 0x61, 0x3d, 0x01, 0xff, 0x3b, 0x01, 0x62, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, _, errs := NewMapping(invalid, StrictMapping())
+	assert.Nil(mapping)
+	assert.True(containsError(errs, ErrInvalidUTF8), "strict NewMapping should reject invalid UTF-8")
+
+	mapping, _, errs = NewMapping(invalid)
+	assert.Empty(errs)
+	_, ok := mapping.Lookup("a")
+	assert.False(ok, "invalid value should not be returned")
+	assert.Equal("b", mapping.OptionString("b", ""))
+}
+
+func TestParseMapping(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x00, 0x06, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, consumed, err := ParseMapping(data)
+	assert.Nil(err)
+	assert.Equal(len(data), consumed)
+	assert.Len(mapping.Values(), 1)
+
+	_, consumed, err = ParseMapping(append(data, 0x00))
+	assert.ErrorIs(err, ErrMappingExcessData)
+	assert.Equal(len(data), consumed)
+
+	_, _, err = ParseMapping([]byte{0x00, 0x06, 0x01, 0x61, 0x3d, 0x01, 0x62})
+	assert.ErrorIs(err, ErrMappingLengthMismatch)
+	if joined, ok := err.(interface{ Unwrap() []error }); assert.True(ok) {
+		assert.Len(joined.Unwrap(), 2, "all errors are joined")
+	}
+
+	mapping, _, err = ParseMapping([]byte{0x00, 0x0c, 0x01, 0x62, 0x3d, 0x01, 0x62, 0x3b, 0x01, 0x61, 0x3d, 0x01, 0x61, 0x3b}, StrictMapping())
+	assert.ErrorIs(err, ErrMappingKeyOrder)
+	assert.Empty(mapping.Values())
+}
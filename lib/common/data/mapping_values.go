@@ -2,6 +2,7 @@ package data
 
 import (
 	"errors"
+	"fmt"
 	"sort"
 
 	"github.com/sirupsen/logrus"
@@ -33,7 +34,9 @@ func (m MappingValues) Get(key I2PString) I2PString {
 
 // ValuesToMapping creates a *Mapping using MappingValues.
 // The values are sorted in the order defined in mappingOrder.
-func ValuesToMapping(values MappingValues) *Mapping {
+// Returns ErrMappingTooLarge if the values do not fit in MAPPING_MAX_SIZE
+// bytes.
+func ValuesToMapping(values MappingValues) (*Mapping, error) {
 	mappingOrder(values)
 
 	// Default length to 2 * len
@@ -49,15 +52,22 @@ func ValuesToMapping(values MappingValues) *Mapping {
 		}
 	}
 
+	mappingSize, err := NewIntegerFromIntChecked(baseLength, 2)
+	if err != nil || baseLength > MAPPING_MAX_SIZE {
+		log.WithFields(logrus.Fields{
+			"mapping_size": baseLength,
+			"max_size":     MAPPING_MAX_SIZE,
+		}).Error("Mapping too large")
+		return nil, fmt.Errorf("%w: %d bytes", ErrMappingTooLarge, baseLength)
+	}
+
 	log.WithFields(logrus.Fields{
 		"mapping_size": baseLength,
 	}).Debug("Created Mapping from MappingValues")
-
-	mappingSize, _ := NewIntegerFromInt(baseLength, 2)
 	return &Mapping{
 		size: mappingSize,
 		vals: &values,
-	}
+	}, nil
 }
 
 // I2P Mappings require consistent order in some cases for cryptographic signing, and sorting
@@ -73,10 +83,9 @@ func mappingOrder(values MappingValues) {
 	})
 }
 
-// ReadMappingValues returns *MappingValues from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingValues, remainder_bytes []byte, errs []error) {
+// readMappingValues reads the key/value pairs of a Mapping of map_length
+// bytes, returning the errors which occurred
+func readMappingValues(remainder []byte, map_length Integer) (values *MappingValues, remainder_bytes []byte, errs []error) {
 	// mapping := remainder
 	// var remainder = mapping
 	// var err error
@@ -90,7 +99,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 			"at":     "(Mapping) Values",
 			"reason": "data shorter than expected",
 		}).Error("mapping contained no data")
-		errs = []error{errors.New("mapping contained no data")}
+		errs = []error{ErrMappingEmpty}
 		return
 	}
 	map_values := make(MappingValues, 0)
@@ -103,7 +112,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 			"mapping_length_field": int_map_length,
 			"reason":               "data longer than expected",
 		}).Warn("mapping format warning")
-		errs = append(errs, errors.New("warning parsing mapping: data exists beyond length of mapping"))
+		errs = append(errs, ErrMappingExcessData)
 	} else if int_map_length > mapping_len {
 		log.WithFields(logrus.Fields{
 			"at":                   "(Mapping) Values",
@@ -111,7 +120,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 			"mapping_length_field": int_map_length,
 			"reason":               "data shorter than expected",
 		}).Warn("mapping format warning")
-		errs = append(errs, errors.New("warning parsing mapping: mapping length exceeds provided data"))
+		errs = append(errs, ErrMappingLengthMismatch)
 	}
 
 	encounteredKeysMap := map[string]bool{}
@@ -136,7 +145,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 			break
 		}
 
-		key_str, more, err := ReadI2PString(remainder)
+		key_str, more, err := readI2PString(remainder)
 		if err != nil {
 			if stopValueRead(err) {
 				errs = append(errs, err)
@@ -158,7 +167,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 				"key":    string(key_str),
 			}).Error("mapping format violation")
 			log.Printf("DUPE: %s", key_str)
-			errs = append(errs, errors.New("mapping format violation, duplicate key in mapping"))
+			errs = append(errs, fmt.Errorf("mapping format violation, %w", ErrDuplicateMappingKey))
 			// Based on other implementations this does not seem to happen often?
 			// Java throws an exception in this case, the base object is a Hashmap so the value is overwritten and an exception is thrown.
 			// i2pd  as far as I can tell just overwrites the original value
@@ -171,7 +180,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 				"reason": "expected =",
 				"value:": string(remainder),
 			}).Warn("mapping format violation")
-			errs = append(errs, errors.New("mapping format violation, expected ="))
+			errs = append(errs, ErrMappingExpectedEquals)
 			log.Printf("ERRVAL: %s", remainder)
 			break
 		} else {
@@ -180,7 +189,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 
 		// Read a value, breaking on fatal errors
 		// and appending warnings
-		val_str, more, err := ReadI2PString(remainder)
+		val_str, more, err := readI2PString(remainder)
 		if err != nil {
 			if stopValueRead(err) {
 				errs = append(errs, err)
@@ -197,7 +206,7 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 				"reason": "expected ;",
 				"value:": string(remainder),
 			}).Warn("mapping format violation")
-			errs = append(errs, errors.New("mapping format violation, expected ;"))
+			errs = append(errs, ErrMappingExpectedEnd)
 			break
 		} else {
 			remainder = remainder[1:]
@@ -222,3 +231,25 @@ func ReadMappingValues(remainder []byte, map_length Integer) (values *MappingVal
 
 	return
 }
+
+// ParseMappingValues reads the key/value pairs of a Mapping whose size field
+// is mapLength from data, the Mapping without its size, and returns them with
+// the number of bytes consumed, which is all of data. The errors which
+// occurred while parsing are joined into one.
+func ParseMappingValues(data []byte, mapLength Integer) (MappingValues, int, error) {
+	values, remainder, errs := readMappingValues(data, mapLength)
+	var value MappingValues
+	if values != nil {
+		value = *values
+	}
+	return value, len(data) - len(remainder), errors.Join(errs...)
+}
+
+// ReadMappingValues returns *MappingValues from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing.
+//
+// Deprecated: use ParseMappingValues.
+func ReadMappingValues(remainder []byte, map_length Integer) (*MappingValues, []byte, []error) {
+	return readMappingValues(remainder, map_length)
+}
@@ -1,7 +1,9 @@
 package data
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -45,3 +47,19 @@ func TestMappingOrderSortsValuesThenKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestValuesToMappingRejectsOversizedValues(t *testing.T) {
+	value, _ := ToI2PString(strings.Repeat("v", 255))
+	values := make(MappingValues, 0, 300)
+	for i := 0; i < 300; i++ {
+		key, _ := ToI2PString(fmt.Sprintf("key%03d", i))
+		values = append(values, [2]I2PString{key, value})
+	}
+	mapping, err := ValuesToMapping(values)
+	if !errors.Is(err, ErrMappingTooLarge) {
+		t.Fatalf("ValuesToMapping expected ErrMappingTooLarge, got %v", err)
+	}
+	if mapping != nil {
+		t.Fatal("ValuesToMapping returned a Mapping with a truncated size")
+	}
+}
@@ -0,0 +1,117 @@
+package data
+
+/*
+Buffer ownership
+
+Structures read from a []byte do not copy it: an Integer, I2PString or
+Mapping, and the Certificates, RouterAddresses, RouterInfos and LeaseSets
+built from them, are slices of the buffer they were read from. The buffer
+must not be changed or reused while any of them is in use.
+
+A transport which reads into a pooled buffer has two ways to hand parsed
+structures on safely:
+
+  - read with CopyOnParse, which parses a private copy of the buffer
+  - Clone the structures it keeps before releasing the buffer
+
+Structures created with the New* and Build functions own their data.
+*/
+
+// ParseOption changes how a structure is read from a []byte.
+type ParseOption func(*parseOptions)
+
+type parseOptions struct {
+	copy bool
+}
+
+// CopyOnParse makes the reader parse a copy of its input, so the structure
+// and the remainder returned do not alias the caller's buffer.
+func CopyOnParse() ParseOption {
+	return func(options *parseOptions) {
+		options.copy = true
+	}
+}
+
+// ParseBuffer returns the buffer a reader should parse: data itself, or a
+// copy of it with CopyOnParse.
+func ParseBuffer(data []byte, opts ...ParseOption) []byte {
+	var options parseOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if !options.copy || data == nil {
+		return data
+	}
+	return append(make([]byte, 0, len(data)), data...)
+}
+
+// cloneBytes returns a copy of data, nil if data is nil
+func cloneBytes(data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(data)), data...)
+}
+
+// Clone returns a copy of the Integer which does not alias its buffer.
+func (i Integer) Clone() Integer {
+	return Integer(cloneBytes(i))
+}
+
+// Clone returns a copy of the I2PString which does not alias its buffer.
+func (str I2PString) Clone() I2PString {
+	return I2PString(cloneBytes(str))
+}
+
+// Clone returns a copy of the MappingValues which does not alias their buffer.
+func (mapping_values MappingValues) Clone() MappingValues {
+	if mapping_values == nil {
+		return nil
+	}
+	clone := make(MappingValues, len(mapping_values))
+	for i, pair := range mapping_values {
+		clone[i] = [2]I2PString{pair[0].Clone(), pair[1].Clone()}
+	}
+	return clone
+}
+
+// Clone returns a copy of the Mapping which does not alias its buffer.
+func (mapping Mapping) Clone() Mapping {
+	var clone Mapping
+	if mapping.size != nil {
+		size := mapping.size.Clone()
+		clone.size = &size
+	}
+	if mapping.vals != nil {
+		vals := mapping.vals.Clone()
+		clone.vals = &vals
+	}
+	return clone
+}
+
+// CloneMapping returns a copy of the Mapping, nil if mapping is nil.
+func CloneMapping(mapping *Mapping) *Mapping {
+	if mapping == nil {
+		return nil
+	}
+	clone := mapping.Clone()
+	return &clone
+}
+
+// CloneInteger returns a copy of the Integer, nil if i is nil.
+func CloneInteger(i *Integer) *Integer {
+	if i == nil {
+		return nil
+	}
+	clone := i.Clone()
+	return &clone
+}
+
+// CloneDate returns a copy of the Date, nil if date is nil.
+func CloneDate(date *Date) *Date {
+	if date == nil {
+		return nil
+	}
+	clone := *date
+	return &clone
+}
@@ -0,0 +1,34 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBuffer(t *testing.T) {
+	data := []byte{0x01, 0x61}
+	assert.Same(t, &data[0], &ParseBuffer(data)[0])
+	copied := ParseBuffer(data, CopyOnParse())
+	assert.Equal(t, data, copied)
+	assert.NotSame(t, &data[0], &copied[0])
+	assert.Nil(t, ParseBuffer(nil, CopyOnParse()))
+}
+
+func TestMappingCloneDoesNotAlias(t *testing.T) {
+	buffer := []byte{0x00, 0x06, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b}
+	mapping, _, errs := NewMapping(buffer)
+	require.Empty(t, errs)
+	clone := mapping.Clone()
+
+	// the buffer is reused for something else
+	for i := range buffer {
+		buffer[i] = 0xff
+	}
+	assert.Equal(t, []byte{0x00, 0x06, 0x01, 0x61, 0x3d, 0x01, 0x62, 0x3b}, clone.Data())
+	value, ok := clone.Lookup("a")
+	assert.True(t, ok)
+	assert.Equal(t, "b", value)
+	assert.Nil(t, CloneMapping(nil))
+}
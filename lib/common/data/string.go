@@ -1,7 +1,8 @@
 package data
 
 import (
-	"errors"
+	"fmt"
+	"unicode/utf8"
 
 	"github.com/sirupsen/logrus"
 )
@@ -27,6 +28,60 @@ Length limit is 255 bytes (not characters). Length may be 0.
 // https://geti2p.net/spec/common-structures#string
 type I2PString []byte
 
+// StringOption changes how an I2PString is read.
+type StringOption func(*stringOptions)
+
+type stringOptions struct {
+	validUTF8 bool
+}
+
+// ValidUTF8 makes ReadI2PString and Data return a *UTF8Error if the content
+// is not valid UTF-8, which the specification requires but which peers do not
+// always send.
+func ValidUTF8() StringOption {
+	return func(options *stringOptions) {
+		options.validUTF8 = true
+	}
+}
+
+func newStringOptions(opts []StringOption) (options stringOptions) {
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return
+}
+
+// UTF8Error is returned when the content of an I2PString is not valid UTF-8.
+// It matches ErrInvalidUTF8 with errors.Is.
+type UTF8Error struct {
+	// Offset is the index in the content of the first invalid byte
+	Offset int
+}
+
+func (e *UTF8Error) Error() string {
+	return fmt.Sprintf("%s at byte %d", ErrInvalidUTF8, e.Offset)
+}
+
+func (e *UTF8Error) Unwrap() error {
+	return ErrInvalidUTF8
+}
+
+// checkUTF8 returns a *UTF8Error if content is not valid UTF-8
+func checkUTF8(content []byte) error {
+	if utf8.Valid(content) {
+		return nil
+	}
+	offset := 0
+	for offset < len(content) {
+		r, size := utf8.DecodeRune(content[offset:])
+		if r == utf8.RuneError && size <= 1 {
+			break
+		}
+		offset += size
+	}
+	return &UTF8Error{Offset: offset}
+}
+
 // Length returns the length specified in the first byte.
 // Returns error if the specified does not match the actual length or the string is otherwise invalid.
 func (str I2PString) Length() (length int, err error) {
@@ -38,10 +93,10 @@ func (str I2PString) Length() (length int, err error) {
 		err = ErrZeroLength
 		return
 	}
-	l, _, err := NewInteger(str[:], 1)
+	l, _, err := ParseInteger(str[:], 1)
 	if err != nil {
 		log.WithError(err).Error("Failed to create Integer from I2PString")
-		return l.Int(), err
+		return 0, err
 	}
 	length = l.Int()
 	str_len := len(str)
@@ -70,8 +125,9 @@ func (str I2PString) Length() (length int, err error) {
 }
 
 // Data returns the I2PString content as a string trimmed to the specified length and not including the length byte.
-// Returns error encountered by Length.
-func (str I2PString) Data() (data string, err error) {
+// Returns error encountered by Length, or with ValidUTF8 a *UTF8Error.
+func (str I2PString) Data(opts ...StringOption) (data string, err error) {
+	options := newStringOptions(opts)
 	length, err := str.Length()
 	if err != nil {
 		switch err {
@@ -100,16 +156,37 @@ func (str I2PString) Data() (data string, err error) {
 		}
 	}
 	if length == 0 {
-		log.Debug("I2PString is empty")
+		if log.TraceEnabled() {
+			log.Trace("I2PString is empty")
+		}
 		return "", nil
 	}
+	if options.validUTF8 {
+		if err = checkUTF8(str[1 : length+1]); err != nil {
+			log.WithError(err).Warn("I2PString is not valid UTF-8")
+			return "", err
+		}
+	}
 	data = string(str[1 : length+1])
-	log.WithFields(logrus.Fields{
-		"data_length": len(data),
-	}).Debug("Retrieved I2PString data")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"data_length": len(data),
+		}).Trace("Retrieved I2PString data")
+	}
 	return data, nil
 }
 
+// MustData returns the content of a well-formed, valid UTF-8 I2PString. It
+// panics otherwise, so it is meant for strings already checked or built by
+// this package.
+func (str I2PString) MustData() string {
+	data, err := str.Data(ValidUTF8())
+	if err != nil && err != ErrZeroLength {
+		panic(err)
+	}
+	return data
+}
+
 // ToI2PString converts a Go string to an I2PString.
 // Returns error if the string exceeds STRING_MAX_SIZE.
 func ToI2PString(data string) (str I2PString, err error) {
@@ -124,7 +201,7 @@ func ToI2PString(data string) (str I2PString, err error) {
 			"max_len":    STRING_MAX_SIZE,
 			"reason":     "too much data",
 		}).Error("cannot create I2P string")
-		err = errors.New("cannot store that much data in I2P string")
+		err = ErrStringTooLong
 		return
 	}
 	i2p_string := []byte{byte(data_len)}
@@ -141,10 +218,8 @@ func ToI2PString(data string) (str I2PString, err error) {
 // of the slice and any errors encountered parsing the I2PString.
 //
 
-// ReadI2PString returns I2PString from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadI2PString(data []byte) (str I2PString, remainder []byte, err error) {
+// readI2PString reads an I2PString, returning the data after it
+func readI2PString(data []byte, opts ...StringOption) (str I2PString, remainder []byte, err error) {
 	if len(data) == 0 {
 		err = ErrZeroLength
 		log.WithError(err).Error("Passed data with len == 0")
@@ -153,7 +228,7 @@ func ReadI2PString(data []byte) (str I2PString, remainder []byte, err error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(data),
 	}).Debug("Reading I2PString from bytes")
-	length, _, err := NewInteger(data, 1)
+	length, _, err := ParseInteger(data, 1)
 	if err != nil {
 		log.WithError(err).Error("Failed to read I2PString length")
 		return
@@ -176,6 +251,12 @@ func ReadI2PString(data []byte) (str I2PString, remainder []byte, err error) {
 		}).Error("I2PString length mismatch")
 		return
 	}
+	if err == nil && newStringOptions(opts).validUTF8 {
+		if err = checkUTF8(str[1:]); err != nil {
+			log.WithError(err).Warn("I2PString is not valid UTF-8")
+			return
+		}
+	}
 	log.WithFields(logrus.Fields{
 		"string_length":    l,
 		"remainder_length": len(remainder),
@@ -183,6 +264,24 @@ func ReadI2PString(data []byte) (str I2PString, remainder []byte, err error) {
 	return
 }
 
+// ParseI2PString reads an I2PString from the start of data and returns it with
+// the number of bytes consumed. With ValidUTF8 it returns a *UTF8Error if the
+// string is not valid UTF-8.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseI2PString(data []byte, opts ...StringOption) (I2PString, int, error) {
+	value, remainder, err := readI2PString(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadI2PString returns I2PString from a []byte.
+// The remaining bytes after the specified length are also returned.
+//
+// Deprecated: use ParseI2PString.
+func ReadI2PString(data []byte, opts ...StringOption) (I2PString, []byte, error) {
+	return readI2PString(data, opts...)
+}
+
 // NewI2PString creates a new *I2PString from []byte using ReadI2PString.
 // Returns a pointer to I2PString unlike ReadI2PString.
 /*func NewI2PString(data []byte) (str *I2PString, remainder []byte, err error) {
@@ -190,3 +289,84 @@ func ReadI2PString(data []byte) (str I2PString, remainder []byte, err error) {
 	str = &objstr
 	return
 }*/
+
+// I2PStringIterator reads back-to-back I2PStrings from a []byte, in the style
+// of bufio.Scanner:
+//
+//	it := NewI2PStringIterator(data)
+//	for it.Next() {
+//		str := it.String()
+//	}
+//	if it.Err() != nil { ... }
+type I2PStringIterator struct {
+	remainder []byte
+	current   I2PString
+	err       error
+}
+
+// NewI2PStringIterator returns an I2PStringIterator over data.
+func NewI2PStringIterator(data []byte) *I2PStringIterator {
+	return &I2PStringIterator{remainder: data}
+}
+
+// Next reads the next I2PString. It returns false once the data is used up or
+// a string could not be read, in which case Err returns the reason.
+func (it *I2PStringIterator) Next() bool {
+	it.current = nil
+	if it.err != nil || len(it.remainder) == 0 {
+		return false
+	}
+	str, remainder, err := readI2PString(it.remainder)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.current, it.remainder = str, remainder
+	return true
+}
+
+// String returns the I2PString read by the last call to Next.
+func (it *I2PStringIterator) String() I2PString {
+	return it.current
+}
+
+// Remainder returns the bytes which have not been read yet. After an error it
+// starts at the string which could not be read.
+func (it *I2PStringIterator) Remainder() []byte {
+	return it.remainder
+}
+
+// Err returns the error which stopped the iterator, if any.
+func (it *I2PStringIterator) Err() error {
+	return it.err
+}
+
+// readI2PStringSlice reads back-to-back I2PStrings, returning the data after them
+func readI2PStringSlice(data []byte) (strs []I2PString, remainder []byte, err error) {
+	it := NewI2PStringIterator(data)
+	for it.Next() {
+		strs = append(strs, it.String())
+	}
+	log.WithFields(logrus.Fields{
+		"count":            len(strs),
+		"remainder_length": len(it.Remainder()),
+	}).Debug("Read I2PString slice")
+	return strs, it.Remainder(), it.Err()
+}
+
+// ParseI2PStringSlice reads back-to-back I2PStrings until data is used up and
+// returns them with the number of bytes consumed. On error the strings read
+// so far are returned, and consumed is where the string which could not be
+// read starts.
+func ParseI2PStringSlice(data []byte) ([]I2PString, int, error) {
+	value, remainder, err := readI2PStringSlice(data)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadI2PStringSlice reads back-to-back I2PStrings until data is used up.
+// On error the strings read so far are returned along with the unread data.
+//
+// Deprecated: use ParseI2PStringSlice.
+func ReadI2PStringSlice(data []byte) ([]I2PString, []byte, error) {
+	return readI2PStringSlice(data)
+}
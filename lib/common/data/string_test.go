@@ -151,3 +151,84 @@ func TestReadI2PStringErrWhenDataTooShort(t *testing.T) {
 	assert.Equal(len(str), 0, "ReadI2PString() should not return any data when data is too short")
 	assert.Equal(len(remainder), 0, "ReadI2PString() should not return any remainder when data is too short")
 }
+
+func TestReadI2PStringSlice(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x03, 'f', 'o', 'o', 0x00, 0x02, 'h', 'i'}
+	strs, remainder, err := ReadI2PStringSlice(data)
+	assert.Nil(err)
+	assert.Empty(remainder)
+	assert.Equal([]I2PString{{0x03, 'f', 'o', 'o'}, {0x00}, {0x02, 'h', 'i'}}, strs)
+}
+
+func TestReadI2PStringSliceTruncated(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x02, 'h', 'i', 0x05, 'a', 'b'}
+	strs, remainder, err := ReadI2PStringSlice(data)
+	assert.ErrorIs(err, ErrDataTooShort)
+	assert.Equal([]I2PString{{0x02, 'h', 'i'}}, strs)
+	assert.Equal([]byte{0x05, 'a', 'b'}, remainder)
+}
+
+func TestI2PStringIterator(t *testing.T) {
+	assert := assert.New(t)
+
+	it := NewI2PStringIterator([]byte{0x01, 'a', 0x01, 'b'})
+	var values []string
+	for it.Next() {
+		value, err := it.String().Data()
+		assert.Nil(err)
+		values = append(values, value)
+	}
+	assert.Nil(it.Err())
+	assert.Equal([]string{"a", "b"}, values)
+	assert.False(it.Next())
+	assert.Nil(it.String())
+}
+
+func TestI2PStringValidUTF8(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := I2PString(append([]byte{0x03}, "é!"...))
+	data, err := valid.Data(ValidUTF8())
+	assert.Nil(err)
+	assert.Equal("é!", data)
+	assert.Equal("é!", valid.MustData())
+
+	invalid := I2PString([]byte{0x03, 'a', 0xff, 'b'})
+	data, err = invalid.Data()
+	assert.Nil(err, "validation is opt-in")
+	assert.Equal("a\xffb", data)
+
+	_, err = invalid.Data(ValidUTF8())
+	assert.ErrorIs(err, ErrInvalidUTF8)
+	var utf8Err *UTF8Error
+	if assert.ErrorAs(err, &utf8Err) {
+		assert.Equal(1, utf8Err.Offset)
+	}
+	assert.Panics(func() { invalid.MustData() })
+
+	_, remainder, err := ReadI2PString([]byte{0x03, 'a', 0xff, 'b', 0x00}, ValidUTF8())
+	assert.ErrorIs(err, ErrInvalidUTF8)
+	assert.Equal([]byte{0x00}, remainder)
+	_, _, err = ReadI2PString([]byte{0x03, 'a', 0xff, 'b'})
+	assert.Nil(err)
+}
+
+func TestParseI2PString(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x02, 0x61, 0x62, 0x03}
+	str, consumed, err := ParseI2PString(data)
+	assert.Nil(err)
+	assert.Equal(3, consumed)
+	value, _ := str.Data()
+	assert.Equal("ab", value)
+	assert.Equal([]byte{0x03}, data[consumed:])
+
+	_, consumed, err = ParseI2PString(data[:2])
+	assert.NotNil(err)
+	assert.Empty(data[:2][consumed:])
+}
@@ -0,0 +1,138 @@
+package data
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TIMESTAMP4_SIZE is the length in bytes of a 4 byte seconds timestamp.
+const TIMESTAMP4_SIZE = 4
+
+/*
+[Timestamp4]
+
+Description
+The number of seconds since midnight on January 1, 1970 in the GMT timezone,
+as used by LeaseSet2 published/expires fields, offline signature expiration,
+and SSU2 DateTime blocks. The value is unsigned and rolls over on
+2106-02-07 06:28:15 UTC. If the number is 0, the timestamp is undefined or null.
+
+Contents
+4 byte Integer
+*/
+
+// Timestamp4 is a 4 byte seconds timestamp.
+type Timestamp4 [TIMESTAMP4_SIZE]byte
+
+// Timestamp4Max is the last time a Timestamp4 can hold.
+var Timestamp4Max = time.Unix(math.MaxUint32, 0).UTC()
+
+var (
+	ErrTimestamp4TooShort   = errors.New("ReadTimestamp4: data is too short")
+	ErrTimestamp4OutOfRange = errors.New("time is outside the range of a 4 byte timestamp")
+)
+
+// NewTimestamp4FromTime converts a Go time.Time to a Timestamp4, truncating
+// it to whole seconds. Returns ErrTimestamp4OutOfRange for times before 1970
+// or after Timestamp4Max.
+func NewTimestamp4FromTime(t time.Time) (Timestamp4, error) {
+	var ts Timestamp4
+	seconds := t.Unix()
+	if seconds < 0 || seconds > math.MaxUint32 {
+		log.WithField("time", t).Error("Time out of range for Timestamp4")
+		return ts, ErrTimestamp4OutOfRange
+	}
+	binary.BigEndian.PutUint32(ts[:], uint32(seconds))
+	return ts, nil
+}
+
+// Bytes returns the raw []byte content of a Timestamp4.
+func (ts Timestamp4) Bytes() []byte {
+	return ts[:]
+}
+
+// Uint32 returns the number of seconds since the epoch.
+func (ts Timestamp4) Uint32() uint32 {
+	return binary.BigEndian.Uint32(ts[:])
+}
+
+// IsZero reports whether the timestamp is undefined.
+func (ts Timestamp4) IsZero() bool {
+	return ts.Uint32() == 0
+}
+
+// Time converts the timestamp to a Go time.Time, reading it as seconds since 1970.
+func (ts Timestamp4) Time() time.Time {
+	return time.Unix(int64(ts.Uint32()), 0)
+}
+
+// TimeNear converts the timestamp to the time closest to reference among all
+// of its 2^32 second wraparounds. Timestamps written by peers are always
+// close to the current time, so passing time.Now() keeps them correct past
+// the 2106 rollover.
+func (ts Timestamp4) TimeNear(reference time.Time) time.Time {
+	const period = int64(1) << 32
+	seconds := int64(ts.Uint32())
+	ref := reference.Unix()
+	// start from the period containing the reference and move to a
+	// neighbouring one if that is closer
+	candidate := ref - ref%period + seconds
+	if candidate-ref > period/2 {
+		candidate -= period
+	} else if ref-candidate > period/2 {
+		candidate += period
+	}
+	return time.Unix(candidate, 0)
+}
+
+// readTimestamp4 reads a Timestamp4, returning the data after it
+func readTimestamp4(data []byte) (ts Timestamp4, remainder []byte, err error) {
+	if len(data) < TIMESTAMP4_SIZE {
+		log.WithFields(logrus.Fields{
+			"data": data,
+		}).Error("ReadTimestamp4: data is too short")
+		err = ErrTimestamp4TooShort
+		return
+	}
+	copy(ts[:], data[:TIMESTAMP4_SIZE])
+	remainder = data[TIMESTAMP4_SIZE:]
+	log.WithFields(logrus.Fields{
+		"timestamp_value":  ts.Uint32(),
+		"remainder_length": len(remainder),
+	}).Debug("Successfully read Timestamp4 from data")
+	return
+}
+
+// ParseTimestamp4 reads a Timestamp4 from the first TIMESTAMP4_SIZE bytes of
+// data and returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseTimestamp4(data []byte) (Timestamp4, int, error) {
+	value, remainder, err := readTimestamp4(data)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadTimestamp4 creates a Timestamp4 from []byte using the first TIMESTAMP4_SIZE bytes.
+// Any data after TIMESTAMP4_SIZE is returned as a remainder.
+//
+// Deprecated: use ParseTimestamp4.
+func ReadTimestamp4(data []byte) (Timestamp4, []byte, error) {
+	return readTimestamp4(data)
+}
+
+// NewTimestamp4 creates a new Timestamp4 from []byte using ReadTimestamp4.
+// Returns a pointer to Timestamp4 unlike ReadTimestamp4.
+//
+// Deprecated: use ParseTimestamp4.
+func NewTimestamp4(data []byte) (ts *Timestamp4, remainder []byte, err error) {
+	objts, remainder, err := readTimestamp4(data)
+	if err != nil {
+		log.WithError(err).Error("Failed to create new Timestamp4")
+		return nil, remainder, err
+	}
+	return &objts, remainder, nil
+}
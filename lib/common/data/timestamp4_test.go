@@ -0,0 +1,61 @@
+package data
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamp4FromTime(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1700000000, 999999999)
+	ts, err := NewTimestamp4FromTime(now)
+	assert.Nil(err)
+	assert.Equal(uint32(1700000000), ts.Uint32())
+	assert.Equal(int64(1700000000), ts.Time().Unix())
+	assert.False(ts.IsZero())
+
+	_, err = NewTimestamp4FromTime(time.Unix(-1, 0))
+	assert.ErrorIs(err, ErrTimestamp4OutOfRange)
+	_, err = NewTimestamp4FromTime(Timestamp4Max.Add(time.Second))
+	assert.ErrorIs(err, ErrTimestamp4OutOfRange)
+	ts, err = NewTimestamp4FromTime(Timestamp4Max)
+	assert.Nil(err)
+	assert.Equal(uint32(math.MaxUint32), ts.Uint32())
+}
+
+func TestReadTimestamp4(t *testing.T) {
+	assert := assert.New(t)
+
+	ts, remainder, err := ReadTimestamp4([]byte{0x00, 0x01, 0x51, 0x80, 0xff})
+	assert.Nil(err)
+	assert.Equal(int64(86400), ts.Time().Unix())
+	assert.Equal([]byte{0xff}, remainder)
+
+	_, _, err = ReadTimestamp4([]byte{0x00, 0x01})
+	assert.ErrorIs(err, ErrTimestamp4TooShort)
+
+	ptr, _, err := NewTimestamp4([]byte{0x00, 0x00, 0x00, 0x00})
+	assert.Nil(err)
+	assert.True(ptr.IsZero())
+}
+
+func TestTimestamp4TimeNear(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Unix(1700000000, 0)
+	ts, _ := NewTimestamp4FromTime(now.Add(time.Hour))
+	assert.Equal(now.Add(time.Hour).Unix(), ts.TimeNear(now).Unix())
+
+	// shortly after the 2106 rollover a small value means just past it
+	afterRollover := Timestamp4Max.Add(10 * time.Second)
+	ts = Timestamp4{0x00, 0x00, 0x00, 0x05}
+	assert.Equal(int64(math.MaxUint32)+6, ts.TimeNear(afterRollover).Unix())
+
+	// and a large value read just after the rollover is just before it
+	ts = Timestamp4{0xff, 0xff, 0xff, 0xf0}
+	assert.Equal(int64(0xfffffff0), ts.TimeNear(afterRollover).Unix())
+}
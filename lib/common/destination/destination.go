@@ -2,6 +2,8 @@
 package destination
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/go-i2p/go-i2p/lib/util/logger"
@@ -9,13 +11,14 @@ import (
 
 	. "github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
 
-	"github.com/go-i2p/go-i2p/lib/common/base32"
 	"github.com/go-i2p/go-i2p/lib/common/base64"
-	"github.com/go-i2p/go-i2p/lib/crypto"
+	common "github.com/go-i2p/go-i2p/lib/common/data"
 )
 
 var log = logger.GetGoI2PLogger()
 
+var ErrInvalidDestination = errors.New("invalid destination address")
+
 /*
 [Destination]
 Accurate for version 0.9.49
@@ -34,15 +37,17 @@ type Destination struct {
 	KeysAndCert
 }
 
+// Hash returns the SHA256 of the Destination, the key its LeaseSet is
+// stored under and the hash of its b32 address.
+func (destination Destination) Hash() common.Hash {
+	return common.HashData(destination.KeysAndCert.Bytes())
+}
+
 // Base32Address returns the I2P base32 address for this Destination.
 func (destination Destination) Base32Address() (str string) {
 	log.Debug("Generating Base32 address for Destination")
 
-	cert := destination.KeysAndCert.Certificate()
-	dest := cert.Bytes()
-	hash := crypto.SHA256(dest)
-	str = strings.Trim(base32.EncodeToString(hash[:]), "=")
-	str = str + ".b32.i2p"
+	str = destination.Hash().Base32Address()
 
 	log.WithFields(logrus.Fields{
 		"base32_address": str,
@@ -55,9 +60,7 @@ func (destination Destination) Base32Address() (str string) {
 func (destination Destination) Base64() string {
 	log.Debug("Generating Base64 address for Destination")
 
-	cert := destination.KeysAndCert.Certificate()
-	dest := cert.Bytes()
-	base64Address := base64.EncodeToString(dest)
+	base64Address := base64.EncodeToString(destination.KeysAndCert.Bytes())
 
 	log.WithFields(logrus.Fields{
 		"base64_address_length": len(base64Address),
@@ -66,15 +69,45 @@ func (destination Destination) Base64() string {
 	return base64Address
 }
 
-// ReadDestination returns Destination from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadDestination(data []byte) (destination Destination, remainder []byte, err error) {
+// ParseDestination parses a user-entered address: a Destination in I2P
+// base64, or a base32 address with or without the .b32.i2p suffix. It
+// returns the hash of the destination in both cases, but the Destination
+// itself only for base64; a base32 address names a hash whose LeaseSet
+// must be looked up in the netDb to get the Destination, and dest is nil.
+func ParseDestination(address string) (dest *Destination, hash common.Hash, err error) {
+	address = strings.TrimSpace(address)
+	if hash, err = common.ParseHashBase32(address); err == nil {
+		log.WithField("hash", hash.Base32()).Debug("Parsed base32 address")
+		return nil, hash, nil
+	}
+	data, err := base64.DecodeString(address)
+	if err != nil {
+		return nil, hash, fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	destination, remainder, err := readDestination(data)
+	if err != nil {
+		return nil, hash, fmt.Errorf("%w: %v", ErrInvalidDestination, err)
+	}
+	if len(remainder) > 0 {
+		return nil, hash, fmt.Errorf("%w: %d bytes after the destination", ErrInvalidDestination, len(remainder))
+	}
+	return &destination, destination.Hash(), nil
+}
+
+// Clone returns a copy of the Destination which does not alias the buffer it
+// was read from.
+func (destination Destination) Clone() Destination {
+	return Destination{destination.KeysAndCert.Clone()}
+}
+
+// readDestination reads a Destination, returning the data after it
+func readDestination(data []byte, opts ...common.ParseOption) (destination Destination, remainder []byte, err error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(data),
 	}).Debug("Reading Destination from bytes")
 
-	keys_and_cert, remainder, err := ReadKeysAndCert(data)
+	keys_and_cert, n, err := ParseKeysAndCert(data, opts...)
+	remainder = data[n:]
 	destination = Destination{
 		keys_and_cert,
 	}
@@ -85,3 +118,23 @@ func ReadDestination(data []byte) (destination Destination, remainder []byte, er
 
 	return
 }
+
+// ParseDestinationBytes reads a Destination from the start of data and returns
+// it with the number of bytes consumed. The Destination aliases data unless
+// CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseDestinationBytes(data []byte, opts ...common.ParseOption) (Destination, int, error) {
+	value, remainder, err := readDestination(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadDestination returns Destination from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing. The Destination
+// aliases data unless CopyOnParse is given.
+//
+// Deprecated: use ParseDestinationBytes.
+func ReadDestination(data []byte, opts ...common.ParseOption) (Destination, []byte, error) {
+	return readDestination(data, opts...)
+}
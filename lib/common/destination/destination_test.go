@@ -0,0 +1,62 @@
+package destination
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDestination(t *testing.T) Destination {
+	public, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyCert, err := key_certificate.BuildKeyCertificate(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_ELG, nil)
+	require.NoError(t, err)
+	var elg crypto.ElgPublicKey
+	_, err = rand.Read(elg[:])
+	require.NoError(t, err)
+	kac, err := keys_and_cert.NewKeysAndCert(keyCert, elg, make([]byte, 96), crypto.Ed25519PublicKey(public))
+	require.NoError(t, err)
+	dest, _, err := ReadDestination(kac.Bytes())
+	require.NoError(t, err)
+	return dest
+}
+
+func TestDestinationAddresses(t *testing.T) {
+	dest := newTestDestination(t)
+	hash := common.HashData(dest.KeysAndCert.Bytes())
+	assert.Equal(t, hash, dest.Hash())
+	assert.Equal(t, hash.Base32Address(), dest.Base32Address())
+	assert.Len(t, dest.Base32Address(), 60)
+	assert.Equal(t, base64.EncodeToString(dest.KeysAndCert.Bytes()), dest.Base64())
+}
+
+func TestParseDestination(t *testing.T) {
+	dest := newTestDestination(t)
+
+	parsed, hash, err := ParseDestination(dest.Base64())
+	require.NoError(t, err)
+	require.NotNil(t, parsed)
+	assert.Equal(t, dest.Hash(), hash)
+	assert.Equal(t, dest.KeysAndCert.Bytes(), parsed.KeysAndCert.Bytes())
+
+	for _, address := range []string{dest.Base32Address(), " " + strings.ToUpper(dest.Base32Address()), dest.Hash().Base32()} {
+		parsed, hash, err = ParseDestination(address)
+		require.NoError(t, err, address)
+		assert.Nil(t, parsed, "a b32 address has no destination")
+		assert.Equal(t, dest.Hash(), hash)
+	}
+
+	for _, address := range []string{"", "not an address", dest.Base64()[:100], dest.Base64() + "AAAA"} {
+		_, _, err = ParseDestination(address)
+		assert.ErrorIs(t, err, ErrInvalidDestination, address)
+	}
+}
@@ -0,0 +1,84 @@
+package key_certificate
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	. "github.com/go-i2p/go-i2p/lib/common/certificate"
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+)
+
+var (
+	ErrUnknownSigningKeyType = errors.New("unknown key certificate signing key type")
+	ErrUnknownCryptoKeyType  = errors.New("unknown key certificate crypto key type")
+	ErrExcessKeyData         = errors.New("excess signing key data does not match the signing key type")
+)
+
+// cryptoKeySizes are the crypto public key sizes by KEYCERT_CRYPTO_* type
+var cryptoKeySizes = map[int]int{
+	KEYCERT_CRYPTO_ELG:    KEYCERT_CRYPTO_ELG_SIZE,
+	KEYCERT_CRYPTO_P256:   KEYCERT_CRYPTO_P256_SIZE,
+	KEYCERT_CRYPTO_P384:   KEYCERT_CRYPTO_P384_SIZE,
+	KEYCERT_CRYPTO_P521:   KEYCERT_CRYPTO_P521_SIZE,
+	KEYCERT_CRYPTO_X25519: KEYCERT_CRYPTO_X25519_SIZE,
+}
+
+// ExcessSigningKeySize returns how many bytes of a signing public key of
+// sigType do not fit in the 128 byte signing key field and are carried in the
+// key certificate instead. It is 0 for every type but P521 and RSA.
+func ExcessSigningKeySize(sigType int) (int, error) {
+	size, err := signature.PublicKeySize(sigType)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownSigningKeyType, sigType)
+	}
+	if size <= KEYCERT_SPK_SIZE {
+		return 0, nil
+	}
+	return size - KEYCERT_SPK_SIZE, nil
+}
+
+// BuildKeyCertificate returns a key certificate for the given KEYCERT_SIGN_*
+// and KEYCERT_CRYPTO_* types. excessSigningKeyData is the tail of signing
+// keys larger than the signing key field, see ExcessSigningKeySize, and must
+// be empty for smaller keys. The payload is laid out as the spec requires:
+//
+//	signing key type (2) | crypto key type (2) | excess signing key data
+//
+// No crypto key type is larger than its 256 byte field, so there is never
+// excess crypto key data.
+func BuildKeyCertificate(sigType, cryptoType int, excessSigningKeyData []byte) (*KeyCertificate, error) {
+	log.WithFields(logrus.Fields{
+		"sig_type":    sigType,
+		"crypto_type": cryptoType,
+		"excess_len":  len(excessSigningKeyData),
+	}).Debug("Building key certificate")
+	excess, err := ExcessSigningKeySize(sigType)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cryptoKeySizes[cryptoType]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownCryptoKeyType, cryptoType)
+	}
+	if len(excessSigningKeyData) != excess {
+		return nil, fmt.Errorf("%w: signing key type %d needs %d bytes, got %d",
+			ErrExcessKeyData, sigType, excess, len(excessSigningKeyData))
+	}
+
+	payload := make([]byte, 4, 4+excess)
+	binary.BigEndian.PutUint16(payload[0:2], uint16(sigType))
+	binary.BigEndian.PutUint16(payload[2:4], uint16(cryptoType))
+	payload = append(payload, excessSigningKeyData...)
+	cert, err := NewCertificateWithType(CERT_KEY, payload)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyCertificate{
+		Certificate: *cert,
+		SpkType:     Integer(payload[0:2]),
+		CpkType:     Integer(payload[2:4]),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package key_certificate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildKeyCertificate(t *testing.T) {
+	for _, tc := range []struct {
+		sigType, cryptoType int
+		payload             []byte
+	}{
+		{KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_X25519, []byte{0x00, 0x07, 0x00, 0x04}},
+		{KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_ELG, []byte{0x00, 0x07, 0x00, 0x00}},
+		{KEYCERT_SIGN_P256, KEYCERT_CRYPTO_X25519, []byte{0x00, 0x01, 0x00, 0x04}},
+	} {
+		keyCert, err := BuildKeyCertificate(tc.sigType, tc.cryptoType, nil)
+		require.NoError(t, err)
+		assert.Equal(t, tc.sigType, keyCert.SigningPublicKeyType())
+		assert.Equal(t, tc.cryptoType, keyCert.PublicKeyType())
+		assert.Equal(t, tc.payload, keyCert.Certificate.Data())
+
+		parsed, remainder, err := NewKeyCertificate(keyCert.Certificate.Bytes())
+		require.NoError(t, err)
+		assert.Empty(t, remainder)
+		assert.Equal(t, tc.sigType, parsed.SigningPublicKeyType())
+		assert.Equal(t, tc.cryptoType, parsed.PublicKeyType())
+	}
+}
+
+func TestBuildKeyCertificateExcessSigningKey(t *testing.T) {
+	excess, err := ExcessSigningKeySize(KEYCERT_SIGN_P521)
+	require.NoError(t, err)
+	assert.Equal(t, 4, excess)
+
+	tail := bytes.Repeat([]byte{0xaa}, excess)
+	keyCert, err := BuildKeyCertificate(KEYCERT_SIGN_P521, KEYCERT_CRYPTO_ELG, tail)
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{0x00, 0x03, 0x00, 0x00}, tail...), keyCert.Certificate.Data())
+
+	_, err = BuildKeyCertificate(KEYCERT_SIGN_P521, KEYCERT_CRYPTO_ELG, nil)
+	assert.ErrorIs(t, err, ErrExcessKeyData)
+	_, err = BuildKeyCertificate(KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_X25519, tail)
+	assert.ErrorIs(t, err, ErrExcessKeyData)
+	_, err = BuildKeyCertificate(99, KEYCERT_CRYPTO_X25519, nil)
+	assert.ErrorIs(t, err, ErrUnknownSigningKeyType)
+	_, err = BuildKeyCertificate(KEYCERT_SIGN_ED25519, 99, nil)
+	assert.ErrorIs(t, err, ErrUnknownCryptoKeyType)
+}
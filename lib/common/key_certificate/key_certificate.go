@@ -53,6 +53,7 @@ const (
 	KEYCERT_SIGN_RSA4096   = 6
 	KEYCERT_SIGN_ED25519   = 7
 	KEYCERT_SIGN_ED25519PH = 8
+	KEYCERT_SIGN_REDDSA    = 11
 )
 
 // Key Certificate Public Key Types
@@ -79,6 +80,7 @@ const (
 	KEYCERT_SIGN_RSA4096_SIZE   = 512
 	KEYCERT_SIGN_ED25519_SIZE   = 32
 	KEYCERT_SIGN_ED25519PH_SIZE = 32
+	KEYCERT_SIGN_REDDSA_SIZE    = 32
 )
 
 // publicKey sizes for Public Key Types
@@ -106,27 +108,33 @@ type KeyCertificate struct {
 // Data returns the raw []byte contained in the Certificate.
 func (keyCertificate KeyCertificate) Data() ([]byte, error) {
 	data := keyCertificate.Certificate.RawBytes()
-	log.WithFields(logrus.Fields{
-		"data_length": len(data),
-	}).Debug("Retrieved raw data from keyCertificate")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"data_length": len(data),
+		}).Trace("Retrieved raw data from keyCertificate")
+	}
 	return keyCertificate.Certificate.RawBytes(), nil
 }
 
 // SigningPublicKeyType returns the signingPublicKey type as a Go integer.
 func (keyCertificate KeyCertificate) SigningPublicKeyType() (signing_pubkey_type int) {
 	signing_pubkey_type = keyCertificate.SpkType.Int()
-	log.WithFields(logrus.Fields{
-		"signing_pubkey_type": signing_pubkey_type,
-	}).Debug("Retrieved signingPublicKey type")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"signing_pubkey_type": signing_pubkey_type,
+		}).Trace("Retrieved signingPublicKey type")
+	}
 	return keyCertificate.SpkType.Int()
 }
 
 // PublicKeyType returns the publicKey type as a Go integer.
 func (keyCertificate KeyCertificate) PublicKeyType() (pubkey_type int) {
 	pubkey_type = keyCertificate.CpkType.Int()
-	log.WithFields(logrus.Fields{
-		"pubkey_type": pubkey_type,
-	}).Debug("Retrieved publicKey type")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"pubkey_type": pubkey_type,
+		}).Trace("Retrieved publicKey type")
+	}
 	return keyCertificate.CpkType.Int()
 }
 
@@ -151,17 +159,18 @@ func (keyCertificate KeyCertificate) ConstructPublicKey(data []byte) (public_key
 		err = errors.New("error constructing public key: not enough data")
 		return
 	}
+	// the public key is left-aligned in the public key field, followed by padding
 	switch key_type {
 	case KEYCERT_CRYPTO_ELG:
 		var elg_key crypto.ElgPublicKey
-		copy(elg_key[:], data[KEYCERT_PUBKEY_SIZE-KEYCERT_CRYPTO_ELG_SIZE:KEYCERT_PUBKEY_SIZE])
+		copy(elg_key[:], data[:KEYCERT_CRYPTO_ELG_SIZE])
 		public_key = elg_key
 		log.Debug("Constructed ElgPublicKey")
 	case KEYCERT_CRYPTO_X25519:
-		var ed25519_key crypto.Ed25519PublicKey
-		copy(ed25519_key[:], data[KEYCERT_PUBKEY_SIZE-KEYCERT_CRYPTO_ELG_SIZE:KEYCERT_PUBKEY_SIZE])
-		public_key = ed25519_key
-		log.Debug("Constructed Ed25519PublicKey")
+		x25519_key := make(crypto.Curve25519PublicKey, KEYCERT_CRYPTO_X25519_SIZE)
+		copy(x25519_key, data[:KEYCERT_CRYPTO_X25519_SIZE])
+		public_key = x25519_key
+		log.Debug("Constructed Curve25519PublicKey")
 	default:
 		log.WithFields(logrus.Fields{
 			"key_type": key_type,
@@ -183,6 +192,11 @@ var CryptoPublicKeySizes = map[uint16]int{
 	CRYPTO_KEY_TYPE_ELGAMAL: 256,
 }
 
+// SignaturePublicKeySizes holds the signing public key sizes of DSA and
+// Ed25519 only.
+//
+// Deprecated: use signature.PublicKeySize, which covers every registered
+// signature type.
 var SignaturePublicKeySizes = map[uint16]int{
 	SIGNATURE_TYPE_DSA_SHA1:       128,
 	SIGNATURE_TYPE_ED25519_SHA512: 32,
@@ -196,30 +210,14 @@ func (keyCertificate *KeyCertificate) CryptoPublicKeySize() (int, error) {
 	return size, nil
 }
 
+// SigningPublicKeySize returns the size of the signing public key, 128 for
+// unknown types.
 func (keyCertificate *KeyCertificate) SigningPublicKeySize() int {
-	spk_type := keyCertificate.SpkType
-	switch spk_type.Int() {
-	case SIGNATURE_TYPE_DSA_SHA1:
-		log.Debug("Returning DSA_SHA1")
-		return 128
-	case signature.SIGNATURE_TYPE_ECDSA_SHA256_P256:
-		log.Debug("Returning ECDSA_SHA256_P256")
-		return 64
-	case signature.SIGNATURE_TYPE_ECDSA_SHA384_P384:
-		return 96
-	case signature.SIGNATURE_TYPE_ECDSA_SHA512_P521:
-		return 132
-	case signature.SIGNATURE_TYPE_RSA_SHA256_2048:
-		return 256
-	case signature.SIGNATURE_TYPE_RSA_SHA384_3072:
-		return 384
-	case signature.SIGNATURE_TYPE_RSA_SHA512_4096:
-		return 512
-	case SIGNATURE_TYPE_ED25519_SHA512:
-		return 32
-	default:
-		return 128
+	size, err := signature.PublicKeySize(keyCertificate.SpkType.Int())
+	if err != nil {
+		return KEYCERT_SPK_SIZE
 	}
+	return size
 }
 
 // ConstructSigningPublicKey returns a SingingPublicKey constructed using any excess data that may be stored in the KeyCertificate.
@@ -243,92 +241,40 @@ func (keyCertificate KeyCertificate) ConstructSigningPublicKey(data []byte) (sig
 		err = errors.New("error constructing signing public key: not enough data")
 		return
 	}
-	switch signing_key_type {
-	case KEYCERT_SIGN_DSA_SHA1:
-		var dsa_key crypto.DSAPublicKey
-		copy(dsa_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_DSA_SHA1_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = dsa_key
-		log.Debug("Constructed DSAPublicKey")
-	case KEYCERT_SIGN_P256:
-		var ec_p256_key crypto.ECP256PublicKey
-		copy(ec_p256_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_P256_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = ec_p256_key
-		log.Debug("Constructed P256PublicKey")
-	case KEYCERT_SIGN_P384:
-		var ec_p384_key crypto.ECP384PublicKey
-		copy(ec_p384_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_P384_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = ec_p384_key
-		log.Debug("Constructed P384PublicKey")
-	case KEYCERT_SIGN_P521:
-		/*var ec_p521_key crypto.ECP521PublicKey
-		copy(ec_p521_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_P521_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = ec_p521_key
-		log.Debug("Constructed P521PublicKey")*/
-		panic("unimplemented P521SigningPublicKey")
-	case KEYCERT_SIGN_RSA2048:
-		/*var rsa2048_key crypto.RSA2048PublicKey
-		copy(rsa2048_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_RSA2048_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = rsa2048_key
-		log.Debug("Constructed RSA2048PublicKey")*/
-		panic("unimplemented RSA2048SigningPublicKey")
-	case KEYCERT_SIGN_RSA3072:
-		/*var rsa3072_key crypto.RSA3072PublicKey
-		copy(rsa3072_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_RSA3072_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = rsa3072_key
-		log.Debug("Constructed RSA3072PublicKey")*/
-		panic("unimplemented RSA3072SigningPublicKey")
-	case KEYCERT_SIGN_RSA4096:
-		/*var rsa4096_key crypto.RSA4096PublicKey
-		copy(rsa4096_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_RSA4096_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = rsa4096_key
-		log.Debug("Constructed RSA4096PublicKey")*/
-		panic("unimplemented RSA4096SigningPublicKey")
-	case KEYCERT_SIGN_ED25519:
-		var ed25519_key crypto.Ed25519PublicKey
-		copy(ed25519_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_ED25519_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = ed25519_key
-		log.Debug("Constructed Ed25519PublicKey")
-	case KEYCERT_SIGN_ED25519PH:
-		var ed25519ph_key crypto.Ed25519PublicKey
-		copy(ed25519ph_key[:], data[KEYCERT_SPK_SIZE-KEYCERT_SIGN_ED25519PH_SIZE:KEYCERT_SPK_SIZE])
-		signing_public_key = ed25519ph_key
-		log.Debug("Constructed Ed25519PHPublicKey")
-	default:
+	// the signing public key is right-aligned in the signing key field, preceded by padding
+	key_data := data[data_len-keyCertificate.SignatureSize():]
+	signing_public_key, err = signature.NewSigningPublicKey(signing_key_type, key_data)
+	if err != nil {
 		log.WithFields(logrus.Fields{
 			"signing_key_type": signing_key_type,
-		}).Warn("Unknown signing key type")
-		panic(err)
+		}).Warn("Unsupported signing key type")
+		err = fmt.Errorf("error constructing signing public key: %w", err)
+		return
 	}
+	log.WithField("signing_key_type", signing_key_type).Debug("Constructed signingPublicKey")
 
 	return
 }
 
-// SignatureSize return the size of a Signature corresponding to the Key Certificate's signingPublicKey type.
+// SignatureSize returns the size of the signing public key of the Key
+// Certificate's signing key type, 0 for unknown types. Despite its name it is
+// not the size of a signature, see signature.SignatureSize for that.
 func (keyCertificate KeyCertificate) SignatureSize() (size int) {
-	sizes := map[int]int{
-		KEYCERT_SIGN_DSA_SHA1:  KEYCERT_SIGN_DSA_SHA1_SIZE,
-		KEYCERT_SIGN_P256:      KEYCERT_SIGN_P256_SIZE,
-		KEYCERT_SIGN_P384:      KEYCERT_SIGN_P384_SIZE,
-		KEYCERT_SIGN_P521:      KEYCERT_SIGN_P521_SIZE,
-		KEYCERT_SIGN_RSA2048:   KEYCERT_SIGN_RSA2048_SIZE,
-		KEYCERT_SIGN_RSA3072:   KEYCERT_SIGN_RSA3072_SIZE,
-		KEYCERT_SIGN_RSA4096:   KEYCERT_SIGN_RSA4096_SIZE,
-		KEYCERT_SIGN_ED25519:   KEYCERT_SIGN_ED25519_SIZE,
-		KEYCERT_SIGN_ED25519PH: KEYCERT_SIGN_ED25519PH_SIZE,
-	}
 	key_type := keyCertificate.SigningPublicKeyType()
-	size, exists := sizes[key_type]
-	if !exists {
+	size, err := signature.PublicKeySize(key_type)
+	if err != nil {
 		log.WithFields(logrus.Fields{
 			"key_type": key_type,
 		}).Warn("Unknown signing key type")
-		return 0 // Or handle error appropriately
+		return 0
 	}
-	log.WithFields(logrus.Fields{
-		"key_type":       key_type,
-		"signature_size": size,
-	}).Debug("Retrieved signature size")
-	return sizes[int(key_type)]
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"key_type":       key_type,
+			"signature_size": size,
+		}).Trace("Retrieved signature size")
+	}
+	return size
 }
 
 // CryptoSize return the size of a Public Key corresponding to the Key Certificate's publicKey type.
@@ -342,32 +288,40 @@ func (keyCertificate KeyCertificate) CryptoSize() (size int) {
 	}
 	key_type := keyCertificate.PublicKeyType()
 	size = sizes[int(key_type)]
-	log.WithFields(logrus.Fields{
-		"key_type":    key_type,
-		"crypto_size": size,
-	}).Debug("Retrieved crypto size")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"key_type":    key_type,
+			"crypto_size": size,
+		}).Trace("Retrieved crypto size")
+	}
 	return sizes[int(key_type)]
 }
 
-// NewKeyCertificate creates a new *KeyCertificate from []byte using ReadCertificate.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func NewKeyCertificate(bytes []byte) (key_certificate *KeyCertificate, remainder []byte, err error) {
+// newKeyCertificate reads a KeyCertificate, returning the data after it
+func newKeyCertificate(bytes []byte) (key_certificate *KeyCertificate, remainder []byte, err error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(bytes),
 	}).Debug("Creating new keyCertificate")
 
 	var certificate Certificate
-	certificate, remainder, err = ReadCertificate(bytes)
+	var n int
+	certificate, n, err = ParseCertificate(bytes)
+	remainder = bytes[n:]
 	if err != nil {
 		log.WithError(err).Error("Failed to read Certificate")
 		return
 	}
 
 	payload := certificate.Data()
+	if len(payload) < 4 {
+		err = errors.New("key certificate payload too short")
+		log.WithError(err).Error("Failed to read key types from Certificate")
+		return
+	}
 
-	cpkTypeBytes := payload[0:2]
-	spkTypeBytes := payload[2:4]
+	// the signing key type precedes the crypto key type
+	spkTypeBytes := payload[0:2]
+	cpkTypeBytes := payload[2:4]
 
 	cpkType := Integer(cpkTypeBytes)
 	spkType := Integer(spkTypeBytes)
@@ -387,30 +341,59 @@ func NewKeyCertificate(bytes []byte) (key_certificate *KeyCertificate, remainder
 	return
 }
 
+// ParseKeyCertificate reads a KeyCertificate from the start of data and
+// returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseKeyCertificate(data []byte) (KeyCertificate, int, error) {
+	key_certificate, remainder, err := newKeyCertificate(data)
+	var value KeyCertificate
+	if key_certificate != nil {
+		value = *key_certificate
+	}
+	return value, len(data) - len(remainder), err
+}
+
+// NewKeyCertificate creates a new *KeyCertificate from []byte using ReadCertificate.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing.
+//
+// Deprecated: use ParseKeyCertificate.
+func NewKeyCertificate(bytes []byte) (*KeyCertificate, []byte, error) {
+	return newKeyCertificate(bytes)
+}
+
+// Clone returns a copy of the KeyCertificate which does not alias the buffer
+// it was read from, nil if keyCertificate is nil.
+func (keyCertificate *KeyCertificate) Clone() *KeyCertificate {
+	if keyCertificate == nil {
+		return nil
+	}
+	return &KeyCertificate{
+		Certificate: keyCertificate.Certificate.Clone(),
+		SpkType:     keyCertificate.SpkType.Clone(),
+		CpkType:     keyCertificate.CpkType.Clone(),
+	}
+}
+
 func KeyCertificateFromCertificate(cert Certificate) (*KeyCertificate, error) {
 	if cert.Type() != CERT_KEY {
 		return nil, fmt.Errorf("expected Key Certificate type, got %d", cert.Type())
 	}
 
 	data := cert.Data()
-	fmt.Printf("Certificate Data Length in KeyCertificateFromCertificate: %d\n", len(data))
-	fmt.Printf("Certificate Data Bytes in KeyCertificateFromCertificate: %v\n", data)
-
 	if len(data) < 4 {
 		return nil, fmt.Errorf("certificate payload too short in KeyCertificateFromCertificate")
 	}
 
-	cpkTypeBytes := data[0:2]
-	spkTypeBytes := data[2:4]
-
-	fmt.Printf("cpkTypeBytes in KeyCertificateFromCertificate: %v\n", cpkTypeBytes)
-	fmt.Printf("spkTypeBytes in KeyCertificateFromCertificate: %v\n", spkTypeBytes)
-
-	cpkType := Integer(cpkTypeBytes)
-	spkType := Integer(spkTypeBytes)
+	// the signing key type precedes the crypto key type
+	spkType := Integer(data[0:2])
+	cpkType := Integer(data[2:4])
 
-	fmt.Printf("cpkType (Int) in KeyCertificateFromCertificate: %d\n", cpkType.Int())
-	fmt.Printf("spkType (Int) in KeyCertificateFromCertificate: %d\n", spkType.Int())
+	log.WithFields(logrus.Fields{
+		"spk_type": spkType.Int(),
+		"cpk_type": cpkType.Int(),
+	}).Debug("Read key types from Certificate")
 
 	keyCert := &KeyCertificate{
 		Certificate: cert,
@@ -1,8 +1,10 @@
 package key_certificate
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
 )
 
 /*
@@ -126,6 +128,33 @@ func TestConstructSigningPublicKeyWithP384(t *testing.T) {
 	assert.Equal(spk.Len(), KEYCERT_SIGN_P384_SIZE, "ConstructSigningPublicKey() with P384 returned incorrect signingPublicKey length")
 }
 
+func TestConstructSigningPublicKeyWithEd25519(t *testing.T) {
+	assert := assert.New(t)
+
+	key_cert, err := BuildKeyCertificate(KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_X25519, nil)
+	assert.Nil(err)
+	priv, err := crypto.GenerateEd25519PrivateKey()
+	assert.Nil(err)
+	public, err := priv.Public()
+	assert.Nil(err)
+	// the key is right-aligned in the signing key field
+	data := make([]byte, KEYCERT_SPK_SIZE)
+	copy(data[KEYCERT_SPK_SIZE-public.Len():], public.Bytes())
+
+	spk, err := key_cert.ConstructSigningPublicKey(data)
+	assert.Nil(err, "ConstructSigningPublicKey() with Ed25519 returned err on valid data")
+	assert.Equal(KEYCERT_SIGN_ED25519_SIZE, spk.Len())
+	assert.Equal(public.Bytes(), spk.Bytes())
+
+	signer, err := priv.NewSigner()
+	assert.Nil(err)
+	sig, err := signer.Sign([]byte("destination"))
+	assert.Nil(err)
+	verifier, err := spk.NewVerifier()
+	assert.Nil(err)
+	assert.Nil(verifier.Verify([]byte("destination"), sig))
+}
+
 /*
 func TestConstructSigningPublicKeyWithP521(t *testing.T) {
 	assert := assert.New(t)
@@ -138,3 +167,18 @@ func TestConstructSigningPublicKeyWithP521(t *testing.T) {
 	assert.Equal(spk.Len(), KEYCERT_SIGN_P521_SIZE, "ConstructSigningPublicKey() with P521 returned incorrect signingPublicKey length")
 }
 */ //TODO -> Before implementing this test, we need to implement P521 first.
+
+func TestParseKeyCertificate(t *testing.T) {
+	assert := assert.New(t)
+
+	data := []byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04, 0xff}
+	keyCert, consumed, err := ParseKeyCertificate(data)
+	assert.Nil(err)
+	assert.Equal(7, consumed)
+	assert.Equal(KEYCERT_SIGN_ED25519, keyCert.SigningPublicKeyType())
+	assert.Equal(KEYCERT_CRYPTO_X25519, keyCert.PublicKeyType())
+
+	_, consumed, err = ParseKeyCertificate([]byte{0x05, 0x00, 0x02, 0x00, 0x07})
+	assert.NotNil(err, "a payload without both key types should be reported")
+	assert.Equal(5, consumed)
+}
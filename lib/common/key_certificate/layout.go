@@ -0,0 +1,89 @@
+package key_certificate
+
+import (
+	"fmt"
+
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+)
+
+// KeyLayout describes where the keys of a KeysAndCert are stored, given the
+// types in its key certificate. The 384 bytes of key data hold the crypto
+// public key left-aligned in its 256 byte field, then padding, then the
+// signing public key right-aligned in its 128 byte field. Signing keys longer
+// than their field continue in the key certificate payload.
+//
+//	crypto key | padding | signing key  (384 bytes)  | cert ... excess signing key
+type KeyLayout struct {
+	// CryptoKeyLength is the length of the crypto public key at offset 0
+	CryptoKeyLength int
+	// PaddingLength is the length of the padding after the crypto key
+	PaddingLength int
+	// SigningKeyLength is the full length of the signing public key
+	SigningKeyLength int
+	// ExcessSigningKeyLength is the part of the signing key stored in the
+	// key certificate after the key types
+	ExcessSigningKeyLength int
+}
+
+// PaddingOffset returns the offset of the padding in the key data.
+func (layout KeyLayout) PaddingOffset() int {
+	return layout.CryptoKeyLength
+}
+
+// SigningKeyOffset returns the offset of the signing public key, or of its
+// first part for keys with excess data, in the key data.
+func (layout KeyLayout) SigningKeyOffset() int {
+	return layout.CryptoKeyLength + layout.PaddingLength
+}
+
+// CryptoPublicKeyLength returns the length of the crypto public key of the
+// certificate's crypto key type.
+func (keyCertificate KeyCertificate) CryptoPublicKeyLength() (int, error) {
+	size, ok := cryptoKeySizes[keyCertificate.PublicKeyType()]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownCryptoKeyType, keyCertificate.PublicKeyType())
+	}
+	return size, nil
+}
+
+// SigningPublicKeyLength returns the full length of the signing public key of
+// the certificate's signing key type, including any excess data.
+func (keyCertificate KeyCertificate) SigningPublicKeyLength() (int, error) {
+	size, err := signature.PublicKeySize(keyCertificate.SigningPublicKeyType())
+	if err != nil {
+		return 0, fmt.Errorf("%w: %d", ErrUnknownSigningKeyType, keyCertificate.SigningPublicKeyType())
+	}
+	return size, nil
+}
+
+// ExcessSigningKeyData returns the part of the signing public key carried in
+// the certificate payload, empty for keys that fit the signing key field.
+func (keyCertificate KeyCertificate) ExcessSigningKeyData() ([]byte, error) {
+	excess, err := ExcessSigningKeySize(keyCertificate.SigningPublicKeyType())
+	if err != nil {
+		return nil, err
+	}
+	payload := keyCertificate.Certificate.Data()
+	if len(payload) < 4+excess {
+		return nil, fmt.Errorf("%w: signing key type %d needs %d bytes, got %d",
+			ErrExcessKeyData, keyCertificate.SigningPublicKeyType(), excess, len(payload)-4)
+	}
+	return payload[4 : 4+excess], nil
+}
+
+// Layout returns where the keys of a KeysAndCert with this certificate are
+// stored.
+func (keyCertificate KeyCertificate) Layout() (layout KeyLayout, err error) {
+	if layout.CryptoKeyLength, err = keyCertificate.CryptoPublicKeyLength(); err != nil {
+		return
+	}
+	if layout.SigningKeyLength, err = keyCertificate.SigningPublicKeyLength(); err != nil {
+		return
+	}
+	if layout.ExcessSigningKeyLength, err = ExcessSigningKeySize(keyCertificate.SigningPublicKeyType()); err != nil {
+		return
+	}
+	inline := layout.SigningKeyLength - layout.ExcessSigningKeyLength
+	layout.PaddingLength = KEYCERT_PUBKEY_SIZE + KEYCERT_SPK_SIZE - layout.CryptoKeyLength - inline
+	return
+}
@@ -0,0 +1,54 @@
+package key_certificate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyCertificateLayout(t *testing.T) {
+	keyCert, err := BuildKeyCertificate(KEYCERT_SIGN_ED25519, KEYCERT_CRYPTO_X25519, nil)
+	require.NoError(t, err)
+	layout, err := keyCert.Layout()
+	require.NoError(t, err)
+	assert.Equal(t, KeyLayout{
+		CryptoKeyLength:  32,
+		PaddingLength:    384 - 32 - 32,
+		SigningKeyLength: 32,
+	}, layout)
+	assert.Equal(t, 32, layout.PaddingOffset())
+	assert.Equal(t, 352, layout.SigningKeyOffset())
+
+	excess := bytes.Repeat([]byte{0xee}, 4)
+	keyCert, err = BuildKeyCertificate(KEYCERT_SIGN_P521, KEYCERT_CRYPTO_ELG, excess)
+	require.NoError(t, err)
+	layout, err = keyCert.Layout()
+	require.NoError(t, err)
+	assert.Equal(t, KeyLayout{
+		CryptoKeyLength:        256,
+		PaddingLength:          0,
+		SigningKeyLength:       132,
+		ExcessSigningKeyLength: 4,
+	}, layout)
+	data, err := keyCert.ExcessSigningKeyData()
+	require.NoError(t, err)
+	assert.Equal(t, excess, data)
+
+	length, err := keyCert.CryptoPublicKeyLength()
+	require.NoError(t, err)
+	assert.Equal(t, 256, length)
+	length, err = keyCert.SigningPublicKeyLength()
+	require.NoError(t, err)
+	assert.Equal(t, 132, length)
+}
+
+func TestKeyCertificateLayoutUnknownType(t *testing.T) {
+	keyCert, _, err := NewKeyCertificate([]byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x63})
+	require.NoError(t, err)
+	_, err = keyCert.Layout()
+	assert.ErrorIs(t, err, ErrUnknownCryptoKeyType)
+	_, err = keyCert.SigningPublicKeyLength()
+	assert.NoError(t, err)
+}
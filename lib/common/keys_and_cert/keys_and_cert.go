@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	common "github.com/go-i2p/go-i2p/lib/common/data"
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 
 	. "github.com/go-i2p/go-i2p/lib/common/certificate"
@@ -91,15 +92,17 @@ func (keys_and_cert KeysAndCert) Bytes() []byte {
 	bytes = append(bytes, keys_and_cert.Padding...)
 	bytes = append(bytes, keys_and_cert.signingPublicKey.Bytes()...)
 	bytes = append(bytes, keys_and_cert.KeyCertificate.Bytes()...)
-	log.WithFields(logrus.Fields{
-		"bytes":                bytes,
-		"padding":              keys_and_cert.Padding,
-		"bytes_length":         len(bytes),
-		"pk_bytes_length":      len(keys_and_cert.publicKey.Bytes()),
-		"padding_bytes_length": len(keys_and_cert.Padding),
-		"spk_bytes_length":     len(keys_and_cert.signingPublicKey.Bytes()),
-		"cert_bytes_length":    len(keys_and_cert.KeyCertificate.Bytes()),
-	}).Debug("Retrieved bytes from KeysAndCert")
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"bytes":                bytes,
+			"padding":              keys_and_cert.Padding,
+			"bytes_length":         len(bytes),
+			"pk_bytes_length":      len(keys_and_cert.publicKey.Bytes()),
+			"padding_bytes_length": len(keys_and_cert.Padding),
+			"spk_bytes_length":     len(keys_and_cert.signingPublicKey.Bytes()),
+			"cert_bytes_length":    len(keys_and_cert.KeyCertificate.Bytes()),
+		}).Trace("Retrieved bytes from KeysAndCert")
+	}
 	return bytes
 }
 
@@ -118,9 +121,21 @@ func (keys_and_cert *KeysAndCert) Certificate() (cert Certificate) {
 	return keys_and_cert.KeyCertificate.Certificate
 }
 
-// ReadKeysAndCert creates a new *KeysAndCert from []byte using ReadKeysAndCert.
-// Returns a pointer to KeysAndCert unlike ReadKeysAndCert.
-func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte, err error) {
+// Clone returns a copy of the KeysAndCert which does not alias the buffer it
+// was read from. The keys are copied when read and never changed, the copy
+// shares them.
+func (keys_and_cert KeysAndCert) Clone() KeysAndCert {
+	clone := keys_and_cert
+	clone.KeyCertificate = keys_and_cert.KeyCertificate.Clone()
+	if keys_and_cert.Padding != nil {
+		clone.Padding = append([]byte(nil), keys_and_cert.Padding...)
+	}
+	return clone
+}
+
+// readKeysAndCert reads a KeysAndCert, returning the data after it
+func readKeysAndCert(data []byte, opts ...common.ParseOption) (keys_and_cert KeysAndCert, remainder []byte, err error) {
+	data = common.ParseBuffer(data, opts...)
 	log.WithFields(logrus.Fields{
 		"input_length": len(data),
 	}).Debug("Reading KeysAndCert from data")
@@ -137,34 +152,44 @@ func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte,
 		return
 	}
 
-	keys_and_cert.KeyCertificate, remainder, err = NewKeyCertificate(data[KEYS_AND_CERT_DATA_SIZE:])
+	keyCertificate, n, err := ParseKeyCertificate(data[KEYS_AND_CERT_DATA_SIZE:])
+	remainder = data[KEYS_AND_CERT_DATA_SIZE+n:]
 	if err != nil {
 		log.WithError(err).Error("Failed to create keyCertificate")
 		return
 	}
+	keys_and_cert.KeyCertificate = &keyCertificate
 
-	// Get the actual key sizes from the certificate
-	pubKeySize := keys_and_cert.KeyCertificate.CryptoSize()
-	sigKeySize := keys_and_cert.KeyCertificate.SignatureSize()
+	layout, err := keys_and_cert.KeyCertificate.Layout()
+	if err != nil {
+		log.WithError(err).Error("Unsupported key types in keyCertificate")
+		return
+	}
 
 	// Construct public key
-	keys_and_cert.publicKey, err = keys_and_cert.KeyCertificate.ConstructPublicKey(data[:pubKeySize])
+	keys_and_cert.publicKey, err = keys_and_cert.KeyCertificate.ConstructPublicKey(data[:layout.CryptoKeyLength])
 	if err != nil {
 		log.WithError(err).Error("Failed to construct publicKey")
 		return
 	}
 
-	// Calculate padding size and extract padding
-	paddingSize := KEYS_AND_CERT_DATA_SIZE - pubKeySize - sigKeySize
-	if paddingSize > 0 {
-		keys_and_cert.Padding = make([]byte, paddingSize)
-		copy(keys_and_cert.Padding, data[pubKeySize:pubKeySize+paddingSize])
+	if layout.PaddingLength > 0 {
+		keys_and_cert.Padding = make([]byte, layout.PaddingLength)
+		copy(keys_and_cert.Padding, data[layout.PaddingOffset():layout.SigningKeyOffset()])
 	}
 
-	// Construct signing public key
-	keys_and_cert.signingPublicKey, err = keys_and_cert.KeyCertificate.ConstructSigningPublicKey(
-		data[KEYS_AND_CERT_DATA_SIZE-sigKeySize : KEYS_AND_CERT_DATA_SIZE],
-	)
+	// Construct signing public key, longer keys continue in the certificate
+	signingKeyData := data[layout.SigningKeyOffset():KEYS_AND_CERT_DATA_SIZE]
+	if layout.ExcessSigningKeyLength > 0 {
+		excess, excessErr := keys_and_cert.KeyCertificate.ExcessSigningKeyData()
+		if excessErr != nil {
+			err = excessErr
+			log.WithError(err).Error("Failed to read excess signing key data")
+			return
+		}
+		signingKeyData = append(append([]byte(nil), signingKeyData...), excess...)
+	}
+	keys_and_cert.signingPublicKey, err = keys_and_cert.KeyCertificate.ConstructSigningPublicKey(signingKeyData)
 	if err != nil {
 		log.WithError(err).Error("Failed to construct signingPublicKey")
 		return
@@ -180,6 +205,30 @@ func ReadKeysAndCert(data []byte) (keys_and_cert KeysAndCert, remainder []byte,
 	return
 }
 
+// ParseKeysAndCert reads a KeysAndCert from the start of data and returns it
+// with the number of bytes consumed. The certificate aliases data unless
+// CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseKeysAndCert(data []byte, opts ...common.ParseOption) (KeysAndCert, int, error) {
+	value, remainder, err := readKeysAndCert(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadKeysAndCert creates a new *KeysAndCert from []byte using ReadKeysAndCert.
+// Returns a pointer to KeysAndCert unlike ReadKeysAndCert. The certificate
+// aliases data unless CopyOnParse is given.
+//
+// Deprecated: use ParseKeysAndCert.
+func ReadKeysAndCert(data []byte, opts ...common.ParseOption) (KeysAndCert, []byte, error) {
+	return readKeysAndCert(data, opts...)
+}
+
+// ReadKeysAndCertElgAndEd25519 reads a KeysAndCert with an ElGamal crypto key
+// and an Ed25519 signing key.
+//
+// Deprecated: use ParseKeysAndCert, which reads any key types and returns a
+// value like the other readers.
 func ReadKeysAndCertElgAndEd25519(data []byte) (keysAndCert *KeysAndCert, remainder []byte, err error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(data),
@@ -232,11 +281,13 @@ func ReadKeysAndCertElgAndEd25519(data []byte) (keysAndCert *KeysAndCert, remain
 
 	// Extract the certificate
 	certData := data[totalKeySize:]
-	keysAndCert.KeyCertificate, remainder, err = NewKeyCertificate(certData)
+	keyCertificate, n, err := ParseKeyCertificate(certData)
+	remainder = certData[n:]
 	if err != nil {
 		log.WithError(err).Error("Failed to read keyCertificate")
 		return
 	}
+	keysAndCert.KeyCertificate = &keyCertificate
 
 	log.WithFields(logrus.Fields{
 		"public_key_type":         "ElGamal",
@@ -278,6 +329,7 @@ func constructSigningPublicKey(data []byte, sigType uint16) (crypto.SigningPubli
 
 // NewKeysAndCert creates a new KeysAndCert instance with the provided parameters.
 // It validates the sizes of the provided keys and padding before assembling the struct.
+// The padding is copied, so later changes to it do not change the signed bytes.
 func NewKeysAndCert(
 	keyCertificate *KeyCertificate,
 	publicKey crypto.PublicKey,
@@ -291,9 +343,13 @@ func NewKeysAndCert(
 		return nil, errors.New("KeyCertificate cannot be nil")
 	}
 
-	// Get actual key sizes from certificate
-	pubKeySize := keyCertificate.CryptoSize()
-	sigKeySize := keyCertificate.SignatureSize()
+	layout, err := keyCertificate.Layout()
+	if err != nil {
+		log.WithError(err).Error("Unsupported key types in keyCertificate")
+		return nil, err
+	}
+	pubKeySize := layout.CryptoKeyLength
+	sigKeySize := layout.SigningKeyLength
 
 	// Validate public key size
 	if publicKey.Len() != pubKeySize {
@@ -313,8 +369,7 @@ func NewKeysAndCert(
 		return nil, fmt.Errorf("signingPublicKey has invalid size: expected %d, got %d", sigKeySize, signingPublicKey.Len())
 	}
 
-	// Calculate expected padding size
-	expectedPaddingSize := KEYS_AND_CERT_DATA_SIZE - pubKeySize - sigKeySize
+	expectedPaddingSize := layout.PaddingLength
 	if len(padding) != expectedPaddingSize {
 		log.WithFields(logrus.Fields{
 			"expected_size": expectedPaddingSize,
@@ -326,7 +381,7 @@ func NewKeysAndCert(
 	keysAndCert := &KeysAndCert{
 		KeyCertificate:   keyCertificate,
 		publicKey:        publicKey,
-		Padding:          padding,
+		Padding:          append([]byte{}, padding...),
 		signingPublicKey: signingPublicKey,
 	}
 
@@ -0,0 +1,24 @@
+package keys_and_cert
+
+import (
+	"io"
+
+	. "github.com/go-i2p/go-i2p/lib/common/certificate"
+)
+
+// ReadKeysAndCertFrom reads a KeysAndCert off r, reading exactly its bytes.
+func ReadKeysAndCertFrom(r io.Reader) (keys_and_cert KeysAndCert, err error) {
+	data := make([]byte, KEYS_AND_CERT_DATA_SIZE)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return
+	}
+	cert, err := ReadCertificateFrom(r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return
+	}
+	keys_and_cert, _, err = readKeysAndCert(append(data, cert.Bytes()...))
+	return
+}
@@ -0,0 +1,24 @@
+package keys_and_cert
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadKeysAndCertFrom(t *testing.T) {
+	keysAndCert := createValidKeyAndCert(t)
+	data := keysAndCert.Bytes()
+
+	stream := bytes.NewReader(append(append([]byte(nil), data...), 0x01))
+	read, err := ReadKeysAndCertFrom(stream)
+	require.NoError(t, err)
+	assert.Equal(t, data, read.Bytes())
+	assert.Equal(t, 1, stream.Len())
+
+	_, err = ReadKeysAndCertFrom(bytes.NewReader(data[:len(data)-1]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
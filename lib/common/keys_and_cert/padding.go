@@ -0,0 +1,79 @@
+package keys_and_cert
+
+import (
+	"crypto/rand"
+	"errors"
+
+	. "github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// length of the random pattern repeated to fill the padding
+const PADDING_PATTERN_SIZE = 32
+
+var ErrInvalidPaddingSize = errors.New("invalid padding size")
+
+/*
+Keys shorter than their fields, such as 32 byte X25519 and Ed25519 keys, leave
+the rest of the 384 bytes of key data to padding. The padding is part of the
+identity: it is hashed into the identity hash and covered by every signature
+over the structure. It is generated once, when the keys are created, and the
+same bytes have to be written every time the KeysAndCert is serialized.
+
+GenerateKeysAndCert generates the padding and keeps it with the keys, so
+Bytes always returns the signed bytes. KeyPadding returns a copy of it to
+store alongside the private keys, and NewKeysAndCert takes it back to rebuild
+an identical KeysAndCert.
+*/
+
+// GeneratePadding returns size bytes of a random 32 byte pattern repeated, as
+// recommended since 0.9.57 so the padding compresses well.
+func GeneratePadding(size int) ([]byte, error) {
+	if size < 0 {
+		return nil, ErrInvalidPaddingSize
+	}
+	padding := make([]byte, size)
+	if size == 0 {
+		return padding, nil
+	}
+	pattern := make([]byte, PADDING_PATTERN_SIZE)
+	if _, err := rand.Read(pattern); err != nil {
+		return nil, err
+	}
+	for i := 0; i < size; i += len(pattern) {
+		copy(padding[i:], pattern)
+	}
+	return padding, nil
+}
+
+// GenerateKeysAndCert creates a KeysAndCert for newly created keys,
+// generating the padding its key certificate calls for.
+func GenerateKeysAndCert(
+	keyCertificate *KeyCertificate,
+	publicKey crypto.PublicKey,
+	signingPublicKey crypto.SigningPublicKey,
+) (*KeysAndCert, error) {
+	if keyCertificate == nil {
+		return nil, errors.New("KeyCertificate cannot be nil")
+	}
+	layout, err := keyCertificate.Layout()
+	if err != nil {
+		return nil, err
+	}
+	padding, err := GeneratePadding(layout.PaddingLength)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"padding_length": len(padding),
+	}).Debug("Generated KeysAndCert padding")
+	return NewKeysAndCert(keyCertificate, publicKey, padding, signingPublicKey)
+}
+
+// KeyPadding returns a copy of the padding between the keys. Passing it to
+// NewKeysAndCert with the same keys and certificate reproduces the same
+// bytes, and the same identity hash.
+func (keys_and_cert *KeysAndCert) KeyPadding() []byte {
+	return append([]byte{}, keys_and_cert.Padding...)
+}
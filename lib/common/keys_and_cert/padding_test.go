@@ -0,0 +1,70 @@
+package keys_and_cert
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generatePaddedKeysAndCert(t *testing.T) *KeysAndCert {
+	keyCert, err := key_certificate.BuildKeyCertificate(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519, nil)
+	require.NoError(t, err)
+	publicKey := make([]byte, 32)
+	signingPublicKey := make([]byte, 32)
+	rand.Read(publicKey)
+	rand.Read(signingPublicKey)
+	keysAndCert, err := GenerateKeysAndCert(keyCert, crypto.Curve25519PublicKey(publicKey), crypto.Ed25519PublicKey(signingPublicKey))
+	require.NoError(t, err)
+	return keysAndCert
+}
+
+func TestGeneratePadding(t *testing.T) {
+	padding, err := GeneratePadding(320)
+	require.NoError(t, err)
+	require.Len(t, padding, 320)
+	for i := PADDING_PATTERN_SIZE; i < len(padding); i += PADDING_PATTERN_SIZE {
+		assert.Equal(t, padding[:PADDING_PATTERN_SIZE], padding[i:i+PADDING_PATTERN_SIZE])
+	}
+
+	_, err = GeneratePadding(-1)
+	assert.ErrorIs(t, err, ErrInvalidPaddingSize)
+}
+
+func TestKeysAndCertPaddingIsStable(t *testing.T) {
+	keysAndCert := generatePaddedKeysAndCert(t)
+	padding := keysAndCert.KeyPadding()
+	require.Len(t, padding, 384-32-32)
+	signed := keysAndCert.Bytes()
+	assert.Equal(t, signed, keysAndCert.Bytes())
+
+	// the returned padding is a copy
+	padding[0] ^= 0xff
+	assert.Equal(t, signed, keysAndCert.Bytes())
+	padding[0] ^= 0xff
+
+	// the recorded padding rebuilds the same bytes
+	rebuilt, err := NewKeysAndCert(keysAndCert.KeyCertificate, keysAndCert.PublicKey(), padding, keysAndCert.SigningPublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, signed, rebuilt.Bytes())
+
+	// and so does reading them back
+	parsed, remainder, err := ReadKeysAndCert(signed)
+	require.NoError(t, err)
+	assert.Empty(t, remainder)
+	assert.Equal(t, padding, parsed.KeyPadding())
+	assert.Equal(t, signed, parsed.Bytes())
+}
+
+func TestNewKeysAndCertCopiesPadding(t *testing.T) {
+	keysAndCert := generatePaddedKeysAndCert(t)
+	padding := keysAndCert.KeyPadding()
+	rebuilt, err := NewKeysAndCert(keysAndCert.KeyCertificate, keysAndCert.PublicKey(), padding, keysAndCert.SigningPublicKey())
+	require.NoError(t, err)
+	signed := rebuilt.Bytes()
+	padding[0] ^= 0xff
+	assert.Equal(t, signed, rebuilt.Bytes())
+}
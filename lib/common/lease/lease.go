@@ -18,6 +18,11 @@ const (
 	LEASE_TUNNEL_ID_SIZE = 4
 )
 
+// DEFAULT_LEASE_GRACE is how long past its end date a lease is still
+// accepted, tolerating clocks of peers drifting apart. It matches the clock
+// fudge factor of the Java router.
+const DEFAULT_LEASE_GRACE = time.Minute
+
 /*
 [Lease]
 Accurate for version 0.9.49
@@ -80,10 +85,14 @@ func (lease Lease) Date() (date Date) {
 	return
 }
 
-// ReadLease returns Lease from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadLease(data []byte) (lease Lease, remainder []byte, err error) {
+// Expired reports whether the lease ended at now, allowing grace past its
+// end date for clock skew.
+func (lease Lease) Expired(now time.Time, grace time.Duration) bool {
+	return !lease.Date().Time().Add(grace).After(now)
+}
+
+// readLease reads a Lease, returning the data after it
+func readLease(data []byte) (lease Lease, remainder []byte, err error) {
 	log.WithField("input_length", len(data)).Debug("Reading Lease from bytes")
 
 	if len(data) < LEASE_SIZE {
@@ -107,6 +116,24 @@ func ReadLease(data []byte) (lease Lease, remainder []byte, err error) {
 	return
 }
 
+// ParseLease reads a Lease from the first LEASE_SIZE bytes of data and returns
+// it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseLease(data []byte) (Lease, int, error) {
+	value, remainder, err := readLease(data)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadLease returns Lease from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing.
+//
+// Deprecated: use ParseLease.
+func ReadLease(data []byte) (Lease, []byte, error) {
+	return readLease(data)
+}
+
 // NewLease creates a new Lease with the provided parameters.
 func NewLease(tunnelGateway Hash, tunnelID uint32, expirationTime time.Time) (*Lease, error) {
 	log.Debug("Creating new Lease")
@@ -122,10 +149,8 @@ func NewLease(tunnelGateway Hash, tunnelID uint32, expirationTime time.Time) (*L
 	copy(lease[LEASE_TUNNEL_GW_SIZE:LEASE_TUNNEL_GW_SIZE+LEASE_TUNNEL_ID_SIZE], tunnelIDBytes)
 
 	// Convert and copy expiration date
-	millis := expirationTime.UnixNano() / int64(time.Millisecond)
-	dateBytes := make([]byte, DATE_SIZE)
-	binary.BigEndian.PutUint64(dateBytes, uint64(millis))
-	copy(lease[LEASE_TUNNEL_GW_SIZE+LEASE_TUNNEL_ID_SIZE:], dateBytes)
+	expiration := DateFromTime(expirationTime)
+	copy(lease[LEASE_TUNNEL_GW_SIZE+LEASE_TUNNEL_ID_SIZE:], expiration[:])
 
 	log.WithFields(logrus.Fields{
 		"tunnel_id":  tunnelID,
@@ -137,11 +162,13 @@ func NewLease(tunnelGateway Hash, tunnelID uint32, expirationTime time.Time) (*L
 
 // NewLeaseFromBytes creates a new *Lease from []byte using ReadLease.
 // Returns a pointer to Lease unlike ReadLease.
+//
+// Deprecated: use ParseLease.
 func NewLeaseFromBytes(data []byte) (lease *Lease, remainder []byte, err error) {
 	log.WithField("input_length", len(data)).Debug("Creating Lease from bytes")
 
 	var l Lease
-	l, remainder, err = ReadLease(data)
+	l, remainder, err = readLease(data)
 	if err != nil {
 		log.WithError(err).Error("Failed to read Lease from bytes")
 		return nil, remainder, err
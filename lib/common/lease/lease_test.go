@@ -2,6 +2,8 @@ package lease
 
 import (
 	"testing"
+	"time"
+
 	"github.com/stretchr/testify/assert"
 
 	. "github.com/go-i2p/go-i2p/lib/common/data"
@@ -49,3 +51,18 @@ func TestDate(t *testing.T) {
 	date := lease.Date()
 	assert.ElementsMatch(date.Bytes(), expectedDateBytes)
 }
+
+func TestExpiredWithGrace(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now().Truncate(time.Millisecond)
+	lease, err := NewLease(Hash{}, 1, now)
+	assert.Nil(err)
+
+	assert.False(lease.Expired(now.Add(-time.Millisecond), 0))
+	assert.True(lease.Expired(now, 0), "a lease has ended at its end date")
+	assert.False(lease.Expired(now.Add(30*time.Second), DEFAULT_LEASE_GRACE))
+	assert.False(lease.Expired(now.Add(DEFAULT_LEASE_GRACE-time.Millisecond), DEFAULT_LEASE_GRACE))
+	assert.True(lease.Expired(now.Add(DEFAULT_LEASE_GRACE), DEFAULT_LEASE_GRACE))
+	assert.True(lease.Expired(now.Add(2*DEFAULT_LEASE_GRACE), DEFAULT_LEASE_GRACE))
+}
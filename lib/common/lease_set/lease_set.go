@@ -21,6 +21,20 @@ import (
 
 var log = logger.GetGoI2PLogger()
 
+var (
+	ErrDestinationTooShort   = errors.New("LeaseSet data too short to contain Destination")
+	ErrDestinationTruncated  = errors.New("LeaseSet data too short to contain full Destination")
+	ErrPublicKeyTooShort     = errors.New("error parsing public key: not enough data")
+	ErrSigningKeyTooShort    = errors.New("error parsing signing public key: not enough data")
+	ErrLeaseCountTooShort    = errors.New("error parsing lease count: not enough data")
+	ErrTooManyLeases         = errors.New("invalid lease set: more than 16 leases")
+	ErrLeasesMissing         = errors.New("error parsing lease set: some leases missing")
+	ErrSignatureTooShort     = errors.New("error parsing signature: not enough data")
+	ErrDestinationTooSmall   = errors.New("invalid destination: minimum size is 387 bytes")
+	ErrInvalidEncryptionKey  = errors.New("invalid encryption key size")
+	ErrInvalidSigningKeySize = errors.New("invalid signing key size")
+)
+
 // Sizes of various structures in an I2P LeaseSet
 const (
 	LEASE_SET_PUBKEY_SIZE = 256
@@ -136,14 +150,23 @@ type LeaseSet struct {
 }
 */
 
+// Clone returns a copy of the LeaseSet which does not alias the buffer it was
+// read from. Structures returned by the accessors of a LeaseSet alias it.
+func (lease_set LeaseSet) Clone() LeaseSet {
+	if lease_set == nil {
+		return nil
+	}
+	return append(LeaseSet{}, lease_set...)
+}
+
 // Destination returns the Destination as []byte.
 func (lease_set LeaseSet) Destination() (destination Destination, err error) {
-	keys_and_cert, _, err := ReadKeysAndCertElgAndEd25519(lease_set)
+	keys_and_cert, _, err := ParseKeysAndCert(lease_set)
 	if err != nil {
 		log.WithError(err).Error("Failed to read KeysAndCert from LeaseSet")
 		return
 	}
-	destination, _, err = ReadDestination(keys_and_cert.Bytes())
+	destination, _, err = ParseDestinationBytes(keys_and_cert.Bytes())
 	if err != nil {
 		log.WithError(err).Error("Failed to read Destination from KeysAndCert")
 	} else {
@@ -158,7 +181,7 @@ func (lease_set LeaseSet) DestinationDeux() (destination Destination, err error)
 	fmt.Printf("Starting DestinationDeux, lease_set_length=%d\n", len(data))
 
 	// Read the Destination (KeysAndCert) from the LeaseSet
-	destination, remainder, err := ReadDestinationFromLeaseSet(data)
+	destination, remainder, err := readDestinationFromLeaseSet(data)
 	if err != nil {
 		fmt.Printf("Failed to read Destination from LeaseSet: %v\n", err)
 		return
@@ -171,11 +194,13 @@ func (lease_set LeaseSet) DestinationDeux() (destination Destination, err error)
 	return
 }
 
-func ReadDestinationFromLeaseSet(data []byte) (destination Destination, remainder []byte, err error) {
+// readDestinationFromLeaseSet reads the Destination at the start of a LeaseSet,
+// returning the data after it
+func readDestinationFromLeaseSet(data []byte) (destination Destination, remainder []byte, err error) {
 	fmt.Printf("Reading Destination from LeaseSet, input_length=%d\n", len(data))
 
 	if len(data) < 387 { // Minimum size of Destination (384 keys + 3 bytes for minimum certificate)
-		err = errors.New("LeaseSet data too short to contain Destination")
+		err = ErrDestinationTooShort
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
@@ -183,7 +208,7 @@ func ReadDestinationFromLeaseSet(data []byte) (destination Destination, remainde
 	certDataStart := 384
 	certData := data[certDataStart:]
 
-	cert, _, err := ReadCertificate(certData)
+	cert, _, err := ParseCertificate(certData)
 	if err != nil {
 		fmt.Printf("Failed to read Certificate from LeaseSet: %v\n", err)
 		return
@@ -199,14 +224,14 @@ func ReadDestinationFromLeaseSet(data []byte) (destination Destination, remainde
 	fmt.Printf("  destinationLength: %d\n", destinationLength)
 
 	if len(data) < destinationLength {
-		err = errors.New("LeaseSet data too short to contain full Destination")
+		err = ErrDestinationTruncated
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
 
 	destinationData := data[:destinationLength]
 
-	keysAndCert, _, err := ReadKeysAndCert(destinationData)
+	keysAndCert, _, err := ParseKeysAndCert(destinationData)
 	if err != nil {
 		fmt.Printf("Failed to read KeysAndCert: %v\n", err) //32 / 0 error
 		return
@@ -225,10 +250,32 @@ func ReadDestinationFromLeaseSet(data []byte) (destination Destination, remainde
 	return
 }
 
+// ParseDestinationFromLeaseSet reads the Destination at the start of the
+// LeaseSet in data and returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseDestinationFromLeaseSet(data []byte) (Destination, int, error) {
+	value, remainder, err := readDestinationFromLeaseSet(data)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadDestinationFromLeaseSet reads the Destination at the start of a LeaseSet
+// and returns the remaining bytes.
+//
+// Deprecated: use ParseDestinationFromLeaseSet.
+func ReadDestinationFromLeaseSet(data []byte) (Destination, []byte, error) {
+	return readDestinationFromLeaseSet(data)
+}
+
 // PublicKey returns the public key as crypto.ElgPublicKey.
 // Returns errors encountered during parsing.
 func (lease_set LeaseSet) PublicKey() (public_key crypto.ElgPublicKey, err error) {
-	_, remainder, err := ReadKeysAndCert(lease_set)
+	_, n, err := ParseKeysAndCert(lease_set)
+	if err != nil {
+		log.WithError(err).Error("Failed to read KeysAndCert for publicKey")
+		return
+	}
+	remainder := lease_set[n:]
 	remainder_len := len(remainder)
 	if remainder_len < LEASE_SET_PUBKEY_SIZE {
 		log.WithFields(logrus.Fields{
@@ -237,7 +284,7 @@ func (lease_set LeaseSet) PublicKey() (public_key crypto.ElgPublicKey, err error
 			"required_len": LEASE_SET_PUBKEY_SIZE,
 			"reason":       "not enough data",
 		}).Error("error parsing public key")
-		err = errors.New("error parsing public key: not enough data")
+		err = ErrPublicKeyTooShort
 		copy(public_key[:], remainder)
 		return
 	}
@@ -255,83 +302,73 @@ func (lease_set LeaseSet) SigningKey() (signing_public_key crypto.SigningPublicK
 		log.WithError(err).Error("Failed to retrieve Destination for SigningKey")
 		return
 	}
-	offset := len(destination.Bytes()) + LEASE_SET_PUBKEY_SIZE
-	cert := destination.Certificate()
-	cert_len := cert.Length()
+	sigType, err := lease_set.SignatureType()
+	if err != nil {
+		return
+	}
+	spk_size, err := signature.PublicKeySize(sigType)
 	if err != nil {
-		log.WithError(err).Error("Failed to get Certificate length")
+		log.WithError(err).Error("Unknown LeaseSet signing key type")
 		return
 	}
+	offset := len(destination.Bytes()) + LEASE_SET_PUBKEY_SIZE
 	lease_set_len := len(lease_set)
-	if lease_set_len < offset+LEASE_SET_SPK_SIZE {
+	if lease_set_len < offset+spk_size {
 		log.WithFields(logrus.Fields{
 			"at":           "(LeaseSet) SigningKey",
 			"data_len":     lease_set_len,
-			"required_len": offset + LEASE_SET_SPK_SIZE,
+			"required_len": offset + spk_size,
 			"reason":       "not enough data",
 		}).Error("error parsing signing public key")
-		err = errors.New("error parsing signing public key: not enough data")
+		err = ErrSigningKeyTooShort
 		return
 	}
-	if cert_len == 0 {
-		// No Certificate is present, return the LEASE_SET_SPK_SIZE byte
-		// signingPublicKey space as legacy DSA SHA1 signingPublicKey.
-		var dsa_pk crypto.DSAPublicKey
-		copy(dsa_pk[:], lease_set[offset:offset+LEASE_SET_SPK_SIZE])
-		signing_public_key = dsa_pk
-		log.Debug("Retrieved legacy DSA SHA1 signingPublicKey")
-	} else {
-		// A Certificate is present in this LeaseSet's Destination
-		cert_type := cert.Type()
-		if cert_type == CERT_KEY {
-			// This LeaseSet's Destination's Certificate is a Key Certificate,
-			// create the signing publickey key using any data that might be
-			// contained in the key certificate.
-			keyCert, err := KeyCertificateFromCertificate(cert)
-			if err != nil {
-				log.WithError(err).Error("Failed to create keyCert")
-			}
-			signing_public_key, err = keyCert.ConstructSigningPublicKey(
-				lease_set[offset : offset+LEASE_SET_SPK_SIZE],
-			)
-			if err != nil {
-				log.WithError(err).Error("Failed to construct signingPublicKey from keyCertificate")
-			} else {
-				log.Debug("Retrieved signingPublicKey from keyCertificate")
-			}
-		} else {
-			// No Certificate is present, return the LEASE_SET_SPK_SIZE byte
-			// signingPublicKey space as legacy DSA SHA1 signingPublicKey.
-			var dsa_pk crypto.DSAPublicKey
-			copy(dsa_pk[:], lease_set[offset:offset+LEASE_SET_SPK_SIZE])
-			signing_public_key = dsa_pk
-			log.Debug("Retrieved legacy DSA SHA1 signingPublicKey (Certificate present but not Key Certificate)")
-		}
+	signing_public_key, err = signature.NewSigningPublicKey(sigType, lease_set[offset:offset+spk_size])
+	if err != nil {
+		log.WithError(err).Error("Failed to construct signingPublicKey")
+		return
 	}
+	log.WithField("sig_type", sigType).Debug("Retrieved signingPublicKey from LeaseSet")
 	return
 }
 
+// signingKeySize returns the size of the signing_key field, that of a key
+// of the Destination's signing key type.
+func (lease_set LeaseSet) signingKeySize() (size int, err error) {
+	sigType, err := lease_set.SignatureType()
+	if err != nil {
+		return
+	}
+	return signature.PublicKeySize(sigType)
+}
+
 // LeaseCount returns the numbert of leases specified by the LeaseCount value as int.
 // returns errors encountered during parsing.
 func (lease_set LeaseSet) LeaseCount() (count int, err error) {
 	log.Debug("Retrieving LeaseCount from LeaseSet")
-	_, remainder, err := ReadKeysAndCert(lease_set)
+	_, n, err := ParseKeysAndCert(lease_set)
 	if err != nil {
 		log.WithError(err).Error("Failed to read KeysAndCert for LeaseCount")
 		return
 	}
+	remainder := lease_set[n:]
+	spk_size, err := lease_set.signingKeySize()
+	if err != nil {
+		log.WithError(err).Error("Failed to get signing key size for LeaseCount")
+		return
+	}
 	remainder_len := len(remainder)
-	if remainder_len < LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE+1 {
+	if remainder_len < LEASE_SET_PUBKEY_SIZE+spk_size+1 {
 		log.WithFields(logrus.Fields{
 			"at":           "(LeaseSet) LeaseCount",
 			"data_len":     remainder_len,
-			"required_len": LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1,
+			"required_len": LEASE_SET_PUBKEY_SIZE + spk_size + 1,
 			"reason":       "not enough data",
 		}).Error("error parsing lease count")
-		err = errors.New("error parsing lease count: not enough data")
+		err = ErrLeaseCountTooShort
 		return
 	}
-	c := Integer([]byte{remainder[LEASE_SET_PUBKEY_SIZE+LEASE_SET_SPK_SIZE]})
+	c := Integer([]byte{remainder[LEASE_SET_PUBKEY_SIZE+spk_size]})
 	count = c.Int()
 	if count > 16 {
 		log.WithFields(logrus.Fields{
@@ -339,23 +376,35 @@ func (lease_set LeaseSet) LeaseCount() (count int, err error) {
 			"lease_count": count,
 			"reason":      "more than 16 leases",
 		}).Warn("invalid lease set")
-		err = errors.New("invalid lease set: more than 16 leases")
+		err = ErrTooManyLeases
 	} else {
 		log.WithField("lease_count", count).Debug("Retrieved LeaseCount from LeaseSet")
 	}
 	return
 }
 
+// leasesOffset returns the offset of the first Lease
+func (lease_set LeaseSet) leasesOffset() (offset int, err error) {
+	destination, err := lease_set.Destination()
+	if err != nil {
+		return
+	}
+	spk_size, err := lease_set.signingKeySize()
+	if err != nil {
+		return
+	}
+	return len(destination.Bytes()) + LEASE_SET_PUBKEY_SIZE + spk_size + 1, nil
+}
+
 // Leases returns the leases as []Lease.
 // returns errors encountered during parsing.
 func (lease_set LeaseSet) Leases() (leases []Lease, err error) {
 	log.Debug("Retrieving Leases from LeaseSet")
-	destination, err := lease_set.Destination()
+	offset, err := lease_set.leasesOffset()
 	if err != nil {
-		log.WithError(err).Error("Failed to retrieve Destination for Leases")
+		log.WithError(err).Error("Failed to find Leases in LeaseSet")
 		return
 	}
-	offset := len(destination.Bytes()) + LEASE_SET_PUBKEY_SIZE + LEASE_SET_SPK_SIZE + 1
 	count, err := lease_set.LeaseCount()
 	if err != nil {
 		log.WithError(err).Error("Failed to retrieve LeaseCount for Leases")
@@ -372,7 +421,7 @@ func (lease_set LeaseSet) Leases() (leases []Lease, err error) {
 				"required_len": end,
 				"reason":       "some leases missing",
 			}).Error("error parsnig lease set")
-			err = errors.New("error parsing lease set: some leases missing")
+			err = ErrLeasesMissing
 			return
 		}
 		var lease Lease
@@ -383,71 +432,89 @@ func (lease_set LeaseSet) Leases() (leases []Lease, err error) {
 	return
 }
 
-// Signature returns the signature as Signature.
-// returns errors encountered during parsing.
-func (lease_set LeaseSet) Signature() (signature signature.Signature, err error) {
-	log.Debug("Retrieving Signature from LeaseSet")
+// SignatureType returns the signature type of the LeaseSet, that of its
+// Destination's signing key, or DSA_SHA1 if the Destination has no key
+// certificate.
+func (lease_set LeaseSet) SignatureType() (sigType int, err error) {
 	destination, err := lease_set.Destination()
 	if err != nil {
-		log.WithError(err).Error("Failed to retrieve Destination for Signature")
+		return
+	}
+	cert := destination.Certificate()
+	if cert.Type() != CERT_KEY {
+		return signature.SIGNATURE_TYPE_DSA_SHA1, nil
+	}
+	keyCert, err := KeyCertificateFromCertificate(cert)
+	if err != nil {
+		log.WithError(err).Error("Failed to create keyCert")
+		return
+	}
+	return keyCert.SigningPublicKeyType(), nil
+}
+
+// signatureOffset returns the offset of the signature, the length of the
+// signed data
+func (lease_set LeaseSet) signatureOffset() (offset int, err error) {
+	offset, err = lease_set.leasesOffset()
+	if err != nil {
 		return
 	}
 	lease_count, err := lease_set.LeaseCount()
 	if err != nil {
-		log.WithError(err).Error("Failed to retrieve LeaseCount for Signature")
 		return
 	}
-	start := len(destination.Bytes()) +
-		LEASE_SET_PUBKEY_SIZE +
-		LEASE_SET_SPK_SIZE +
-		1 +
-		(LEASE_SIZE * lease_count)
-	cert := destination.Certificate()
-	cert_type := cert.Type()
-	var end int
-	if cert_type == CERT_KEY {
-		keyCert, err := KeyCertificateFromCertificate(cert)
-		if err != nil {
-			log.WithError(err).Error("Failed to create keyCert")
-		}
-		end = start + keyCert.SignatureSize()
-	} else {
-		end = start + LEASE_SET_SIG_SIZE
+	return offset + LEASE_SIZE*lease_count, nil
+}
+
+// Signature returns the signature as Signature, sized by SignatureType.
+// returns errors encountered during parsing.
+func (lease_set LeaseSet) Signature() (sig signature.Signature, err error) {
+	log.Debug("Retrieving Signature from LeaseSet")
+	start, err := lease_set.signatureOffset()
+	if err != nil {
+		log.WithError(err).Error("Failed to find Signature in LeaseSet")
+		return
 	}
-	lease_set_len := len(lease_set)
-	if lease_set_len < end {
-		log.WithFields(logrus.Fields{
-			"at":           "(LeaseSet) Signature",
-			"data_len":     lease_set_len,
-			"required_len": end,
-			"reason":       "not enough data",
-		}).Error("error parsing signatre")
-		err = errors.New("error parsing signature: not enough data")
+	sigType, err := lease_set.SignatureType()
+	if err != nil {
+		return
+	}
+	if len(lease_set) < start {
+		err = ErrSignatureTooShort
+		log.WithError(err).Error("LeaseSet ends before its signature")
 		return
 	}
-	signature = []byte(lease_set[start:end])
-	log.WithField("signature_length", len(signature)).Debug("Retrieved Signature from LeaseSet")
+	sig, _, err = signature.ParseSignature(lease_set[start:], sigType)
+	if err != nil {
+		log.WithError(err).Error("error parsing signature")
+		return
+	}
+	log.WithField("signature_length", len(sig)).Debug("Retrieved Signature from LeaseSet")
 	return
 }
 
-// Verify returns nil
+// Verify checks the LeaseSet signature against the signing public key of its
+// Destination. Returns nil if the signature is valid.
 func (lease_set LeaseSet) Verify() error {
 	log.Debug("Verifying LeaseSet")
-	//data_end := len(destination) +
-	//	LEASE_SET_PUBKEY_SIZE +
-	//	LEASE_SET_SPK_SIZE +
-	//	1 +
-	//	(44 * lease_set.LeaseCount())
-	//data := lease_set[:data_end]
-	//spk, _ := lease_set.
-	//	Destination().
-	//	signingPublicKey()
-	//verifier, err := spk.NewVerifier()
-	//if err != nil {
-	//	return err
-	//}
-	log.Warn("LeaseSet verification not implemented")
-	return nil // verifier.Verify(data, lease_set.Signature())
+	destination, err := lease_set.Destination()
+	if err != nil {
+		return err
+	}
+	end, err := lease_set.signatureOffset()
+	if err != nil {
+		return err
+	}
+	sig, err := lease_set.Signature()
+	if err != nil {
+		return err
+	}
+	if err := crypto.VerifySignature(destination.SigningPublicKey(), lease_set[:end], sig); err != nil {
+		log.WithError(err).Warn("LeaseSet signature verification failed")
+		return err
+	}
+	log.Debug("LeaseSet signature verified successfully")
+	return nil
 }
 
 // NewestExpiration returns the newest lease expiration as an I2P Date.
@@ -459,10 +526,9 @@ func (lease_set LeaseSet) NewestExpiration() (newest Date, err error) {
 		log.WithError(err).Error("Failed to retrieve Leases for NewestExpiration")
 		return
 	}
-	newest = Date{0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 	for _, lease := range leases {
 		date := lease.Date()
-		if date.Time().After(newest.Time()) {
+		if date.After(newest) {
 			newest = date
 		}
 	}
@@ -479,10 +545,9 @@ func (lease_set LeaseSet) OldestExpiration() (earliest Date, err error) {
 		log.WithError(err).Error("Failed to retrieve Leases for OldestExpiration")
 		return
 	}
-	earliest = Date{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
-	for _, lease := range leases {
+	for i, lease := range leases {
 		date := lease.Date()
-		if date.Time().Before(earliest.Time()) {
+		if i == 0 || date.Before(earliest) {
 			earliest = date
 		}
 	}
@@ -500,15 +565,15 @@ func NewLeaseSet(
 	log.Debug("Creating new LeaseSet")
 	// Validate destination size
 	if len(destination.KeysAndCert.Bytes()) < 387 {
-		return nil, errors.New("invalid destination: minimum size is 387 bytes")
+		return nil, ErrDestinationTooSmall
 	}
 	// Validate encryption key size
 	if len(encryptionKey.Bytes()) != LEASE_SET_PUBKEY_SIZE {
-		return nil, errors.New("invalid encryption key size")
+		return nil, ErrInvalidEncryptionKey
 	}
 	// Validate inputs
 	if len(leases) > 16 {
-		return nil, errors.New("invalid lease set: more than 16 leases")
+		return nil, ErrTooManyLeases
 	}
 	// Validate signing key size matches certificate
 	cert := destination.Certificate()
@@ -520,13 +585,13 @@ func NewLeaseSet(
 		}
 		expectedSize := keyCert.SignatureSize()
 		if len(signingKey.Bytes()) != expectedSize {
-			return nil, fmt.Errorf("invalid signing key size: got %d, expected %d",
+			return nil, fmt.Errorf("%w: got %d, expected %d", ErrInvalidSigningKeySize,
 				len(signingKey.Bytes()), expectedSize)
 		}
 	} else {
 		// Default DSA size
 		if len(signingKey.Bytes()) != LEASE_SET_SPK_SIZE {
-			return nil, errors.New("invalid signing key size")
+			return nil, ErrInvalidSigningKeySize
 		}
 	}
 	// Build LeaseSet data
@@ -542,7 +607,7 @@ func NewLeaseSet(
 	data = append(data, signingKey.Bytes()...)
 
 	// Add lease count
-	leaseCount, err := NewIntegerFromInt(len(leases), 1)
+	leaseCount, err := NewIntegerFromIntChecked(len(leases), 1)
 	if err != nil {
 		log.WithError(err).Error("Failed to create lease count")
 		return nil, err
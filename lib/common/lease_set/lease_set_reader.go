@@ -0,0 +1,49 @@
+package lease_set
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	. "github.com/go-i2p/go-i2p/lib/common/lease"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+)
+
+// ReadLeaseSetFrom reads a LeaseSet off r, reading exactly its bytes. The
+// signing key and signature are sized by the Destination's signing key type.
+func ReadLeaseSetFrom(r io.Reader) (lease_set LeaseSet, err error) {
+	var data bytes.Buffer
+	tee := io.TeeReader(r, &data)
+	destination, err := ReadKeysAndCertFrom(tee)
+	if err != nil {
+		return
+	}
+	sigType := destination.KeyCertificate.SigningPublicKeyType()
+	keySize, err := signature.PublicKeySize(sigType)
+	if err != nil {
+		return
+	}
+	sigSize, err := signature.SignatureSize(sigType)
+	if err != nil {
+		return
+	}
+	// encryption key, signing key and lease count
+	header := make([]byte, LEASE_SET_PUBKEY_SIZE+keySize+1)
+	if _, err = io.ReadFull(tee, header); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	rest := make([]byte, LEASE_SIZE*int(header[len(header)-1])+sigSize)
+	if _, err = io.ReadFull(tee, rest); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return LeaseSet(data.Bytes()), nil
+}
+
+// unexpectedEOF turns io.EOF part way through a LeaseSet into
+// io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
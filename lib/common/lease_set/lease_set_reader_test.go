@@ -0,0 +1,27 @@
+package lease_set
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadLeaseSetFrom(t *testing.T) {
+	routerInfo, _, _, _, _, err := generateTestRouterInfo(t)
+	require.NoError(t, err)
+	leaseSet, err := createTestLeaseSet(t, routerInfo, 2)
+	require.NoError(t, err)
+
+	stream := bytes.NewReader(append(append([]byte(nil), leaseSet...), 0xde, 0xad))
+	read, err := ReadLeaseSetFrom(stream)
+	require.NoError(t, err)
+	assert.Equal(t, leaseSet, read)
+	assert.Equal(t, 2, stream.Len())
+	assert.NoError(t, read.Verify())
+
+	_, err = ReadLeaseSetFrom(bytes.NewReader(leaseSet[:len(leaseSet)-1]))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
@@ -78,8 +78,8 @@ func generateTestRouterInfo(t *testing.T) (*router_info.RouterInfo, crypto.Publi
 		t.Fatalf("Failed to create crypto public key type integer: %v", err)
 	}
 
-	payload.Write(*cryptoPublicKeyType)
 	payload.Write(*signingPublicKeyType)
+	payload.Write(*cryptoPublicKeyType)
 
 	cert, err := certificate.NewCertificateWithType(certificate.CERT_KEY, payload.Bytes())
 	if err != nil {
@@ -218,8 +218,8 @@ func generateTestDestination(t *testing.T) (*destination.Destination, crypto.Pub
 	if err != nil {
 		t.Fatalf("Failed to create signing public key type integer: %v", err)
 	}
-	payload.Write(*cryptoPublicKeyType)
 	payload.Write(*signingPublicKeyType)
+	payload.Write(*cryptoPublicKeyType)
 
 	// Create Certificate
 	cert, err := certificate.NewCertificateWithType(certificate.CERT_KEY, payload.Bytes())
@@ -346,9 +346,9 @@ func TestLeaseSetValidation(t *testing.T) {
 	_, err = createTestLeaseSet(t, routerInfo, 17)
 	assert.NotNil(err)
 	assert.Equal("invalid lease set: more than 16 leases", err.Error())
+	assert.ErrorIs(err, ErrTooManyLeases)
 }
 
-/*
 func TestLeaseSetComponents(t *testing.T) {
 	assert := assert.New(t)
 
@@ -419,4 +419,52 @@ func TestSignatureVerification(t *testing.T) {
 	assert.NotNil(sig)
 }
 
-*/
+func TestLeaseSetSignatureSizedByType(t *testing.T) {
+	assert := assert.New(t)
+
+	dest, _, _, _, err := generateTestDestination(t)
+	assert.Nil(err)
+	// an Ed25519 LeaseSet without leases, with a 32 byte signing key
+	data := append([]byte{}, dest.KeysAndCert.Bytes()...)
+	data = append(data, make([]byte, LEASE_SET_PUBKEY_SIZE+32+1)...)
+	sigBytes := bytes.Repeat([]byte{0xab}, signature.EdDSA_SHA512_Ed25519_SIZE)
+	leaseSet := LeaseSet(append(data, sigBytes...))
+
+	sigType, err := leaseSet.SignatureType()
+	assert.Nil(err)
+	assert.Equal(signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, sigType)
+
+	sig, err := leaseSet.Signature()
+	assert.Nil(err)
+	assert.Equal(sigBytes, []byte(sig))
+
+	_, err = leaseSet[:len(leaseSet)-1].Signature()
+	assert.NotNil(err)
+}
+
+func TestLeaseSetAccessorsReportTruncation(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := LeaseSet([]byte{0x01, 0x02}).PublicKey()
+	assert.NotNil(err)
+	_, err = LeaseSet([]byte{0x01, 0x02}).SigningKey()
+	assert.NotNil(err)
+}
+
+func TestLeaseSetVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfo, _, _, _, _, err := generateTestRouterInfo(t)
+	assert.Nil(err)
+	leaseSet, err := createTestLeaseSet(t, routerInfo, 2)
+	assert.Nil(err)
+	assert.Nil(leaseSet.Verify())
+
+	leases, err := leaseSet.Leases()
+	assert.Nil(err)
+	assert.Len(leases, 2)
+
+	tampered := append(LeaseSet{}, leaseSet...)
+	tampered[len(tampered)-70] ^= 0xff
+	assert.ErrorIs(tampered.Verify(), crypto.ErrInvalidSignature)
+}
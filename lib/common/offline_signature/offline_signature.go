@@ -0,0 +1,185 @@
+// Package offline_signature implements the OfflineSignature common structure,
+// which lets a destination whose signing key is kept offline delegate signing
+// to a short-lived transient key.
+package offline_signature
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+/*
+[OfflineSignature]
+Accurate for version 0.9.64
+
+Description
+An optional part of LeaseSet2Header, streaming SYN packets and repliable
+datagrams, carrying a transient signing key signed by the long-term key of the
+destination.
+
+Contents
++----+----+----+----+----+----+----+----+
+|     expires       | sigtype |         |
++----+----+----+----+----+----+         +
+|       transient_public_key            |
+~                                       ~
+|                                       |
++----+----+----+----+----+----+----+----+
+|           signature                   |
+~                                       ~
+|                                       |
++----+----+----+----+----+----+----+----+
+
+expires :: 4 byte date, seconds since the epoch
+
+sigtype :: 2 byte type of the transient_public_key
+
+transient_public_key :: SigningPublicKey of sigtype
+
+signature :: Signature by the destination's long-term key of expires,
+             sigtype and transient_public_key
+*/
+
+// OFFLINE_SIGNATURE_HEADER_SIZE is the size of expires and sigtype
+const OFFLINE_SIGNATURE_HEADER_SIZE = 6
+
+var (
+	ErrOfflineSignatureTooShort = errors.New("offline signature too short")
+	ErrOfflineSignatureExpired  = errors.New("offline signature expired")
+	ErrUnsupportedTransientType = errors.New("unsupported transient signing key type")
+)
+
+// OfflineSignature is the representation of an I2P OfflineSignature.
+//
+// https://geti2p.net/spec/common-structures#offlinesignature
+type OfflineSignature struct {
+	// Expires is when the transient key stops being valid, in whole seconds
+	Expires time.Time
+	// TransientType is the signature type of TransientKey
+	TransientType int
+	TransientKey  []byte
+	// Signature is made with the destination's long-term signing key
+	Signature signature.Signature
+}
+
+// readOfflineSignature reads an OfflineSignature, returning the data after it
+func readOfflineSignature(data []byte, destinationSigType int) (offline OfflineSignature, remainder []byte, err error) {
+	if len(data) < OFFLINE_SIGNATURE_HEADER_SIZE {
+		err = ErrOfflineSignatureTooShort
+		log.WithField("length", len(data)).Error("Failed to read offline signature header")
+		return
+	}
+	offline.Expires = time.Unix(int64(binary.BigEndian.Uint32(data[0:4])), 0).UTC()
+	offline.TransientType = int(binary.BigEndian.Uint16(data[4:6]))
+	keySize, err := signature.PublicKeySize(offline.TransientType)
+	if err != nil {
+		err = fmt.Errorf("%w: %d", ErrUnsupportedTransientType, offline.TransientType)
+		return
+	}
+	data = data[OFFLINE_SIGNATURE_HEADER_SIZE:]
+	if len(data) < keySize {
+		err = ErrOfflineSignatureTooShort
+		log.WithFields(logrus.Fields{
+			"length":   len(data),
+			"key_size": keySize,
+		}).Error("Failed to read offline signature transient key")
+		return
+	}
+	offline.TransientKey = data[:keySize]
+	var n int
+	offline.Signature, n, err = signature.ParseSignature(data[keySize:], destinationSigType)
+	remainder = data[keySize+n:]
+	return
+}
+
+// ParseOfflineSignature reads an OfflineSignature made by a Destination of
+// signature type destinationSigType from the start of data and returns it
+// with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseOfflineSignature(data []byte, destinationSigType int) (OfflineSignature, int, error) {
+	value, remainder, err := readOfflineSignature(data, destinationSigType)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadOfflineSignature reads an OfflineSignature of a destination whose
+// long-term key has signature type destinationSigType, and returns the
+// remaining bytes.
+//
+// Deprecated: use ParseOfflineSignature.
+func ReadOfflineSignature(data []byte, destinationSigType int) (OfflineSignature, []byte, error) {
+	return readOfflineSignature(data, destinationSigType)
+}
+
+// NewOfflineSignature delegates signing to transientKey until expires, signed
+// by the destination's long-term signer.
+func NewOfflineSignature(expires time.Time, transientType int, transientKey []byte, signer crypto.Signer) (*OfflineSignature, error) {
+	if size, err := signature.PublicKeySize(transientType); err != nil || size != len(transientKey) {
+		return nil, fmt.Errorf("%w: %d with %d byte key", ErrUnsupportedTransientType, transientType, len(transientKey))
+	}
+	offline := &OfflineSignature{
+		Expires:       time.Unix(expires.Unix(), 0).UTC(),
+		TransientType: transientType,
+		TransientKey:  append([]byte(nil), transientKey...),
+	}
+	sig, err := signer.Sign(offline.signedBytes())
+	if err != nil {
+		return nil, err
+	}
+	offline.Signature = sig
+	log.WithFields(logrus.Fields{
+		"transient_type": transientType,
+		"expires":        offline.Expires,
+	}).Debug("Created offline signature")
+	return offline, nil
+}
+
+// signedBytes returns the data covered by the signature
+func (offline OfflineSignature) signedBytes() []byte {
+	data := make([]byte, OFFLINE_SIGNATURE_HEADER_SIZE, OFFLINE_SIGNATURE_HEADER_SIZE+len(offline.TransientKey))
+	binary.BigEndian.PutUint32(data[0:4], uint32(offline.Expires.Unix()))
+	binary.BigEndian.PutUint16(data[4:6], uint16(offline.TransientType))
+	return append(data, offline.TransientKey...)
+}
+
+// Bytes returns the OfflineSignature in wire form.
+func (offline OfflineSignature) Bytes() []byte {
+	return append(offline.signedBytes(), offline.Signature...)
+}
+
+// Len returns the length of the OfflineSignature in wire form.
+func (offline OfflineSignature) Len() int {
+	return OFFLINE_SIGNATURE_HEADER_SIZE + len(offline.TransientKey) + len(offline.Signature)
+}
+
+// Verify checks that the destination's long-term key destinationKey signed
+// the transient key and that it has not expired at now.
+func (offline OfflineSignature) Verify(destinationKey crypto.SigningPublicKey, now time.Time) error {
+	if !now.Before(offline.Expires) {
+		log.WithField("expires", offline.Expires).Warn("Offline signature expired")
+		return ErrOfflineSignatureExpired
+	}
+	verifier, err := destinationKey.NewVerifier()
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(offline.signedBytes(), offline.Signature)
+}
+
+// TransientPublicKey returns the transient key as a SigningPublicKey.
+func (offline OfflineSignature) TransientPublicKey() (crypto.SigningPublicKey, error) {
+	key, err := signature.NewSigningPublicKey(offline.TransientType, offline.TransientKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedTransientType, err)
+	}
+	return key, nil
+}
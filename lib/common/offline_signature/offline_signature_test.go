@@ -0,0 +1,55 @@
+package offline_signature
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOfflineSignature(t *testing.T, expires time.Time) (*OfflineSignature, crypto.SigningPublicKey) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := crypto.Ed25519PrivateKey(private).NewSigner()
+	require.NoError(t, err)
+	transient, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	offline, err := NewOfflineSignature(expires, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, transient, signer)
+	require.NoError(t, err)
+	return offline, crypto.Ed25519PublicKey(public)
+}
+
+func TestOfflineSignatureRoundTrip(t *testing.T) {
+	now := time.Now()
+	offline, destinationKey := newTestOfflineSignature(t, now.Add(time.Hour))
+
+	data := append(offline.Bytes(), 0xff)
+	assert.Equal(t, 6+32+64, offline.Len())
+	read, remainder, err := ReadOfflineSignature(data, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0xff}, remainder)
+	assert.True(t, offline.Expires.Equal(read.Expires))
+	assert.Equal(t, offline.TransientKey, read.TransientKey)
+
+	assert.NoError(t, read.Verify(destinationKey, now))
+	assert.ErrorIs(t, read.Verify(destinationKey, now.Add(2*time.Hour)), ErrOfflineSignatureExpired)
+
+	read.TransientKey[0] ^= 0xff
+	assert.Error(t, read.Verify(destinationKey, now))
+}
+
+func TestReadOfflineSignatureErrors(t *testing.T) {
+	_, _, err := ReadOfflineSignature([]byte{0, 0, 0, 1}, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	assert.ErrorIs(t, err, ErrOfflineSignatureTooShort)
+
+	_, _, err = ReadOfflineSignature([]byte{0, 0, 0, 1, 0x01, 0x02, 1, 2, 3}, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	assert.ErrorIs(t, err, ErrUnsupportedTransientType)
+
+	_, _, err = ReadOfflineSignature([]byte{0, 0, 0, 1, 0, 7, 1, 2, 3}, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	assert.ErrorIs(t, err, ErrOfflineSignatureTooShort)
+}
@@ -2,7 +2,6 @@
 package router_address
 
 import (
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
@@ -180,6 +179,24 @@ func (router_address RouterAddress) GetOption(key I2PString) I2PString {
 	return router_address.Options().Values().Get(key)
 }
 
+// GetOptionString returns the value of the option named key, or def if it is
+// not set.
+func (router_address RouterAddress) GetOptionString(key, def string) string {
+	return router_address.TransportOptions.OptionString(key, def)
+}
+
+// GetOptionInt returns the option named key as an integer, or def if it is
+// not set. A *data.OptionError is returned if the value is not an integer.
+func (router_address RouterAddress) GetOptionInt(key string, def int) (int, error) {
+	return router_address.TransportOptions.OptionInt(key, def)
+}
+
+// GetOptionBool returns the option named key as a boolean, or def if it is
+// not set. A *data.OptionError is returned if the value is not a boolean.
+func (router_address RouterAddress) GetOptionBool(key string, def bool) (bool, error) {
+	return router_address.TransportOptions.OptionBool(key, def)
+}
+
 func (router_address RouterAddress) HostString() I2PString {
 	host, _ := ToI2PString("host")
 	return router_address.GetOption(host)
@@ -253,14 +270,10 @@ func (router_address RouterAddress) Host() (net.Addr, error) {
 		log.Error("Failed to parse IP address")
 		return nil, fmt.Errorf("null host error")
 	}
-	// return net.ResolveIPAddr("", ip.String())
-	addr, err := net.ResolveIPAddr("", ip.String())
-	if err != nil {
-		log.WithError(err).Error("Failed to resolve IP address")
-	} else {
-		log.WithField("addr", addr).Debug("Retrieved host from RouterAddress")
-	}
-	return addr, err
+	// the host is an IP literal, so there is nothing to resolve
+	addr := &net.IPAddr{IP: ip}
+	log.WithField("addr", addr).Debug("Retrieved host from RouterAddress")
+	return addr, nil
 }
 
 func (router_address RouterAddress) Port() (string, error) {
@@ -282,22 +295,6 @@ func (router_address RouterAddress) Port() (string, error) {
 	return portStr, nil
 }
 
-func (router_address RouterAddress) StaticKey() ([32]byte, error) {
-	sk := router_address.StaticKeyString()
-	if len([]byte(sk)) != 32 {
-		return [32]byte{}, fmt.Errorf("error: invalid static key")
-	}
-	return [32]byte(sk), nil
-}
-
-func (router_address RouterAddress) InitializationVector() ([32]byte, error) {
-	iv := router_address.InitializationVectorString()
-	if len([]byte(iv)) != 32 {
-		return [32]byte{}, fmt.Errorf("error: invalid static key")
-	}
-	return [32]byte(iv), nil
-}
-
 func (router_address RouterAddress) ProtocolVersion() (string, error) {
 	return router_address.ProtocolVersionString().Data()
 }
@@ -312,49 +309,87 @@ func (router_address RouterAddress) checkValid() (err error, exit bool) {
 	return
 }
 
-// ReadRouterAddress returns RouterAddress from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadRouterAddress(data []byte) (router_address RouterAddress, remainder []byte, err error) {
+// readRouterAddress reads a RouterAddress, returning the data after it
+func readRouterAddress(data []byte, opts ...ParseOption) (router_address RouterAddress, remainder []byte, err error) {
 	log.WithField("data_length", len(data)).Debug("Reading RouterAddress from data")
+	data = ParseBuffer(data, opts...)
 	if len(data) == 0 {
 		log.WithField("at", "(RouterAddress) ReadRouterAddress").Error("error parsing RouterAddress: no data")
 		err = errors.New("error parsing RouterAddress: no data")
 		return
 	}
-	router_address.TransportCost, remainder, err = NewInteger(data, 1)
+	cost, n, err := ParseInteger(data, 1)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"at":     "(RouterAddress) ReadNewRouterAddress",
 			"reason": "error parsing cost",
 		}).Warn("error parsing RouterAddress")
+	} else {
+		router_address.TransportCost = &cost
 	}
-	router_address.ExpirationDate, remainder, err = NewDate(remainder)
+	remainder = data[n:]
+	expiration, n, err := ParseDate(remainder)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"at":     "(RouterAddress) ReadNewRouterAddress",
 			"reason": "error parsing expiration",
 		}).Error("error parsing RouterAddress")
+	} else {
+		router_address.ExpirationDate = &expiration
 	}
-	router_address.TransportType, remainder, err = ReadI2PString(remainder)
+	remainder = remainder[n:]
+	router_address.TransportType, n, err = ParseI2PString(remainder)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"at":     "(RouterAddress) ReadNewRouterAddress",
 			"reason": "error parsing transport_style",
 		}).Error("error parsing RouterAddress")
 	}
-	var errs []error
-	router_address.TransportOptions, remainder, errs = NewMapping(remainder)
-	for _, err := range errs {
+	remainder = remainder[n:]
+	options, n, optionsErr := ParseMapping(remainder)
+	router_address.TransportOptions = &options
+	remainder = remainder[n:]
+	if optionsErr != nil {
 		log.WithFields(logrus.Fields{
 			"at":     "(RouterAddress) ReadNewRouterAddress",
 			"reason": "error parsing options",
-			"error":  err,
+			"error":  optionsErr,
 		}).Error("error parsing RozuterAddress")
 	}
 	return
 }
 
+// ParseRouterAddress reads a RouterAddress from the start of data and returns
+// it with the number of bytes consumed. The RouterAddress aliases data unless
+// CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseRouterAddress(data []byte, opts ...ParseOption) (RouterAddress, int, error) {
+	value, remainder, err := readRouterAddress(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadRouterAddress returns RouterAddress from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing. The RouterAddress
+// aliases data unless CopyOnParse is given.
+//
+// Deprecated: use ParseRouterAddress.
+func ReadRouterAddress(data []byte, opts ...ParseOption) (RouterAddress, []byte, error) {
+	return readRouterAddress(data, opts...)
+}
+
+// Clone returns a copy of the RouterAddress which does not alias the buffer it
+// was read from.
+func (router_address RouterAddress) Clone() RouterAddress {
+	return RouterAddress{
+		TransportCost:    CloneInteger(router_address.TransportCost),
+		ExpirationDate:   CloneDate(router_address.ExpirationDate),
+		TransportType:    router_address.TransportType.Clone(),
+		TransportOptions: CloneMapping(router_address.TransportOptions),
+	}
+}
+
 // NewRouterAddress creates a new RouterAddress with the provided parameters.
 // Returns a pointer to RouterAddress.
 func NewRouterAddress(cost uint8, expiration time.Time, transportType string, options map[string]string) (*RouterAddress, error) {
@@ -368,14 +403,7 @@ func NewRouterAddress(cost uint8, expiration time.Time, transportType string, op
 	}
 
 	// Create ExpirationDate as a Date
-	millis := expiration.UnixNano() / int64(time.Millisecond)
-	dateBytes := make([]byte, DATE_SIZE)
-	binary.BigEndian.PutUint64(dateBytes, uint64(millis))
-	expirationDate, _, err := NewDate(dateBytes)
-	if err != nil {
-		log.WithError(err).Error("Failed to create ExpirationDate")
-		return nil, err
-	}
+	expirationDate := DateFromTime(expiration)
 
 	// Create TransportType as an I2PString
 	transportTypeStr, err := ToI2PString(transportType)
@@ -394,7 +422,7 @@ func NewRouterAddress(cost uint8, expiration time.Time, transportType string, op
 	// Create RouterAddress
 	ra := &RouterAddress{
 		TransportCost:    transportCost,
-		ExpirationDate:   expirationDate,
+		ExpirationDate:   &expirationDate,
 		TransportType:    transportTypeStr,
 		TransportOptions: transportOptions,
 	}
@@ -0,0 +1,284 @@
+package router_address
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+// Sizes of the decoded key options.
+//
+// https://geti2p.net/spec/ntcp2#published-router-info
+// https://geti2p.net/spec/ssu2#published-router-info
+const (
+	STATIC_KEY_SIZE     = 32
+	NTCP2_IV_SIZE       = 16
+	SSU2_INTRO_KEY_SIZE = 32
+	MAX_INTRODUCERS     = 3
+)
+
+// Address caps letters. They describe the address, not the router, and are
+// unrelated to the RouterInfo caps.
+const (
+	ADDRESS_CAP_IPV4       = '4'
+	ADDRESS_CAP_IPV6       = '6'
+	ADDRESS_CAP_TESTING    = 'B'
+	ADDRESS_CAP_INTRODUCER = 'C'
+)
+
+var (
+	ErrMissingOption = errors.New("router address option not set")
+	ErrInvalidHost   = errors.New("router address host is not a valid IP address")
+	ErrInvalidPort   = errors.New("router address port is not in the range 1-65535")
+	ErrInvalidKey    = errors.New("router address key has the wrong length")
+	// ErrInvalidIntroducer is returned for malformed introducer options
+	ErrInvalidIntroducer = errors.New("router address introducer is invalid")
+)
+
+// AddressCaps is the parsed form of a RouterAddress caps option.
+type AddressCaps struct {
+	IPv4 bool
+	IPv6 bool
+	// Testing is set if the router can act as a peer test partner
+	Testing bool
+	// Introducer is set if the router can act as an introducer
+	Introducer bool
+}
+
+// Introducer is one of the introducers published by a firewalled SSU2 address.
+type Introducer struct {
+	// Hash is the router hash of the introducer
+	Hash [32]byte
+	// Expiration is the zero time if no iexp option was published
+	Expiration time.Time
+	// Tag is the relay tag the introducer gave the firewalled router, it is
+	// sent in the relay request so the introducer can find the session
+	Tag uint32
+	// Host and Port are only published by SSU 1 addresses, SSU2 looks the
+	// introducer up by Hash instead. Host is nil and Port 0 if unset.
+	Host net.IP
+	Port int
+}
+
+// Expired reports whether the introducer has expired at now. Introducers
+// without an expiration never expire.
+func (introducer Introducer) Expired(now time.Time) bool {
+	return !introducer.Expiration.IsZero() && now.After(introducer.Expiration)
+}
+
+// option returns the value of the option named key, or ErrMissingOption if
+// it is not set or empty.
+func (router_address RouterAddress) option(key string) (string, error) {
+	if router_address.TransportOptions == nil {
+		return "", fmt.Errorf("%w: %s", ErrMissingOption, key)
+	}
+	k, err := ToI2PString(key)
+	if err != nil {
+		return "", err
+	}
+	value := router_address.GetOption(k)
+	if len(value) == 0 {
+		return "", fmt.Errorf("%w: %s", ErrMissingOption, key)
+	}
+	data, err := value.Data()
+	if err != nil {
+		return "", err
+	}
+	if data == "" {
+		return "", fmt.Errorf("%w: %s", ErrMissingOption, key)
+	}
+	return data, nil
+}
+
+// HostIP returns the host option as an IP address.
+func (router_address RouterAddress) HostIP() (net.IP, error) {
+	host, err := router_address.option("host")
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		log.WithField("host", host).Error("Invalid router address host")
+		return nil, ErrInvalidHost
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4, nil
+	}
+	return ip, nil
+}
+
+// PortNumber returns the port option as a port number.
+func (router_address RouterAddress) PortNumber() (uint16, error) {
+	port, err := router_address.option("port")
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || value == 0 {
+		log.WithField("port", port).Error("Invalid router address port")
+		return 0, ErrInvalidPort
+	}
+	return uint16(value), nil
+}
+
+// StaticKey returns the decoded s option, the X25519 static public key of an
+// NTCP2 or SSU2 address.
+func (router_address RouterAddress) StaticKey() (key [STATIC_KEY_SIZE]byte, err error) {
+	err = router_address.decodeKey("s", key[:])
+	return
+}
+
+// InitializationVector returns the decoded i option of an NTCP2 address, the
+// AES IV used to obfuscate the ephemeral key.
+func (router_address RouterAddress) InitializationVector() (iv [NTCP2_IV_SIZE]byte, err error) {
+	err = router_address.decodeKey("i", iv[:])
+	return
+}
+
+// IntroKey returns the decoded i option of an SSU2 address, the intro key used
+// for the header encryption of the first messages.
+func (router_address RouterAddress) IntroKey() (key [SSU2_INTRO_KEY_SIZE]byte, err error) {
+	err = router_address.decodeKey("i", key[:])
+	return
+}
+
+// decodeKey decodes the base64 option named key into out, which it must fill
+// exactly.
+func (router_address RouterAddress) decodeKey(key string, out []byte) error {
+	encoded, err := router_address.option(key)
+	if err != nil {
+		return err
+	}
+	decoded, err := base64.DecodeString(encoded)
+	if err != nil {
+		log.WithError(err).WithField("option", key).Error("Failed to decode router address key")
+		return err
+	}
+	if len(decoded) != len(out) {
+		log.WithField("option", key).Error("Router address key has the wrong length")
+		return fmt.Errorf("%w: %s is %d bytes, want %d", ErrInvalidKey, key, len(decoded), len(out))
+	}
+	copy(out, decoded)
+	return nil
+}
+
+// ProtocolVersions returns the comma separated versions in the v option.
+func (router_address RouterAddress) ProtocolVersions() ([]int, error) {
+	v, err := router_address.option("v")
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, field := range strings.Split(v, ",") {
+		version, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || version < 0 {
+			return nil, fmt.Errorf("invalid router address version %q", v)
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// Caps returns the parsed caps option. Unknown letters are ignored and an
+// unset option yields no caps.
+func (router_address RouterAddress) Caps() AddressCaps {
+	var caps AddressCaps
+	value, err := router_address.option("caps")
+	if err != nil {
+		return caps
+	}
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case ADDRESS_CAP_IPV4:
+			caps.IPv4 = true
+		case ADDRESS_CAP_IPV6:
+			caps.IPv6 = true
+		case ADDRESS_CAP_TESTING:
+			caps.Testing = true
+		case ADDRESS_CAP_INTRODUCER:
+			caps.Introducer = true
+		}
+	}
+	return caps
+}
+
+// Introducers returns the introducers published in the ihN, itagN, iexpN,
+// ihostN and iportN options. An introducer is only returned if both its hash
+// and tag are set, a malformed option is reported as ErrInvalidIntroducer.
+func (router_address RouterAddress) Introducers() ([]Introducer, error) {
+	var introducers []Introducer
+	for num := 0; num < MAX_INTRODUCERS; num++ {
+		introducer, err := router_address.introducer(strconv.Itoa(num))
+		if errors.Is(err, ErrMissingOption) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		introducers = append(introducers, introducer)
+	}
+	return introducers, nil
+}
+
+// Firewalled reports whether peers have to reach the address through its
+// introducers, that is it publishes introducers but no host.
+func (router_address RouterAddress) Firewalled() bool {
+	if _, err := router_address.option("host"); err == nil {
+		return false
+	}
+	introducers, err := router_address.Introducers()
+	return err == nil && len(introducers) > 0
+}
+
+// introducer parses the introducer options with suffix n
+func (router_address RouterAddress) introducer(n string) (Introducer, error) {
+	var introducer Introducer
+	err := router_address.decodeKey("ih"+n, introducer.Hash[:])
+	if errors.Is(err, ErrMissingOption) {
+		return introducer, err
+	}
+	if err != nil {
+		return introducer, fmt.Errorf("%w: ih%s: %v", ErrInvalidIntroducer, n, err)
+	}
+	tag, err := router_address.option("itag" + n)
+	if err != nil {
+		return introducer, err
+	}
+	value, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || value == 0 {
+		return introducer, fmt.Errorf("%w: itag%s %q", ErrInvalidIntroducer, n, tag)
+	}
+	introducer.Tag = uint32(value)
+	if expiration, err := router_address.option("iexp" + n); err == nil {
+		seconds, err := strconv.ParseInt(expiration, 10, 64)
+		if err != nil || seconds < 0 {
+			return introducer, fmt.Errorf("%w: iexp%s %q", ErrInvalidIntroducer, n, expiration)
+		}
+		introducer.Expiration = time.Unix(seconds, 0)
+	} else if !errors.Is(err, ErrMissingOption) {
+		return introducer, err
+	}
+	if host, err := router_address.option("ihost" + n); err == nil {
+		if introducer.Host = net.ParseIP(host); introducer.Host == nil {
+			return introducer, fmt.Errorf("%w: ihost%s %q", ErrInvalidIntroducer, n, host)
+		}
+	} else if !errors.Is(err, ErrMissingOption) {
+		return introducer, err
+	}
+	if port, err := router_address.option("iport" + n); err == nil {
+		value, err := strconv.Atoi(port)
+		if err != nil || value < 1 || value > 65535 {
+			return introducer, fmt.Errorf("%w: iport%s %q", ErrInvalidIntroducer, n, port)
+		}
+		introducer.Port = value
+	} else if !errors.Is(err, ErrMissingOption) {
+		return introducer, err
+	}
+	return introducer, nil
+}
@@ -0,0 +1,173 @@
+package router_address
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTypedTestAddress(t *testing.T, style string, options map[string]string) *RouterAddress {
+	address, err := NewRouterAddress(5, time.Unix(0, 0), style, options)
+	require.NoError(t, err)
+	return address
+}
+
+func TestNTCP2TypedOptions(t *testing.T) {
+	assert := assert.New(t)
+	static := bytes.Repeat([]byte{0xaa}, 32)
+	iv := bytes.Repeat([]byte{0xbb}, 16)
+	address := newTypedTestAddress(t, "NTCP2", map[string]string{
+		"host": "192.0.2.7",
+		"port": "12345",
+		"s":    base64.EncodeToString(static),
+		"i":    base64.EncodeToString(iv),
+		"v":    "2",
+	})
+
+	ip, err := address.HostIP()
+	assert.NoError(err)
+	assert.True(ip.Equal(net.ParseIP("192.0.2.7")))
+	assert.Len(ip, net.IPv4len)
+
+	port, err := address.PortNumber()
+	assert.NoError(err)
+	assert.Equal(uint16(12345), port)
+
+	key, err := address.StaticKey()
+	assert.NoError(err)
+	assert.Equal(static, key[:])
+
+	gotIV, err := address.InitializationVector()
+	assert.NoError(err)
+	assert.Equal(iv, gotIV[:])
+
+	versions, err := address.ProtocolVersions()
+	assert.NoError(err)
+	assert.Equal([]int{2}, versions)
+}
+
+func TestTypedOptionsValidation(t *testing.T) {
+	assert := assert.New(t)
+	address := newTypedTestAddress(t, "NTCP2", map[string]string{
+		"host": "not-an-ip",
+		"port": "70000",
+		"s":    base64.EncodeToString(make([]byte, 16)),
+		"i":    "!!",
+		"v":    "2,x",
+	})
+
+	_, err := address.HostIP()
+	assert.ErrorIs(err, ErrInvalidHost)
+	_, err = address.PortNumber()
+	assert.ErrorIs(err, ErrInvalidPort)
+	_, err = address.StaticKey()
+	assert.ErrorIs(err, ErrInvalidKey)
+	_, err = address.InitializationVector()
+	assert.Error(err)
+	_, err = address.ProtocolVersions()
+	assert.Error(err)
+}
+
+func TestTypedOptionsMissing(t *testing.T) {
+	assert := assert.New(t)
+	address := newTypedTestAddress(t, "SSU2", map[string]string{"caps": "4"})
+
+	_, err := address.HostIP()
+	assert.ErrorIs(err, ErrMissingOption)
+	_, err = address.PortNumber()
+	assert.ErrorIs(err, ErrMissingOption)
+	_, err = address.StaticKey()
+	assert.ErrorIs(err, ErrMissingOption)
+	introducers, err := address.Introducers()
+	assert.NoError(err)
+	assert.Empty(introducers)
+}
+
+func TestSSU2CapsAndIntroducers(t *testing.T) {
+	assert := assert.New(t)
+	introKey := bytes.Repeat([]byte{0x11}, 32)
+	hash0 := bytes.Repeat([]byte{0x22}, 32)
+	hash2 := bytes.Repeat([]byte{0x33}, 32)
+	address := newTypedTestAddress(t, "SSU2", map[string]string{
+		"caps":  "6BC",
+		"i":     base64.EncodeToString(introKey),
+		"ih0":   base64.EncodeToString(hash0),
+		"itag0": "1234",
+		"iexp0": "1700000000",
+		"ih2":   base64.EncodeToString(hash2),
+		"itag2": "99",
+	})
+
+	assert.Equal(AddressCaps{IPv6: true, Testing: true, Introducer: true}, address.Caps())
+
+	key, err := address.IntroKey()
+	assert.NoError(err)
+	assert.Equal(introKey, key[:])
+
+	introducers, err := address.Introducers()
+	require.NoError(t, err)
+	require.Len(t, introducers, 2)
+	assert.Equal(hash0, introducers[0].Hash[:])
+	assert.Equal(uint32(1234), introducers[0].Tag)
+	assert.Equal(time.Unix(1700000000, 0), introducers[0].Expiration)
+	assert.Equal(hash2, introducers[1].Hash[:])
+	assert.Equal(uint32(99), introducers[1].Tag)
+	assert.True(introducers[1].Expiration.IsZero())
+}
+
+func TestRouterAddressGetOptionTyped(t *testing.T) {
+	assert := assert.New(t)
+	address := newTypedTestAddress(t, "SSU2", map[string]string{"mtu": "1500", "host": "192.0.2.1"})
+	mtu, err := address.GetOptionInt("mtu", 1280)
+	assert.NoError(err)
+	assert.Equal(1500, mtu)
+	_, err = address.GetOptionInt("host", 0)
+	assert.Error(err)
+	assert.Equal("192.0.2.1", address.GetOptionString("host", ""))
+}
+
+func TestIntroducerHostPortAndExpiry(t *testing.T) {
+	assert := assert.New(t)
+	hash := bytes.Repeat([]byte{0x44}, 32)
+	address := newTypedTestAddress(t, "SSU", map[string]string{
+		"ih1":    base64.EncodeToString(hash),
+		"itag1":  "7",
+		"iexp1":  "1700000000",
+		"ihost1": "192.0.2.7",
+		"iport1": "9000",
+	})
+
+	introducers, err := address.Introducers()
+	require.NoError(t, err)
+	require.Len(t, introducers, 1)
+	assert.True(net.ParseIP("192.0.2.7").Equal(introducers[0].Host))
+	assert.Equal(9000, introducers[0].Port)
+	assert.False(introducers[0].Expired(time.Unix(1700000000, 0)))
+	assert.True(introducers[0].Expired(time.Unix(1700000001, 0)))
+	assert.False(Introducer{}.Expired(time.Now()))
+	assert.True(address.Firewalled())
+
+	published := newTypedTestAddress(t, "SSU2", map[string]string{"host": "192.0.2.1", "ih0": base64.EncodeToString(hash), "itag0": "7"})
+	assert.False(published.Firewalled())
+}
+
+func TestIntroducerInvalid(t *testing.T) {
+	hash := base64.EncodeToString(bytes.Repeat([]byte{0x44}, 32))
+	for name, options := range map[string]map[string]string{
+		"short hash": {"ih0": base64.EncodeToString([]byte{1, 2, 3}), "itag0": "1"},
+		"zero tag":   {"ih0": hash, "itag0": "0"},
+		"bad expiry": {"ih0": hash, "itag0": "1", "iexp0": "soon"},
+		"bad host":   {"ih0": hash, "itag0": "1", "ihost0": "example"},
+		"bad port":   {"ih0": hash, "itag0": "1", "iport0": "70000"},
+	} {
+		address := newTypedTestAddress(t, "SSU2", options)
+		_, err := address.Introducers()
+		assert.ErrorIs(t, err, ErrInvalidIntroducer, name)
+		assert.False(t, address.Firewalled(), name)
+	}
+}
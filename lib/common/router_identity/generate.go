@@ -0,0 +1,69 @@
+package router_identity
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	. "github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrUnsupportedKeyType = errors.New("unsupported router identity key type")
+
+// PrivateKeys are the private halves of a generated RouterIdentity.
+type PrivateKeys struct {
+	// SigningPrivateKey signs the RouterInfo
+	SigningPrivateKey crypto.SigningPrivateKey
+	// EncryptionPrivateKey is the 256 byte ElGamal or 32 byte X25519
+	// private key, matching the crypto type of the identity
+	EncryptionPrivateKey []byte
+}
+
+// GenerateRouterIdentity creates a RouterIdentity with fresh keys of the
+// given KEYCERT_SIGN_* and KEYCERT_CRYPTO_* types, for a new router. Keys
+// are generated by crypto.GenerateSigningKeyPair and
+// crypto.GenerateEncryptionKeyPair, new routers should use Ed25519 and
+// X25519.
+//
+// The padding between the keys is generated by GeneratePadding. It is part of
+// the signed identity, KeyPadding returns it to be stored with the keys.
+func GenerateRouterIdentity(sigType, cryptoType int) (*RouterIdentity, *PrivateKeys, error) {
+	log.WithFields(logrus.Fields{
+		"sig_type":    sigType,
+		"crypto_type": cryptoType,
+	}).Debug("Generating RouterIdentity")
+	signingPublicKey, signingPrivateKey, err := crypto.GenerateSigningKeyPair(sigType)
+	if errors.Is(err, crypto.ErrUnsupportedKeyType) {
+		return nil, nil, fmt.Errorf("%w: signature type %d", ErrUnsupportedKeyType, sigType)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	publicKey, privateKey, err := crypto.GenerateEncryptionKeyPair(cryptoType)
+	if errors.Is(err, crypto.ErrUnsupportedKeyType) {
+		return nil, nil, fmt.Errorf("%w: crypto type %d", ErrUnsupportedKeyType, cryptoType)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	keys := &PrivateKeys{
+		SigningPrivateKey:    signingPrivateKey,
+		EncryptionPrivateKey: privateKey.Bytes(),
+	}
+
+	keyCert, err := key_certificate.BuildKeyCertificate(sigType, cryptoType, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	padding, err := GeneratePadding(KEYS_AND_CERT_DATA_SIZE - publicKey.Len() - signingPublicKey.Len())
+	if err != nil {
+		return nil, nil, err
+	}
+	identity, err := NewRouterIdentity(publicKey, signingPublicKey, keyCert.Certificate, padding)
+	if err != nil {
+		return nil, nil, err
+	}
+	return identity, keys, nil
+}
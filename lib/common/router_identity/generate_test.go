@@ -0,0 +1,61 @@
+package router_identity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRouterIdentity(t *testing.T) {
+	for _, cryptoType := range []int{key_certificate.KEYCERT_CRYPTO_X25519, key_certificate.KEYCERT_CRYPTO_ELG} {
+		identity, keys, err := GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, cryptoType)
+		require.NoError(t, err)
+
+		data := identity.Bytes()
+		// 384 bytes of keys and padding plus a 7 byte key certificate
+		assert.Len(t, data, 391)
+		parsed, remainder, err := ReadRouterIdentity(data)
+		require.NoError(t, err)
+		assert.Empty(t, remainder)
+		assert.Equal(t, key_certificate.KEYCERT_SIGN_ED25519, parsed.KeyCertificate.SigningPublicKeyType())
+		assert.Equal(t, cryptoType, parsed.KeyCertificate.PublicKeyType())
+		assert.Equal(t, parsed.PublicKey().Len(), map[int]int{
+			key_certificate.KEYCERT_CRYPTO_X25519: 32,
+			key_certificate.KEYCERT_CRYPTO_ELG:    256,
+		}[cryptoType])
+		assert.Len(t, keys.EncryptionPrivateKey, parsed.PublicKey().Len())
+
+		// the signing key belongs to the identity
+		signer, err := keys.SigningPrivateKey.NewSigner()
+		require.NoError(t, err)
+		sig, err := signer.Sign([]byte("router info"))
+		require.NoError(t, err)
+		verifier, err := identity.SigningPublicKey().NewVerifier()
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify([]byte("router info"), sig))
+	}
+}
+
+func TestGenerateRouterIdentityPadding(t *testing.T) {
+	identity, _, err := GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	padding := identity.Padding
+	require.Len(t, padding, 384-32-32)
+	for i := 32; i < len(padding); i += 32 {
+		end := i + 32
+		if end > len(padding) {
+			end = len(padding)
+		}
+		assert.True(t, bytes.Equal(padding[:end-i], padding[i:end]))
+	}
+}
+
+func TestGenerateRouterIdentityUnsupported(t *testing.T) {
+	_, _, err := GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_RSA4096, key_certificate.KEYCERT_CRYPTO_X25519)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+	_, _, err = GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_P521)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+}
@@ -3,6 +3,7 @@ package router_identity
 
 import (
 	"github.com/go-i2p/go-i2p/lib/common/certificate"
+	common "github.com/go-i2p/go-i2p/lib/common/data"
 	"github.com/go-i2p/go-i2p/lib/common/destination"
 	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
 	. "github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
@@ -31,14 +32,13 @@ type RouterIdentity struct {
 	KeysAndCert
 }
 
-// ReadRouterIdentity returns RouterIdentity from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadRouterIdentity(data []byte) (router_identity RouterIdentity, remainder []byte, err error) {
+// readRouterIdentity reads a RouterIdentity, returning the data after it
+func readRouterIdentity(data []byte, opts ...common.ParseOption) (router_identity RouterIdentity, remainder []byte, err error) {
 	log.WithFields(logrus.Fields{
 		"input_length": len(data),
 	}).Debug("Reading RouterIdentity from data")
-	keys_and_cert, remainder, err := ReadKeysAndCert(data)
+	keys_and_cert, n, err := ParseKeysAndCert(data, opts...)
+	remainder = data[n:]
 	if err != nil {
 		log.WithError(err).Error("Failed to read KeysAndCert for RouterIdentity")
 		return
@@ -52,6 +52,32 @@ func ReadRouterIdentity(data []byte) (router_identity RouterIdentity, remainder
 	return
 }
 
+// ParseRouterIdentity reads a RouterIdentity from the start of data and
+// returns it with the number of bytes consumed. The RouterIdentity aliases
+// data unless CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseRouterIdentity(data []byte, opts ...common.ParseOption) (RouterIdentity, int, error) {
+	value, remainder, err := readRouterIdentity(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadRouterIdentity returns RouterIdentity from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns a list of errors that occurred during parsing. The RouterIdentity
+// aliases data unless CopyOnParse is given.
+//
+// Deprecated: use ParseRouterIdentity.
+func ReadRouterIdentity(data []byte, opts ...common.ParseOption) (RouterIdentity, []byte, error) {
+	return readRouterIdentity(data, opts...)
+}
+
+// Clone returns a copy of the RouterIdentity which does not alias the buffer
+// it was read from.
+func (router_identity RouterIdentity) Clone() RouterIdentity {
+	return RouterIdentity{router_identity.KeysAndCert.Clone()}
+}
+
 func NewRouterIdentity(publicKey crypto.PublicKey, signingPublicKey crypto.SigningPublicKey, cert certificate.Certificate, padding []byte) (*RouterIdentity, error) {
 	log.Debug("Creating new RouterIdentity")
 
@@ -0,0 +1,78 @@
+package router_info
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	. "github.com/go-i2p/go-i2p/lib/common/router_address"
+)
+
+// BestAddress returns the cheapest RouterAddress of the given transport style
+// (such as "NTCP2" or "SSU2", compared case-insensitively) that can be dialed:
+// it has not expired and has a valid host IP and port. Addresses of the
+// preferred IP family win over cheaper ones of the other family, which is
+// only used as a fallback. Returns nil if no address qualifies.
+func (router_info *RouterInfo) BestAddress(style string, preferIPv6 bool) *RouterAddress {
+	now := time.Now()
+	var best *RouterAddress
+	bestPreferred := false
+	for _, address := range router_info.addresses {
+		ip, ok := dialableAddress(address, style, now)
+		if !ok {
+			continue
+		}
+		preferred := (ip.To4() == nil) == preferIPv6
+		switch {
+		case best == nil:
+		case preferred && !bestPreferred:
+		case preferred == bestPreferred && address.Cost() < best.Cost():
+		default:
+			continue
+		}
+		best, bestPreferred = address, preferred
+	}
+	log.WithFields(logrus.Fields{
+		"style":       style,
+		"prefer_ipv6": preferIPv6,
+		"found":       best != nil,
+	}).Debug("Selected RouterAddress")
+	return best
+}
+
+// dialableAddress checks an address for BestAddress and returns its host IP.
+func dialableAddress(address *RouterAddress, style string, now time.Time) (net.IP, bool) {
+	if address == nil || address.TransportType == nil || address.TransportOptions == nil {
+		return nil, false
+	}
+	transport, err := address.TransportStyle().Data()
+	if err != nil || !strings.EqualFold(transport, style) {
+		return nil, false
+	}
+	// the spec says expiration is always zero, but honour one if it is set
+	if address.ExpirationDate != nil {
+		if expiration := address.Expiration(); !expiration.IsZero() && expiration.Time().Before(now) {
+			return nil, false
+		}
+	}
+	host, err := address.HostString().Data()
+	if err != nil {
+		return nil, false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsUnspecified() || ip.IsMulticast() {
+		return nil, false
+	}
+	portString, err := address.PortString().Data()
+	if err != nil {
+		return nil, false
+	}
+	port, err := strconv.Atoi(portString)
+	if err != nil || port < 1 || port > 65535 {
+		return nil, false
+	}
+	return ip, true
+}
@@ -0,0 +1,54 @@
+package router_info
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAddress(t *testing.T, cost uint8, expiration time.Time, style, host, port string) *router_address.RouterAddress {
+	options := map[string]string{}
+	if host != "" {
+		options["host"] = host
+	}
+	if port != "" {
+		options["port"] = port
+	}
+	address, err := router_address.NewRouterAddress(cost, expiration, style, options)
+	require.NoError(t, err)
+	return address
+}
+
+func TestRouterInfoBestAddress(t *testing.T) {
+	never := time.Unix(0, 0)
+	cheapV4 := testAddress(t, 3, never, "NTCP2", "192.0.2.1", "12345")
+	expensiveV4 := testAddress(t, 10, never, "NTCP2", "192.0.2.2", "12345")
+	v6 := testAddress(t, 5, never, "NTCP2", "2001:db8::1", "12345")
+	ssu := testAddress(t, 1, never, "SSU2", "192.0.2.3", "12346")
+	cheaperButInvalid := []*router_address.RouterAddress{
+		testAddress(t, 0, never, "NTCP2", "", "12345"),
+		testAddress(t, 0, never, "NTCP2", "0.0.0.0", "12345"),
+		testAddress(t, 0, never, "NTCP2", "192.0.2.4", "0"),
+		testAddress(t, 0, never, "NTCP2", "192.0.2.4", "70000"),
+		testAddress(t, 0, never, "NTCP2", "not-an-ip", "12345"),
+		testAddress(t, 0, time.Now().Add(-time.Hour), "NTCP2", "192.0.2.5", "12345"),
+	}
+
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	builder := NewRouterInfoBuilder(routerIdentity, privateKey)
+	for _, address := range append(cheaperButInvalid, expensiveV4, v6, cheapV4, ssu) {
+		builder.AddAddress(address)
+	}
+	routerInfo, err := builder.Build()
+	require.NoError(t, err)
+
+	assert.Same(t, cheapV4, routerInfo.BestAddress("NTCP2", false))
+	assert.Same(t, cheapV4, routerInfo.BestAddress("ntcp2", false))
+	assert.Same(t, v6, routerInfo.BestAddress("NTCP2", true))
+	// falls back to the other family
+	assert.Same(t, ssu, routerInfo.BestAddress("SSU2", true))
+	assert.Nil(t, routerInfo.BestAddress("SSU", false))
+}
@@ -0,0 +1,72 @@
+package router_info
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+
+	. "github.com/go-i2p/go-i2p/lib/common/router_address"
+	. "github.com/go-i2p/go-i2p/lib/common/router_identity"
+)
+
+// RouterInfoBuilder collects the fields of a RouterInfo for our own router
+// and signs the result with the router's signing private key.
+// The signature type is taken from the RouterIdentity's key certificate.
+type RouterInfoBuilder struct {
+	routerIdentity    *RouterIdentity
+	signingPrivateKey crypto.SigningPrivateKey
+	published         time.Time
+	addresses         []*RouterAddress
+	options           map[string]string
+}
+
+// NewRouterInfoBuilder returns a RouterInfoBuilder for the given identity and
+// signing key. The published date defaults to the time Build is called.
+func NewRouterInfoBuilder(routerIdentity *RouterIdentity, signingPrivateKey crypto.SigningPrivateKey) *RouterInfoBuilder {
+	return &RouterInfoBuilder{
+		routerIdentity:    routerIdentity,
+		signingPrivateKey: signingPrivateKey,
+		options:           make(map[string]string),
+	}
+}
+
+// Published sets the published date of the RouterInfo.
+func (builder *RouterInfoBuilder) Published(published time.Time) *RouterInfoBuilder {
+	builder.published = published
+	return builder
+}
+
+// AddAddress appends a RouterAddress to the RouterInfo.
+func (builder *RouterInfoBuilder) AddAddress(address *RouterAddress) *RouterInfoBuilder {
+	builder.addresses = append(builder.addresses, address)
+	return builder
+}
+
+// SetOption sets a single key/value pair in the RouterInfo options.
+func (builder *RouterInfoBuilder) SetOption(key, value string) *RouterInfoBuilder {
+	builder.options[key] = value
+	return builder
+}
+
+// Build serializes and signs the RouterInfo.
+func (builder *RouterInfoBuilder) Build() (*RouterInfo, error) {
+	if builder.routerIdentity == nil {
+		return nil, fmt.Errorf("error building router info: %w", ErrNoRouterIdentity)
+	}
+	if builder.signingPrivateKey == nil {
+		return nil, fmt.Errorf("error building router info: %w", ErrNoSigningPrivateKey)
+	}
+	if len(builder.addresses) > 255 {
+		return nil, fmt.Errorf("error building router info: %w", ErrTooManyAddresses)
+	}
+	if builder.routerIdentity.KeyCertificate == nil {
+		return nil, fmt.Errorf("error building router info: %w", ErrNoKeyCertificate)
+	}
+	sigType := builder.routerIdentity.KeyCertificate.SigningPublicKeyType()
+	published := builder.published
+	if published.IsZero() {
+		published = time.Now()
+	}
+	return NewRouterInfo(builder.routerIdentity, published, builder.addresses, builder.options, builder.signingPrivateKey, sigType)
+}
@@ -0,0 +1,159 @@
+package router_info
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Compression modes of a RouterInfo bundle.
+const (
+	// BUNDLE_GZIP compresses the bundle with plain gzip
+	BUNDLE_GZIP byte = 0
+	// BUNDLE_DICTIONARY compresses the bundle with deflate primed with
+	// bundleDictionary, which holds the option keys and transport strings
+	// nearly every RouterInfo repeats. Most of a RouterInfo is random key
+	// material, the dictionary saves on the structure around it.
+	BUNDLE_DICTIONARY byte = 1
+)
+
+// bundleMagic starts every RouterInfo bundle, followed by the mode byte
+var bundleMagic = []byte("RIBN")
+
+var (
+	ErrNotBundle           = errors.New("not a RouterInfo bundle")
+	ErrUnknownBundleMode   = errors.New("unknown RouterInfo bundle compression mode")
+	ErrBundleEntryTooLarge = errors.New("too large for a bundle")
+)
+
+// bundleDictionaryStrings are the strings most RouterInfos contain. They are
+// part of the BUNDLE_DICTIONARY format: changing them breaks reading existing
+// bundles, so a new list needs a new mode. The most common strings come last,
+// deflate finds matches near the end of the dictionary with shorter distances.
+var bundleDictionaryStrings = []string{
+	"iexp0", "iexp1", "iexp2", "itag0", "itag1", "itag2", "ih0", "ih1", "ih2",
+	"family", "family.key", "family.sig",
+	"netdb.knownLeaseSets", "netdb.knownRouters",
+	"mtu", "v", "i", "s", "port", "host",
+	"router.version", "netId", "caps",
+	"NTCP2", "SSU2",
+}
+
+// bundleDictionary is built from bundleDictionaryStrings the way they appear
+// inside a serialized RouterInfo: length prefixed, keys followed by '='.
+var bundleDictionary = func() []byte {
+	var dict bytes.Buffer
+	for _, s := range bundleDictionaryStrings {
+		dict.WriteByte(byte(len(s)))
+		dict.WriteString(s)
+		if s != "NTCP2" && s != "SSU2" {
+			dict.WriteByte('=')
+		}
+	}
+	// key certificate for Ed25519 signing and X25519 encryption keys
+	dict.Write([]byte{0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04})
+	// values shared by most routers on the main network
+	dict.Write([]byte("\x01v=\x012;\x0erouter.version=\x060.9.6"))
+	dict.Write([]byte("\x05netId=\x012;"))
+	return dict.Bytes()
+}()
+
+// WriteBundle writes infos as a single compressed bundle, for reseed files
+// and bulk transfers. Each RouterInfo is stored in its wire format behind a 2
+// byte length. Single RouterInfos sent in a DatabaseStore are not affected.
+func WriteBundle(w io.Writer, infos []*RouterInfo, mode byte) error {
+	log.WithFields(logrus.Fields{
+		"count": len(infos),
+		"mode":  mode,
+	}).Debug("Writing RouterInfo bundle")
+	if _, err := w.Write(append(append([]byte(nil), bundleMagic...), mode)); err != nil {
+		return err
+	}
+	var compressor io.WriteCloser
+	var err error
+	switch mode {
+	case BUNDLE_GZIP:
+		compressor, err = gzip.NewWriterLevel(w, gzip.BestCompression)
+	case BUNDLE_DICTIONARY:
+		compressor, err = flate.NewWriterDict(w, flate.BestCompression, bundleDictionary)
+	default:
+		return ErrUnknownBundleMode
+	}
+	if err != nil {
+		return err
+	}
+	var length [2]byte
+	for _, info := range infos {
+		data, err := info.Bytes()
+		if err != nil {
+			return err
+		}
+		if len(data) > 0xffff {
+			return fmt.Errorf("RouterInfo of %d bytes is %w", len(data), ErrBundleEntryTooLarge)
+		}
+		binary.BigEndian.PutUint16(length[:], uint16(len(data)))
+		if _, err := compressor.Write(length[:]); err != nil {
+			return err
+		}
+		if _, err := compressor.Write(data); err != nil {
+			return err
+		}
+	}
+	return compressor.Close()
+}
+
+// ReadBundle reads the RouterInfos from a bundle written by WriteBundle.
+func ReadBundle(r io.Reader) ([]RouterInfo, error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(bundleMagic)+1)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, ErrNotBundle
+	}
+	if !bytes.Equal(header[:len(bundleMagic)], bundleMagic) {
+		return nil, ErrNotBundle
+	}
+	var decompressor io.ReadCloser
+	switch header[len(bundleMagic)] {
+	case BUNDLE_GZIP:
+		zr, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		decompressor = zr
+	case BUNDLE_DICTIONARY:
+		decompressor = flate.NewReaderDict(reader, bundleDictionary)
+	default:
+		return nil, ErrUnknownBundleMode
+	}
+	defer decompressor.Close()
+
+	var infos []RouterInfo
+	var length [2]byte
+	for {
+		if _, err := io.ReadFull(decompressor, length[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		data := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err := io.ReadFull(decompressor, data); err != nil {
+			return nil, err
+		}
+		info, _, err := readRouterInfo(data)
+		if err != nil {
+			log.WithError(err).Error("Failed to read RouterInfo from bundle")
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	log.WithField("count", len(infos)).Debug("Read RouterInfo bundle")
+	return infos, nil
+}
@@ -0,0 +1,69 @@
+package router_info
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle(t *testing.T, count int) []*RouterInfo {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	var infos []*RouterInfo
+	for i := 0; i < count; i++ {
+		address, err := router_address.NewRouterAddress(10, time.Unix(0, 0), "NTCP2", map[string]string{
+			"host": fmt.Sprintf("192.0.2.%d", i+1),
+			"port": fmt.Sprintf("%d", 10000+i),
+			"v":    "2",
+		})
+		require.NoError(t, err)
+		info, err := NewRouterInfoBuilder(routerIdentity, privateKey).
+			AddAddress(address).
+			SetOption("caps", "LR").
+			SetOption("netId", "2").
+			SetOption("router.version", "0.9.64").
+			Build()
+		require.NoError(t, err)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	infos := newTestBundle(t, 3)
+	for _, mode := range []byte{BUNDLE_GZIP, BUNDLE_DICTIONARY} {
+		var buf bytes.Buffer
+		require.NoError(t, WriteBundle(&buf, infos, mode))
+		read, err := ReadBundle(&buf)
+		require.NoError(t, err)
+		require.Len(t, read, len(infos))
+		for i := range infos {
+			want, err := infos[i].Bytes()
+			require.NoError(t, err)
+			got, err := read[i].Bytes()
+			require.NoError(t, err)
+			assert.Equal(t, want, got, "mode %d router info %d", mode, i)
+		}
+	}
+}
+
+func TestBundleDictionaryIsSmaller(t *testing.T) {
+	infos := newTestBundle(t, 1)
+	var plain, dict bytes.Buffer
+	require.NoError(t, WriteBundle(&plain, infos, BUNDLE_GZIP))
+	require.NoError(t, WriteBundle(&dict, infos, BUNDLE_DICTIONARY))
+	t.Logf("gzip %d bytes, dictionary %d bytes", plain.Len(), dict.Len())
+	assert.Less(t, dict.Len(), plain.Len())
+}
+
+func TestReadBundleRejectsOtherData(t *testing.T) {
+	_, err := ReadBundle(bytes.NewReader([]byte("not a bundle")))
+	assert.ErrorIs(t, err, ErrNotBundle)
+	_, err = ReadBundle(bytes.NewReader(append([]byte("RIBN"), 9)))
+	assert.ErrorIs(t, err, ErrUnknownBundleMode)
+	assert.ErrorIs(t, WriteBundle(&bytes.Buffer{}, nil, 9), ErrUnknownBundleMode)
+}
@@ -0,0 +1,113 @@
+package router_info
+
+import "strings"
+
+// Bandwidth tiers published in the caps option, slowest first.
+//
+// https://geti2p.net/spec/common-structures#routerinfo
+const (
+	BANDWIDTH_K = 'K' // under 12 KBps
+	BANDWIDTH_L = 'L' // 12 - 48 KBps
+	BANDWIDTH_M = 'M' // 48 - 64 KBps
+	BANDWIDTH_N = 'N' // 64 - 128 KBps
+	BANDWIDTH_O = 'O' // 128 - 256 KBps
+	BANDWIDTH_P = 'P' // 256 - 2000 KBps
+	BANDWIDTH_X = 'X' // over 2000 KBps
+)
+
+// Other capability letters.
+const (
+	CAP_FLOODFILL   = 'f'
+	CAP_HIDDEN      = 'H'
+	CAP_REACHABLE   = 'R'
+	CAP_UNREACHABLE = 'U'
+	// congestion caps, from least to most congested
+	CONGESTION_D = 'D' // moderately congested or a low-performance router
+	CONGESTION_E = 'E' // severely congested or at the participating tunnel limit
+	CONGESTION_G = 'G' // rejecting all tunnels
+)
+
+const bandwidthTiers = "KLMNOPX"
+
+// Capabilities is the parsed form of the RouterInfo caps option.
+type Capabilities struct {
+	// Bandwidth is the highest bandwidth tier letter, 0 if none is published
+	Bandwidth byte
+	Floodfill bool
+	Hidden    bool
+	// Reachable and Unreachable are both false when reachability is unknown
+	Reachable   bool
+	Unreachable bool
+	// Congestion is D, E or G, 0 if the router is not congested
+	Congestion byte
+	// Unknown holds letters this implementation does not understand, in order
+	Unknown string
+}
+
+// ParseCapabilities parses the value of a caps option.
+func ParseCapabilities(caps string) Capabilities {
+	var c Capabilities
+	var unknown strings.Builder
+	for i := 0; i < len(caps); i++ {
+		letter := caps[i]
+		switch letter {
+		case BANDWIDTH_K, BANDWIDTH_L, BANDWIDTH_M, BANDWIDTH_N, BANDWIDTH_O, BANDWIDTH_P, BANDWIDTH_X:
+			// P and X routers also publish O for older readers
+			if c.Bandwidth == 0 || strings.IndexByte(bandwidthTiers, letter) > strings.IndexByte(bandwidthTiers, c.Bandwidth) {
+				c.Bandwidth = letter
+			}
+		case CAP_FLOODFILL:
+			c.Floodfill = true
+		case CAP_HIDDEN:
+			c.Hidden = true
+		case CAP_REACHABLE:
+			c.Reachable = true
+		case CAP_UNREACHABLE:
+			c.Unreachable = true
+		case CONGESTION_D, CONGESTION_E, CONGESTION_G:
+			if strings.IndexByte("DEG", letter) > strings.IndexByte("DEG", c.Congestion) {
+				c.Congestion = letter
+			}
+		default:
+			unknown.WriteByte(letter)
+		}
+	}
+	c.Unknown = unknown.String()
+	return c
+}
+
+// String serializes the capabilities into a caps option value, in the order
+// the Java router writes them: bandwidth, floodfill, hidden, reachability,
+// congestion, then any unknown letters.
+func (c Capabilities) String() string {
+	var caps strings.Builder
+	if c.Bandwidth == BANDWIDTH_P || c.Bandwidth == BANDWIDTH_X {
+		caps.WriteByte(BANDWIDTH_O)
+	}
+	if c.Bandwidth != 0 {
+		caps.WriteByte(c.Bandwidth)
+	}
+	if c.Floodfill {
+		caps.WriteByte(CAP_FLOODFILL)
+	}
+	if c.Hidden {
+		caps.WriteByte(CAP_HIDDEN)
+	}
+	if c.Reachable {
+		caps.WriteByte(CAP_REACHABLE)
+	}
+	if c.Unreachable {
+		caps.WriteByte(CAP_UNREACHABLE)
+	}
+	if c.Congestion != 0 {
+		caps.WriteByte(c.Congestion)
+	}
+	caps.WriteString(c.Unknown)
+	return caps.String()
+}
+
+// Congested reports whether the router is too congested or too slow to ask
+// to participate in tunnels.
+func (c Capabilities) Congested() bool {
+	return c.Bandwidth == BANDWIDTH_K || c.Congestion == CONGESTION_E || c.Congestion == CONGESTION_G
+}
@@ -0,0 +1,61 @@
+package router_info
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCapabilities(t *testing.T) {
+	tests := []struct {
+		caps string
+		want Capabilities
+	}{
+		{"", Capabilities{}},
+		{"LR", Capabilities{Bandwidth: BANDWIDTH_L, Reachable: true}},
+		{"OfR", Capabilities{Bandwidth: BANDWIDTH_O, Floodfill: true, Reachable: true}},
+		{"XOfR", Capabilities{Bandwidth: BANDWIDTH_X, Floodfill: true, Reachable: true}},
+		{"NU", Capabilities{Bandwidth: BANDWIDTH_N, Unreachable: true}},
+		{"LHU", Capabilities{Bandwidth: BANDWIDTH_L, Hidden: true, Unreachable: true}},
+		{"PRDG", Capabilities{Bandwidth: BANDWIDTH_P, Reachable: true, Congestion: CONGESTION_G}},
+		{"MRz", Capabilities{Bandwidth: BANDWIDTH_M, Reachable: true, Unknown: "z"}},
+	}
+	for _, test := range tests {
+		t.Run(test.caps, func(t *testing.T) {
+			assert.Equal(t, test.want, ParseCapabilities(test.caps))
+		})
+	}
+}
+
+func TestCapabilitiesString(t *testing.T) {
+	for _, caps := range []string{"", "LR", "OfR", "OPfR", "OXfR", "NU", "LHU", "ORE", "MRz"} {
+		assert.Equal(t, caps, ParseCapabilities(caps).String())
+	}
+	// P and X also publish O, and letters come out in a canonical order
+	assert.Equal(t, "OPfRD", Capabilities{Bandwidth: BANDWIDTH_P, Congestion: CONGESTION_D, Reachable: true, Floodfill: true}.String())
+	assert.Equal(t, "OfR", ParseCapabilities("RfO").String())
+}
+
+func TestCapabilitiesCongested(t *testing.T) {
+	assert.True(t, ParseCapabilities("KR").Congested())
+	assert.True(t, ParseCapabilities("ORE").Congested())
+	assert.True(t, ParseCapabilities("ORG").Congested())
+	assert.False(t, ParseCapabilities("ORD").Congested())
+	assert.False(t, ParseCapabilities("OfR").Congested())
+}
+
+func TestRouterInfoCapabilitiesOption(t *testing.T) {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	routerInfo, err := NewRouterInfoBuilder(routerIdentity, privateKey).
+		SetOption("caps", "XOfR").
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "XOfR", routerInfo.RouterCapabilities())
+	caps := routerInfo.Capabilities()
+	assert.Equal(t, byte(BANDWIDTH_X), caps.Bandwidth)
+	assert.True(t, caps.Floodfill)
+	assert.True(t, routerInfo.Reachable())
+	assert.True(t, routerInfo.UnCongested())
+}
@@ -0,0 +1,71 @@
+package router_info
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MAX_UNCOMPRESSED_SIZE bounds how much a compressed RouterInfo may expand
+// to. Real RouterInfos are a few kilobytes, anything much larger is an
+// attempt to exhaust memory with a small, highly compressible payload.
+const MAX_UNCOMPRESSED_SIZE = 64 * 1024
+
+var ErrRouterInfoTooLarge = errors.New("compressed router info expands beyond the size limit")
+
+// CompressedBytes returns the RouterInfo gzip compressed, the form it takes
+// in DatabaseStore messages.
+func (router_info *RouterInfo) CompressedBytes() ([]byte, error) {
+	data, err := router_info.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadCompressedRouterInfo reads a gzip compressed RouterInfo, refusing to
+// decompress more than MAX_UNCOMPRESSED_SIZE bytes.
+func ReadCompressedRouterInfo(data []byte) (RouterInfo, error) {
+	uncompressed, err := gunzipLimited(data, MAX_UNCOMPRESSED_SIZE)
+	if err != nil {
+		return RouterInfo{}, err
+	}
+	info, remainder, err := readRouterInfo(uncompressed)
+	if err != nil {
+		return RouterInfo{}, err
+	}
+	if len(remainder) != 0 {
+		log.WithField("remainder_length", len(remainder)).Warn("Trailing data after compressed RouterInfo")
+	}
+	return info, nil
+}
+
+// gunzipLimited decompresses data, failing if it expands beyond limit bytes
+func gunzipLimited(data []byte, limit int64) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		log.WithError(err).Error("Failed to open gzip compressed RouterInfo")
+		return nil, err
+	}
+	defer zr.Close()
+	uncompressed, err := io.ReadAll(io.LimitReader(zr, limit+1))
+	if err != nil {
+		log.WithError(err).Error("Failed to decompress RouterInfo")
+		return nil, err
+	}
+	if int64(len(uncompressed)) > limit {
+		log.WithField("limit", limit).Warn("Rejecting oversized compressed RouterInfo")
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrRouterInfoTooLarge, limit)
+	}
+	return uncompressed, nil
+}
@@ -0,0 +1,38 @@
+package router_info
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressedRouterInfoRoundTrip(t *testing.T) {
+	original := newTestBundle(t, 1)[0]
+	compressed, err := original.CompressedBytes()
+	require.NoError(t, err)
+
+	read, err := ReadCompressedRouterInfo(compressed)
+	require.NoError(t, err)
+	want, err := original.Bytes()
+	require.NoError(t, err)
+	got, err := read.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestCompressedRouterInfoSizeLimit(t *testing.T) {
+	var bomb bytes.Buffer
+	zw := gzip.NewWriter(&bomb)
+	_, err := zw.Write(make([]byte, MAX_UNCOMPRESSED_SIZE+1))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.Less(t, bomb.Len(), 1024)
+
+	_, err = ReadCompressedRouterInfo(bomb.Bytes())
+	assert.ErrorIs(t, err, ErrRouterInfoTooLarge)
+	_, err = ReadCompressedRouterInfo([]byte("not gzip"))
+	assert.Error(t, err)
+}
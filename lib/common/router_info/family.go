@@ -0,0 +1,256 @@
+package router_info
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+// RouterInfo options declaring membership of a router family. The signature
+// covers the family name followed by the router hash, so a family key holder
+// vouches for each member individually.
+//
+// https://geti2p.net/spec/family
+const (
+	FAMILY_OPTION     = "family"
+	FAMILY_KEY_OPTION = "family.key"
+	FAMILY_SIG_OPTION = "family.sig"
+)
+
+var (
+	ErrNoFamily             = errors.New("router info does not declare a family")
+	ErrInvalidFamily        = errors.New("router info has malformed family options")
+	ErrFamilyUntrusted      = errors.New("router family is not signed by a trusted family key")
+	ErrFamilyKeyMismatch    = errors.New("router family key does not match the trusted family key")
+	ErrFamilySignature      = errors.New("router family signature is invalid")
+	ErrUnsupportedFamilyKey = errors.New("unsupported family key type")
+)
+
+// Family is the family membership published in a RouterInfo.
+type Family struct {
+	Name      string
+	SigType   int
+	Key       crypto.SigningPublicKey
+	Signature []byte
+}
+
+// Family returns the family the RouterInfo claims to belong to, or
+// ErrNoFamily. The claim is not verified, see FamilyVerifier.
+func (router_info *RouterInfo) Family() (*Family, error) {
+	options := router_info.OptionsMap()
+	name, ok := options[FAMILY_OPTION]
+	if !ok || name == "" {
+		return nil, ErrNoFamily
+	}
+	family := &Family{Name: name}
+	sigType, key, err := parseFamilyKey(options[FAMILY_KEY_OPTION])
+	if err != nil {
+		return nil, err
+	}
+	family.SigType = sigType
+	family.Key = key
+	family.Signature, err = base64.DecodeString(options[FAMILY_SIG_OPTION])
+	if err != nil || len(family.Signature) == 0 {
+		return nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_SIG_OPTION)
+	}
+	return family, nil
+}
+
+// FamilyOptions returns the options which declare the router with hash
+// routerHash a member of the family name, signed with the family key.
+func FamilyOptions(name string, routerHash Hash, familyKey crypto.SigningPrivateKey) (map[string]string, error) {
+	if name == "" {
+		return nil, fmt.Errorf("%w: empty family name", ErrInvalidFamily)
+	}
+	publicKey, err := familyKey.Public()
+	if err != nil {
+		return nil, err
+	}
+	sigType, err := familyKeyType(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := familyKey.NewSigner()
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(familySignedData(name, routerHash))
+	if err != nil {
+		log.WithError(err).Error("Failed to sign router family")
+		return nil, err
+	}
+	return map[string]string{
+		FAMILY_OPTION:     name,
+		FAMILY_KEY_OPTION: strconv.Itoa(sigType) + ";" + base64.EncodeToString(publicKey.Bytes()),
+		FAMILY_SIG_OPTION: base64.EncodeToString(sig),
+	}, nil
+}
+
+// JoinFamily signs the RouterInfo into the family name with the family key
+// and republishes it.
+func (owned *OwnedRouterInfo) JoinFamily(name string, familyKey crypto.SigningPrivateKey) error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	options, err := FamilyOptions(name, owned.current.IdentHash(), familyKey)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]string, len(owned.options))
+	for key, value := range owned.options {
+		previous[key] = value
+	}
+	for key, value := range options {
+		owned.options[key] = value
+	}
+	if err := owned.rebuild(); err != nil {
+		owned.options = previous
+		return err
+	}
+	log.WithField("family", name).Info("Joined router family")
+	return nil
+}
+
+// FamilyVerifier checks family claims against the keys of trusted families.
+type FamilyVerifier struct {
+	mutex   sync.RWMutex
+	trusted map[string]crypto.SigningPublicKey
+}
+
+// NewFamilyVerifier returns a verifier which trusts no families yet.
+func NewFamilyVerifier() *FamilyVerifier {
+	return &FamilyVerifier{trusted: make(map[string]crypto.SigningPublicKey)}
+}
+
+// Trust adds or replaces the key of a trusted family.
+func (verifier *FamilyVerifier) Trust(name string, key crypto.SigningPublicKey) {
+	verifier.mutex.Lock()
+	defer verifier.mutex.Unlock()
+	verifier.trusted[name] = key
+}
+
+// Verify returns the family of the RouterInfo if its claim is signed by the
+// trusted key of that family. For a family with no trusted key the self
+// signed claim is still checked, and the family is returned together with
+// ErrFamilyUntrusted.
+func (verifier *FamilyVerifier) Verify(router_info *RouterInfo) (*Family, error) {
+	family, err := router_info.Family()
+	if err != nil {
+		return nil, err
+	}
+	verifier.mutex.RLock()
+	trusted, ok := verifier.trusted[family.Name]
+	verifier.mutex.RUnlock()
+	key := family.Key
+	if ok {
+		if string(trusted.Bytes()) != string(family.Key.Bytes()) {
+			return nil, ErrFamilyKeyMismatch
+		}
+		key = trusted
+	}
+	v, err := key.NewVerifier()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.Verify(familySignedData(family.Name, router_info.IdentHash()), family.Signature); err != nil {
+		log.WithError(err).WithField("family", family.Name).Warn("Invalid router family signature")
+		return nil, ErrFamilySignature
+	}
+	if !ok {
+		return family, ErrFamilyUntrusted
+	}
+	return family, nil
+}
+
+func familySignedData(name string, routerHash Hash) []byte {
+	data := make([]byte, 0, len(name)+len(routerHash))
+	data = append(data, name...)
+	return append(data, routerHash[:]...)
+}
+
+// parseFamilyKey parses a family.key value, the signature type code and the
+// base64 public key separated by a semicolon.
+func parseFamilyKey(value string) (int, crypto.SigningPublicKey, error) {
+	code, encoded, found := strings.Cut(value, ";")
+	if !found {
+		return 0, nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_KEY_OPTION)
+	}
+	sigType, err := strconv.Atoi(code)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_KEY_OPTION)
+	}
+	data, err := base64.DecodeString(encoded)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_KEY_OPTION)
+	}
+	switch sigType {
+	case signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519:
+		if len(data) != ed25519.PublicKeySize {
+			return 0, nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_KEY_OPTION)
+		}
+		return sigType, crypto.Ed25519PublicKey(data), nil
+	case signature.SIGNATURE_TYPE_ECDSA_SHA256_P256:
+		var key crypto.ECP256PublicKey
+		if len(data) != len(key) {
+			return 0, nil, fmt.Errorf("%w: bad %s", ErrInvalidFamily, FAMILY_KEY_OPTION)
+		}
+		copy(key[:], data)
+		return sigType, key, nil
+	default:
+		return 0, nil, fmt.Errorf("%w: signature type %d", ErrUnsupportedFamilyKey, sigType)
+	}
+}
+
+func familyKeyType(key crypto.SigningPublicKey) (int, error) {
+	switch key.(type) {
+	case crypto.Ed25519PublicKey:
+		return signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, nil
+	case crypto.ECP256PublicKey:
+		return signature.SIGNATURE_TYPE_ECDSA_SHA256_P256, nil
+	default:
+		return 0, fmt.Errorf("%w: %T", ErrUnsupportedFamilyKey, key)
+	}
+}
+
+// parseFamilyCertificate returns the family name and key of a PEM encoded
+// family certificate. fallbackName is used if it has no common name.
+func parseFamilyCertificate(data []byte, fallbackName string) (string, crypto.SigningPublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", nil, errors.New("no PEM certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", nil, err
+	}
+	name := cert.Subject.CommonName
+	if name == "" {
+		name = fallbackName
+	}
+	switch key := cert.PublicKey.(type) {
+	case ed25519.PublicKey:
+		return name, crypto.Ed25519PublicKey(key), nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", nil, ErrUnsupportedFamilyKey
+		}
+		var ecKey crypto.ECP256PublicKey
+		key.X.FillBytes(ecKey[:32])
+		key.Y.FillBytes(ecKey[32:])
+		return name, ecKey, nil
+	default:
+		return "", nil, ErrUnsupportedFamilyKey
+	}
+}
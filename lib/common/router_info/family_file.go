@@ -0,0 +1,44 @@
+//go:build !js
+
+package router_info
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// LoadCertificates trusts the family certificates in dir, PEM encoded X.509
+// certificates with a .crt suffix as distributed with the Java router. The
+// family name is the certificate's common name, or the file name without its
+// suffix if the certificate has none.
+func (verifier *FamilyVerifier) LoadCertificates(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.crt"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		name, key, err := readFamilyCertificate(path)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Warn("Skipping family certificate")
+			continue
+		}
+		verifier.Trust(name, key)
+		log.WithFields(logrus.Fields{
+			"family": name,
+			"path":   path,
+		}).Debug("Loaded family certificate")
+	}
+	return nil
+}
+
+func readFamilyCertificate(path string) (string, crypto.SigningPublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return parseFamilyCertificate(data, strings.TrimSuffix(filepath.Base(path), ".crt"))
+}
@@ -0,0 +1,43 @@
+//go:build !js
+
+package router_info
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFamilyVerifierLoadCertificates(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "testfamily"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, privateKey)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	pemData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "testfamily.crt"), pemData, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.crt"), []byte("not a cert"), 0o644))
+
+	verifier := NewFamilyVerifier()
+	require.NoError(t, verifier.LoadCertificates(dir))
+	trusted, ok := verifier.trusted["testfamily"]
+	require.True(t, ok)
+	assert.Equal(t, []byte(publicKey), trusted.Bytes())
+	assert.Len(t, verifier.trusted, 1)
+}
@@ -0,0 +1,76 @@
+package router_info
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTestFamilyKey(t *testing.T) crypto.SigningPrivateKey {
+	var key crypto.Ed25519PrivateKey
+	familyKey, err := key.Generate()
+	require.NoError(t, err)
+	return familyKey
+}
+
+func TestJoinFamilyAndVerify(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	familyKey := generateTestFamilyKey(t)
+	require.NoError(t, owned.JoinFamily("testfamily", familyKey))
+
+	routerInfo := owned.RouterInfo()
+	require.NoError(t, routerInfo.Verify())
+	family, err := routerInfo.Family()
+	require.NoError(t, err)
+	assert.Equal(t, "testfamily", family.Name)
+	assert.Equal(t, 7, family.SigType)
+
+	verifier := NewFamilyVerifier()
+	family, err = verifier.Verify(routerInfo)
+	assert.ErrorIs(t, err, ErrFamilyUntrusted)
+	assert.NotNil(t, family)
+
+	publicKey, err := familyKey.Public()
+	require.NoError(t, err)
+	verifier.Trust("testfamily", publicKey)
+	family, err = verifier.Verify(routerInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, "testfamily", family.Name)
+
+	otherKey, err := generateTestFamilyKey(t).Public()
+	require.NoError(t, err)
+	verifier.Trust("testfamily", otherKey)
+	_, err = verifier.Verify(routerInfo)
+	assert.ErrorIs(t, err, ErrFamilyKeyMismatch)
+}
+
+func TestFamilyRejectsForgedSignature(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	familyKey := generateTestFamilyKey(t)
+	require.NoError(t, owned.JoinFamily("testfamily", familyKey))
+	// a signature for another router does not carry over
+	options, err := FamilyOptions("testfamily", [32]byte{1}, familyKey)
+	require.NoError(t, err)
+	require.NoError(t, owned.SetOption(FAMILY_SIG_OPTION, options[FAMILY_SIG_OPTION]))
+
+	_, err = NewFamilyVerifier().Verify(owned.RouterInfo())
+	assert.ErrorIs(t, err, ErrFamilySignature)
+}
+
+func TestFamilyMissingOrMalformed(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	_, err := owned.RouterInfo().Family()
+	assert.ErrorIs(t, err, ErrNoFamily)
+
+	require.NoError(t, owned.SetOption(FAMILY_OPTION, "testfamily"))
+	require.NoError(t, owned.SetOption(FAMILY_KEY_OPTION, "7;"+base64.EncodeToString([]byte("short"))))
+	_, err = owned.RouterInfo().Family()
+	assert.ErrorIs(t, err, ErrInvalidFamily)
+
+	require.NoError(t, owned.SetOption(FAMILY_KEY_OPTION, "99;"+base64.EncodeToString(make([]byte, 32))))
+	_, err = owned.RouterInfo().Family()
+	assert.ErrorIs(t, err, ErrUnsupportedFamilyKey)
+}
@@ -0,0 +1,105 @@
+//go:build !js
+
+package router_info
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+// gzipMagic is the two byte header of a gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// RouterInfoFilePath returns the path a RouterInfo with the given identity hash
+// is stored at inside netDbDir, using the layout shared by Java I2P and i2pd:
+// netDb/r<first base64 character>/routerInfo-<base64 hash>.dat
+func RouterInfoFilePath(netDbDir string, hash Hash) string {
+	fname := hash.Base64()
+	return filepath.Join(netDbDir, fmt.Sprintf("r%c", fname[0]), fmt.Sprintf("routerInfo-%s.dat", fname))
+}
+
+// WriteToFile stores the RouterInfo in the netDb directory netDbDir, creating
+// the bucket directory if needed. The file is written uncompressed, the same
+// as other routers do, and replaced atomically. Returns the path written.
+func (router_info *RouterInfo) WriteToFile(netDbDir string) (string, error) {
+	data, err := router_info.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return router_info.writeFile(netDbDir, data)
+}
+
+// WriteCompressedToFile is like WriteToFile but gzip compresses the file,
+// saving space in large netDbs. ReadRouterInfoFromFile reads either form, as
+// do Java I2P and i2pd.
+func (router_info *RouterInfo) WriteCompressedToFile(netDbDir string) (string, error) {
+	data, err := router_info.CompressedBytes()
+	if err != nil {
+		return "", err
+	}
+	return router_info.writeFile(netDbDir, data)
+}
+
+// writeFile atomically replaces the netDb file of the RouterInfo with data
+func (router_info *RouterInfo) writeFile(netDbDir string, data []byte) (string, error) {
+	path := RouterInfoFilePath(netDbDir, router_info.IdentHash())
+	log.WithFields(logrus.Fields{
+		"path":   path,
+		"length": len(data),
+	}).Debug("Writing RouterInfo to file")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		log.WithError(err).Error("Failed to create netDb bucket directory")
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".routerInfo-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		log.WithError(err).Error("Failed to move RouterInfo file into place")
+		return "", err
+	}
+	return path, nil
+}
+
+// ReadRouterInfoFromFile reads a RouterInfo from a netDb file.
+// Files which are gzip compressed are decompressed transparently, up to
+// MAX_UNCOMPRESSED_SIZE.
+func ReadRouterInfoFromFile(path string) (info RouterInfo, err error) {
+	log.WithField("path", path).Debug("Reading RouterInfo from file")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		data, err = gunzipLimited(data, MAX_UNCOMPRESSED_SIZE)
+		if err != nil {
+			return
+		}
+	}
+	info, remainder, err := readRouterInfo(data)
+	if err != nil {
+		return
+	}
+	if len(remainder) != 0 {
+		log.WithFields(logrus.Fields{
+			"path":             path,
+			"remainder_length": len(remainder),
+		}).Warn("Trailing data after RouterInfo in file")
+	}
+	return
+}
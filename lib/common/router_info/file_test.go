@@ -0,0 +1,82 @@
+//go:build !js
+
+package router_info
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterInfoFilePath(t *testing.T) {
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	require.NoError(t, err)
+
+	hash := routerInfo.IdentHash()
+	encoded := base64.EncodeToString(hash[:])
+	path := RouterInfoFilePath("netDb", hash)
+	assert.Equal(t, filepath.Join("netDb", "r"+encoded[:1], "routerInfo-"+encoded+".dat"), path)
+}
+
+func TestRouterInfoWriteReadFile(t *testing.T) {
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	path, err := routerInfo.WriteToFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, RouterInfoFilePath(dir, routerInfo.IdentHash()), path)
+
+	read, err := ReadRouterInfoFromFile(path)
+	require.NoError(t, err)
+	expected, _ := routerInfo.Bytes()
+	actual, _ := read.Bytes()
+	assert.Equal(t, expected, actual)
+}
+
+func TestRouterInfoWriteCompressedFile(t *testing.T) {
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	path, err := routerInfo.WriteCompressedToFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, RouterInfoFilePath(dir, routerInfo.IdentHash()), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, gzipMagic))
+
+	read, err := ReadRouterInfoFromFile(path)
+	require.NoError(t, err)
+	expected, _ := routerInfo.Bytes()
+	actual, _ := read.Bytes()
+	assert.Equal(t, expected, actual)
+}
+
+func TestReadRouterInfoFromGzipFile(t *testing.T) {
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	require.NoError(t, err)
+	expected, err := routerInfo.Bytes()
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	_, err = zw.Write(expected)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	path := filepath.Join(t.TempDir(), "routerInfo-test.dat")
+	require.NoError(t, os.WriteFile(path, compressed.Bytes(), 0o600))
+
+	read, err := ReadRouterInfoFromFile(path)
+	require.NoError(t, err)
+	actual, _ := read.Bytes()
+	assert.Equal(t, expected, actual)
+}
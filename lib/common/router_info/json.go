@@ -0,0 +1,135 @@
+package router_info
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+	. "github.com/go-i2p/go-i2p/lib/common/router_address"
+)
+
+// routerInfoJSON is the JSON schema of a RouterInfo. Binary fields use the
+// I2P base64 alphabet and times are milliseconds since the epoch, as they are
+// on the wire. Fields are only ever added to it, so tools can rely on it.
+type routerInfoJSON struct {
+	IdentHash string `json:"ident_hash"`
+	// Identity is the serialized RouterIdentity
+	Identity  string              `json:"identity"`
+	Published int64               `json:"published"`
+	Addresses []routerAddressJSON `json:"addresses"`
+	Options   map[string]string   `json:"options"`
+	// Caps and Version repeat the caps and router.version options for
+	// convenience, they are ignored when unmarshalling
+	Caps      string `json:"caps"`
+	Version   string `json:"version"`
+	Signature string `json:"signature"`
+}
+
+type routerAddressJSON struct {
+	Cost int `json:"cost"`
+	// Expiration is 0 if the address does not expire
+	Expiration int64             `json:"expiration"`
+	Transport  string            `json:"transport"`
+	Options    map[string]string `json:"options"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (router_info RouterInfo) MarshalJSON() ([]byte, error) {
+	if router_info.published == nil || router_info.signature == nil {
+		return nil, fmt.Errorf("error marshalling router info: %w", ErrIncompleteRouterInfo)
+	}
+	hash := router_info.IdentHash()
+	ri := routerInfoJSON{
+		IdentHash: hash.Base64(),
+		Identity:  base64.EncodeToString(router_info.router_identity.Bytes()),
+		Published: int64(router_info.published.Int()),
+		Addresses: make([]routerAddressJSON, 0, len(router_info.addresses)),
+		Options:   router_info.OptionsMap(),
+		Caps:      router_info.RouterCapabilities(),
+		Version:   router_info.GetOptionString("router.version", ""),
+		Signature: base64.EncodeToString(*router_info.signature),
+	}
+	for _, address := range router_info.addresses {
+		transport, _ := address.TransportStyle().Data()
+		options := make(map[string]string)
+		for _, pair := range address.Options().Values() {
+			key, err := pair[0].Data()
+			if err != nil {
+				continue
+			}
+			value, _ := pair[1].Data()
+			options[key] = value
+		}
+		ri.Addresses = append(ri.Addresses, routerAddressJSON{
+			Cost:       address.Cost(),
+			Expiration: int64(address.Expiration().Int()),
+			Transport:  transport,
+			Options:    options,
+		})
+	}
+	return json.Marshal(ri)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The RouterInfo is rebuilt in
+// its wire format and parsed, so the signature still verifies as long as the
+// original options and address options were in the sorted order the spec
+// requires.
+func (router_info *RouterInfo) UnmarshalJSON(data []byte) error {
+	var ri routerInfoJSON
+	if err := json.Unmarshal(data, &ri); err != nil {
+		return err
+	}
+	identity, err := base64.DecodeString(ri.Identity)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling router info identity: %w", err)
+	}
+	signature, err := base64.DecodeString(ri.Signature)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling router info signature: %w", err)
+	}
+	if len(ri.Addresses) > 255 {
+		return fmt.Errorf("error unmarshalling router info: %w", ErrTooManyAddresses)
+	}
+
+	wire := append([]byte(nil), identity...)
+	wire = binary.BigEndian.AppendUint64(wire, uint64(ri.Published))
+	wire = append(wire, byte(len(ri.Addresses)))
+	for _, a := range ri.Addresses {
+		if a.Cost < 0 || a.Cost > 255 {
+			return fmt.Errorf("error unmarshalling router address: %w %d", ErrInvalidAddressCost, a.Cost)
+		}
+		address, err := NewRouterAddress(uint8(a.Cost), time.UnixMilli(a.Expiration), a.Transport, a.Options)
+		if err != nil {
+			return err
+		}
+		wire = append(wire, address.Bytes()...)
+	}
+	// peer_size, always 0
+	wire = append(wire, 0)
+	options, err := GoMapToMapping(ri.Options)
+	if err != nil {
+		return err
+	}
+	wire = append(wire, options.Data()...)
+	wire = append(wire, signature...)
+
+	parsed, remainder, err := readRouterInfo(wire)
+	if err != nil {
+		return err
+	}
+	if len(remainder) != 0 {
+		return fmt.Errorf("error unmarshalling router info: %w", ErrSignatureTooLong)
+	}
+	if ri.IdentHash != "" {
+		hash := parsed.IdentHash()
+		if hash.Base64() != ri.IdentHash {
+			return fmt.Errorf("error unmarshalling router info: %w", ErrIdentHashMismatch)
+		}
+	}
+	*router_info = parsed
+	return nil
+}
@@ -0,0 +1,48 @@
+package router_info
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterInfoJSONRoundTrip(t *testing.T) {
+	original := newTestBundle(t, 1)[0]
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "LR", fields["caps"])
+	assert.Equal(t, "0.9.64", fields["version"])
+	assert.Equal(t, "2", fields["options"].(map[string]interface{})["netId"])
+	addresses := fields["addresses"].([]interface{})
+	require.Len(t, addresses, 1)
+	assert.Equal(t, "NTCP2", addresses[0].(map[string]interface{})["transport"])
+
+	var decoded RouterInfo
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	want, err := original.Bytes()
+	require.NoError(t, err)
+	got, err := decoded.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.NoError(t, decoded.Verify())
+	assert.Equal(t, original.IdentHash(), decoded.IdentHash())
+}
+
+func TestRouterInfoJSONRejectsWrongHash(t *testing.T) {
+	original := newTestBundle(t, 1)[0]
+	data, err := json.Marshal(original)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	fields["ident_hash"] = "AAAA"
+	data, err = json.Marshal(fields)
+	require.NoError(t, err)
+
+	var decoded RouterInfo
+	assert.Error(t, json.Unmarshal(data, &decoded))
+}
@@ -0,0 +1,93 @@
+package router_info
+
+import (
+	"fmt"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+// OptionsMap returns the RouterInfo options as a Go map.
+func (router_info *RouterInfo) OptionsMap() map[string]string {
+	options := make(map[string]string)
+	if router_info.options == nil {
+		return options
+	}
+	for _, pair := range router_info.options.Values() {
+		key, err := pair[0].Data()
+		if err != nil {
+			continue
+		}
+		value, _ := pair[1].Data()
+		options[key] = value
+	}
+	return options
+}
+
+// GetOptionString returns the value of the option named key, or def if it is
+// not set.
+func (router_info *RouterInfo) GetOptionString(key, def string) string {
+	return router_info.options.OptionString(key, def)
+}
+
+// GetOptionInt returns the option named key as an integer, or def if it is
+// not set. A *data.OptionError is returned if the value is not an integer.
+func (router_info *RouterInfo) GetOptionInt(key string, def int) (int, error) {
+	return router_info.options.OptionInt(key, def)
+}
+
+// GetOptionBool returns the option named key as a boolean, or def if it is
+// not set. A *data.OptionError is returned if the value is not a boolean.
+func (router_info *RouterInfo) GetOptionBool(key string, def bool) (bool, error) {
+	return router_info.options.OptionBool(key, def)
+}
+
+// SetOption sets an option and re-signs the RouterInfo with signingPrivateKey,
+// which must belong to the RouterInfo's identity. The options are rebuilt in
+// sorted key order. The published date is left alone, peers will only accept
+// the change if it is published with a newer date, see OwnedRouterInfo.
+func (router_info *RouterInfo) SetOption(key, value string, signingPrivateKey crypto.SigningPrivateKey) error {
+	options := router_info.OptionsMap()
+	if current, ok := options[key]; ok && current == value {
+		return nil
+	}
+	options[key] = value
+	return router_info.replaceOptions(options, signingPrivateKey)
+}
+
+// DeleteOption removes an option and re-signs the RouterInfo with
+// signingPrivateKey. Nothing changes if the option is not set.
+func (router_info *RouterInfo) DeleteOption(key string, signingPrivateKey crypto.SigningPrivateKey) error {
+	options := router_info.OptionsMap()
+	if _, ok := options[key]; !ok {
+		return nil
+	}
+	delete(options, key)
+	return router_info.replaceOptions(options, signingPrivateKey)
+}
+
+// replaceOptions swaps in the new options and signs the result, restoring the
+// previous options and signature if signing fails.
+func (router_info *RouterInfo) replaceOptions(options map[string]string, signingPrivateKey crypto.SigningPrivateKey) error {
+	if signingPrivateKey == nil {
+		return fmt.Errorf("error setting router info option: %w", ErrNoSigningPrivateKey)
+	}
+	if router_info.router_identity.KeyCertificate == nil {
+		return fmt.Errorf("error setting router info option: %w", ErrNoKeyCertificate)
+	}
+	mapping, err := GoMapToMapping(options)
+	if err != nil {
+		log.WithError(err).Error("Failed to convert options map to Mapping")
+		return err
+	}
+	previousOptions, previousSignature := router_info.options, router_info.signature
+	router_info.options = mapping
+	sigType := router_info.router_identity.KeyCertificate.SigningPublicKeyType()
+	if err := router_info.sign(signingPrivateKey, sigType); err != nil {
+		router_info.options, router_info.signature = previousOptions, previousSignature
+		return err
+	}
+	log.WithField("option_count", len(options)).Debug("Re-signed RouterInfo with new options")
+	return nil
+}
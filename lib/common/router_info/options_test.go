@@ -0,0 +1,119 @@
+package router_info
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/data"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterInfoSetOption(t *testing.T) {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	routerInfo, err := NewRouterInfoBuilder(routerIdentity, privateKey).
+		SetOption("router.version", "0.9.64").
+		Build()
+	require.NoError(t, err)
+	before := routerInfo.Signature()
+
+	require.NoError(t, routerInfo.SetOption("caps", "OfR", privateKey))
+	require.NoError(t, routerInfo.SetOption("netId", "2", privateKey))
+	assert.Equal(t, map[string]string{
+		"caps":           "OfR",
+		"netId":          "2",
+		"router.version": "0.9.64",
+	}, routerInfo.OptionsMap())
+	assert.NotEqual(t, before, routerInfo.Signature())
+	require.NoError(t, routerInfo.Verify())
+
+	// the options are written in sorted order and survive a round trip
+	data, err := routerInfo.Bytes()
+	require.NoError(t, err)
+	read, _, err := ReadRouterInfo(data)
+	require.NoError(t, err)
+	require.NoError(t, read.Verify())
+	keys := []string{}
+	for _, pair := range read.Options().Values() {
+		key, _ := pair[0].Data()
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []string{"caps", "netId", "router.version"}, keys)
+
+	require.NoError(t, routerInfo.DeleteOption("netId", privateKey))
+	assert.NotContains(t, routerInfo.OptionsMap(), "netId")
+	require.NoError(t, routerInfo.Verify())
+}
+
+func TestRouterInfoSetOptionWithoutKey(t *testing.T) {
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	require.NoError(t, err)
+	before := routerInfo.OptionsMap()
+
+	assert.Error(t, routerInfo.SetOption("caps", "OfR", nil))
+	assert.Equal(t, before, routerInfo.OptionsMap())
+	assert.NoError(t, routerInfo.Verify())
+}
+
+func TestRouterInfoGetOptionTyped(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	require.NoError(t, owned.SetOption("netdb.knownLeaseSets", "12"))
+	require.NoError(t, owned.SetOption("netdb.floodfill", "false"))
+	routerInfo := owned.RouterInfo()
+
+	assert.Equal(t, "L", routerInfo.GetOptionString("caps", ""))
+	assert.Equal(t, "L", routerInfo.RouterCapabilities())
+	count, err := routerInfo.GetOptionInt("netdb.knownLeaseSets", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 12, count)
+	floodfill, err := routerInfo.GetOptionBool("netdb.floodfill", true)
+	assert.NoError(t, err)
+	assert.False(t, floodfill)
+	_, err = routerInfo.GetOptionBool("caps", false)
+	assert.Error(t, err)
+}
+
+func TestRouterInfoErrorsMatchSentinels(t *testing.T) {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+
+	_, err := NewRouterInfoBuilder(routerIdentity, nil).Build()
+	assert.ErrorIs(t, err, ErrNoSigningPrivateKey)
+	assert.Equal(t, "error building router info: no signing private key", err.Error())
+
+	routerInfo, err := NewRouterInfoBuilder(routerIdentity, privateKey).Build()
+	require.NoError(t, err)
+	assert.ErrorIs(t, routerInfo.SetOption("caps", "R", nil), ErrNoSigningPrivateKey)
+
+	routerInfo.signature = nil
+	assert.ErrorIs(t, routerInfo.Verify(), ErrNoSignature)
+}
+
+func TestRouterInfoDoesNotAliasReusedBuffer(t *testing.T) {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	built, err := NewRouterInfoBuilder(routerIdentity, privateKey).
+		SetOption("caps", "OfR").
+		Build()
+	require.NoError(t, err)
+	wire, err := built.Bytes()
+	require.NoError(t, err)
+
+	buffer := append([]byte(nil), wire...)
+	copied, _, err := ReadRouterInfo(buffer, data.CopyOnParse())
+	require.NoError(t, err)
+	aliased, _, err := ReadRouterInfo(buffer)
+	require.NoError(t, err)
+	cloned := aliased.Clone()
+
+	// the buffer is reused for the next message
+	for i := range buffer {
+		buffer[i] = 0
+	}
+	for _, info := range []RouterInfo{copied, cloned} {
+		data, err := info.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, wire, data)
+		assert.Equal(t, "OfR", info.RouterCapabilities())
+		require.NoError(t, info.Verify())
+	}
+}
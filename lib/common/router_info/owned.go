@@ -0,0 +1,244 @@
+package router_info
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+
+	. "github.com/go-i2p/go-i2p/lib/common/router_address"
+	. "github.com/go-i2p/go-i2p/lib/common/router_identity"
+)
+
+// DefaultRepublishInterval is how often our own RouterInfo is re-signed with a
+// fresh published date when nothing else about it changes.
+const DefaultRepublishInterval = 30 * time.Minute
+
+// OwnedRouterInfo is the RouterInfo of the local router. It keeps the
+// addresses and options it is built from, and whenever they change it bumps
+// the published date, re-signs, and hands the new RouterInfo to its
+// subscribers so the netdb publisher can flood it.
+type OwnedRouterInfo struct {
+	mutex             sync.Mutex
+	routerIdentity    *RouterIdentity
+	signingPrivateKey crypto.SigningPrivateKey
+	addresses         []*RouterAddress
+	options           map[string]string
+	current           *RouterInfo
+	published         time.Time
+	subscribers       []chan *RouterInfo
+	callbacks         []func(*RouterInfo)
+	stop              chan struct{}
+	done              chan struct{}
+}
+
+// NewOwnedRouterInfo builds and signs the initial RouterInfo for the local router.
+func NewOwnedRouterInfo(routerIdentity *RouterIdentity, signingPrivateKey crypto.SigningPrivateKey, addresses []*RouterAddress, options map[string]string) (*OwnedRouterInfo, error) {
+	log.Debug("Creating OwnedRouterInfo")
+	owned := &OwnedRouterInfo{
+		routerIdentity:    routerIdentity,
+		signingPrivateKey: signingPrivateKey,
+		addresses:         append([]*RouterAddress(nil), addresses...),
+		options:           make(map[string]string, len(options)),
+	}
+	for key, value := range options {
+		owned.options[key] = value
+	}
+	if err := owned.rebuild(); err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+// RouterInfo returns the most recently signed RouterInfo.
+func (owned *OwnedRouterInfo) RouterInfo() *RouterInfo {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	return owned.current
+}
+
+// SetAddresses replaces the router addresses, republishing if they changed.
+func (owned *OwnedRouterInfo) SetAddresses(addresses []*RouterAddress) error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	if addressesEqual(owned.addresses, addresses) {
+		return nil
+	}
+	previous := owned.addresses
+	owned.addresses = append([]*RouterAddress(nil), addresses...)
+	if err := owned.rebuild(); err != nil {
+		owned.addresses = previous
+		return err
+	}
+	return nil
+}
+
+// SetOption sets a RouterInfo option, republishing if its value changed.
+func (owned *OwnedRouterInfo) SetOption(key, value string) error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	previous, ok := owned.options[key]
+	if ok && previous == value {
+		return nil
+	}
+	owned.options[key] = value
+	if err := owned.rebuild(); err != nil {
+		if ok {
+			owned.options[key] = previous
+		} else {
+			delete(owned.options, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteOption removes a RouterInfo option, republishing if it was set.
+func (owned *OwnedRouterInfo) DeleteOption(key string) error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	previous, ok := owned.options[key]
+	if !ok {
+		return nil
+	}
+	delete(owned.options, key)
+	if err := owned.rebuild(); err != nil {
+		owned.options[key] = previous
+		return err
+	}
+	return nil
+}
+
+// Republish re-signs the RouterInfo with a new published date even though
+// nothing else changed, so that peers do not consider it stale.
+func (owned *OwnedRouterInfo) Republish() error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	return owned.rebuild()
+}
+
+// Subscribe returns a channel which receives every newly signed RouterInfo.
+// Only the latest one is kept for a slow reader; older ones are dropped.
+func (owned *OwnedRouterInfo) Subscribe() <-chan *RouterInfo {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	ch := make(chan *RouterInfo, 1)
+	owned.subscribers = append(owned.subscribers, ch)
+	return ch
+}
+
+// OnPublish registers a callback run synchronously with every newly signed
+// RouterInfo. Callbacks must not call back into the OwnedRouterInfo.
+func (owned *OwnedRouterInfo) OnPublish(callback func(*RouterInfo)) {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	owned.callbacks = append(owned.callbacks, callback)
+}
+
+// Start republishes the RouterInfo every interval until Stop is called.
+func (owned *OwnedRouterInfo) Start(interval time.Duration) error {
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	if owned.stop != nil {
+		return fmt.Errorf("owned router info: %w", ErrRepublishingStarted)
+	}
+	if interval <= 0 {
+		interval = DefaultRepublishInterval
+	}
+	owned.stop = make(chan struct{})
+	owned.done = make(chan struct{})
+	go owned.republishLoop(interval, owned.stop, owned.done)
+	log.WithField("interval", interval).Debug("Started RouterInfo republishing")
+	return nil
+}
+
+// Stop ends periodic republishing and closes the subscriber channels.
+func (owned *OwnedRouterInfo) Stop() {
+	owned.mutex.Lock()
+	stop, done := owned.stop, owned.done
+	owned.stop, owned.done = nil, nil
+	owned.mutex.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+	owned.mutex.Lock()
+	defer owned.mutex.Unlock()
+	for _, ch := range owned.subscribers {
+		close(ch)
+	}
+	owned.subscribers = nil
+	log.Debug("Stopped RouterInfo republishing")
+}
+
+func (owned *OwnedRouterInfo) republishLoop(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := owned.Republish(); err != nil {
+				log.WithError(err).Error("Failed to republish RouterInfo")
+			}
+		}
+	}
+}
+
+// rebuild signs a new RouterInfo and notifies subscribers. The published date
+// always moves forward, even if the clock has not advanced a millisecond since
+// the last one, because peers only replace a RouterInfo with a newer one.
+// Callers must hold the mutex.
+func (owned *OwnedRouterInfo) rebuild() error {
+	published := time.Now().Truncate(time.Millisecond)
+	if !published.After(owned.published) {
+		published = owned.published.Add(time.Millisecond)
+	}
+	builder := NewRouterInfoBuilder(owned.routerIdentity, owned.signingPrivateKey).Published(published)
+	for _, address := range owned.addresses {
+		builder.AddAddress(address)
+	}
+	for key, value := range owned.options {
+		builder.SetOption(key, value)
+	}
+	routerInfo, err := builder.Build()
+	if err != nil {
+		log.WithError(err).Error("Failed to rebuild owned RouterInfo")
+		return err
+	}
+	owned.current = routerInfo
+	owned.published = published
+	log.WithFields(logrus.Fields{
+		"published":     published,
+		"address_count": len(owned.addresses),
+		"option_count":  len(owned.options),
+	}).Debug("Rebuilt owned RouterInfo")
+
+	for _, ch := range owned.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- routerInfo
+	}
+	for _, callback := range owned.callbacks {
+		callback(routerInfo)
+	}
+	return nil
+}
+
+func addressesEqual(a, b []*RouterAddress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if string(a[i].Bytes()) != string(b[i].Bytes()) {
+			return false
+		}
+	}
+	return true
+}
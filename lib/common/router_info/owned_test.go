@@ -0,0 +1,76 @@
+package router_info
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_address"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOwnedRouterInfo(t *testing.T) *OwnedRouterInfo {
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	owned, err := NewOwnedRouterInfo(routerIdentity, privateKey, nil, map[string]string{"caps": "L"})
+	require.NoError(t, err)
+	return owned
+}
+
+func TestOwnedRouterInfoRepublishesOnChange(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	first := owned.RouterInfo()
+	require.NoError(t, first.Verify())
+	updates := owned.Subscribe()
+
+	require.NoError(t, owned.SetOption("caps", "O"))
+	second := <-updates
+	assert.Same(t, owned.RouterInfo(), second)
+	assert.NoError(t, second.Verify())
+	assert.True(t, second.Published().Time().After(first.Published().Time()))
+
+	// unchanged values do not republish
+	require.NoError(t, owned.SetOption("caps", "O"))
+	require.NoError(t, owned.DeleteOption("missing"))
+	select {
+	case <-updates:
+		t.Fatal("unexpected republish")
+	default:
+	}
+
+	address, err := router_address.NewRouterAddress(3, time.Time{}, "NTCP2", map[string]string{})
+	require.NoError(t, err)
+	require.NoError(t, owned.SetAddresses([]*router_address.RouterAddress{address}))
+	third := <-updates
+	assert.Equal(t, 1, third.RouterAddressCount())
+	assert.NoError(t, third.Verify())
+}
+
+func TestOwnedRouterInfoOnPublish(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	var published []*RouterInfo
+	owned.OnPublish(func(ri *RouterInfo) {
+		published = append(published, ri)
+	})
+	require.NoError(t, owned.Republish())
+	require.NoError(t, owned.Republish())
+	require.Len(t, published, 2)
+	assert.True(t, published[1].Published().Time().After(published[0].Published().Time()))
+}
+
+func TestOwnedRouterInfoStartStop(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	updates := owned.Subscribe()
+	require.NoError(t, owned.Start(10*time.Millisecond))
+	assert.Error(t, owned.Start(10*time.Millisecond))
+
+	select {
+	case ri := <-updates:
+		assert.NoError(t, ri.Verify())
+	case <-time.After(5 * time.Second):
+		t.Fatal("RouterInfo was not republished")
+	}
+
+	owned.Stop()
+	for range updates {
+	}
+}
@@ -4,12 +4,10 @@ package router_info
 import (
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/go-i2p/go-i2p/lib/common/certificate"
-
 	"github.com/go-i2p/go-i2p/lib/crypto"
 
 	"github.com/go-i2p/go-i2p/lib/util/logger"
@@ -23,10 +21,35 @@ import (
 
 var log = logger.GetGoI2PLogger()
 
+// Errors returned when building, changing or verifying a RouterInfo. Most are
+// wrapped with the operation that failed, test for them with errors.Is.
+var (
+	ErrNoRouterIdentity     = errors.New("no router identity")
+	ErrNoSigningPrivateKey  = errors.New("no signing private key")
+	ErrNoSigningPublicKey   = errors.New("no signing public key")
+	ErrNoKeyCertificate     = errors.New("router identity has no key certificate")
+	ErrTooManyAddresses     = errors.New("too many router addresses")
+	ErrNoSignature          = errors.New("no signature")
+	ErrSignatureLength      = errors.New("signature length does not match signature type")
+	ErrSignatureTooLong     = errors.New("signature longer than its type")
+	ErrOptionsSizeTooShort  = errors.New("not enough data to read options size")
+	ErrOptionsTooShort      = errors.New("options length exceeds provided data")
+	ErrIncompleteRouterInfo = errors.New("incomplete router info")
+	ErrIdentHashMismatch    = errors.New("identity hash does not match identity")
+	ErrInvalidAddressCost   = errors.New("invalid cost")
+	ErrInvalidVersion       = errors.New("invalid version")
+	ErrRepublishingStarted  = errors.New("republishing already started")
+)
+
 const ROUTER_INFO_MIN_SIZE = 439
 
 const (
+	// MIN_GOOD_VERSION is the oldest 0.9.x release accepted by DefaultVersionPolicy
 	MIN_GOOD_VERSION = 58
+	// MAX_GOOD_VERSION was the newest 0.9.x release GoodVersion accepted.
+	//
+	// Deprecated: DefaultVersionPolicy has no upper bound, set
+	// VersionPolicy.Max to impose one.
 	MAX_GOOD_VERSION = 99
 )
 
@@ -121,6 +144,36 @@ type RouterInfo struct {
 	peer_size       *Integer
 	options         *Mapping
 	signature       *Signature
+	// ident_hash caches the hash of router_identity, which never changes
+	// once the RouterInfo is built
+	ident_hash *Hash
+}
+
+// Clone returns a copy of the RouterInfo which does not alias the buffer it
+// was read from.
+func (router_info RouterInfo) Clone() RouterInfo {
+	clone := RouterInfo{
+		router_identity: router_info.router_identity.Clone(),
+		published:       CloneDate(router_info.published),
+		size:            CloneInteger(router_info.size),
+		peer_size:       CloneInteger(router_info.peer_size),
+		options:         CloneMapping(router_info.options),
+		ident_hash:      router_info.ident_hash,
+	}
+	if router_info.addresses != nil {
+		clone.addresses = make([]*RouterAddress, len(router_info.addresses))
+		for i, address := range router_info.addresses {
+			if address != nil {
+				addressClone := address.Clone()
+				clone.addresses[i] = &addressClone
+			}
+		}
+	}
+	if router_info.signature != nil {
+		signature := router_info.signature.Clone()
+		clone.signature = &signature
+	}
+	return clone
 }
 
 // Bytes returns the RouterInfo as a []byte suitable for writing to a stream.
@@ -172,15 +225,21 @@ func (router_info *RouterInfo) RouterIdentity() *RouterIdentity {
 	return &router_info.router_identity
 }
 
-// IndentHash returns the identity hash (sha256 sum) for this RouterInfo.
+// IdentHash returns the identity hash (sha256 sum) of the RouterIdentity,
+// the key this RouterInfo is stored under in the netdb. It is computed once
+// when the RouterInfo is read or built.
 func (router_info *RouterInfo) IdentHash() Hash {
-	log.Debug("Calculating IdentHash for RouterInfo")
-	// data, _ := router_info.RouterIdentity().keyCertificate.Data()
-	cert := router_info.RouterIdentity().KeysAndCert.Certificate()
-	data := cert.Data()
-	hash := HashData(data)
+	if router_info.ident_hash != nil {
+		return *router_info.ident_hash
+	}
+	return HashData(router_info.router_identity.Bytes())
+}
+
+// cacheIdentHash stores the identity hash for IdentHash
+func (router_info *RouterInfo) cacheIdentHash() {
+	hash := HashData(router_info.router_identity.Bytes())
+	router_info.ident_hash = &hash
 	log.WithField("hash", hash).Debug("Calculated IdentHash for RouterInfo")
-	return HashData(data)
 }
 
 // Published returns the date this RouterInfo was published as an I2P Date.
@@ -201,11 +260,14 @@ func (router_info *RouterInfo) RouterAddresses() []*RouterAddress {
 	return router_info.addresses
 }
 
-// PeerSize returns the peer size as a Go integer.
+// PeerSize returns the peer size as a Go integer. The field is unused and
+// should always be 0, see
+// https://geti2p.net/spec/common-structures#routerinfo
 func (router_info *RouterInfo) PeerSize() int {
-	// Peer size is unused:
-	// https://geti2p.net/spec/common-structures#routeraddress
-	return 0
+	if router_info.peer_size == nil {
+		return 0
+	}
+	return router_info.peer_size.Int()
 }
 
 // Options returns the options for this RouterInfo as an I2P Mapping.
@@ -223,90 +285,104 @@ func (router_info RouterInfo) Network() string {
 	return "i2p"
 }
 
-// ReadRouterInfo returns RouterInfo from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns a list of errors that occurred during parsing.
-func ReadRouterInfo(bytes []byte) (info RouterInfo, remainder []byte, err error) {
+// readRouterInfo reads a RouterInfo, returning the data after it
+func readRouterInfo(bytes []byte, opts ...ParseOption) (info RouterInfo, remainder []byte, err error) {
 	log.WithField("input_length", len(bytes)).Debug("Reading RouterInfo from bytes")
+	bytes = ParseBuffer(bytes, opts...)
+	parseErr := &RouterInfoParseError{}
+	defer func() {
+		if len(parseErr.Errors) > 0 {
+			log.WithFields(logrus.Fields{
+				"at":       "(RouterInfo) ReadRouterInfo",
+				"data_len": len(bytes),
+				"complete": parseErr.Complete,
+				"reason":   parseErr.Error(),
+			}).Error("error parsing router info")
+			err = parseErr
+		}
+	}()
 
-	info.router_identity, remainder, err = ReadRouterIdentity(bytes)
+	var n int
+	info.router_identity, n, err = ParseRouterIdentity(bytes)
+	remainder = bytes[n:]
 	if err != nil {
-		log.WithFields(logrus.Fields{
-			"at":           "(RouterInfo) ReadRouterInfo",
-			"data_len":     len(bytes),
-			"required_len": ROUTER_INFO_MIN_SIZE,
-			"reason":       "not enough data",
-		}).Error("error parsing router info")
-		err = errors.New("error parsing router info: not enough data to read identity")
+		parseErr.add(FIELD_IDENTITY, -1, err)
 		return
 	}
-	info.published, remainder, err = NewDate(remainder)
+	info.cacheIdentHash()
+	published, n, err := ParseDate(remainder)
+	remainder = remainder[n:]
 	if err != nil {
-		log.WithFields(logrus.Fields{
-			"at":           "(RouterInfo) ReadRouterInfo",
-			"data_len":     len(remainder),
-			"required_len": DATE_SIZE,
-			"reason":       "not enough data",
-		}).Error("error parsing router info")
-		err = errors.New("error parsing router info: not enough data to read publish date")
-	}
-	info.size, remainder, err = NewInteger(remainder, 1)
+		parseErr.add(FIELD_PUBLISHED, -1, err)
+		return
+	}
+	info.published = &published
+	size, n, err := ParseInteger(remainder, 1)
+	remainder = remainder[n:]
 	if err != nil {
-		log.WithFields(logrus.Fields{
-			"at":           "(RouterInfo) ReadRouterInfo",
-			"data_len":     len(remainder),
-			"required_len": info.size.Int(),
-			"reason":       "read error",
-		}).Error("error parsing router info size")
+		parseErr.add(FIELD_ADDRESSES, -1, err)
+		return
 	}
+	info.size = &size
 	for i := 0; i < info.size.Int(); i++ {
-		address, more, err := ReadRouterAddress(remainder)
-		remainder = more
+		address, n, err := ParseRouterAddress(remainder)
+		remainder = remainder[n:]
 		if err != nil {
-			log.WithFields(logrus.Fields{
-				"at":       "(RouterInfo) ReadRouterInfo",
-				"data_len": len(remainder),
-				//"required_len": ROUTER_ADDRESS_SIZE,
-				"reason": "not enough data",
-			}).Error("error parsing router address")
-			err = errors.New("error parsing router info: not enough data to read router addresses")
+			parseErr.add(FIELD_ADDRESSES, i, err)
+			return info, remainder, nil
 		}
 		info.addresses = append(info.addresses, &address)
 	}
-	info.peer_size, remainder, err = NewInteger(remainder, 1)
+	peerSize, n, err := ParseInteger(remainder, 1)
+	remainder = remainder[n:]
 	if err != nil {
-		log.WithError(err).Error("Failed to read PeerSize")
+		parseErr.add(FIELD_PEER_SIZE, -1, err)
 		return
 	}
-	var errs []error
-	info.options, remainder, errs = NewMapping(remainder)
-	if len(errs) != 0 {
-		log.WithFields(logrus.Fields{
-			"at":       "(RouterInfo) ReadRouterInfo",
-			"data_len": len(remainder),
-			//"required_len": MAPPING_SIZE,
-			"reason": "not enough data",
-		}).Error("error parsing router info")
-		estring := ""
-		for _, e := range errs {
-			estring += e.Error() + " "
+	info.peer_size = &peerSize
+	if peerSize := info.peer_size.Int(); peerSize != 0 {
+		if StrictParsing() {
+			parseErr.add(FIELD_PEER_SIZE, -1, &ValidationError{Field: FIELD_PEER_SIZE, Value: peerSize, Reason: "must be 0"})
+		} else {
+			log.WithField("peer_size", peerSize).Warn("RouterInfo has nonzero peer size")
+		}
+	}
+	if len(remainder) < 2 {
+		parseErr.add(FIELD_OPTIONS, -1, ErrOptionsSizeTooShort)
+		return info, remainder, nil
+	}
+	optionsLength := 2 + int(binary.BigEndian.Uint16(remainder))
+	if len(remainder) < optionsLength {
+		parseErr.add(FIELD_OPTIONS, -1, ErrOptionsTooShort)
+		return info, remainder, nil
+	}
+	var options Mapping
+	if optionsLength == 2 {
+		// ParseMapping rejects a mapping of only its size, read it with what follows
+		options, _, _ = ParseMapping(remainder)
+	} else {
+		var optionsErr error
+		// the mapping is read on its own, so it is not reported as followed by data
+		options, _, optionsErr = ParseMapping(remainder[:optionsLength])
+		for _, e := range unjoin(optionsErr) {
+			parseErr.add(FIELD_OPTIONS, -1, e)
 		}
-		err = errors.New("error parsing router info: " + estring)
 	}
-	sigType, err := certificate.GetSignatureTypeFromCertificate(info.router_identity.Certificate())
+	info.options = &options
+	remainder = remainder[optionsLength:]
+	// the signature length depends on the signing key type of the identity
+	sigType := info.router_identity.KeyCertificate.SigningPublicKeyType()
 	log.WithFields(logrus.Fields{
 		"sigType": sigType,
 	}).Debug("Got sigType")
-	info.signature, remainder, err = NewSignature(remainder, sigType)
+	signature, n, err := ParseSignature(remainder, sigType)
+	remainder = remainder[n:]
 	if err != nil {
-		log.WithFields(logrus.Fields{
-			"at":       "(RouterInfo) ReadRouterInfo",
-			"data_len": len(remainder),
-			//"required_len": MAPPING_SIZE,
-			"reason": "not enough data",
-		}).Error("error parsing router info")
-		err = errors.New("error parsing router info: not enough data to read signature")
+		parseErr.add(FIELD_SIGNATURE, -1, err)
+		return
 	}
+	info.signature = &signature
+	parseErr.Complete = true
 
 	log.WithFields(logrus.Fields{
 		"router_identity":  info.router_identity,
@@ -318,6 +394,29 @@ func ReadRouterInfo(bytes []byte) (info RouterInfo, remainder []byte, err error)
 	return
 }
 
+// ParseRouterInfo reads a RouterInfo from the start of data and returns it
+// with the number of bytes consumed. Errors are reported as by ReadRouterInfo,
+// and the RouterInfo aliases data unless CopyOnParse is given.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseRouterInfo(data []byte, opts ...ParseOption) (RouterInfo, int, error) {
+	value, remainder, err := readRouterInfo(data, opts...)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadRouterInfo returns RouterInfo from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Parsing stops at the first field that cannot be read, since everything after
+// it is misaligned, but problems which leave the rest readable, such as a
+// malformed option, are collected and parsing continues. Any error returned is
+// a *RouterInfoParseError listing the failures by field. The RouterInfo
+// aliases bytes unless CopyOnParse is given.
+//
+// Deprecated: use ParseRouterInfo.
+func ReadRouterInfo(data []byte, opts ...ParseOption) (RouterInfo, []byte, error) {
+	return readRouterInfo(data, opts...)
+}
+
 // serializeWithoutSignature serializes the RouterInfo up to (but not including) the signature.
 func (ri *RouterInfo) serializeWithoutSignature() []byte {
 	var bytes []byte
@@ -344,6 +443,44 @@ func (ri *RouterInfo) serializeWithoutSignature() []byte {
 	return bytes
 }
 
+// Verify checks the RouterInfo signature against the signing public key of its
+// RouterIdentity. The signed data is everything preceding the signature and the
+// signature type is taken from the RouterIdentity's key certificate.
+// Returns nil if the signature is valid.
+func (router_info *RouterInfo) Verify() error {
+	log.Debug("Verifying RouterInfo signature")
+	if router_info.signature == nil {
+		return fmt.Errorf("error verifying router info: %w", ErrNoSignature)
+	}
+	if router_info.router_identity.KeyCertificate == nil {
+		return fmt.Errorf("error verifying router info: %w", ErrNoKeyCertificate)
+	}
+	sigType := router_info.router_identity.KeyCertificate.SigningPublicKeyType()
+	sig, _, err := ParseSignature(*router_info.signature, sigType)
+	if err != nil {
+		log.WithError(err).Error("Failed to read RouterInfo signature")
+		return err
+	}
+	if len(sig) != len(*router_info.signature) {
+		log.WithFields(logrus.Fields{
+			"sig_type":        sigType,
+			"expected_length": len(sig),
+			"actual_length":   len(*router_info.signature),
+		}).Error("RouterInfo signature length does not match signature type")
+		return fmt.Errorf("error verifying router info: %w", ErrSignatureLength)
+	}
+	signingPublicKey := router_info.router_identity.SigningPublicKey()
+	if signingPublicKey == nil {
+		return fmt.Errorf("error verifying router info: %w", ErrNoSigningPublicKey)
+	}
+	if err = crypto.VerifySignature(signingPublicKey, router_info.serializeWithoutSignature(), sig); err != nil {
+		log.WithError(err).Warn("RouterInfo signature verification failed")
+		return err
+	}
+	log.Debug("RouterInfo signature verified successfully")
+	return nil
+}
+
 func NewRouterInfo(
 	routerIdentity *RouterIdentity,
 	publishedTime time.Time,
@@ -355,17 +492,10 @@ func NewRouterInfo(
 	log.Debug("Creating new RouterInfo")
 
 	// 1. Create Published Date
-	millis := publishedTime.UnixNano() / int64(time.Millisecond)
-	dateBytes := make([]byte, DATE_SIZE)
-	binary.BigEndian.PutUint64(dateBytes, uint64(millis))
-	publishedDate, _, err := ReadDate(dateBytes)
-	if err != nil {
-		log.WithError(err).Error("Failed to create Published Date")
-		return nil, err
-	}
+	publishedDate := DateFromTime(publishedTime)
 
 	// 2. Create Size Integer
-	sizeInt, err := NewIntegerFromInt(len(addresses), 1)
+	sizeInt, err := NewIntegerFromIntChecked(len(addresses), 1)
 	if err != nil {
 		log.WithError(err).Error("Failed to create Size Integer")
 		return nil, err
@@ -395,30 +525,13 @@ func NewRouterInfo(
 		options:         mapping,
 		signature:       nil, // To be set after signing
 	}
+	routerInfo.cacheIdentHash()
 
-	// 6. Serialize RouterInfo without signature
-	dataBytes := routerInfo.serializeWithoutSignature()
-
-	// 7. Compute signature over serialized data
-	signer, err := signingPrivateKey.NewSigner()
-	if err != nil {
-		log.WithError(err).Error("Failed to create new signer")
-		return nil, err
-	}
-	signatureBytes, err := signer.Sign(dataBytes)
-	if err != nil {
-		log.WithError(err).Error("Failed to sign")
-	}
-
-	// 8. Create Signature struct from signatureBytes
-	sig, _, err := ReadSignature(signatureBytes, sigType)
-	if err != nil {
-		log.WithError(err).Error("Failed to create Signature from signature bytes")
+	// 6. Sign the serialized RouterInfo and attach the signature
+	if err = routerInfo.sign(signingPrivateKey, sigType); err != nil {
 		return nil, err
 	}
-
-	// 9. Attach signature to RouterInfo
-	routerInfo.signature = &sig
+	sig := *routerInfo.signature
 
 	log.WithFields(logrus.Fields{
 		"router_identity": routerIdentity,
@@ -431,65 +544,65 @@ func NewRouterInfo(
 	return routerInfo, nil
 }
 
-func (router_info *RouterInfo) RouterCapabilities() string {
-	log.Debug("Retrieving RouterCapabilities")
-	str, err := ToI2PString("caps")
+// sign computes the signature over the serialized RouterInfo and replaces the current one.
+func (router_info *RouterInfo) sign(signingPrivateKey crypto.SigningPrivateKey, sigType int) error {
+	dataBytes := router_info.serializeWithoutSignature()
+	signer, err := signingPrivateKey.NewSigner()
 	if err != nil {
-		log.WithError(err).Error("Failed to create I2PString for 'caps'")
-		return ""
+		log.WithError(err).Error("Failed to create new signer")
+		return err
 	}
-	// return string(router_info.options.Values().Get(str))
-	caps := string(router_info.options.Values().Get(str))
+	signatureBytes, err := signer.Sign(dataBytes)
+	if err != nil {
+		log.WithError(err).Error("Failed to sign")
+		return err
+	}
+	sig, _, err := ParseSignature(signatureBytes, sigType)
+	if err != nil {
+		log.WithError(err).Error("Failed to create Signature from signature bytes")
+		return err
+	}
+	router_info.signature = &sig
+	return nil
+}
+
+// RouterCapabilities returns the raw value of the caps option.
+func (router_info *RouterInfo) RouterCapabilities() string {
+	caps := router_info.GetOptionString("caps", "")
 	log.WithField("capabilities", caps).Debug("Retrieved RouterCapabilities")
 	return caps
 }
 
+// Capabilities returns the parsed caps option.
+func (router_info *RouterInfo) Capabilities() Capabilities {
+	return ParseCapabilities(router_info.RouterCapabilities())
+}
+
+// RouterVersion returns the value of the router.version option.
 func (router_info *RouterInfo) RouterVersion() string {
-	log.Debug("Retrieving RouterVersion")
-	str, err := ToI2PString("router.version")
-	if err != nil {
-		log.WithError(err).Error("Failed to create I2PString for 'router.version'")
-		return ""
-	}
-	// return string(router_info.options.Values().Get(str))
-	version := string(router_info.options.Values().Get(str))
+	version := router_info.GetOptionString("router.version", "")
 	log.WithField("version", version).Debug("Retrieved RouterVersion")
 	return version
 }
 
+// GoodVersion reports whether the router version is accepted by
+// DefaultVersionPolicy.
 func (router_info *RouterInfo) GoodVersion() bool {
-	log.Debug("Checking if RouterVersion is good")
-	version := router_info.RouterVersion()
-	v := strings.Split(version, ".")
-	if len(v) != 3 {
-		log.WithField("version", version).Warn("Invalid version format")
+	if !DefaultVersionPolicy.Good(router_info) {
+		log.WithField("version", router_info.RouterVersion()).Warn("Version not in good range")
 		return false
 	}
-	if v[0] == "0" {
-		if v[1] == "9" {
-			val, _ := strconv.Atoi(v[2])
-			if val >= MIN_GOOD_VERSION && val <= MAX_GOOD_VERSION {
-				return true
-			}
-		}
-	}
-	log.WithField("version", version).Warn("Version not in good range")
-	return false
+	return true
 }
 
 func (router_info *RouterInfo) UnCongested() bool {
 	log.Debug("Checking if RouterInfo is uncongested")
-	caps := router_info.RouterCapabilities()
-	if strings.Contains(caps, "K") {
-		log.WithField("reason", "K capability").Warn("RouterInfo is congested")
-		return false
-	}
-	if strings.Contains(caps, "G") {
-		log.WithField("reason", "G capability").Warn("RouterInfo is congested")
-		return false
-	}
-	if strings.Contains(caps, "E") {
-		log.WithField("reason", "E capability").Warn("RouterInfo is congested")
+	caps := router_info.Capabilities()
+	if caps.Congested() {
+		log.WithFields(logrus.Fields{
+			"bandwidth":  string(caps.Bandwidth),
+			"congestion": string(caps.Congestion),
+		}).Warn("RouterInfo is congested")
 		return false
 	}
 	log.Debug("RouterInfo is uncongested")
@@ -498,16 +611,14 @@ func (router_info *RouterInfo) UnCongested() bool {
 
 func (router_info *RouterInfo) Reachable() bool {
 	log.Debug("Checking if RouterInfo is reachable")
-	caps := router_info.RouterCapabilities()
-	if strings.Contains(caps, "U") {
+	caps := router_info.Capabilities()
+	if caps.Unreachable {
 		log.WithField("reason", "U capability").Debug("RouterInfo is unreachable")
 		return false
 	}
-	// return strings.Contains(caps, "R")
-	reachable := strings.Contains(caps, "R")
 	log.WithFields(logrus.Fields{
-		"reachable": reachable,
+		"reachable": caps.Reachable,
 		"reason":    "R capability",
 	}).Debug("Checked RouterInfo reachability")
-	return reachable
+	return caps.Reachable
 }
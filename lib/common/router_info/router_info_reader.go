@@ -0,0 +1,80 @@
+package router_info
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	. "github.com/go-i2p/go-i2p/lib/common/signature"
+)
+
+// ReadRouterInfoFrom reads a RouterInfo off r, reading exactly its bytes, so
+// a transport can parse one directly off a stream. Every field is bounded by
+// its length prefix, at most MAX_UNCOMPRESSED_SIZE bytes are buffered.
+func ReadRouterInfoFrom(r io.Reader) (info RouterInfo, err error) {
+	var data bytes.Buffer
+	tee := io.TeeReader(r, &data)
+	identity, err := keys_and_cert.ReadKeysAndCertFrom(tee)
+	if err != nil {
+		return
+	}
+	// published date and address count
+	header, err := readFull(tee, DATE_SIZE+1)
+	if err != nil {
+		return
+	}
+	for i := 0; i < int(header[DATE_SIZE]); i++ {
+		if err = skipRouterAddress(tee); err != nil {
+			return
+		}
+	}
+	// peer size, always 0
+	if _, err = readFull(tee, 1); err != nil {
+		return
+	}
+	if _, err = ReadMappingBytes(tee); err != nil {
+		return info, unexpectedEOF(err)
+	}
+	sigSize, err := SignatureSize(identity.KeyCertificate.SigningPublicKeyType())
+	if err != nil {
+		return
+	}
+	if _, err = readFull(tee, sigSize); err != nil {
+		return
+	}
+	info, _, err = readRouterInfo(data.Bytes())
+	return
+}
+
+// skipRouterAddress reads past a RouterAddress
+func skipRouterAddress(r io.Reader) error {
+	// cost, expiration and the length of the transport style
+	header, err := readFull(r, 1+DATE_SIZE+1)
+	if err != nil {
+		return err
+	}
+	if _, err := readFull(r, int(header[len(header)-1])); err != nil {
+		return err
+	}
+	_, err = ReadMappingBytes(r)
+	return unexpectedEOF(err)
+}
+
+// readFull reads n bytes off r, part way through a RouterInfo
+func readFull(r io.Reader, n int) ([]byte, error) {
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return data, nil
+}
+
+// unexpectedEOF turns io.EOF part way through a RouterInfo into
+// io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
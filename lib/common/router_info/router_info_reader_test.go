@@ -0,0 +1,31 @@
+package router_info
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRouterInfoFrom(t *testing.T) {
+	info := newTestBundle(t, 1)[0]
+	data, err := info.Bytes()
+	require.NoError(t, err)
+
+	// the next structure on the stream is left unread
+	stream := bytes.NewReader(append(append([]byte(nil), data...), 0xde, 0xad))
+	read, err := ReadRouterInfoFrom(stream)
+	require.NoError(t, err)
+	assert.Equal(t, info.IdentHash(), read.IdentHash())
+	assert.NoError(t, read.Verify())
+	assert.Equal(t, 2, stream.Len())
+
+	for _, n := range []int{100, len(data) - 1} {
+		_, err = ReadRouterInfoFrom(bytes.NewReader(data[:n]))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF, "truncated at %d", n)
+	}
+	_, err = ReadRouterInfoFrom(bytes.NewReader(nil))
+	assert.ErrorIs(t, err, io.EOF)
+}
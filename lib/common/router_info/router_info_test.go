@@ -2,6 +2,7 @@ package router_info
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/rand"
 	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
 	"testing"
@@ -19,7 +20,7 @@ import (
 	"github.com/go-i2p/go-i2p/lib/common/router_address"
 )
 
-func generateTestRouterInfo(t *testing.T, publishedTime time.Time) (*RouterInfo, error) {
+func generateTestRouterIdentity(t *testing.T) (*router_identity.RouterIdentity, *crypto.Ed25519PrivateKey) {
 	// Generate signing key pair (Ed25519)
 	var ed25519_privkey crypto.Ed25519PrivateKey
 	_, err := (&ed25519_privkey).Generate()
@@ -74,8 +75,8 @@ func generateTestRouterInfo(t *testing.T, publishedTime time.Time) (*RouterInfo,
 		t.Fatalf("Failed to create crypto public key type integer: %v", err)
 	}
 
-	payload.Write(*cryptoPublicKeyType)
 	payload.Write(*signingPublicKeyType)
+	payload.Write(*cryptoPublicKeyType)
 
 	// Create KeyCertificate specifying key types
 	cert, err := certificate.NewCertificateWithType(certificate.CERT_KEY, payload.Bytes())
@@ -103,6 +104,11 @@ func generateTestRouterInfo(t *testing.T, publishedTime time.Time) (*RouterInfo,
 	if err != nil {
 		t.Fatalf("Failed to create router identity: %v\n", err)
 	}
+	return routerIdentity, &ed25519_privkey
+}
+
+func generateTestRouterInfo(t *testing.T, publishedTime time.Time) (*RouterInfo, error) {
+	routerIdentity, ed25519_privkey := generateTestRouterIdentity(t)
 	// create some dummy addresses
 	options := map[string]string{}
 	routerAddress, err := router_address.NewRouterAddress(3, <-time.After(1*time.Second), "NTCP2", options)
@@ -111,7 +117,7 @@ func generateTestRouterInfo(t *testing.T, publishedTime time.Time) (*RouterInfo,
 	}
 	routerAddresses := []*router_address.RouterAddress{routerAddress}
 	// create router info
-	routerInfo, err := NewRouterInfo(routerIdentity, publishedTime, routerAddresses, nil, &ed25519_privkey, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	routerInfo, err := NewRouterInfo(routerIdentity, publishedTime, routerAddresses, nil, ed25519_privkey, signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
 	if err != nil {
 		t.Fatalf("Failed to create router info: %v\n", err)
 	}
@@ -245,3 +251,158 @@ func TestRouterInfoReachable(t *testing.T) {
 	isReachable := routerInfo.Reachable()
 	assert.IsType(true, isReachable, "Reachable should return a boolean")
 }
+
+// TestRouterInfoBuilder verifies that a RouterInfo built and signed by the builder round trips.
+func TestRouterInfoBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	routerIdentity, privateKey := generateTestRouterIdentity(t)
+	routerAddress, err := router_address.NewRouterAddress(3, time.Now(), "NTCP2", map[string]string{})
+	assert.Nil(err, "RouterAddress creation should not return an error")
+	publishedTime := time.Unix(86400, 0)
+
+	routerInfo, err := NewRouterInfoBuilder(routerIdentity, privateKey).
+		Published(publishedTime).
+		AddAddress(routerAddress).
+		SetOption("caps", "LR").
+		SetOption("router.version", "0.9.64").
+		Build()
+	assert.Nil(err, "Build should not return an error")
+	assert.Equal(publishedTime.Unix(), routerInfo.Published().Time().Unix())
+	assert.Equal(1, routerInfo.RouterAddressCount())
+	assert.Len(routerInfo.Signature(), signature.EdDSA_SHA512_Ed25519_SIZE)
+
+	bytes, err := routerInfo.Bytes()
+	assert.Nil(err, "Serialization should not return an error")
+	_, _, err = ReadRouterInfo(bytes)
+	assert.Nil(err, "Built RouterInfo should parse")
+}
+
+// TestRouterInfoBuilderMissingKey verifies that Build refuses to produce an unsigned RouterInfo.
+func TestRouterInfoBuilderMissingKey(t *testing.T) {
+	routerIdentity, _ := generateTestRouterIdentity(t)
+	_, err := NewRouterInfoBuilder(routerIdentity, nil).Build()
+	assert.NotNil(t, err, "Build without a signing key should fail")
+}
+
+// TestRouterInfoVerify verifies that a freshly signed RouterInfo passes signature verification.
+func TestRouterInfoVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	assert.Nil(err, "RouterInfo creation should not return an error")
+	assert.Nil(routerInfo.Verify(), "Signature of a freshly created RouterInfo should verify")
+}
+
+// TestRouterInfoVerifyTampered verifies that modifying the signed data invalidates the signature.
+func TestRouterInfoVerifyTampered(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	assert.Nil(err, "RouterInfo creation should not return an error")
+
+	routerInfo.published[7] ^= 0xff
+	assert.NotNil(routerInfo.Verify(), "Tampered RouterInfo should not verify")
+
+	routerInfo.signature = nil
+	assert.NotNil(routerInfo.Verify(), "RouterInfo without a signature should not verify")
+}
+
+// buildSpecRouterInfoBytes lays out an Ed25519/X25519 RouterInfo byte for byte as
+// the Java router publishes it: signing type before crypto type in the key
+// certificate, the X25519 key left-aligned and the Ed25519 key right-aligned.
+func buildSpecRouterInfoBytes(t *testing.T) (routerInfoBytes []byte, signingKey ed25519.PublicKey, sig []byte) {
+	signingKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	keys := make([]byte, keys_and_cert.KEYS_AND_CERT_DATA_SIZE)
+	rand.Read(keys)
+	copy(keys[keys_and_cert.KEYS_AND_CERT_DATA_SIZE-ed25519.PublicKeySize:], signingKey)
+	routerInfoBytes = append(routerInfoBytes, keys...)
+	routerInfoBytes = append(routerInfoBytes, 0x05, 0x00, 0x04, 0x00, 0x07, 0x00, 0x04)
+
+	published := make([]byte, data.DATE_SIZE)
+	published[5] = 0x01
+	routerInfoBytes = append(routerInfoBytes, published...)
+	routerInfoBytes = append(routerInfoBytes, 0x00) // no addresses
+	routerInfoBytes = append(routerInfoBytes, 0x00) // peer size
+	options, err := data.GoMapToMapping(map[string]string{"caps": "LR", "router.version": "0.9.64"})
+	if err != nil {
+		t.Fatalf("Failed to create options mapping: %v", err)
+	}
+	routerInfoBytes = append(routerInfoBytes, options.Data()...)
+
+	sig = ed25519.Sign(privateKey, routerInfoBytes)
+	routerInfoBytes = append(routerInfoBytes, sig...)
+	return
+}
+
+// TestReadRouterInfoEd25519SignatureLength verifies that the signature length is taken from the
+// key certificate instead of assuming a 40 byte DSA signature.
+func TestReadRouterInfoEd25519SignatureLength(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfoBytes, signingKey, sig := buildSpecRouterInfoBytes(t)
+	trailing := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	routerInfo, remainder, err := ReadRouterInfo(append(routerInfoBytes, trailing...))
+	assert.Nil(err, "Ed25519 RouterInfo should parse")
+	assert.Equal(trailing, remainder, "Only the bytes after the 64 byte signature should remain")
+	assert.Equal(signature.Signature(sig), routerInfo.Signature())
+
+	keyCert := routerInfo.RouterIdentity().KeyCertificate
+	assert.Equal(key_certificate.KEYCERT_SIGN_ED25519, keyCert.SigningPublicKeyType())
+	assert.Equal(key_certificate.KEYCERT_CRYPTO_X25519, keyCert.PublicKeyType())
+	assert.Equal(signingKey, ed25519.PublicKey(routerInfo.RouterIdentity().SigningPublicKey().Bytes()))
+	assert.Equal(key_certificate.KEYCERT_CRYPTO_X25519_SIZE, routerInfo.RouterIdentity().PublicKey().Len())
+
+	reserialized, err := routerInfo.Bytes()
+	assert.Nil(err, "Serialization should not return an error")
+	assert.Equal(routerInfoBytes, reserialized, "Parsed RouterInfo should serialize to the original bytes")
+}
+
+// TestParseRouterInfo verifies that the bytes of the RouterInfo are reported
+// consumed and the bytes after it are not.
+func TestParseRouterInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfoBytes, _, sig := buildSpecRouterInfoBytes(t)
+	data := append(routerInfoBytes, 0xde, 0xad)
+
+	routerInfo, consumed, err := ParseRouterInfo(data)
+	assert.Nil(err)
+	assert.Equal(len(routerInfoBytes), consumed)
+	assert.Equal(signature.Signature(sig), routerInfo.Signature())
+
+	_, _, err = ParseRouterInfo(routerInfoBytes[:len(routerInfoBytes)-24])
+	var parseErr *RouterInfoParseError
+	if assert.ErrorAs(err, &parseErr) {
+		assert.NotEmpty(parseErr.Field(FIELD_SIGNATURE))
+	}
+}
+
+// TestReadRouterInfoTruncatedEd25519Signature verifies that a short Ed25519 signature is reported.
+func TestReadRouterInfoTruncatedEd25519Signature(t *testing.T) {
+	routerInfoBytes, _, _ := buildSpecRouterInfoBytes(t)
+	_, _, err := ReadRouterInfo(routerInfoBytes[:len(routerInfoBytes)-24])
+	assert.NotNil(t, err, "A 40 byte signature should not satisfy an Ed25519 key certificate")
+}
+
+// TestRouterInfoIdentHash verifies the identity hash covers the full RouterIdentity.
+func TestRouterInfoIdentHash(t *testing.T) {
+	assert := assert.New(t)
+
+	routerInfo, err := generateTestRouterInfo(t, time.Now())
+	assert.Nil(err, "RouterInfo creation should not return an error")
+
+	identity := routerInfo.RouterIdentity().Bytes()
+	wire, err := routerInfo.Bytes()
+	assert.Nil(err)
+	assert.Equal(identity, wire[:len(identity)], "identity should lead the serialized RouterInfo")
+	assert.Equal(data.HashData(identity), routerInfo.IdentHash())
+
+	parsed, _, err := ReadRouterInfo(wire)
+	assert.Nil(err)
+	assert.Equal(routerInfo.IdentHash(), parsed.IdentHash(), "parsed RouterInfo should have the same hash")
+}
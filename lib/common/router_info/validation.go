@@ -0,0 +1,121 @@
+package router_info
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// strictParsing is read by ReadRouterInfo
+var strictParsing atomic.Bool
+
+// SetStrictParsing turns strict RouterInfo parsing on or off for the whole
+// process. Strict parsing rejects RouterInfos which decode but break the spec,
+// such as a nonzero peer_size, with a *ValidationError. It is off by default
+// because some deployed routers publish such fields and are otherwise usable.
+func SetStrictParsing(strict bool) {
+	log.WithField("strict", strict).Debug("Setting strict RouterInfo parsing")
+	strictParsing.Store(strict)
+}
+
+// StrictParsing reports whether strict RouterInfo parsing is on.
+func StrictParsing() bool {
+	return strictParsing.Load()
+}
+
+// ValidationError reports a RouterInfo field which was read successfully but
+// holds a value the spec does not allow.
+type ValidationError struct {
+	// Field is the name of the field in the spec, for example "peer_size"
+	Field string
+	// Value is the value read from the wire
+	Value interface{}
+	// Reason says what the spec requires
+	Reason string
+}
+
+func (err *ValidationError) Error() string {
+	return fmt.Sprintf("invalid router info %s %v: %s", err.Field, err.Value, err.Reason)
+}
+
+// Names of the RouterInfo fields reported in parse errors, as in the spec.
+const (
+	FIELD_IDENTITY  = "router_ident"
+	FIELD_PUBLISHED = "published"
+	FIELD_ADDRESSES = "addresses"
+	FIELD_PEER_SIZE = "peer_size"
+	FIELD_OPTIONS   = "options"
+	FIELD_SIGNATURE = "signature"
+)
+
+// FieldError is a failure to read one field of a RouterInfo.
+type FieldError struct {
+	// Field is one of the FIELD_ constants
+	Field string
+	// Index is the position of the RouterAddress for FIELD_ADDRESSES
+	// errors about a single address, otherwise -1
+	Index int
+	Err   error
+}
+
+func (err *FieldError) Error() string {
+	if err.Index >= 0 {
+		return fmt.Sprintf("%s[%d]: %v", err.Field, err.Index, err.Err)
+	}
+	return fmt.Sprintf("%s: %v", err.Field, err.Err)
+}
+
+func (err *FieldError) Unwrap() error {
+	return err.Err
+}
+
+// RouterInfoParseError collects the errors found by ReadRouterInfo. It
+// unwraps to its FieldErrors, so errors.As finds a *ValidationError or the
+// error of a particular field.
+type RouterInfoParseError struct {
+	Errors []*FieldError
+	// Complete is set if every field was read despite the errors
+	Complete bool
+}
+
+func (err *RouterInfoParseError) Error() string {
+	messages := make([]string, len(err.Errors))
+	for i, e := range err.Errors {
+		messages[i] = e.Error()
+	}
+	return "error parsing router info: " + strings.Join(messages, "; ")
+}
+
+func (err *RouterInfoParseError) Unwrap() []error {
+	errs := make([]error, len(err.Errors))
+	for i, e := range err.Errors {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Field returns the errors about field.
+func (err *RouterInfoParseError) Field(field string) []*FieldError {
+	var errs []*FieldError
+	for _, e := range err.Errors {
+		if e.Field == field {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func (err *RouterInfoParseError) add(field string, index int, e error) {
+	err.Errors = append(err.Errors, &FieldError{Field: field, Index: index, Err: e})
+}
+
+// unjoin splits an error made by errors.Join into the errors it joins.
+func unjoin(e error) []error {
+	if e == nil {
+		return nil
+	}
+	if joined, ok := e.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{e}
+}
@@ -0,0 +1,74 @@
+package router_info
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestReadRouterInfoPeerSize verifies a nonzero peer_size is kept by default and
+// rejected in strict mode.
+func TestReadRouterInfoPeerSize(t *testing.T) {
+	assert := assert.New(t)
+	t.Cleanup(func() { SetStrictParsing(false) })
+
+	routerInfoBytes, _, _ := buildSpecRouterInfoBytes(t)
+	peerSizeOffset := keys_and_cert.KEYS_AND_CERT_DATA_SIZE + 7 + data.DATE_SIZE + 1
+	assert.Equal(byte(0), routerInfoBytes[peerSizeOffset])
+	routerInfoBytes[peerSizeOffset] = 3
+
+	routerInfo, _, err := ReadRouterInfo(routerInfoBytes)
+	assert.Nil(err, "Nonzero peer size should be accepted when not strict")
+	assert.Equal(3, routerInfo.PeerSize())
+
+	SetStrictParsing(true)
+	assert.True(StrictParsing())
+	_, _, err = ReadRouterInfo(routerInfoBytes)
+	var validationErr *ValidationError
+	if assert.True(errors.As(err, &validationErr), "Strict parsing should return a ValidationError") {
+		assert.Equal("peer_size", validationErr.Field)
+		assert.Equal(3, validationErr.Value)
+	}
+
+	routerInfoBytes[peerSizeOffset] = 0
+	_, _, err = ReadRouterInfo(routerInfoBytes)
+	assert.Nil(err, "Zero peer size should pass strict parsing")
+}
+
+// TestReadRouterInfoParseError verifies parse failures are reported by field.
+func TestReadRouterInfoParseError(t *testing.T) {
+	assert := assert.New(t)
+	routerInfoBytes, _, _ := buildSpecRouterInfoBytes(t)
+
+	_, _, err := ReadRouterInfo(routerInfoBytes[:100])
+	var parseErr *RouterInfoParseError
+	if assert.True(errors.As(err, &parseErr)) {
+		assert.Len(parseErr.Field(FIELD_IDENTITY), 1)
+		assert.False(parseErr.Complete)
+	}
+
+	_, _, err = ReadRouterInfo(routerInfoBytes[:len(routerInfoBytes)-1])
+	if assert.True(errors.As(err, &parseErr)) {
+		assert.Len(parseErr.Errors, 1)
+		assert.Len(parseErr.Field(FIELD_SIGNATURE), 1)
+		assert.Contains(err.Error(), FIELD_SIGNATURE)
+	}
+
+	// a duplicate option is reported, but the rest of the RouterInfo is read
+	optionsOffset := keys_and_cert.KEYS_AND_CERT_DATA_SIZE + 7 + data.DATE_SIZE + 2
+	options := []byte{0x00, 0x0c, 0x01, 'a', '=', 0x01, '1', ';', 0x01, 'a', '=', 0x01, '2', ';'}
+	duplicate := append([]byte{}, routerInfoBytes[:optionsOffset]...)
+	duplicate = append(duplicate, options...)
+	duplicate = append(duplicate, make([]byte, 64)...)
+	routerInfo, remainder, err := ReadRouterInfo(duplicate)
+	if assert.True(errors.As(err, &parseErr)) {
+		assert.True(parseErr.Complete)
+		assert.NotEmpty(parseErr.Field(FIELD_OPTIONS))
+		assert.Empty(parseErr.Field(FIELD_SIGNATURE))
+	}
+	assert.Empty(remainder)
+	assert.Len(routerInfo.Signature(), 64)
+}
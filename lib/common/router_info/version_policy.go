@@ -0,0 +1,111 @@
+package router_info
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionPolicy decides which router.version values are recent enough to
+// use. The netdb and peer selection can each apply their own policy, for
+// example to stop building tunnels through a release with a known bug.
+type VersionPolicy struct {
+	// Min is the oldest accepted version, empty to accept any
+	Min string
+	// Max is the newest accepted version, empty for no upper bound
+	Max string
+	// Blocked versions are rejected even if they are in range
+	Blocked []string
+	// ZeroMajorOnly rejects versions which do not start with 0. All current
+	// routers publish 0.9.x whatever their release number, so a 1.x or 2.x
+	// router.version is either from the future or mislabelled.
+	ZeroMajorOnly bool
+}
+
+// DefaultVersionPolicy accepts every version since 0.9.MIN_GOOD_VERSION.
+var DefaultVersionPolicy = VersionPolicy{
+	Min: "0.9." + strconv.Itoa(MIN_GOOD_VERSION),
+}
+
+// Accepts reports whether version satisfies the policy. Versions are dot
+// separated numbers, anything else is rejected.
+func (policy VersionPolicy) Accepts(version string) bool {
+	parsed, err := parseVersion(version)
+	if err != nil {
+		log.WithError(err).Debug("Rejecting unparseable router version")
+		return false
+	}
+	if policy.ZeroMajorOnly && parsed[0] != 0 {
+		return false
+	}
+	for _, blocked := range policy.Blocked {
+		if b, err := parseVersion(blocked); err == nil && compareVersions(parsed, b) == 0 {
+			return false
+		}
+	}
+	if policy.Min != "" {
+		min, err := parseVersion(policy.Min)
+		if err != nil || compareVersions(parsed, min) < 0 {
+			return false
+		}
+	}
+	if policy.Max != "" {
+		max, err := parseVersion(policy.Max)
+		if err != nil || compareVersions(parsed, max) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate returns ErrInvalidVersion if Min, Max or a blocked version is not
+// a version, which would make the policy reject everything.
+func (policy VersionPolicy) Validate() error {
+	for _, version := range append([]string{policy.Min, policy.Max}, policy.Blocked...) {
+		if version == "" {
+			continue
+		}
+		if _, err := parseVersion(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Good reports whether the router.version of router_info satisfies the policy.
+func (policy VersionPolicy) Good(router_info *RouterInfo) bool {
+	return policy.Accepts(router_info.RouterVersion())
+}
+
+func parseVersion(version string) ([]int, error) {
+	fields := strings.Split(strings.TrimSpace(version), ".")
+	parsed := make([]int, len(fields))
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w %q", ErrInvalidVersion, version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareVersions compares parsed versions, missing components count as 0
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
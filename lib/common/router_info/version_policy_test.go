@@ -0,0 +1,54 @@
+package router_info
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultVersionPolicy(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(DefaultVersionPolicy.Accepts("0.9.58"))
+	assert.True(DefaultVersionPolicy.Accepts("0.9.64"))
+	assert.True(DefaultVersionPolicy.Accepts("0.9.100"))
+	assert.True(DefaultVersionPolicy.Accepts("1.5.0"))
+	assert.True(DefaultVersionPolicy.Accepts("2.4.0"))
+	assert.False(DefaultVersionPolicy.Accepts("0.9.57"))
+	assert.False(DefaultVersionPolicy.Accepts("0.8.99"))
+	assert.False(DefaultVersionPolicy.Accepts(""))
+	assert.False(DefaultVersionPolicy.Accepts("0.9.x"))
+}
+
+func TestVersionPolicyOptions(t *testing.T) {
+	assert := assert.New(t)
+	policy := VersionPolicy{
+		Min:           "0.9.60",
+		Max:           "0.9.65",
+		Blocked:       []string{"0.9.62"},
+		ZeroMajorOnly: true,
+	}
+	assert.True(policy.Accepts("0.9.60"))
+	assert.True(policy.Accepts("0.9.65"))
+	assert.False(policy.Accepts("0.9.62"))
+	assert.False(policy.Accepts("0.9.66"))
+	assert.False(policy.Accepts("1.0.0"))
+
+	assert.True(VersionPolicy{}.Accepts("3"))
+	assert.False(VersionPolicy{ZeroMajorOnly: true}.Accepts("3"))
+}
+
+func TestVersionPolicyValidate(t *testing.T) {
+	assert.NoError(t, DefaultVersionPolicy.Validate())
+	assert.NoError(t, VersionPolicy{Max: "0.9.65", Blocked: []string{"0.9.62"}}.Validate())
+	assert.ErrorIs(t, VersionPolicy{Min: "0.9.x"}.Validate(), ErrInvalidVersion)
+	assert.ErrorIs(t, VersionPolicy{Blocked: []string{"latest"}}.Validate(), ErrInvalidVersion)
+}
+
+func TestRouterInfoGoodVersionReadsOption(t *testing.T) {
+	owned := newTestOwnedRouterInfo(t)
+	assert.False(t, owned.RouterInfo().GoodVersion())
+	assert.NoError(t, owned.SetOption("router.version", "0.9.64"))
+	assert.Equal(t, "0.9.64", owned.RouterInfo().RouterVersion())
+	assert.True(t, owned.RouterInfo().GoodVersion())
+	assert.False(t, VersionPolicy{Blocked: []string{"0.9.64"}}.Good(owned.RouterInfo()))
+}
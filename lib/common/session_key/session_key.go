@@ -1,7 +1,16 @@
 // Package session_key implements the I2P SessionKey common data structure
 package session_key
 
-import log "github.com/sirupsen/logrus"
+import (
+	"errors"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// SESSION_KEY_SIZE is the size of a SessionKey
+const SESSION_KEY_SIZE = 32
+
+var ErrSessionKeyTooShort = errors.New("error parsing session key: not enough data")
 
 /*
 [SessionKey]
@@ -19,20 +28,43 @@ Contents
 // https://geti2p.net/spec/common-structures#sessionkey
 type SessionKey [32]byte
 
+// readSessionKey reads a SessionKey, returning the data after it
+func readSessionKey(bytes []byte) (info SessionKey, remainder []byte, err error) {
+	if len(bytes) < SESSION_KEY_SIZE {
+		err = ErrSessionKeyTooShort
+		log.WithField("data_length", len(bytes)).Error("Failed to read SessionKey")
+		return
+	}
+	copy(info[:], bytes[:SESSION_KEY_SIZE])
+	remainder = bytes[SESSION_KEY_SIZE:]
+	return
+}
+
+// ParseSessionKey reads a SessionKey from the first SESSION_KEY_SIZE bytes of
+// data and returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseSessionKey(data []byte) (SessionKey, int, error) {
+	value, remainder, err := readSessionKey(data)
+	return value, len(data) - len(remainder), err
+}
+
 // ReadSessionKey returns SessionKey from a []byte.
 // The remaining bytes after the specified length are also returned.
 // Returns a list of errors that occurred during parsing.
-func ReadSessionKey(bytes []byte) (info SessionKey, remainder []byte, err error) {
-	// TODO: stub
-	log.Warn("ReadSessionKey is not implemented")
-	return
+//
+// Deprecated: use ParseSessionKey.
+func ReadSessionKey(data []byte) (SessionKey, []byte, error) {
+	return readSessionKey(data)
 }
 
 // NewSessionKey creates a new *SessionKey from []byte using ReadSessionKey.
 // Returns a pointer to SessionKey unlike ReadSessionKey.
+//
+// Deprecated: use ParseSessionKey.
 func NewSessionKey(data []byte) (session_key *SessionKey, remainder []byte, err error) {
 	log.WithField("input_length", len(data)).Debug("Creating new SessionKey")
-	sessionKey, remainder, err := ReadSessionKey(data)
+	sessionKey, remainder, err := readSessionKey(data)
 	if err != nil {
 		log.WithError(err).Error("Failed to create new SessionKey")
 		return nil, remainder, err
@@ -0,0 +1,62 @@
+package session_key
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionKeyExactLength(t *testing.T) {
+	assert := assert.New(t)
+
+	data := bytes.Repeat([]byte{0x42}, SESSION_KEY_SIZE)
+	key, consumed, err := ParseSessionKey(data)
+	assert.Nil(err)
+	assert.Equal(SESSION_KEY_SIZE, consumed)
+	assert.Equal(data, key[:])
+}
+
+func TestParseSessionKeyWithRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	data := append(bytes.Repeat([]byte{0x42}, SESSION_KEY_SIZE), 0x01, 0x02)
+	key, consumed, err := ParseSessionKey(data)
+	assert.Nil(err)
+	assert.Equal(SESSION_KEY_SIZE, consumed)
+	assert.Equal(data[:SESSION_KEY_SIZE], key[:])
+	assert.Equal([]byte{0x01, 0x02}, data[consumed:])
+}
+
+func TestParseSessionKeyTooShort(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, size := range []int{0, 1, SESSION_KEY_SIZE - 1} {
+		data := make([]byte, size)
+		key, consumed, err := ParseSessionKey(data)
+		assert.ErrorIs(err, ErrSessionKeyTooShort, "size %d", size)
+		assert.Empty(data[consumed:], "size %d", size)
+		assert.Equal(SessionKey{}, key)
+	}
+}
+
+func TestReadSessionKeyTooShort(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, err := ReadSessionKey(make([]byte, SESSION_KEY_SIZE-1))
+	assert.ErrorIs(err, ErrSessionKeyTooShort)
+	key, remainder, err := NewSessionKey(make([]byte, SESSION_KEY_SIZE-1))
+	assert.ErrorIs(err, ErrSessionKeyTooShort)
+	assert.Nil(key)
+	assert.Empty(remainder)
+}
+
+func TestReadSessionKeyExactLength(t *testing.T) {
+	assert := assert.New(t)
+
+	data := bytes.Repeat([]byte{0x42}, SESSION_KEY_SIZE)
+	key, remainder, err := ReadSessionKey(data)
+	assert.Nil(err)
+	assert.Equal(data, key[:])
+	assert.Empty(remainder)
+}
@@ -2,12 +2,19 @@
 package session_tag
 
 import (
+	"errors"
+
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 	"github.com/sirupsen/logrus"
 )
 
 var log = logger.GetGoI2PLogger()
 
+// SESSION_TAG_SIZE is the size of a SessionTag
+const SESSION_TAG_SIZE = 32
+
+var ErrSessionTagTooShort = errors.New("error parsing session tag: not enough data")
+
 /*
 [SessionKey]
 Accurate for version 0.9.49
@@ -24,20 +31,43 @@ Contents
 // https://geti2p.net/spec/common-structures#session-tag
 type SessionTag [32]byte
 
+// readSessionTag reads a SessionTag, returning the data after it
+func readSessionTag(bytes []byte) (info SessionTag, remainder []byte, err error) {
+	if len(bytes) < SESSION_TAG_SIZE {
+		err = ErrSessionTagTooShort
+		log.WithField("data_length", len(bytes)).Error("Failed to read SessionTag")
+		return
+	}
+	copy(info[:], bytes[:SESSION_TAG_SIZE])
+	remainder = bytes[SESSION_TAG_SIZE:]
+	return
+}
+
+// ParseSessionTag reads a SessionTag from the first SESSION_TAG_SIZE bytes of
+// data and returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseSessionTag(data []byte) (SessionTag, int, error) {
+	value, remainder, err := readSessionTag(data)
+	return value, len(data) - len(remainder), err
+}
+
 // ReadSessionTag returns SessionTag from a []byte.
 // The remaining bytes after the specified length are also returned.
 // Returns a list of errors that occurred during parsing.
-func ReadSessionTag(bytes []byte) (info SessionTag, remainder []byte, err error) {
-	// TODO: stub
-	log.Warn("ReadSessionTag is not implemented")
-	return
+//
+// Deprecated: use ParseSessionTag.
+func ReadSessionTag(data []byte) (SessionTag, []byte, error) {
+	return readSessionTag(data)
 }
 
 // NewSessionTag creates a new *SessionTag from []byte using ReadSessionTag.
 // Returns a pointer to SessionTag unlike ReadSessionTag.
+//
+// Deprecated: use ParseSessionTag.
 func NewSessionTag(data []byte) (session_tag *SessionTag, remainder []byte, err error) {
 	log.WithField("input_length", len(data)).Debug("Creating new SessionTag")
-	sessionTag, remainder, err := ReadSessionTag(data)
+	sessionTag, remainder, err := readSessionTag(data)
 	if err != nil {
 		log.WithError(err).Error("Failed to read SessionTag")
 		return nil, remainder, err
@@ -0,0 +1,62 @@
+package session_tag
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSessionTagExactLength(t *testing.T) {
+	assert := assert.New(t)
+
+	data := bytes.Repeat([]byte{0x42}, SESSION_TAG_SIZE)
+	tag, consumed, err := ParseSessionTag(data)
+	assert.Nil(err)
+	assert.Equal(SESSION_TAG_SIZE, consumed)
+	assert.Equal(data, tag[:])
+}
+
+func TestParseSessionTagWithRemainder(t *testing.T) {
+	assert := assert.New(t)
+
+	data := append(bytes.Repeat([]byte{0x42}, SESSION_TAG_SIZE), 0x01, 0x02)
+	tag, consumed, err := ParseSessionTag(data)
+	assert.Nil(err)
+	assert.Equal(SESSION_TAG_SIZE, consumed)
+	assert.Equal(data[:SESSION_TAG_SIZE], tag[:])
+	assert.Equal([]byte{0x01, 0x02}, data[consumed:])
+}
+
+func TestParseSessionTagTooShort(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, size := range []int{0, 1, SESSION_TAG_SIZE - 1} {
+		data := make([]byte, size)
+		tag, consumed, err := ParseSessionTag(data)
+		assert.ErrorIs(err, ErrSessionTagTooShort, "size %d", size)
+		assert.Empty(data[consumed:], "size %d", size)
+		assert.Equal(SessionTag{}, tag)
+	}
+}
+
+func TestReadSessionTagTooShort(t *testing.T) {
+	assert := assert.New(t)
+
+	_, _, err := ReadSessionTag(make([]byte, SESSION_TAG_SIZE-1))
+	assert.ErrorIs(err, ErrSessionTagTooShort)
+	tag, remainder, err := NewSessionTag(make([]byte, SESSION_TAG_SIZE-1))
+	assert.ErrorIs(err, ErrSessionTagTooShort)
+	assert.Nil(tag)
+	assert.Empty(remainder)
+}
+
+func TestReadSessionTagExactLength(t *testing.T) {
+	assert := assert.New(t)
+
+	data := bytes.Repeat([]byte{0x42}, SESSION_TAG_SIZE)
+	tag, remainder, err := ReadSessionTag(data)
+	assert.Nil(err)
+	assert.Equal(data, tag[:])
+	assert.Empty(remainder)
+}
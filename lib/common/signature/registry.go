@@ -0,0 +1,213 @@
+package signature
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+var ErrUnknownSignatureType = errors.New("unsupported signature type")
+
+// SignatureType describes a signature type code: its name, sizes and how to
+// build keys of the type.
+type SignatureType struct {
+	Code int
+	// Name is the name of the type in the spec, for example
+	// "EdDSA_SHA512_Ed25519"
+	Name string
+	// SignatureSize is the size of a signature
+	SignatureSize int
+	// PublicKeySize is the size of a signing public key
+	PublicKeySize int
+	// PublicKey builds a signing public key from its bytes, nil if this
+	// implementation cannot verify the type
+	PublicKey func(data []byte) (crypto.SigningPublicKey, error)
+	// Signer builds a signer from private key bytes, nil if this
+	// implementation cannot sign with the type
+	Signer func(data []byte) (crypto.Signer, error)
+}
+
+var registry = struct {
+	sync.RWMutex
+	types map[int]SignatureType
+}{types: make(map[int]SignatureType)}
+
+// RegisterSignatureType adds t to the registry, replacing any type with the
+// same code.
+func RegisterSignatureType(t SignatureType) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.types[t.Code] = t
+}
+
+// LookupSignatureType returns the registered type with code.
+func LookupSignatureType(code int) (SignatureType, error) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.types[code]
+	if !ok {
+		return t, fmt.Errorf("%w: %d", ErrUnknownSignatureType, code)
+	}
+	return t, nil
+}
+
+// SignatureTypes returns every registered type ordered by code.
+func SignatureTypes() []SignatureType {
+	registry.RLock()
+	defer registry.RUnlock()
+	types := make([]SignatureType, 0, len(registry.types))
+	for _, t := range registry.types {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+	return types
+}
+
+// SignatureSize returns the size of signatures of type code.
+func SignatureSize(code int) (int, error) {
+	t, err := LookupSignatureType(code)
+	return t.SignatureSize, err
+}
+
+// PublicKeySize returns the size of signing public keys of type code.
+func PublicKeySize(code int) (int, error) {
+	t, err := LookupSignatureType(code)
+	return t.PublicKeySize, err
+}
+
+// NewSigningPublicKey builds a signing public key of type code from data.
+func NewSigningPublicKey(code int, data []byte) (crypto.SigningPublicKey, error) {
+	t, err := LookupSignatureType(code)
+	if err != nil {
+		return nil, err
+	}
+	if t.PublicKey == nil {
+		return nil, fmt.Errorf("%w: %s keys are not implemented", ErrUnknownSignatureType, t.Name)
+	}
+	if len(data) != t.PublicKeySize {
+		return nil, fmt.Errorf("%s public key needs %d bytes, got %d", t.Name, t.PublicKeySize, len(data))
+	}
+	return t.PublicKey(data)
+}
+
+// NewVerifier returns a verifier for signatures of type code by publicKey.
+func NewVerifier(code int, publicKey []byte) (crypto.Verifier, error) {
+	key, err := NewSigningPublicKey(code, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return key.NewVerifier()
+}
+
+// NewSigner returns a signer of type code using privateKey.
+func NewSigner(code int, privateKey []byte) (crypto.Signer, error) {
+	t, err := LookupSignatureType(code)
+	if err != nil {
+		return nil, err
+	}
+	if t.Signer == nil {
+		return nil, fmt.Errorf("%w: signing with %s is not implemented", ErrUnknownSignatureType, t.Name)
+	}
+	return t.Signer(privateKey)
+}
+
+func init() {
+	for _, t := range []SignatureType{
+		{
+			Code: SIGNATURE_TYPE_DSA_SHA1, Name: "DSA_SHA1",
+			SignatureSize: DSA_SHA1_SIZE, PublicKeySize: 128,
+			PublicKey: func(data []byte) (crypto.SigningPublicKey, error) {
+				var key crypto.DSAPublicKey
+				copy(key[:], data)
+				return key, nil
+			},
+			Signer: func(data []byte) (crypto.Signer, error) {
+				var key crypto.DSAPrivateKey
+				if len(data) != len(key) {
+					return nil, crypto.ErrInvalidKeyFormat
+				}
+				copy(key[:], data)
+				return key.NewSigner()
+			},
+		},
+		{
+			Code: SIGNATURE_TYPE_ECDSA_SHA256_P256, Name: "ECDSA_SHA256_P256",
+			SignatureSize: ECDSA_SHA256_P256_SIZE, PublicKeySize: 64,
+			PublicKey: func(data []byte) (crypto.SigningPublicKey, error) {
+				var key crypto.ECP256PublicKey
+				copy(key[:], data)
+				return key, nil
+			},
+		},
+		{
+			Code: SIGNATURE_TYPE_ECDSA_SHA384_P384, Name: "ECDSA_SHA384_P384",
+			SignatureSize: ECDSA_SHA384_P384_SIZE, PublicKeySize: 96,
+			PublicKey: func(data []byte) (crypto.SigningPublicKey, error) {
+				var key crypto.ECP384PublicKey
+				copy(key[:], data)
+				return key, nil
+			},
+		},
+		{
+			Code: SIGNATURE_TYPE_ECDSA_SHA512_P521, Name: "ECDSA_SHA512_P521",
+			SignatureSize: ECDSA_SHA512_P512_SIZE, PublicKeySize: 132,
+			PublicKey: func(data []byte) (crypto.SigningPublicKey, error) {
+				var key crypto.ECP521PublicKey
+				copy(key[:], data)
+				return key, nil
+			},
+		},
+		{
+			Code: SIGNATURE_TYPE_RSA_SHA256_2048, Name: "RSA_SHA256_2048",
+			SignatureSize: RSA_SHA256_2048_SIZE, PublicKeySize: 256,
+		},
+		{
+			Code: SIGNATURE_TYPE_RSA_SHA384_3072, Name: "RSA_SHA384_3072",
+			SignatureSize: RSA_SHA384_3072_SIZE, PublicKeySize: 384,
+		},
+		{
+			Code: SIGNATURE_TYPE_RSA_SHA512_4096, Name: "RSA_SHA512_4096",
+			SignatureSize: RSA_SHA512_4096_SIZE, PublicKeySize: 512,
+		},
+		{
+			Code: SIGNATURE_TYPE_EDDSA_SHA512_ED25519, Name: "EdDSA_SHA512_Ed25519",
+			SignatureSize: EdDSA_SHA512_Ed25519_SIZE, PublicKeySize: 32,
+			PublicKey: newEd25519PublicKey,
+			Signer:    newEd25519Signer,
+		},
+		{
+			Code: SIGNATURE_TYPE_EDDSA_SHA512_ED25519PH, Name: "EdDSA_SHA512_Ed25519ph",
+			SignatureSize: EdDSA_SHA512_Ed25519ph_SIZE, PublicKeySize: 32,
+			PublicKey: newEd25519PublicKey,
+		},
+		{
+			Code: SIGNATURE_TYPE_REDDSA_SHA512_ED25519, Name: "RedDSA_SHA512_Ed25519",
+			SignatureSize: RedDSA_SHA512_Ed25519_SIZE, PublicKeySize: 32,
+			PublicKey: func(data []byte) (crypto.SigningPublicKey, error) {
+				return crypto.RedDSAPublicKey(append([]byte(nil), data...)), nil
+			},
+			Signer: func(data []byte) (crypto.Signer, error) {
+				return crypto.RedDSAPrivateKey(data).NewSigner()
+			},
+		},
+	} {
+		RegisterSignatureType(t)
+	}
+}
+
+func newEd25519PublicKey(data []byte) (crypto.SigningPublicKey, error) {
+	return crypto.CreateEd25519PublicKeyFromBytes(append([]byte(nil), data...))
+}
+
+// newEd25519Signer accepts a 32 byte seed, as I2P stores Ed25519 private
+// keys, or a 64 byte Go private key
+func newEd25519Signer(data []byte) (crypto.Signer, error) {
+	key, err := crypto.CreateEd25519PrivateKeyFromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return key.NewSigner()
+}
@@ -0,0 +1,113 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignatureTypeSizes(t *testing.T) {
+	for code, want := range map[int][2]int{
+		SIGNATURE_TYPE_DSA_SHA1:               {40, 128},
+		SIGNATURE_TYPE_ECDSA_SHA256_P256:      {64, 64},
+		SIGNATURE_TYPE_ECDSA_SHA512_P521:      {132, 132},
+		SIGNATURE_TYPE_RSA_SHA512_4096:        {512, 512},
+		SIGNATURE_TYPE_EDDSA_SHA512_ED25519:   {64, 32},
+		SIGNATURE_TYPE_REDDSA_SHA512_ED25519:  {64, 32},
+		SIGNATURE_TYPE_EDDSA_SHA512_ED25519PH: {64, 32},
+	} {
+		sigSize, err := SignatureSize(code)
+		require.NoError(t, err)
+		keySize, err := PublicKeySize(code)
+		require.NoError(t, err)
+		assert.Equal(t, want, [2]int{sigSize, keySize}, "type %d", code)
+	}
+}
+
+func TestLookupUnknownSignatureType(t *testing.T) {
+	_, err := LookupSignatureType(1000)
+	assert.ErrorIs(t, err, ErrUnknownSignatureType)
+	_, err = SignatureSize(1000)
+	assert.ErrorIs(t, err, ErrUnknownSignatureType)
+	_, err = NewVerifier(SIGNATURE_TYPE_RSA_SHA256_2048, make([]byte, 256))
+	assert.ErrorIs(t, err, ErrUnknownSignatureType)
+}
+
+func TestSignatureTypesOrdered(t *testing.T) {
+	types := SignatureTypes()
+	require.NotEmpty(t, types)
+	for i := 1; i < len(types); i++ {
+		assert.Less(t, types[i-1].Code, types[i].Code)
+	}
+}
+
+func TestEd25519SignerVerifier(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	signer, err := NewSigner(SIGNATURE_TYPE_EDDSA_SHA512_ED25519, private.Seed())
+	require.NoError(t, err)
+	sig, err := signer.Sign([]byte("message"))
+	require.NoError(t, err)
+
+	verifier, err := NewVerifier(SIGNATURE_TYPE_EDDSA_SHA512_ED25519, public)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify([]byte("message"), sig))
+	assert.Error(t, verifier.Verify([]byte("other"), sig))
+
+	_, err = NewVerifier(SIGNATURE_TYPE_EDDSA_SHA512_ED25519, public[:31])
+	assert.Error(t, err)
+}
+
+func TestRegisterSignatureType(t *testing.T) {
+	const code = 65000
+	RegisterSignatureType(SignatureType{Code: code, Name: "test", SignatureSize: 10, PublicKeySize: 20})
+	defer func() {
+		registry.Lock()
+		delete(registry.types, code)
+		registry.Unlock()
+	}()
+	size, err := SignatureSize(code)
+	require.NoError(t, err)
+	assert.Equal(t, 10, size)
+}
+
+func TestRedDSASignerVerifier(t *testing.T) {
+	var private crypto.RedDSAPrivateKey
+	_, err := private.Generate()
+	require.NoError(t, err)
+	public, err := private.Public()
+	require.NoError(t, err)
+
+	signer, err := NewSigner(SIGNATURE_TYPE_REDDSA_SHA512_ED25519, private)
+	require.NoError(t, err)
+	sig, err := signer.Sign([]byte("message"))
+	require.NoError(t, err)
+	verifier, err := NewVerifier(SIGNATURE_TYPE_REDDSA_SHA512_ED25519, public.Bytes())
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify([]byte("message"), sig))
+}
+
+func TestGeneratedKeysMatchRegistry(t *testing.T) {
+	for _, code := range []int{SIGNATURE_TYPE_DSA_SHA1, SIGNATURE_TYPE_EDDSA_SHA512_ED25519, SIGNATURE_TYPE_REDDSA_SHA512_ED25519} {
+		public, private, err := crypto.GenerateSigningKeyPair(code)
+		require.NoError(t, err)
+		size, err := PublicKeySize(code)
+		require.NoError(t, err)
+		assert.Equal(t, size, public.Len())
+
+		signer, err := private.NewSigner()
+		require.NoError(t, err)
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		sigSize, err := SignatureSize(code)
+		require.NoError(t, err)
+		assert.Len(t, sig, sigSize)
+		verifier, err := NewVerifier(code, public.Bytes())
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify([]byte("message"), sig))
+	}
+}
@@ -54,41 +54,10 @@ DSA_SHA1. As of release 0.9.12, other types may be supported, depending on conte
 // https://geti2p.net/spec/common-structures#signature
 type Signature []byte
 
-// ReadSignature returns a Signature from a []byte.
-// The remaining bytes after the specified length are also returned.
-// Returns an error if there is insufficient data to read the signature.
-//
-// Since the signature type and length are inferred from context (the type of key used),
-// and are not explicitly stated, this function assumes the default signature type (DSA_SHA1)
-// with a length of 40 bytes.
-//
-// If a different signature type is expected based on context, this function should be
-// modified accordingly to handle the correct signature length.
-func ReadSignature(data []byte, sigType int) (sig Signature, remainder []byte, err error) {
-	var sigLength int
-	switch sigType {
-	case SIGNATURE_TYPE_DSA_SHA1:
-		sigLength = DSA_SHA1_SIZE
-	case SIGNATURE_TYPE_ECDSA_SHA256_P256:
-		sigLength = ECDSA_SHA256_P256_SIZE
-	case SIGNATURE_TYPE_ECDSA_SHA384_P384:
-		sigLength = ECDSA_SHA384_P384_SIZE
-	case SIGNATURE_TYPE_ECDSA_SHA512_P521:
-		sigLength = ECDSA_SHA512_P512_SIZE
-	case SIGNATURE_TYPE_RSA_SHA256_2048:
-		sigLength = RSA_SHA256_2048_SIZE
-	case SIGNATURE_TYPE_RSA_SHA384_3072:
-		sigLength = RSA_SHA384_3072_SIZE
-	case SIGNATURE_TYPE_RSA_SHA512_4096:
-		sigLength = RSA_SHA512_4096_SIZE
-	case SIGNATURE_TYPE_EDDSA_SHA512_ED25519:
-		sigLength = EdDSA_SHA512_Ed25519_SIZE
-	case SIGNATURE_TYPE_EDDSA_SHA512_ED25519PH:
-		sigLength = EdDSA_SHA512_Ed25519ph_SIZE
-	case SIGNATURE_TYPE_REDDSA_SHA512_ED25519:
-		sigLength = RedDSA_SHA512_Ed25519_SIZE
-	default:
-		err = fmt.Errorf("unsupported signature type: %d", sigType)
+// readSignature reads a Signature, returning the data after it
+func readSignature(data []byte, sigType int) (sig Signature, remainder []byte, err error) {
+	sigLength, err := SignatureSize(sigType)
+	if err != nil {
 		return
 	}
 
@@ -102,11 +71,44 @@ func ReadSignature(data []byte, sigType int) (sig Signature, remainder []byte, e
 	return
 }
 
+// ParseSignature reads a Signature of type sigType from the start of data and
+// returns it with the number of bytes consumed.
+// On error, data[consumed:] is the data left unread, which is empty if the
+// end of the value could not be told.
+func ParseSignature(data []byte, sigType int) (Signature, int, error) {
+	value, remainder, err := readSignature(data, sigType)
+	return value, len(data) - len(remainder), err
+}
+
+// ReadSignature returns a Signature from a []byte.
+// The remaining bytes after the specified length are also returned.
+// Returns an error if there is insufficient data to read the signature.
+//
+// Signature type and length are not stored with the signature but inferred
+// from context, the type of the key used. The length of sigType is looked up
+// in the signature type registry.
+//
+// Deprecated: use ParseSignature.
+func ReadSignature(data []byte, sigType int) (Signature, []byte, error) {
+	return readSignature(data, sigType)
+}
+
+// Clone returns a copy of the Signature which does not alias the buffer it was
+// read from.
+func (signature Signature) Clone() Signature {
+	if signature == nil {
+		return nil
+	}
+	return append(Signature{}, signature...)
+}
+
 // NewSignature creates a new *Signature from []byte using ReadSignature.
 // Returns a pointer to Signature unlike ReadSignature.
+//
+// Deprecated: use ParseSignature.
 func NewSignature(data []byte, sigType int) (signature *Signature, remainder []byte, err error) {
 	log.WithField("input_length", len(data)).Debug("Creating new Signature")
-	sig, remainder, err := ReadSignature(data, sigType)
+	sig, remainder, err := readSignature(data, sigType)
 	if err != nil {
 		log.WithError(err).Error("Failed to read Signature")
 		return nil, remainder, err
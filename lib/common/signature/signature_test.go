@@ -77,3 +77,17 @@ func TestNewSignature(t *testing.T) {
 	assert.Equal(*sig, Signature(data[:sigLength]), "signature should be sliced from data")
 	assert.Equal(rem, data[sigLength:], "remainder should be sliced from data ")
 }
+
+func TestParseSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	data := make([]byte, EdDSA_SHA512_Ed25519_SIZE+1)
+	sig, consumed, err := ParseSignature(data, SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	assert.Nil(err)
+	assert.Equal(EdDSA_SHA512_Ed25519_SIZE, consumed)
+	assert.Equal(Signature(data[:EdDSA_SHA512_Ed25519_SIZE]), sig)
+
+	_, consumed, err = ParseSignature(data[:EdDSA_SHA512_Ed25519_SIZE-1], SIGNATURE_TYPE_EDDSA_SHA512_ED25519)
+	assert.NotNil(err, "insufficient data error should be reported")
+	assert.Empty(data[:EdDSA_SHA512_Ed25519_SIZE-1][consumed:])
+}
@@ -0,0 +1,48 @@
+package config
+
+// a user allowed to connect to the client protocols
+type ClientUser struct {
+	// user name sent by the client
+	Name string
+	// bcrypt hash of the password
+	PasswordHash string
+	// may open I2CP sessions
+	I2CP bool
+	// may open SAM sessions
+	SAM bool
+	// may use the management API
+	Management bool
+}
+
+// I2CP, SAM and management API access configuration
+type ClientConfig struct {
+	// reject clients which do not log in as one of Users
+	AuthRequired bool
+	// addresses or CIDR ranges the I2CP, SAM and management API listeners
+	// may bind to, empty allows loopback only
+	AllowedBindAddresses []string
+	// users allowed to connect
+	Users []*ClientUser
+	// serve I2CP, SAM and the management API over TLS
+	TLS bool
+	// TLS certificate and key, a self-signed certificate is generated with
+	// the router's private keys if both are empty
+	TLSCertFile string
+	TLSKeyFile  string
+	// bandwidth limits of each client session in KBps, 0 leaves sessions
+	// limited by the router only
+	InboundLimit  int
+	OutboundLimit int
+}
+
+// default client access configuration, loopback only and no authentication
+var DefaultClientConfig = ClientConfig{
+	AuthRequired:         false,
+	AllowedBindAddresses: []string{},
+	Users:                []*ClientUser{},
+	TLS:                  false,
+	TLSCertFile:          "",
+	TLSKeyFile:           "",
+	InboundLimit:         0,
+	OutboundLimit:        0,
+}
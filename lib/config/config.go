@@ -33,15 +33,47 @@ func InitConfig() {
 
 			// Create default configuration
 			defaultConfig := struct {
-				BaseDir    string          `yaml:"base_dir"`
-				WorkingDir string          `yaml:"working_dir"`
-				NetDB      NetDbConfig     `yaml:"netdb"`
-				Bootstrap  BootstrapConfig `yaml:"bootstrap"`
+				BaseDir    string           `yaml:"base_dir"`
+				WorkingDir string           `yaml:"working_dir"`
+				Observer   bool             `yaml:"observer"`
+				NetDB      NetDbConfig      `yaml:"netdb"`
+				Bootstrap  BootstrapConfig  `yaml:"bootstrap"`
+				Workers    WorkerConfig     `yaml:"workers"`
+				Report     ReportConfig     `yaml:"report"`
+				Update     UpdateConfig     `yaml:"update"`
+				Peers      PeerConfig       `yaml:"peers"`
+				Versions   VersionConfig    `yaml:"versions"`
+				Clients    ClientConfig     `yaml:"clients"`
+				Management ManagementConfig `yaml:"management"`
+				Memory     MemoryConfig     `yaml:"memory"`
+				Tunnels    TunnelConfig     `yaml:"tunnels"`
+				Transport  TransportConfig  `yaml:"transport"`
+				Instances  InstanceConfig   `yaml:"instances"`
+				Research   ResearchConfig   `yaml:"research"`
+				Keychain   KeychainConfig   `yaml:"keychain"`
+				Storage    StorageConfig    `yaml:"storage"`
+				Features   map[string]bool  `yaml:"features"`
 			}{
 				BaseDir:    DefaultRouterConfig().BaseDir,
 				WorkingDir: DefaultRouterConfig().WorkingDir,
+				Observer:   DefaultRouterConfig().Observer,
 				NetDB:      *DefaultRouterConfig().NetDb,
 				Bootstrap:  *DefaultRouterConfig().Bootstrap,
+				Workers:    *DefaultRouterConfig().Workers,
+				Report:     *DefaultRouterConfig().Report,
+				Update:     *DefaultRouterConfig().Update,
+				Peers:      *DefaultRouterConfig().Peers,
+				Versions:   *DefaultRouterConfig().Versions,
+				Clients:    *DefaultRouterConfig().Clients,
+				Management: *DefaultRouterConfig().Management,
+				Memory:     *DefaultRouterConfig().Memory,
+				Tunnels:    *DefaultRouterConfig().Tunnels,
+				Transport:  *DefaultRouterConfig().Transport,
+				Instances:  *DefaultRouterConfig().Instances,
+				Research:   *DefaultRouterConfig().Research,
+				Keychain:   *DefaultRouterConfig().Keychain,
+				Storage:    *DefaultRouterConfig().Storage,
+				Features:   DefaultRouterConfig().Features,
 			}
 
 			yamlData, err := yaml.Marshal(defaultConfig)
@@ -80,23 +112,110 @@ func setDefaults() {
 	// Router defaults
 	viper.SetDefault("base_dir", DefaultRouterConfig().BaseDir)
 	viper.SetDefault("working_dir", DefaultRouterConfig().WorkingDir)
+	viper.SetDefault("observer", DefaultRouterConfig().Observer)
 
 	// NetDb defaults
 	viper.SetDefault("netdb.path", DefaultNetDbConfig.Path)
+	viper.SetDefault("netdb.floodfill", DefaultNetDbConfig.Floodfill)
+	viper.SetDefault("netdb.shared_path", DefaultNetDbConfig.SharedPath)
+	viper.SetDefault("netdb.publish_floodfills", DefaultNetDbConfig.PublishFloodfills)
+	viper.SetDefault("netdb.publish_quorum", DefaultNetDbConfig.PublishQuorum)
 
 	// Bootstrap defaults
 	viper.SetDefault("bootstrap.low_peer_threshold", DefaultBootstrapConfig.LowPeerThreshold)
 	viper.SetDefault("bootstrap.reseed_servers", []ReseedConfig{})
+
+	// Worker pool defaults
+	viper.SetDefault("workers.bandwidth_class", DefaultWorkersConfig.BandwidthClass)
+	viper.SetDefault("workers.netdb", 0)
+	viper.SetDefault("workers.tunnel_build", 0)
+
+	// Shutdown report defaults
+	viper.SetDefault("report.enabled", DefaultReportConfig.Enabled)
+	viper.SetDefault("report.endpoint", DefaultReportConfig.Endpoint)
+
+	// Router update defaults
+	viper.SetDefault("update.enabled", DefaultUpdateConfig.Enabled)
+	viper.SetDefault("update.url", DefaultUpdateConfig.URL)
+	viper.SetDefault("update.proxy", DefaultUpdateConfig.Proxy)
+	viper.SetDefault("update.check_interval", DefaultUpdateConfig.CheckInterval)
+
+	// Peer selection defaults
+	viper.SetDefault("peers.external_score_weight", DefaultPeerConfig.ExternalScoreWeight)
+
+	// Router version policy defaults
+	viper.SetDefault("versions.min", DefaultVersionConfig.Min)
+	viper.SetDefault("versions.max", DefaultVersionConfig.Max)
+	viper.SetDefault("versions.blocked", DefaultVersionConfig.Blocked)
+	viper.SetDefault("versions.zero_major_only", DefaultVersionConfig.ZeroMajorOnly)
+
+	// Client access defaults
+	viper.SetDefault("clients.auth_required", DefaultClientConfig.AuthRequired)
+	viper.SetDefault("clients.allowed_bind_addresses", DefaultClientConfig.AllowedBindAddresses)
+	viper.SetDefault("clients.users", []ClientUser{})
+	viper.SetDefault("clients.tls", DefaultClientConfig.TLS)
+	viper.SetDefault("clients.tls_cert_file", DefaultClientConfig.TLSCertFile)
+	viper.SetDefault("clients.tls_key_file", DefaultClientConfig.TLSKeyFile)
+	viper.SetDefault("clients.inbound_limit", DefaultClientConfig.InboundLimit)
+	viper.SetDefault("clients.outbound_limit", DefaultClientConfig.OutboundLimit)
+
+	// Memory accounting defaults
+	viper.SetDefault("memory.netdb", DefaultMemoryConfig.NetDb)
+	viper.SetDefault("memory.check_interval", DefaultMemoryConfig.CheckInterval)
+
+	// Client tunnel defaults
+	viper.SetDefault("tunnels.warm_pool", DefaultTunnelConfig.WarmPool)
+	viper.SetDefault("tunnels.lease_strategy", DefaultTunnelConfig.LeaseStrategy)
+	viper.SetDefault("tunnels.lease_stickiness", DefaultTunnelConfig.LeaseStickiness)
+	viper.SetDefault("tunnels.lease_grace", DefaultTunnelConfig.LeaseGrace)
+
+	// Transport defaults
+	viper.SetDefault("transport.port", DefaultTransportConfig.Port)
+	viper.SetDefault("transport.dscp", DefaultTransportConfig.DSCP)
+	viper.SetDefault("transport.reuse_port", DefaultTransportConfig.ReusePort)
+	viper.SetDefault("transport.interface", DefaultTransportConfig.Interface)
+
+	// Instance defaults
+	viper.SetDefault("instances.count", DefaultInstanceConfig.Count)
+	viper.SetDefault("instances.shared_netdb", DefaultInstanceConfig.SharedNetDb)
+
+	// Management API defaults
+	viper.SetDefault("management.enabled", DefaultManagementConfig.Enabled)
+	viper.SetDefault("management.address", DefaultManagementConfig.Address)
+	viper.SetDefault("management.debug_chaos", DefaultManagementConfig.DebugChaos)
+
+	// Research statistics defaults
+	viper.SetDefault("research.enabled", DefaultResearchConfig.Enabled)
+	viper.SetDefault("research.collector", DefaultResearchConfig.Collector)
+	viper.SetDefault("research.proxy", DefaultResearchConfig.Proxy)
+	viper.SetDefault("research.interval", DefaultResearchConfig.Interval)
+
+	// Keychain defaults
+	viper.SetDefault("keychain.enabled", DefaultKeychainConfig.Enabled)
+	viper.SetDefault("keychain.service", DefaultKeychainConfig.Service)
+
+	// Storage defaults
+	viper.SetDefault("storage.read_only", DefaultStorageConfig.ReadOnly)
+	viper.SetDefault("storage.state_dir", DefaultStorageConfig.StateDir)
+	viper.SetDefault("storage.strict_permissions", DefaultStorageConfig.StrictPermissions)
+
+	// Feature flag defaults
+	viper.SetDefault("features", DefaultFeatures)
 }
 
 func UpdateRouterConfig() {
 	// Update Router configuration
 	RouterConfigProperties.BaseDir = viper.GetString("base_dir")
 	RouterConfigProperties.WorkingDir = viper.GetString("working_dir")
+	RouterConfigProperties.Observer = viper.GetBool("observer")
 
 	// Update NetDb configuration
 	RouterConfigProperties.NetDb = &NetDbConfig{
-		Path: viper.GetString("netdb.path"),
+		Path:              viper.GetString("netdb.path"),
+		Floodfill:         viper.GetString("netdb.floodfill"),
+		SharedPath:        viper.GetString("netdb.shared_path"),
+		PublishFloodfills: viper.GetInt("netdb.publish_floodfills"),
+		PublishQuorum:     viper.GetInt("netdb.publish_quorum"),
 	}
 
 	// Update Bootstrap configuration
@@ -110,4 +229,117 @@ func UpdateRouterConfig() {
 		LowPeerThreshold: viper.GetInt("bootstrap.low_peer_threshold"),
 		ReseedServers:    reseedServers,
 	}
+
+	// Update worker pool configuration, sizes left at 0 follow the CPU count
+	workers := DefaultWorkerConfig(viper.GetString("workers.bandwidth_class"))
+	if n := viper.GetInt("workers.netdb"); n > 0 {
+		workers.NetDb = n
+	}
+	if n := viper.GetInt("workers.tunnel_build"); n > 0 {
+		workers.TunnelBuild = n
+	}
+	RouterConfigProperties.Workers = &workers
+
+	// Update shutdown report configuration
+	RouterConfigProperties.Report = &ReportConfig{
+		Enabled:  viper.GetBool("report.enabled"),
+		Endpoint: viper.GetString("report.endpoint"),
+	}
+
+	// Update router update configuration
+	RouterConfigProperties.Update = &UpdateConfig{
+		Enabled:       viper.GetBool("update.enabled"),
+		URL:           viper.GetString("update.url"),
+		Proxy:         viper.GetString("update.proxy"),
+		CheckInterval: viper.GetDuration("update.check_interval"),
+	}
+
+	// Update peer selection configuration
+	RouterConfigProperties.Peers = &PeerConfig{
+		ExternalScoreWeight: viper.GetFloat64("peers.external_score_weight"),
+	}
+
+	// Update router version policy
+	RouterConfigProperties.Versions = &VersionConfig{
+		Min:           viper.GetString("versions.min"),
+		Max:           viper.GetString("versions.max"),
+		Blocked:       viper.GetStringSlice("versions.blocked"),
+		ZeroMajorOnly: viper.GetBool("versions.zero_major_only"),
+	}
+
+	// Update client access configuration
+	var clientUsers []*ClientUser
+	if err := viper.UnmarshalKey("clients.users", &clientUsers); err != nil {
+		log.Warnf("Error parsing client users: %s", err)
+		clientUsers = []*ClientUser{}
+	}
+	RouterConfigProperties.Clients = &ClientConfig{
+		AuthRequired:         viper.GetBool("clients.auth_required"),
+		AllowedBindAddresses: viper.GetStringSlice("clients.allowed_bind_addresses"),
+		Users:                clientUsers,
+		TLS:                  viper.GetBool("clients.tls"),
+		TLSCertFile:          viper.GetString("clients.tls_cert_file"),
+		TLSKeyFile:           viper.GetString("clients.tls_key_file"),
+		InboundLimit:         viper.GetInt("clients.inbound_limit"),
+		OutboundLimit:        viper.GetInt("clients.outbound_limit"),
+	}
+
+	// Update management API configuration
+	RouterConfigProperties.Management = &ManagementConfig{
+		Enabled:    viper.GetBool("management.enabled"),
+		Address:    viper.GetString("management.address"),
+		DebugChaos: viper.GetBool("management.debug_chaos"),
+	}
+
+	// Update memory accounting configuration
+	RouterConfigProperties.Memory = &MemoryConfig{
+		NetDb:         viper.GetInt("memory.netdb"),
+		CheckInterval: viper.GetDuration("memory.check_interval"),
+	}
+
+	// Update client tunnel configuration
+	RouterConfigProperties.Tunnels = &TunnelConfig{
+		WarmPool:        viper.GetInt("tunnels.warm_pool"),
+		LeaseStrategy:   viper.GetString("tunnels.lease_strategy"),
+		LeaseStickiness: viper.GetDuration("tunnels.lease_stickiness"),
+		LeaseGrace:      viper.GetDuration("tunnels.lease_grace"),
+	}
+
+	// Update transport configuration
+	RouterConfigProperties.Transport = &TransportConfig{
+		Port:      viper.GetInt("transport.port"),
+		DSCP:      viper.GetInt("transport.dscp"),
+		ReusePort: viper.GetBool("transport.reuse_port"),
+		Interface: viper.GetString("transport.interface"),
+	}
+
+	// Update instance configuration
+	RouterConfigProperties.Instances = &InstanceConfig{
+		Count:       viper.GetInt("instances.count"),
+		SharedNetDb: viper.GetBool("instances.shared_netdb"),
+	}
+
+	// Update research statistics configuration
+	RouterConfigProperties.Research = &ResearchConfig{
+		Enabled:   viper.GetBool("research.enabled"),
+		Collector: viper.GetString("research.collector"),
+		Proxy:     viper.GetString("research.proxy"),
+		Interval:  viper.GetDuration("research.interval"),
+	}
+
+	// Update keychain configuration
+	RouterConfigProperties.Keychain = &KeychainConfig{
+		Enabled: viper.GetBool("keychain.enabled"),
+		Service: viper.GetString("keychain.service"),
+	}
+
+	// Update storage configuration
+	RouterConfigProperties.Storage = &StorageConfig{
+		ReadOnly:          viper.GetBool("storage.read_only"),
+		StateDir:          viper.GetString("storage.state_dir"),
+		StrictPermissions: viper.GetBool("storage.strict_permissions"),
+	}
+
+	// Update feature flags
+	RouterConfigProperties.Features = featuresFromViper()
 }
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"strconv"
+
+	"github.com/spf13/viper"
+)
+
+// default feature flag settings, empty so every feature keeps the default
+// of its definition
+var DefaultFeatures = map[string]bool{}
+
+// featuresFromViper reads the features section, where values are booleans
+// in the config file and strings when given with --features
+func featuresFromViper() map[string]bool {
+	features := make(map[string]bool)
+	for name, value := range viper.GetStringMap("features") {
+		switch v := value.(type) {
+		case bool:
+			features[name] = v
+		case string:
+			enabled, err := strconv.ParseBool(v)
+			if err != nil {
+				log.Warnf("Ignoring feature %s with invalid value %q", name, v)
+				continue
+			}
+			features[name] = enabled
+		default:
+			log.Warnf("Ignoring feature %s with invalid value %v", name, v)
+		}
+	}
+	return features
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+)
+
+// directory under WorkingDir holding the working directory of each instance
+const INSTANCES_DIR = "instances"
+
+// running several routers from one binary
+type InstanceConfig struct {
+	// routers to run, each in its own working directory under
+	// WorkingDir/instances and on its own transport port
+	Count int
+	// share the netDb at NetDb.Path between the instances, read only, each
+	// instance storing what it learns in a netDb of its own
+	SharedNetDb bool
+}
+
+// default settings run a single router
+var DefaultInstanceConfig = InstanceConfig{
+	Count:       1,
+	SharedNetDb: false,
+}
+
+// InstanceConfigs returns the configuration of each router to run. With a
+// single instance that is cfg itself.
+func (cfg *RouterConfig) InstanceConfigs() []*RouterConfig {
+	count := 1
+	if cfg.Instances != nil && cfg.Instances.Count > 1 {
+		count = cfg.Instances.Count
+	}
+	if count == 1 {
+		return []*RouterConfig{cfg}
+	}
+	configs := make([]*RouterConfig, count)
+	for i := range configs {
+		configs[i] = cfg.ForInstance(i)
+	}
+	return configs
+}
+
+// ForInstance returns the configuration of the instance with the given
+// index. It has its own working directory and netDb, and the transport and
+// management ports index above the configured ones, unless the transport
+// port is 0 for a random port.
+func (cfg *RouterConfig) ForInstance(index int) *RouterConfig {
+	instance := *cfg
+	instance.WorkingDir = filepath.Join(cfg.WorkingDir, INSTANCES_DIR, strconv.Itoa(index))
+
+	netDb := DefaultNetDbConfig
+	if cfg.NetDb != nil {
+		netDb = *cfg.NetDb
+	}
+	if cfg.Instances != nil && cfg.Instances.SharedNetDb {
+		netDb.SharedPath = netDb.Path
+	}
+	netDb.Path = filepath.Join(instance.WorkingDir, "netDb")
+	instance.NetDb = &netDb
+
+	transport := DefaultTransportConfig
+	if cfg.Transport != nil {
+		transport = *cfg.Transport
+	}
+	if transport.Port != 0 {
+		transport.Port += index
+	}
+	instance.Transport = &transport
+
+	if cfg.Management != nil {
+		management := *cfg.Management
+		if host, port, err := net.SplitHostPort(management.Address); err == nil {
+			if n, err := strconv.Atoi(port); err == nil && n != 0 {
+				management.Address = net.JoinHostPort(host, strconv.Itoa(n+index))
+			}
+		}
+		instance.Management = &management
+	}
+
+	if cfg.Storage != nil && cfg.Storage.StateDir != "" {
+		storage := *cfg.Storage
+		storage.StateDir = filepath.Join(storage.StateDir, INSTANCES_DIR, strconv.Itoa(index))
+		instance.Storage = &storage
+	}
+
+	instances := InstanceConfig{Count: 1}
+	instance.Instances = &instances
+	return &instance
+}
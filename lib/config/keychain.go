@@ -0,0 +1,17 @@
+package config
+
+// passphrases of private key files kept in the keychain of the operating
+// system
+type KeychainConfig struct {
+	// seal private key files with passphrases kept in the OS keychain, so
+	// no plaintext keys or passphrases are stored on disk
+	Enabled bool
+	// service the passphrases are stored under in the keychain
+	Service string
+}
+
+// default settings, storing keys unsealed
+var DefaultKeychainConfig = KeychainConfig{
+	Enabled: false,
+	Service: "go-i2p",
+}
@@ -0,0 +1,19 @@
+package config
+
+// management API configuration
+type ManagementConfig struct {
+	// serve the management API
+	Enabled bool
+	// host:port the management API listens on, bound like the client ports
+	Address string
+	// serve the fault injection debug API at /debug/chaos, never in
+	// production, and only if clients have to log in
+	DebugChaos bool
+}
+
+// default management API settings, disabled and on loopback when enabled
+var DefaultManagementConfig = ManagementConfig{
+	Enabled:    false,
+	Address:    "127.0.0.1:7651",
+	DebugChaos: false,
+}
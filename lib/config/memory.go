@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// memory accounting configuration
+type MemoryConfig struct {
+	// ceiling of the RouterInfos and LeaseSets held in memory in megabytes,
+	// 0 for none; past it the oldest RouterInfos are dropped from memory
+	NetDb int
+	// how often caches are checked against their ceilings
+	CheckInterval time.Duration
+}
+
+// default settings for memory accounting
+var DefaultMemoryConfig = MemoryConfig{
+	NetDb:         32,
+	CheckInterval: time.Minute,
+}
@@ -8,9 +8,23 @@ import (
 type NetDbConfig struct {
 	// path to network database directory
 	Path string
+	// floodfill mode: "auto" to volunteer when fast, reachable and stable,
+	// "true" or "false" to decide manually
+	Floodfill string
+	// path to a netDb read for entries missing from Path but never written,
+	// empty for none
+	SharedPath string
+	// how many of the floodfills closest to our LeaseSets they are stored
+	// to, and how many of them have to store them
+	PublishFloodfills int
+	PublishQuorum     int
 }
 
 // default settings for netdb
 var DefaultNetDbConfig = NetDbConfig{
-	Path: filepath.Join(defaultConfig(), "netDb"),
+	Path:              filepath.Join(defaultConfig(), "netDb"),
+	Floodfill:         "auto",
+	SharedPath:        "",
+	PublishFloodfills: 3,
+	PublishQuorum:     2,
 }
@@ -0,0 +1,13 @@
+package config
+
+// peer selection configuration
+type PeerConfig struct {
+	// weight of externally supplied peer scores against local profiles,
+	// from 0 (ignore them) to 1 (use only them)
+	ExternalScoreWeight float64
+}
+
+// default settings for peer selection
+var DefaultPeerConfig = PeerConfig{
+	ExternalScoreWeight: 0.25,
+}
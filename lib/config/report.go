@@ -0,0 +1,15 @@
+package config
+
+// shutdown report configuration
+type ReportConfig struct {
+	// write a JSON state snapshot to the working directory on shutdown
+	Enabled bool
+	// optional URL the shutdown report is POSTed to
+	Endpoint string
+}
+
+// default settings for shutdown reports
+var DefaultReportConfig = ReportConfig{
+	Enabled:  true,
+	Endpoint: "",
+}
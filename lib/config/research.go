@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// opt-in publication of anonymized statistics for network research
+type ResearchConfig struct {
+	// periodically publish signed, anonymized statistics
+	Enabled bool
+	// URL of the collector reports are posted to, normally a .b32.i2p
+	// address
+	Collector string
+	// HTTP proxy reports are posted through, normally the I2P HTTP proxy
+	Proxy string
+	// how often to publish
+	Interval time.Duration
+}
+
+// default settings, publishing nothing
+var DefaultResearchConfig = ResearchConfig{
+	Enabled:   false,
+	Collector: "",
+	Proxy:     "http://127.0.0.1:4444",
+	Interval:  24 * time.Hour,
+}
@@ -15,6 +15,38 @@ type RouterConfig struct {
 	NetDb *NetDbConfig
 	// configuration for bootstrapping into the network
 	Bootstrap *BootstrapConfig
+	// worker pool sizes
+	Workers *WorkerConfig
+	// shutdown report configuration
+	Report *ReportConfig
+	// router update configuration
+	Update *UpdateConfig
+	// peer selection configuration
+	Peers *PeerConfig
+	// router versions the netdb and peer selection accept
+	Versions *VersionConfig
+	// I2CP and SAM access configuration
+	Clients *ClientConfig
+	// management API configuration
+	Management *ManagementConfig
+	// memory accounting configuration
+	Memory *MemoryConfig
+	// client tunnel configuration
+	Tunnels *TunnelConfig
+	// socket options of the transports
+	Transport *TransportConfig
+	// routers run from this binary
+	Instances *InstanceConfig
+	// opt-in publication of statistics for network research
+	Research *ResearchConfig
+	// passphrases of private key files kept in the OS keychain
+	Keychain *KeychainConfig
+	// read only storage and file permissions
+	Storage *StorageConfig
+	// feature flags to enable or disable, by name
+	Features map[string]bool
+	// take part in the netdb only, building no tunnels and accepting no clients
+	Observer bool
 }
 
 func home() string {
@@ -37,6 +69,21 @@ func defaultConfig() string {
 var defaultRouterConfig = &RouterConfig{
 	NetDb:      &DefaultNetDbConfig,
 	Bootstrap:  &DefaultBootstrapConfig,
+	Workers:    &DefaultWorkersConfig,
+	Report:     &DefaultReportConfig,
+	Update:     &DefaultUpdateConfig,
+	Peers:      &DefaultPeerConfig,
+	Versions:   &DefaultVersionConfig,
+	Clients:    &DefaultClientConfig,
+	Management: &DefaultManagementConfig,
+	Memory:     &DefaultMemoryConfig,
+	Tunnels:    &DefaultTunnelConfig,
+	Transport:  &DefaultTransportConfig,
+	Instances:  &DefaultInstanceConfig,
+	Research:   &DefaultResearchConfig,
+	Keychain:   &DefaultKeychainConfig,
+	Storage:    &DefaultStorageConfig,
+	Features:   DefaultFeatures,
 	BaseDir:    defaultBase(),
 	WorkingDir: defaultConfig(),
 }
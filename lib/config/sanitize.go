@@ -0,0 +1,153 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// replaces secrets in a sanitized configuration
+const REDACTED = "REDACTED"
+
+// Sanitized returns a copy of the configuration fit to share in a bug
+// report: password hashes and credentials in URLs are redacted, and the
+// user's home directory in paths is replaced with ~. The configuration
+// itself is left unchanged.
+func (cfg *RouterConfig) Sanitized() *RouterConfig {
+	home, _ := os.UserHomeDir()
+	path := func(p string) string {
+		return sanitizePath(p, home)
+	}
+	sanitized := *cfg
+	sanitized.BaseDir = path(cfg.BaseDir)
+	sanitized.WorkingDir = path(cfg.WorkingDir)
+	if cfg.NetDb != nil {
+		netDb := *cfg.NetDb
+		netDb.Path = path(netDb.Path)
+		netDb.SharedPath = path(netDb.SharedPath)
+		sanitized.NetDb = &netDb
+	}
+	if cfg.Bootstrap != nil {
+		bootstrap := *cfg.Bootstrap
+		bootstrap.ReseedServers = make([]*ReseedConfig, len(cfg.Bootstrap.ReseedServers))
+		for i, server := range cfg.Bootstrap.ReseedServers {
+			reseed := *server
+			reseed.Url = sanitizeURL(reseed.Url)
+			bootstrap.ReseedServers[i] = &reseed
+		}
+		sanitized.Bootstrap = &bootstrap
+	}
+	if cfg.Report != nil {
+		report := *cfg.Report
+		report.Endpoint = sanitizeURL(report.Endpoint)
+		sanitized.Report = &report
+	}
+	if cfg.Update != nil {
+		update := *cfg.Update
+		update.URL = sanitizeURL(update.URL)
+		update.Proxy = sanitizeURL(update.Proxy)
+		sanitized.Update = &update
+	}
+	if cfg.Clients != nil {
+		clients := *cfg.Clients
+		clients.TLSCertFile = path(clients.TLSCertFile)
+		clients.TLSKeyFile = path(clients.TLSKeyFile)
+		clients.Users = make([]*ClientUser, len(cfg.Clients.Users))
+		for i, user := range cfg.Clients.Users {
+			redacted := *user
+			if redacted.PasswordHash != "" {
+				redacted.PasswordHash = REDACTED
+			}
+			clients.Users[i] = &redacted
+		}
+		sanitized.Clients = &clients
+	}
+	if cfg.Storage != nil {
+		storage := *cfg.Storage
+		storage.StateDir = path(storage.StateDir)
+		sanitized.Storage = &storage
+	}
+	if cfg.Research != nil {
+		research := *cfg.Research
+		research.Collector = sanitizeURL(research.Collector)
+		research.Proxy = sanitizeURL(research.Proxy)
+		sanitized.Research = &research
+	}
+	return &sanitized
+}
+
+// sanitizeURL redacts the user info and query of a URL, which may hold
+// credentials
+func sanitizeURL(s string) string {
+	if s == "" {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return REDACTED
+	}
+	if u.User != nil {
+		u.User = url.User(REDACTED)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = REDACTED
+	}
+	return u.String()
+}
+
+// SanitizePath replaces the user's home directory at the start of p with ~,
+// as Sanitized does for the paths in the configuration.
+func SanitizePath(p string) string {
+	home, _ := os.UserHomeDir()
+	return sanitizePath(p, home)
+}
+
+// SanitizeText replaces the user's home directory in the paths found in s,
+// such as the path fields of log lines, with ~.
+func SanitizeText(s string) string {
+	home, _ := os.UserHomeDir()
+	return sanitizeText(s, home)
+}
+
+// bytes which may follow a path component in text
+const pathEnds = "/\\\"' \t\n"
+
+// sanitizeText replaces every occurrence of home in s which ends a path
+// component with ~
+func sanitizeText(s, home string) string {
+	if home == "" {
+		return s
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, home)
+		if i < 0 {
+			break
+		}
+		end := i + len(home)
+		if end == len(s) || strings.IndexByte(pathEnds, s[end]) >= 0 {
+			b.WriteString(s[:i])
+			b.WriteString("~")
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// sanitizePath replaces the home directory at the start of p with ~
+func sanitizePath(p, home string) string {
+	if home == "" || p == "" {
+		return p
+	}
+	if p == home {
+		return "~"
+	}
+	if strings.HasPrefix(p, home+string(filepath.Separator)) {
+		return "~" + p[len(home):]
+	}
+	return p
+}
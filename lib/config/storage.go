@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// where the router keeps its files
+type StorageConfig struct {
+	// treat the working directory, its keys and the netDb as read only, as
+	// in an immutable container image
+	ReadOnly bool
+	// writable directory volatile state is kept in when read only: the
+	// RouterInfos learned while running, reports and keys missing from the
+	// working directory
+	StateDir string
+	// restrict private key files readable by other users to 0600, instead
+	// of only warning about them
+	StrictPermissions bool
+}
+
+// default settings, keeping everything in the working directory
+var DefaultStorageConfig = StorageConfig{
+	ReadOnly:          false,
+	StateDir:          "",
+	StrictPermissions: true,
+}
+
+var ErrNoStateDir = errors.New("read only storage requires a state directory")
+
+// ReadOnly reports whether the working directory and netDb are read only.
+func (cfg *RouterConfig) ReadOnly() bool {
+	return cfg.Storage != nil && cfg.Storage.ReadOnly
+}
+
+// StateDir returns the directory the router writes its state to, the state
+// directory when read only and the working directory otherwise.
+func (cfg *RouterConfig) StateDir() string {
+	if cfg.ReadOnly() {
+		return cfg.Storage.StateDir
+	}
+	return cfg.WorkingDir
+}
+
+// StatePath returns the path of the state file name.
+func (cfg *RouterConfig) StatePath(name string) string {
+	return filepath.Join(cfg.StateDir(), name)
+}
+
+// KeyPath returns the path of the private key file name: in the working
+// directory, unless it is read only and the key is missing there, then in
+// the state directory, where it can be generated.
+func (cfg *RouterConfig) KeyPath(name string) string {
+	path := filepath.Join(cfg.WorkingDir, name)
+	if !cfg.ReadOnly() {
+		return path
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return cfg.StatePath(name)
+}
+
+// NetDbPaths returns the netDb the router writes and the shared one it
+// reads RouterInfos missing from it from. When read only the configured
+// netDb is read as the shared one, and a netDb in the state directory is
+// written instead.
+func (cfg *RouterConfig) NetDbPaths() (path, shared string) {
+	netDb := DefaultNetDbConfig
+	if cfg.NetDb != nil {
+		netDb = *cfg.NetDb
+	}
+	if !cfg.ReadOnly() {
+		return netDb.Path, netDb.SharedPath
+	}
+	return cfg.StatePath("netDb"), netDb.Path
+}
+
+// CheckStorage checks the storage configuration is usable.
+func (cfg *RouterConfig) CheckStorage() error {
+	if cfg.ReadOnly() && cfg.Storage.StateDir == "" {
+		return ErrNoStateDir
+	}
+	return nil
+}
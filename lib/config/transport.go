@@ -0,0 +1,22 @@
+package config
+
+// transport listener and socket configuration
+type TransportConfig struct {
+	// port the transports listen on, 0 for a random port
+	Port int
+	// DSCP code point marked on outgoing packets, 0 for none
+	DSCP int
+	// set SO_REUSEPORT so several routers can share a port
+	ReusePort bool
+	// network interface to bind sockets to, empty for any
+	Interface string
+}
+
+// default transport settings, a random port and sockets left as the system
+// makes them
+var DefaultTransportConfig = TransportConfig{
+	Port:      0,
+	DSCP:      0,
+	ReusePort: false,
+	Interface: "",
+}
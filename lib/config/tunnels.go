@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// client tunnel configuration
+type TunnelConfig struct {
+	// outbound client tunnels built at startup and shared by new sessions
+	// until their own tunnels are built, 0 to build none
+	WarmPool int
+	// how the lease of a remote LeaseSet is chosen for outbound messages:
+	// random, latency or round-robin
+	LeaseStrategy string
+	// how long a remote destination keeps the lease chosen for it, 0 to
+	// choose for every message
+	LeaseStickiness time.Duration
+	// how long past their end date leases of a remote destination are
+	// still used when it has no unexpired lease, tolerating clock skew
+	LeaseGrace time.Duration
+}
+
+// default settings for client tunnels
+var DefaultTunnelConfig = TunnelConfig{
+	WarmPool:        0,
+	LeaseStrategy:   "random",
+	LeaseStickiness: time.Minute,
+	LeaseGrace:      time.Minute,
+}
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// router update configuration
+type UpdateConfig struct {
+	// periodically check for and stage signed router updates
+	Enabled bool
+	// URL of the release su3 file
+	URL string
+	// HTTP proxy updates are fetched through, normally the I2P HTTP proxy
+	Proxy string
+	// how often to check for updates
+	CheckInterval time.Duration
+}
+
+// default settings for router updates
+var DefaultUpdateConfig = UpdateConfig{
+	Enabled:       false,
+	URL:           "",
+	Proxy:         "http://127.0.0.1:4444",
+	CheckInterval: 24 * time.Hour,
+}
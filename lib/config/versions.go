@@ -0,0 +1,22 @@
+package config
+
+// router version policy, which router.version values the netdb stores and
+// peer selection uses
+type VersionConfig struct {
+	// oldest accepted version, empty to accept any
+	Min string
+	// newest accepted version, empty for no upper bound
+	Max string
+	// versions rejected even if they are in range
+	Blocked []string
+	// reject versions which do not start with 0
+	ZeroMajorOnly bool
+}
+
+// default version policy, the same as router_info.DefaultVersionPolicy
+var DefaultVersionConfig = VersionConfig{
+	Min:           "0.9.58",
+	Max:           "",
+	Blocked:       []string{},
+	ZeroMajorOnly: false,
+}
@@ -0,0 +1,41 @@
+package config
+
+import "runtime"
+
+// worker pool sizing
+type WorkerConfig struct {
+	// bandwidth class of the router (K, L, M, N, O, P or X)
+	BandwidthClass string
+	// number of workers reading the netdb and storing LeaseSets to floodfills
+	NetDb int
+	// number of workers building tunnels
+	TunnelBuild int
+}
+
+// bandwidthMultiplier scales worker counts with the share bandwidth class
+func bandwidthMultiplier(class string) int {
+	switch class {
+	case "M", "N":
+		return 2
+	case "O":
+		return 3
+	case "P", "X":
+		return 4
+	default:
+		return 1
+	}
+}
+
+// DefaultWorkerConfig sizes the worker pools from GOMAXPROCS and the bandwidth class
+func DefaultWorkerConfig(bandwidthClass string) WorkerConfig {
+	procs := runtime.GOMAXPROCS(0)
+	multiplier := bandwidthMultiplier(bandwidthClass)
+	return WorkerConfig{
+		BandwidthClass: bandwidthClass,
+		NetDb:          max(1, procs/2) * multiplier,
+		TunnelBuild:    max(1, procs/4) * multiplier,
+	}
+}
+
+// default settings for worker pools
+var DefaultWorkersConfig = DefaultWorkerConfig("L")
@@ -28,7 +28,7 @@ type AESSymmetricEncrypter struct {
 func (e *AESSymmetricEncrypter) Encrypt(data []byte) ([]byte, error) {
 	log.WithField("data_length", len(data)).Debug("Encrypting data")
 
-	block, err := aes.NewCipher(e.Key)
+	block, err := NewAESCipher(e.Key)
 	if err != nil {
 		log.WithError(err).Error("Failed to create AES cipher")
 		return nil, err
@@ -53,7 +53,7 @@ type AESSymmetricDecrypter struct {
 func (d *AESSymmetricDecrypter) Decrypt(data []byte) ([]byte, error) {
 	log.WithField("data_length", len(data)).Debug("Decrypting data")
 
-	block, err := aes.NewCipher(d.Key)
+	block, err := NewAESCipher(d.Key)
 	if err != nil {
 		log.WithError(err).Error("Failed to create AES cipher")
 		return nil, err
@@ -143,10 +143,10 @@ func pkcs7Unpad(data []byte) ([]byte, error) {
 // EncryptNoPadding encrypts data using AES-CBC without padding
 func (e *AESSymmetricEncrypter) EncryptNoPadding(data []byte) ([]byte, error) {
 	if len(data)%aes.BlockSize != 0 {
-		return nil, fmt.Errorf("data length must be a multiple of block size")
+		return nil, ErrUnalignedData
 	}
 
-	block, err := aes.NewCipher(e.Key)
+	block, err := NewAESCipher(e.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -161,10 +161,10 @@ func (e *AESSymmetricEncrypter) EncryptNoPadding(data []byte) ([]byte, error) {
 // DecryptNoPadding decrypts data using AES-CBC without padding
 func (d *AESSymmetricDecrypter) DecryptNoPadding(data []byte) ([]byte, error) {
 	if len(data)%aes.BlockSize != 0 {
-		return nil, fmt.Errorf("data length must be a multiple of block size")
+		return nil, ErrUnalignedData
 	}
 
-	block, err := aes.NewCipher(d.Key)
+	block, err := NewAESCipher(d.Key)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+// Sizes of AES-256-CBC as tunnel layers and ElGamal/AES+SessionTag use it
+const (
+	AES256_KEY_SIZE = 32
+	AES_BLOCK_SIZE  = aes.BlockSize
+)
+
+var (
+	ErrInvalidAES256KeySize = errors.New("AES-256 key must be 32 bytes")
+	ErrInvalidIVSize        = errors.New("AES-CBC IV must be 16 bytes")
+	ErrUnalignedData        = errors.New("AES-CBC data must be a multiple of 16 bytes")
+	ErrShortBuffer          = errors.New("AES-CBC destination shorter than source")
+)
+
+// CBCCipher is AES-256-CBC without padding. I2P pads its messages itself
+// before encryption, so the data must be a multiple of AES_BLOCK_SIZE.
+// dst and src may be the same slice, encrypting in place.
+//
+// NewCBCCipher returns an implementation using the current Backend; other
+// implementations, such as ones offloading to a hardware engine, can be
+// used wherever a CBCCipher is taken.
+type CBCCipher interface {
+	EncryptBlocks(dst, src, iv []byte) error
+	DecryptBlocks(dst, src, iv []byte) error
+}
+
+// backendCBC is a CBCCipher over a block cipher of the current Backend
+type backendCBC struct {
+	block cipher.Block
+}
+
+// NewCBCCipher returns AES-256-CBC for a 32 byte key.
+func NewCBCCipher(key []byte) (CBCCipher, error) {
+	if len(key) != AES256_KEY_SIZE {
+		return nil, ErrInvalidAES256KeySize
+	}
+	block, err := NewAESCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return backendCBC{block: block}, nil
+}
+
+// checkCBC validates the arguments of EncryptBlocks and DecryptBlocks
+func checkCBC(dst, src, iv []byte) error {
+	switch {
+	case len(iv) != AES_BLOCK_SIZE:
+		return ErrInvalidIVSize
+	case len(src)%AES_BLOCK_SIZE != 0:
+		return ErrUnalignedData
+	case len(dst) < len(src):
+		return ErrShortBuffer
+	}
+	return nil
+}
+
+// EncryptBlocks implements CBCCipher.
+func (c backendCBC) EncryptBlocks(dst, src, iv []byte) error {
+	if err := checkCBC(dst, src, iv); err != nil {
+		return err
+	}
+	cipher.NewCBCEncrypter(c.block, iv).CryptBlocks(dst[:len(src)], src)
+	return nil
+}
+
+// DecryptBlocks implements CBCCipher.
+func (c backendCBC) DecryptBlocks(dst, src, iv []byte) error {
+	if err := checkCBC(dst, src, iv); err != nil {
+		return err
+	}
+	cipher.NewCBCDecrypter(c.block, iv).CryptBlocks(dst[:len(src)], src)
+	return nil
+}
+
+// AES256CBCEncrypt returns data encrypted with AES-256-CBC, without padding.
+func AES256CBCEncrypt(key, iv, data []byte) ([]byte, error) {
+	c, err := NewCBCCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(data))
+	if err := c.EncryptBlocks(ciphertext, data, iv); err != nil {
+		return nil, err
+	}
+	return ciphertext, nil
+}
+
+// AES256CBCDecrypt returns data decrypted with AES-256-CBC, without padding.
+func AES256CBCDecrypt(key, iv, data []byte) ([]byte, error) {
+	c, err := NewCBCCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext := make([]byte, len(data))
+	if err := c.DecryptBlocks(plaintext, data, iv); err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,83 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// NIST SP 800-38A F.2.5
+func TestAES256CBCVector(t *testing.T) {
+	key, _ := hex.DecodeString("603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4")
+	iv, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	plaintext, _ := hex.DecodeString("6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51" +
+		"30c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	expected, _ := hex.DecodeString("f58c4c04d6e5f1ba779eabfb5f7bfbd69cfc4e967edb808d679f777bc6702c7d" +
+		"39f23369a9d9bacfa530e26304231461b2eb05e2c39be9fcda6c19078c6a9d1b")
+
+	ciphertext, err := AES256CBCEncrypt(key, iv, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, expected, ciphertext)
+
+	decrypted, err := AES256CBCDecrypt(key, iv, ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
+
+func TestCBCCipherInPlace(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, AES256_KEY_SIZE)
+	iv := bytes.Repeat([]byte{2}, AES_BLOCK_SIZE)
+	plaintext := bytes.Repeat([]byte("sixteen byte blk"), 4)
+	c, err := NewCBCCipher(key)
+	require.NoError(t, err)
+
+	data := append([]byte(nil), plaintext...)
+	require.NoError(t, c.EncryptBlocks(data, data, iv))
+	assert.NotEqual(t, plaintext, data)
+	expected, err := AES256CBCEncrypt(key, iv, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, expected, data)
+
+	require.NoError(t, c.DecryptBlocks(data, data, iv))
+	assert.Equal(t, plaintext, data)
+	assert.Equal(t, bytes.Repeat([]byte{2}, AES_BLOCK_SIZE), iv, "the IV is not modified")
+}
+
+func TestCBCCipherErrors(t *testing.T) {
+	_, err := NewCBCCipher(make([]byte, 16))
+	assert.ErrorIs(t, err, ErrInvalidAES256KeySize)
+
+	c, err := NewCBCCipher(make([]byte, AES256_KEY_SIZE))
+	require.NoError(t, err)
+	iv := make([]byte, AES_BLOCK_SIZE)
+	assert.ErrorIs(t, c.EncryptBlocks(make([]byte, 32), make([]byte, 32), iv[:8]), ErrInvalidIVSize)
+	assert.ErrorIs(t, c.EncryptBlocks(make([]byte, 32), make([]byte, 31), iv), ErrUnalignedData)
+	assert.ErrorIs(t, c.DecryptBlocks(make([]byte, 17), make([]byte, 17), iv), ErrUnalignedData)
+	assert.ErrorIs(t, c.DecryptBlocks(make([]byte, 16), make([]byte, 32), iv), ErrShortBuffer)
+
+	_, err = AES256CBCEncrypt(make([]byte, AES256_KEY_SIZE), iv, make([]byte, 15))
+	assert.ErrorIs(t, err, ErrUnalignedData)
+}
+
+func TestTunnelLayerRoundTrip(t *testing.T) {
+	var layerKey, ivKey TunnelKey
+	layerKey[0], ivKey[0] = 1, 2
+	hop, err := NewTunnelCrypto(layerKey, ivKey)
+	require.NoError(t, err)
+
+	var original TunnelData
+	for i := range original {
+		original[i] = byte(i)
+	}
+	td := original
+	require.NoError(t, hop.Encrypt(&td))
+	assert.Equal(t, original[:tunnelIVStart], td[:tunnelIVStart], "the tunnel ID is not encrypted")
+	assert.NotEqual(t, original[tunnelIVStart:tunnelDataStart], td[tunnelIVStart:tunnelDataStart])
+	assert.NotEqual(t, original[tunnelDataStart:], td[tunnelDataStart:])
+
+	require.NoError(t, hop.Decrypt(&td))
+	assert.Equal(t, original, td)
+}
@@ -0,0 +1,102 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Backend implements the primitives on the hot paths of the router: AES for
+// tunnel layers and NTCP2, SHA256 for hashing. The pure Go backend is always
+// available and builds for every GOOS and GOARCH; accelerated backends are
+// compiled in by build tags, see backend_openssl.go.
+type Backend interface {
+	// Name identifies the backend to UseBackend
+	Name() string
+	// NewAESCipher returns an AES block cipher for a 16, 24 or 32 byte key.
+	// A backend may return a block which also implements
+	// NewCBCEncrypter(iv) and NewCBCDecrypter(iv) for bulk CBC, which
+	// cipher.NewCBCEncrypter and cipher.NewCBCDecrypter use when present.
+	NewAESCipher(key []byte) (cipher.Block, error)
+	// SHA256 returns the SHA256 sum of data
+	SHA256(data []byte) [32]byte
+}
+
+// GO_BACKEND is the name of the pure Go backend.
+const GO_BACKEND = "go"
+
+var ErrUnknownBackend = errors.New("unknown crypto backend")
+
+var (
+	backendsMutex sync.Mutex
+	backends      = make(map[string]Backend)
+	current       atomic.Pointer[Backend]
+)
+
+// RegisterBackend makes a backend available to UseBackend. Backends compiled
+// in by build tags register themselves in init.
+func RegisterBackend(backend Backend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	backends[backend.Name()] = backend
+}
+
+// Backends returns the names of the available backends, sorted.
+func Backends() []string {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UseBackend switches the backend used from now on. Ciphers already created
+// keep the backend which created them.
+func UseBackend(name string) error {
+	backendsMutex.Lock()
+	backend, ok := backends[name]
+	backendsMutex.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %q, available %v", ErrUnknownBackend, name, Backends())
+	}
+	current.Store(&backend)
+	log.WithFields(logrus.Fields{
+		"backend": name,
+	}).Info("Selected crypto backend")
+	return nil
+}
+
+// CurrentBackend returns the backend in use.
+func CurrentBackend() Backend {
+	return *current.Load()
+}
+
+// NewAESCipher returns an AES block cipher from the current backend.
+func NewAESCipher(key []byte) (cipher.Block, error) {
+	return CurrentBackend().NewAESCipher(key)
+}
+
+// SHA256 returns the SHA256 sum of data from the current backend.
+func SHA256(data []byte) [32]byte {
+	return CurrentBackend().SHA256(data)
+}
+
+func init() {
+	RegisterBackend(goBackend{})
+	for _, backend := range taggedBackends() {
+		RegisterBackend(backend)
+	}
+	backend := backends[defaultBackend]
+	if backend == nil {
+		backend = goBackend{}
+	}
+	current.Store(&backend)
+}
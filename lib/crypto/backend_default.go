@@ -0,0 +1,11 @@
+//go:build !openssl || !cgo
+
+package crypto
+
+// defaultBackend is the backend selected at startup
+const defaultBackend = GO_BACKEND
+
+// taggedBackends returns the backends compiled in by build tags
+func taggedBackends() []Backend {
+	return nil
+}
@@ -0,0 +1,23 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+)
+
+// goBackend is the standard library, which uses assembly where Go has it
+// for the target and portable Go everywhere else
+type goBackend struct{}
+
+func (goBackend) Name() string {
+	return GO_BACKEND
+}
+
+func (goBackend) NewAESCipher(key []byte) (cipher.Block, error) {
+	return aes.NewCipher(key)
+}
+
+func (goBackend) SHA256(data []byte) [32]byte {
+	return sha256.Sum256(data)
+}
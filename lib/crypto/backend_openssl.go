@@ -0,0 +1,196 @@
+//go:build openssl && cgo
+
+package crypto
+
+/*
+#cgo LDFLAGS: -lcrypto
+#include <openssl/evp.h>
+#include <openssl/sha.h>
+
+static const EVP_CIPHER *aes_ecb(int size) {
+	switch (size) {
+	case 16: return EVP_aes_128_ecb();
+	case 24: return EVP_aes_192_ecb();
+	default: return EVP_aes_256_ecb();
+	}
+}
+
+static const EVP_CIPHER *aes_cbc(int size) {
+	switch (size) {
+	case 16: return EVP_aes_128_cbc();
+	case 24: return EVP_aes_192_cbc();
+	default: return EVP_aes_256_cbc();
+	}
+}
+
+static EVP_CIPHER_CTX *aes_ctx(const EVP_CIPHER *cipher, const unsigned char *key, const unsigned char *iv, int encrypt) {
+	EVP_CIPHER_CTX *ctx = EVP_CIPHER_CTX_new();
+	if (ctx == NULL) {
+		return NULL;
+	}
+	if (EVP_CipherInit_ex(ctx, cipher, NULL, key, iv, encrypt) != 1) {
+		EVP_CIPHER_CTX_free(ctx);
+		return NULL;
+	}
+	EVP_CIPHER_CTX_set_padding(ctx, 0);
+	return ctx;
+}
+
+static int aes_update(EVP_CIPHER_CTX *ctx, unsigned char *dst, const unsigned char *src, int len) {
+	int out = 0;
+	return EVP_CipherUpdate(ctx, dst, &out, src, len) == 1 && out == len;
+}
+*/
+import "C"
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"runtime"
+	"sync"
+	"unsafe"
+)
+
+// OPENSSL_BACKEND is the name of the libcrypto backend, compiled in with the
+// openssl build tag and selected by default when it is.
+const OPENSSL_BACKEND = "openssl"
+
+const defaultBackend = OPENSSL_BACKEND
+
+func taggedBackends() []Backend {
+	return []Backend{opensslBackend{}}
+}
+
+// opensslBackend hands bulk AES-CBC and hashing to libcrypto, for targets
+// where Go has no assembly for them
+type opensslBackend struct{}
+
+func (opensslBackend) Name() string {
+	return OPENSSL_BACKEND
+}
+
+func (opensslBackend) NewAESCipher(key []byte) (cipher.Block, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, aes.KeySizeError(len(key))
+	}
+	block := &opensslAES{key: append([]byte(nil), key...)}
+	block.encrypt = newEVPCtx(C.aes_ecb(C.int(len(key))), block.key, nil, true)
+	block.decrypt = newEVPCtx(C.aes_ecb(C.int(len(key))), block.key, nil, false)
+	return block, nil
+}
+
+func (opensslBackend) SHA256(data []byte) (sum [32]byte) {
+	C.SHA256((*C.uchar)(bytesPointer(data)), C.size_t(len(data)), (*C.uchar)(unsafe.Pointer(&sum[0])))
+	return
+}
+
+// evpCtx is an EVP_CIPHER_CTX freed with the Go value owning it
+type evpCtx struct {
+	ctx *C.EVP_CIPHER_CTX
+}
+
+func newEVPCtx(evpCipher *C.EVP_CIPHER, key, iv []byte, encrypt bool) *evpCtx {
+	enc := C.int(0)
+	if encrypt {
+		enc = 1
+	}
+	var ivPointer *C.uchar
+	if iv != nil {
+		ivPointer = (*C.uchar)(unsafe.Pointer(&iv[0]))
+	}
+	ctx := C.aes_ctx(evpCipher, (*C.uchar)(unsafe.Pointer(&key[0])), ivPointer, enc)
+	if ctx == nil {
+		panic("crypto: libcrypto failed to create an AES context")
+	}
+	wrapped := &evpCtx{ctx: ctx}
+	runtime.SetFinalizer(wrapped, func(c *evpCtx) {
+		C.EVP_CIPHER_CTX_free(c.ctx)
+	})
+	return wrapped
+}
+
+func (c *evpCtx) update(dst, src []byte) {
+	if len(src) == 0 {
+		return
+	}
+	if len(dst) < len(src) {
+		panic("crypto: output smaller than input")
+	}
+	ok := C.aes_update(c.ctx, (*C.uchar)(unsafe.Pointer(&dst[0])), (*C.uchar)(unsafe.Pointer(&src[0])), C.int(len(src)))
+	runtime.KeepAlive(c)
+	if ok != 1 {
+		panic("crypto: libcrypto AES update failed")
+	}
+}
+
+// opensslAES is an AES cipher.Block whose CBC modes run in libcrypto.
+// Single blocks go through one EVP_CIPHER_CTX per direction, which libcrypto
+// does not allow to be used concurrently, so Encrypt and Decrypt hold mutex.
+// Like a crypto/aes Block, it is safe for concurrent use.
+type opensslAES struct {
+	key     []byte
+	mutex   sync.Mutex
+	encrypt *evpCtx
+	decrypt *evpCtx
+}
+
+func (block *opensslAES) BlockSize() int {
+	return aes.BlockSize
+}
+
+func (block *opensslAES) Encrypt(dst, src []byte) {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+	block.encrypt.update(dst[:aes.BlockSize], src[:aes.BlockSize])
+}
+
+func (block *opensslAES) Decrypt(dst, src []byte) {
+	block.mutex.Lock()
+	defer block.mutex.Unlock()
+	block.decrypt.update(dst[:aes.BlockSize], src[:aes.BlockSize])
+}
+
+// NewCBCEncrypter is used by cipher.NewCBCEncrypter.
+func (block *opensslAES) NewCBCEncrypter(iv []byte) cipher.BlockMode {
+	return block.newCBC(iv, true)
+}
+
+// NewCBCDecrypter is used by cipher.NewCBCDecrypter.
+func (block *opensslAES) NewCBCDecrypter(iv []byte) cipher.BlockMode {
+	return block.newCBC(iv, false)
+}
+
+func (block *opensslAES) newCBC(iv []byte, encrypt bool) cipher.BlockMode {
+	if len(iv) != aes.BlockSize {
+		panic("cipher.NewCBC: IV length must equal block size")
+	}
+	return &opensslCBC{ctx: newEVPCtx(C.aes_cbc(C.int(len(block.key))), block.key, iv, encrypt)}
+}
+
+// opensslCBC is an AES-CBC cipher.BlockMode keeping its chaining state in
+// libcrypto between calls
+type opensslCBC struct {
+	ctx *evpCtx
+}
+
+func (mode *opensslCBC) BlockSize() int {
+	return aes.BlockSize
+}
+
+func (mode *opensslCBC) CryptBlocks(dst, src []byte) {
+	if len(src)%aes.BlockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	mode.ctx.update(dst, src)
+}
+
+// bytesPointer returns a pointer libcrypto accepts for data, also when empty
+func bytesPointer(data []byte) unsafe.Pointer {
+	if len(data) == 0 {
+		var empty [1]byte
+		return unsafe.Pointer(&empty[0])
+	}
+	return unsafe.Pointer(&data[0])
+}
@@ -0,0 +1,111 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBackendsAgree checks every compiled in backend against the standard
+// library; run it with -tags openssl to cover the libcrypto backend.
+func TestBackendsAgree(t *testing.T) {
+	data := bytes.Repeat([]byte("go-i2p backend "), 100)[:1024]
+	iv := bytes.Repeat([]byte{7}, aes.BlockSize)
+	for _, name := range Backends() {
+		backend := backends[name]
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, sha256.Sum256(data), backend.SHA256(data))
+			assert.Equal(t, sha256.Sum256(nil), backend.SHA256(nil))
+
+			for _, size := range []int{16, 24, 32} {
+				key := bytes.Repeat([]byte{byte(size)}, size)
+				want, err := aes.NewCipher(key)
+				require.NoError(t, err)
+				got, err := backend.NewAESCipher(key)
+				require.NoError(t, err)
+
+				block, expected := make([]byte, aes.BlockSize), make([]byte, aes.BlockSize)
+				got.Encrypt(block, data)
+				want.Encrypt(expected, data)
+				assert.Equal(t, expected, block)
+				got.Decrypt(block, block)
+				assert.Equal(t, data[:aes.BlockSize], block)
+
+				// CBC chaining carries over between calls
+				expected = make([]byte, len(data))
+				cipher.NewCBCEncrypter(want, iv).CryptBlocks(expected, data)
+				encrypted := make([]byte, len(data))
+				mode := cipher.NewCBCEncrypter(got, iv)
+				mode.CryptBlocks(encrypted[:512], data[:512])
+				mode.CryptBlocks(encrypted[512:], data[512:])
+				assert.Equal(t, expected, encrypted)
+
+				decrypted := make([]byte, len(data))
+				cipher.NewCBCDecrypter(got, iv).CryptBlocks(decrypted, encrypted)
+				assert.Equal(t, data, decrypted)
+			}
+
+			_, err := backend.NewAESCipher(make([]byte, 10))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestBackendBlockConcurrent uses one Block of every backend from several
+// goroutines at once, as crypto/aes Blocks may be
+func TestBackendBlockConcurrent(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	want, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	for _, name := range Backends() {
+		got, err := backends[name].NewAESCipher(key)
+		require.NoError(t, err)
+		var wg sync.WaitGroup
+		errs := make(chan string, 8)
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g byte) {
+				defer wg.Done()
+				src := bytes.Repeat([]byte{g}, aes.BlockSize)
+				expected := make([]byte, aes.BlockSize)
+				want.Encrypt(expected, src)
+				block := make([]byte, aes.BlockSize)
+				for i := 0; i < 1000; i++ {
+					got.Encrypt(block, src)
+					if !bytes.Equal(expected, block) {
+						errs <- "encrypt"
+						return
+					}
+					got.Decrypt(block, block)
+					if !bytes.Equal(src, block) {
+						errs <- "decrypt"
+						return
+					}
+				}
+			}(byte(g))
+		}
+		wg.Wait()
+		close(errs)
+		for op := range errs {
+			t.Errorf("%s: concurrent %s gave a wrong block", name, op)
+		}
+	}
+}
+
+func TestUseBackend(t *testing.T) {
+	previous := CurrentBackend().Name()
+	t.Cleanup(func() { require.NoError(t, UseBackend(previous)) })
+
+	assert.Contains(t, Backends(), GO_BACKEND)
+	assert.Equal(t, defaultBackend, previous)
+	require.NoError(t, UseBackend(GO_BACKEND))
+	assert.Equal(t, GO_BACKEND, CurrentBackend().Name())
+	assert.ErrorIs(t, UseBackend("quantum"), ErrUnknownBackend)
+	assert.Equal(t, GO_BACKEND, CurrentBackend().Name())
+}
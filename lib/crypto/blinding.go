@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"filippo.io/edwards25519"
+)
+
+/*
+Key blinding of encrypted LeaseSet2s, proposal 123. An Ed25519 or RedDSA
+destination signing key A is blinded every day into a RedDSA key
+
+	A' = A + alpha * B    a' = a + alpha mod L
+
+published LeaseSets are signed with and stored under. alpha is derived from
+A, the UTC date and an optional secret, so everybody knowing the destination
+and secret can derive A' and look it up, while A' cannot be linked to A
+without them:
+
+	keydata = A || stA || stA'
+	seed = HKDF(SHA256("I2PGenerateAlpha" || keydata), "YYYYMMDD" || secret, "i2pblinding1", 64)
+	alpha = seed mod L
+
+stA and stA' are the 2 byte signature types of A and A'.
+
+https://geti2p.net/spec/encryptedleaseset
+*/
+
+const (
+	BLINDING_ALPHA_PREFIX = "I2PGenerateAlpha"
+	BLINDING_INFO         = "i2pblinding1"
+)
+
+var ErrUnsupportedBlinding = errors.New("only Ed25519 and RedDSA keys can be blinded")
+
+// blindingSigType returns the signature type of a key which can be blinded
+func blindingSigType(public SigningPublicKey) (uint16, error) {
+	switch public.(type) {
+	case Ed25519PublicKey, *Ed25519PublicKey:
+		return SIGTYPE_EDDSA_SHA512_ED25519, nil
+	case RedDSAPublicKey, *RedDSAPublicKey:
+		return SIGTYPE_REDDSA_SHA512_ED25519, nil
+	}
+	return 0, ErrUnsupportedBlinding
+}
+
+// blindingAlpha returns alpha for the public key on the day of date
+func blindingAlpha(public SigningPublicKey, date time.Time, secret string) (*edwards25519.Scalar, error) {
+	sigType, err := blindingSigType(public)
+	if err != nil {
+		return nil, err
+	}
+	if public.Len() != REDDSA_PUBLIC_KEY_SIZE {
+		return nil, ErrInvalidKeyFormat
+	}
+	keydata := make([]byte, 0, REDDSA_PUBLIC_KEY_SIZE+4)
+	keydata = append(keydata, public.Bytes()...)
+	keydata = binary.BigEndian.AppendUint16(keydata, sigType)
+	keydata = binary.BigEndian.AppendUint16(keydata, SIGTYPE_REDDSA_SHA512_ED25519)
+	salt := sha256.Sum256(append([]byte(BLINDING_ALPHA_PREFIX), keydata...))
+	ikm := append([]byte(date.UTC().Format("20060102")), secret...)
+	return edwards25519.NewScalar().SetUniformBytes(HKDF(salt[:], ikm, BLINDING_INFO, 64))
+}
+
+// BlindingAlpha returns the little-endian scalar alpha an Ed25519 or RedDSA
+// public key is blinded with on the day of date, with an optional secret.
+func BlindingAlpha(public SigningPublicKey, date time.Time, secret string) ([]byte, error) {
+	alpha, err := blindingAlpha(public, date, secret)
+	if err != nil {
+		return nil, err
+	}
+	return alpha.Bytes(), nil
+}
+
+// BlindPublicKey returns the blinded key A' of an Ed25519 or RedDSA public
+// key on the day of date, as a client looking up an encrypted LeaseSet
+// derives it.
+func BlindPublicKey(public SigningPublicKey, date time.Time, secret string) (RedDSAPublicKey, error) {
+	alpha, err := blindingAlpha(public, date, secret)
+	if err != nil {
+		return nil, err
+	}
+	A, err := edwards25519.NewIdentityPoint().SetBytes(public.Bytes())
+	if err != nil {
+		return nil, ErrInvalidKeyFormat
+	}
+	blinded := edwards25519.NewIdentityPoint().ScalarBaseMult(alpha)
+	return RedDSAPublicKey(blinded.Add(blinded, A).Bytes()), nil
+}
+
+// BlindPrivateKey returns the blinded private key a' of an Ed25519 or RedDSA
+// private key on the day of date, which the destination signs its encrypted
+// LeaseSet with. Its public key is BlindPublicKey of the unblinded one.
+func BlindPrivateKey(private SigningPrivateKey, date time.Time, secret string) (RedDSAPrivateKey, error) {
+	var a *edwards25519.Scalar
+	switch key := private.(type) {
+	case *Ed25519PrivateKey:
+		if len(*key) != ed25519.PrivateKeySize {
+			return nil, ErrInvalidKeyFormat
+		}
+		h := sha512.Sum512(key.Seed())
+		var err error
+		if a, err = edwards25519.NewScalar().SetBytesWithClamping(h[:32]); err != nil {
+			return nil, err
+		}
+	case *RedDSAPrivateKey:
+		var err error
+		if a, err = key.scalar(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedBlinding
+	}
+	public, err := private.Public()
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := blindingAlpha(public, date, secret)
+	if err != nil {
+		return nil, err
+	}
+	return RedDSAPrivateKey(edwards25519.NewScalar().Add(a, alpha).Bytes()), nil
+}
+
+// BlindedStoreKey returns the netdb key an encrypted LeaseSet signed with
+// the blinded key is stored under, SHA256(stA' || A').
+func BlindedStoreKey(blinded RedDSAPublicKey) [32]byte {
+	var sigType [2]byte
+	binary.BigEndian.PutUint16(sigType[:], SIGTYPE_REDDSA_SHA512_ED25519)
+	return sha256.Sum256(append(sigType[:], blinded...))
+}
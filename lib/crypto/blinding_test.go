@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var blindingDate = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+func TestBlindPrivateKeyMatchesBlindPublicKey(t *testing.T) {
+	ed, err := GenerateEd25519PrivateKey()
+	require.NoError(t, err)
+	var red RedDSAPrivateKey
+	_, err = red.Generate()
+	require.NoError(t, err)
+
+	for name, private := range map[string]SigningPrivateKey{"Ed25519": &ed, "RedDSA": &red} {
+		t.Run(name, func(t *testing.T) {
+			public, err := private.Public()
+			require.NoError(t, err)
+			blindedPublic, err := BlindPublicKey(public, blindingDate, "")
+			require.NoError(t, err)
+			assert.NotEqual(t, public.Bytes(), blindedPublic.Bytes())
+
+			blindedPrivate, err := BlindPrivateKey(private, blindingDate, "")
+			require.NoError(t, err)
+			derived, err := blindedPrivate.Public()
+			require.NoError(t, err)
+			assert.Equal(t, blindedPublic, derived)
+
+			signer, err := blindedPrivate.NewSigner()
+			require.NoError(t, err)
+			sig, err := signer.Sign([]byte("encrypted LeaseSet"))
+			require.NoError(t, err)
+			verifier, err := blindedPublic.NewVerifier()
+			require.NoError(t, err)
+			assert.NoError(t, verifier.Verify([]byte("encrypted LeaseSet"), sig))
+			assert.False(t, ed25519.Verify(ed25519.PublicKey(public.Bytes()), []byte("encrypted LeaseSet"), sig))
+		})
+	}
+}
+
+func TestBlindingAlphaChangesDaily(t *testing.T) {
+	private, err := GenerateEd25519PrivateKey()
+	require.NoError(t, err)
+	public, err := private.Public()
+	require.NoError(t, err)
+
+	alpha, err := BlindingAlpha(public, blindingDate, "")
+	require.NoError(t, err)
+	assert.Len(t, alpha, 32)
+	sameDay, err := BlindingAlpha(public, blindingDate.Add(11*time.Hour), "")
+	require.NoError(t, err)
+	assert.Equal(t, alpha, sameDay)
+	// the day is taken in UTC
+	local, err := BlindingAlpha(public, blindingDate.In(time.FixedZone("UTC+13", 13*3600)), "")
+	require.NoError(t, err)
+	assert.Equal(t, alpha, local)
+
+	nextDay, err := BlindingAlpha(public, blindingDate.AddDate(0, 0, 1), "")
+	require.NoError(t, err)
+	assert.NotEqual(t, alpha, nextDay)
+	withSecret, err := BlindingAlpha(public, blindingDate, "secret")
+	require.NoError(t, err)
+	assert.NotEqual(t, alpha, withSecret)
+	// the signature type is part of the key data
+	asRedDSA, err := BlindingAlpha(RedDSAPublicKey(public.Bytes()), blindingDate, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, alpha, asRedDSA)
+}
+
+func TestBlindedStoreKey(t *testing.T) {
+	var red RedDSAPrivateKey
+	_, err := red.Generate()
+	require.NoError(t, err)
+	public, err := red.Public()
+	require.NoError(t, err)
+	today, err := BlindPublicKey(public, blindingDate, "")
+	require.NoError(t, err)
+	tomorrow, err := BlindPublicKey(public, blindingDate.AddDate(0, 0, 1), "")
+	require.NoError(t, err)
+	assert.NotEqual(t, BlindedStoreKey(today), BlindedStoreKey(tomorrow))
+}
+
+func TestBlindingUnsupportedKey(t *testing.T) {
+	_, err := BlindPublicKey(&ECP256PublicKey{}, blindingDate, "")
+	assert.ErrorIs(t, err, ErrUnsupportedBlinding)
+	_, err = BlindPublicKey(Ed25519PublicKey{1, 2, 3}, blindingDate, "")
+	assert.ErrorIs(t, err, ErrInvalidKeyFormat)
+}
@@ -0,0 +1,182 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Sizes of the ChaCha20-Poly1305 AEAD used by NTCP2, SSU2 and ECIES-Ratchet
+const (
+	CHACHA20POLY1305_KEY_SIZE   = chacha20poly1305.KeySize
+	CHACHA20POLY1305_NONCE_SIZE = chacha20poly1305.NonceSize
+	CHACHA20POLY1305_TAG_SIZE   = chacha20poly1305.Overhead
+)
+
+// Noise reserves the largest counter, 2^64-1, so it is never used as a nonce
+const MAX_NONCE_COUNTER = math.MaxUint64 - 1
+
+var (
+	ErrInvalidAEADKeySize = errors.New("invalid ChaCha20-Poly1305 key size")
+	ErrInvalidNonceSize   = errors.New("invalid ChaCha20-Poly1305 nonce size")
+	ErrAEADAuthFailed     = errors.New("ChaCha20-Poly1305 authentication failed")
+	ErrNonceExhausted     = errors.New("nonce counter exhausted, rekey required")
+	ErrNonceReplayed      = errors.New("nonce already used or too old")
+)
+
+// ChaCha20Poly1305 is the RFC 7539 AEAD with explicit 96 bit nonces.
+type ChaCha20Poly1305 struct {
+	aead cipher.AEAD
+}
+
+// NewChaCha20Poly1305 returns the AEAD for a 32 byte key.
+func NewChaCha20Poly1305(key []byte) (*ChaCha20Poly1305, error) {
+	if len(key) != CHACHA20POLY1305_KEY_SIZE {
+		return nil, ErrInvalidAEADKeySize
+	}
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return &ChaCha20Poly1305{aead: aead}, nil
+}
+
+// Encrypt returns the ciphertext of plaintext followed by the 16 byte
+// authentication tag, covering plaintext and ad.
+func (c *ChaCha20Poly1305) Encrypt(nonce, plaintext, ad []byte) ([]byte, error) {
+	if len(nonce) != CHACHA20POLY1305_NONCE_SIZE {
+		return nil, ErrInvalidNonceSize
+	}
+	return c.aead.Seal(nil, nonce, plaintext, ad), nil
+}
+
+// Decrypt authenticates ciphertext and ad and returns the plaintext.
+func (c *ChaCha20Poly1305) Decrypt(nonce, ciphertext, ad []byte) ([]byte, error) {
+	if len(nonce) != CHACHA20POLY1305_NONCE_SIZE {
+		return nil, ErrInvalidNonceSize
+	}
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, ad)
+	if err != nil {
+		log.WithField("ciphertext_length", len(ciphertext)).Debug("ChaCha20-Poly1305 authentication failed")
+		return nil, ErrAEADAuthFailed
+	}
+	return plaintext, nil
+}
+
+// CounterNonce returns the Noise nonce for counter n: four zero bytes and n
+// little endian, as NTCP2 and ECIES-Ratchet use.
+func CounterNonce(n uint64) []byte {
+	nonce := make([]byte, CHACHA20POLY1305_NONCE_SIZE)
+	binary.LittleEndian.PutUint64(nonce[4:], n)
+	return nonce
+}
+
+// CipherState encrypts or decrypts a stream of messages with a counter
+// nonce, starting at 0, the way the NTCP2 data phase does. Each direction
+// uses its own CipherState.
+type CipherState struct {
+	mutex sync.Mutex
+	aead  *ChaCha20Poly1305
+	n     uint64
+}
+
+// NewCipherState returns a CipherState for the key, with the counter at 0.
+func NewCipherState(key []byte) (*CipherState, error) {
+	aead, err := NewChaCha20Poly1305(key)
+	if err != nil {
+		return nil, err
+	}
+	return &CipherState{aead: aead}, nil
+}
+
+// Nonce returns the counter the next message will use.
+func (cs *CipherState) Nonce() uint64 {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	return cs.n
+}
+
+// Encrypt encrypts the next message and advances the counter.
+func (cs *CipherState) Encrypt(plaintext, ad []byte) ([]byte, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if cs.n > MAX_NONCE_COUNTER {
+		return nil, ErrNonceExhausted
+	}
+	ciphertext, err := cs.aead.Encrypt(CounterNonce(cs.n), plaintext, ad)
+	if err != nil {
+		return nil, err
+	}
+	cs.n++
+	return ciphertext, nil
+}
+
+// Decrypt decrypts the next message and advances the counter. The counter
+// is left unchanged if authentication fails.
+func (cs *CipherState) Decrypt(ciphertext, ad []byte) ([]byte, error) {
+	cs.mutex.Lock()
+	defer cs.mutex.Unlock()
+	if cs.n > MAX_NONCE_COUNTER {
+		return nil, ErrNonceExhausted
+	}
+	plaintext, err := cs.aead.Decrypt(CounterNonce(cs.n), ciphertext, ad)
+	if err != nil {
+		return nil, err
+	}
+	cs.n++
+	return plaintext, nil
+}
+
+// size of the window of counters NonceWindow remembers below the highest
+const NONCE_WINDOW_SIZE = 64
+
+// NonceWindow accepts each counter once, for messages which may arrive out
+// of order, such as ECIES-Ratchet messages numbered by their session tag.
+// Counters more than NONCE_WINDOW_SIZE below the highest seen are rejected.
+type NonceWindow struct {
+	mutex   sync.Mutex
+	highest uint64
+	seen    uint64
+	started bool
+}
+
+// Accept records counter n, returning ErrNonceReplayed if it was seen
+// before or is too old. Call it only once the message authenticated.
+func (window *NonceWindow) Accept(n uint64) error {
+	window.mutex.Lock()
+	defer window.mutex.Unlock()
+	if n > MAX_NONCE_COUNTER {
+		return ErrNonceExhausted
+	}
+	if !window.started {
+		window.started = true
+		window.highest = n
+		window.seen = 1
+		return nil
+	}
+	if n > window.highest {
+		shift := n - window.highest
+		if shift >= NONCE_WINDOW_SIZE {
+			window.seen = 0
+		} else {
+			window.seen <<= shift
+		}
+		window.seen |= 1
+		window.highest = n
+		return nil
+	}
+	offset := window.highest - n
+	if offset >= NONCE_WINDOW_SIZE {
+		return ErrNonceReplayed
+	}
+	bit := uint64(1) << offset
+	if window.seen&bit != 0 {
+		return ErrNonceReplayed
+	}
+	window.seen |= bit
+	return nil
+}
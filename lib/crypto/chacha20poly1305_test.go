@@ -0,0 +1,87 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 7539 section 2.8.2
+func TestChaCha20Poly1305Vector(t *testing.T) {
+	key, _ := hex.DecodeString("808182838485868788898a8b8c8d8e8f909192939495969798999a9b9c9d9e9f")
+	nonce, _ := hex.DecodeString("070000004041424344454647")
+	ad, _ := hex.DecodeString("50515253c0c1c2c3c4c5c6c7")
+	plaintext := []byte("Ladies and Gentlemen of the class of '99: If I could offer you only one tip for the future, sunscreen would be it.")
+
+	aead, err := NewChaCha20Poly1305(key)
+	require.NoError(t, err)
+	ciphertext, err := aead.Encrypt(nonce, plaintext, ad)
+	require.NoError(t, err)
+	require.Len(t, ciphertext, len(plaintext)+CHACHA20POLY1305_TAG_SIZE)
+	assert.Equal(t, "d31a8d34648e60db7b86afbc53ef7ec2", hex.EncodeToString(ciphertext[:16]))
+	assert.Equal(t, "1ae10b594f09e26a7e902ecbd0600691", hex.EncodeToString(ciphertext[len(plaintext):]))
+
+	decrypted, err := aead.Decrypt(nonce, ciphertext, ad)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	_, err = aead.Decrypt(nonce, ciphertext, []byte("other"))
+	assert.ErrorIs(t, err, ErrAEADAuthFailed)
+	_, err = aead.Encrypt(nonce[:8], plaintext, ad)
+	assert.ErrorIs(t, err, ErrInvalidNonceSize)
+	_, err = NewChaCha20Poly1305(key[:16])
+	assert.ErrorIs(t, err, ErrInvalidAEADKeySize)
+}
+
+func TestCounterNonce(t *testing.T) {
+	assert.Equal(t, "000000000102000000000000", hex.EncodeToString(CounterNonce(0x0201)))
+}
+
+func TestCipherState(t *testing.T) {
+	key := make([]byte, CHACHA20POLY1305_KEY_SIZE)
+	sender, err := NewCipherState(key)
+	require.NoError(t, err)
+	receiver, err := NewCipherState(key)
+	require.NoError(t, err)
+
+	first, err := sender.Encrypt([]byte("one"), nil)
+	require.NoError(t, err)
+	second, err := sender.Encrypt([]byte("two"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), sender.Nonce())
+
+	// out of order messages fail and leave the counter alone
+	_, err = receiver.Decrypt(second, nil)
+	assert.ErrorIs(t, err, ErrAEADAuthFailed)
+	assert.Equal(t, uint64(0), receiver.Nonce())
+	plaintext, err := receiver.Decrypt(first, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("one"), plaintext)
+	plaintext, err = receiver.Decrypt(second, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("two"), plaintext)
+}
+
+func TestCipherStateExhausted(t *testing.T) {
+	cs, err := NewCipherState(make([]byte, CHACHA20POLY1305_KEY_SIZE))
+	require.NoError(t, err)
+	cs.n = MAX_NONCE_COUNTER
+	_, err = cs.Encrypt(nil, nil)
+	require.NoError(t, err)
+	_, err = cs.Encrypt(nil, nil)
+	assert.ErrorIs(t, err, ErrNonceExhausted)
+}
+
+func TestNonceWindow(t *testing.T) {
+	var window NonceWindow
+	assert.NoError(t, window.Accept(5))
+	assert.ErrorIs(t, window.Accept(5), ErrNonceReplayed)
+	assert.NoError(t, window.Accept(3))
+	assert.NoError(t, window.Accept(100))
+	assert.ErrorIs(t, window.Accept(3), ErrNonceReplayed)
+	assert.NoError(t, window.Accept(100-NONCE_WINDOW_SIZE+1))
+	assert.ErrorIs(t, window.Accept(100-NONCE_WINDOW_SIZE), ErrNonceReplayed)
+	assert.ErrorIs(t, window.Accept(100), ErrNonceReplayed)
+}
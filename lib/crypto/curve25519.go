@@ -34,6 +34,10 @@ func (k Curve25519PublicKey) Len() int {
 	return length
 }
 
+func (k Curve25519PublicKey) Bytes() []byte {
+	return k
+}
+
 func createCurve25519PublicKey(data []byte) (k *curve25519.PublicKey) {
 	log.WithField("data_length", len(data)).Debug("Creating Curve25519PublicKey")
 	if len(data) == 256 {
@@ -10,6 +10,28 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+/*
+DSA_SHA1, signature type 0, the legacy signature type of I2P. It is only
+verified today, signing is kept for tests and compatibility. The 1024 bit
+parameters p and q were generated from a SEED as in FIPS 186-2, appendix
+2.2, so anybody can check they were not chosen with a backdoor:
+
+	SEED    = 86108236b8526e296e923a4015b4282845b572cc
+	counter = 33
+
+https://geti2p.net/spec/cryptography#dsa
+*/
+
+const (
+	// DSA_SEED and DSA_COUNTER generate p and q
+	DSA_SEED    = "86108236b8526e296e923a4015b4282845b572cc"
+	DSA_COUNTER = 33
+
+	DSA_PUBLIC_KEY_SIZE  = 128
+	DSA_PRIVATE_KEY_SIZE = 20
+	DSA_SIGNATURE_SIZE   = 40
+)
+
 var dsap = new(big.Int).SetBytes([]byte{
 	0x9c, 0x05, 0xb2, 0xaa, 0x96, 0x0d, 0x9b, 0x97, 0xb8, 0x93, 0x19, 0x63, 0xc9, 0xcc, 0x9e, 0x8c,
 	0x30, 0x26, 0xe9, 0xb8, 0xed, 0x92, 0xfa, 0xd0, 0xa6, 0x9c, 0xc8, 0x86, 0xd5, 0xbf, 0x80, 0x15,
@@ -69,10 +91,11 @@ func createDSAPublicKey(Y *big.Int) *dsa.PublicKey {
 	}
 }
 
-// createa i2p dsa private key given its public component
+// create i2p dsa private key given its private component x, which must be
+// in [1, q-1]
 func createDSAPrivkey(X *big.Int) (k *dsa.PrivateKey) {
 	log.Debug("Creating DSA private key")
-	if X.Cmp(dsap) == -1 {
+	if X.Sign() > 0 && X.Cmp(dsaq) == -1 {
 		Y := new(big.Int)
 		Y.Exp(dsag, X, dsap)
 		k = &dsa.PrivateKey{
@@ -84,7 +107,7 @@ func createDSAPrivkey(X *big.Int) (k *dsa.PrivateKey) {
 		}
 		log.Debug("DSA private key created successfully")
 	} else {
-		log.Warn("Failed to create DSA private key: X is not less than p")
+		log.Warn("Failed to create DSA private key: X is not in [1, q-1]")
 	}
 	return
 }
@@ -93,7 +116,7 @@ type DSAVerifier struct {
 	k *dsa.PublicKey
 }
 
-type DSAPublicKey [128]byte
+type DSAPublicKey [DSA_PUBLIC_KEY_SIZE]byte
 
 func (k DSAPublicKey) Bytes() []byte {
 	return k[:]
@@ -125,7 +148,7 @@ func (v *DSAVerifier) VerifyHash(h, sig []byte) (err error) {
 		"hash_length": len(h),
 		"sig_length":  len(sig),
 	}).Debug("Verifying DSA signature hash")
-	if len(sig) == 40 {
+	if len(sig) == DSA_SIGNATURE_SIZE {
 		r := new(big.Int).SetBytes(sig[:20])
 		s := new(big.Int).SetBytes(sig[20:])
 		if dsa.Verify(v.k, h, r, s) {
@@ -151,41 +174,46 @@ type DSASigner struct {
 	k *dsa.PrivateKey
 }
 
-type DSAPrivateKey [20]byte
+// DSAPrivateKey is the private component x, big endian.
+type DSAPrivateKey [DSA_PRIVATE_KEY_SIZE]byte
 
 // create a new dsa signer
 func (k DSAPrivateKey) NewSigner() (s Signer, err error) {
 	log.Debug("Creating new DSA signer")
+	p := createDSAPrivkey(new(big.Int).SetBytes(k[:]))
+	if p == nil {
+		return nil, ErrInvalidKeyFormat
+	}
 	s = &DSASigner{
-		k: createDSAPrivkey(new(big.Int).SetBytes(k[:])),
+		k: p,
 	}
 	return
 }
 
-func (k DSAPrivateKey) Public() (pk DSAPublicKey, err error) {
+// Public returns the DSAPublicKey y = g^x mod p.
+func (k DSAPrivateKey) Public() (SigningPublicKey, error) {
 	p := createDSAPrivkey(new(big.Int).SetBytes(k[:]))
 	if p == nil {
 		log.Error("Invalid DSA private key format")
-		err = ErrInvalidKeyFormat
-	} else {
-		copy(pk[:], p.Y.Bytes())
-		log.Debug("DSA public key derived successfully")
+		return nil, ErrInvalidKeyFormat
 	}
-	return
+	var pk DSAPublicKey
+	p.Y.FillBytes(pk[:])
+	log.Debug("DSA public key derived successfully")
+	return pk, nil
 }
 
-func (k DSAPrivateKey) Generate() (s DSAPrivateKey, err error) {
+// Generate replaces the key with a new random one.
+func (k *DSAPrivateKey) Generate() (SigningPrivateKey, error) {
 	log.Debug("Generating new DSA private key")
 	dk := new(dsa.PrivateKey)
-	err = generateDSA(dk, rand.Reader)
-	if err == nil {
-		copy(k[:], dk.X.Bytes())
-		s = k
-		log.Debug("New DSA private key generated successfully")
-	} else {
+	if err := generateDSA(dk, rand.Reader); err != nil {
 		log.WithError(err).Error("Failed to generate new DSA private key")
+		return nil, err
 	}
-	return
+	dk.X.FillBytes(k[:])
+	log.Debug("New DSA private key generated successfully")
+	return k, nil
 }
 
 func (ds *DSASigner) Sign(data []byte) (sig []byte, err error) {
@@ -200,13 +228,9 @@ func (ds *DSASigner) SignHash(h []byte) (sig []byte, err error) {
 	var r, s *big.Int
 	r, s, err = dsa.Sign(rand.Reader, ds.k, h)
 	if err == nil {
-		sig = make([]byte, 40)
-		rb := r.Bytes()
-		rl := len(rb)
-		copy(sig[20-rl:20], rb)
-		sb := s.Bytes()
-		sl := len(sb)
-		copy(sig[20+(20-sl):], sb)
+		sig = make([]byte, DSA_SIGNATURE_SIZE)
+		r.FillBytes(sig[:20])
+		s.FillBytes(sig[20:])
 		log.WithField("sig_length", len(sig)).Debug("DSA signature created successfully")
 	} else {
 		log.WithError(err).Error("Failed to create DSA signature")
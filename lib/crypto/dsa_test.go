@@ -2,15 +2,17 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
 	"io"
+	"math/big"
 	"testing"
 )
 
 func TestDSA(t *testing.T) {
 	var sk DSAPrivateKey
-	var pk DSAPublicKey
-	var err error
-	sk, err = sk.Generate()
+	var pk SigningPublicKey
+	_, err := sk.Generate()
 	if err == nil {
 		zeros := 0
 		for b := range sk {
@@ -60,13 +62,11 @@ func BenchmarkDSAGenerate(b *testing.B) {
 
 func BenchmarkDSASignVerify(b *testing.B) {
 	var sk DSAPrivateKey
-	var pk DSAPublicKey
-	var err error
-	sk, err = sk.Generate()
+	_, err := sk.Generate()
 	if err != nil {
 		panic(err.Error())
 	}
-	pk, err = sk.Public()
+	pk, err := sk.Public()
 	if err != nil {
 		panic(err.Error())
 	}
@@ -86,3 +86,95 @@ func BenchmarkDSASignVerify(b *testing.B) {
 	}
 	log.Debugf("%d fails %d signs", fail, b.N)
 }
+
+// TestDSAParametersFromSeed regenerates q and p from DSA_SEED and
+// DSA_COUNTER as FIPS 186-2 appendix 2.2 does
+func TestDSAParametersFromSeed(t *testing.T) {
+	seed, err := hex.DecodeString(DSA_SEED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := new(big.Int).SetBytes(seed)
+	mod := new(big.Int).Lsh(big.NewInt(1), 160)
+	hash := func(x *big.Int) *big.Int {
+		var buf [20]byte
+		new(big.Int).Mod(x, mod).FillBytes(buf[:])
+		h := sha1.Sum(buf[:])
+		return new(big.Int).SetBytes(h[:])
+	}
+	plus := func(n int) *big.Int {
+		return new(big.Int).Add(s, big.NewInt(int64(n)))
+	}
+
+	q := new(big.Int).Xor(hash(s), hash(plus(1)))
+	q.SetBit(q, 159, 1).SetBit(q, 0, 1)
+	if q.Cmp(dsaq) != 0 {
+		t.Fatalf("q from seed is %x", q)
+	}
+
+	// 1024 bit p from 7 hashes, the last contributing 63 bits
+	const n, b = 6, 63
+	offset := 2 + DSA_COUNTER*(n+1)
+	w := new(big.Int)
+	for k := n; k >= 0; k-- {
+		v := hash(plus(offset + k))
+		if k == n {
+			v.Mod(v, new(big.Int).Lsh(big.NewInt(1), b))
+		}
+		w.Lsh(w, 160).Or(w, v)
+	}
+	x := w.SetBit(w, 1023, 1)
+	c := new(big.Int).Mod(x, new(big.Int).Lsh(q, 1))
+	p := new(big.Int).Sub(x, c.Sub(c, big.NewInt(1)))
+	if p.Cmp(dsap) != 0 {
+		t.Fatalf("p from seed and counter is %x", p)
+	}
+
+	// g generates the subgroup of order q
+	if dsag.Cmp(big.NewInt(1)) <= 0 || new(big.Int).Exp(dsag, dsaq, dsap).Cmp(big.NewInt(1)) != 0 {
+		t.Fatal("g does not have order q")
+	}
+}
+
+func TestDSASmallKeyIsPadded(t *testing.T) {
+	var sk DSAPrivateKey
+	sk[DSA_PRIVATE_KEY_SIZE-1] = 2
+	pk, err := sk.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk.Len() != DSA_PUBLIC_KEY_SIZE {
+		t.Fatalf("public key has %d bytes", pk.Len())
+	}
+	want := new(big.Int).Exp(dsag, big.NewInt(2), dsap)
+	if new(big.Int).SetBytes(pk.Bytes()).Cmp(want) != 0 {
+		t.Fatal("public key is not g^x mod p")
+	}
+	signer, err := sk.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.Sign([]byte("legacy"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := pk.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify([]byte("legacy"), sig); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDSAInvalidPrivateKey(t *testing.T) {
+	var zero DSAPrivateKey
+	if _, err := zero.Public(); err != ErrInvalidKeyFormat {
+		t.Fatalf("zero key: %v", err)
+	}
+	var tooLarge DSAPrivateKey
+	dsaq.FillBytes(tooLarge[:])
+	if _, err := tooLarge.NewSigner(); err != ErrInvalidKeyFormat {
+		t.Fatalf("key q: %v", err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/sha256"
-	"crypto/sha512"
 	"errors"
 	"fmt"
 	"io"
@@ -145,6 +144,8 @@ func (elg Ed25519PublicKey) NewEncrypter() (enc Encrypter, err error) {
 	return enc, nil
 }
 
+// VerifyHash verifies an Ed25519 signature of h. Ed25519 hashes the message
+// itself, so h is verified as the signed message.
 func (v *Ed25519Verifier) VerifyHash(h, sig []byte) (err error) {
 	log.WithFields(logrus.Fields{
 		"hash_length": len(h),
@@ -158,36 +159,61 @@ func (v *Ed25519Verifier) VerifyHash(h, sig []byte) (err error) {
 	}
 	if len(v.k) != ed25519.PublicKeySize {
 		log.Error("Invalid Ed25519 public key size")
-		err = errors.New("failed to verify: invalid ed25519 public key size")
+		err = ErrInvalidPublicKeySize
 		return
 	}
 
 	ok := ed25519.Verify(v.k, h, sig)
 	if !ok {
 		log.Warn("Invalid Ed25519 signature")
-		err = errors.New("failed to verify: invalid signature")
+		err = ErrInvalidSignature
 	} else {
 		log.Debug("Ed25519 signature verified successfully")
 	}
 	return
 }
 
+// Verify verifies an EdDSA_SHA512_Ed25519 signature of data. The SHA-512
+// hashing is part of Ed25519, the data is not hashed beforehand.
 func (v *Ed25519Verifier) Verify(data, sig []byte) (err error) {
 	log.WithFields(logrus.Fields{
 		"data_length": len(data),
 		"sig_length":  len(sig),
 	}).Debug("Verifying Ed25519 signature")
-
-	h := sha512.Sum512(data)
-	err = v.VerifyHash(h[:], sig)
-	return
+	return v.VerifyHash(data, sig)
 }
 
+// Ed25519PrivateKey is a 64 byte Ed25519 private key, the 32 byte seed
+// followed by the public key. I2P stores only the seed, see Seed and
+// CreateEd25519PrivateKeyFromBytes.
 type Ed25519PrivateKey ed25519.PrivateKey
 
+// GenerateEd25519PrivateKey returns a new random Ed25519 private key
+func GenerateEd25519PrivateKey() (Ed25519PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		log.WithError(err).Error("Failed to generate Ed25519 private key")
+		return nil, err
+	}
+	return Ed25519PrivateKey(priv), nil
+}
+
+// CreateEd25519PrivateKeyFromBytes creates a private key from a 32 byte seed,
+// as I2P stores Ed25519 private keys, or from a 64 byte private key. The data
+// is copied.
+func CreateEd25519PrivateKeyFromBytes(data []byte) (Ed25519PrivateKey, error) {
+	switch len(data) {
+	case ed25519.SeedSize:
+		return Ed25519PrivateKey(ed25519.NewKeyFromSeed(data)), nil
+	case ed25519.PrivateKeySize:
+		return Ed25519PrivateKey(append([]byte(nil), data...)), nil
+	}
+	log.WithField("data_length", len(data)).Error("Invalid Ed25519 private key size")
+	return nil, ErrInvalidKeyFormat
+}
+
 func (k Ed25519PrivateKey) NewDecrypter() (Decrypter, error) {
-	// TODO implement me
-	panic("implement me")
+	return nil, errors.New("ed25519 private keys do not decrypt")
 }
 
 func (k Ed25519PrivateKey) NewSigner() (Signer, error) {
@@ -201,46 +227,47 @@ func (k Ed25519PrivateKey) Len() int {
 	return len(k)
 }
 
+// Seed returns the 32 byte seed of the private key, the form I2P stores
+func (k Ed25519PrivateKey) Seed() []byte {
+	return ed25519.PrivateKey(k).Seed()
+}
+
 func (k *Ed25519PrivateKey) Generate() (SigningPrivateKey, error) {
-	// Generate a new Ed25519 key pair
-	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	priv, err := GenerateEd25519PrivateKey()
 	if err != nil {
 		return nil, err
 	}
-	// Assign the generated private key to the receiver
-	*k = Ed25519PrivateKey(priv)
+	*k = priv
 	return k, nil
 }
 
 func (k Ed25519PrivateKey) Public() (SigningPublicKey, error) {
-	fmt.Printf("Ed25519PrivateKey.Public(): len(k) = %d\n", len(k))
 	if len(k) != ed25519.PrivateKeySize {
 		return nil, fmt.Errorf("invalid ed25519 private key size: expected %d, got %d", ed25519.PrivateKeySize, len(k))
 	}
-	pubKey := k[32:]
-	fmt.Printf("Ed25519PrivateKey.Public(): extracted pubKey length: %d\n", len(pubKey))
-	return Ed25519PublicKey(pubKey), nil
+	return Ed25519PublicKey(append([]byte(nil), k[ed25519.SeedSize:]...)), nil
 }
 
 type Ed25519Signer struct {
 	k []byte
 }
 
+// Sign signs data with EdDSA_SHA512_Ed25519. The SHA-512 hashing is part of
+// Ed25519, the data is not hashed beforehand.
 func (s *Ed25519Signer) Sign(data []byte) (sig []byte, err error) {
 	log.WithField("data_length", len(data)).Debug("Signing data with Ed25519")
+	return s.SignHash(data)
+}
 
+// SignHash signs h with Ed25519. Ed25519 hashes the message itself, so h is
+// signed as the message.
+func (s *Ed25519Signer) SignHash(h []byte) (sig []byte, err error) {
+	log.WithField("hash_length", len(h)).Debug("Signing hash with Ed25519")
 	if len(s.k) != ed25519.PrivateKeySize {
 		log.Error("Invalid Ed25519 private key size")
 		err = errors.New("failed to sign: invalid ed25519 private key size")
 		return
 	}
-	h := sha512.Sum512(data)
-	sig, err = s.SignHash(h[:])
-	return
-}
-
-func (s *Ed25519Signer) SignHash(h []byte) (sig []byte, err error) {
-	log.WithField("hash_length", len(h)).Debug("Signing hash with Ed25519")
 	sig = ed25519.Sign(s.k, h)
 	log.WithField("signature_length", len(sig)).Debug("Ed25519 signature created successfully")
 	return
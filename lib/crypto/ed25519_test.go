@@ -1,8 +1,10 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"errors"
 	"io"
 	"testing"
 )
@@ -40,3 +42,57 @@ func TestEd25519(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestEd25519SignaturesAreStandard(t *testing.T) {
+	priv, err := GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := priv.NewSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("router info")
+	sig, err := signer.Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// EdDSA_SHA512_Ed25519 signs the message itself, other routers verify
+	// it with plain Ed25519
+	pub := ed25519.PrivateKey(priv).Public().(ed25519.PublicKey)
+	if !ed25519.Verify(pub, message, sig) {
+		t.Fatal("signature does not verify with crypto/ed25519")
+	}
+
+	public, err := priv.Public()
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := public.NewVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify(message, ed25519.Sign(ed25519.PrivateKey(priv), message)); err != nil {
+		t.Fatalf("crypto/ed25519 signature does not verify: %s", err)
+	}
+	if err := verifier.Verify([]byte("other"), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("expected ErrInvalidSignature, got %v", err)
+	}
+}
+
+func TestEd25519PrivateKeyFromSeed(t *testing.T) {
+	priv, err := GenerateEd25519PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSeed, err := CreateEd25519PrivateKeyFromBytes(priv.Seed())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(priv, fromSeed) {
+		t.Fatal("private key from seed differs")
+	}
+	if _, err := CreateEd25519PrivateKeyFromBytes(make([]byte, 31)); !errors.Is(err, ErrInvalidKeyFormat) {
+		t.Fatalf("expected ErrInvalidKeyFormat, got %v", err)
+	}
+}
@@ -96,7 +96,15 @@ func elgamalDecrypt(priv *elgamal.PrivateKey, data []byte, zeroPadding bool) (de
 	b.SetBytes(data[idx+256:])
 
 	// decrypt
-	m := new(big.Int).Mod(new(big.Int).Mul(b, new(big.Int).Exp(a, new(big.Int).Sub(new(big.Int).Sub(priv.P, priv.X), one), priv.P)), priv.P).Bytes()
+	mInt := new(big.Int).Mod(new(big.Int).Mul(b, new(big.Int).Exp(a, new(big.Int).Sub(new(big.Int).Sub(priv.P, priv.X), one), priv.P)), priv.P)
+	if mInt.BitLen() > 255*8 {
+		// not the 255 byte block encrypt produces
+		err = ElgDecryptFail
+		log.WithError(err).Error("ElGamal decryption failed")
+		return
+	}
+	m := make([]byte, 255)
+	mInt.FillBytes(m)
 
 	// check digest
 	d := sha256.Sum256(m[33:255])
@@ -148,16 +156,17 @@ func (elg *ElgamalEncryption) EncryptPadding(data []byte, zeroPadding bool) (enc
 	copy(mbytes[1:], d[:])
 	m := new(big.Int).SetBytes(mbytes)
 	// do encryption
-	b := new(big.Int).Mod(new(big.Int).Mul(elg.b1, m), elg.p).Bytes()
+	b := new(big.Int).Mod(new(big.Int).Mul(elg.b1, m), elg.p)
 
+	// a and b are right aligned in their 256 bytes
 	if zeroPadding {
 		encrypted = make([]byte, 514)
-		copy(encrypted[1:], elg.a.Bytes())
-		copy(encrypted[258:], b)
+		elg.a.FillBytes(encrypted[1:257])
+		b.FillBytes(encrypted[258:514])
 	} else {
 		encrypted = make([]byte, 512)
-		copy(encrypted, elg.a.Bytes())
-		copy(encrypted[256:], b)
+		elg.a.FillBytes(encrypted[:256])
+		b.FillBytes(encrypted[256:])
 	}
 
 	log.WithField("encrypted_length", len(encrypted)).Debug("Data encrypted successfully with ElGamal")
@@ -257,6 +266,18 @@ func (elg ElgPrivateKey) Len() int {
 	return len(elg)
 }
 
+// Bytes returns the key.
+func (elg ElgPrivateKey) Bytes() []byte {
+	return elg[:]
+}
+
+// Public returns the public key g^x mod p.
+func (elg ElgPrivateKey) Public() (PublicKey, error) {
+	var public ElgPublicKey
+	new(big.Int).Exp(elgg, new(big.Int).SetBytes(elg[:]), elgp).FillBytes(public[:])
+	return public, nil
+}
+
 func (elg ElgPrivateKey) NewDecrypter() (dec Decrypter, err error) {
 	log.Debug("Creating new ElGamal decrypter")
 	dec = &elgDecrypter{
@@ -0,0 +1,254 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"sync"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+	"golang.org/x/crypto/curve25519"
+)
+
+var ErrNotElligator2Encodable = errors.New("public key is not encodable with Elligator2")
+
+// curve25519A is the Montgomery curve coefficient A = 486662
+var curve25519A = new(field.Element).Mult32(new(field.Element).One(), 486662)
+
+// Elligator2Encode computes the Elligator2 representative of a Curve25519
+// public key (u-coordinate). About half of all public keys have a
+// representative, ErrNotElligator2Encodable is returned for the rest.
+// tweak supplies the two random high bits which are not part of the field
+// element and must be randomized so the representative is uniform.
+func Elligator2Encode(publicKey []byte, tweak byte) ([]byte, error) {
+	if len(publicKey) != curve25519.PointSize {
+		return nil, ErrInvalidPublicKeySize
+	}
+	u, err := new(field.Element).SetBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	// r = sqrt(-u / (2 * (u + A)))
+	num := new(field.Element).Negate(u)
+	den := new(field.Element).Add(u, curve25519A)
+	den.Add(den, den)
+	r, wasSquare := new(field.Element).SqrtRatio(num, den)
+	if wasSquare != 1 {
+		return nil, ErrNotElligator2Encodable
+	}
+	representative := r.Bytes()
+	if representative[31]&0x40 != 0 {
+		// use the root below 2^254 so the two high bits are free
+		representative = r.Negate(r).Bytes()
+	}
+	representative[31] |= tweak & 0xc0
+	return representative, nil
+}
+
+// Elligator2Decode maps a representative back to the Curve25519 public key
+// it encodes. The two high bits of the representative are ignored.
+func Elligator2Decode(representative []byte) ([]byte, error) {
+	if len(representative) != curve25519.PointSize {
+		return nil, ErrInvalidPublicKeySize
+	}
+	rb := make([]byte, curve25519.PointSize)
+	copy(rb, representative)
+	rb[31] &= 0x3f
+	r, err := new(field.Element).SetBytes(rb)
+	if err != nil {
+		return nil, err
+	}
+	one := new(field.Element).One()
+	// w = -A / (1 + 2r^2)
+	den := new(field.Element).Square(r)
+	den.Add(den, den)
+	den.Add(den, one)
+	w := new(field.Element).Invert(den)
+	w.Multiply(w, curve25519A)
+	w.Negate(w)
+	// e = w^3 + A*w^2 + w, if e is not square the point is -w - A
+	w2 := new(field.Element).Square(w)
+	e := new(field.Element).Multiply(w2, w)
+	e.Add(e, new(field.Element).Multiply(curve25519A, w2))
+	e.Add(e, w)
+	_, isSquare := new(field.Element).SqrtRatio(e, one)
+	alt := new(field.Element).Add(w, curve25519A)
+	alt.Negate(alt)
+	u := new(field.Element).Select(w, alt, isSquare)
+	return u.Bytes(), nil
+}
+
+// lowOrderPoints is the torsion subgroup of edwards25519, i*T for i 0 to 7
+// and a point T of order 8
+var lowOrderPoints = func() (points [8]*edwards25519.Point) {
+	encoded, _ := hex.DecodeString("26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05")
+	t, err := new(edwards25519.Point).SetBytes(encoded)
+	if err != nil {
+		panic(err)
+	}
+	points[0] = edwards25519.NewIdentityPoint()
+	for i := 1; i < len(points); i++ {
+		points[i] = new(edwards25519.Point).Add(points[i-1], t)
+	}
+	return
+}()
+
+// dirtyPublicKey returns the Curve25519 public key of priv with the low
+// order point lowOrder&7 added. X25519 clamps its scalar to a multiple of 8,
+// which cancels the low order component, so Diffie-Hellman results are those
+// of the plain public key. Without it every public key is in the prime order
+// subgroup, and representatives of such keys can be told apart from random
+// bytes by decoding them and checking the subgroup.
+func dirtyPublicKey(priv []byte, lowOrder byte) ([]byte, error) {
+	scalar, err := edwards25519.NewScalar().SetBytesWithClamping(priv)
+	if err != nil {
+		return nil, err
+	}
+	point := edwards25519.NewIdentityPoint().ScalarBaseMult(scalar)
+	point.Add(point, lowOrderPoints[lowOrder&7])
+	return point.BytesMontgomery(), nil
+}
+
+// Elligator2Key is a Curve25519 keypair whose public key has an Elligator2
+// representative.
+type Elligator2Key struct {
+	PrivateKey     []byte
+	PublicKey      []byte
+	Representative []byte
+}
+
+// GenerateElligator2Key generates Curve25519 keypairs from rand until one is
+// Elligator2-encodable. On average two attempts are required. The public key
+// has a random low order component, so its representative is
+// indistinguishable from random bytes, and Diffie-Hellman with it gives the
+// same results as with the plain public key.
+func GenerateElligator2Key(rand io.Reader) (*Elligator2Key, error) {
+	for {
+		priv := make([]byte, curve25519.ScalarSize)
+		if _, err := io.ReadFull(rand, priv); err != nil {
+			return nil, err
+		}
+		// the high bits tweak the representative, the low ones pick the
+		// low order component
+		var tweak [1]byte
+		if _, err := io.ReadFull(rand, tweak[:]); err != nil {
+			return nil, err
+		}
+		pub, err := dirtyPublicKey(priv, tweak[0])
+		if err != nil {
+			return nil, err
+		}
+		representative, err := Elligator2Encode(pub, tweak[0])
+		if err == ErrNotElligator2Encodable {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Elligator2Key{
+			PrivateKey:     priv,
+			PublicKey:      pub,
+			Representative: representative,
+		}, nil
+	}
+}
+
+// Elligator2KeyPool precomputes Elligator2-encodable keypairs in the
+// background so that handshakes do not pay for key generation and the
+// rejection sampling it requires.
+type Elligator2KeyPool struct {
+	keys  chan *Elligator2Key
+	done  chan struct{}
+	once  sync.Once
+	wg    sync.WaitGroup
+	rand  io.Reader
+	mutex sync.Mutex
+	err   error
+}
+
+// NewElligator2KeyPool creates a pool holding up to size keys and starts
+// filling it in the background. Call Close to stop the filler.
+func NewElligator2KeyPool(size int) *Elligator2KeyPool {
+	return newElligator2KeyPool(size, rand.Reader)
+}
+
+func newElligator2KeyPool(size int, random io.Reader) *Elligator2KeyPool {
+	if size < 1 {
+		size = 1
+	}
+	pool := &Elligator2KeyPool{
+		keys: make(chan *Elligator2Key, size),
+		done: make(chan struct{}),
+		rand: random,
+	}
+	pool.wg.Add(1)
+	go pool.fill()
+	log.WithField("size", size).Debug("Started Elligator2 key pool")
+	return pool
+}
+
+// fill generates keys until the pool is closed. A generation error stops the
+// filler, it is kept for Err and returned by Get once the pool runs dry.
+func (pool *Elligator2KeyPool) fill() {
+	defer pool.wg.Done()
+	for {
+		key, err := GenerateElligator2Key(pool.rand)
+		if err != nil {
+			log.WithError(err).Error("Failed to generate Elligator2 key, stopping key pool")
+			pool.mutex.Lock()
+			pool.err = err
+			pool.mutex.Unlock()
+			return
+		}
+		select {
+		case pool.keys <- key:
+		case <-pool.done:
+			return
+		}
+	}
+}
+
+// Err returns the error which stopped the background filler, or nil while it
+// is running.
+func (pool *Elligator2KeyPool) Err() error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	return pool.err
+}
+
+// Get returns a precomputed key, or generates one in place if the pool is
+// empty. Every key is handed out at most once. If the pool is empty because
+// the filler failed, the filler's error is returned along with any error from
+// generating in place.
+func (pool *Elligator2KeyPool) Get() (*Elligator2Key, error) {
+	select {
+	case key := <-pool.keys:
+		return key, nil
+	default:
+	}
+	if err := pool.Err(); err != nil {
+		key, genErr := GenerateElligator2Key(pool.rand)
+		if genErr != nil {
+			return nil, errors.Join(err, genErr)
+		}
+		return key, nil
+	}
+	log.Debug("Elligator2 key pool empty, generating key in place")
+	return GenerateElligator2Key(pool.rand)
+}
+
+// Len returns the number of keys currently available in the pool.
+func (pool *Elligator2KeyPool) Len() int {
+	return len(pool.keys)
+}
+
+// Close stops the background filler and waits for it to exit.
+func (pool *Elligator2KeyPool) Close() error {
+	pool.once.Do(func() {
+		close(pool.done)
+	})
+	pool.wg.Wait()
+	return nil
+}
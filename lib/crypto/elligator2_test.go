@@ -0,0 +1,148 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestElligator2RoundTrip(t *testing.T) {
+	for i := 0; i < 32; i++ {
+		key, err := GenerateElligator2Key(rand.Reader)
+		require.NoError(t, err)
+		decoded, err := Elligator2Decode(key.Representative)
+		require.NoError(t, err)
+		assert.Equal(t, key.PublicKey, decoded, "representative should decode to the public key")
+	}
+}
+
+func TestElligator2EncodeIgnoresTweakOnDecode(t *testing.T) {
+	key, err := GenerateElligator2Key(rand.Reader)
+	require.NoError(t, err)
+	plain, err := Elligator2Encode(key.PublicKey, 0)
+	require.NoError(t, err)
+	tweaked, err := Elligator2Encode(key.PublicKey, 0xc0)
+	require.NoError(t, err)
+	assert.Equal(t, plain[31]|0xc0, tweaked[31])
+	decoded, err := Elligator2Decode(tweaked)
+	require.NoError(t, err)
+	assert.Equal(t, key.PublicKey, decoded)
+}
+
+func TestElligator2KeyPool(t *testing.T) {
+	pool := NewElligator2KeyPool(4)
+	defer pool.Close()
+	seen := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		key, err := pool.Get()
+		require.NoError(t, err)
+		assert.False(t, seen[string(key.PrivateKey)], "pool handed out the same key twice")
+		seen[string(key.PrivateKey)] = true
+	}
+	assert.NoError(t, pool.Close())
+}
+
+func TestElligator2KeyPoolReportsFillerError(t *testing.T) {
+	failure := errors.New("no entropy")
+	pool := newElligator2KeyPool(4, iotest.ErrReader(failure))
+	defer pool.Close()
+	require.Eventually(t, func() bool { return pool.Err() != nil }, time.Second, time.Millisecond)
+	assert.ErrorIs(t, pool.Err(), failure)
+	_, err := pool.Get()
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestLowOrderPoints(t *testing.T) {
+	identity := edwards25519.NewIdentityPoint()
+	for i, point := range lowOrderPoints {
+		assert.Equal(t, i == 0, point.Equal(identity) == 1, "point %d", i)
+		times8 := new(edwards25519.Point).MultByCofactor(point)
+		assert.Equal(t, 1, times8.Equal(identity), "point %d is not of low order", i)
+	}
+	times4 := new(edwards25519.Point).Add(lowOrderPoints[2], lowOrderPoints[2])
+	assert.Equal(t, 0, times4.Equal(identity), "T is not of order 8")
+}
+
+func TestElligator2KeyAgreement(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		key, err := GenerateElligator2Key(rand.Reader)
+		require.NoError(t, err)
+		clean, err := curve25519.X25519(key.PrivateKey, curve25519.Basepoint)
+		require.NoError(t, err)
+
+		peer := make([]byte, curve25519.ScalarSize)
+		rand.Read(peer)
+		peerPublic, err := curve25519.X25519(peer, curve25519.Basepoint)
+		require.NoError(t, err)
+		ours, err := curve25519.X25519(key.PrivateKey, peerPublic)
+		require.NoError(t, err)
+		theirs, err := curve25519.X25519(peer, key.PublicKey)
+		require.NoError(t, err)
+		assert.Equal(t, ours, theirs)
+		withClean, err := curve25519.X25519(peer, clean)
+		require.NoError(t, err)
+		assert.Equal(t, withClean, theirs, "the low order component changed the shared secret")
+	}
+}
+
+// inPrimeOrderSubgroup reports whether the point with Montgomery coordinate u
+// is in the prime order subgroup, whose points all are multiples of the base
+// point. Such a point times the group order is the identity.
+func inPrimeOrderSubgroup(t *testing.T, u []byte) bool {
+	fu, err := new(field.Element).SetBytes(u)
+	require.NoError(t, err)
+	one := new(field.Element).One()
+	// y = (u - 1) / (u + 1)
+	y := new(field.Element).Subtract(fu, one)
+	y.Multiply(y, new(field.Element).Invert(new(field.Element).Add(fu, one)))
+	point, err := new(edwards25519.Point).SetBytes(y.Bytes())
+	require.NoError(t, err)
+	// L * P = (L - 1) * P + P
+	minusOne := edwards25519.NewScalar().Subtract(edwards25519.NewScalar(), scalarOne())
+	times := new(edwards25519.Point).ScalarMult(minusOne, point)
+	times.Add(times, point)
+	return times.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+func scalarOne() *edwards25519.Scalar {
+	one := make([]byte, 32)
+	one[0] = 1
+	s, _ := edwards25519.NewScalar().SetCanonicalBytes(one)
+	return s
+}
+
+func TestElligator2RepresentativeDistribution(t *testing.T) {
+	const samples = 512
+	var bits [256]int
+	inSubgroup := 0
+	for i := 0; i < samples; i++ {
+		key, err := GenerateElligator2Key(rand.Reader)
+		require.NoError(t, err)
+		for bit := range bits {
+			if key.Representative[bit/8]&(1<<(bit%8)) != 0 {
+				bits[bit]++
+			}
+		}
+		decoded, err := Elligator2Decode(key.Representative)
+		require.NoError(t, err)
+		if inPrimeOrderSubgroup(t, decoded) {
+			inSubgroup++
+		}
+	}
+	// every bit is set in half of the representatives, more than 5 standard
+	// deviations off is not random
+	for bit, count := range bits {
+		assert.InDelta(t, samples/2, count, 5*11.4, "bit %d", bit)
+	}
+	// only 1 in 8 random points is in the prime order subgroup, while
+	// public keys without a low order component always are
+	assert.InDelta(t, samples/8, inSubgroup, 5*7.5, "decoded keys in the prime order subgroup")
+}
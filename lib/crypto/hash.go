@@ -1,7 +0,0 @@
-package crypto
-
-import (
-	"crypto/sha256"
-)
-
-var SHA256 = sha256.Sum256
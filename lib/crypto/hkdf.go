@@ -0,0 +1,94 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// HKDF returns n bytes of HKDF-SHA256 output, as the NTCP2, SSU2 and ratchet
+// specs define it: keyed by salt, the chaining key or key of the spec, over
+// the input key material ikm and expanded with the label info. The first 32
+// bytes are
+//
+//	temp_key = HMAC-SHA256(salt, ikm)
+//	output1 = HMAC-SHA256(temp_key, info || 0x01)
+//
+// and every further 32 bytes HMAC-SHA256(temp_key, previous || info || i).
+// n may be at most 255*32.
+func HKDF(salt, ikm []byte, info string, n int) []byte {
+	out := make([]byte, n)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, []byte(info)), out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// HKDF2 returns the two 32 byte outputs of HKDF, the usual way the specs
+// derive a new chaining key and a key, or the keys of both directions.
+func HKDF2(salt, ikm []byte, info string) (first, second [32]byte) {
+	out := HKDF(salt, ikm, info, 64)
+	copy(first[:], out)
+	copy(second[:], out[32:])
+	return
+}
+
+// MixHash returns SHA256(h || data), the Noise MixHash of the handshake hash.
+func MixHash(h [32]byte, data ...[]byte) [32]byte {
+	hash := sha256.New()
+	hash.Write(h[:])
+	for _, d := range data {
+		hash.Write(d)
+	}
+	var mixed [32]byte
+	hash.Sum(mixed[:0])
+	return mixed
+}
+
+// MixKey returns the new chaining key and the cipher key derived from the
+// chaining key ck and a DH result, the Noise MixKey:
+//
+//	ck, k = HKDF(ck, ikm, "", 64)
+func MixKey(ck [32]byte, ikm []byte) (newCK, k [32]byte) {
+	return HKDF2(ck[:], ikm, "")
+}
+
+// SymmetricState is the Noise symmetric state of a handshake: the chaining
+// key and the handshake hash.
+type SymmetricState struct {
+	CK [32]byte
+	H  [32]byte
+}
+
+// NewSymmetricState initializes the state for the Noise protocol name, e.g.
+// "Noise_XKaesobfse+hs2+hs3_25519_ChaChaPoly_SHA256". A name of up to 32
+// bytes is used as the hash, padded with zeros, a longer one is hashed.
+func NewSymmetricState(protocolName string) *SymmetricState {
+	state := &SymmetricState{}
+	if len(protocolName) <= len(state.H) {
+		copy(state.H[:], protocolName)
+	} else {
+		state.H = sha256.Sum256([]byte(protocolName))
+	}
+	state.CK = state.H
+	return state
+}
+
+// MixHash mixes data into the handshake hash.
+func (state *SymmetricState) MixHash(data ...[]byte) {
+	state.H = MixHash(state.H, data...)
+}
+
+// MixKey mixes a DH result into the chaining key and returns the cipher
+// key derived with it.
+func (state *SymmetricState) MixKey(ikm []byte) (k [32]byte) {
+	state.CK, k = MixKey(state.CK, ikm)
+	return k
+}
+
+// Split returns the cipher keys of the data phase, for the initiator to
+// send and for the responder to send.
+func (state *SymmetricState) Split() (initiator, responder [32]byte) {
+	return HKDF2(state.CK[:], nil, "")
+}
@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 5869 test case 1
+func TestHKDFVector(t *testing.T) {
+	ikm, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	okm := HKDF(salt, ikm, string(info), 42)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865", hex.EncodeToString(okm))
+}
+
+func hmacSHA256(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// the two output HKDF as the I2P specs spell it out
+func TestHKDF2MatchesSpec(t *testing.T) {
+	ck := []byte("chaining key, 32 bytes long.....")
+	ikm := []byte("input key material")
+	tempKey := hmacSHA256(ck, ikm)
+	output1 := hmacSHA256(tempKey, []byte("label"), []byte{0x01})
+	output2 := hmacSHA256(tempKey, output1, []byte("label"), []byte{0x02})
+
+	first, second := HKDF2(ck, ikm, "label")
+	assert.Equal(t, output1, first[:])
+	assert.Equal(t, output2, second[:])
+}
+
+func TestSymmetricState(t *testing.T) {
+	short := NewSymmetricState("Noise_N_25519_ChaChaPoly_SHA256")
+	assert.Equal(t, "Noise_N_25519_ChaChaPoly_SHA256\x00", string(short.H[:]))
+	assert.Equal(t, short.H, short.CK)
+
+	name := "Noise_XKaesobfse+hs2+hs3_25519_ChaChaPoly_SHA256"
+	state := NewSymmetricState(name)
+	assert.Equal(t, sha256.Sum256([]byte(name)), state.H)
+
+	h := state.H
+	state.MixHash([]byte("a"), []byte("b"))
+	assert.Equal(t, sha256.Sum256(append(h[:], "ab"...)), state.H)
+
+	ck := state.CK
+	k := state.MixKey([]byte("dh result"))
+	newCK, expectedK := HKDF2(ck[:], []byte("dh result"), "")
+	assert.Equal(t, newCK, state.CK)
+	assert.Equal(t, expectedK, k)
+
+	initiator, responder := state.Split()
+	require.NotEqual(t, initiator, responder)
+	expected := HKDF(state.CK[:], nil, "", 64)
+	assert.Equal(t, expected[:32], initiator[:])
+	assert.Equal(t, expected[32:], responder[:])
+}
@@ -0,0 +1,135 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/openpgp/elgamal"
+)
+
+// Type codes of the keys built-in generators exist for: the signature types
+// of lib/common/signature and the crypto types of lib/common/key_certificate
+const (
+	SIGTYPE_DSA_SHA1              = 0
+	SIGTYPE_EDDSA_SHA512_ED25519  = 7
+	SIGTYPE_REDDSA_SHA512_ED25519 = 11
+	CRYPTOTYPE_ELGAMAL            = 0
+	CRYPTOTYPE_X25519             = 4
+)
+
+var ErrUnsupportedKeyType = errors.New("no key generator for key type")
+
+// EncryptionPrivateKey is the private half of a generated encryption key
+// pair.
+type EncryptionPrivateKey interface {
+	PrivateEncryptionKey
+	Len() int
+	// Bytes returns the key in the form I2P stores it
+	Bytes() []byte
+	// Public returns the public half of the pair
+	Public() (PublicKey, error)
+}
+
+// SigningKeyGenerator generates a private key of one signature type.
+type SigningKeyGenerator func() (SigningPrivateKey, error)
+
+// EncryptionKeyGenerator generates a private key of one crypto type.
+type EncryptionKeyGenerator func() (EncryptionPrivateKey, error)
+
+var generators = struct {
+	sync.RWMutex
+	signing    map[int]SigningKeyGenerator
+	encryption map[int]EncryptionKeyGenerator
+}{
+	signing:    make(map[int]SigningKeyGenerator),
+	encryption: make(map[int]EncryptionKeyGenerator),
+}
+
+// RegisterSigningKeyGenerator sets the generator of keys of signature type
+// sigType, replacing any registered before.
+func RegisterSigningKeyGenerator(sigType int, generate SigningKeyGenerator) {
+	generators.Lock()
+	defer generators.Unlock()
+	generators.signing[sigType] = generate
+}
+
+// RegisterEncryptionKeyGenerator sets the generator of keys of crypto type
+// cryptoType, replacing any registered before.
+func RegisterEncryptionKeyGenerator(cryptoType int, generate EncryptionKeyGenerator) {
+	generators.Lock()
+	defer generators.Unlock()
+	generators.encryption[cryptoType] = generate
+}
+
+// GenerateSigningKeyPair returns a new key pair of signature type sigType,
+// for router identities, destinations and tests alike.
+func GenerateSigningKeyPair(sigType int) (SigningPublicKey, SigningPrivateKey, error) {
+	generators.RLock()
+	generate, ok := generators.signing[sigType]
+	generators.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: signature type %d", ErrUnsupportedKeyType, sigType)
+	}
+	private, err := generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	public, err := private.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+	return public, private, nil
+}
+
+// GenerateEncryptionKeyPair returns a new key pair of crypto type
+// cryptoType.
+func GenerateEncryptionKeyPair(cryptoType int) (PublicKey, EncryptionPrivateKey, error) {
+	generators.RLock()
+	generate, ok := generators.encryption[cryptoType]
+	generators.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: crypto type %d", ErrUnsupportedKeyType, cryptoType)
+	}
+	private, err := generate()
+	if err != nil {
+		return nil, nil, err
+	}
+	public, err := private.Public()
+	if err != nil {
+		return nil, nil, err
+	}
+	return public, private, nil
+}
+
+func init() {
+	RegisterSigningKeyGenerator(SIGTYPE_DSA_SHA1, func() (SigningPrivateKey, error) {
+		var key DSAPrivateKey
+		return key.Generate()
+	})
+	RegisterSigningKeyGenerator(SIGTYPE_EDDSA_SHA512_ED25519, func() (SigningPrivateKey, error) {
+		var key Ed25519PrivateKey
+		return key.Generate()
+	})
+	RegisterSigningKeyGenerator(SIGTYPE_REDDSA_SHA512_ED25519, func() (SigningPrivateKey, error) {
+		var key RedDSAPrivateKey
+		return key.Generate()
+	})
+	RegisterEncryptionKeyGenerator(CRYPTOTYPE_ELGAMAL, func() (EncryptionPrivateKey, error) {
+		var elgKey elgamal.PrivateKey
+		if err := ElgamalGenerate(&elgKey, rand.Reader); err != nil {
+			return nil, err
+		}
+		var key ElgPrivateKey
+		elgKey.X.FillBytes(key[:])
+		return key, nil
+	})
+	RegisterEncryptionKeyGenerator(CRYPTOTYPE_X25519, func() (EncryptionPrivateKey, error) {
+		var key X25519PrivateKey
+		if _, err := rand.Read(key[:]); err != nil {
+			return nil, err
+		}
+		return key, nil
+	})
+}
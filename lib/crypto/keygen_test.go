@@ -0,0 +1,82 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSigningKeyPair(t *testing.T) {
+	publicSizes := map[int]int{
+		SIGTYPE_DSA_SHA1:              DSA_PUBLIC_KEY_SIZE,
+		SIGTYPE_EDDSA_SHA512_ED25519:  32,
+		SIGTYPE_REDDSA_SHA512_ED25519: 32,
+	}
+	for sigType, size := range publicSizes {
+		public, private, err := GenerateSigningKeyPair(sigType)
+		require.NoError(t, err)
+		assert.Equal(t, size, public.Len())
+
+		signer, err := private.NewSigner()
+		require.NoError(t, err)
+		sig, err := signer.Sign([]byte("message"))
+		require.NoError(t, err)
+		verifier, err := public.NewVerifier()
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify([]byte("message"), sig), "signature type %d", sigType)
+
+		_, other, err := GenerateSigningKeyPair(sigType)
+		require.NoError(t, err)
+		assert.NotEqual(t, private, other)
+	}
+}
+
+func TestGenerateEncryptionKeyPair(t *testing.T) {
+	public, private, err := GenerateEncryptionKeyPair(CRYPTOTYPE_X25519)
+	require.NoError(t, err)
+	assert.IsType(t, Curve25519PublicKey{}, public)
+	assert.Equal(t, 32, public.Len())
+	assert.Len(t, private.Bytes(), X25519_PRIVATE_KEY_SIZE)
+
+	public, private, err = GenerateEncryptionKeyPair(CRYPTOTYPE_ELGAMAL)
+	require.NoError(t, err)
+	assert.IsType(t, ElgPublicKey{}, public)
+	assert.Len(t, private.Bytes(), 256)
+
+	// the pair matches: what is encrypted to the public key decrypts
+	block := make([]byte, 222)
+	copy(block, "garlic")
+	encrypter, err := public.NewEncrypter()
+	require.NoError(t, err)
+	ciphertext, err := encrypter.Encrypt(block)
+	require.NoError(t, err)
+	decrypter, err := private.NewDecrypter()
+	require.NoError(t, err)
+	plaintext, err := decrypter.Decrypt(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, block, plaintext)
+}
+
+func TestGenerateKeyPairUnsupported(t *testing.T) {
+	_, _, err := GenerateSigningKeyPair(6)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+	_, _, err = GenerateEncryptionKeyPair(3)
+	assert.ErrorIs(t, err, ErrUnsupportedKeyType)
+}
+
+func TestRegisterSigningKeyGenerator(t *testing.T) {
+	const code = 65000
+	RegisterSigningKeyGenerator(code, func() (SigningPrivateKey, error) {
+		var key RedDSAPrivateKey
+		return key.Generate()
+	})
+	defer func() {
+		generators.Lock()
+		delete(generators.signing, code)
+		generators.Unlock()
+	}()
+	public, _, err := GenerateSigningKeyPair(code)
+	require.NoError(t, err)
+	assert.IsType(t, RedDSAPublicKey{}, public)
+}
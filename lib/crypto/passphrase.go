@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Private key files sealed with a passphrase are
+//
+//	magic || salt || nonce || ChaCha20-Poly1305(scrypt(passphrase, salt), plaintext)
+//
+// with the magic as associated data.
+const (
+	SEALED_MAGIC     = "go-i2p-sealed-1\n"
+	SEALED_SALT_SIZE = 16
+	// scrypt cost parameters, the interactive login setting of the scrypt
+	// paper
+	SEALED_SCRYPT_N = 1 << 15
+	SEALED_SCRYPT_R = 8
+	SEALED_SCRYPT_P = 1
+)
+
+var (
+	ErrEmptyPassphrase = errors.New("empty passphrase")
+	ErrNotSealed       = errors.New("data is not sealed with a passphrase")
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted sealed data")
+)
+
+// IsSealed reports whether data was sealed with SealWithPassphrase.
+func IsSealed(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(SEALED_MAGIC))
+}
+
+func passphraseKey(passphrase, salt []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, SEALED_SCRYPT_N, SEALED_SCRYPT_R, SEALED_SCRYPT_P, CHACHA20POLY1305_KEY_SIZE)
+}
+
+// SealWithPassphrase encrypts plaintext, normally a private key, under a key
+// derived from passphrase with a fresh salt.
+func SealWithPassphrase(passphrase, plaintext []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	header := make([]byte, len(SEALED_MAGIC)+SEALED_SALT_SIZE+CHACHA20POLY1305_NONCE_SIZE)
+	copy(header, SEALED_MAGIC)
+	if _, err := rand.Read(header[len(SEALED_MAGIC):]); err != nil {
+		return nil, err
+	}
+	salt := header[len(SEALED_MAGIC) : len(SEALED_MAGIC)+SEALED_SALT_SIZE]
+	nonce := header[len(SEALED_MAGIC)+SEALED_SALT_SIZE:]
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewChaCha20Poly1305(key)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := aead.Encrypt(nonce, plaintext, []byte(SEALED_MAGIC))
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+// OpenWithPassphrase decrypts data sealed with SealWithPassphrase.
+func OpenWithPassphrase(passphrase, sealed []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrEmptyPassphrase
+	}
+	headerSize := len(SEALED_MAGIC) + SEALED_SALT_SIZE + CHACHA20POLY1305_NONCE_SIZE
+	if !IsSealed(sealed) || len(sealed) < headerSize+CHACHA20POLY1305_TAG_SIZE {
+		return nil, ErrNotSealed
+	}
+	salt := sealed[len(SEALED_MAGIC) : len(SEALED_MAGIC)+SEALED_SALT_SIZE]
+	nonce := sealed[len(SEALED_MAGIC)+SEALED_SALT_SIZE : headerSize]
+	key, err := passphraseKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := NewChaCha20Poly1305(key)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Decrypt(nonce, sealed[headerSize:], []byte(SEALED_MAGIC))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
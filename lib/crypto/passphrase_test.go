@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealWithPassphraseRoundTrip(t *testing.T) {
+	plaintext := []byte("private key seed")
+	sealed, err := SealWithPassphrase([]byte("correct horse"), plaintext)
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.NotContains(t, string(sealed), string(plaintext))
+
+	opened, err := OpenWithPassphrase([]byte("correct horse"), sealed)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	again, err := SealWithPassphrase([]byte("correct horse"), plaintext)
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again, "every seal uses a fresh salt and nonce")
+}
+
+func TestOpenWithPassphraseRejects(t *testing.T) {
+	sealed, err := SealWithPassphrase([]byte("correct horse"), []byte("seed"))
+	require.NoError(t, err)
+
+	_, err = OpenWithPassphrase([]byte("battery staple"), sealed)
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 1
+	_, err = OpenWithPassphrase([]byte("correct horse"), tampered)
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	_, err = OpenWithPassphrase([]byte("correct horse"), []byte("seed"))
+	assert.ErrorIs(t, err, ErrNotSealed)
+
+	_, err = SealWithPassphrase(nil, []byte("seed"))
+	assert.ErrorIs(t, err, ErrEmptyPassphrase)
+}
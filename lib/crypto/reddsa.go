@@ -0,0 +1,177 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+
+	"filippo.io/edwards25519"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+RedDSA_SHA512_Ed25519, signature type 11, is Ed25519 with the private key a
+plain scalar instead of a hashed seed, so keys can be blinded by scalar
+multiplication. Signatures use a randomized nonce:
+
+	r = H*(T || A || M)  T 80 random bytes
+	R = r * B
+	S = r + H*(R || A || M) * a
+
+H* is SHA-512 reduced mod L. Signatures verify as plain Ed25519 signatures.
+
+https://geti2p.net/spec/red25519
+*/
+
+const (
+	REDDSA_PRIVATE_KEY_SIZE = 32
+	REDDSA_PUBLIC_KEY_SIZE  = 32
+	REDDSA_SIGNATURE_SIZE   = 64
+	// size of the random input to the nonce
+	reddsaNonceSize = 80
+)
+
+var ErrInvalidRedDSAKey = errors.New("invalid RedDSA key")
+
+// RedDSAPublicKey is a RedDSA public key, the point a * B.
+type RedDSAPublicKey []byte
+
+// NewVerifier returns a verifier for signatures by this key.
+func (k RedDSAPublicKey) NewVerifier() (Verifier, error) {
+	if len(k) != REDDSA_PUBLIC_KEY_SIZE {
+		return nil, ErrInvalidRedDSAKey
+	}
+	return &RedDSAVerifier{k: k}, nil
+}
+
+// Len returns the length of the key.
+func (k RedDSAPublicKey) Len() int {
+	return len(k)
+}
+
+// Bytes returns the key.
+func (k RedDSAPublicKey) Bytes() []byte {
+	return k
+}
+
+// RedDSAVerifier verifies RedDSA signatures.
+type RedDSAVerifier struct {
+	k []byte
+}
+
+// VerifyHash verifies sig over h. RedDSA does not pre-hash, h is verified as
+// the message.
+func (v *RedDSAVerifier) VerifyHash(h, sig []byte) error {
+	return v.Verify(h, sig)
+}
+
+// Verify verifies sig over data.
+func (v *RedDSAVerifier) Verify(data, sig []byte) error {
+	if len(sig) != REDDSA_SIGNATURE_SIZE {
+		return ErrBadSignatureSize
+	}
+	if !ed25519.Verify(v.k, data, sig) {
+		log.Warn("Invalid RedDSA signature")
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// RedDSAPrivateKey is a RedDSA private key, a little-endian scalar a reduced
+// mod L.
+type RedDSAPrivateKey []byte
+
+// scalar returns the key as a scalar
+func (k RedDSAPrivateKey) scalar() (*edwards25519.Scalar, error) {
+	if len(k) != REDDSA_PRIVATE_KEY_SIZE {
+		return nil, ErrInvalidRedDSAKey
+	}
+	a, err := edwards25519.NewScalar().SetCanonicalBytes(k)
+	if err != nil {
+		return nil, ErrInvalidRedDSAKey
+	}
+	return a, nil
+}
+
+// NewSigner returns a signer using this key.
+func (k RedDSAPrivateKey) NewSigner() (Signer, error) {
+	a, err := k.scalar()
+	if err != nil {
+		return nil, err
+	}
+	public := edwards25519.NewIdentityPoint().ScalarBaseMult(a).Bytes()
+	return &RedDSASigner{a: a, public: public}, nil
+}
+
+// Len returns the length of the key.
+func (k RedDSAPrivateKey) Len() int {
+	return len(k)
+}
+
+// Public returns the public key a * B.
+func (k RedDSAPrivateKey) Public() (SigningPublicKey, error) {
+	a, err := k.scalar()
+	if err != nil {
+		return nil, err
+	}
+	return RedDSAPublicKey(edwards25519.NewIdentityPoint().ScalarBaseMult(a).Bytes()), nil
+}
+
+// Generate replaces the key with a new random scalar.
+func (k *RedDSAPrivateKey) Generate() (SigningPrivateKey, error) {
+	var seed [64]byte
+	if _, err := rand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	a, err := edwards25519.NewScalar().SetUniformBytes(seed[:])
+	if err != nil {
+		return nil, err
+	}
+	*k = RedDSAPrivateKey(a.Bytes())
+	return k, nil
+}
+
+// RedDSASigner signs with a RedDSA private key.
+type RedDSASigner struct {
+	a      *edwards25519.Scalar
+	public []byte
+}
+
+// Sign signs data.
+func (s *RedDSASigner) Sign(data []byte) ([]byte, error) {
+	log.WithField("data_length", len(data)).Debug("Signing data with RedDSA")
+	var t [reddsaNonceSize]byte
+	if _, err := rand.Read(t[:]); err != nil {
+		return nil, err
+	}
+	r, err := reddsaHash(t[:], s.public, data)
+	if err != nil {
+		return nil, err
+	}
+	R := edwards25519.NewIdentityPoint().ScalarBaseMult(r).Bytes()
+	k, err := reddsaHash(R, s.public, data)
+	if err != nil {
+		return nil, err
+	}
+	S := edwards25519.NewScalar().MultiplyAdd(k, s.a, r)
+	sig := append(R, S.Bytes()...)
+	log.WithFields(logrus.Fields{
+		"signature_length": len(sig),
+	}).Debug("RedDSA signature created successfully")
+	return sig, nil
+}
+
+// SignHash signs h. RedDSA does not pre-hash, h is signed as the message.
+func (s *RedDSASigner) SignHash(h []byte) ([]byte, error) {
+	return s.Sign(h)
+}
+
+// reddsaHash returns H*(parts...)
+func reddsaHash(parts ...[]byte) (*edwards25519.Scalar, error) {
+	hash := sha512.New()
+	for _, part := range parts {
+		hash.Write(part)
+	}
+	return edwards25519.NewScalar().SetUniformBytes(hash.Sum(nil))
+}
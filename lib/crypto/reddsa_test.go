@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedDSASignVerify(t *testing.T) {
+	var private RedDSAPrivateKey
+	_, err := private.Generate()
+	require.NoError(t, err)
+	assert.Len(t, private, REDDSA_PRIVATE_KEY_SIZE)
+
+	public, err := private.Public()
+	require.NoError(t, err)
+	signer, err := private.NewSigner()
+	require.NoError(t, err)
+	verifier, err := public.NewVerifier()
+	require.NoError(t, err)
+
+	message := []byte("blinded leaseset")
+	sig, err := signer.Sign(message)
+	require.NoError(t, err)
+	assert.Len(t, sig, REDDSA_SIGNATURE_SIZE)
+	assert.NoError(t, verifier.Verify(message, sig))
+	// RedDSA signatures are valid Ed25519 signatures
+	assert.True(t, ed25519.Verify(public.Bytes(), message, sig))
+
+	// the nonce is random, two signatures differ
+	other, err := signer.Sign(message)
+	require.NoError(t, err)
+	assert.NotEqual(t, sig, other)
+
+	assert.ErrorIs(t, verifier.Verify([]byte("other"), sig), ErrInvalidSignature)
+	assert.ErrorIs(t, verifier.Verify(message, sig[:63]), ErrBadSignatureSize)
+}
+
+func TestRedDSARejectsNonCanonicalKey(t *testing.T) {
+	key := make(RedDSAPrivateKey, REDDSA_PRIVATE_KEY_SIZE)
+	for i := range key {
+		key[i] = 0xff
+	}
+	_, err := key.NewSigner()
+	assert.ErrorIs(t, err, ErrInvalidRedDSAKey)
+	_, err = RedDSAPrivateKey(key[:31]).Public()
+	assert.ErrorIs(t, err, ErrInvalidRedDSAKey)
+}
@@ -0,0 +1,30 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// vectors from the SipHash reference implementation, key 00..0f and
+// messages 00..len-1
+func TestSipHash24Vectors(t *testing.T) {
+	var key [SIPHASH_KEY_SIZE]byte
+	for i := range key {
+		key[i] = byte(i)
+	}
+	k0, k1 := SipHashKey(key)
+	msg := make([]byte, 16)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for length, expected := range map[int]uint64{
+		0:  0x726fdb47dd0e0e31,
+		1:  0x74f839c593dc67fd,
+		7:  0xab0200f58b01d137,
+		8:  0x93f5f5799a932462,
+		15: 0xa129ca6149be45e5,
+	} {
+		assert.Equal(t, expected, SipHash24(k0, k1, msg[:length]), "length %d", length)
+	}
+}
@@ -1,7 +1,6 @@
 package crypto
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
 )
 
@@ -13,17 +12,27 @@ type TunnelKey [32]byte
 // The initialization vector for a tunnel message
 type TunnelIV []byte
 
+// offsets of the IV and the encrypted data in a tunnel message, after the
+// 4 byte tunnel ID
+const (
+	tunnelIVStart   = 4
+	tunnelDataStart = tunnelIVStart + AES_BLOCK_SIZE
+)
+
+// Tunnel applies the layer encryption of one tunnel hop. The IV is
+// encrypted with the IV key before and after the data is encrypted with the
+// layer key, so IVs do not repeat from hop to hop.
 type Tunnel struct {
-	layerKey cipher.Block
+	layerKey CBCCipher
 	ivKey    cipher.Block
 }
 
 func NewTunnelCrypto(layerKey, ivKey TunnelKey) (t *Tunnel, err error) {
 	log.Debug("Creating new Tunnel crypto")
 	t = new(Tunnel)
-	t.layerKey, err = aes.NewCipher(layerKey[:])
+	t.layerKey, err = NewCBCCipher(layerKey[:])
 	if err == nil {
-		t.ivKey, err = aes.NewCipher(ivKey[:])
+		t.ivKey, err = NewAESCipher(ivKey[:])
 	}
 
 	if err != nil {
@@ -37,22 +46,29 @@ func NewTunnelCrypto(layerKey, ivKey TunnelKey) (t *Tunnel, err error) {
 }
 
 // encrypt tunnel data in place
-func (t *Tunnel) Encrypt(td *TunnelData) {
+func (t *Tunnel) Encrypt(td *TunnelData) error {
 	log.Debug("Encrypting Tunnel data")
-	data := *td
-	t.ivKey.Encrypt(data[16:1024], data[16:1024])
-	layerBlock := cipher.NewCBCEncrypter(t.layerKey, data[:16])
-	layerBlock.CryptBlocks(data[16:1024], data[16:1024])
-	t.ivKey.Encrypt(data[16:1024], data[16:1024])
+	iv := td[tunnelIVStart:tunnelDataStart]
+	data := td[tunnelDataStart:]
+	t.ivKey.Encrypt(iv, iv)
+	if err := t.layerKey.EncryptBlocks(data, data, iv); err != nil {
+		return err
+	}
+	t.ivKey.Encrypt(iv, iv)
 	log.Debug("Tunnel data encrypted successfully")
+	return nil
 }
 
-func (t *Tunnel) Decrypt(td *TunnelData) {
+// decrypt tunnel data in place, undoing Encrypt
+func (t *Tunnel) Decrypt(td *TunnelData) error {
 	log.Debug("Decrypting Tunnel data")
-	data := *td
-	t.ivKey.Decrypt(data[16:1024], data[16:1024])
-	layerBlock := cipher.NewCBCDecrypter(t.layerKey, data[:16])
-	layerBlock.CryptBlocks(data[16:1024], data[16:1024])
-	t.ivKey.Decrypt(data[16:1024], data[16:1024])
+	iv := td[tunnelIVStart:tunnelDataStart]
+	data := td[tunnelDataStart:]
+	t.ivKey.Decrypt(iv, iv)
+	if err := t.layerKey.DecryptBlocks(data, data, iv); err != nil {
+		return err
+	}
+	t.ivKey.Decrypt(iv, iv)
 	log.Debug("Tunnel data decrypted successfully")
+	return nil
 }
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// signatureSize returns the size of signatures by key, 0 if the key type
+// does not fix it
+func signatureSize(key SigningPublicKey) int {
+	switch key.(type) {
+	case DSAPublicKey, *DSAPublicKey:
+		return 40
+	case ECP256PublicKey, *ECP256PublicKey:
+		return 64
+	case ECP384PublicKey, *ECP384PublicKey:
+		return 96
+	case ECP521PublicKey, *ECP521PublicKey:
+		return 132
+	case Ed25519PublicKey, RedDSAPublicKey:
+		return 64
+	}
+	return 0
+}
+
+// VerifySignature verifies sig over data with the verifier of key, whatever
+// its type. Errors are normalized: ErrInvalidKeyFormat if no verifier can be
+// built from key, ErrBadSignatureSize if sig has the wrong size for the key
+// type and ErrInvalidSignature if it does not verify.
+func VerifySignature(key SigningPublicKey, data, sig []byte) error {
+	if key == nil {
+		return fmt.Errorf("%w: no signing public key", ErrInvalidKeyFormat)
+	}
+	if size := signatureSize(key); size != 0 && len(sig) != size {
+		return fmt.Errorf("%w: %d bytes, expected %d", ErrBadSignatureSize, len(sig), size)
+	}
+	verifier, err := key.NewVerifier()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidKeyFormat, err)
+	}
+	if err := verifier.Verify(data, sig); err != nil {
+		if errors.Is(err, ErrBadSignatureSize) || errors.Is(err, ErrInvalidSignature) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySignature(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := Ed25519PrivateKey(private).NewSigner()
+	require.NoError(t, err)
+	sig, err := signer.Sign([]byte("data"))
+	require.NoError(t, err)
+
+	key := Ed25519PublicKey(public)
+	assert.NoError(t, VerifySignature(key, []byte("data"), sig))
+	assert.ErrorIs(t, VerifySignature(key, []byte("other"), sig), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature(key, []byte("data"), sig[:40]), ErrBadSignatureSize)
+	assert.ErrorIs(t, VerifySignature(nil, []byte("data"), sig), ErrInvalidKeyFormat)
+
+	var red RedDSAPrivateKey
+	_, err = red.Generate()
+	require.NoError(t, err)
+	redSigner, err := red.NewSigner()
+	require.NoError(t, err)
+	redSig, err := redSigner.Sign([]byte("data"))
+	require.NoError(t, err)
+	redKey, err := red.Public()
+	require.NoError(t, err)
+	assert.NoError(t, VerifySignature(redKey, []byte("data"), redSig))
+	assert.ErrorIs(t, VerifySignature(redKey, []byte("data"), sig), ErrInvalidSignature)
+}
@@ -0,0 +1,39 @@
+package crypto
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// X25519_PRIVATE_KEY_SIZE is the size of an X25519 private key
+const X25519_PRIVATE_KEY_SIZE = curve25519.ScalarSize
+
+// X25519PrivateKey is the private key of the X25519 crypto type, used by
+// NTCP2, SSU2 and ECIES-X25519 for key agreement.
+type X25519PrivateKey [X25519_PRIVATE_KEY_SIZE]byte
+
+// Len returns the length of the key.
+func (k X25519PrivateKey) Len() int {
+	return len(k)
+}
+
+// Bytes returns the key.
+func (k X25519PrivateKey) Bytes() []byte {
+	return k[:]
+}
+
+// Public returns the public key k * basepoint.
+func (k X25519PrivateKey) Public() (PublicKey, error) {
+	public, err := curve25519.X25519(k[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return Curve25519PublicKey(public), nil
+}
+
+// NewDecrypter fails, X25519 keys agree on keys for ECIES instead of
+// decrypting.
+func (k X25519PrivateKey) NewDecrypter() (Decrypter, error) {
+	return nil, errors.New("x25519 private keys do not decrypt")
+}
@@ -0,0 +1,83 @@
+// Package datagram implements the I2P datagram formats carried in I2CP
+// payloads: legacy repliable datagrams, Datagram2, which authenticates the
+// sender with less overhead and binds each datagram to its target, and
+// Datagram3, which is repliable but unauthenticated.
+//
+// https://geti2p.net/spec/datagrams
+package datagram
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/go-i2p/go-i2p/lib/util/bloom"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Protocol is the I2CP protocol number a datagram format is sent under.
+type Protocol int
+
+const (
+	PROTOCOL_REPLIABLE Protocol = 17
+	PROTOCOL_RAW       Protocol = 18
+	PROTOCOL_DATAGRAM2 Protocol = 19
+	PROTOCOL_DATAGRAM3 Protocol = 20
+)
+
+// Datagram2 and Datagram3 flags, the low four bits are the version
+const (
+	FLAG_VERSION_MASK = 0x000f
+	FLAG_OPTIONS      = 0x0010
+	FLAG_OFFLINE      = 0x0020
+
+	DATAGRAM2_VERSION = 2
+	DATAGRAM3_VERSION = 3
+)
+
+var (
+	ErrDatagramTooShort   = errors.New("datagram too short")
+	ErrUnsupportedVersion = errors.New("unsupported datagram version")
+	ErrNoCommonProtocol   = errors.New("no datagram format supported by both sides")
+	ErrDatagramReplayed   = errors.New("datagram already received")
+)
+
+// Negotiate picks the datagram format of a session: the first protocol in
+// local, ordered by preference, the remote side also supports.
+func Negotiate(local, remote []Protocol) (Protocol, error) {
+	for _, protocol := range local {
+		for _, other := range remote {
+			if protocol == other {
+				log.WithField("protocol", protocol).Debug("Negotiated datagram format")
+				return protocol, nil
+			}
+		}
+	}
+	return 0, ErrNoCommonProtocol
+}
+
+// ReplayFilter drops datagrams received before. Datagram2 signatures cover
+// the target, so a datagram cannot be replayed to another destination, but
+// the receiving session still has to remember what it has seen.
+type ReplayFilter struct {
+	filter *bloom.DecayingFilter
+}
+
+// NewReplayFilter returns a filter remembering datagrams for at least
+// window, sized for expected datagrams per window.
+func NewReplayFilter(window time.Duration, expected int) *ReplayFilter {
+	return &ReplayFilter{filter: bloom.NewDecayingFilter(window, expected, 0.0001)}
+}
+
+// Check records a received datagram in wire form and returns
+// ErrDatagramReplayed if it was already seen.
+func (f *ReplayFilter) Check(datagram []byte) error {
+	hash := crypto.SHA256(datagram)
+	if f.filter.Add(hash[:]) {
+		log.Warn("Dropping replayed datagram")
+		return ErrDatagramReplayed
+	}
+	return nil
+}
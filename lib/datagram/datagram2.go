@@ -0,0 +1,221 @@
+package datagram
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/common/offline_signature"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+/*
+[Datagram2]
+
++----+----+----+----+----+----+----+----+
+~            from                       ~
++----+----+----+----+----+----+----+----+
+|  flags  |     options (optional)      ~
++----+----+----+----+----+----+----+----+
+~     offline_signature (optional)      ~
++----+----+----+----+----+----+----+----+
+~            payload                    ~
++----+----+----+----+----+----+----+----+
+~            signature                  ~
++----+----+----+----+----+----+----+----+
+
+from :: Destination of the sender
+
+flags :: 2 bytes, version 2 in the low four bits, FLAG_OPTIONS and
+         FLAG_OFFLINE
+
+signature :: by the sender, or by the transient key of the offline
+             signature, over the hash of the target destination followed
+             by flags, options, offline_signature and payload
+*/
+
+// Datagram2 is a datagram authenticated by its sender and bound to its
+// target destination.
+type Datagram2 struct {
+	From    destination.Destination
+	Options map[string]string
+	Offline *offline_signature.OfflineSignature
+	Payload []byte
+	// Signature is set by Sign
+	Signature []byte
+}
+
+// flags returns the flags field
+func (d *Datagram2) flags() uint16 {
+	flags := uint16(DATAGRAM2_VERSION)
+	if len(d.Options) > 0 {
+		flags |= FLAG_OPTIONS
+	}
+	if d.Offline != nil {
+		flags |= FLAG_OFFLINE
+	}
+	return flags
+}
+
+// body returns the fields after from, up to the signature
+func (d *Datagram2) body() ([]byte, error) {
+	body := binary.BigEndian.AppendUint16(nil, d.flags())
+	if len(d.Options) > 0 {
+		options, err := optionsBytes(d.Options)
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, options...)
+	}
+	if d.Offline != nil {
+		body = append(body, d.Offline.Bytes()...)
+	}
+	return append(body, d.Payload...), nil
+}
+
+// Sign signs the datagram for target with signer, the sender's signing key
+// or the transient key of its offline signature.
+func (d *Datagram2) Sign(signer crypto.Signer, target common.Hash) error {
+	body, err := d.body()
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(append(target[:], body...))
+	if err != nil {
+		return err
+	}
+	d.Signature = sig
+	return nil
+}
+
+// Verify checks that the datagram was signed by its sender for target. If it
+// carries an offline signature, that must be valid at now.
+func (d *Datagram2) Verify(target common.Hash, now time.Time) error {
+	key := d.From.SigningPublicKey()
+	if d.Offline != nil {
+		if err := d.Offline.Verify(key, now); err != nil {
+			return err
+		}
+		transient, err := d.Offline.TransientPublicKey()
+		if err != nil {
+			return err
+		}
+		key = transient
+	}
+	verifier, err := key.NewVerifier()
+	if err != nil {
+		return err
+	}
+	body, err := d.body()
+	if err != nil {
+		return err
+	}
+	if err := verifier.Verify(append(target[:], body...), d.Signature); err != nil {
+		log.WithError(err).Warn("Datagram2 signature does not verify")
+		return err
+	}
+	return nil
+}
+
+// Bytes returns the signed datagram in wire form.
+func (d *Datagram2) Bytes() ([]byte, error) {
+	body, err := d.body()
+	if err != nil {
+		return nil, err
+	}
+	data := append(d.From.KeysAndCert.Bytes(), body...)
+	return append(data, d.Signature...), nil
+}
+
+// ReadDatagram2 parses a Datagram2, which aliases data. The signature is not
+// checked, call Verify.
+func ReadDatagram2(data []byte) (*Datagram2, error) {
+	from, n, err := destination.ParseDestinationBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[n:]
+	d := &Datagram2{From: from}
+	flags, rest, err := readFlags(rest, DATAGRAM2_VERSION)
+	if err != nil {
+		return nil, err
+	}
+	if flags&FLAG_OPTIONS != 0 {
+		if d.Options, rest, err = readOptions(rest); err != nil {
+			return nil, err
+		}
+	}
+	sigType := from.KeyCertificate.SigningPublicKeyType()
+	if flags&FLAG_OFFLINE != 0 {
+		offline, n, err := offline_signature.ParseOfflineSignature(rest, sigType)
+		if err != nil {
+			return nil, err
+		}
+		d.Offline, rest = &offline, rest[n:]
+		sigType = offline.TransientType
+	}
+	sigSize, err := signature.SignatureSize(sigType)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < sigSize {
+		return nil, ErrDatagramTooShort
+	}
+	d.Payload = rest[:len(rest)-sigSize]
+	d.Signature = rest[len(rest)-sigSize:]
+	log.WithFields(logrus.Fields{
+		"payload_length": len(d.Payload),
+		"offline":        d.Offline != nil,
+	}).Debug("Read Datagram2")
+	return d, nil
+}
+
+// readFlags reads the flags field and checks the version
+func readFlags(data []byte, version uint16) (uint16, []byte, error) {
+	if len(data) < 2 {
+		return 0, nil, ErrDatagramTooShort
+	}
+	flags := binary.BigEndian.Uint16(data)
+	if flags&FLAG_VERSION_MASK != version {
+		return 0, nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, flags&FLAG_VERSION_MASK)
+	}
+	return flags, data[2:], nil
+}
+
+// optionsBytes returns options as a Mapping
+func optionsBytes(options map[string]string) ([]byte, error) {
+	mapping, err := common.GoMapToMapping(options)
+	if err != nil {
+		return nil, err
+	}
+	return mapping.Data(), nil
+}
+
+// readOptions reads a Mapping of options
+func readOptions(data []byte) (map[string]string, []byte, error) {
+	// ReadMapping warns about any data following the mapping, so only hand
+	// it the mapping itself
+	if len(data) < 2 {
+		return nil, nil, ErrDatagramTooShort
+	}
+	size := 2 + int(binary.BigEndian.Uint16(data))
+	if len(data) < size {
+		return nil, nil, ErrDatagramTooShort
+	}
+	mapping, _, err := common.ParseMapping(data[:size])
+	remainder := data[size:]
+	if err != nil {
+		return nil, nil, fmt.Errorf("datagram options: %w", err)
+	}
+	options := make(map[string]string)
+	for _, pair := range mapping.Values() {
+		key, _ := pair[0].Data()
+		value, _ := pair[1].Data()
+		options[key] = value
+	}
+	return options, remainder, nil
+}
@@ -0,0 +1,68 @@
+package datagram
+
+import (
+	"encoding/binary"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+/*
+[Datagram3]
+
++----+----+----+----+----+----+----+----+
+|            fromhash (32 bytes)        |
++----+----+----+----+----+----+----+----+
+|  flags  |     options (optional)      ~
++----+----+----+----+----+----+----+----+
+~            payload                    ~
++----+----+----+----+----+----+----+----+
+
+fromhash :: hash of the sender's Destination, for replies
+
+flags :: 2 bytes, version 3 in the low four bits and FLAG_OPTIONS
+*/
+
+// Datagram3 is a repliable datagram without a signature. The sender is not
+// authenticated, fromhash only says where replies go.
+type Datagram3 struct {
+	FromHash common.Hash
+	Options  map[string]string
+	Payload  []byte
+}
+
+// Bytes returns the datagram in wire form.
+func (d *Datagram3) Bytes() ([]byte, error) {
+	flags := uint16(DATAGRAM3_VERSION)
+	if len(d.Options) > 0 {
+		flags |= FLAG_OPTIONS
+	}
+	data := binary.BigEndian.AppendUint16(append([]byte(nil), d.FromHash[:]...), flags)
+	if len(d.Options) > 0 {
+		options, err := optionsBytes(d.Options)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, options...)
+	}
+	return append(data, d.Payload...), nil
+}
+
+// ReadDatagram3 parses a Datagram3, which aliases data.
+func ReadDatagram3(data []byte) (*Datagram3, error) {
+	if len(data) < len(common.Hash{}) {
+		return nil, ErrDatagramTooShort
+	}
+	d := &Datagram3{}
+	copy(d.FromHash[:], data)
+	flags, rest, err := readFlags(data[len(d.FromHash):], DATAGRAM3_VERSION)
+	if err != nil {
+		return nil, err
+	}
+	if flags&FLAG_OPTIONS != 0 {
+		if d.Options, rest, err = readOptions(rest); err != nil {
+			return nil, err
+		}
+	}
+	d.Payload = rest
+	return d, nil
+}
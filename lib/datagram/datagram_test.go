@@ -0,0 +1,144 @@
+package datagram
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	"github.com/go-i2p/go-i2p/lib/common/offline_signature"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDestination returns an ElGamal/Ed25519 destination and its signer
+func newTestDestination(t *testing.T) (destination.Destination, crypto.Signer) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyCert, err := key_certificate.BuildKeyCertificate(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_ELG, nil)
+	require.NoError(t, err)
+	var elg crypto.ElgPublicKey
+	_, err = rand.Read(elg[:])
+	require.NoError(t, err)
+	kac, err := keys_and_cert.NewKeysAndCert(keyCert, elg, make([]byte, 96), crypto.Ed25519PublicKey(public))
+	require.NoError(t, err)
+	signer, err := crypto.Ed25519PrivateKey(private).NewSigner()
+	require.NoError(t, err)
+
+	// round trip so the destination looks like one read off the wire
+	dest, _, err := destination.ReadDestination(kac.Bytes())
+	require.NoError(t, err)
+	return dest, signer
+}
+
+func TestNegotiate(t *testing.T) {
+	protocol, err := Negotiate(
+		[]Protocol{PROTOCOL_DATAGRAM2, PROTOCOL_REPLIABLE},
+		[]Protocol{PROTOCOL_REPLIABLE, PROTOCOL_DATAGRAM3, PROTOCOL_DATAGRAM2},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, PROTOCOL_DATAGRAM2, protocol)
+
+	// older peers only speak the legacy format
+	protocol, err = Negotiate([]Protocol{PROTOCOL_DATAGRAM2, PROTOCOL_REPLIABLE}, []Protocol{PROTOCOL_REPLIABLE})
+	require.NoError(t, err)
+	assert.Equal(t, PROTOCOL_REPLIABLE, protocol)
+
+	_, err = Negotiate([]Protocol{PROTOCOL_DATAGRAM3}, []Protocol{PROTOCOL_RAW})
+	assert.ErrorIs(t, err, ErrNoCommonProtocol)
+}
+
+func TestDatagram2RoundTrip(t *testing.T) {
+	from, signer := newTestDestination(t)
+	target := common.HashData([]byte("target"))
+	d := &Datagram2{
+		From:    from,
+		Options: map[string]string{"s": "1"},
+		Payload: []byte("hello"),
+	}
+	require.NoError(t, d.Sign(signer, target))
+	data, err := d.Bytes()
+	require.NoError(t, err)
+
+	read, err := ReadDatagram2(data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), read.Payload)
+	assert.Equal(t, map[string]string{"s": "1"}, read.Options)
+	assert.NoError(t, read.Verify(target, time.Now()))
+
+	// a datagram cannot be replayed to another destination
+	assert.Error(t, read.Verify(common.HashData([]byte("other")), time.Now()))
+
+	read.Payload[0] ^= 0xff
+	assert.Error(t, read.Verify(target, time.Now()))
+}
+
+func TestDatagram2Offline(t *testing.T) {
+	from, signer := newTestDestination(t)
+	transientPublic, transientPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	transientSigner, err := crypto.Ed25519PrivateKey(transientPrivate).NewSigner()
+	require.NoError(t, err)
+	now := time.Now()
+	offline, err := offline_signature.NewOfflineSignature(now.Add(time.Hour),
+		signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, transientPublic, signer)
+	require.NoError(t, err)
+
+	target := common.HashData([]byte("target"))
+	d := &Datagram2{From: from, Offline: offline, Payload: []byte("from a transient key")}
+	require.NoError(t, d.Sign(transientSigner, target))
+	data, err := d.Bytes()
+	require.NoError(t, err)
+
+	read, err := ReadDatagram2(data)
+	require.NoError(t, err)
+	require.NotNil(t, read.Offline)
+	assert.Equal(t, []byte("from a transient key"), read.Payload)
+	assert.NoError(t, read.Verify(target, now))
+	assert.ErrorIs(t, read.Verify(target, now.Add(2*time.Hour)), offline_signature.ErrOfflineSignatureExpired)
+}
+
+func TestDatagram3RoundTrip(t *testing.T) {
+	d := &Datagram3{
+		FromHash: common.HashData([]byte("sender")),
+		Payload:  []byte("unsigned"),
+	}
+	data, err := d.Bytes()
+	require.NoError(t, err)
+	assert.Len(t, data, 32+2+8)
+
+	read, err := ReadDatagram3(data)
+	require.NoError(t, err)
+	assert.Equal(t, d.FromHash, read.FromHash)
+	assert.Equal(t, d.Payload, read.Payload)
+	assert.Nil(t, read.Options)
+
+	// a Datagram2 version is rejected
+	data[33] = DATAGRAM2_VERSION
+	_, err = ReadDatagram3(data)
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestRepliableRoundTrip(t *testing.T) {
+	from, signer := newTestDestination(t)
+	d := &Repliable{From: from, Payload: []byte("legacy")}
+	require.NoError(t, d.Sign(signer))
+
+	read, err := ReadRepliable(d.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("legacy"), read.Payload)
+	assert.NoError(t, read.Verify())
+}
+
+func TestReplayFilter(t *testing.T) {
+	filter := NewReplayFilter(time.Minute, 100)
+	assert.NoError(t, filter.Check([]byte("one")))
+	assert.NoError(t, filter.Check([]byte("two")))
+	assert.ErrorIs(t, filter.Check([]byte("one")), ErrDatagramReplayed)
+}
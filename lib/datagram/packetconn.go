@@ -0,0 +1,350 @@
+package datagram
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DEFAULT_READ_QUEUE is how many received datagrams a PacketConn holds
+	// before dropping new ones, as a UDP socket buffer would
+	DEFAULT_READ_QUEUE = 64
+	// maxKnownDestinations bounds the destinations remembered for replies
+	maxKnownDestinations = 1024
+)
+
+var (
+	ErrInvalidAddr       = errors.New("not an I2P b32 address")
+	ErrUnsupportedFormat = errors.New("datagram format cannot be used by a PacketConn")
+	ErrReadQueueFull     = errors.New("datagram read queue full")
+	ErrConnClosed        = net.ErrClosed
+)
+
+// Addr is the address of a destination, shown as its b32 address.
+type Addr struct {
+	Hash common.Hash
+}
+
+// Network returns "i2p".
+func (Addr) Network() string {
+	return "i2p"
+}
+
+// String returns the b32 address.
+func (a Addr) String() string {
+	return a.Hash.Base32Address()
+}
+
+// ParseAddr parses a b32 address.
+func ParseAddr(address string) (Addr, error) {
+	if !strings.HasSuffix(strings.ToLower(address), common.B32_SUFFIX) {
+		return Addr{}, fmt.Errorf("%w: %q", ErrInvalidAddr, address)
+	}
+	hash, err := common.ParseHashBase32(address)
+	if err != nil {
+		return Addr{}, fmt.Errorf("%w: %q", ErrInvalidAddr, address)
+	}
+	return Addr{Hash: hash}, nil
+}
+
+// DestinationAddr returns the address of dest.
+func DestinationAddr(dest destination.Destination) Addr {
+	return Addr{Hash: dest.Hash()}
+}
+
+// Session is the datagram session under a PacketConn.
+type Session interface {
+	// Destination returns the local destination.
+	Destination() destination.Destination
+	// Signer signs with the local destination's signing key.
+	Signer() crypto.Signer
+	// Lookup resolves a destination by hash, for addresses no datagram
+	// was received from.
+	Lookup(hash common.Hash) (destination.Destination, error)
+	// Send sends a datagram in wire form to dest under protocol.
+	Send(dest destination.Destination, protocol Protocol, datagram []byte) error
+}
+
+type packet struct {
+	from    Addr
+	payload []byte
+}
+
+// PacketConn is a net.PacketConn over a datagram session, so code written
+// for UDP can run over I2P. Addresses are b32 addresses, Addr values or
+// anything whose String is one. Received datagrams are handed in by the
+// session through Deliver.
+type PacketConn struct {
+	session  Session
+	protocol Protocol
+	local    Addr
+	replay   *ReplayFilter
+
+	mutex sync.Mutex
+	// changed is closed and replaced whenever readers should re-check
+	changed       chan struct{}
+	queue         []packet
+	known         map[common.Hash]destination.Destination
+	readDeadline  time.Time
+	writeDeadline time.Time
+	closed        bool
+}
+
+var _ net.PacketConn = (*PacketConn)(nil)
+
+// NewPacketConn returns a PacketConn sending protocol, which must be one of
+// the repliable formats. Received datagrams of any of them are accepted.
+func NewPacketConn(session Session, protocol Protocol) (*PacketConn, error) {
+	switch protocol {
+	case PROTOCOL_REPLIABLE, PROTOCOL_DATAGRAM2, PROTOCOL_DATAGRAM3:
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormat, protocol)
+	}
+	return &PacketConn{
+		session:  session,
+		protocol: protocol,
+		local:    DestinationAddr(session.Destination()),
+		replay:   NewReplayFilter(10*time.Minute, 10000),
+		changed:  make(chan struct{}),
+		known:    make(map[common.Hash]destination.Destination),
+	}, nil
+}
+
+// wake wakes every waiter, the caller holds the mutex
+func (c *PacketConn) wake() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// remember records the destination of a sender for replies, the caller
+// holds the mutex
+func (c *PacketConn) remember(from destination.Destination) Addr {
+	addr := DestinationAddr(from)
+	if _, ok := c.known[addr.Hash]; !ok && len(c.known) >= maxKnownDestinations {
+		c.known = make(map[common.Hash]destination.Destination)
+	}
+	c.known[addr.Hash] = from
+	return addr
+}
+
+// Deliver hands a received datagram in wire form to the PacketConn. It is
+// checked and queued for ReadFrom, or dropped with an error if it does not
+// verify, was seen before or the queue is full. The caller may reuse data once
+// Deliver returns.
+func (c *PacketConn) Deliver(protocol Protocol, data []byte) error {
+	var (
+		from    *destination.Destination
+		addr    Addr
+		payload []byte
+	)
+	switch protocol {
+	case PROTOCOL_REPLIABLE:
+		d, err := ReadRepliable(data)
+		if err != nil {
+			return err
+		}
+		if err := d.Verify(); err != nil {
+			return err
+		}
+		from, payload = &d.From, d.Payload
+	case PROTOCOL_DATAGRAM2:
+		d, err := ReadDatagram2(data)
+		if err != nil {
+			return err
+		}
+		if err := d.Verify(c.local.Hash, time.Now()); err != nil {
+			return err
+		}
+		from, payload = &d.From, d.Payload
+	case PROTOCOL_DATAGRAM3:
+		d, err := ReadDatagram3(data)
+		if err != nil {
+			return err
+		}
+		addr, payload = Addr{Hash: d.FromHash}, d.Payload
+	default:
+		return fmt.Errorf("%w: %d", ErrUnsupportedFormat, protocol)
+	}
+	if err := c.replay.Check(data); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrConnClosed
+	}
+	if from != nil {
+		addr = c.remember(from.Clone())
+	}
+	if len(c.queue) >= DEFAULT_READ_QUEUE {
+		log.WithField("from", addr).Warn("PacketConn read queue full, dropping datagram")
+		return ErrReadQueueFull
+	}
+	// the payload is a slice of data
+	c.queue = append(c.queue, packet{from: addr, payload: append([]byte(nil), payload...)})
+	c.wake()
+	return nil
+}
+
+// ReadFrom reads the payload of the next datagram into p. As with UDP, a
+// payload longer than p is truncated.
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for {
+		if c.closed {
+			return 0, nil, ErrConnClosed
+		}
+		if len(c.queue) > 0 {
+			next := c.queue[0]
+			c.queue[0] = packet{}
+			c.queue = c.queue[1:]
+			return copy(p, next.payload), next.from, nil
+		}
+		if !c.wait(c.readDeadline) {
+			return 0, nil, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+// wait releases the mutex until woken or deadline passes, returning false
+// on the deadline
+func (c *PacketConn) wait(deadline time.Time) bool {
+	changed := c.changed
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return false
+		}
+		timer := time.NewTimer(remaining)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	c.mutex.Unlock()
+	defer c.mutex.Lock()
+	select {
+	case <-changed:
+		return true
+	case <-timeout:
+		return false
+	}
+}
+
+// WriteTo sends p as one datagram to addr. The session may block while
+// sending; the write deadline is only checked before the datagram is
+// handed to it.
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	to, err := ParseAddr(addr.String())
+	if err != nil {
+		return 0, err
+	}
+	c.mutex.Lock()
+	closed, deadline := c.closed, c.writeDeadline
+	dest, ok := c.known[to.Hash]
+	c.mutex.Unlock()
+	if closed {
+		return 0, ErrConnClosed
+	}
+	if !deadline.IsZero() && !time.Now().Before(deadline) {
+		return 0, os.ErrDeadlineExceeded
+	}
+	if !ok {
+		if dest, err = c.session.Lookup(to.Hash); err != nil {
+			return 0, err
+		}
+		c.mutex.Lock()
+		c.remember(dest)
+		c.mutex.Unlock()
+	}
+
+	datagram, err := c.format(p, to)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.session.Send(dest, c.protocol, datagram); err != nil {
+		return 0, err
+	}
+	log.WithFields(logrus.Fields{
+		"to":       to,
+		"protocol": c.protocol,
+		"length":   len(p),
+	}).Debug("PacketConn sent datagram")
+	return len(p), nil
+}
+
+// format builds the datagram for payload in the PacketConn's format
+func (c *PacketConn) format(payload []byte, to Addr) ([]byte, error) {
+	switch c.protocol {
+	case PROTOCOL_REPLIABLE:
+		d := &Repliable{From: c.session.Destination(), Payload: payload}
+		if err := d.Sign(c.session.Signer()); err != nil {
+			return nil, err
+		}
+		return d.Bytes(), nil
+	case PROTOCOL_DATAGRAM2:
+		d := &Datagram2{From: c.session.Destination(), Payload: payload}
+		if err := d.Sign(c.session.Signer(), to.Hash); err != nil {
+			return nil, err
+		}
+		return d.Bytes()
+	default:
+		d := &Datagram3{FromHash: c.local.Hash, Payload: payload}
+		return d.Bytes()
+	}
+}
+
+// Close closes the PacketConn, pending and later reads and writes fail.
+func (c *PacketConn) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return ErrConnClosed
+	}
+	c.closed = true
+	c.queue = nil
+	c.wake()
+	return nil
+}
+
+// LocalAddr returns the address of the local destination.
+func (c *PacketConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// SetDeadline sets the read and write deadlines.
+func (c *PacketConn) SetDeadline(t time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.wake()
+	return nil
+}
+
+// SetReadDeadline sets the deadline of pending and future ReadFrom calls.
+func (c *PacketConn) SetReadDeadline(t time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.readDeadline = t
+	c.wake()
+	return nil
+}
+
+// SetWriteDeadline sets the deadline of future WriteTo calls.
+func (c *PacketConn) SetWriteDeadline(t time.Time) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.writeDeadline = t
+	return nil
+}
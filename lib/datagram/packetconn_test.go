@@ -0,0 +1,130 @@
+package datagram
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopSession delivers sent datagrams straight to the peer's PacketConn
+type loopSession struct {
+	dest   destination.Destination
+	signer crypto.Signer
+	peers  map[common.Hash]destination.Destination
+	conns  map[common.Hash]*PacketConn
+}
+
+func (s *loopSession) Destination() destination.Destination { return s.dest }
+func (s *loopSession) Signer() crypto.Signer                { return s.signer }
+
+func (s *loopSession) Lookup(hash common.Hash) (destination.Destination, error) {
+	dest, ok := s.peers[hash]
+	if !ok {
+		return dest, errors.New("not found")
+	}
+	return dest, nil
+}
+
+func (s *loopSession) Send(dest destination.Destination, protocol Protocol, datagram []byte) error {
+	return s.conns[DestinationAddr(dest).Hash].Deliver(protocol, datagram)
+}
+
+func newConnPair(t *testing.T, protocol Protocol) (*PacketConn, *PacketConn) {
+	destA, signerA := newTestDestination(t)
+	destB, signerB := newTestDestination(t)
+	conns := make(map[common.Hash]*PacketConn)
+	peers := map[common.Hash]destination.Destination{
+		DestinationAddr(destA).Hash: destA,
+		DestinationAddr(destB).Hash: destB,
+	}
+	a, err := NewPacketConn(&loopSession{destA, signerA, peers, conns}, protocol)
+	require.NoError(t, err)
+	b, err := NewPacketConn(&loopSession{destB, signerB, peers, conns}, protocol)
+	require.NoError(t, err)
+	conns[a.local.Hash], conns[b.local.Hash] = a, b
+	return a, b
+}
+
+func TestAddrRoundTrip(t *testing.T) {
+	addr := Addr{Hash: common.HashData([]byte("destination"))}
+	assert.Len(t, addr.String(), 52+len(".b32.i2p"))
+	parsed, err := ParseAddr(addr.String())
+	require.NoError(t, err)
+	assert.Equal(t, addr, parsed)
+
+	_, err = ParseAddr("example.i2p")
+	assert.ErrorIs(t, err, ErrInvalidAddr)
+}
+
+func TestPacketConnExchange(t *testing.T) {
+	for _, protocol := range []Protocol{PROTOCOL_REPLIABLE, PROTOCOL_DATAGRAM2, PROTOCOL_DATAGRAM3} {
+		a, b := newConnPair(t, protocol)
+
+		n, err := a.WriteTo([]byte("ping"), b.LocalAddr())
+		require.NoError(t, err, "protocol %d", protocol)
+		assert.Equal(t, 4, n)
+
+		buf := make([]byte, 64)
+		n, from, err := b.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "ping", string(buf[:n]))
+		assert.Equal(t, a.LocalAddr().String(), from.String())
+
+		// reply to whatever address ReadFrom returned
+		_, err = b.WriteTo([]byte("pong"), from)
+		require.NoError(t, err)
+		n, _, err = a.ReadFrom(buf)
+		require.NoError(t, err)
+		assert.Equal(t, "pong", string(buf[:n]))
+	}
+}
+
+func TestPacketConnTruncatesAndDropsReplays(t *testing.T) {
+	a, b := newConnPair(t, PROTOCOL_DATAGRAM2)
+	d := &Datagram2{From: a.session.Destination(), Payload: []byte("longer than the buffer")}
+	require.NoError(t, d.Sign(a.session.Signer(), b.local.Hash))
+	data, err := d.Bytes()
+	require.NoError(t, err)
+
+	require.NoError(t, b.Deliver(PROTOCOL_DATAGRAM2, data))
+	assert.ErrorIs(t, b.Deliver(PROTOCOL_DATAGRAM2, data), ErrDatagramReplayed)
+
+	buf := make([]byte, 6)
+	n, _, err := b.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "longer", string(buf[:n]))
+}
+
+func TestPacketConnDeadlineAndClose(t *testing.T) {
+	_, b := newConnPair(t, PROTOCOL_DATAGRAM3)
+	require.NoError(t, b.SetReadDeadline(time.Now().Add(20*time.Millisecond)))
+	_, _, err := b.ReadFrom(make([]byte, 8))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+
+	require.NoError(t, b.SetReadDeadline(time.Time{}))
+	done := make(chan error)
+	go func() {
+		_, _, err := b.ReadFrom(make([]byte, 8))
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, b.Close())
+	assert.ErrorIs(t, <-done, net.ErrClosed)
+}
+
+func TestNewPacketConnRejectsRaw(t *testing.T) {
+	dest, signer := newTestDestination(t)
+	_, err := NewPacketConn(&loopSession{dest: dest, signer: signer}, PROTOCOL_RAW)
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,85 @@
+package datagram
+
+import (
+	"github.com/go-i2p/go-i2p/lib/common/destination"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+/*
+[Repliable Datagram]
+
++----+----+----+----+----+----+----+----+
+~            from                       ~
++----+----+----+----+----+----+----+----+
+~            signature                  ~
++----+----+----+----+----+----+----+----+
+~            payload                    ~
++----+----+----+----+----+----+----+----+
+
+signature :: by the sender over the payload, or over the SHA-256 hash of
+             the payload for DSA_SHA1
+*/
+
+// Repliable is a legacy repliable datagram.
+type Repliable struct {
+	From      destination.Destination
+	Signature []byte
+	Payload   []byte
+}
+
+// signedData returns what the signature covers
+func (d *Repliable) signedData() []byte {
+	if d.From.KeyCertificate.SigningPublicKeyType() == signature.SIGNATURE_TYPE_DSA_SHA1 {
+		hash := crypto.SHA256(d.Payload)
+		return hash[:]
+	}
+	return d.Payload
+}
+
+// Sign signs the datagram with the sender's signing key.
+func (d *Repliable) Sign(signer crypto.Signer) error {
+	sig, err := signer.Sign(d.signedData())
+	if err != nil {
+		return err
+	}
+	d.Signature = sig
+	return nil
+}
+
+// Verify checks the signature against the sender's signing key.
+func (d *Repliable) Verify() error {
+	verifier, err := d.From.SigningPublicKey().NewVerifier()
+	if err != nil {
+		return err
+	}
+	return verifier.Verify(d.signedData(), d.Signature)
+}
+
+// Bytes returns the datagram in wire form.
+func (d *Repliable) Bytes() []byte {
+	data := append(d.From.KeysAndCert.Bytes(), d.Signature...)
+	return append(data, d.Payload...)
+}
+
+// ReadRepliable parses a repliable datagram, which aliases data. The signature
+// is not checked, call Verify.
+func ReadRepliable(data []byte) (*Repliable, error) {
+	from, n, err := destination.ParseDestinationBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	rest := data[n:]
+	sigSize, err := signature.SignatureSize(from.KeyCertificate.SigningPublicKeyType())
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < sigSize {
+		return nil, ErrDatagramTooShort
+	}
+	return &Repliable{
+		From:      from,
+		Signature: rest[:sigSize],
+		Payload:   rest[sigSize:],
+	}, nil
+}
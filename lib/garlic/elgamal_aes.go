@@ -0,0 +1,233 @@
+// Package garlic implements ElGamal/AES+SessionTag, the legacy end-to-end
+// encryption of garlic messages. Older routers and ElGamal-only
+// destinations still use it.
+//
+// The first message to a destination is a new session message: the session
+// key is ElGamal encrypted to the destination's public key and followed by
+// an AES block holding the payload. The AES block can deliver session tags;
+// once their delivery is acknowledged, later messages start with one of the
+// tags instead of the ElGamal block, which is far cheaper to decrypt. Each
+// tag is used once.
+//
+// https://geti2p.net/spec/elgamal-aes
+package garlic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Sizes of the ElGamal/AES+SessionTag structures
+const (
+	SESSION_KEY_SIZE = 32
+	SESSION_TAG_SIZE = 32
+	// ELGAMAL_BLOCK_SIZE is the size of the ElGamal block of a new session
+	// message, and elgamalPlaintextSize what it encrypts: the session key,
+	// the pre-IV and random padding
+	ELGAMAL_BLOCK_SIZE   = 514
+	elgamalPlaintextSize = 222
+	preIVSize            = 32
+	// MAX_TAGS_PER_MESSAGE is the most tags one AES block may deliver
+	MAX_TAGS_PER_MESSAGE = 200
+)
+
+// flag of an AES block carrying a new session key
+const newSessionKeyFlag = 0x01
+
+var (
+	ErrMessageTooShort  = errors.New("garlic message too short")
+	ErrInvalidAESBlock  = errors.New("invalid ElGamal/AES block")
+	ErrTooManyTags      = errors.New("too many session tags for one message")
+	ErrUnknownTag       = errors.New("unknown session tag")
+	ErrDecryptionFailed = errors.New("garlic message decryption failed")
+)
+
+// SessionKey is the AES-256 key of a session.
+type SessionKey [SESSION_KEY_SIZE]byte
+
+// SessionTag identifies the session key of an existing session message.
+type SessionTag [SESSION_TAG_SIZE]byte
+
+// NewSessionKey returns a random session key.
+func NewSessionKey() (key SessionKey, err error) {
+	_, err = rand.Read(key[:])
+	return
+}
+
+// newSessionTags returns count random session tags
+func newSessionTags(count int) ([]SessionTag, error) {
+	tags := make([]SessionTag, count)
+	for i := range tags {
+		if _, err := rand.Read(tags[i][:]); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// aesBlock is the decrypted content of the AES block of a message
+type aesBlock struct {
+	Tags    []SessionTag
+	Payload []byte
+	// NewKey replaces the session key for the tags delivered, if set
+	NewKey *SessionKey
+}
+
+// Bytes serializes the block, padded to a multiple of 16 bytes with random
+// bytes:
+//
+//	tag count (2) | tags (32 each) | payload size (4) | SHA256 of payload (32) |
+//	flag (1) | new session key (32, if flag is 1) | payload | padding
+func (block aesBlock) Bytes() ([]byte, error) {
+	if len(block.Tags) > MAX_TAGS_PER_MESSAGE {
+		return nil, ErrTooManyTags
+	}
+	var buffer bytes.Buffer
+	buffer.Write(binary.BigEndian.AppendUint16(nil, uint16(len(block.Tags))))
+	for _, tag := range block.Tags {
+		buffer.Write(tag[:])
+	}
+	buffer.Write(binary.BigEndian.AppendUint32(nil, uint32(len(block.Payload))))
+	hash := sha256.Sum256(block.Payload)
+	buffer.Write(hash[:])
+	if block.NewKey != nil {
+		buffer.WriteByte(newSessionKeyFlag)
+		buffer.Write(block.NewKey[:])
+	} else {
+		buffer.WriteByte(0)
+	}
+	buffer.Write(block.Payload)
+	padding := make([]byte, (crypto.AES_BLOCK_SIZE-buffer.Len()%crypto.AES_BLOCK_SIZE)%crypto.AES_BLOCK_SIZE)
+	if _, err := rand.Read(padding); err != nil {
+		return nil, err
+	}
+	buffer.Write(padding)
+	return buffer.Bytes(), nil
+}
+
+// readAESBlock parses a decrypted AES block, checking the payload hash
+func readAESBlock(data []byte) (block aesBlock, err error) {
+	if len(data) < 2 {
+		return block, ErrInvalidAESBlock
+	}
+	count := int(binary.BigEndian.Uint16(data))
+	data = data[2:]
+	if count > MAX_TAGS_PER_MESSAGE || len(data) < count*SESSION_TAG_SIZE+4+32+1 {
+		return block, ErrInvalidAESBlock
+	}
+	block.Tags = make([]SessionTag, count)
+	for i := range block.Tags {
+		copy(block.Tags[i][:], data)
+		data = data[SESSION_TAG_SIZE:]
+	}
+	size := binary.BigEndian.Uint32(data)
+	hash := data[4:36]
+	flag := data[36]
+	data = data[37:]
+	if flag == newSessionKeyFlag {
+		if len(data) < SESSION_KEY_SIZE {
+			return block, ErrInvalidAESBlock
+		}
+		key := SessionKey{}
+		copy(key[:], data)
+		block.NewKey = &key
+		data = data[SESSION_KEY_SIZE:]
+	}
+	if uint64(size) > uint64(len(data)) {
+		return block, ErrInvalidAESBlock
+	}
+	block.Payload = data[:size]
+	if sum := sha256.Sum256(block.Payload); !bytes.Equal(sum[:], hash) {
+		return block, ErrInvalidAESBlock
+	}
+	return block, nil
+}
+
+// ivOf returns the AES IV of a message: the start of the SHA256 of its tag
+// or pre-IV
+func ivOf(seed []byte) []byte {
+	sum := sha256.Sum256(seed)
+	return sum[:crypto.AES_BLOCK_SIZE]
+}
+
+// EncryptNewSession encrypts a new session message to target, ElGamal
+// encrypting key, and delivering tags with the payload.
+func EncryptNewSession(target crypto.ElgPublicKey, key SessionKey, payload []byte, tags []SessionTag, newKey *SessionKey) ([]byte, error) {
+	plaintext := make([]byte, elgamalPlaintextSize)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+	copy(plaintext, key[:])
+	preIV := plaintext[SESSION_KEY_SIZE : SESSION_KEY_SIZE+preIVSize]
+	encrypter, err := target.NewEncrypter()
+	if err != nil {
+		return nil, err
+	}
+	elgamalBlock, err := encrypter.Encrypt(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptAESBlock(key, ivOf(preIV), aesBlock{Tags: tags, Payload: payload, NewKey: newKey})
+	if err != nil {
+		return nil, err
+	}
+	return append(elgamalBlock, encrypted...), nil
+}
+
+// EncryptExistingSession encrypts an existing session message, starting
+// with tag, under the key the tag was delivered with.
+func EncryptExistingSession(tag SessionTag, key SessionKey, payload []byte, tags []SessionTag, newKey *SessionKey) ([]byte, error) {
+	encrypted, err := encryptAESBlock(key, ivOf(tag[:]), aesBlock{Tags: tags, Payload: payload, NewKey: newKey})
+	if err != nil {
+		return nil, err
+	}
+	return append(tag[:], encrypted...), nil
+}
+
+func encryptAESBlock(key SessionKey, iv []byte, block aesBlock) ([]byte, error) {
+	plaintext, err := block.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.AES256CBCEncrypt(key[:], iv, plaintext)
+}
+
+func decryptAESBlock(key SessionKey, iv, data []byte) (aesBlock, error) {
+	if len(data) == 0 || len(data)%crypto.AES_BLOCK_SIZE != 0 {
+		return aesBlock{}, ErrInvalidAESBlock
+	}
+	plaintext, err := crypto.AES256CBCDecrypt(key[:], iv, data)
+	if err != nil {
+		return aesBlock{}, err
+	}
+	return readAESBlock(plaintext)
+}
+
+// decryptNewSession decrypts a new session message with the private key,
+// returning the session key it carried and its AES block
+func decryptNewSession(private crypto.ElgPrivateKey, data []byte) (SessionKey, aesBlock, error) {
+	var key SessionKey
+	if len(data) < ELGAMAL_BLOCK_SIZE+crypto.AES_BLOCK_SIZE {
+		return key, aesBlock{}, ErrMessageTooShort
+	}
+	decrypter, err := private.NewDecrypter()
+	if err != nil {
+		return key, aesBlock{}, err
+	}
+	plaintext, err := decrypter.Decrypt(data[:ELGAMAL_BLOCK_SIZE])
+	if err != nil {
+		return key, aesBlock{}, ErrDecryptionFailed
+	}
+	copy(key[:], plaintext)
+	preIV := plaintext[SESSION_KEY_SIZE : SESSION_KEY_SIZE+preIVSize]
+	block, err := decryptAESBlock(key, ivOf(preIV), data[ELGAMAL_BLOCK_SIZE:])
+	return key, block, err
+}
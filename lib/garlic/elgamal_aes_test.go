@@ -0,0 +1,84 @@
+package garlic
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp/elgamal"
+)
+
+func testElgKeys(t *testing.T) (crypto.ElgPublicKey, crypto.ElgPrivateKey) {
+	private := new(elgamal.PrivateKey)
+	require.NoError(t, crypto.ElgamalGenerate(private, rand.Reader))
+	var pub crypto.ElgPublicKey
+	var priv crypto.ElgPrivateKey
+	private.Y.FillBytes(pub[:])
+	private.X.FillBytes(priv[:])
+	return pub, priv
+}
+
+func TestAESBlockRoundTrip(t *testing.T) {
+	tags, err := newSessionTags(3)
+	require.NoError(t, err)
+	newKey, err := NewSessionKey()
+	require.NoError(t, err)
+
+	for _, block := range []aesBlock{
+		{Payload: []byte("payload")},
+		{Tags: tags, Payload: []byte{}, NewKey: &newKey},
+		{Tags: tags, Payload: make([]byte, 1000)},
+	} {
+		data, err := block.Bytes()
+		require.NoError(t, err)
+		assert.Zero(t, len(data)%crypto.AES_BLOCK_SIZE)
+		parsed, err := readAESBlock(data)
+		require.NoError(t, err)
+		assert.Equal(t, block.Payload, parsed.Payload)
+		assert.Equal(t, len(block.Tags), len(parsed.Tags))
+		assert.Equal(t, block.NewKey, parsed.NewKey)
+	}
+
+	data, err := aesBlock{Payload: []byte("payload")}.Bytes()
+	require.NoError(t, err)
+	data[2+4+32+1] ^= 1
+	_, err = readAESBlock(data)
+	assert.ErrorIs(t, err, ErrInvalidAESBlock, "the payload hash is checked")
+	_, err = readAESBlock(data[:10])
+	assert.ErrorIs(t, err, ErrInvalidAESBlock)
+
+	_, err = aesBlock{Tags: make([]SessionTag, MAX_TAGS_PER_MESSAGE+1)}.Bytes()
+	assert.ErrorIs(t, err, ErrTooManyTags)
+}
+
+func TestNewAndExistingSessionMessages(t *testing.T) {
+	pub, priv := testElgKeys(t)
+	key, err := NewSessionKey()
+	require.NoError(t, err)
+	tags, err := newSessionTags(2)
+	require.NoError(t, err)
+
+	data, err := EncryptNewSession(pub, key, []byte("hello"), tags, nil)
+	require.NoError(t, err)
+	decryptedKey, block, err := decryptNewSession(priv, data)
+	require.NoError(t, err)
+	assert.Equal(t, key, decryptedKey)
+	assert.Equal(t, []byte("hello"), block.Payload)
+	assert.Equal(t, tags, block.Tags)
+
+	data, err = EncryptExistingSession(tags[0], key, []byte("again"), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tags[0][:], data[:SESSION_TAG_SIZE])
+	block, err = decryptAESBlock(key, ivOf(tags[0][:]), data[SESSION_TAG_SIZE:])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("again"), block.Payload)
+
+	// another private key
+	_, other := testElgKeys(t)
+	data, err = EncryptNewSession(pub, key, []byte("hello"), nil, nil)
+	require.NoError(t, err)
+	_, _, err = decryptNewSession(other, data)
+	assert.Error(t, err)
+}
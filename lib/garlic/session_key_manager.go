@@ -0,0 +1,342 @@
+package garlic
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// Tag windows and lifetimes, as in the Java router
+const (
+	// TAGS_PER_DELIVERY is how many tags a message delivers when the
+	// session runs low
+	TAGS_PER_DELIVERY = 40
+	// TAG_LOW_THRESHOLD is the number of usable tags below which new ones
+	// are delivered
+	TAG_LOW_THRESHOLD = 30
+	// OUTBOUND_TAG_LIFETIME is how long tags we delivered are used. It is
+	// shorter than INBOUND_TAG_LIFETIME so the far end never discards a tag
+	// before we stop sending it.
+	OUTBOUND_TAG_LIFETIME = 12 * time.Minute
+	// INBOUND_TAG_LIFETIME is how long tags delivered to us are accepted
+	INBOUND_TAG_LIFETIME = 15 * time.Minute
+	// MAX_INBOUND_TAGS limits the tags kept for all inbound sessions, so
+	// peers cannot exhaust memory by delivering tags
+	MAX_INBOUND_TAGS = 750000
+)
+
+// TagSet is a set of tags delivered in one message. Its tags are used once
+// the delivery is acknowledged with TagsAcked.
+type TagSet struct {
+	Key     SessionKey
+	Tags    []SessionTag
+	Created time.Time
+	acked   bool
+	next    int
+}
+
+// remaining returns the tags of the set not used yet
+func (set *TagSet) remaining() int {
+	return len(set.Tags) - set.next
+}
+
+// expired reports whether the set may no longer be used at now
+func (set *TagSet) expired(now time.Time) bool {
+	return !now.Before(set.Created.Add(OUTBOUND_TAG_LIFETIME))
+}
+
+// outboundSession is what we keep for a remote destination we send to
+type outboundSession struct {
+	key      SessionKey
+	lastUsed time.Time
+	tagSets  []*TagSet
+}
+
+// inboundTag is a tag delivered to us and the key its messages use
+type inboundTag struct {
+	key     SessionKey
+	expires time.Time
+}
+
+// SessionKeyManager keeps the session keys and tags of ElGamal/AES+SessionTag
+// sessions, in both directions, and encrypts and decrypts garlic messages
+// with them. It is safe for concurrent use.
+type SessionKeyManager struct {
+	mutex    sync.Mutex
+	outbound map[crypto.ElgPublicKey]*outboundSession
+	inbound  map[SessionTag]inboundTag
+}
+
+// NewSessionKeyManager returns an empty manager.
+func NewSessionKeyManager() *SessionKeyManager {
+	return &SessionKeyManager{
+		outbound: make(map[crypto.ElgPublicKey]*outboundSession),
+		inbound:  make(map[SessionTag]inboundTag),
+	}
+}
+
+// session returns the outbound session to target, creating it if needed.
+// Callers must hold the lock.
+func (manager *SessionKeyManager) session(target crypto.ElgPublicKey, now time.Time) (*outboundSession, error) {
+	session, ok := manager.outbound[target]
+	if ok && now.Before(session.lastUsed.Add(OUTBOUND_TAG_LIFETIME)) {
+		return session, nil
+	}
+	key, err := NewSessionKey()
+	if err != nil {
+		return nil, err
+	}
+	session = &outboundSession{key: key, lastUsed: now}
+	manager.outbound[target] = session
+	log.Debug("Created ElGamal/AES outbound session")
+	return session, nil
+}
+
+// Encrypt encrypts payload to the destination with the ElGamal public key
+// target. It uses an acknowledged tag if one is left, and ElGamal otherwise.
+// When the session runs low on tags, the message delivers new ones and the
+// TagSet returned is non-nil: pass it to TagsAcked once the message is
+// confirmed delivered, or to TagsFailed if it was lost.
+func (manager *SessionKeyManager) Encrypt(target crypto.ElgPublicKey, payload []byte, now time.Time) ([]byte, *TagSet, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	session, err := manager.session(target, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	session.lastUsed = now
+
+	var delivered *TagSet
+	if session.usableTags(now) < TAG_LOW_THRESHOLD && !session.pending(now) {
+		tags, err := newSessionTags(TAGS_PER_DELIVERY)
+		if err != nil {
+			return nil, nil, err
+		}
+		delivered = &TagSet{Key: session.key, Tags: tags, Created: now}
+		session.tagSets = append(session.tagSets, delivered)
+	}
+	var tags []SessionTag
+	if delivered != nil {
+		tags = delivered.Tags
+	}
+
+	if tag, key, ok := session.consumeTag(now); ok {
+		data, err := EncryptExistingSession(tag, key, payload, tags, nil)
+		return data, delivered, err
+	}
+	data, err := EncryptNewSession(target, session.key, payload, tags, nil)
+	return data, delivered, err
+}
+
+// usableTags counts the acknowledged, unexpired tags left
+func (session *outboundSession) usableTags(now time.Time) int {
+	count := 0
+	for _, set := range session.tagSets {
+		if set.acked && !set.expired(now) {
+			count += set.remaining()
+		}
+	}
+	return count
+}
+
+// pending reports whether a delivery of tags awaits its acknowledgement
+func (session *outboundSession) pending(now time.Time) bool {
+	for _, set := range session.tagSets {
+		if !set.acked && !set.expired(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeTag takes the next acknowledged tag, dropping used up and expired
+// sets
+func (session *outboundSession) consumeTag(now time.Time) (SessionTag, SessionKey, bool) {
+	sets := session.tagSets[:0]
+	var tag SessionTag
+	var key SessionKey
+	found := false
+	for _, set := range session.tagSets {
+		if set.expired(now) {
+			continue
+		}
+		if !found && set.acked && set.remaining() > 0 {
+			tag, key, found = set.Tags[set.next], set.Key, true
+			set.next++
+		}
+		if set.remaining() > 0 || !set.acked {
+			sets = append(sets, set)
+		}
+	}
+	session.tagSets = sets
+	return tag, key, found
+}
+
+// TagsAcked marks the tags delivered to target in set as usable.
+func (manager *SessionKeyManager) TagsAcked(target crypto.ElgPublicKey, set *TagSet) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	set.acked = true
+}
+
+// TagsFailed forgets the tags of set, whose delivery to target failed. If
+// no tag of the session was ever acknowledged the session is dropped, so
+// the next message starts a new one.
+func (manager *SessionKeyManager) TagsFailed(target crypto.ElgPublicKey, set *TagSet) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	session, ok := manager.outbound[target]
+	if !ok {
+		return
+	}
+	sets := session.tagSets[:0]
+	acked := false
+	for _, s := range session.tagSets {
+		if s != set {
+			sets = append(sets, s)
+			acked = acked || s.acked
+		}
+	}
+	session.tagSets = sets
+	if !acked {
+		delete(manager.outbound, target)
+	}
+}
+
+// TagsReceived stores tags delivered to us, which mark messages encrypted
+// with key.
+func (manager *SessionKeyManager) TagsReceived(key SessionKey, tags []SessionTag, now time.Time) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	if len(manager.inbound)+len(tags) > MAX_INBOUND_TAGS {
+		manager.expireInbound(now)
+		if len(manager.inbound)+len(tags) > MAX_INBOUND_TAGS {
+			log.WithField("tags", len(tags)).Warn("Too many inbound session tags, dropping delivered tags")
+			return
+		}
+	}
+	expires := now.Add(INBOUND_TAG_LIFETIME)
+	for _, tag := range tags {
+		manager.inbound[tag] = inboundTag{key: key, expires: expires}
+	}
+}
+
+// consumeInboundTag returns the key of a tag delivered to us and forgets
+// the tag, which is used once
+func (manager *SessionKeyManager) consumeInboundTag(tag SessionTag, now time.Time) (SessionKey, bool) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	inbound, ok := manager.inbound[tag]
+	if !ok {
+		return SessionKey{}, false
+	}
+	delete(manager.inbound, tag)
+	if !now.Before(inbound.expires) {
+		return SessionKey{}, false
+	}
+	return inbound.key, true
+}
+
+// Decrypt decrypts a garlic message sent to the destination with the
+// ElGamal private key. A message starting with a tag delivered to us is
+// decrypted with its session key, any other is taken for a new session
+// message. Tags delivered by the message are stored.
+func (manager *SessionKeyManager) Decrypt(private crypto.ElgPrivateKey, data []byte, now time.Time) ([]byte, error) {
+	if len(data) < SESSION_TAG_SIZE+crypto.AES_BLOCK_SIZE {
+		return nil, ErrMessageTooShort
+	}
+	var tag SessionTag
+	copy(tag[:], data)
+	var block aesBlock
+	key, ok := manager.consumeInboundTag(tag, now)
+	if ok {
+		var err error
+		block, err = decryptAESBlock(key, ivOf(tag[:]), data[SESSION_TAG_SIZE:])
+		if err != nil {
+			log.WithError(err).Debug("Failed to decrypt existing session message")
+			return nil, ErrDecryptionFailed
+		}
+	} else {
+		var err error
+		key, block, err = decryptNewSession(private, data)
+		if err != nil {
+			log.WithError(err).Debug("Failed to decrypt new session message")
+			return nil, ErrDecryptionFailed
+		}
+	}
+	if len(block.Tags) > 0 {
+		if block.NewKey != nil {
+			key = *block.NewKey
+		}
+		manager.TagsReceived(key, block.Tags, now)
+	}
+	log.WithFields(logrus.Fields{
+		"existing_session": ok,
+		"tags":             len(block.Tags),
+		"payload_length":   len(block.Payload),
+	}).Debug("Decrypted garlic message")
+	return block.Payload, nil
+}
+
+// expireInbound drops expired inbound tags. Callers must hold the lock.
+func (manager *SessionKeyManager) expireInbound(now time.Time) {
+	for tag, inbound := range manager.inbound {
+		if !now.Before(inbound.expires) {
+			delete(manager.inbound, tag)
+		}
+	}
+}
+
+// Expire drops expired tags and outbound sessions idle for longer than
+// OUTBOUND_TAG_LIFETIME. It should be called periodically.
+func (manager *SessionKeyManager) Expire(now time.Time) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	manager.expireInbound(now)
+	for target, session := range manager.outbound {
+		if !now.Before(session.lastUsed.Add(OUTBOUND_TAG_LIFETIME)) {
+			delete(manager.outbound, target)
+			continue
+		}
+		sets := session.tagSets[:0]
+		for _, set := range session.tagSets {
+			if !set.expired(now) {
+				sets = append(sets, set)
+			}
+		}
+		session.tagSets = sets
+	}
+}
+
+// InboundTags returns how many tags delivered to us are stored.
+func (manager *SessionKeyManager) InboundTags() int {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	return len(manager.inbound)
+}
+
+// approximate sizes of what the manager keeps, for MemoryUsage
+const (
+	// a map entry of an inbound tag: the tag, its key and its expiry
+	inboundTagMemory = SESSION_TAG_SIZE + SESSION_KEY_SIZE + 24
+	// an outbound session without its tags: the target key, the session key
+	// and its last use
+	outboundSessionMemory = int64(len(crypto.ElgPublicKey{})) + SESSION_KEY_SIZE + 24
+)
+
+// MemoryUsage returns the number of inbound tags and outbound sessions kept
+// and an estimate of their size, for the router memory accounting.
+func (manager *SessionKeyManager) MemoryUsage() (entries int, bytes int64) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	bytes = int64(len(manager.inbound)) * inboundTagMemory
+	for _, session := range manager.outbound {
+		bytes += outboundSessionMemory
+		for _, set := range session.tagSets {
+			bytes += SESSION_KEY_SIZE + int64(len(set.Tags))*SESSION_TAG_SIZE
+		}
+	}
+	return len(manager.inbound) + len(manager.outbound), bytes
+}
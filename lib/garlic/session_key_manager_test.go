@@ -0,0 +1,113 @@
+package garlic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionKeyManagerTags(t *testing.T) {
+	now := time.Now()
+	pub, priv := testElgKeys(t)
+	alice, bob := NewSessionKeyManager(), NewSessionKeyManager()
+
+	// the first message is a new session message delivering tags
+	data, delivered, err := alice.Encrypt(pub, []byte("one"), now)
+	require.NoError(t, err)
+	require.NotNil(t, delivered)
+	assert.Len(t, delivered.Tags, TAGS_PER_DELIVERY)
+	assert.Greater(t, len(data), ELGAMAL_BLOCK_SIZE)
+	payload, err := bob.Decrypt(priv, data, now)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("one"), payload)
+	assert.Equal(t, TAGS_PER_DELIVERY, bob.InboundTags())
+
+	// until the tags are acknowledged, ElGamal is used without delivering
+	// more tags
+	data, pending, err := alice.Encrypt(pub, []byte("two"), now)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+	assert.Greater(t, len(data), ELGAMAL_BLOCK_SIZE)
+	_, err = bob.Decrypt(priv, data, now)
+	require.NoError(t, err)
+
+	alice.TagsAcked(pub, delivered)
+	data, pending, err = alice.Encrypt(pub, []byte("three"), now)
+	require.NoError(t, err)
+	assert.Nil(t, pending)
+	assert.Equal(t, delivered.Tags[0][:], data[:SESSION_TAG_SIZE])
+	assert.Less(t, len(data), ELGAMAL_BLOCK_SIZE)
+	payload, err = bob.Decrypt(priv, data, now)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("three"), payload)
+	assert.Equal(t, TAGS_PER_DELIVERY-1, bob.InboundTags())
+
+	// a tag is used once
+	_, err = bob.Decrypt(priv, data, now)
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+
+	// running low delivers a new set along with a tagged message
+	var refill *TagSet
+	used := 1
+	for refill == nil && used < TAGS_PER_DELIVERY {
+		data, refill, err = alice.Encrypt(pub, []byte("more"), now)
+		require.NoError(t, err)
+		_, err = bob.Decrypt(priv, data, now)
+		require.NoError(t, err)
+		used++
+	}
+	require.NotNil(t, refill)
+	assert.Equal(t, TAGS_PER_DELIVERY-TAG_LOW_THRESHOLD+2, used, "delivered by the first message finding fewer than the threshold left, which uses a tag too")
+	assert.Equal(t, 2*TAGS_PER_DELIVERY-used, bob.InboundTags())
+}
+
+func TestSessionKeyManagerTagsFailed(t *testing.T) {
+	now := time.Now()
+	pub, _ := testElgKeys(t)
+	alice := NewSessionKeyManager()
+
+	_, delivered, err := alice.Encrypt(pub, []byte("one"), now)
+	require.NoError(t, err)
+	key := delivered.Key
+	alice.TagsFailed(pub, delivered)
+
+	// the session was dropped, the next message starts a new one
+	_, delivered, err = alice.Encrypt(pub, []byte("two"), now)
+	require.NoError(t, err)
+	require.NotNil(t, delivered)
+	assert.NotEqual(t, key, delivered.Key)
+}
+
+func TestSessionKeyManagerExpiry(t *testing.T) {
+	now := time.Now()
+	pub, priv := testElgKeys(t)
+	alice, bob := NewSessionKeyManager(), NewSessionKeyManager()
+
+	data, delivered, err := alice.Encrypt(pub, []byte("one"), now)
+	require.NoError(t, err)
+	_, err = bob.Decrypt(priv, data, now)
+	require.NoError(t, err)
+	alice.TagsAcked(pub, delivered)
+
+	// tags we delivered stop being used before the far end drops them
+	later := now.Add(OUTBOUND_TAG_LIFETIME)
+	data, _, err = alice.Encrypt(pub, []byte("two"), later)
+	require.NoError(t, err)
+	assert.Greater(t, len(data), ELGAMAL_BLOCK_SIZE)
+
+	bob.Expire(now.Add(INBOUND_TAG_LIFETIME))
+	assert.Zero(t, bob.InboundTags())
+
+	alice.Expire(later.Add(OUTBOUND_TAG_LIFETIME))
+	assert.Empty(t, alice.outbound)
+}
+
+func TestSessionKeyManagerRejectsShortMessages(t *testing.T) {
+	_, priv := testElgKeys(t)
+	_, err := NewSessionKeyManager().Decrypt(priv, make([]byte, SESSION_TAG_SIZE), time.Now())
+	assert.ErrorIs(t, err, ErrMessageTooShort)
+	_, err = NewSessionKeyManager().Decrypt(priv, make([]byte, 100), time.Now())
+	assert.ErrorIs(t, err, ErrDecryptionFailed)
+}
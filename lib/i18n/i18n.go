@@ -0,0 +1,200 @@
+// Package i18n translates the user facing strings of the router: CLI output
+// and the pages of the web console.
+//
+// Messages are identified by their English text, which is also what is
+// shown when no translation exists. Catalogs are JSON objects mapping
+// messages to their translation, one per language in catalogs/, named by
+// the language tag. catalogs/en.json lists every message passed to T in the
+// source tree; regenerate it with
+//
+//	go test ./lib/i18n -run TestSourceCatalog -update
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// DEFAULT_LANGUAGE is the language of the messages in the source
+const DEFAULT_LANGUAGE = "en"
+
+// LANGUAGE_PARAMETER is the query parameter overriding the language of a
+// web console page
+const LANGUAGE_PARAMETER = "lang"
+
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+// Catalog maps messages to their translation.
+type Catalog map[string]string
+
+var (
+	catalogs = make(map[string]Catalog)
+	// supported lists the languages with a catalog, the default first, as
+	// the matcher needs it
+	supported []language.Tag
+	matcher   language.Matcher
+	current   atomic.Pointer[Localizer]
+)
+
+func init() {
+	entries, err := catalogFiles.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+	supported = []language.Tag{language.Make(DEFAULT_LANGUAGE)}
+	for _, entry := range entries {
+		lang := strings.TrimSuffix(entry.Name(), ".json")
+		data, err := catalogFiles.ReadFile(path.Join("catalogs", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var catalog Catalog
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("invalid catalog %s: %v", entry.Name(), err))
+		}
+		catalogs[lang] = catalog
+		if lang != DEFAULT_LANGUAGE {
+			supported = append(supported, language.Make(lang))
+		}
+	}
+	matcher = language.NewMatcher(supported)
+	SetDefault(FromEnvironment())
+}
+
+// Languages returns the languages with a catalog, sorted.
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Negotiate returns the supported language best matching the preferences,
+// in order of preference. Each may be an Accept-Language header, a language
+// tag or a POSIX locale such as de_DE.UTF-8. DEFAULT_LANGUAGE is returned
+// if nothing matches.
+func Negotiate(preferences ...string) string {
+	var wanted []language.Tag
+	for _, preference := range preferences {
+		tags, _, err := language.ParseAcceptLanguage(posixToTag(preference))
+		if err != nil {
+			continue
+		}
+		wanted = append(wanted, tags...)
+	}
+	if len(wanted) == 0 {
+		return DEFAULT_LANGUAGE
+	}
+	_, index, confidence := matcher.Match(wanted...)
+	if confidence == language.No {
+		return DEFAULT_LANGUAGE
+	}
+	base, _ := supported[index].Base()
+	return base.String()
+}
+
+// posixToTag turns a POSIX locale into a language tag, leaving anything
+// else unchanged: de_DE.UTF-8@euro becomes de-DE, C and POSIX become empty
+func posixToTag(locale string) string {
+	if strings.ContainsAny(locale, ",;") {
+		return locale
+	}
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return strings.ReplaceAll(locale, "_", "-")
+}
+
+// Localizer translates messages into one language.
+type Localizer struct {
+	lang    string
+	catalog Catalog
+}
+
+// NewLocalizer returns a localizer for lang, or for the best match of it
+// among the supported languages.
+func NewLocalizer(lang string) *Localizer {
+	lang = Negotiate(lang)
+	return &Localizer{lang: lang, catalog: catalogs[lang]}
+}
+
+// FromEnvironment returns a localizer for the language of the user's
+// locale, from LC_ALL, LC_MESSAGES or LANG.
+func FromEnvironment() *Localizer {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			return NewLocalizer(value)
+		}
+	}
+	return NewLocalizer(DEFAULT_LANGUAGE)
+}
+
+// FromRequest returns a localizer for a web console request, using the
+// LANGUAGE_PARAMETER query parameter if given and the Accept-Language
+// header otherwise.
+func FromRequest(req *http.Request) *Localizer {
+	if lang := req.URL.Query().Get(LANGUAGE_PARAMETER); lang != "" {
+		return NewLocalizer(lang)
+	}
+	lang := Negotiate(req.Header.Values("Accept-Language")...)
+	return &Localizer{lang: lang, catalog: catalogs[lang]}
+}
+
+// Language returns the language messages are translated into.
+func (l *Localizer) Language() string {
+	return l.lang
+}
+
+// T translates message and formats it with args as fmt.Sprintf does. A
+// message without a translation is used as it is.
+func (l *Localizer) T(message string, args ...interface{}) string {
+	translated, ok := l.catalog[message]
+	if !ok || translated == "" {
+		translated = message
+		if l.lang != DEFAULT_LANGUAGE {
+			log.WithFields(logrus.Fields{
+				"language": l.lang,
+				"message":  message,
+			}).Debug("Message has no translation")
+		}
+	}
+	if len(args) == 0 {
+		return translated
+	}
+	return fmt.Sprintf(translated, args...)
+}
+
+// SetDefault sets the localizer T uses, which is FromEnvironment at
+// startup.
+func SetDefault(l *Localizer) {
+	current.Store(l)
+}
+
+// Default returns the localizer T uses.
+func Default() *Localizer {
+	return current.Load()
+}
+
+// T translates message with the default localizer.
+func T(message string, args ...interface{}) string {
+	return Default().T(message, args...)
+}
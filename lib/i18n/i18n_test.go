@@ -0,0 +1,174 @@
+package i18n
+
+import (
+	"encoding/json"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite catalogs/en.json from the source tree")
+
+// repoRoot is the module root, relative to this package
+const repoRoot = "../.."
+
+// sourceMessages extracts the literal messages passed to i18n.T in the
+// non-test sources of the module
+func sourceMessages(t *testing.T) map[string]bool {
+	messages := make(map[string]bool)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir(repoRoot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name != ".." && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			selector, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || selector.Sel.Name != "T" {
+				return true
+			}
+			if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+				return true
+			}
+			literal, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || literal.Kind != token.STRING {
+				t.Errorf("%s: i18n.T needs a literal message", fset.Position(call.Pos()))
+				return true
+			}
+			message, err := strconv.Unquote(literal.Value)
+			require.NoError(t, err)
+			messages[message] = true
+			return true
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	return messages
+}
+
+func TestSourceCatalog(t *testing.T) {
+	messages := sourceMessages(t)
+	require.NotEmpty(t, messages)
+	if *update {
+		catalog := make(Catalog, len(messages))
+		for message := range messages {
+			catalog[message] = message
+		}
+		data, err := json.MarshalIndent(catalog, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("catalogs/en.json", append(data, '\n'), 0o644))
+		return
+	}
+	for message := range messages {
+		assert.Contains(t, catalogs[DEFAULT_LANGUAGE], message, "missing from en.json, run the test with -update")
+	}
+	for message := range catalogs[DEFAULT_LANGUAGE] {
+		assert.True(t, messages[message], "%q is no longer used, run the test with -update", message)
+	}
+}
+
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func TestCatalogsMatchSource(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for message, translated := range catalog {
+			assert.Contains(t, catalogs[DEFAULT_LANGUAGE], message, "%s.json has an unknown message", lang)
+			if translated == "" {
+				continue
+			}
+			want := formatVerb.FindAllString(message, -1)
+			got := formatVerb.FindAllString(translated, -1)
+			sort.Strings(want)
+			sort.Strings(got)
+			assert.Equal(t, want, got, "%s.json: format verbs of %q differ", lang, message)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, "de", Negotiate("de"))
+	assert.Equal(t, "de", Negotiate("de-AT"))
+	assert.Equal(t, "de", Negotiate("de_DE.UTF-8"))
+	assert.Equal(t, "de", Negotiate("fr-CH, de;q=0.8, en;q=0.5"))
+	assert.Equal(t, "en", Negotiate("en-US,en;q=0.9,de;q=0.8"))
+	assert.Equal(t, DEFAULT_LANGUAGE, Negotiate("C"))
+	assert.Equal(t, DEFAULT_LANGUAGE, Negotiate("POSIX"))
+	assert.Equal(t, DEFAULT_LANGUAGE, Negotiate("x-invalid!"))
+	assert.Equal(t, DEFAULT_LANGUAGE, Negotiate())
+	assert.Equal(t, "de", Negotiate("", "de"))
+}
+
+func TestLanguages(t *testing.T) {
+	assert.Equal(t, []string{"de", "en"}, Languages())
+}
+
+func TestLocalizerTranslates(t *testing.T) {
+	de := NewLocalizer("de-DE")
+	assert.Equal(t, "de", de.Language())
+	assert.Equal(t, "Aktuelle Konfiguration anzeigen", de.T("Show current configuration"))
+	assert.Equal(t, "3 Router-Infos nach a.tar.zst exportiert", de.T("Exported %d router infos to %s", 3, "a.tar.zst"))
+	assert.Equal(t, "not in any catalog", de.T("not in any catalog"))
+	assert.Equal(t, "100%", de.T("100%"), "messages without arguments are not formatted")
+
+	en := NewLocalizer("en")
+	assert.Equal(t, "Exported 3 router infos to a.tar.zst", en.T("Exported %d router infos to %s", 3, "a.tar.zst"))
+}
+
+func TestFromEnvironment(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "de_AT.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	assert.Equal(t, "de", FromEnvironment().Language())
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, DEFAULT_LANGUAGE, FromEnvironment().Language())
+}
+
+func TestFromRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Language", "de-CH,de;q=0.9,en;q=0.8")
+	assert.Equal(t, "de", FromRequest(req).Language())
+
+	req = httptest.NewRequest("GET", "/?lang=en", nil)
+	req.Header.Set("Accept-Language", "de")
+	assert.Equal(t, "en", FromRequest(req).Language())
+
+	req = httptest.NewRequest("GET", "/", nil)
+	assert.Equal(t, DEFAULT_LANGUAGE, FromRequest(req).Language())
+}
+
+func TestSetDefault(t *testing.T) {
+	previous := Default()
+	defer SetDefault(previous)
+	SetDefault(NewLocalizer("de"))
+	assert.Equal(t, "Aktuelle Konfiguration anzeigen", T("Show current configuration"))
+}
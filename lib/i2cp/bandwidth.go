@@ -0,0 +1,77 @@
+// Package i2cp implements the parts of the I2P Client Protocol the router
+// shares with its client sessions.
+//
+// https://geti2p.net/spec/i2cp
+package i2cp
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// I2CP message types for querying bandwidth limits
+const (
+	GET_BANDWIDTH_LIMITS_MESSAGE = 8
+	BANDWIDTH_LIMITS_MESSAGE     = 23
+)
+
+// a BandwidthLimits payload is 16 four byte integers, 9 of them undefined
+const BANDWIDTH_LIMITS_SIZE = 16 * 4
+
+var ErrBandwidthLimitsTooShort = errors.New("bandwidth limits message too short")
+
+// BandwidthLimits is the payload of a BandwidthLimitsMessage. All values are
+// in KBps except BurstSeconds, 0 means no limit is imposed.
+type BandwidthLimits struct {
+	// limits for the client session
+	ClientInbound  uint32
+	ClientOutbound uint32
+	// limits for the whole router
+	RouterInbound       uint32
+	RouterInboundBurst  uint32
+	RouterOutbound      uint32
+	RouterOutboundBurst uint32
+	// how long the router may run at its burst rates
+	BurstSeconds uint32
+}
+
+// fields returns the defined fields in wire order
+func (limits *BandwidthLimits) fields() []*uint32 {
+	return []*uint32{
+		&limits.ClientInbound,
+		&limits.ClientOutbound,
+		&limits.RouterInbound,
+		&limits.RouterInboundBurst,
+		&limits.RouterOutbound,
+		&limits.RouterOutboundBurst,
+		&limits.BurstSeconds,
+	}
+}
+
+// Bytes returns the message payload, with the undefined fields zeroed.
+func (limits BandwidthLimits) Bytes() []byte {
+	data := make([]byte, BANDWIDTH_LIMITS_SIZE)
+	for i, field := range limits.fields() {
+		binary.BigEndian.PutUint32(data[i*4:], *field)
+	}
+	return data
+}
+
+// ReadBandwidthLimits parses a BandwidthLimitsMessage payload and returns
+// the remaining bytes.
+func ReadBandwidthLimits(data []byte) (limits BandwidthLimits, remainder []byte, err error) {
+	if len(data) < BANDWIDTH_LIMITS_SIZE {
+		log.WithField("length", len(data)).Error("Bandwidth limits message too short")
+		err = ErrBandwidthLimitsTooShort
+		return
+	}
+	for i, field := range limits.fields() {
+		*field = binary.BigEndian.Uint32(data[i*4:])
+	}
+	remainder = data[BANDWIDTH_LIMITS_SIZE:]
+	return
+}
@@ -0,0 +1,53 @@
+package i2cp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBandwidthLimitsRoundTrip(t *testing.T) {
+	limits := BandwidthLimits{
+		ClientInbound:       64,
+		ClientOutbound:      32,
+		RouterInbound:       256,
+		RouterInboundBurst:  300,
+		RouterOutbound:      128,
+		RouterOutboundBurst: 150,
+		BurstSeconds:        20,
+	}
+	data := limits.Bytes()
+	require.Len(t, data, BANDWIDTH_LIMITS_SIZE)
+	assert.Equal(t, []byte{0, 0, 0, 64}, data[:4])
+	assert.Equal(t, make([]byte, 9*4), data[7*4:])
+
+	read, remainder, err := ReadBandwidthLimits(append(data, 0xff))
+	require.NoError(t, err)
+	assert.Equal(t, limits, read)
+	assert.Equal(t, []byte{0xff}, remainder)
+
+	_, _, err = ReadBandwidthLimits(data[:BANDWIDTH_LIMITS_SIZE-1])
+	assert.ErrorIs(t, err, ErrBandwidthLimitsTooShort)
+}
+
+func TestSessionCounters(t *testing.T) {
+	counters := NewSessionCounters()
+	counters.AddInbound(100)
+	counters.AddOutbound(40)
+	counters.Tunnel(7).AddInbound(100)
+	counters.Tunnel(9).AddOutbound(40)
+
+	usage := counters.Usage()
+	assert.Equal(t, uint64(100), usage.InboundBytes)
+	assert.Equal(t, uint64(40), usage.OutboundBytes)
+	assert.Greater(t, usage.InboundRate, 0.0)
+
+	tunnels := counters.TunnelUsage()
+	require.Len(t, tunnels, 2)
+	assert.Equal(t, uint64(100), tunnels[7].InboundBytes)
+	assert.Equal(t, uint64(40), tunnels[9].OutboundBytes)
+
+	counters.RemoveTunnel(7)
+	assert.NotContains(t, counters.TunnelUsage(), uint32(7))
+}
@@ -0,0 +1,100 @@
+package i2cp
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Usage is the traffic counted for a session or tunnel.
+type Usage struct {
+	InboundBytes  uint64 `json:"inbound_bytes"`
+	OutboundBytes uint64 `json:"outbound_bytes"`
+	// average rates since counting started, in bytes per second
+	InboundRate  float64 `json:"inbound_rate"`
+	OutboundRate float64 `json:"outbound_rate"`
+}
+
+// ByteCounter counts the bytes sent and received through a session or tunnel.
+// It is safe for concurrent use.
+type ByteCounter struct {
+	inbound  atomic.Uint64
+	outbound atomic.Uint64
+	started  time.Time
+}
+
+// NewByteCounter returns a counter starting now.
+func NewByteCounter() *ByteCounter {
+	return &ByteCounter{started: time.Now()}
+}
+
+// AddInbound counts n bytes received.
+func (counter *ByteCounter) AddInbound(n int) {
+	counter.inbound.Add(uint64(n))
+}
+
+// AddOutbound counts n bytes sent.
+func (counter *ByteCounter) AddOutbound(n int) {
+	counter.outbound.Add(uint64(n))
+}
+
+// Usage returns the bytes counted so far and the average rates.
+func (counter *ByteCounter) Usage() Usage {
+	usage := Usage{
+		InboundBytes:  counter.inbound.Load(),
+		OutboundBytes: counter.outbound.Load(),
+	}
+	if elapsed := time.Since(counter.started).Seconds(); elapsed > 0 {
+		usage.InboundRate = float64(usage.InboundBytes) / elapsed
+		usage.OutboundRate = float64(usage.OutboundBytes) / elapsed
+	}
+	return usage
+}
+
+// SessionCounters counts the traffic of a client session in total and for
+// each of its tunnels.
+type SessionCounters struct {
+	*ByteCounter
+	mutex   sync.Mutex
+	tunnels map[uint32]*ByteCounter
+}
+
+// NewSessionCounters returns the counters for a new session.
+func NewSessionCounters() *SessionCounters {
+	return &SessionCounters{
+		ByteCounter: NewByteCounter(),
+		tunnels:     make(map[uint32]*ByteCounter),
+	}
+}
+
+// Tunnel returns the counter of the tunnel with id, creating it on first use.
+// Bytes added to it are not included in the session totals, count them on
+// the session as well.
+func (counters *SessionCounters) Tunnel(id uint32) *ByteCounter {
+	counters.mutex.Lock()
+	defer counters.mutex.Unlock()
+	counter, ok := counters.tunnels[id]
+	if !ok {
+		counter = NewByteCounter()
+		counters.tunnels[id] = counter
+	}
+	return counter
+}
+
+// RemoveTunnel drops the counter of an expired tunnel.
+func (counters *SessionCounters) RemoveTunnel(id uint32) {
+	counters.mutex.Lock()
+	defer counters.mutex.Unlock()
+	delete(counters.tunnels, id)
+}
+
+// TunnelUsage returns the usage of each tunnel of the session by tunnel ID.
+func (counters *SessionCounters) TunnelUsage() map[uint32]Usage {
+	counters.mutex.Lock()
+	defer counters.mutex.Unlock()
+	usage := make(map[uint32]Usage, len(counters.tunnels))
+	for id, counter := range counters.tunnels {
+		usage[id] = counter.Usage()
+	}
+	return usage
+}
@@ -0,0 +1,96 @@
+package i2cp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/i2np"
+	"github.com/sirupsen/logrus"
+)
+
+// Session options controlling what a session reveals about itself. SAM
+// passes these through from SESSION CREATE unchanged.
+const (
+	// OPTION_BUNDLE_REPLY_INFO bundles the session's LeaseSet and a reply
+	// block with outbound garlic messages, default true
+	OPTION_BUNDLE_REPLY_INFO = "shouldBundleReplyInfo"
+	// OPTION_DONT_PUBLISH_LEASESET keeps the session's LeaseSet out of the
+	// network database, default false
+	OPTION_DONT_PUBLISH_LEASESET = "i2cp.dontPublishLeaseSet"
+)
+
+var ErrInvalidOption = errors.New("invalid session option")
+
+// SessionOptions are the session options the router acts on.
+type SessionOptions struct {
+	// BundleReplyInfo bundles the LeaseSet and a reply block with outbound
+	// messages so the far end can answer without a lookup
+	BundleReplyInfo bool
+	// PublishLeaseSet publishes the LeaseSet to the network database
+	PublishLeaseSet bool
+}
+
+// DefaultSessionOptions returns the options of a session that set none.
+func DefaultSessionOptions() SessionOptions {
+	return SessionOptions{
+		BundleReplyInfo: true,
+		PublishLeaseSet: true,
+	}
+}
+
+// ParseSessionOptions reads the options the router acts on from the options
+// of a session, leaving the others at their defaults.
+func ParseSessionOptions(options map[string]string) (SessionOptions, error) {
+	parsed := DefaultSessionOptions()
+	if value, ok := options[OPTION_BUNDLE_REPLY_INFO]; ok {
+		bundle, err := strconv.ParseBool(value)
+		if err != nil {
+			return parsed, fmt.Errorf("%w: %s=%q", ErrInvalidOption, OPTION_BUNDLE_REPLY_INFO, value)
+		}
+		parsed.BundleReplyInfo = bundle
+	}
+	if value, ok := options[OPTION_DONT_PUBLISH_LEASESET]; ok {
+		dontPublish, err := strconv.ParseBool(value)
+		if err != nil {
+			return parsed, fmt.Errorf("%w: %s=%q", ErrInvalidOption, OPTION_DONT_PUBLISH_LEASESET, value)
+		}
+		parsed.PublishLeaseSet = !dontPublish
+	}
+	log.WithFields(logrus.Fields{
+		"bundle_reply_info": parsed.BundleReplyInfo,
+		"publish_leaseset":  parsed.PublishLeaseSet,
+	}).Debug("Parsed session options")
+	return parsed, nil
+}
+
+// ParseOptionArgs reads options given as KEY=VALUE words, as SAM commands
+// carry them.
+func ParseOptionArgs(args []string) (map[string]string, error) {
+	options := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidOption, arg)
+		}
+		options[key] = strings.Trim(value, `"`)
+	}
+	return options, nil
+}
+
+// SendOnly reports whether the session hides how to reach it: it neither
+// publishes nor bundles its LeaseSet, so it can only send.
+func (options SessionOptions) SendOnly() bool {
+	return !options.BundleReplyInfo && !options.PublishLeaseSet
+}
+
+// OutboundCloves returns the cloves of an outbound garlic message: payload,
+// followed by the LeaseSet and reply cloves unless the session does not
+// bundle reply info.
+func (options SessionOptions) OutboundCloves(payload i2np.GarlicClove, replyInfo ...i2np.GarlicClove) []i2np.GarlicClove {
+	if !options.BundleReplyInfo {
+		return []i2np.GarlicClove{payload}
+	}
+	return append([]i2np.GarlicClove{payload}, replyInfo...)
+}
@@ -0,0 +1,39 @@
+package i2cp
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/i2np"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSessionOptionsDefaults(t *testing.T) {
+	options, err := ParseSessionOptions(map[string]string{"inbound.length": "3"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSessionOptions(), options)
+	assert.False(t, options.SendOnly())
+}
+
+func TestParseSessionOptionsSendOnly(t *testing.T) {
+	args, err := ParseOptionArgs([]string{"shouldBundleReplyInfo=false", "i2cp.dontPublishLeaseSet=true"})
+	require.NoError(t, err)
+	options, err := ParseSessionOptions(args)
+	require.NoError(t, err)
+	assert.False(t, options.BundleReplyInfo)
+	assert.False(t, options.PublishLeaseSet)
+	assert.True(t, options.SendOnly())
+
+	payload := i2np.GarlicClove{CloveID: 1}
+	leaseSet := i2np.GarlicClove{CloveID: 2}
+	assert.Equal(t, []i2np.GarlicClove{payload}, options.OutboundCloves(payload, leaseSet))
+	assert.Equal(t, []i2np.GarlicClove{payload, leaseSet}, DefaultSessionOptions().OutboundCloves(payload, leaseSet))
+}
+
+func TestParseSessionOptionsInvalid(t *testing.T) {
+	_, err := ParseSessionOptions(map[string]string{OPTION_BUNDLE_REPLY_INFO: "maybe"})
+	assert.ErrorIs(t, err, ErrInvalidOption)
+
+	_, err = ParseOptionArgs([]string{"novalue"})
+	assert.ErrorIs(t, err, ErrInvalidOption)
+}
@@ -2,8 +2,10 @@ package i2np
 
 import (
 	"testing"
+	"time"
 
 	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/session_key"
 	"github.com/go-i2p/go-i2p/lib/tunnel"
 	"github.com/stretchr/testify/assert"
 )
@@ -51,22 +53,151 @@ func TestReadBuildRequestRecordOurIdentValidData(t *testing.T) {
 	assert.Equal(nil, err)
 }
 
-func TestReadBuildRequestRecordNextTunnel(t *testing.T) {}
+// cleartextBuildRequestRecord lays out a 222 byte cleartext record at the
+// offsets given in the I2NP spec, with a distinct value in every field so a
+// field read from the wrong offset cannot match. It is built from the spec
+// layout, not recorded from the Java router.
+func cleartextBuildRequestRecord() []byte {
+	record := make([]byte, 222)
+	copy(record[0:4], []byte{0x00, 0x00, 0x00, 0x11})     // receive_tunnel
+	fill(record[4:36], 0x21)                              // our_ident
+	copy(record[36:40], []byte{0x00, 0x00, 0x00, 0x12})   // next_tunnel
+	fill(record[40:72], 0x22)                             // next_ident
+	fill(record[72:104], 0x23)                            // layer_key
+	fill(record[104:136], 0x24)                           // iv_key
+	fill(record[136:168], 0x25)                           // reply_key
+	fill(record[168:184], 0x26)                           // reply_iv
+	record[184] = 0x80                                    // flag, outbound endpoint
+	copy(record[185:189], []byte{0x00, 0x07, 0x35, 0x8f}) // request_time, hours since epoch
+	copy(record[189:193], []byte{0x00, 0x00, 0x00, 0x13}) // send_message_id
+	fill(record[193:222], 0x27)                           // padding
+	return record
+}
+
+func fill(b []byte, value byte) {
+	for i := range b {
+		b[i] = value
+	}
+}
+
+func TestReadBuildRequestRecordNextTunnel(t *testing.T) {
+	assert := assert.New(t)
+
+	next_tunnel, err := readBuildRequestRecordNextTunnel(cleartextBuildRequestRecord())
+	assert.Equal(tunnel.TunnelID(0x12), next_tunnel)
+	assert.Nil(err)
+
+	_, err = readBuildRequestRecordNextTunnel(make([]byte, 39))
+	assert.Equal(ERR_BUILD_REQUEST_RECORD_NOT_ENOUGH_DATA, err)
+}
+
+func TestReadBuildRequestRecordNextIdent(t *testing.T) {
+	assert := assert.New(t)
 
-func TestReadBuildRequestRecordNextIdent(t *testing.T) {}
+	next_ident, err := readBuildRequestRecordNextIdent(cleartextBuildRequestRecord())
+	expected := common.Hash{}
+	fill(expected[:], 0x22)
+	assert.Equal(expected, next_ident)
+	assert.Nil(err)
+}
 
-func TestReadBuildRequestRecordLayerKey(t *testing.T) {}
+func TestReadBuildRequestRecordLayerKey(t *testing.T) {
+	assert := assert.New(t)
 
-func TestReadBuildRequestRecordIVKey(t *testing.T) {}
+	layer_key, err := readBuildRequestRecordLayerKey(cleartextBuildRequestRecord())
+	expected := session_key.SessionKey{}
+	fill(expected[:], 0x23)
+	assert.Equal(expected, layer_key)
+	assert.Nil(err)
+}
 
-func TestReadBuildRequestRecordReplyKey(t *testing.T) {}
+func TestReadBuildRequestRecordIVKey(t *testing.T) {
+	assert := assert.New(t)
 
-func TestReadBuildRequestRecordReplyIV(t *testing.T) {}
+	iv_key, err := readBuildRequestRecordIVKey(cleartextBuildRequestRecord())
+	expected := session_key.SessionKey{}
+	fill(expected[:], 0x24)
+	assert.Equal(expected, iv_key)
+	assert.Nil(err)
+}
 
-func TestReadBuildRequestRecordFlag(t *testing.T) {}
+func TestReadBuildRequestRecordReplyKey(t *testing.T) {
+	assert := assert.New(t)
 
-func TestReadBuildRequestRecordRequestTime(t *testing.T) {}
+	reply_key, err := readBuildRequestRecordReplyKey(cleartextBuildRequestRecord())
+	expected := session_key.SessionKey{}
+	fill(expected[:], 0x25)
+	assert.Equal(expected, reply_key)
+	assert.Nil(err)
+}
 
-func TestReadBuildRequestRecordSendMessageID(t *testing.T) {}
+func TestReadBuildRequestRecordReplyIV(t *testing.T) {
+	assert := assert.New(t)
 
-func TestReadBuildRequestRecordPadding(t *testing.T) {}
+	reply_iv, err := readBuildRequestRecordReplyIV(cleartextBuildRequestRecord())
+	expected := [16]byte{}
+	fill(expected[:], 0x26)
+	assert.Equal(expected, reply_iv)
+	assert.Nil(err)
+}
+
+func TestReadBuildRequestRecordFlag(t *testing.T) {
+	assert := assert.New(t)
+
+	flag, err := readBuildRequestRecordFlag(cleartextBuildRequestRecord())
+	assert.Equal(0x80, flag)
+	assert.Nil(err)
+
+	_, err = readBuildRequestRecordFlag(make([]byte, 184))
+	assert.Equal(ERR_BUILD_REQUEST_RECORD_NOT_ENOUGH_DATA, err)
+}
+
+func TestReadBuildRequestRecordRequestTime(t *testing.T) {
+	assert := assert.New(t)
+
+	request_time, err := readBuildRequestRecordRequestTime(cleartextBuildRequestRecord())
+	assert.Equal(time.Unix(0x7358f*3600, 0), request_time)
+	assert.Nil(err)
+}
+
+func TestReadBuildRequestRecordSendMessageID(t *testing.T) {
+	assert := assert.New(t)
+
+	send_message_id, err := readBuildRequestRecordSendMessageID(cleartextBuildRequestRecord())
+	assert.Equal(0x13, send_message_id)
+	assert.Nil(err)
+}
+
+func TestReadBuildRequestRecordPadding(t *testing.T) {
+	assert := assert.New(t)
+
+	padding, err := readBuildRequestRecordPadding(cleartextBuildRequestRecord())
+	expected := [29]byte{}
+	fill(expected[:], 0x27)
+	assert.Equal(expected, padding)
+	assert.Nil(err)
+}
+
+// TestReadBuildRequestRecordFieldOrder reads a whole record, so a field
+// consuming the bytes of its neighbour shows up as a mismatch.
+func TestReadBuildRequestRecordFieldOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	record, err := ReadBuildRequestRecord(cleartextBuildRequestRecord())
+	assert.Nil(err)
+	assert.Equal(tunnel.TunnelID(0x11), record.ReceiveTunnel)
+	assert.Equal(byte(0x21), record.OurIdent[31])
+	assert.Equal(tunnel.TunnelID(0x12), record.NextTunnel)
+	assert.Equal(byte(0x22), record.NextIdent[0])
+	assert.Equal(byte(0x23), record.LayerKey[31])
+	assert.Equal(byte(0x24), record.IVKey[0])
+	assert.Equal(byte(0x25), record.ReplyKey[31])
+	assert.Equal(byte(0x26), record.ReplyIV[15])
+	assert.Equal(0x80, record.Flag)
+	assert.Equal(time.Unix(0x7358f*3600, 0), record.RequestTime)
+	assert.Equal(0x13, record.SendMessageID)
+	assert.Equal(byte(0x27), record.Padding[0])
+
+	_, err = ReadBuildRequestRecord(make([]byte, 221))
+	assert.Equal(ERR_BUILD_REQUEST_RECORD_NOT_ENOUGH_DATA, err)
+}
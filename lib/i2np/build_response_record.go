@@ -1,7 +1,11 @@
 package i2np
 
 import (
+	"crypto/sha256"
+	"errors"
+
 	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
 )
 
 /*
@@ -48,3 +52,41 @@ type BuildResponseRecord struct {
 	Padding [495]byte
 	Reply   byte
 }
+
+var (
+	ERR_BUILD_RESPONSE_RECORD_NOT_ENOUGH_DATA = errors.New("not enough i2np build response record data")
+	ERR_BUILD_RESPONSE_RECORD_BAD_HASH        = errors.New("i2np build response record hash does not match its data")
+)
+
+// ReadBuildResponseRecord reads a cleartext BuildResponseRecord and checks
+// its hash.
+func ReadBuildResponseRecord(data []byte) (BuildResponseRecord, error) {
+	build_response_record := BuildResponseRecord{}
+	if len(data) < 528 {
+		log.WithField("length", len(data)).Error("Failed to read BuildResponseRecord")
+		return build_response_record, ERR_BUILD_RESPONSE_RECORD_NOT_ENOUGH_DATA
+	}
+	copy(build_response_record.Hash[:], data[0:32])
+	copy(build_response_record.Padding[:], data[32:527])
+	build_response_record.Reply = data[527]
+	if sha256.Sum256(data[32:528]) != build_response_record.Hash {
+		log.Error("BuildResponseRecord hash mismatch")
+		return build_response_record, ERR_BUILD_RESPONSE_RECORD_BAD_HASH
+	}
+	log.WithFields(logrus.Fields{
+		"at":    "i2np.ReadBuildResponseRecord",
+		"reply": build_response_record.Reply,
+	}).Debug("parsed_build_response_record")
+	return build_response_record, nil
+}
+
+// Bytes returns the cleartext record, with the hash computed over the padding
+// and reply. The Hash field is not used.
+func (build_response_record BuildResponseRecord) Bytes() []byte {
+	data := make([]byte, 528)
+	copy(data[32:527], build_response_record.Padding[:])
+	data[527] = build_response_record.Reply
+	hash := sha256.Sum256(data[32:528])
+	copy(data[0:32], hash[:])
+	return data
+}
@@ -0,0 +1,49 @@
+package i2np
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The expected hashes are SHA-256 over bytes 32-527 of the record, computed
+// independently of this package from the spec layout.
+func TestBuildResponseRecordVectors(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, vector := range []struct {
+		padding byte
+		reply   byte
+		hash    string
+	}{
+		{0x00, 0, "882993b55cc0c527f0a6059b69b3faf4ef3ccb9cecd3d8847ca0e49a1444debe"},
+		{0x5a, 30, "d127b31c9e3764746859e1ed1efa731616802c838ba1d3c624030b737394f5c6"},
+	} {
+		record := BuildResponseRecord{Reply: vector.reply}
+		fill(record.Padding[:], vector.padding)
+		data := record.Bytes()
+		assert.Len(data, 528)
+		assert.Equal(vector.hash, hex.EncodeToString(data[:32]))
+		assert.Equal(vector.reply, data[527], "the reply is the last byte")
+
+		read, err := ReadBuildResponseRecord(data)
+		assert.Nil(err)
+		assert.Equal(vector.reply, read.Reply)
+		assert.Equal(record.Padding, read.Padding)
+		assert.Equal(vector.hash, hex.EncodeToString(read.Hash[:]))
+	}
+}
+
+func TestReadBuildResponseRecordErrors(t *testing.T) {
+	assert := assert.New(t)
+
+	data := BuildResponseRecord{Reply: 30}.Bytes()
+	_, err := ReadBuildResponseRecord(data[:527])
+	assert.Equal(ERR_BUILD_RESPONSE_RECORD_NOT_ENOUGH_DATA, err)
+
+	data[527] = 0
+	record, err := ReadBuildResponseRecord(data)
+	assert.Equal(ERR_BUILD_RESPONSE_RECORD_BAD_HASH, err)
+	assert.Equal(byte(0), record.Reply)
+}
@@ -1,7 +1,12 @@
 package i2np
 
 import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
 	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
 )
 
 /*
@@ -94,3 +99,54 @@ type DatabaseStore struct {
 	ReplyGateway  common.Hash
 	Data          []byte
 }
+
+// DatabaseStore types
+const (
+	DATABASE_STORE_TYPE_ROUTER_INFO = 0
+	DATABASE_STORE_TYPE_LEASE_SET   = 1
+	DATABASE_STORE_TYPE_LEASE_SET2  = 3
+)
+
+var ErrNotRouterInfoStore = errors.New("database store does not hold a router info")
+
+// NewRouterInfoDatabaseStore returns a DatabaseStore without a reply token
+// holding routerInfo compressed, keyed by its identity hash.
+func NewRouterInfoDatabaseStore(routerInfo *router_info.RouterInfo) (*DatabaseStore, error) {
+	compressed, err := routerInfo.CompressedBytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(compressed) > 0xffff {
+		return nil, fmt.Errorf("compressed router info of %d bytes is too large for a database store", len(compressed))
+	}
+	data := make([]byte, 2, 2+len(compressed))
+	binary.BigEndian.PutUint16(data, uint16(len(compressed)))
+	return &DatabaseStore{
+		Key:  routerInfo.IdentHash(),
+		Type: DATABASE_STORE_TYPE_ROUTER_INFO,
+		Data: append(data, compressed...),
+	}, nil
+}
+
+// RouterInfo decompresses the RouterInfo held by a DatabaseStore of type 0,
+// whose Data is the 2 byte length followed by the gzip compressed RouterInfo.
+func (store *DatabaseStore) RouterInfo() (*router_info.RouterInfo, error) {
+	if store.Type != DATABASE_STORE_TYPE_ROUTER_INFO {
+		return nil, ErrNotRouterInfoStore
+	}
+	if len(store.Data) < 2 {
+		return nil, ERR_I2NP_NOT_ENOUGH_DATA
+	}
+	length := int(binary.BigEndian.Uint16(store.Data[:2]))
+	if len(store.Data)-2 < length {
+		return nil, ERR_I2NP_NOT_ENOUGH_DATA
+	}
+	routerInfo, err := router_info.ReadCompressedRouterInfo(store.Data[2 : 2+length])
+	if err != nil {
+		return nil, err
+	}
+	if routerInfo.IdentHash() != store.Key {
+		return nil, errors.New("database store key does not match router info identity")
+	}
+	return &routerInfo, nil
+}
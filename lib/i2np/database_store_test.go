@@ -0,0 +1,40 @@
+package i2np
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterInfoDatabaseStore(t *testing.T) {
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	routerInfo, err := router_info.NewRouterInfoBuilder(identity, keys.SigningPrivateKey).SetOption("caps", "LR").Build()
+	require.NoError(t, err)
+
+	store, err := NewRouterInfoDatabaseStore(routerInfo)
+	require.NoError(t, err)
+	assert.Equal(t, routerInfo.IdentHash(), store.Key)
+
+	read, err := store.RouterInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "LR", read.RouterCapabilities())
+	assert.NoError(t, read.Verify())
+
+	store.Key[0] ^= 0xff
+	_, err = store.RouterInfo()
+	assert.Error(t, err)
+
+	store.Type = DATABASE_STORE_TYPE_LEASE_SET
+	_, err = store.RouterInfo()
+	assert.ErrorIs(t, err, ErrNotRouterInfoStore)
+
+	store.Type = DATABASE_STORE_TYPE_ROUTER_INFO
+	store.Data = store.Data[:10]
+	_, err = store.RouterInfo()
+	assert.ErrorIs(t, err, ERR_I2NP_NOT_ENOUGH_DATA)
+}
@@ -0,0 +1,78 @@
+package i2np
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/bloom"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MESSAGE_CLOCK_SKEW is how long after its expiration a message is still accepted
+	MESSAGE_CLOCK_SKEW = time.Minute
+	// MESSAGE_MAX_FUTURE is how far in the future a message may expire
+	MESSAGE_MAX_FUTURE = 2 * time.Minute
+	// DEFAULT_EXPECTED_MESSAGES is the number of messages per replay window
+	// the default validator is sized for
+	DEFAULT_EXPECTED_MESSAGES = 100000
+	// DEFAULT_REPLAY_FALSE_POSITIVE_RATE is the chance of the default
+	// validator dropping a message it has not seen
+	DEFAULT_REPLAY_FALSE_POSITIVE_RATE = 0.0001
+)
+
+var (
+	ErrMessageExpired   = errors.New("i2np message expired")
+	ErrMessageTooFuture = errors.New("i2np message expires too far in the future")
+	ErrMessageReplayed  = errors.New("i2np message id already seen")
+)
+
+// MessageValidator rejects expired and replayed I2NP messages. One validator
+// is shared by everything that receives messages, the inbound tunnel gateways
+// and the garlic receiver, so a message replayed through a different path is
+// still caught.
+type MessageValidator struct {
+	filter *bloom.DecayingFilter
+	now    func() time.Time
+}
+
+// NewMessageValidator returns a validator sized for expected messages per
+// replay window, dropping unseen messages at falsePositiveRate.
+func NewMessageValidator(expected int, falsePositiveRate float64) *MessageValidator {
+	// a message has to be remembered until it is rejected as expired anyway
+	window := MESSAGE_MAX_FUTURE + MESSAGE_CLOCK_SKEW
+	return &MessageValidator{
+		filter: bloom.NewDecayingFilter(window, expected, falsePositiveRate),
+		now:    time.Now,
+	}
+}
+
+// Validate checks the expiration of a message and records its ID, returning
+// an error if the message should be dropped.
+func (validator *MessageValidator) Validate(messageID int, expiration time.Time) error {
+	now := validator.now()
+	if expiration.Before(now.Add(-MESSAGE_CLOCK_SKEW)) {
+		return ErrMessageExpired
+	}
+	if expiration.After(now.Add(MESSAGE_MAX_FUTURE + MESSAGE_CLOCK_SKEW)) {
+		return ErrMessageTooFuture
+	}
+	// the same ID with a different expiration is a different message
+	var key [12]byte
+	binary.BigEndian.PutUint32(key[:4], uint32(messageID))
+	binary.BigEndian.PutUint64(key[4:], uint64(expiration.UnixMilli()))
+	if validator.filter.Add(key[:]) {
+		log.WithFields(logrus.Fields{
+			"message_id": messageID,
+			"expiration": expiration,
+		}).Warn("Dropping replayed I2NP message")
+		return ErrMessageReplayed
+	}
+	return nil
+}
+
+// Stats returns the counters of the replay filter.
+func (validator *MessageValidator) Stats() bloom.FilterStats {
+	return validator.filter.Stats()
+}
@@ -0,0 +1,25 @@
+package i2np
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageValidator(t *testing.T) {
+	assert := assert.New(t)
+	validator := NewMessageValidator(1000, 0.001)
+	now := time.Now()
+	validator.now = func() time.Time { return now }
+
+	expiration := now.Add(30 * time.Second)
+	assert.NoError(validator.Validate(42, expiration))
+	assert.ErrorIs(validator.Validate(42, expiration), ErrMessageReplayed)
+	assert.NoError(validator.Validate(42, expiration.Add(time.Second)))
+	assert.NoError(validator.Validate(43, expiration))
+
+	assert.ErrorIs(validator.Validate(44, now.Add(-2*MESSAGE_CLOCK_SKEW)), ErrMessageExpired)
+	assert.ErrorIs(validator.Validate(45, now.Add(time.Hour)), ErrMessageTooFuture)
+	assert.Equal(uint64(1), validator.Stats().Duplicates)
+}
@@ -0,0 +1,66 @@
+package mobile
+
+// Network types reported by the platform to SetNetwork.
+const (
+	NETWORK_NONE     = 0
+	NETWORK_WIFI     = 1
+	NETWORK_CELLULAR = 2
+	NETWORK_ETHERNET = 3
+)
+
+// Tunnel quantities chosen by conditions. DEFAULT_TUNNEL_QUANTITY is the
+// quantity of a desktop router; it is reduced on metered networks, in the
+// background and on low battery, to a single tunnel of each direction.
+const (
+	DEFAULT_TUNNEL_QUANTITY  = 3
+	METERED_TUNNEL_QUANTITY  = 2
+	MIN_TUNNEL_QUANTITY      = 1
+	LOW_BATTERY_PERCENT      = 20
+	CRITICAL_BATTERY_PERCENT = 5
+)
+
+// conditions are what the platform told us about the device
+type conditions struct {
+	foreground bool
+	network    int
+	battery    int
+	charging   bool
+}
+
+// defaultConditions assumes a foreground app on wifi with a full battery
+// until the platform reports otherwise
+func defaultConditions() conditions {
+	return conditions{
+		foreground: true,
+		network:    NETWORK_WIFI,
+		battery:    100,
+		charging:   true,
+	}
+}
+
+// online reports whether there is a network to run on
+func (c conditions) online() bool {
+	return c.network != NETWORK_NONE
+}
+
+// throttled reports whether the router should use as little CPU and radio
+// time as it can: in the background, or on a low battery not charging
+func (c conditions) throttled() bool {
+	return !c.foreground || (!c.charging && c.battery <= LOW_BATTERY_PERCENT)
+}
+
+// tunnelQuantity is the number of client tunnels of each direction to keep
+// under the conditions, 0 when there is no point keeping any
+func (c conditions) tunnelQuantity() int {
+	switch {
+	case !c.online():
+		return 0
+	case !c.charging && c.battery <= CRITICAL_BATTERY_PERCENT:
+		return 0
+	case c.throttled():
+		return MIN_TUNNEL_QUANTITY
+	case c.network == NETWORK_CELLULAR:
+		return METERED_TUNNEL_QUANTITY
+	}
+	return DEFAULT_TUNNEL_QUANTITY
+}
@@ -0,0 +1,260 @@
+// Package mobile is the facade gomobile binds to embed go-i2p in Android and
+// iOS apps:
+//
+//	gomobile bind -target android github.com/go-i2p/go-i2p/lib/mobile
+//
+// Its API only uses types gomobile supports, strings, numbers, errors and
+// interfaces implemented on the platform side, and reports router state as
+// JSON. The app forwards the lifecycle events of the device, background and
+// foreground, network and battery changes, and the router scales its work
+// down accordingly.
+package mobile
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/router"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// States reported to the Listener.
+const (
+	STATE_STOPPED   = "stopped"
+	STATE_RUNNING   = "running"
+	STATE_THROTTLED = "throttled"
+	STATE_OFFLINE   = "offline"
+)
+
+// Listener is implemented by the app to be told of router state changes.
+// Its methods are called from the goroutine reporting the change, which is
+// not the platform's main thread.
+type Listener interface {
+	// OnStateChanged is called with one of the STATE constants and the
+	// number of client tunnels of each direction to keep
+	OnStateChanged(state string, tunnelQuantity int)
+}
+
+// Router is an embedded I2P router.
+type Router struct {
+	mutex      sync.Mutex
+	router     *router.Router
+	cfg        *config.RouterConfig
+	conditions conditions
+	listener   Listener
+	running    bool
+	closed     bool
+	// closed once the started router has stopped
+	stopped chan struct{}
+	state   string
+}
+
+// mobileConfig is the default configuration with every path under dataDir,
+// and without the desktop duties a phone should not take on: no floodfill,
+// no self update, app stores ship updates
+func mobileConfig(dataDir string) *config.RouterConfig {
+	netDb := config.DefaultNetDbConfig
+	netDb.Path = filepath.Join(dataDir, "netDb")
+	netDb.Floodfill = netdb.FLOODFILL_OFF
+	bootstrap := config.DefaultBootstrapConfig
+	workers := config.DefaultWorkerConfig("L")
+	report := config.DefaultReportConfig
+	report.Enabled = false
+	update := config.DefaultUpdateConfig
+	update.Enabled = false
+	peers := config.DefaultPeerConfig
+	clients := config.DefaultClientConfig
+	memory := config.DefaultMemoryConfig
+	return &config.RouterConfig{
+		BaseDir:    filepath.Join(dataDir, "base"),
+		WorkingDir: filepath.Join(dataDir, "config"),
+		NetDb:      &netDb,
+		Bootstrap:  &bootstrap,
+		Workers:    &workers,
+		Report:     &report,
+		Update:     &update,
+		Peers:      &peers,
+		Clients:    &clients,
+		Memory:     &memory,
+	}
+}
+
+// NewRouter creates a router keeping its files in dataDir, which should be
+// the app's private files directory.
+func NewRouter(dataDir string) (*Router, error) {
+	cfg := mobileConfig(dataDir)
+	r, err := router.FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Router{
+		router:     r,
+		cfg:        cfg,
+		conditions: defaultConditions(),
+		state:      STATE_STOPPED,
+	}, nil
+}
+
+// SetListener sets the listener told of state changes, nil for none.
+func (r *Router) SetListener(listener Listener) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.listener = listener
+}
+
+// Start starts the router. It does nothing once the router was stopped.
+func (r *Router) Start() {
+	r.mutex.Lock()
+	if r.running || r.closed {
+		r.mutex.Unlock()
+		return
+	}
+	r.running = true
+	r.router.Start()
+	r.stopped = make(chan struct{})
+	go func(stopped chan struct{}) {
+		r.router.Wait()
+		close(stopped)
+	}(r.stopped)
+	notify := r.update()
+	r.mutex.Unlock()
+	notify()
+}
+
+// Stop stops the router, waits for its mainloop to return and closes it.
+// The Router cannot be started again; create a new one.
+func (r *Router) Stop() {
+	r.mutex.Lock()
+	if !r.running {
+		r.mutex.Unlock()
+		return
+	}
+	r.running = false
+	r.closed = true
+	r.router.Stop()
+	<-r.stopped
+	if err := r.router.Close(); err != nil {
+		log.WithError(err).Error("Failed to close router")
+	}
+	notify := r.update()
+	r.mutex.Unlock()
+	notify()
+}
+
+// IsRunning reports whether the router was started and not stopped.
+func (r *Router) IsRunning() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.running
+}
+
+// State returns one of the STATE constants.
+func (r *Router) State() string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.state
+}
+
+// TunnelQuantity returns the number of client tunnels of each direction to
+// keep under the current conditions, for the app's client sessions.
+func (r *Router) TunnelQuantity() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.conditions.tunnelQuantity()
+}
+
+// StatusJSON returns the router's StateReport as JSON.
+func (r *Router) StatusJSON() (string, error) {
+	data, err := json.Marshal(r.router.Report())
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// SetForeground is called when the app moves to the foreground (true) or
+// the background (false).
+func (r *Router) SetForeground(foreground bool) {
+	r.mutex.Lock()
+	r.conditions.foreground = foreground
+	notify := r.update()
+	r.mutex.Unlock()
+	notify()
+}
+
+// SetNetwork is called when the device's network changes, with one of the
+// NETWORK constants.
+func (r *Router) SetNetwork(network int) {
+	r.mutex.Lock()
+	r.conditions.network = network
+	notify := r.update()
+	r.mutex.Unlock()
+	notify()
+}
+
+// SetBattery is called when the battery level in percent or the charging
+// state changes.
+func (r *Router) SetBattery(percent int, charging bool) {
+	r.mutex.Lock()
+	r.conditions.battery = percent
+	r.conditions.charging = charging
+	notify := r.update()
+	r.mutex.Unlock()
+	notify()
+}
+
+// update applies the conditions to the router. The mutex must be held; the
+// returned function tells the listener of a state change and must be called
+// once it is released.
+func (r *Router) update() (notify func()) {
+	state := STATE_STOPPED
+	switch {
+	case !r.running:
+	case !r.conditions.online():
+		state = STATE_OFFLINE
+	case r.conditions.throttled():
+		state = STATE_THROTTLED
+	default:
+		state = STATE_RUNNING
+	}
+	if r.running {
+		r.applyWorkers(state == STATE_RUNNING)
+	}
+	if state == r.state {
+		return func() {}
+	}
+	r.state = state
+	quantity := r.conditions.tunnelQuantity()
+	log.WithFields(logrus.Fields{
+		"state":           state,
+		"tunnel_quantity": quantity,
+		"foreground":      r.conditions.foreground,
+		"network":         r.conditions.network,
+		"battery":         r.conditions.battery,
+	}).Info("Mobile router state changed")
+	listener := r.listener
+	return func() {
+		if listener != nil {
+			listener.OnStateChanged(state, quantity)
+		}
+	}
+}
+
+// applyWorkers runs the worker pools at their configured size when full,
+// with a single worker each otherwise
+func (r *Router) applyWorkers(full bool) {
+	cfg := *r.cfg.Workers
+	if !full {
+		cfg.NetDb, cfg.TunnelBuild = 1, 1
+	}
+	if err := r.router.ApplyWorkerConfig(cfg); err != nil {
+		log.WithError(err).Warn("Failed to resize worker pools")
+	}
+}
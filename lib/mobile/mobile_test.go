@@ -0,0 +1,75 @@
+package mobile
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/router"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingListener struct {
+	mutex  sync.Mutex
+	states []string
+}
+
+func (l *recordingListener) OnStateChanged(state string, tunnelQuantity int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.states = append(l.states, state)
+}
+
+func TestTunnelQuantity(t *testing.T) {
+	for name, c := range map[string]struct {
+		modify func(*conditions)
+		want   int
+	}{
+		"foreground wifi":  {func(*conditions) {}, DEFAULT_TUNNEL_QUANTITY},
+		"cellular":         {func(c *conditions) { c.network = NETWORK_CELLULAR }, METERED_TUNNEL_QUANTITY},
+		"background":       {func(c *conditions) { c.foreground = false }, MIN_TUNNEL_QUANTITY},
+		"low battery":      {func(c *conditions) { c.battery, c.charging = 15, false }, MIN_TUNNEL_QUANTITY},
+		"low but charging": {func(c *conditions) { c.battery = 15 }, DEFAULT_TUNNEL_QUANTITY},
+		"critical battery": {func(c *conditions) { c.battery, c.charging = 3, false }, 0},
+		"offline":          {func(c *conditions) { c.network = NETWORK_NONE }, 0},
+	} {
+		conditions := defaultConditions()
+		c.modify(&conditions)
+		assert.Equal(t, c.want, conditions.tunnelQuantity(), name)
+	}
+}
+
+func TestRouterLifecycle(t *testing.T) {
+	r, err := NewRouter(t.TempDir())
+	require.NoError(t, err)
+	listener := &recordingListener{}
+	r.SetListener(listener)
+	assert.Equal(t, STATE_STOPPED, r.State())
+	status, err := r.StatusJSON()
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(status)))
+
+	r.Start()
+	assert.True(t, r.IsRunning())
+	assert.Equal(t, STATE_RUNNING, r.State())
+
+	r.SetForeground(false)
+	assert.Equal(t, STATE_THROTTLED, r.State())
+	assert.Equal(t, MIN_TUNNEL_QUANTITY, r.TunnelQuantity())
+	assert.Equal(t, 1, r.router.WorkerSizes()[router.WORKERS_NETDB], "background runs one worker per pool")
+
+	r.SetNetwork(NETWORK_NONE)
+	assert.Equal(t, STATE_OFFLINE, r.State())
+	r.SetNetwork(NETWORK_WIFI)
+	r.SetForeground(true)
+	assert.Equal(t, STATE_RUNNING, r.State())
+	assert.Equal(t, r.cfg.Workers.NetDb, r.router.WorkerSizes()[router.WORKERS_NETDB])
+
+	r.Stop()
+	assert.False(t, r.IsRunning())
+	r.Start()
+	assert.False(t, r.IsRunning(), "a stopped router does not start again")
+
+	assert.Equal(t, []string{STATE_RUNNING, STATE_THROTTLED, STATE_OFFLINE, STATE_THROTTLED, STATE_RUNNING, STATE_STOPPED}, listener.states)
+}
@@ -0,0 +1,207 @@
+// Package analytics summarizes the RouterInfos in the local netdb into
+// distributions describing the health of the network: which versions,
+// signature types and transports routers use, and where they are.
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// UNKNOWN is the bucket for routers a value could not be determined for
+const UNKNOWN = "unknown"
+
+// signature type names as the Java router displays them
+var sigTypeNames = map[int]string{
+	signature.SIGNATURE_TYPE_DSA_SHA1:               "DSA_SHA1",
+	signature.SIGNATURE_TYPE_ECDSA_SHA256_P256:      "ECDSA_SHA256_P256",
+	signature.SIGNATURE_TYPE_ECDSA_SHA384_P384:      "ECDSA_SHA384_P384",
+	signature.SIGNATURE_TYPE_ECDSA_SHA512_P521:      "ECDSA_SHA512_P521",
+	signature.SIGNATURE_TYPE_RSA_SHA256_2048:        "RSA_SHA256_2048",
+	signature.SIGNATURE_TYPE_RSA_SHA384_3072:        "RSA_SHA384_3072",
+	signature.SIGNATURE_TYPE_RSA_SHA512_4096:        "RSA_SHA512_4096",
+	signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519:   "EdDSA_SHA512_Ed25519",
+	signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519PH: "EdDSA_SHA512_Ed25519ph",
+	signature.SIGNATURE_TYPE_REDDSA_SHA512_ED25519:  "RedDSA_SHA512_Ed25519",
+}
+
+// CountryFunc maps an IP address onto a country code. The repository ships
+// no GeoIP database, callers plug in their own.
+type CountryFunc func(net.IP) string
+
+// Distribution counts routers per value.
+type Distribution map[string]int
+
+// Share is one value of a Distribution.
+type Share struct {
+	Name    string  `json:"name"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+// Shares returns the values of the distribution, most common first, as a
+// share of total routers.
+func (d Distribution) Shares(total int) []Share {
+	shares := make([]Share, 0, len(d))
+	for name, count := range d {
+		share := Share{Name: name, Count: count}
+		if total > 0 {
+			share.Percent = 100 * float64(count) / float64(total)
+		}
+		shares = append(shares, share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Count != shares[j].Count {
+			return shares[i].Count > shares[j].Count
+		}
+		return shares[i].Name < shares[j].Name
+	})
+	return shares
+}
+
+// Report holds the distributions computed from a set of RouterInfos.
+type Report struct {
+	Generated time.Time    `json:"generated"`
+	Routers   int          `json:"routers"`
+	Versions  Distribution `json:"versions"`
+	SigTypes  Distribution `json:"signature_types"`
+	// Transports counts routers publishing each transport, so a router
+	// with several transports is counted once for each
+	Transports Distribution `json:"transports"`
+	// Countries is empty unless a CountryFunc was given
+	Countries Distribution `json:"countries"`
+}
+
+// Analyze computes a report over infos. country may be nil.
+func Analyze(infos []*router_info.RouterInfo, country CountryFunc) *Report {
+	report := &Report{
+		Generated:  time.Now(),
+		Versions:   make(Distribution),
+		SigTypes:   make(Distribution),
+		Transports: make(Distribution),
+		Countries:  make(Distribution),
+	}
+	for _, info := range infos {
+		if info == nil {
+			continue
+		}
+		report.Routers++
+		report.Versions[valueOrUnknown(info.RouterVersion())]++
+		report.SigTypes[sigTypeName(info)]++
+		seen := make(map[string]bool)
+		for _, address := range info.RouterAddresses() {
+			style, err := address.TransportStyle().Data()
+			if err != nil || style == "" || seen[style] {
+				continue
+			}
+			seen[style] = true
+			report.Transports[style]++
+		}
+		if country != nil {
+			report.Countries[valueOrUnknown(routerCountry(info, country))]++
+		}
+	}
+	log.WithField("routers", report.Routers).Debug("Computed netdb analytics")
+	return report
+}
+
+// AnalyzeNetDB computes a report over the RouterInfos loaded into db.
+func AnalyzeNetDB(db *netdb.StdNetDB, country CountryFunc) *Report {
+	return Analyze(db.KnownRouterInfos(), country)
+}
+
+// WriteJSON writes the report as indented JSON.
+func (report *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteCharts writes the report as text bar charts for a terminal, with
+// bars at most width characters long.
+func (report *Report) WriteCharts(w io.Writer, width int) error {
+	if width < 1 {
+		width = 40
+	}
+	if _, err := fmt.Fprintf(w, "%d routers\n", report.Routers); err != nil {
+		return err
+	}
+	charts := []struct {
+		title        string
+		distribution Distribution
+	}{
+		{"Versions", report.Versions},
+		{"Signature types", report.SigTypes},
+		{"Transports", report.Transports},
+		{"Countries", report.Countries},
+	}
+	for _, chart := range charts {
+		if len(chart.distribution) == 0 {
+			continue
+		}
+		if err := writeChart(w, chart.title, chart.distribution.Shares(report.Routers), width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeChart(w io.Writer, title string, shares []Share, width int) error {
+	nameWidth := 0
+	for _, share := range shares {
+		if len(share.Name) > nameWidth {
+			nameWidth = len(share.Name)
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\n%s\n", title); err != nil {
+		return err
+	}
+	for _, share := range shares {
+		bar := strings.Repeat("#", int(share.Percent*float64(width)/100+0.5))
+		if _, err := fmt.Fprintf(w, "  %-*s %6.2f%% %6d %s\n", nameWidth, share.Name, share.Percent, share.Count, bar); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sigTypeName(info *router_info.RouterInfo) string {
+	sigType := signature.SIGNATURE_TYPE_DSA_SHA1
+	if cert := info.RouterIdentity().KeyCertificate; cert != nil {
+		sigType = cert.SigningPublicKeyType()
+	}
+	if name, ok := sigTypeNames[sigType]; ok {
+		return name
+	}
+	return "type " + strconv.Itoa(sigType)
+}
+
+// routerCountry returns the country of the first published IP address
+func routerCountry(info *router_info.RouterInfo, country CountryFunc) string {
+	for _, address := range info.RouterAddresses() {
+		if ip, err := address.HostIP(); err == nil {
+			return country(ip)
+		}
+	}
+	return ""
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return UNKNOWN
+	}
+	return value
+}
@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/certificate"
+	"github.com/go-i2p/go-i2p/lib/common/keys_and_cert"
+	"github.com/go-i2p/go-i2p/lib/common/router_address"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouterInfo builds an Ed25519 signed RouterInfo. The ElGamal key is
+// not a real key, nothing here encrypts to it.
+func newTestRouterInfo(t *testing.T, version string, addresses map[string]string) *router_info.RouterInfo {
+	var signingKey crypto.Ed25519PrivateKey
+	privateKey, err := signingKey.Generate()
+	require.NoError(t, err)
+	publicKey, err := privateKey.Public()
+	require.NoError(t, err)
+	cert, err := certificate.NewCertificateWithType(certificate.CERT_KEY, []byte{0x00, 0x07, 0x00, 0x00})
+	require.NoError(t, err)
+	var elgKey crypto.ElgPublicKey
+	padding := make([]byte, keys_and_cert.KEYS_AND_CERT_DATA_SIZE-len(elgKey)-publicKey.Len())
+	identity, err := router_identity.NewRouterIdentity(elgKey, publicKey, *cert, padding)
+	require.NoError(t, err)
+
+	builder := router_info.NewRouterInfoBuilder(identity, privateKey).SetOption("router.version", version)
+	for style, host := range addresses {
+		address, err := router_address.NewRouterAddress(10, time.Unix(0, 0), style, map[string]string{"host": host, "port": "12345"})
+		require.NoError(t, err)
+		builder.AddAddress(address)
+	}
+	info, err := builder.Build()
+	require.NoError(t, err)
+	return info
+}
+
+func TestAnalyze(t *testing.T) {
+	infos := []*router_info.RouterInfo{
+		newTestRouterInfo(t, "0.9.64", map[string]string{"NTCP2": "192.0.2.1", "SSU2": "192.0.2.1"}),
+		newTestRouterInfo(t, "0.9.64", map[string]string{"NTCP2": "198.51.100.1"}),
+		newTestRouterInfo(t, "0.9.63", nil),
+	}
+	country := func(ip net.IP) string {
+		if ip.Equal(net.ParseIP("192.0.2.1")) {
+			return "de"
+		}
+		return "nl"
+	}
+	report := Analyze(infos, country)
+
+	assert.Equal(t, 3, report.Routers)
+	assert.Equal(t, Distribution{"0.9.64": 2, "0.9.63": 1}, report.Versions)
+	assert.Equal(t, Distribution{"EdDSA_SHA512_Ed25519": 3}, report.SigTypes)
+	assert.Equal(t, Distribution{"NTCP2": 2, "SSU2": 1}, report.Transports)
+	assert.Equal(t, Distribution{"de": 1, "nl": 1, UNKNOWN: 1}, report.Countries)
+
+	assert.Empty(t, Analyze(infos, nil).Countries)
+}
+
+func TestDistributionShares(t *testing.T) {
+	shares := Distribution{"b": 1, "a": 1, "c": 2}.Shares(4)
+	assert.Equal(t, []Share{
+		{Name: "c", Count: 2, Percent: 50},
+		{Name: "a", Count: 1, Percent: 25},
+		{Name: "b", Count: 1, Percent: 25},
+	}, shares)
+}
+
+func TestReportOutput(t *testing.T) {
+	report := &Report{
+		Routers:    4,
+		Versions:   Distribution{"0.9.64": 3, "0.9.63": 1},
+		SigTypes:   Distribution{"EdDSA_SHA512_Ed25519": 4},
+		Transports: Distribution{},
+		Countries:  Distribution{},
+	}
+	var charts bytes.Buffer
+	require.NoError(t, report.WriteCharts(&charts, 20))
+	assert.Contains(t, charts.String(), "4 routers")
+	assert.Contains(t, charts.String(), "0.9.64  75.00%      3 ###############\n")
+	assert.NotContains(t, charts.String(), "Countries")
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, 3.0, decoded["versions"].(map[string]interface{})["0.9.64"])
+}
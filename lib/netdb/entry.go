@@ -12,6 +12,15 @@ import (
 type Entry struct {
 	*router_info.RouterInfo
 	*lease_set.LeaseSet
+	// serialized size, 0 until computed
+	size int64
+}
+
+// newRouterInfoEntry returns an Entry holding ri with its size computed
+func newRouterInfoEntry(ri *router_info.RouterInfo) Entry {
+	entry := Entry{RouterInfo: ri}
+	entry.size = entrySize(entry)
+	return entry
 }
 
 func (e *Entry) WriteTo(w io.Writer) (err error) {
@@ -0,0 +1,190 @@
+package netdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+// Floodfill modes, the values of the Java router's
+// router.floodfillParticipant setting.
+const (
+	// FLOODFILL_AUTO lets the FloodfillMonitor decide
+	FLOODFILL_AUTO = "auto"
+	// FLOODFILL_ON always runs as a floodfill
+	FLOODFILL_ON = "true"
+	// FLOODFILL_OFF never runs as a floodfill
+	FLOODFILL_OFF = "false"
+)
+
+// Defaults of FloodfillPolicy, after the Java router's FloodfillMonitorJob.
+const (
+	// DEFAULT_FLOODFILL_MIN_UPTIME is how long the router must run before it
+	// becomes a floodfill
+	DEFAULT_FLOODFILL_MIN_UPTIME = 2 * time.Hour
+	// DEFAULT_FLOODFILL_MAX_CLOCK_SKEW is the largest clock offset a
+	// floodfill may have, its stores are judged by the dates in them
+	DEFAULT_FLOODFILL_MAX_CLOCK_SKEW = 20 * time.Second
+	// DEFAULT_FLOODFILL_MAX_MESSAGE_DELAY is the message processing delay
+	// above which a floodfill steps down
+	DEFAULT_FLOODFILL_MAX_MESSAGE_DELAY = time.Second
+	// DEFAULT_FLOODFILL_TARGET is how many floodfills the netdb should know
+	// of before we stop volunteering
+	DEFAULT_FLOODFILL_TARGET = 500
+	// DEFAULT_FLOODFILL_CHANGE_INTERVAL is the shortest time between
+	// becoming a floodfill and the last change, so the router does not flap
+	DEFAULT_FLOODFILL_CHANGE_INTERVAL = time.Hour
+)
+
+var ErrInvalidFloodfillMode = errors.New("invalid floodfill mode")
+
+// ParseFloodfillMode normalizes a configured floodfill mode. The empty string
+// is FLOODFILL_AUTO.
+func ParseFloodfillMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", FLOODFILL_AUTO:
+		return FLOODFILL_AUTO, nil
+	case FLOODFILL_ON:
+		return FLOODFILL_ON, nil
+	case FLOODFILL_OFF:
+		return FLOODFILL_OFF, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidFloodfillMode, mode)
+}
+
+// FloodfillStatus is what the router knows of itself when the
+// FloodfillMonitor decides.
+type FloodfillStatus struct {
+	// Uptime is how long the router has been running
+	Uptime time.Duration
+	// Capabilities are the caps the router publishes, without the floodfill
+	// letter; bandwidth tier, reachability, hidden and congestion are used
+	Capabilities router_info.Capabilities
+	// ClockSkew is the offset of the local clock from network time
+	ClockSkew time.Duration
+	// MessageDelay is how long inbound messages wait to be processed
+	MessageDelay time.Duration
+	// KnownFloodfills is the number of floodfills in the netdb
+	KnownFloodfills int
+}
+
+// FloodfillPolicy holds the thresholds of automatic floodfill duty.
+type FloodfillPolicy struct {
+	MinUptime       time.Duration
+	MaxClockSkew    time.Duration
+	MaxMessageDelay time.Duration
+	Target          int
+	ChangeInterval  time.Duration
+}
+
+// DefaultFloodfillPolicy returns the policy of the Java router.
+func DefaultFloodfillPolicy() FloodfillPolicy {
+	return FloodfillPolicy{
+		MinUptime:       DEFAULT_FLOODFILL_MIN_UPTIME,
+		MaxClockSkew:    DEFAULT_FLOODFILL_MAX_CLOCK_SKEW,
+		MaxMessageDelay: DEFAULT_FLOODFILL_MAX_MESSAGE_DELAY,
+		Target:          DEFAULT_FLOODFILL_TARGET,
+		ChangeInterval:  DEFAULT_FLOODFILL_CHANGE_INTERVAL,
+	}
+}
+
+// capable returns why the router cannot serve as a floodfill, or "" if it can
+func (policy FloodfillPolicy) capable(status FloodfillStatus) string {
+	caps := status.Capabilities
+	switch {
+	case caps.Hidden:
+		return "hidden"
+	case !caps.Reachable || caps.Unreachable:
+		return "not reachable"
+	case caps.Bandwidth != router_info.BANDWIDTH_O && caps.Bandwidth != router_info.BANDWIDTH_P && caps.Bandwidth != router_info.BANDWIDTH_X:
+		return "bandwidth below class O"
+	case caps.Congestion == router_info.CONGESTION_E || caps.Congestion == router_info.CONGESTION_G:
+		return "congested"
+	case status.MessageDelay > policy.MaxMessageDelay:
+		return "message delay too high"
+	case status.ClockSkew > policy.MaxClockSkew || status.ClockSkew < -policy.MaxClockSkew:
+		return "clock skew too large"
+	}
+	return ""
+}
+
+// FloodfillMonitor decides whether the router serves as a floodfill.
+//
+// In FLOODFILL_AUTO mode a router volunteers once it has been up for
+// MinUptime, is reachable, not hidden, shares bandwidth class O or above, has
+// an accurate clock, keeps up with its messages and the netdb knows fewer
+// floodfills than Target. It steps down at once when it stops being capable,
+// under load for example, and a floodfill already serving does not step down
+// because others joined. Becoming a floodfill again waits ChangeInterval after
+// the last change. FLOODFILL_ON and FLOODFILL_OFF fix the decision.
+type FloodfillMonitor struct {
+	mutex      sync.Mutex
+	mode       string
+	policy     FloodfillPolicy
+	floodfill  bool
+	lastChange time.Time
+}
+
+// NewFloodfillMonitor returns a monitor for mode, which must have been
+// checked by ParseFloodfillMode.
+func NewFloodfillMonitor(mode string, policy FloodfillPolicy) *FloodfillMonitor {
+	return &FloodfillMonitor{
+		mode:      mode,
+		policy:    policy,
+		floodfill: mode == FLOODFILL_ON,
+	}
+}
+
+// Mode returns the floodfill mode.
+func (monitor *FloodfillMonitor) Mode() string {
+	return monitor.mode
+}
+
+// Floodfill reports whether the router currently serves as a floodfill.
+func (monitor *FloodfillMonitor) Floodfill() bool {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	return monitor.floodfill
+}
+
+// Update decides from status at now and returns whether the router serves as
+// a floodfill, and whether that changed.
+func (monitor *FloodfillMonitor) Update(status FloodfillStatus, now time.Time) (floodfill, changed bool) {
+	monitor.mutex.Lock()
+	defer monitor.mutex.Unlock()
+	if monitor.mode != FLOODFILL_AUTO {
+		return monitor.floodfill, false
+	}
+	reason := monitor.policy.capable(status)
+	switch {
+	case monitor.floodfill && reason != "":
+		log.WithField("reason", reason).Info("Stepping down from floodfill duty")
+		monitor.set(false, now)
+		return false, true
+	case monitor.floodfill || reason != "":
+		return monitor.floodfill, false
+	case status.Uptime < monitor.policy.MinUptime:
+		return false, false
+	case status.KnownFloodfills >= monitor.policy.Target:
+		return false, false
+	case !monitor.lastChange.IsZero() && now.Sub(monitor.lastChange) < monitor.policy.ChangeInterval:
+		return false, false
+	}
+	log.WithFields(logrus.Fields{
+		"uptime":           status.Uptime,
+		"known_floodfills": status.KnownFloodfills,
+	}).Info("Volunteering for floodfill duty")
+	monitor.set(true, now)
+	return true, true
+}
+
+func (monitor *FloodfillMonitor) set(floodfill bool, now time.Time) {
+	monitor.floodfill = floodfill
+	monitor.lastChange = now
+}
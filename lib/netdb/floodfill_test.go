@@ -0,0 +1,95 @@
+package netdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+func capableStatus() FloodfillStatus {
+	return FloodfillStatus{
+		Uptime:          3 * time.Hour,
+		Capabilities:    router_info.ParseCapabilities("OPR"),
+		KnownFloodfills: 100,
+	}
+}
+
+func TestParseFloodfillMode(t *testing.T) {
+	for in, want := range map[string]string{"": FLOODFILL_AUTO, "Auto": FLOODFILL_AUTO, "true": FLOODFILL_ON, " false": FLOODFILL_OFF} {
+		mode, err := ParseFloodfillMode(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, mode, in)
+	}
+	_, err := ParseFloodfillMode("sometimes")
+	assert.ErrorIs(t, err, ErrInvalidFloodfillMode)
+}
+
+func TestFloodfillMonitorPromotesAndDemotes(t *testing.T) {
+	monitor := NewFloodfillMonitor(FLOODFILL_AUTO, DefaultFloodfillPolicy())
+	now := time.Now()
+
+	status := capableStatus()
+	status.Uptime = time.Hour
+	floodfill, changed := monitor.Update(status, now)
+	assert.False(t, floodfill || changed, "uptime too short")
+
+	floodfill, changed = monitor.Update(capableStatus(), now)
+	assert.True(t, floodfill)
+	assert.True(t, changed)
+	assert.True(t, monitor.Floodfill())
+
+	// more floodfills joining do not push us out
+	status = capableStatus()
+	status.KnownFloodfills = DEFAULT_FLOODFILL_TARGET * 2
+	floodfill, changed = monitor.Update(status, now)
+	assert.True(t, floodfill)
+	assert.False(t, changed)
+
+	// load does
+	status = capableStatus()
+	status.MessageDelay = 2 * time.Second
+	floodfill, changed = monitor.Update(status, now.Add(time.Minute))
+	assert.False(t, floodfill)
+	assert.True(t, changed)
+
+	// and we wait before volunteering again
+	floodfill, _ = monitor.Update(capableStatus(), now.Add(30*time.Minute))
+	assert.False(t, floodfill)
+	floodfill, _ = monitor.Update(capableStatus(), now.Add(time.Minute+DEFAULT_FLOODFILL_CHANGE_INTERVAL))
+	assert.True(t, floodfill)
+}
+
+func TestFloodfillMonitorRequirements(t *testing.T) {
+	for name, modify := range map[string]func(*FloodfillStatus){
+		"slow":        func(s *FloodfillStatus) { s.Capabilities = router_info.ParseCapabilities("NR") },
+		"firewalled":  func(s *FloodfillStatus) { s.Capabilities = router_info.ParseCapabilities("OPU") },
+		"hidden":      func(s *FloodfillStatus) { s.Capabilities = router_info.ParseCapabilities("OPHR") },
+		"congested":   func(s *FloodfillStatus) { s.Capabilities = router_info.ParseCapabilities("OPRE") },
+		"clock skew":  func(s *FloodfillStatus) { s.ClockSkew = -time.Minute },
+		"enough ffs":  func(s *FloodfillStatus) { s.KnownFloodfills = DEFAULT_FLOODFILL_TARGET },
+		"short lived": func(s *FloodfillStatus) { s.Uptime = time.Minute },
+	} {
+		status := capableStatus()
+		modify(&status)
+		floodfill, _ := NewFloodfillMonitor(FLOODFILL_AUTO, DefaultFloodfillPolicy()).Update(status, time.Now())
+		assert.False(t, floodfill, name)
+	}
+}
+
+func TestFloodfillMonitorManualModes(t *testing.T) {
+	on := NewFloodfillMonitor(FLOODFILL_ON, DefaultFloodfillPolicy())
+	status := capableStatus()
+	status.Capabilities = router_info.ParseCapabilities("LU")
+	floodfill, changed := on.Update(status, time.Now())
+	assert.True(t, floodfill)
+	assert.False(t, changed)
+
+	off := NewFloodfillMonitor(FLOODFILL_OFF, DefaultFloodfillPolicy())
+	floodfill, changed = off.Update(capableStatus(), time.Now())
+	assert.False(t, floodfill)
+	assert.False(t, changed)
+}
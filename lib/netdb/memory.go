@@ -0,0 +1,79 @@
+package netdb
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+// MemoryUsage returns the number of RouterInfos and LeaseSets held in memory
+// and their size serialized, which the parsed forms roughly match. Sizes are
+// computed once per entry and cached.
+func (db *StdNetDB) MemoryUsage() (entries int, bytes int64) {
+	defer db.writeLock()()
+	for _, table := range []map[common.Hash]Entry{db.RouterInfos, db.LeaseSets} {
+		for hash, entry := range table {
+			if entry.size == 0 {
+				entry.size = entrySize(entry)
+				table[hash] = entry
+			}
+			bytes += entry.size
+		}
+	}
+	return len(db.RouterInfos) + len(db.LeaseSets), bytes
+}
+
+// Evict drops RouterInfos from memory, oldest published first, until at
+// least bytes were freed. They stay in the netDb directory. LeaseSets are
+// short lived and not evicted.
+func (db *StdNetDB) Evict(bytes int64) (freed int64) {
+	type candidate struct {
+		hash      common.Hash
+		entry     Entry
+		published time.Time
+	}
+	defer db.writeLock()()
+	candidates := make([]candidate, 0, len(db.RouterInfos))
+	for hash, entry := range db.RouterInfos {
+		var published time.Time
+		if entry.RouterInfo != nil && entry.RouterInfo.Published() != nil {
+			published = entry.RouterInfo.Published().Time()
+		}
+		candidates = append(candidates, candidate{hash: hash, entry: entry, published: published})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].published.Before(candidates[j].published)
+	})
+	evicted := 0
+	for _, candidate := range candidates {
+		if freed >= bytes {
+			break
+		}
+		delete(db.RouterInfos, candidate.hash)
+		if candidate.entry.size == 0 {
+			candidate.entry.size = entrySize(candidate.entry)
+		}
+		freed += candidate.entry.size
+		evicted++
+	}
+	log.WithFields(logrus.Fields{
+		"evicted": evicted,
+		"freed":   freed,
+	}).Debug("Evicted RouterInfos from memory")
+	return
+}
+
+// entrySize returns the serialized size of the RouterInfo or LeaseSet in entry
+func entrySize(entry Entry) (size int64) {
+	if entry.RouterInfo != nil {
+		data, _ := entry.RouterInfo.Bytes()
+		size += int64(len(data))
+	}
+	if entry.LeaseSet != nil {
+		size += int64(len(*entry.LeaseSet))
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package netdb
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+func TestStdNetDBEvictsOldestRouterInfos(t *testing.T) {
+	now := time.Now()
+	db := NewStdNetDB(t.TempDir())
+	var infos []*router_info.RouterInfo
+	for i := 0; i < 3; i++ {
+		info := newSnapshotRouterInfo(t, now.Add(-time.Duration(i)*time.Hour), "fR")
+		db.RouterInfos[info.IdentHash()] = Entry{RouterInfo: info}
+		infos = append(infos, info)
+	}
+	entries, bytes := db.MemoryUsage()
+	assert.Equal(t, 3, entries)
+	size := entrySize(Entry{RouterInfo: infos[2]})
+	require.Positive(t, size)
+	assert.Equal(t, 3*size, bytes)
+
+	// one byte over frees the oldest RouterInfo only
+	assert.Equal(t, size, db.Evict(1))
+	assert.NotContains(t, db.RouterInfos, infos[2].IdentHash())
+	assert.Contains(t, db.RouterInfos, infos[0].IdentHash())
+	assert.Contains(t, db.RouterInfos, infos[1].IdentHash())
+
+	assert.Equal(t, 2*size, db.Evict(10*size))
+	assert.Empty(t, db.RouterInfos)
+}
+
+func TestStdNetDBCachesEntrySizes(t *testing.T) {
+	db := NewStdNetDB(t.TempDir())
+	info := newSnapshotRouterInfo(t, time.Now(), "R")
+	db.RouterInfos[info.IdentHash()] = Entry{RouterInfo: info}
+
+	_, bytes := db.MemoryUsage()
+	assert.Equal(t, entrySize(Entry{RouterInfo: info}), db.RouterInfos[info.IdentHash()].size)
+	_, again := db.MemoryUsage()
+	assert.Equal(t, bytes, again)
+
+	stored := newSnapshotRouterInfo(t, time.Now(), "R")
+	require.True(t, db.StoreRouterInfo(stored, true))
+	assert.Positive(t, db.RouterInfos[stored.IdentHash()].size)
+	assert.False(t, db.StoreRouterInfo(stored, true))
+}
+
+func TestStdNetDBConcurrentAccess(t *testing.T) {
+	db := NewStdNetDB(t.TempDir())
+	var infos []*router_info.RouterInfo
+	for i := 0; i < 4; i++ {
+		infos = append(infos, newSnapshotRouterInfo(t, time.Now().Add(-time.Duration(i)*time.Minute), "R"))
+	}
+	var wg sync.WaitGroup
+	for _, info := range infos {
+		info := info
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			db.StoreRouterInfo(info, false)
+			db.Evict(1)
+		}()
+		go func() {
+			defer wg.Done()
+			db.MemoryUsage()
+			db.Counts()
+			db.KnownRouterInfos()
+			db.LookupRouterInfo(info.IdentHash())
+		}()
+	}
+	wg.Wait()
+	routerInfos, _ := db.Counts()
+	assert.LessOrEqual(t, routerInfos, len(infos))
+}
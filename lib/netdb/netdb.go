@@ -8,6 +8,23 @@ import (
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
 )
 
+// SpawnFunc starts task concurrently, on a worker pool for instance, and
+// returns an error if it could not
+type SpawnFunc func(task func()) error
+
+// spawn starts task with spawnFunc, or on a new goroutine if spawnFunc is nil
+// or failed to start it
+func spawn(spawnFunc SpawnFunc, task func()) {
+	if spawnFunc != nil {
+		err := spawnFunc(task)
+		if err == nil {
+			return
+		}
+		log.WithError(err).Debug("Failed to spawn netdb task, running it on its own goroutine")
+	}
+	go task()
+}
+
 // resolves unknown RouterInfos given the hash of their RouterIdentity
 type Resolver interface {
 	// resolve a router info by hash
@@ -0,0 +1,244 @@
+package netdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/i2np"
+)
+
+// Defaults of LeaseSet publishing, after the Java router's
+// FloodfillStoreJob and FloodfillVerifyStoreJob.
+const (
+	// DEFAULT_PUBLISH_FLOODFILLS is how many of the floodfills closest to a
+	// LeaseSet it is stored to
+	DEFAULT_PUBLISH_FLOODFILLS = 3
+	// DEFAULT_PUBLISH_QUORUM is how many of them have to store it for the
+	// publication to succeed
+	DEFAULT_PUBLISH_QUORUM = 2
+	// DEFAULT_PUBLISH_TIMEOUT is how long a floodfill has to store and
+	// verify the LeaseSet before the next closest one is tried instead
+	DEFAULT_PUBLISH_TIMEOUT = 10 * time.Second
+)
+
+var (
+	ErrInvalidPublishQuorum = errors.New("publish quorum must be between 1 and the number of floodfills")
+	ErrPublishQuorum        = errors.New("LeaseSet stored to fewer floodfills than the quorum")
+)
+
+// StoreFunc sends the DatabaseStore of a LeaseSet to floodfill, of
+// storeType i2np.DATABASE_STORE_TYPE_LEASE_SET or LEASE_SET2, and returns
+// once the floodfill acknowledged it, or an error.
+type StoreFunc func(ctx context.Context, floodfill common.Hash, storeType byte) error
+
+// VerifyFunc looks up the LeaseSet stored to floodfill to verify the store
+// took effect, returning an error if it did not. The Java router asks a
+// different floodfill, which the store was flooded to.
+type VerifyFunc func(ctx context.Context, floodfill common.Hash) error
+
+// PublishOptions controls how a LeaseSetPublisher publishes. The zero value
+// uses the DEFAULT_PUBLISH_* settings.
+type PublishOptions struct {
+	// Floodfills is how many of the closest floodfills to store to
+	Floodfills int
+	// Quorum is how many stores have to succeed
+	Quorum int
+	// Timeout bounds the store and verification at each floodfill
+	Timeout time.Duration
+	// LeaseSet2 publishes LeaseSet2 instead of LeaseSet
+	LeaseSet2 bool
+}
+
+// StoreType returns the DatabaseStore type LeaseSets are published as.
+func (options PublishOptions) StoreType() byte {
+	if options.LeaseSet2 {
+		return i2np.DATABASE_STORE_TYPE_LEASE_SET2
+	}
+	return i2np.DATABASE_STORE_TYPE_LEASE_SET
+}
+
+func (options PublishOptions) withDefaults() PublishOptions {
+	if options.Floodfills <= 0 {
+		options.Floodfills = DEFAULT_PUBLISH_FLOODFILLS
+	}
+	if options.Quorum <= 0 {
+		options.Quorum = min(DEFAULT_PUBLISH_QUORUM, options.Floodfills)
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = DEFAULT_PUBLISH_TIMEOUT
+	}
+	return options
+}
+
+// PublishFailure is a floodfill a publication failed at.
+type PublishFailure struct {
+	Floodfill common.Hash
+	Error     string
+}
+
+// PublishEvent is the outcome of a publication.
+type PublishEvent struct {
+	Key     common.Hash
+	Started time.Time
+	// Duration is how long the publication took
+	Duration time.Duration
+	// Stored lists the floodfills which stored and verified the LeaseSet,
+	// in the order they did
+	Stored []common.Hash
+	Failed []PublishFailure
+	Quorum int
+	// Success reports whether at least Quorum floodfills stored it
+	Success bool
+}
+
+// LeaseSetPublisher stores LeaseSets to the floodfills closest to them. It
+// stores to several floodfills at once, replaces each floodfill failing
+// with the next closest, and requires a quorum of them to store and verify
+// the LeaseSet. The outcome of every publication is passed to the OnPublish
+// callbacks. It is safe for concurrent use.
+type LeaseSetPublisher struct {
+	store   StoreFunc
+	verify  VerifyFunc
+	options PublishOptions
+
+	mutex     sync.Mutex
+	callbacks []func(PublishEvent)
+	spawn     SpawnFunc
+}
+
+// NewLeaseSetPublisher returns a publisher sending stores with store and
+// verifying them with verify, which may be nil to trust acknowledgements.
+func NewLeaseSetPublisher(store StoreFunc, verify VerifyFunc, options PublishOptions) (*LeaseSetPublisher, error) {
+	options = options.withDefaults()
+	if options.Quorum > options.Floodfills {
+		return nil, fmt.Errorf("%w: %d of %d", ErrInvalidPublishQuorum, options.Quorum, options.Floodfills)
+	}
+	return &LeaseSetPublisher{store: store, verify: verify, options: options}, nil
+}
+
+// Options returns the options in use, with defaults filled in.
+func (publisher *LeaseSetPublisher) Options() PublishOptions {
+	return publisher.options
+}
+
+// OnPublish registers a callback run with the outcome of every publication,
+// on the goroutine which called Publish.
+func (publisher *LeaseSetPublisher) OnPublish(callback func(PublishEvent)) {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+	publisher.callbacks = append(publisher.callbacks, callback)
+}
+
+// SetSpawn makes the publisher start the store to each floodfill with spawn,
+// nil to start each on its own goroutine.
+func (publisher *LeaseSetPublisher) SetSpawn(spawnFunc SpawnFunc) {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+	publisher.spawn = spawnFunc
+}
+
+// publishResult is the outcome of the publication to one floodfill
+type publishResult struct {
+	floodfill common.Hash
+	err       error
+}
+
+// Publish stores the LeaseSet with key to the floodfills among candidates
+// closest to it on the day of now, and returns the outcome. The error wraps
+// ErrPublishQuorum if fewer than the quorum stored it, or is the error of
+// ctx if it ended first.
+func (publisher *LeaseSetPublisher) Publish(ctx context.Context, key common.Hash, candidates []*router_info.RouterInfo, now time.Time) (PublishEvent, error) {
+	floodfills := make([]*router_info.RouterInfo, 0, len(candidates))
+	for _, ri := range candidates {
+		if ri != nil && ri.Capabilities().Floodfill {
+			floodfills = append(floodfills, ri)
+		}
+	}
+	closest := ClosestPeers(key, floodfills, now, SearchReplyOptions{Max: MAX_SEARCH_REPLY_PEERS})
+
+	publisher.mutex.Lock()
+	spawnFunc := publisher.spawn
+	publisher.mutex.Unlock()
+
+	event := PublishEvent{Key: key, Started: time.Now(), Quorum: publisher.options.Quorum}
+	// buffered so a store finishing never waits for Publish, which may be
+	// waiting for room on the spawn queue
+	results := make(chan publishResult, len(closest))
+	inFlight, next := 0, 0
+	start := func() {
+		floodfill := closest[next]
+		next++
+		inFlight++
+		spawn(spawnFunc, func() {
+			results <- publishResult{floodfill: floodfill, err: publisher.publishTo(ctx, floodfill)}
+		})
+	}
+	for inFlight < publisher.options.Floodfills && next < len(closest) {
+		start()
+	}
+	for inFlight > 0 {
+		result := <-results
+		inFlight--
+		if result.err == nil {
+			event.Stored = append(event.Stored, result.floodfill)
+			continue
+		}
+		event.Failed = append(event.Failed, PublishFailure{Floodfill: result.floodfill, Error: result.err.Error()})
+		log.WithFields(logrus.Fields{
+			"key":       key,
+			"floodfill": result.floodfill,
+		}).WithError(result.err).Debug("LeaseSet store failed")
+		if ctx.Err() == nil && next < len(closest) {
+			start()
+		}
+	}
+	event.Duration = time.Since(event.Started)
+	event.Success = len(event.Stored) >= event.Quorum
+
+	var err error
+	switch {
+	case event.Success:
+	case ctx.Err() != nil:
+		err = ctx.Err()
+	default:
+		err = fmt.Errorf("%w: %d of %d", ErrPublishQuorum, len(event.Stored), event.Quorum)
+	}
+	log.WithFields(logrus.Fields{
+		"key":      key,
+		"stored":   len(event.Stored),
+		"failed":   len(event.Failed),
+		"quorum":   event.Quorum,
+		"duration": event.Duration,
+	}).Debug("Published LeaseSet")
+
+	publisher.mutex.Lock()
+	callbacks := publisher.callbacks
+	publisher.mutex.Unlock()
+	for _, callback := range callbacks {
+		callback(event)
+	}
+	return event, err
+}
+
+// publishTo stores to and verifies at one floodfill within the timeout
+func (publisher *LeaseSetPublisher) publishTo(ctx context.Context, floodfill common.Hash) error {
+	ctx, cancel := context.WithTimeout(ctx, publisher.options.Timeout)
+	defer cancel()
+	if err := publisher.store(ctx, floodfill, publisher.options.StoreType()); err != nil {
+		return err
+	}
+	if publisher.verify == nil {
+		return nil
+	}
+	if err := publisher.verify(ctx, floodfill); err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	return nil
+}
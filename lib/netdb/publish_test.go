@@ -0,0 +1,191 @@
+package netdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/i2np"
+)
+
+// publishFixture has floodfill candidates and records the stores sent
+type publishFixture struct {
+	now        time.Time
+	key        common.Hash
+	candidates []*router_info.RouterInfo
+	// closest lists the floodfill hashes, closest to key first
+	closest []common.Hash
+
+	mutex  sync.Mutex
+	stored []common.Hash
+}
+
+func newPublishFixture(t *testing.T, floodfills int) *publishFixture {
+	fixture := &publishFixture{now: time.Now().Truncate(time.Millisecond)}
+	fixture.key[0] = 7
+	for i := 0; i < floodfills; i++ {
+		fixture.candidates = append(fixture.candidates, newSnapshotRouterInfo(t, fixture.now, "fR"))
+	}
+	fixture.candidates = append(fixture.candidates, newSnapshotRouterInfo(t, fixture.now, "R"))
+	fixture.closest = ClosestPeers(fixture.key, fixture.candidates[:floodfills], fixture.now, SearchReplyOptions{Max: floodfills})
+	return fixture
+}
+
+// store fails for the floodfills in failing
+func (fixture *publishFixture) store(failing ...common.Hash) StoreFunc {
+	return func(ctx context.Context, floodfill common.Hash, storeType byte) error {
+		fixture.mutex.Lock()
+		fixture.stored = append(fixture.stored, floodfill)
+		fixture.mutex.Unlock()
+		for _, hash := range failing {
+			if hash == floodfill {
+				return errors.New("store timed out")
+			}
+		}
+		return nil
+	}
+}
+
+func TestPublishToClosestFloodfills(t *testing.T) {
+	fixture := newPublishFixture(t, 5)
+	publisher, err := NewLeaseSetPublisher(fixture.store(), nil, PublishOptions{})
+	require.NoError(t, err)
+	var events []PublishEvent
+	publisher.OnPublish(func(event PublishEvent) {
+		events = append(events, event)
+	})
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	require.NoError(t, err)
+	assert.True(t, event.Success)
+	assert.Equal(t, DEFAULT_PUBLISH_QUORUM, event.Quorum)
+	assert.ElementsMatch(t, fixture.closest[:DEFAULT_PUBLISH_FLOODFILLS], event.Stored)
+	assert.ElementsMatch(t, fixture.closest[:DEFAULT_PUBLISH_FLOODFILLS], fixture.stored, "the non-floodfill and farther floodfills are not stored to")
+	assert.Empty(t, event.Failed)
+	require.Len(t, events, 1)
+	assert.Equal(t, event.Stored, events[0].Stored)
+}
+
+func TestPublishStoreType(t *testing.T) {
+	fixture := newPublishFixture(t, 1)
+	for _, leaseSet2 := range []bool{false, true} {
+		var storeTypes []byte
+		store := func(ctx context.Context, floodfill common.Hash, storeType byte) error {
+			storeTypes = append(storeTypes, storeType)
+			return nil
+		}
+		options := PublishOptions{Floodfills: 1, Quorum: 1, LeaseSet2: leaseSet2}
+		publisher, err := NewLeaseSetPublisher(store, nil, options)
+		require.NoError(t, err)
+		_, err = publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{options.StoreType()}, storeTypes)
+	}
+	assert.Equal(t, byte(i2np.DATABASE_STORE_TYPE_LEASE_SET), PublishOptions{}.StoreType())
+	assert.Equal(t, byte(i2np.DATABASE_STORE_TYPE_LEASE_SET2), PublishOptions{LeaseSet2: true}.StoreType())
+}
+
+func TestPublishFallsBackToNextClosest(t *testing.T) {
+	fixture := newPublishFixture(t, 5)
+	publisher, err := NewLeaseSetPublisher(fixture.store(fixture.closest[0]), nil, PublishOptions{})
+	require.NoError(t, err)
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, fixture.closest[1:4], event.Stored)
+	require.Len(t, event.Failed, 1)
+	assert.Equal(t, fixture.closest[0], event.Failed[0].Floodfill)
+	assert.Equal(t, "store timed out", event.Failed[0].Error)
+}
+
+func TestPublishWithoutQuorum(t *testing.T) {
+	fixture := newPublishFixture(t, 3)
+	publisher, err := NewLeaseSetPublisher(fixture.store(fixture.closest[0], fixture.closest[2]), nil, PublishOptions{})
+	require.NoError(t, err)
+	var published *PublishEvent
+	publisher.OnPublish(func(event PublishEvent) {
+		published = &event
+	})
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	assert.ErrorIs(t, err, ErrPublishQuorum)
+	assert.False(t, event.Success)
+	assert.Equal(t, []common.Hash{fixture.closest[1]}, event.Stored)
+	assert.Len(t, event.Failed, 2)
+	require.NotNil(t, published, "failures are surfaced as events too")
+	assert.False(t, published.Success)
+}
+
+func TestPublishVerifies(t *testing.T) {
+	fixture := newPublishFixture(t, 4)
+	verify := func(ctx context.Context, floodfill common.Hash) error {
+		if floodfill == fixture.closest[1] {
+			return errors.New("not found")
+		}
+		return nil
+	}
+	publisher, err := NewLeaseSetPublisher(fixture.store(), verify, PublishOptions{Floodfills: 2, Quorum: 2})
+	require.NoError(t, err)
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []common.Hash{fixture.closest[0], fixture.closest[2]}, event.Stored)
+	require.Len(t, event.Failed, 1)
+	assert.Contains(t, event.Failed[0].Error, "verification failed")
+}
+
+func TestPublishTimesOutFloodfills(t *testing.T) {
+	fixture := newPublishFixture(t, 2)
+	store := func(ctx context.Context, floodfill common.Hash, storeType byte) error {
+		if floodfill == fixture.closest[0] {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+	publisher, err := NewLeaseSetPublisher(store, nil, PublishOptions{Floodfills: 1, Quorum: 1, Timeout: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	require.NoError(t, err)
+	assert.Equal(t, []common.Hash{fixture.closest[1]}, event.Stored)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = publisher.Publish(ctx, fixture.key, fixture.candidates, fixture.now)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewLeaseSetPublisherOptions(t *testing.T) {
+	_, err := NewLeaseSetPublisher(nil, nil, PublishOptions{Floodfills: 2, Quorum: 3})
+	assert.ErrorIs(t, err, ErrInvalidPublishQuorum)
+
+	publisher, err := NewLeaseSetPublisher(nil, nil, PublishOptions{Floodfills: 1})
+	require.NoError(t, err)
+	assert.Equal(t, PublishOptions{Floodfills: 1, Quorum: 1, Timeout: DEFAULT_PUBLISH_TIMEOUT}, publisher.Options())
+}
+
+func TestPublishSpawnsStores(t *testing.T) {
+	fixture := newPublishFixture(t, 5)
+	publisher, err := NewLeaseSetPublisher(fixture.store(), nil, PublishOptions{})
+	require.NoError(t, err)
+	var spawned atomic.Int32
+	publisher.SetSpawn(func(task func()) error {
+		spawned.Add(1)
+		go task()
+		return nil
+	})
+
+	event, err := publisher.Publish(context.Background(), fixture.key, fixture.candidates, fixture.now)
+	require.NoError(t, err)
+	assert.True(t, event.Success)
+	assert.Equal(t, int32(DEFAULT_PUBLISH_FLOODFILLS), spawned.Load())
+}
@@ -105,7 +105,7 @@ func (r Reseed) SingleReseed(uri string) ([]router_info.RouterInfo, error) {
 					log.WithError(err).WithField("file", f).Warn("Failed to read router info file")
 					continue
 				}
-				ri, _, err := router_info.ReadRouterInfo(riB)
+				ri, _, err := router_info.ParseRouterInfo(riB)
 				if err != nil {
 					log.WithError(err).WithField("file", f).Warn("Failed to parse router info")
 					continue
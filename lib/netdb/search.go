@@ -0,0 +1,155 @@
+package netdb
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/i2np"
+)
+
+const (
+	// DEFAULT_SEARCH_REPLY_PEERS is how many peers a DatabaseSearchReply
+	// suggests when SearchReplyOptions.Max is 0
+	DEFAULT_SEARCH_REPLY_PEERS = 3
+	// MAX_SEARCH_REPLY_PEERS is the most peer hashes the 1 byte num field of
+	// a DatabaseSearchReply can count
+	MAX_SEARCH_REPLY_PEERS = 255
+	// DEFAULT_SEARCH_REPLY_MAX_AGE skips RouterInfos published longer ago
+	// than this when SearchReplyOptions.MaxAge is 0
+	DEFAULT_SEARCH_REPLY_MAX_AGE = 24 * time.Hour
+	// MAX_PUBLISHED_SKEW is how far in the future a RouterInfo may claim to
+	// have been published before it is treated as bogus
+	MAX_PUBLISHED_SKEW = 10 * time.Minute
+)
+
+// RoutingKey returns the key hash is stored under in the keyspace on the day
+// of now, SHA256(hash || yyyyMMdd) with the date in UTC.
+func RoutingKey(hash common.Hash, now time.Time) common.Hash {
+	data := make([]byte, 0, len(hash)+8)
+	data = append(data, hash[:]...)
+	data = append(data, now.UTC().Format("20060102")...)
+	return common.HashData(data)
+}
+
+// Distance returns the XOR distance between a and b. Distances compare as
+// big-endian integers, with bytes.Compare.
+func Distance(a, b common.Hash) (distance common.Hash) {
+	for i := range distance {
+		distance[i] = a[i] ^ b[i]
+	}
+	return
+}
+
+// SearchReplyOptions controls which peers NewSearchReply suggests.
+// The zero value suggests DEFAULT_SEARCH_REPLY_PEERS peers published within
+// DEFAULT_SEARCH_REPLY_MAX_AGE.
+type SearchReplyOptions struct {
+	// Max is the number of peers to suggest, capped at MAX_SEARCH_REPLY_PEERS
+	Max int
+	// MaxAge skips RouterInfos published longer ago than this
+	MaxAge time.Duration
+	// Exclude lists peers never to suggest, such as the peer asking and the
+	// peers it asked us not to return
+	Exclude []common.Hash
+	// Banned reports peers never to suggest, nil for none
+	Banned func(hash common.Hash) bool
+}
+
+func (options SearchReplyOptions) max() int {
+	switch {
+	case options.Max <= 0:
+		return DEFAULT_SEARCH_REPLY_PEERS
+	case options.Max > MAX_SEARCH_REPLY_PEERS:
+		return MAX_SEARCH_REPLY_PEERS
+	}
+	return options.Max
+}
+
+func (options SearchReplyOptions) maxAge() time.Duration {
+	if options.MaxAge <= 0 {
+		return DEFAULT_SEARCH_REPLY_MAX_AGE
+	}
+	return options.MaxAge
+}
+
+// searchCandidate is a peer eligible for a search reply
+type searchCandidate struct {
+	hash      common.Hash
+	distance  common.Hash
+	published time.Time
+}
+
+// ClosestPeers returns the hashes of the candidates closest to the routing
+// key of key on the day of now, closest first. Banned and excluded peers,
+// RouterInfos without a published date, published longer ago than the
+// maximum age or more than MAX_PUBLISHED_SKEW in the future are skipped.
+// A peer listed more than once counts with its freshest RouterInfo.
+func ClosestPeers(key common.Hash, candidates []*router_info.RouterInfo, now time.Time, options SearchReplyOptions) []common.Hash {
+	target := RoutingKey(key, now)
+	excluded := make(map[common.Hash]bool, len(options.Exclude))
+	for _, hash := range options.Exclude {
+		excluded[hash] = true
+	}
+	maxAge := options.maxAge()
+	eligible := make(map[common.Hash]searchCandidate, len(candidates))
+	for _, ri := range candidates {
+		if ri == nil || ri.Published() == nil {
+			continue
+		}
+		hash := ri.IdentHash()
+		if excluded[hash] || (options.Banned != nil && options.Banned(hash)) {
+			continue
+		}
+		published := ri.Published().Time()
+		age := now.Sub(published)
+		if age > maxAge || age < -MAX_PUBLISHED_SKEW {
+			continue
+		}
+		if previous, ok := eligible[hash]; ok && !published.After(previous.published) {
+			continue
+		}
+		eligible[hash] = searchCandidate{
+			hash:      hash,
+			distance:  Distance(hash, target),
+			published: published,
+		}
+	}
+	sorted := make([]searchCandidate, 0, len(eligible))
+	for _, candidate := range eligible {
+		sorted = append(sorted, candidate)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].distance[:], sorted[j].distance[:]) < 0
+	})
+	if max := options.max(); len(sorted) > max {
+		sorted = sorted[:max]
+	}
+	peers := make([]common.Hash, len(sorted))
+	for i, candidate := range sorted {
+		peers[i] = candidate.hash
+	}
+	return peers
+}
+
+// NewSearchReply answers a DatabaseLookup for key we could not satisfy with
+// the candidates closest to it, as selected by ClosestPeers. from is the hash
+// of our own RouterIdentity and is never suggested.
+func NewSearchReply(key, from common.Hash, candidates []*router_info.RouterInfo, now time.Time, options SearchReplyOptions) *i2np.DatabaseSearchReply {
+	options.Exclude = append(append([]common.Hash(nil), options.Exclude...), from)
+	peers := ClosestPeers(key, candidates, now, options)
+	log.WithFields(logrus.Fields{
+		"candidates": len(candidates),
+		"peers":      len(peers),
+	}).Debug("Built DatabaseSearchReply")
+	return &i2np.DatabaseSearchReply{
+		Key:        key,
+		Count:      len(peers),
+		PeerHashes: peers,
+		From:       from,
+	}
+}
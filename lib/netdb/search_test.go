@@ -0,0 +1,106 @@
+package netdb
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+func TestRoutingKeyChangesAtMidnightUTC(t *testing.T) {
+	var key common.Hash
+	key[0] = 1
+	midnight := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	before := RoutingKey(key, midnight.Add(-time.Nanosecond))
+	assert.Equal(t, common.HashData(append(key[:], "20240229"...)), before)
+	assert.Equal(t, common.HashData(append(key[:], "20240301"...)), RoutingKey(key, midnight))
+	// the date is taken in UTC whatever the zone of now
+	assert.Equal(t, before, RoutingKey(key, midnight.Add(-time.Nanosecond).In(time.FixedZone("east", 5*3600))))
+}
+
+func TestDistance(t *testing.T) {
+	var a, b common.Hash
+	a[0], b[0] = 0x0f, 0xf0
+	a[31], b[31] = 0x01, 0x01
+	distance := Distance(a, b)
+	assert.Equal(t, byte(0xff), distance[0])
+	assert.Equal(t, byte(0), distance[31])
+	assert.Equal(t, common.Hash{}, Distance(a, a))
+}
+
+func TestClosestPeersOrderedByDistance(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	var infos []*router_info.RouterInfo
+	for i := 0; i < 5; i++ {
+		infos = append(infos, newSnapshotRouterInfo(t, now, "fR"))
+	}
+	var key common.Hash
+	peers := ClosestPeers(key, infos, now, SearchReplyOptions{Max: 4})
+	require.Len(t, peers, 4)
+	target := RoutingKey(key, now)
+	for i := 1; i < len(peers); i++ {
+		previous, current := Distance(peers[i-1], target), Distance(peers[i], target)
+		assert.Negative(t, bytes.Compare(previous[:], current[:]))
+	}
+	// the farthest peer is the one left out
+	included := make(map[common.Hash]bool)
+	for _, peer := range peers {
+		included[peer] = true
+	}
+	last := Distance(peers[3], target)
+	for _, info := range infos {
+		if !included[info.IdentHash()] {
+			distance := Distance(info.IdentHash(), target)
+			assert.Positive(t, bytes.Compare(distance[:], last[:]))
+		}
+	}
+}
+
+func TestClosestPeersPublishedBoundaries(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	oldest := newSnapshotRouterInfo(t, now.Add(-time.Hour), "fR")
+	expired := newSnapshotRouterInfo(t, now.Add(-time.Hour-time.Millisecond), "fR")
+	skewed := newSnapshotRouterInfo(t, now.Add(MAX_PUBLISHED_SKEW), "fR")
+	future := newSnapshotRouterInfo(t, now.Add(MAX_PUBLISHED_SKEW+time.Millisecond), "fR")
+
+	peers := ClosestPeers(common.Hash{}, []*router_info.RouterInfo{oldest, expired, skewed, future}, now, SearchReplyOptions{MaxAge: time.Hour})
+	assert.ElementsMatch(t, []common.Hash{oldest.IdentHash(), skewed.IdentHash()}, peers)
+
+	// the default maximum age applies when none is set
+	stale := newSnapshotRouterInfo(t, now.Add(-DEFAULT_SEARCH_REPLY_MAX_AGE-time.Second), "fR")
+	assert.Empty(t, ClosestPeers(common.Hash{}, []*router_info.RouterInfo{stale}, now, SearchReplyOptions{}))
+}
+
+func TestNewSearchReplySkipsBannedAndExcluded(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	self := newSnapshotRouterInfo(t, now, "fR")
+	banned := newSnapshotRouterInfo(t, now, "fR")
+	asking := newSnapshotRouterInfo(t, now, "fR")
+	good := newSnapshotRouterInfo(t, now, "fR")
+
+	var key common.Hash
+	key[0] = 0xaa
+	reply := NewSearchReply(key, self.IdentHash(), []*router_info.RouterInfo{self, banned, asking, good, nil}, now, SearchReplyOptions{
+		Max:     10,
+		Exclude: []common.Hash{asking.IdentHash()},
+		Banned: func(hash common.Hash) bool {
+			return hash == banned.IdentHash()
+		},
+	})
+	assert.Equal(t, key, reply.Key)
+	assert.Equal(t, self.IdentHash(), reply.From)
+	assert.Equal(t, []common.Hash{good.IdentHash()}, reply.PeerHashes)
+	assert.Equal(t, 1, reply.Count)
+}
+
+func TestClosestPeersListsEachPeerOnce(t *testing.T) {
+	now := time.Now().Truncate(time.Millisecond)
+	info := newSnapshotRouterInfo(t, now, "fR")
+	peers := ClosestPeers(common.Hash{}, []*router_info.RouterInfo{info, info}, now, SearchReplyOptions{})
+	assert.Equal(t, []common.Hash{info.IdentHash()}, peers)
+}
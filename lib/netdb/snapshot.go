@@ -0,0 +1,175 @@
+package netdb
+
+import (
+	"archive/tar"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+// SnapshotFilter selects which RouterInfos ExportSnapshot writes.
+// The zero value exports everything.
+type SnapshotFilter struct {
+	// MaxAge skips RouterInfos published longer ago than this, 0 for no limit
+	MaxAge time.Duration
+	// Caps skips RouterInfos which do not advertise every capability listed,
+	// for example "fR" for reachable floodfills
+	Caps string
+}
+
+// Match reports whether ri passes the filter at now.
+func (filter SnapshotFilter) Match(ri *router_info.RouterInfo, now time.Time) bool {
+	if filter.MaxAge > 0 {
+		published := ri.Published()
+		if published == nil || now.Sub(published.Time()) > filter.MaxAge {
+			return false
+		}
+	}
+	caps := ri.RouterCapabilities()
+	for _, c := range filter.Caps {
+		if !strings.ContainsRune(caps, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExportSnapshot writes the RouterInfos stored in the netDb directory to w as
+// a zstd compressed tar archive. Entries use the same r<c>/routerInfo-<hash>.dat
+// layout as the netDb directory, so the archive can also be unpacked by hand
+// into the netDb of another router. Files which fail to parse are skipped.
+// Returns the number of RouterInfos written.
+func (db *StdNetDB) ExportSnapshot(w io.Writer, filter SnapshotFilter) (exported int, err error) {
+	log.WithFields(logrus.Fields{
+		"path":    db.Path(),
+		"max_age": filter.MaxAge,
+		"caps":    filter.Caps,
+	}).Debug("Exporting netDb snapshot")
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return 0, err
+	}
+	tw := tar.NewWriter(zw)
+	now := time.Now()
+	err = filepath.Walk(db.Path(), func(fname string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !db.CheckFilePathValid(fname) {
+			return nil
+		}
+		ri, err := router_info.ReadRouterInfoFromFile(fname)
+		if err != nil {
+			log.WithError(err).WithField("file_name", fname).Warn("Skipping unparseable RouterInfo")
+			return nil
+		}
+		if !filter.Match(&ri, now) {
+			return nil
+		}
+		data, err := ri.Bytes()
+		if err != nil {
+			return err
+		}
+		// the entry name is derived from the hash, not the file name, so a
+		// misplaced file is exported where it belongs
+		header := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(router_info.RouterInfoFilePath("", ri.IdentHash())),
+			Mode:     0o600,
+			Size:     int64(len(data)),
+			ModTime:  ri.Published().Time(),
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		exported++
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to export netDb snapshot")
+		zw.Close()
+		return exported, err
+	}
+	if err = tw.Close(); err != nil {
+		zw.Close()
+		return exported, err
+	}
+	if err = zw.Close(); err != nil {
+		return exported, err
+	}
+	log.WithField("count", exported).Debug("Exported netDb snapshot")
+	return exported, nil
+}
+
+// ImportSnapshot reads an archive written by ExportSnapshot and stores every
+// RouterInfo in it whose signature verifies into the netDb directory, creating
+// the directory if needed. Entry names are ignored, each RouterInfo is written
+// to the path derived from its own hash. Returns the number of RouterInfos
+// imported.
+func (db *StdNetDB) ImportSnapshot(r io.Reader) (imported int, err error) {
+	log.WithField("path", db.Path()).Debug("Importing netDb snapshot")
+	if err = db.Ensure(); err != nil {
+		return 0, err
+	}
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			log.WithError(err).Error("Failed to read netDb snapshot")
+			return imported, err
+		}
+		if header.Typeflag != tar.TypeReg || !db.CheckFilePathValid(header.Name) {
+			continue
+		}
+		if header.Size > router_info.MAX_UNCOMPRESSED_SIZE {
+			log.WithFields(logrus.Fields{
+				"name": header.Name,
+				"size": header.Size,
+			}).Warn("Skipping oversized snapshot entry")
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return imported, err
+		}
+		ri, _, err := router_info.ParseRouterInfo(data)
+		if err != nil {
+			log.WithError(err).WithField("name", header.Name).Warn("Skipping unparseable snapshot entry")
+			continue
+		}
+		if err := ri.Verify(); err != nil {
+			log.WithError(err).WithField("name", header.Name).Warn("Skipping snapshot entry with bad signature")
+			continue
+		}
+		if db.VersionPolicy != nil && !db.VersionPolicy.Good(&ri) {
+			log.WithField("name", header.Name).Debug("Skipping snapshot entry of rejected router version")
+			continue
+		}
+		if _, err := ri.WriteToFile(db.Path()); err != nil {
+			return imported, err
+		}
+		db.StoreRouterInfo(&ri, false)
+		imported++
+	}
+	log.WithField("count", imported).Debug("Imported netDb snapshot")
+	return imported, nil
+}
@@ -0,0 +1,98 @@
+package netdb
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+func newSnapshotRouterInfo(t *testing.T, published time.Time, caps string) *router_info.RouterInfo {
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	info, err := router_info.NewRouterInfoBuilder(identity, keys.SigningPrivateKey).
+		Published(published).
+		SetOption("caps", caps).
+		Build()
+	require.NoError(t, err)
+	return info
+}
+
+func newSnapshotNetDB(t *testing.T, infos ...*router_info.RouterInfo) *StdNetDB {
+	db := NewStdNetDB(t.TempDir())
+	require.NoError(t, db.Create())
+	for _, info := range infos {
+		_, err := info.WriteToFile(db.Path())
+		require.NoError(t, err)
+	}
+	return &db
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	now := time.Now()
+	floodfill := newSnapshotRouterInfo(t, now, "fLR")
+	stale := newSnapshotRouterInfo(t, now.Add(-48*time.Hour), "fLR")
+	plain := newSnapshotRouterInfo(t, now, "LR")
+	source := newSnapshotNetDB(t, floodfill, stale, plain)
+
+	var archive bytes.Buffer
+	exported, err := source.ExportSnapshot(&archive, SnapshotFilter{MaxAge: 24 * time.Hour, Caps: "f"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, exported)
+
+	destination := NewStdNetDB(t.TempDir() + "/netDb")
+	imported, err := destination.ImportSnapshot(&archive)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	assert.Contains(t, destination.RouterInfos, floodfill.IdentHash())
+
+	_, err = os.Stat(router_info.RouterInfoFilePath(destination.Path(), floodfill.IdentHash()))
+	assert.NoError(t, err)
+	_, err = os.Stat(router_info.RouterInfoFilePath(destination.Path(), stale.IdentHash()))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestImportSnapshotSkipsBadEntries(t *testing.T) {
+	good := newSnapshotRouterInfo(t, time.Now(), "LR")
+	goodBytes, err := good.Bytes()
+	require.NoError(t, err)
+	tampered := newSnapshotRouterInfo(t, time.Now(), "LR")
+	tamperedBytes, err := tampered.Bytes()
+	require.NoError(t, err)
+	tamperedBytes[len(tamperedBytes)-1] ^= 0xff
+
+	var archive bytes.Buffer
+	zw, err := zstd.NewWriter(&archive)
+	require.NoError(t, err)
+	tw := tar.NewWriter(zw)
+	entries := map[string][]byte{
+		// the name is ignored, the file lands where its hash says
+		"../../escape/routerInfo-good.dat": goodBytes,
+		"rA/routerInfo-tampered.dat":       tamperedBytes,
+		"rA/routerInfo-garbage.dat":        []byte("not a router info"),
+	}
+	for name, data := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: name, Mode: 0o600, Size: int64(len(data))}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+
+	db := NewStdNetDB(t.TempDir())
+	imported, err := db.ImportSnapshot(&archive)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	_, err = os.Stat(router_info.RouterInfoFilePath(db.Path(), good.IdentHash()))
+	assert.NoError(t, err)
+	assert.NotContains(t, db.RouterInfos, tampered.IdentHash())
+}
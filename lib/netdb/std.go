@@ -1,19 +1,18 @@
 package netdb
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 	"github.com/sirupsen/logrus"
 
 	"github.com/go-i2p/go-i2p/lib/bootstrap"
-	"github.com/go-i2p/go-i2p/lib/common/base32"
 	"github.com/go-i2p/go-i2p/lib/common/base64"
 	common "github.com/go-i2p/go-i2p/lib/common/data"
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
@@ -27,6 +26,18 @@ type StdNetDB struct {
 	DB          string
 	RouterInfos map[common.Hash]Entry
 	LeaseSets   map[common.Hash]Entry
+	// path of a netDb shared with other routers, read for RouterInfos
+	// missing from DB and never written, empty for none
+	Shared string
+	// RouterInfos of router versions VersionPolicy does not accept are not
+	// stored, nil to store every version
+	VersionPolicy *router_info.VersionPolicy
+	// Spawn starts reading each RouterInfo file when the size is
+	// recalculated, nil to read each on its own goroutine
+	Spawn SpawnFunc
+	// guards RouterInfos and LeaseSets, shared by copies of the StdNetDB
+	// since they share the maps
+	mutex *sync.RWMutex
 }
 
 func NewStdNetDB(db string) StdNetDB {
@@ -35,36 +46,87 @@ func NewStdNetDB(db string) StdNetDB {
 		DB:          db,
 		RouterInfos: make(map[common.Hash]Entry),
 		LeaseSets:   make(map[common.Hash]Entry),
+		mutex:       new(sync.RWMutex),
 	}
 }
 
+// readLock and writeLock lock RouterInfos and LeaseSets and return the
+// matching unlock. A StdNetDB not made by NewStdNetDB has no lock.
+func (db *StdNetDB) readLock() (unlock func()) {
+	if db.mutex == nil {
+		return func() {}
+	}
+	db.mutex.RLock()
+	return db.mutex.RUnlock
+}
+
+func (db *StdNetDB) writeLock() (unlock func()) {
+	if db.mutex == nil {
+		return func() {}
+	}
+	db.mutex.Lock()
+	return db.mutex.Unlock
+}
+
+// LookupRouterInfo returns the RouterInfo with this hash held in memory, or
+// nil if there is none
+func (db *StdNetDB) LookupRouterInfo(hash common.Hash) *router_info.RouterInfo {
+	defer db.readLock()()
+	return db.RouterInfos[hash].RouterInfo
+}
+
+// KnownRouterInfos returns the RouterInfos held in memory
+func (db *StdNetDB) KnownRouterInfos() []*router_info.RouterInfo {
+	defer db.readLock()()
+	infos := make([]*router_info.RouterInfo, 0, len(db.RouterInfos))
+	for _, entry := range db.RouterInfos {
+		if entry.RouterInfo != nil {
+			infos = append(infos, entry.RouterInfo)
+		}
+	}
+	return infos
+}
+
+// Counts returns the number of RouterInfos and LeaseSets held in memory
+func (db *StdNetDB) Counts() (routerInfos, leaseSets int) {
+	defer db.readLock()()
+	return len(db.RouterInfos), len(db.LeaseSets)
+}
+
+// StoreRouterInfo holds ri in memory, replacing any RouterInfo with the same
+// hash, unless keep is set and there is one already or the VersionPolicy
+// rejects its version. Returns whether ri was stored.
+func (db *StdNetDB) StoreRouterInfo(ri *router_info.RouterInfo, keep bool) bool {
+	if db.VersionPolicy != nil && !db.VersionPolicy.Good(ri) {
+		log.WithField("version", ri.RouterVersion()).Debug("Not storing RouterInfo of rejected router version")
+		return false
+	}
+	hash := ri.IdentHash()
+	defer db.writeLock()()
+	if _, ok := db.RouterInfos[hash]; ok && keep {
+		return false
+	}
+	db.RouterInfos[hash] = newRouterInfoEntry(ri)
+	return true
+}
+
 func (db *StdNetDB) GetRouterInfo(hash common.Hash) (chnl chan router_info.RouterInfo) {
 	log.WithField("hash", hash).Debug("Getting RouterInfo")
-	if ri, ok := db.RouterInfos[hash]; ok {
+	if ri := db.LookupRouterInfo(hash); ri != nil {
 		log.Debug("RouterInfo found in memory cache")
-		chnl <- *ri.RouterInfo
+		chnl <- *ri
 		return
 	}
-	fname := db.SkiplistFile(hash)
-	buff := new(bytes.Buffer)
-	if f, err := os.Open(fname); err != nil {
-		log.WithError(err).Error("Failed to open RouterInfo file")
+	fname, ok := db.routerInfoFile(hash)
+	if !ok {
+		log.WithField("hash", hash).Error("Failed to open RouterInfo file")
 		return nil
-	} else {
-		if _, err := io.Copy(buff, f); err != nil {
-			log.WithError(err).Error("Failed to read RouterInfo file")
-			return nil
-		}
-		defer f.Close()
 	}
 	chnl = make(chan router_info.RouterInfo)
-	ri, _, err := router_info.ReadRouterInfo(buff.Bytes())
+	ri, err := router_info.ReadRouterInfoFromFile(fname)
 	if err == nil {
-		if _, ok := db.RouterInfos[hash]; !ok {
+		if db.StoreRouterInfo(&ri, true) {
 			log.Debug("Adding RouterInfo to memory cache")
-			db.RouterInfos[hash] = Entry{
-				RouterInfo: &ri,
-			}
 		}
 		chnl <- ri
 	} else {
@@ -75,12 +137,29 @@ func (db *StdNetDB) GetRouterInfo(hash common.Hash) (chnl chan router_info.Route
 
 // get the skiplist file that a RouterInfo with this hash would go in
 func (db *StdNetDB) SkiplistFile(hash common.Hash) (fpath string) {
-	fname := base64.EncodeToString(hash[:])
-	fpath = filepath.Join(db.Path(), fmt.Sprintf("r%c", fname[0]), fmt.Sprintf("routerInfo-%s.dat", fname))
+	fpath = router_info.RouterInfoFilePath(db.Path(), hash)
 	log.WithField("file_path", fpath).Debug("Generated skiplist file path")
 	return
 }
 
+// routerInfoFile returns the file holding the RouterInfo with this hash,
+// looking in the shared netDb if it is not in ours
+func (db *StdNetDB) routerInfoFile(hash common.Hash) (string, bool) {
+	fname := db.SkiplistFile(hash)
+	if _, err := os.Stat(fname); err == nil {
+		return fname, true
+	}
+	if db.Shared == "" {
+		return "", false
+	}
+	fname = router_info.RouterInfoFilePath(db.Shared, hash)
+	if _, err := os.Stat(fname); err != nil {
+		return "", false
+	}
+	log.WithField("file_path", fname).Debug("Found RouterInfo in shared netDb")
+	return fname, true
+}
+
 // get netdb path
 func (db *StdNetDB) Path() string {
 	return string(db.DB)
@@ -92,7 +171,8 @@ func (db *StdNetDB) Size() (routers int) {
 	log.Debug("Calculating NetDB size")
 	var err error
 	var data []byte
-	if !util.CheckFileExists(db.cacheFilePath()) || util.CheckFileAge(db.cacheFilePath(), 2) || len(db.RouterInfos) == 0 {
+	cached, _ := db.Counts()
+	if !util.CheckFileExists(db.cacheFilePath()) || util.CheckFileAge(db.cacheFilePath(), 2) || cached == 0 {
 		// regenerate
 		log.Debug("Recalculating NetDB size")
 		err = db.RecalculateSize()
@@ -133,10 +213,16 @@ func (db *StdNetDB) CheckFilePathValid(fpath string) bool {
 	return isValid
 }
 
-// recalculateSize recalculates cached size of netdb
+// recalculateSize recalculates cached size of netdb, reading the RouterInfo
+// files concurrently with Spawn
 func (db *StdNetDB) RecalculateSize() (err error) {
 	log.Debug("Recalculating NetDB size")
-	count := 0
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		count   int
+		readErr error
+	)
 	err = filepath.Walk(db.Path(), func(fname string, info os.FileInfo, err error) error {
 		if info.IsDir() {
 			if !strings.HasPrefix(fname, db.Path()) {
@@ -151,42 +237,30 @@ func (db *StdNetDB) RecalculateSize() (err error) {
 			return err
 		}
 		if db.CheckFilePathValid(fname) {
-			log.WithField("file_name", fname).Debug("Reading RouterInfo file")
-			log.Println("Reading in file:", fname)
-			b, err := os.ReadFile(fname)
-			if err != nil {
-				log.WithError(err).Error("Failed to read RouterInfo file")
-				return err
-			}
-			ri, _, err := router_info.ReadRouterInfo(b)
-			if err != nil {
-				log.WithError(err).Error("Failed to parse RouterInfo")
-				return err
-			}
-			ih := ri.IdentHash().Bytes()
-			log.WithError(err).Error("Failed to parse RouterInfo")
-			log.Printf("Read in IdentHash: %s", base32.EncodeToString(ih[:]))
-			for _, addr := range ri.RouterAddresses() {
-				log.Println(string(addr.Bytes()))
-				log.WithField("address", string(addr.Bytes())).Debug("RouterInfo address")
-			}
-			if ent, ok := db.RouterInfos[ih]; !ok {
-				log.Debug("Adding new RouterInfo to memory cache")
-				db.RouterInfos[ri.IdentHash()] = Entry{
-					RouterInfo: &ri,
+			wg.Add(1)
+			spawn(db.Spawn, func() {
+				defer wg.Done()
+				err := db.loadRouterInfoFile(fname)
+				mutex.Lock()
+				defer mutex.Unlock()
+				if err != nil {
+					if readErr == nil {
+						readErr = err
+					}
+					return
 				}
-			} else {
-				log.Debug("RouterInfo already in memory cache")
-				log.Println("entry previously found in table", ent, fname)
-			}
-			ri = router_info.RouterInfo{}
-			count++
+				count++
+			})
 		} else {
 			log.WithField("file_path", fname).Warn("Invalid file path")
 			log.Println("Invalid path error")
 		}
 		return err
 	})
+	wg.Wait()
+	if err == nil {
+		err = readErr
+	}
 	if err == nil {
 		log.WithField("count", count).Debug("Finished recalculating NetDB size")
 		str := fmt.Sprintf("%d", count)
@@ -205,6 +279,27 @@ func (db *StdNetDB) RecalculateSize() (err error) {
 	return
 }
 
+// loadRouterInfoFile reads the RouterInfo in fname and holds it in memory
+func (db *StdNetDB) loadRouterInfoFile(fname string) error {
+	log.WithField("file_name", fname).Debug("Reading RouterInfo file")
+	ri, err := router_info.ReadRouterInfoFromFile(fname)
+	if err != nil {
+		log.WithError(err).Error("Failed to parse RouterInfo")
+		return err
+	}
+	ih := ri.IdentHash()
+	log.WithField("ident_hash", ih.Base32()).Debug("Read RouterInfo")
+	for _, addr := range ri.RouterAddresses() {
+		log.WithField("address", string(addr.Bytes())).Debug("RouterInfo address")
+	}
+	if db.StoreRouterInfo(&ri, true) {
+		log.Debug("Adding new RouterInfo to memory cache")
+	} else {
+		log.WithField("file_name", fname).Debug("RouterInfo already in memory cache")
+	}
+	return nil
+}
+
 // return true if the network db directory exists and is writable
 func (db *StdNetDB) Exists() bool {
 	p := db.Path()
@@ -249,7 +344,13 @@ func (db *StdNetDB) SaveEntry(e *Entry) (err error) {
 
 func (db *StdNetDB) Save() (err error) {
 	log.Debug("Saving all NetDB entries")
+	unlock := db.readLock()
+	entries := make([]Entry, 0, len(db.RouterInfos))
 	for _, dbe := range db.RouterInfos {
+		entries = append(entries, dbe)
+	}
+	unlock()
+	for _, dbe := range entries {
 		if e := db.SaveEntry(&dbe); e != nil {
 			err = e
 			log.WithError(e).Error("Failed to save NetDB entry")
@@ -0,0 +1,80 @@
+package netdb
+
+import (
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+)
+
+func TestRouterInfoFileFallsBackToSharedNetDb(t *testing.T) {
+	dir := t.TempDir()
+	db := NewStdNetDB(filepath.Join(dir, "own"))
+	require.NoError(t, db.Ensure())
+
+	info := newSnapshotRouterInfo(t, time.Now(), "XR")
+	hash := info.IdentHash()
+	_, ok := db.routerInfoFile(hash)
+	assert.False(t, ok, "no shared netDb configured")
+
+	shared := filepath.Join(dir, "shared")
+	sharedFile, err := info.WriteToFile(shared)
+	require.NoError(t, err)
+	db.Shared = shared
+	fname, ok := db.routerInfoFile(hash)
+	require.True(t, ok)
+	assert.Equal(t, sharedFile, fname)
+
+	// our own copy is preferred
+	ownFile, err := info.WriteToFile(db.Path())
+	require.NoError(t, err)
+	fname, ok = db.routerInfoFile(hash)
+	require.True(t, ok)
+	assert.Equal(t, ownFile, fname)
+}
+
+func TestStdNetDBVersionPolicy(t *testing.T) {
+	db := NewStdNetDB(t.TempDir())
+	info := newSnapshotRouterInfo(t, time.Now(), "XR")
+	assert.True(t, db.StoreRouterInfo(info, false), "every version is stored without a policy")
+
+	db = NewStdNetDB(t.TempDir())
+	db.VersionPolicy = &router_info.VersionPolicy{Min: "0.9.58"}
+	assert.False(t, db.StoreRouterInfo(info, false), "a RouterInfo without a version is rejected")
+	routerInfos, _ := db.Counts()
+	assert.Zero(t, routerInfos)
+}
+
+func TestRecalculateSizeSpawnsReads(t *testing.T) {
+	db := NewStdNetDB(t.TempDir())
+	require.NoError(t, db.Ensure())
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := newSnapshotRouterInfo(t, now, "XR").WriteToFile(db.Path())
+		require.NoError(t, err)
+	}
+	var spawned atomic.Int32
+	db.Spawn = func(task func()) error {
+		spawned.Add(1)
+		go task()
+		return nil
+	}
+
+	require.NoError(t, db.RecalculateSize())
+	assert.Equal(t, int32(3), spawned.Load())
+	routerInfos, _ := db.Counts()
+	assert.Equal(t, 3, routerInfos)
+
+	// reads which cannot be spawned run on their own goroutine
+	db = NewStdNetDB(db.Path())
+	db.Spawn = func(task func()) error { return errors.New("pool closed") }
+	require.NoError(t, db.RecalculateSize())
+	routerInfos, _ = db.Counts()
+	assert.Equal(t, 3, routerInfos)
+}
@@ -0,0 +1,49 @@
+package peer
+
+import (
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
+)
+
+// ScoreSource supplies peer scores from outside the router, for example an
+// abuse feed or the profiles of an earlier deployment. Scores range from 0
+// (never use the peer) to 1 (always prefer it), values outside are clamped.
+// PeerScore is called during peer selection and must not block.
+type ScoreSource interface {
+	// PeerScore returns the score of the peer, and false if the source has
+	// no opinion on it.
+	PeerScore(hash common.Hash) (float64, bool)
+}
+
+// ScoreSourceFunc adapts a function to a ScoreSource.
+type ScoreSourceFunc func(hash common.Hash) (float64, bool)
+
+// PeerScore calls f.
+func (f ScoreSourceFunc) PeerScore(hash common.Hash) (float64, bool) {
+	return f(hash)
+}
+
+// StaticScores is a fixed table of peer scores.
+type StaticScores map[common.Hash]float64
+
+// PeerScore looks the peer up in the table.
+func (scores StaticScores) PeerScore(hash common.Hash) (float64, bool) {
+	score, ok := scores[hash]
+	return score, ok
+}
+
+// SetScoreSource blends scores from source into peer selection. weight is
+// the share of the external score in the result, from 0 to 1, so a weight of
+// 0.25 mixes one part external score with three parts local profile. A nil
+// source or a weight of 0 turns blending off.
+func (profiles *Profiles) SetScoreSource(source ScoreSource, weight float64) {
+	weight = clamp(weight)
+	log.WithFields(logrus.Fields{
+		"enabled": source != nil,
+		"weight":  weight,
+	}).Debug("Setting external peer score source")
+	profiles.mutex.Lock()
+	defer profiles.mutex.Unlock()
+	profiles.source = source
+	profiles.weight = weight
+}
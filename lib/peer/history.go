@@ -0,0 +1,202 @@
+package peer
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
+)
+
+// Bounds of the connection history
+const (
+	// HISTORY_EVENTS_PER_PEER is how many events are kept for each peer
+	HISTORY_EVENTS_PER_PEER = 32
+	// HISTORY_MAX_PEERS is how many peers a history is kept for. When a new
+	// peer would exceed it, the peer with the oldest last event is dropped.
+	HISTORY_MAX_PEERS = 2048
+)
+
+// Connection history event types
+const (
+	EVENT_CONNECT    = "connect"
+	EVENT_DISCONNECT = "disconnect"
+	EVENT_BAN        = "ban"
+)
+
+// PeerEvent is one entry of the connection history of a peer.
+type PeerEvent struct {
+	Time time.Time
+	// Type is one of the EVENT_* types
+	Type string
+	// Transport is the transport style of the session, empty for bans
+	Transport string
+	// Reason tells why the session was closed or the peer banned
+	Reason string
+	// Duration is how long the session lasted for disconnects, and how long
+	// the ban lasts for bans
+	Duration time.Duration
+}
+
+// MarshalJSON writes the event with its duration in Go notation, e.g. 1m30s.
+func (event PeerEvent) MarshalJSON() ([]byte, error) {
+	encoded := struct {
+		Time      time.Time `json:"time"`
+		Type      string    `json:"type"`
+		Transport string    `json:"transport,omitempty"`
+		Reason    string    `json:"reason,omitempty"`
+		Duration  string    `json:"duration,omitempty"`
+	}{
+		Time:      event.Time,
+		Type:      event.Type,
+		Transport: event.Transport,
+		Reason:    event.Reason,
+	}
+	if event.Duration > 0 {
+		encoded.Duration = event.Duration.String()
+	}
+	return json.Marshal(encoded)
+}
+
+// peerHistory is what History keeps of one peer
+type peerHistory struct {
+	events []PeerEvent
+	next   int
+	// open holds the start of the sessions open by transport
+	open map[string]time.Time
+	last time.Time
+}
+
+// add appends event, overwriting the oldest once the ring is full
+func (history *peerHistory) add(event PeerEvent) {
+	history.last = event.Time
+	if len(history.events) < HISTORY_EVENTS_PER_PEER {
+		history.events = append(history.events, event)
+		return
+	}
+	history.events[history.next] = event
+	history.next = (history.next + 1) % len(history.events)
+}
+
+// History records when sessions with peers were opened and closed and when
+// peers were banned, to debug flapping transport sessions. It is bounded:
+// only the last events of each peer, and the peers seen last, are kept. It
+// is safe for concurrent use.
+type History struct {
+	mutex sync.Mutex
+	peers map[common.Hash]*peerHistory
+}
+
+// NewHistory returns an empty history.
+func NewHistory() *History {
+	return &History{peers: make(map[common.Hash]*peerHistory)}
+}
+
+// peer returns the history of hash, creating it and making room for it.
+// Callers must hold the lock.
+func (history *History) peer(hash common.Hash) *peerHistory {
+	if peer, ok := history.peers[hash]; ok {
+		return peer
+	}
+	if len(history.peers) >= HISTORY_MAX_PEERS {
+		var oldest common.Hash
+		var oldestTime time.Time
+		first := true
+		for h, peer := range history.peers {
+			if first || peer.last.Before(oldestTime) {
+				oldest, oldestTime, first = h, peer.last, false
+			}
+		}
+		delete(history.peers, oldest)
+	}
+	peer := &peerHistory{open: make(map[string]time.Time)}
+	history.peers[hash] = peer
+	return peer
+}
+
+// Connected records a session with the peer opened over transport.
+func (history *History) Connected(hash common.Hash, transport string, now time.Time) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	peer := history.peer(hash)
+	peer.open[transport] = now
+	peer.add(PeerEvent{Time: now, Type: EVENT_CONNECT, Transport: transport})
+}
+
+// Disconnected records the session with the peer over transport closed for
+// reason. Its duration is known if it was recorded with Connected.
+func (history *History) Disconnected(hash common.Hash, transport, reason string, now time.Time) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	peer := history.peer(hash)
+	event := PeerEvent{Time: now, Type: EVENT_DISCONNECT, Transport: transport, Reason: reason}
+	if started, ok := peer.open[transport]; ok {
+		event.Duration = now.Sub(started)
+		delete(peer.open, transport)
+	}
+	peer.add(event)
+	log.WithFields(logrus.Fields{
+		"hash":      hash,
+		"transport": transport,
+		"reason":    reason,
+		"duration":  event.Duration,
+	}).Debug("Recorded peer disconnect")
+}
+
+// Banned records the peer banned for duration because of reason.
+func (history *History) Banned(hash common.Hash, reason string, duration time.Duration, now time.Time) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	history.peer(hash).add(PeerEvent{Time: now, Type: EVENT_BAN, Reason: reason, Duration: duration})
+}
+
+// Events returns the events kept for the peer, oldest first, and false if
+// there are none.
+func (history *History) Events(hash common.Hash) ([]PeerEvent, bool) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	peer, ok := history.peers[hash]
+	if !ok {
+		return nil, false
+	}
+	events := make([]PeerEvent, 0, len(peer.events))
+	events = append(events, peer.events[peer.next:]...)
+	return append(events, peer.events[:peer.next]...), true
+}
+
+// Open returns the transport styles of the sessions with the peer which were
+// recorded opened and not yet closed, sorted.
+func (history *History) Open(hash common.Hash) []string {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	transports := []string{}
+	if peer, ok := history.peers[hash]; ok {
+		for transport := range peer.open {
+			transports = append(transports, transport)
+		}
+	}
+	sort.Strings(transports)
+	return transports
+}
+
+// ConnectedPeers returns how many peers have a session recorded opened and
+// not yet closed.
+func (history *History) ConnectedPeers() (count int) {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	for _, peer := range history.peers {
+		if len(peer.open) > 0 {
+			count++
+		}
+	}
+	return
+}
+
+// Peers returns how many peers a history is kept for.
+func (history *History) Peers() int {
+	history.mutex.Lock()
+	defer history.mutex.Unlock()
+	return len(history.peers)
+}
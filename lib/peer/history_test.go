@@ -0,0 +1,68 @@
+package peer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryRecordsSessions(t *testing.T) {
+	history := NewHistory()
+	hash := testHash(1)
+	start := time.Unix(1700000000, 0)
+
+	_, ok := history.Events(hash)
+	assert.False(t, ok)
+
+	history.Connected(hash, "NTCP2", start)
+	assert.Equal(t, []string{"NTCP2"}, history.Open(hash))
+	assert.Equal(t, 1, history.ConnectedPeers())
+	history.Disconnected(hash, "NTCP2", "idle timeout", start.Add(90*time.Second))
+	assert.Empty(t, history.Open(hash))
+	assert.Zero(t, history.ConnectedPeers())
+	history.Disconnected(hash, "SSU2", "reset", start.Add(2*time.Minute))
+	history.Banned(hash, "clock skew", time.Hour, start.Add(3*time.Minute))
+
+	events, ok := history.Events(hash)
+	require.True(t, ok)
+	require.Len(t, events, 4)
+	assert.Equal(t, EVENT_CONNECT, events[0].Type)
+	assert.Equal(t, EVENT_DISCONNECT, events[1].Type)
+	assert.Equal(t, 90*time.Second, events[1].Duration)
+	assert.Equal(t, "idle timeout", events[1].Reason)
+	assert.Zero(t, events[2].Duration, "a session never recorded as opened has no duration")
+	assert.Equal(t, EVENT_BAN, events[3].Type)
+	assert.Equal(t, time.Hour, events[3].Duration)
+
+	data, err := json.Marshal(events[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"time":"`+events[1].Time.Format(time.RFC3339Nano)+`","type":"disconnect","transport":"NTCP2","reason":"idle timeout","duration":"1m30s"}`, string(data))
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	history := NewHistory()
+	hash := testHash(1)
+	start := time.Unix(1700000000, 0)
+	for i := 0; i < HISTORY_EVENTS_PER_PEER+5; i++ {
+		history.Connected(hash, "NTCP2", start.Add(time.Duration(i)*time.Second))
+	}
+	events, _ := history.Events(hash)
+	require.Len(t, events, HISTORY_EVENTS_PER_PEER)
+	assert.Equal(t, start.Add(5*time.Second), events[0].Time)
+	assert.Equal(t, start.Add(time.Duration(HISTORY_EVENTS_PER_PEER+4)*time.Second), events[len(events)-1].Time)
+
+	for i := 1; i < HISTORY_MAX_PEERS; i++ {
+		var other [32]byte
+		other[0], other[1] = byte(i), byte(i>>8)
+		other[2] = 1
+		history.Connected(other, "SSU2", start.Add(time.Hour))
+	}
+	assert.Equal(t, HISTORY_MAX_PEERS, history.Peers())
+	history.Connected(testHash(2), "SSU2", start.Add(2*time.Hour))
+	assert.Equal(t, HISTORY_MAX_PEERS, history.Peers())
+	_, ok := history.Events(hash)
+	assert.False(t, ok, "the peer seen longest ago is dropped")
+}
@@ -0,0 +1,185 @@
+// Package peer keeps profiles of the routers we have dealt with and ranks
+// them for tunnel building and netdb queries.
+package peer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Profile is what we have observed of a single peer.
+type Profile struct {
+	Hash common.Hash
+	// Successes counts accepted tunnel builds and answered lookups
+	Successes uint64
+	// Failures counts rejected or timed out requests
+	Failures uint64
+	// LastSeen is the time of the last recorded result
+	LastSeen time.Time
+}
+
+// Score rates the profile from 0 to 1. A peer without history scores 0.5,
+// and every result moves the score towards the observed success ratio.
+func (profile Profile) Score() float64 {
+	return float64(profile.Successes+1) / float64(profile.Successes+profile.Failures+2)
+}
+
+// Profiles tracks the profiles of all peers and ranks them by score,
+// optionally blending in scores from an external ScoreSource.
+type Profiles struct {
+	mutex    sync.RWMutex
+	profiles map[common.Hash]*Profile
+	source   ScoreSource
+	weight   float64
+	// routers of versions it rejects are never selected, nil for any
+	versions *router_info.VersionPolicy
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// NewProfiles returns an empty profile store.
+func NewProfiles() *Profiles {
+	return &Profiles{
+		profiles: make(map[common.Hash]*Profile),
+		now:      time.Now,
+	}
+}
+
+// RecordSuccess notes a successful interaction with the peer.
+func (profiles *Profiles) RecordSuccess(hash common.Hash) {
+	profiles.mutex.Lock()
+	defer profiles.mutex.Unlock()
+	profile := profiles.profile(hash)
+	profile.Successes++
+	profile.LastSeen = profiles.now()
+}
+
+// RecordFailure notes a failed interaction with the peer.
+func (profiles *Profiles) RecordFailure(hash common.Hash) {
+	profiles.mutex.Lock()
+	defer profiles.mutex.Unlock()
+	profile := profiles.profile(hash)
+	profile.Failures++
+	profile.LastSeen = profiles.now()
+}
+
+// Profile returns a copy of the profile of the peer, and false if we have
+// none.
+func (profiles *Profiles) Profile(hash common.Hash) (Profile, bool) {
+	profiles.mutex.RLock()
+	defer profiles.mutex.RUnlock()
+	profile, ok := profiles.profiles[hash]
+	if !ok {
+		return Profile{Hash: hash}, false
+	}
+	return *profile, true
+}
+
+// Score returns the score used to rank the peer, its local profile score
+// blended with the external score if there is one.
+func (profiles *Profiles) Score(hash common.Hash) float64 {
+	profiles.mutex.RLock()
+	defer profiles.mutex.RUnlock()
+	return profiles.score(hash)
+}
+
+// Select returns up to n of candidates, best scoring first. Peers with
+// equal scores keep their order in candidates.
+func (profiles *Profiles) Select(candidates []common.Hash, n int) []common.Hash {
+	profiles.mutex.RLock()
+	scores := make(map[common.Hash]float64, len(candidates))
+	for _, hash := range candidates {
+		scores[hash] = profiles.score(hash)
+	}
+	profiles.mutex.RUnlock()
+
+	selected := make([]common.Hash, len(candidates))
+	copy(selected, candidates)
+	sort.SliceStable(selected, func(i, j int) bool {
+		return scores[selected[i]] > scores[selected[j]]
+	})
+	if n >= 0 && n < len(selected) {
+		selected = selected[:n]
+	}
+	return selected
+}
+
+// SetVersionPolicy sets the router versions SelectRouters selects from. Pass
+// nil to accept any version.
+func (profiles *Profiles) SetVersionPolicy(policy *router_info.VersionPolicy) {
+	profiles.mutex.Lock()
+	defer profiles.mutex.Unlock()
+	profiles.versions = policy
+}
+
+// SelectRouters returns up to n of the candidates whose router version the
+// version policy accepts, best scoring first.
+func (profiles *Profiles) SelectRouters(candidates []*router_info.RouterInfo, n int) []common.Hash {
+	profiles.mutex.RLock()
+	policy := profiles.versions
+	profiles.mutex.RUnlock()
+	hashes := make([]common.Hash, 0, len(candidates))
+	for _, ri := range candidates {
+		if ri == nil || (policy != nil && !policy.Good(ri)) {
+			continue
+		}
+		hashes = append(hashes, ri.IdentHash())
+	}
+	return profiles.Select(hashes, n)
+}
+
+// profile returns the profile of hash, creating it. Callers must hold the
+// write lock.
+func (profiles *Profiles) profile(hash common.Hash) *Profile {
+	profile, ok := profiles.profiles[hash]
+	if !ok {
+		log.WithField("hash", hash).Debug("Creating peer profile")
+		profile = &Profile{Hash: hash}
+		profiles.profiles[hash] = profile
+	}
+	return profile
+}
+
+// score blends the local and external scores. Callers must hold the lock.
+func (profiles *Profiles) score(hash common.Hash) float64 {
+	local := Profile{Hash: hash}
+	if profile, ok := profiles.profiles[hash]; ok {
+		local = *profile
+	}
+	score := local.Score()
+	if profiles.source == nil || profiles.weight == 0 {
+		return score
+	}
+	external, ok := profiles.source.PeerScore(hash)
+	if !ok {
+		return score
+	}
+	external = clamp(external)
+	blended := (1-profiles.weight)*score + profiles.weight*external
+	log.WithFields(logrus.Fields{
+		"hash":     hash,
+		"local":    score,
+		"external": external,
+		"blended":  blended,
+	}).Debug("Blended external peer score")
+	return blended
+}
+
+func clamp(score float64) float64 {
+	switch {
+	case score < 0:
+		return 0
+	case score > 1:
+		return 1
+	default:
+		return score
+	}
+}
@@ -0,0 +1,89 @@
+package peer
+
+import (
+	"testing"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHash(b byte) common.Hash {
+	var hash common.Hash
+	hash[0] = b
+	return hash
+}
+
+func TestProfileScore(t *testing.T) {
+	assert.Equal(t, 0.5, Profile{}.Score())
+	assert.Greater(t, Profile{Successes: 10}.Score(), 0.9)
+	assert.Less(t, Profile{Failures: 10}.Score(), 0.1)
+}
+
+func TestProfilesSelect(t *testing.T) {
+	profiles := NewProfiles()
+	good, bad, unknown := testHash(1), testHash(2), testHash(3)
+	for i := 0; i < 5; i++ {
+		profiles.RecordSuccess(good)
+		profiles.RecordFailure(bad)
+	}
+
+	profile, ok := profiles.Profile(good)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(5), profile.Successes)
+	_, ok = profiles.Profile(unknown)
+	assert.False(t, ok)
+
+	assert.Equal(t, []common.Hash{good, unknown, bad}, profiles.Select([]common.Hash{bad, unknown, good}, -1))
+	assert.Equal(t, []common.Hash{good, unknown}, profiles.Select([]common.Hash{bad, unknown, good}, 2))
+}
+
+func TestProfilesExternalScores(t *testing.T) {
+	profiles := NewProfiles()
+	good, flagged := testHash(1), testHash(2)
+	for i := 0; i < 5; i++ {
+		profiles.RecordSuccess(good)
+		profiles.RecordSuccess(flagged)
+	}
+	local := profiles.Score(flagged)
+
+	profiles.SetScoreSource(StaticScores{flagged: 0}, 0.5)
+	assert.InDelta(t, local/2, profiles.Score(flagged), 1e-9)
+	// peers the source has no opinion on keep their local score
+	assert.Equal(t, local, profiles.Score(good))
+	assert.Equal(t, []common.Hash{good, flagged}, profiles.Select([]common.Hash{flagged, good}, -1))
+
+	// out of range scores and weights are clamped
+	profiles.SetScoreSource(ScoreSourceFunc(func(common.Hash) (float64, bool) { return 7, true }), 3)
+	assert.Equal(t, 1.0, profiles.Score(flagged))
+
+	profiles.SetScoreSource(nil, 1)
+	assert.Equal(t, local, profiles.Score(flagged))
+}
+
+func testRouterInfo(t *testing.T, version string) *router_info.RouterInfo {
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	info, err := router_info.NewRouterInfoBuilder(identity, keys.SigningPrivateKey).
+		SetOption("router.version", version).
+		Build()
+	require.NoError(t, err)
+	return info
+}
+
+func TestProfilesSelectRouters(t *testing.T) {
+	current := testRouterInfo(t, "0.9.64")
+	old := testRouterInfo(t, "0.9.50")
+	future := testRouterInfo(t, "2.8.0")
+	candidates := []*router_info.RouterInfo{old, current, nil, future}
+
+	profiles := NewProfiles()
+	profiles.RecordSuccess(future.IdentHash())
+	assert.Equal(t, []common.Hash{future.IdentHash(), old.IdentHash(), current.IdentHash()}, profiles.SelectRouters(candidates, -1))
+
+	profiles.SetVersionPolicy(&router_info.VersionPolicy{Min: "0.9.58", ZeroMajorOnly: true})
+	assert.Equal(t, []common.Hash{current.IdentHash()}, profiles.SelectRouters(candidates, -1))
+}
@@ -0,0 +1,228 @@
+package peer
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
+)
+
+// ProfileStore persists peer profiles between runs. FileProfileStore keeps
+// them in a JSON file and SQLProfileStore in an SQLite table; embedders can
+// provide their own.
+type ProfileStore interface {
+	// LoadProfiles returns the stored profiles
+	LoadProfiles() ([]Profile, error)
+	// SaveProfiles stores the profiles, replacing stored profiles of the
+	// same peers
+	SaveProfiles(profiles []Profile) error
+}
+
+// All returns a copy of every profile.
+func (profiles *Profiles) All() []Profile {
+	profiles.mutex.RLock()
+	defer profiles.mutex.RUnlock()
+	all := make([]Profile, 0, len(profiles.profiles))
+	for _, profile := range profiles.profiles {
+		all = append(all, *profile)
+	}
+	return all
+}
+
+// Load adds the profiles kept in store. Peers already profiled in this run
+// keep their current profile.
+func (profiles *Profiles) Load(store ProfileStore) error {
+	loaded, err := store.LoadProfiles()
+	if err != nil {
+		log.WithError(err).Error("Failed to load peer profiles")
+		return err
+	}
+	profiles.mutex.Lock()
+	defer profiles.mutex.Unlock()
+	for i := range loaded {
+		if _, ok := profiles.profiles[loaded[i].Hash]; !ok {
+			profiles.profiles[loaded[i].Hash] = &loaded[i]
+		}
+	}
+	log.WithField("profiles", len(loaded)).Debug("Loaded peer profiles")
+	return nil
+}
+
+// Save writes every profile to store.
+func (profiles *Profiles) Save(store ProfileStore) error {
+	all := profiles.All()
+	if err := store.SaveProfiles(all); err != nil {
+		log.WithError(err).Error("Failed to save peer profiles")
+		return err
+	}
+	log.WithField("profiles", len(all)).Debug("Saved peer profiles")
+	return nil
+}
+
+// FileProfileStore keeps profiles in a JSON file, replaced atomically on
+// every save.
+type FileProfileStore struct {
+	path string
+}
+
+// NewFileProfileStore returns a store keeping the profiles in the file at
+// path. A missing file holds no profiles.
+func NewFileProfileStore(path string) *FileProfileStore {
+	return &FileProfileStore{path: path}
+}
+
+// profileRecord is a Profile as written to the JSON file
+type profileRecord struct {
+	Hash      string    `json:"hash"`
+	Successes uint64    `json:"successes"`
+	Failures  uint64    `json:"failures"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// LoadProfiles reads the profiles from the file.
+func (store *FileProfileStore) LoadProfiles() ([]Profile, error) {
+	content, err := os.ReadFile(store.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []profileRecord
+	if err := json.Unmarshal(content, &records); err != nil {
+		return nil, err
+	}
+	loaded := make([]Profile, 0, len(records))
+	for _, record := range records {
+		hash, err := common.ParseHashBase64(record.Hash)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"path": store.path,
+				"hash": record.Hash,
+			}).Warn("Skipping peer profile with invalid hash")
+			continue
+		}
+		loaded = append(loaded, Profile{
+			Hash:      hash,
+			Successes: record.Successes,
+			Failures:  record.Failures,
+			LastSeen:  record.LastSeen,
+		})
+	}
+	return loaded, nil
+}
+
+// SaveProfiles writes the profiles to the file, merged with the stored
+// profiles of other peers.
+func (store *FileProfileStore) SaveProfiles(profiles []Profile) error {
+	stored, err := store.LoadProfiles()
+	if err != nil {
+		return err
+	}
+	merged := make(map[common.Hash]Profile, len(stored)+len(profiles))
+	for _, profile := range stored {
+		merged[profile.Hash] = profile
+	}
+	for _, profile := range profiles {
+		merged[profile.Hash] = profile
+	}
+	records := make([]profileRecord, 0, len(merged))
+	for _, profile := range merged {
+		records = append(records, profileRecord{
+			Hash:      profile.Hash.Base64(),
+			Successes: profile.Successes,
+			Failures:  profile.Failures,
+			LastSeen:  profile.LastSeen,
+		})
+	}
+	content, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(store.path), 0o700); err != nil {
+		return err
+	}
+	tmp := store.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, store.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// SQLProfileStore keeps profiles in a table of an SQLite database. The
+// embedder opens the database with the SQLite driver of its choice and hands
+// it over; the store does not import a driver itself.
+type SQLProfileStore struct {
+	db *sql.DB
+}
+
+// OpenSQLProfileStore creates the profile table in db if needed.
+func OpenSQLProfileStore(db *sql.DB) (*SQLProfileStore, error) {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS peer_profiles (
+		hash BLOB PRIMARY KEY,
+		successes INTEGER NOT NULL,
+		failures INTEGER NOT NULL,
+		last_seen INTEGER NOT NULL
+	)`); err != nil {
+		log.WithError(err).Error("Failed to create peer profile table")
+		return nil, err
+	}
+	return &SQLProfileStore{db: db}, nil
+}
+
+// LoadProfiles reads the profiles from the table.
+func (store *SQLProfileStore) LoadProfiles() ([]Profile, error) {
+	rows, err := store.db.Query(`SELECT hash, successes, failures, last_seen FROM peer_profiles`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var loaded []Profile
+	for rows.Next() {
+		var hash []byte
+		var successes, failures, lastSeen int64
+		if err := rows.Scan(&hash, &successes, &failures, &lastSeen); err != nil {
+			return nil, err
+		}
+		if len(hash) != len(common.Hash{}) {
+			log.WithField("hash_length", len(hash)).Warn("Skipping peer profile with invalid hash")
+			continue
+		}
+		profile := Profile{
+			Successes: uint64(successes),
+			Failures:  uint64(failures),
+			LastSeen:  time.Unix(0, lastSeen),
+		}
+		copy(profile.Hash[:], hash)
+		loaded = append(loaded, profile)
+	}
+	return loaded, rows.Err()
+}
+
+// SaveProfiles writes the profiles to the table in one transaction.
+func (store *SQLProfileStore) SaveProfiles(profiles []Profile) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if _, err := tx.Exec(`INSERT INTO peer_profiles (hash, successes, failures, last_seen) VALUES (?, ?, ?, ?)
+			ON CONFLICT(hash) DO UPDATE SET successes = excluded.successes,
+			failures = excluded.failures, last_seen = excluded.last_seen`,
+			profile.Hash[:], int64(profile.Successes), int64(profile.Failures), profile.LastSeen.UnixNano()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
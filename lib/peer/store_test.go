@@ -0,0 +1,136 @@
+package peer
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/util/sqltest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileProfileStoreRoundTrip(t *testing.T) {
+	store := NewFileProfileStore(filepath.Join(t.TempDir(), "profiles.json"))
+	loaded, err := store.LoadProfiles()
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	profiles := NewProfiles()
+	profiles.now = func() time.Time { return seen }
+	good, bad := common.Hash{1}, common.Hash{2}
+	profiles.RecordSuccess(good)
+	profiles.RecordSuccess(good)
+	profiles.RecordFailure(bad)
+	require.NoError(t, profiles.Save(store))
+
+	restored := NewProfiles()
+	require.NoError(t, restored.Load(store))
+	profile, ok := restored.Profile(good)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), profile.Successes)
+	assert.True(t, seen.Equal(profile.LastSeen))
+	assert.Equal(t, profiles.Score(bad), restored.Score(bad))
+}
+
+func TestProfilesLoadKeepsCurrentProfiles(t *testing.T) {
+	store := NewFileProfileStore(filepath.Join(t.TempDir(), "profiles.json"))
+	hash := common.Hash{1}
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: hash, Failures: 5}}))
+
+	profiles := NewProfiles()
+	profiles.RecordSuccess(hash)
+	require.NoError(t, profiles.Load(store))
+	profile, _ := profiles.Profile(hash)
+	assert.Equal(t, uint64(1), profile.Successes)
+	assert.Zero(t, profile.Failures)
+}
+
+func TestFileProfileStoreMergesOtherPeers(t *testing.T) {
+	store := NewFileProfileStore(filepath.Join(t.TempDir(), "profiles.json"))
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: common.Hash{1}, Successes: 1}}))
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: common.Hash{2}, Failures: 1}}))
+	loaded, err := store.LoadProfiles()
+	require.NoError(t, err)
+	assert.Len(t, loaded, 2)
+}
+
+func TestSQLProfileStoreRoundTrip(t *testing.T) {
+	db, _ := sqltest.Open()
+	defer db.Close()
+	store, err := OpenSQLProfileStore(db)
+	require.NoError(t, err)
+	loaded, err := store.LoadProfiles()
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	profiles := NewProfiles()
+	profiles.now = func() time.Time { return seen }
+	good, bad := common.Hash{1}, common.Hash{2}
+	profiles.RecordSuccess(good)
+	profiles.RecordSuccess(good)
+	profiles.RecordFailure(bad)
+	require.NoError(t, profiles.Save(store))
+
+	restored := NewProfiles()
+	require.NoError(t, restored.Load(store))
+	profile, ok := restored.Profile(good)
+	require.True(t, ok)
+	assert.Equal(t, uint64(2), profile.Successes)
+	assert.True(t, seen.Equal(profile.LastSeen))
+	assert.Equal(t, profiles.Score(bad), restored.Score(bad))
+
+	// saving again updates the rows in place
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: good, Successes: 7}}))
+	loaded, err = store.LoadProfiles()
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, uint64(7), loaded[0].Successes)
+}
+
+func TestSQLProfileStoreRollsBackFailedSave(t *testing.T) {
+	db, state := sqltest.Open()
+	defer db.Close()
+	store, err := OpenSQLProfileStore(db)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: common.Hash{1}, Successes: 1}}))
+
+	// the second insert of the transaction fails after the first succeeded
+	failure := errors.New("disk full")
+	second := common.Hash{2}
+	state.Fail(func(query string, args []driver.Value) error {
+		if len(args) > 0 && bytes.Equal(args[0].([]byte), second[:]) {
+			return failure
+		}
+		return nil
+	})
+	err = store.SaveProfiles([]Profile{{Hash: common.Hash{1}, Successes: 5}, {Hash: second, Failures: 1}})
+	assert.ErrorIs(t, err, failure)
+	state.Fail(nil)
+
+	loaded, err := store.LoadProfiles()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, uint64(1), loaded[0].Successes)
+}
+
+func TestSQLProfileStoreSkipsInvalidHash(t *testing.T) {
+	db, _ := sqltest.Open()
+	defer db.Close()
+	store, err := OpenSQLProfileStore(db)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO peer_profiles (hash, successes, failures, last_seen) VALUES (?, ?, ?, ?)`, []byte{1, 2, 3}, 1, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, store.SaveProfiles([]Profile{{Hash: common.Hash{1}, Successes: 2}}))
+
+	loaded, err := store.LoadProfiles()
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	assert.Equal(t, common.Hash{1}, loaded[0].Hash)
+}
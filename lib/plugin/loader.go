@@ -0,0 +1,376 @@
+// Package plugin installs and manages I2P plugins delivered as SU3 files.
+//
+// Plugins are unpacked into their own directory under the plugin directory,
+// and the Loader calls the registered Hooks at each step of their lifecycle.
+// Running plugin code is left to the hooks, so that the console can decide
+// what a plugin is allowed to do.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/su3"
+	"github.com/go-i2p/go-i2p/lib/update"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+var (
+	ErrPluginNotFound     = errors.New("plugin not found")
+	ErrPluginInstalled    = errors.New("plugin already installed")
+	ErrPluginNotInstalled = errors.New("plugin update-only and not installed")
+	ErrPluginDowngrade    = errors.New("plugin version older than the installed one")
+	ErrPluginSigner       = errors.New("plugin update signed by a different signer than the installed plugin")
+	ErrStopDisabled       = errors.New("plugin cannot be stopped")
+	ErrUnsafePath         = errors.New("plugin archive entry escapes plugin directory")
+)
+
+// Hook is called with the plugin a lifecycle event applies to.
+// An error returned from a hook aborts the operation.
+type Hook func(p *Plugin) error
+
+// Hooks are the callbacks run by the Loader. Any of them may be nil.
+type Hooks struct {
+	// Installed runs after the archive has been unpacked into a staging
+	// directory, the plugin's Dir until it replaces the installed one.
+	Installed Hook
+	// Started runs when the plugin is started.
+	Started Hook
+	// Stopped runs when the plugin is stopped.
+	Stopped Hook
+	// Uninstalled runs before the plugin directory is removed.
+	Uninstalled Hook
+}
+
+// Plugin is a plugin installed in the plugin directory.
+type Plugin struct {
+	Config  *su3.PluginConfig
+	Dir     string
+	Running bool
+}
+
+// Loader keeps track of the plugins installed in a directory.
+type Loader struct {
+	dir     string
+	hooks   Hooks
+	mutex   sync.Mutex
+	plugins map[string]*Plugin
+}
+
+// NewLoader returns a Loader for plugins installed under dir.
+func NewLoader(dir string, hooks Hooks) *Loader {
+	log.WithField("dir", dir).Debug("Creating plugin loader")
+	return &Loader{
+		dir:     dir,
+		hooks:   hooks,
+		plugins: make(map[string]*Plugin),
+	}
+}
+
+// Load scans the plugin directory for installed plugins. Plugins which fail
+// to parse are logged and skipped.
+func (l *Loader) Load() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	entries, err := os.ReadDir(l.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		log.WithField("dir", l.dir).Debug("Plugin directory does not exist")
+		return nil
+	}
+	if err != nil {
+		log.WithError(err).Error("Failed to read plugin directory")
+		return err
+	}
+	for _, entry := range entries {
+		// staging directories left over by an interrupted Install are hidden
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		dir := filepath.Join(l.dir, entry.Name())
+		f, err := os.Open(filepath.Join(dir, su3.PluginConfigFile))
+		if err != nil {
+			continue
+		}
+		config, err := su3.ParsePluginConfig(f)
+		f.Close()
+		if err != nil {
+			log.WithError(err).WithField("dir", dir).Warn("Skipping plugin with invalid plugin.config")
+			continue
+		}
+		if _, ok := l.plugins[config.Name]; !ok {
+			l.plugins[config.Name] = &Plugin{Config: config, Dir: dir}
+		}
+	}
+	log.WithField("count", len(l.plugins)).Debug("Loaded plugins")
+	return nil
+}
+
+// Install unpacks a verified plugin into the plugin directory and runs the
+// Installed hook. Installing a newer version of a plugin stops and replaces
+// it, unless the new version is install-only. Reinstalling the installed
+// version or an older one is refused, as is an update from a different signer
+// than the installed plugin's, which su3.ReadPlugin matched to the SU3 signer
+// ID. The new version is unpacked next to the installed one and only replaces
+// it once the Installed hook succeeded, so a failed update leaves the
+// installed plugin as it was. Unless the plugin asks not to be, it is started
+// afterwards.
+func (l *Loader) Install(p *su3.Plugin) (*Plugin, error) {
+	config := p.Config
+	log.WithFields(logrus.Fields{
+		"name":    config.Name,
+		"version": config.Version,
+	}).Info("Installing plugin")
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	existing, installed := l.plugins[config.Name]
+	if installed {
+		if config.InstallOnly {
+			return nil, fmt.Errorf("%w: %s is install-only", ErrPluginInstalled, config.Name)
+		}
+		if config.Signer != existing.Config.Signer {
+			log.WithFields(logrus.Fields{
+				"name":             config.Name,
+				"signer":           config.Signer,
+				"installed_signer": existing.Config.Signer,
+			}).Error("Refusing plugin update from a different signer")
+			return nil, fmt.Errorf("%w: %s is signed by %s, not %s", ErrPluginSigner, config.Name, config.Signer, existing.Config.Signer)
+		}
+		if !update.NewerVersion(config.Version, existing.Config.Version) {
+			if update.NewerVersion(existing.Config.Version, config.Version) {
+				return nil, fmt.Errorf("%w: %s is older than %s", ErrPluginDowngrade, config.Version, existing.Config.Version)
+			}
+			return nil, ErrPluginInstalled
+		}
+	}
+	if !installed && config.UpdateOnly {
+		return nil, ErrPluginNotInstalled
+	}
+
+	if err := os.MkdirAll(l.dir, 0o755); err != nil {
+		return nil, err
+	}
+	staging, err := os.MkdirTemp(l.dir, "."+config.Name+"-install-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	if err := os.Chmod(staging, 0o755); err != nil {
+		return nil, err
+	}
+	if err := extract(p, staging); err != nil {
+		log.WithError(err).Error("Failed to unpack plugin")
+		return nil, err
+	}
+	plugin := &Plugin{Config: config, Dir: staging}
+	if err := l.run(l.hooks.Installed, plugin); err != nil {
+		return nil, err
+	}
+
+	wasRunning := installed && existing.Running
+	if wasRunning {
+		if err := l.stop(existing, true); err != nil {
+			return nil, err
+		}
+	}
+	dir := filepath.Join(l.dir, config.Name)
+	if err := replaceDir(staging, dir); err != nil {
+		log.WithError(err).Error("Failed to replace plugin directory")
+		if wasRunning {
+			if startErr := l.start(existing); startErr != nil {
+				log.WithError(startErr).WithField("name", config.Name).Warn("Failed to restart installed plugin")
+			}
+		}
+		return nil, err
+	}
+	plugin.Dir = dir
+	l.plugins[config.Name] = plugin
+	if !config.DontStartAtInstall {
+		if err := l.start(plugin); err != nil {
+			return plugin, err
+		}
+	}
+	return plugin, nil
+}
+
+// replaceDir renames staging to dir, moving a previous dir out of the way
+// first and putting it back if the rename fails.
+func replaceDir(staging, dir string) error {
+	backup := ""
+	if _, err := os.Stat(dir); err == nil {
+		backup = filepath.Join(filepath.Dir(dir), "."+filepath.Base(dir)+"-old")
+		if err := os.RemoveAll(backup); err != nil {
+			return err
+		}
+		if err := os.Rename(dir, backup); err != nil {
+			return err
+		}
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		if backup != "" {
+			if restoreErr := os.Rename(backup, dir); restoreErr != nil {
+				log.WithError(restoreErr).WithField("dir", dir).Error("Failed to restore plugin directory")
+			}
+		}
+		return err
+	}
+	if backup != "" {
+		return os.RemoveAll(backup)
+	}
+	return nil
+}
+
+// Start starts an installed plugin.
+func (l *Loader) Start(name string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	plugin, ok := l.plugins[name]
+	if !ok {
+		return ErrPluginNotFound
+	}
+	return l.start(plugin)
+}
+
+// Stop stops a running plugin, unless the plugin has disabled stopping.
+func (l *Loader) Stop(name string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	plugin, ok := l.plugins[name]
+	if !ok {
+		return ErrPluginNotFound
+	}
+	return l.stop(plugin, false)
+}
+
+// StopAll stops every running plugin, including ones which have disabled
+// stopping. It is meant to be called on router shutdown.
+func (l *Loader) StopAll() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, plugin := range l.plugins {
+		if err := l.stop(plugin, true); err != nil {
+			log.WithError(err).WithField("name", plugin.Config.Name).Warn("Failed to stop plugin")
+		}
+	}
+}
+
+// Uninstall stops a plugin and removes its directory.
+func (l *Loader) Uninstall(name string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	plugin, ok := l.plugins[name]
+	if !ok {
+		return ErrPluginNotFound
+	}
+	if err := l.stop(plugin, true); err != nil {
+		return err
+	}
+	if err := l.run(l.hooks.Uninstalled, plugin); err != nil {
+		return err
+	}
+	delete(l.plugins, name)
+	log.WithField("name", name).Info("Uninstalling plugin")
+	return os.RemoveAll(plugin.Dir)
+}
+
+// Plugins returns the installed plugins sorted by name.
+func (l *Loader) Plugins() []*Plugin {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	plugins := make([]*Plugin, 0, len(l.plugins))
+	for _, plugin := range l.plugins {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Config.Name < plugins[j].Config.Name
+	})
+	return plugins
+}
+
+func (l *Loader) start(plugin *Plugin) error {
+	if plugin.Running {
+		return nil
+	}
+	if err := l.run(l.hooks.Started, plugin); err != nil {
+		return err
+	}
+	plugin.Running = true
+	log.WithField("name", plugin.Config.Name).Info("Started plugin")
+	return nil
+}
+
+func (l *Loader) stop(plugin *Plugin, force bool) error {
+	if !plugin.Running {
+		return nil
+	}
+	if plugin.Config.DisableStop && !force {
+		return ErrStopDisabled
+	}
+	if err := l.run(l.hooks.Stopped, plugin); err != nil {
+		return err
+	}
+	plugin.Running = false
+	log.WithField("name", plugin.Config.Name).Info("Stopped plugin")
+	return nil
+}
+
+func (l *Loader) run(hook Hook, plugin *Plugin) error {
+	if hook == nil {
+		return nil
+	}
+	if err := hook(plugin); err != nil {
+		log.WithError(err).WithField("name", plugin.Config.Name).Error("Plugin hook failed")
+		return err
+	}
+	return nil
+}
+
+// extract unpacks the plugin archive into the empty directory dir.
+func extract(p *su3.Plugin, dir string) error {
+	for _, file := range p.Archive.File {
+		target := filepath.Join(dir, file.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			log.WithField("entry", file.Name).Error("Plugin archive entry escapes plugin directory")
+			return fmt.Errorf("%w: %s", ErrUnsafePath, file.Name)
+		}
+		if file.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return err
+		}
+		if err := extractFile(file.Open, target, file.Mode().Perm()|0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFile(open func() (io.ReadCloser, error), target string, mode os.FileMode) error {
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
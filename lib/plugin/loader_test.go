@@ -0,0 +1,186 @@
+package plugin
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/su3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPlugin(t *testing.T, config string, files map[string]string) *su3.Plugin {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files[su3.PluginConfigFile] = config
+	for name, content := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	parsed, err := su3.ParsePluginConfig(strings.NewReader(config))
+	require.NoError(t, err)
+	return &su3.Plugin{Config: parsed, Archive: archive}
+}
+
+func TestLoaderLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	var events []string
+	record := func(event string) Hook {
+		return func(p *Plugin) error {
+			events = append(events, event+":"+p.Config.Name)
+			return nil
+		}
+	}
+	loader := NewLoader(dir, Hooks{
+		Installed:   record("installed"),
+		Started:     record("started"),
+		Stopped:     record("stopped"),
+		Uninstalled: record("uninstalled"),
+	})
+
+	p := testPlugin(t, "name=example\nsigner=a@mail.i2p\nversion=1\n", map[string]string{
+		"lib/example.jar": "jar",
+	})
+	installed, err := loader.Install(p)
+	require.NoError(t, err)
+	assert.True(t, installed.Running)
+	content, err := os.ReadFile(filepath.Join(dir, "example", "lib", "example.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "jar", string(content))
+
+	_, err = loader.Install(p)
+	assert.ErrorIs(t, err, ErrPluginInstalled)
+
+	require.NoError(t, loader.Stop("example"))
+	require.NoError(t, loader.Start("example"))
+	require.NoError(t, loader.Uninstall("example"))
+	assert.NoDirExists(t, filepath.Join(dir, "example"))
+	assert.Empty(t, loader.Plugins())
+
+	assert.Equal(t, []string{
+		"installed:example",
+		"started:example",
+		"stopped:example",
+		"started:example",
+		"stopped:example",
+		"uninstalled:example",
+	}, events)
+}
+
+func TestLoaderVersions(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewLoader(dir, Hooks{})
+	config := "name=example\nsigner=a@mail.i2p\ndont-start-at-install=true\nversion="
+	_, err := loader.Install(testPlugin(t, config+"1.2\n", map[string]string{}))
+	require.NoError(t, err)
+
+	_, err = loader.Install(testPlugin(t, config+"1.2.0\n", map[string]string{}))
+	assert.ErrorIs(t, err, ErrPluginInstalled)
+	_, err = loader.Install(testPlugin(t, config+"1.1\n", map[string]string{}))
+	assert.ErrorIs(t, err, ErrPluginDowngrade)
+	_, err = loader.Install(testPlugin(t, config+"1.3\ninstall-only=true\n", map[string]string{}))
+	assert.ErrorIs(t, err, ErrPluginInstalled)
+	assert.Equal(t, "1.2", loader.Plugins()[0].Config.Version)
+
+	updated, err := loader.Install(testPlugin(t, config+"1.10\n", map[string]string{}))
+	require.NoError(t, err)
+	assert.Equal(t, "1.10", updated.Config.Version)
+}
+
+func TestLoaderLoad(t *testing.T) {
+	dir := t.TempDir()
+	p := testPlugin(t, "name=example\nsigner=a@mail.i2p\nversion=1\ndont-start-at-install=true\n", map[string]string{})
+	installed, err := NewLoader(dir, Hooks{}).Install(p)
+	require.NoError(t, err)
+	assert.False(t, installed.Running)
+
+	loader := NewLoader(dir, Hooks{})
+	require.NoError(t, loader.Load())
+	plugins := loader.Plugins()
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "example", plugins[0].Config.Name)
+	assert.Equal(t, filepath.Join(dir, "example"), plugins[0].Dir)
+}
+
+func TestLoaderDisableStop(t *testing.T) {
+	loader := NewLoader(t.TempDir(), Hooks{})
+	p := testPlugin(t, "name=example\nsigner=a@mail.i2p\nversion=1\ndisableStop=true\n", map[string]string{})
+	_, err := loader.Install(p)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, loader.Stop("example"), ErrStopDisabled)
+	loader.StopAll()
+	assert.False(t, loader.Plugins()[0].Running)
+}
+
+func TestLoaderUnsafePath(t *testing.T) {
+	loader := NewLoader(t.TempDir(), Hooks{})
+	p := testPlugin(t, "name=example\nsigner=a@mail.i2p\nversion=1\n", map[string]string{
+		"../escape": "bad",
+	})
+	_, err := loader.Install(p)
+	assert.ErrorIs(t, err, ErrUnsafePath)
+}
+
+func TestLoaderRefusesOtherSigner(t *testing.T) {
+	loader := NewLoader(t.TempDir(), Hooks{})
+	config := "name=example\ndont-start-at-install=true\n"
+	_, err := loader.Install(testPlugin(t, config+"signer=a@mail.i2p\nversion=1\n", map[string]string{}))
+	require.NoError(t, err)
+
+	_, err = loader.Install(testPlugin(t, config+"signer=b@mail.i2p\nversion=2\n", map[string]string{}))
+	assert.ErrorIs(t, err, ErrPluginSigner)
+	assert.Equal(t, "1", loader.Plugins()[0].Config.Version)
+}
+
+func TestLoaderFailedUpdateKeepsInstalledPlugin(t *testing.T) {
+	dir := t.TempDir()
+	fail := false
+	loader := NewLoader(dir, Hooks{
+		Installed: func(p *Plugin) error {
+			if fail {
+				return errors.New("hook failed")
+			}
+			return nil
+		},
+	})
+	config := "name=example\nsigner=a@mail.i2p\nversion="
+	installed, err := loader.Install(testPlugin(t, config+"1\n", map[string]string{"lib/example.jar": "one"}))
+	require.NoError(t, err)
+	require.True(t, installed.Running)
+
+	fail = true
+	_, err = loader.Install(testPlugin(t, config+"2\n", map[string]string{"lib/example.jar": "two"}))
+	require.Error(t, err)
+	plugins := loader.Plugins()
+	require.Len(t, plugins, 1)
+	assert.Same(t, installed, plugins[0])
+	assert.True(t, installed.Running, "the installed plugin keeps running")
+	content, err := os.ReadFile(filepath.Join(dir, "example", "lib", "example.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "one", string(content))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the staging directory is removed")
+
+	fail = false
+	updated, err := loader.Install(testPlugin(t, config+"2\n", map[string]string{"lib/example.jar": "two"}))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "example"), updated.Dir)
+	content, err = os.ReadFile(filepath.Join(dir, "example", "lib", "example.jar"))
+	require.NoError(t, err)
+	assert.Equal(t, "two", string(content))
+	entries, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the previous version is removed")
+}
@@ -0,0 +1,160 @@
+package research
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// file in the working directory holding the seed of the research key
+const KEY_FILE_NAME = "research.key"
+
+var (
+	ErrCollectorRejected = errors.New("collector rejected research report")
+	ErrKeySealed         = errors.New("research key is sealed with a passphrase")
+)
+
+// Publisher sends signed reports to the collector.
+type Publisher struct {
+	// URL reports are posted to, normally on a .b32.i2p collector
+	URL string
+	// Client used to post reports. To publish over I2P it should be
+	// configured to use the router's HTTP proxy.
+	Client *http.Client
+	// Key signs the reports
+	Key crypto.Ed25519PrivateKey
+}
+
+// Publish signs the report and posts it to the collector.
+func (publisher *Publisher) Publish(ctx context.Context, report Report) error {
+	signed, err := report.Sign(publisher.Key)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(signed)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := publisher.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.WithError(err).Warn("Failed to publish research report")
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s", ErrCollectorRejected, resp.Status)
+	}
+	log.WithFields(logrus.Fields{
+		"url":     publisher.URL,
+		"version": report.Version,
+		"uptime":  report.Uptime,
+	}).Debug("Published research report")
+	return nil
+}
+
+// LoadOrCreateKey reads the research key from path, generating and storing
+// a new key if there is none. The key is unrelated to the router identity.
+func LoadOrCreateKey(path string) (crypto.Ed25519PrivateKey, error) {
+	return LoadOrCreateSealedKey(path, nil)
+}
+
+// LoadOrCreateSealedKey is LoadOrCreateKey for a key file sealed with
+// passphrase. A key stored unsealed before is sealed in place if the file
+// can be written. With no passphrase the key is stored unsealed.
+func LoadOrCreateSealedKey(path string, passphrase []byte) (crypto.Ed25519PrivateKey, error) {
+	seed, err := os.ReadFile(path)
+	if err == nil {
+		return loadKey(path, seed, passphrase)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	key, err := crypto.GenerateEd25519PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := writeKey(path, key.Seed(), passphrase); err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"path":   path,
+		"sealed": len(passphrase) > 0,
+	}).Info("Created research report key")
+	return key, nil
+}
+
+// loadKey parses the contents of a key file, sealing an unsealed one if a
+// passphrase is given
+func loadKey(path string, content, passphrase []byte) (crypto.Ed25519PrivateKey, error) {
+	if !crypto.IsSealed(content) {
+		key, err := crypto.CreateEd25519PrivateKeyFromBytes(content)
+		if err != nil || len(passphrase) == 0 {
+			return key, err
+		}
+		if err := writeKey(path, content, passphrase); err != nil {
+			// e.g. on read only storage, the key is still usable
+			log.WithError(err).WithField("path", path).Warn("Failed to seal research report key")
+			return key, nil
+		}
+		log.WithField("path", path).Info("Sealed research report key")
+		return key, nil
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrKeySealed, path)
+	}
+	seed, err := crypto.OpenWithPassphrase(passphrase, content)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.CreateEd25519PrivateKeyFromBytes(seed)
+}
+
+// writeKey atomically replaces the key file with a key seed, sealed if a
+// passphrase is given, so an interrupted migration leaves the old file
+func writeKey(path string, seed, passphrase []byte) error {
+	if len(passphrase) > 0 {
+		sealed, err := crypto.SealWithPassphrase(passphrase, seed)
+		if err != nil {
+			return err
+		}
+		seed = sealed
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(seed); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
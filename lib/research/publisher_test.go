@@ -0,0 +1,102 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+func TestPublish(t *testing.T) {
+	received := make(chan Report, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var signed SignedReport
+		if err := json.NewDecoder(req.Body).Decode(&signed); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		report, err := signed.Verify()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		received <- report
+	}))
+	defer server.Close()
+
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), KEY_FILE_NAME))
+	require.NoError(t, err)
+	publisher := &Publisher{URL: server.URL, Key: key}
+	report := NewReport("0.1.0", 2*time.Hour, nil, time.Now())
+	require.NoError(t, publisher.Publish(context.Background(), report))
+	assert.Equal(t, UPTIME_DAY, (<-received).Uptime)
+}
+
+func TestPublishRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	key, err := LoadOrCreateKey(filepath.Join(t.TempDir(), KEY_FILE_NAME))
+	require.NoError(t, err)
+	publisher := &Publisher{URL: server.URL, Key: key}
+	err = publisher.Publish(context.Background(), NewReport("0.1.0", 0, nil, time.Now()))
+	assert.ErrorIs(t, err, ErrCollectorRejected)
+}
+
+func TestLoadOrCreateKeyIsStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KEY_FILE_NAME)
+	created, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+	loaded, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+	assert.Equal(t, created, loaded)
+}
+
+func TestLoadOrCreateSealedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KEY_FILE_NAME)
+	passphrase := []byte("from the keychain")
+	created, err := LoadOrCreateSealedKey(path, passphrase)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, crypto.IsSealed(content))
+
+	loaded, err := LoadOrCreateSealedKey(path, passphrase)
+	require.NoError(t, err)
+	assert.Equal(t, created, loaded)
+
+	_, err = LoadOrCreateKey(path)
+	assert.ErrorIs(t, err, ErrKeySealed)
+	_, err = LoadOrCreateSealedKey(path, []byte("wrong"))
+	assert.ErrorIs(t, err, crypto.ErrWrongPassphrase)
+}
+
+func TestLoadOrCreateSealedKeySealsExistingKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KEY_FILE_NAME)
+	created, err := LoadOrCreateKey(path)
+	require.NoError(t, err)
+
+	sealed, err := LoadOrCreateSealedKey(path, []byte("from the keychain"))
+	require.NoError(t, err)
+	assert.Equal(t, created, sealed)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, crypto.IsSealed(content))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary file is left next to the key")
+}
@@ -0,0 +1,141 @@
+// Package research publishes anonymized router statistics to a collector for
+// network health research. Publication is opt-in.
+//
+// A report carries only coarse values: the router version, an uptime bucket
+// and tunnel counts rounded down to a multiple of ten. It names no router
+// identity. Reports are signed with an Ed25519 key kept for this purpose
+// only, which lets a collector tell reports of different routers apart
+// without learning which routers they are.
+package research
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+var ErrInvalidReportSignature = errors.New("invalid research report signature")
+
+// Uptime buckets reported instead of the exact uptime
+const (
+	UPTIME_HOUR   = "<1h"
+	UPTIME_DAY    = "1h-1d"
+	UPTIME_WEEK   = "1d-1w"
+	UPTIME_LONGER = ">1w"
+)
+
+// counts are rounded down to a multiple of COUNT_GRANULARITY
+const COUNT_GRANULARITY = 10
+
+// Report is one publication of router statistics.
+type Report struct {
+	// Version of the router
+	Version string `json:"version"`
+	// Uptime is one of the UPTIME_* buckets
+	Uptime string `json:"uptime"`
+	// Tunnels counts tunnels by kind, rounded with RoundCount
+	Tunnels map[string]int `json:"tunnels"`
+	// Published is the hour the report was made
+	Published time.Time `json:"published"`
+}
+
+// NewReport builds a report, bucketing and rounding the values given.
+func NewReport(version string, uptime time.Duration, tunnels map[string]int, now time.Time) Report {
+	rounded := make(map[string]int, len(tunnels))
+	for kind, count := range tunnels {
+		rounded[kind] = RoundCount(count)
+	}
+	return Report{
+		Version:   version,
+		Uptime:    UptimeBucket(uptime),
+		Tunnels:   rounded,
+		Published: now.UTC().Truncate(time.Hour),
+	}
+}
+
+// UptimeBucket returns the UPTIME_* bucket of uptime.
+func UptimeBucket(uptime time.Duration) string {
+	switch {
+	case uptime < time.Hour:
+		return UPTIME_HOUR
+	case uptime < 24*time.Hour:
+		return UPTIME_DAY
+	case uptime < 7*24*time.Hour:
+		return UPTIME_WEEK
+	default:
+		return UPTIME_LONGER
+	}
+}
+
+// RoundCount rounds count down to a multiple of COUNT_GRANULARITY.
+func RoundCount(count int) int {
+	if count < 0 {
+		return 0
+	}
+	return count - count%COUNT_GRANULARITY
+}
+
+// SignedReport is the JSON document sent to the collector. Report holds the
+// exact bytes signed, so the collector can verify them before decoding.
+type SignedReport struct {
+	Report    json.RawMessage `json:"report"`
+	PublicKey string          `json:"public_key"`
+	Signature string          `json:"signature"`
+}
+
+// Sign signs the report with the research key.
+func (report Report) Sign(key crypto.Ed25519PrivateKey) (*SignedReport, error) {
+	content, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := key.NewSigner()
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(content)
+	if err != nil {
+		return nil, err
+	}
+	public, err := key.Public()
+	if err != nil {
+		return nil, err
+	}
+	return &SignedReport{
+		Report:    content,
+		PublicKey: base64.EncodeToString(public.Bytes()),
+		Signature: base64.EncodeToString(signature),
+	}, nil
+}
+
+// Verify checks the signature and returns the report.
+func (signed SignedReport) Verify() (Report, error) {
+	var report Report
+	public, err := base64.DecodeString(signed.PublicKey)
+	if err != nil {
+		return report, ErrInvalidReportSignature
+	}
+	signature, err := base64.DecodeString(signed.Signature)
+	if err != nil {
+		return report, ErrInvalidReportSignature
+	}
+	key, err := crypto.CreateEd25519PublicKeyFromBytes(public)
+	if err != nil {
+		return report, ErrInvalidReportSignature
+	}
+	verifier, err := key.NewVerifier()
+	if err != nil {
+		return report, err
+	}
+	if err := verifier.Verify(signed.Report, signature); err != nil {
+		return report, ErrInvalidReportSignature
+	}
+	err = json.Unmarshal(signed.Report, &report)
+	return report, err
+}
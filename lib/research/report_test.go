@@ -0,0 +1,48 @@
+package research
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReportAnonymizes(t *testing.T) {
+	now := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	report := NewReport("0.1.0", 30*time.Hour, map[string]int{"participating": 1234, "warm": 3}, now)
+	assert.Equal(t, UPTIME_WEEK, report.Uptime)
+	assert.Equal(t, map[string]int{"participating": 1230, "warm": 0}, report.Tunnels)
+	assert.Equal(t, time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC), report.Published)
+}
+
+func TestUptimeBucket(t *testing.T) {
+	assert.Equal(t, UPTIME_HOUR, UptimeBucket(0))
+	assert.Equal(t, UPTIME_DAY, UptimeBucket(time.Hour))
+	assert.Equal(t, UPTIME_WEEK, UptimeBucket(24*time.Hour))
+	assert.Equal(t, UPTIME_LONGER, UptimeBucket(8*24*time.Hour))
+}
+
+func TestRoundCount(t *testing.T) {
+	assert.Equal(t, 0, RoundCount(-5))
+	assert.Equal(t, 0, RoundCount(9))
+	assert.Equal(t, 10, RoundCount(19))
+}
+
+func TestSignedReportVerify(t *testing.T) {
+	key, err := crypto.GenerateEd25519PrivateKey()
+	require.NoError(t, err)
+	report := NewReport("0.1.0", time.Minute, map[string]int{"participating": 42}, time.Now())
+	signed, err := report.Sign(key)
+	require.NoError(t, err)
+
+	verified, err := signed.Verify()
+	require.NoError(t, err)
+	assert.Equal(t, report.Tunnels, verified.Tunnels)
+	assert.True(t, report.Published.Equal(verified.Published))
+
+	signed.Report = []byte(`{"version":"9.9.9"}`)
+	_, err = signed.Verify()
+	assert.ErrorIs(t, err, ErrInvalidReportSignature)
+}
@@ -0,0 +1,95 @@
+package router
+
+import (
+	"net"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/clientauth"
+	"github.com/go-i2p/go-i2p/lib/i2cp"
+	"github.com/go-i2p/go-i2p/lib/util/tlsconfig"
+)
+
+// ClientAuthenticator returns the login and bind checks for the I2CP and SAM
+// ports and the management API, set up from the clients configuration. With
+// clients.tls set, its listeners serve TLS. Observers accept no client
+// sessions and return ErrObserverMode.
+func (r *Router) ClientAuthenticator() (*clientauth.Authenticator, error) {
+	if err := r.AllowClientSessions(); err != nil {
+		log.Warn("Not opening client ports in observer mode")
+		return nil, err
+	}
+	return r.clientAuthenticator()
+}
+
+// clientAuthenticator returns the checks of ClientAuthenticator, also for
+// observers, which serve the management API
+func (r *Router) clientAuthenticator() (*clientauth.Authenticator, error) {
+	if r.cfg == nil || r.cfg.Clients == nil {
+		return clientauth.NewAuthenticator(nil)
+	}
+	clients := r.cfg.Clients
+	auth, err := clientauth.NewAuthenticator(clients)
+	if err != nil {
+		return nil, err
+	}
+	if !clients.TLS {
+		return auth, nil
+	}
+	// the certificate is valid for the single addresses listeners may use
+	var hosts []string
+	for _, addr := range clients.AllowedBindAddresses {
+		if !strings.Contains(addr, "/") && net.ParseIP(addr) != nil {
+			hosts = append(hosts, addr)
+		}
+	}
+	tlsConfig, err := tlsconfig.ServerConfig(clients.TLSCertFile, clients.TLSKeyFile, r.cfg.KeyPath, hosts)
+	if err != nil {
+		log.WithError(err).Error("Failed to set up TLS for client ports")
+		return nil, err
+	}
+	auth.SetTLSConfig(tlsConfig)
+	return auth, nil
+}
+
+// upper bound of each bandwidth class in KBps, X has none
+var bandwidthClassLimits = map[string]uint32{
+	"K": 12,
+	"L": 48,
+	"M": 64,
+	"N": 128,
+	"O": 256,
+	"P": 2000,
+}
+
+// ClientBandwidthLimits returns the limits answered to an I2CP
+// GetBandwidthLimits message. The router limits follow the bandwidth class,
+// the session limits the clients configuration, capped at the router limits.
+func (r *Router) ClientBandwidthLimits() i2cp.BandwidthLimits {
+	var limits i2cp.BandwidthLimits
+	if r.cfg == nil {
+		return limits
+	}
+	if r.cfg.Workers != nil {
+		limit := bandwidthClassLimits[strings.ToUpper(r.cfg.Workers.BandwidthClass)]
+		limits.RouterInbound, limits.RouterInboundBurst = limit, limit
+		limits.RouterOutbound, limits.RouterOutboundBurst = limit, limit
+	}
+	limits.ClientInbound, limits.ClientOutbound = limits.RouterInbound, limits.RouterOutbound
+	if r.cfg.Clients != nil {
+		limits.ClientInbound = capLimit(r.cfg.Clients.InboundLimit, limits.RouterInbound)
+		limits.ClientOutbound = capLimit(r.cfg.Clients.OutboundLimit, limits.RouterOutbound)
+	}
+	return limits
+}
+
+// capLimit returns the configured limit, or routerLimit if it is unset or
+// higher. 0 is no limit for both.
+func capLimit(configured int, routerLimit uint32) uint32 {
+	if configured <= 0 {
+		return routerLimit
+	}
+	if routerLimit != 0 && uint32(configured) > routerLimit {
+		return routerLimit
+	}
+	return uint32(configured)
+}
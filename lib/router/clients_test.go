@@ -0,0 +1,74 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientAuthenticatorTLS(t *testing.T) {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Clients:    &config.ClientConfig{TLS: true},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+
+	auth, err := r.ClientAuthenticator()
+	require.NoError(t, err)
+	require.NotNil(t, auth.TLSConfig())
+	listener, err := auth.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Write([]byte("HELLO REPLY RESULT=OK VERSION=3.3\n"))
+			conn.Close()
+		}
+	}()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(mustParseCertificate(t, auth.TLSConfig().Certificates[0].Certificate[0]))
+	conn, err := tls.Dial("tcp", listener.Addr().String(), &tls.Config{RootCAs: roots})
+	require.NoError(t, err)
+	defer conn.Close()
+	reply, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Contains(t, string(reply), "RESULT=OK")
+
+	plain := newStatsTestRouter(t)
+	auth, err = plain.ClientAuthenticator()
+	require.NoError(t, err)
+	assert.Nil(t, auth.TLSConfig())
+}
+
+func mustParseCertificate(t *testing.T, der []byte) *x509.Certificate {
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestClientBandwidthLimits(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.cfg.Workers.BandwidthClass = "O"
+	r.cfg.Clients = &config.ClientConfig{InboundLimit: 64, OutboundLimit: 1000}
+
+	limits := r.ClientBandwidthLimits()
+	assert.Equal(t, uint32(256), limits.RouterInbound)
+	assert.Equal(t, uint32(256), limits.RouterOutbound)
+	assert.Equal(t, uint32(64), limits.ClientInbound)
+	// sessions get no more than the router
+	assert.Equal(t, uint32(256), limits.ClientOutbound)
+
+	r.cfg.Workers.BandwidthClass = "X"
+	limits = r.ClientBandwidthLimits()
+	assert.Equal(t, uint32(0), limits.RouterInbound)
+	assert.Equal(t, uint32(1000), limits.ClientOutbound)
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"github.com/go-i2p/go-i2p/lib/util/features"
+)
+
+// initFeatures applies the configured feature flags to the registry
+// subsystems check. Unknown features are logged and otherwise ignored, so a
+// configuration written for a newer router still starts.
+func (r *Router) initFeatures() {
+	if r.cfg == nil || len(r.cfg.Features) == 0 {
+		return
+	}
+	if err := features.Default.Apply(r.cfg.Features); err != nil {
+		log.WithError(err).Warn("Ignoring unknown feature flags")
+	}
+	for _, feature := range features.Default.All() {
+		if feature.Enabled != feature.Default {
+			log.WithField("feature", feature.Name).Infof("Feature %s enabled: %t", feature.Name, feature.Enabled)
+		}
+	}
+}
+
+// Features returns the feature flags and their state.
+func (r *Router) Features() []features.Feature {
+	return features.Default.All()
+}
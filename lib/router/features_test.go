@@ -0,0 +1,26 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/util/features"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeaturesFromConfig(t *testing.T) {
+	t.Cleanup(features.Default.Reset)
+	r, err := FromConfig(&config.RouterConfig{
+		Workers:  &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Features: map[string]bool{features.SSU2: true, "warp_drive": true},
+	})
+	require.NoError(t, err, "unknown features do not stop the router")
+	t.Cleanup(r.closeWorkers)
+	assert.True(t, features.Enabled(features.SSU2))
+	assert.False(t, features.Enabled(features.LS2_PUBLISH))
+
+	for _, feature := range r.Features() {
+		assert.NotEqual(t, "warp_drive", feature.Name)
+	}
+}
@@ -0,0 +1,119 @@
+package router
+
+import (
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/sirupsen/logrus"
+)
+
+// how often the mainloop feeds the floodfill monitor
+const floodfillCheckInterval = time.Minute
+
+// initFloodfill creates the floodfill monitor for the configured mode
+func (r *Router) initFloodfill() error {
+	mode := config.DefaultNetDbConfig.Floodfill
+	if r.cfg != nil && r.cfg.NetDb != nil {
+		mode = r.cfg.NetDb.Floodfill
+	}
+	mode, err := netdb.ParseFloodfillMode(mode)
+	if err != nil {
+		return err
+	}
+	r.floodfill = netdb.NewFloodfillMonitor(mode, netdb.DefaultFloodfillPolicy())
+	return nil
+}
+
+// Floodfill reports whether the router serves as a floodfill and should
+// publish the f capability.
+func (r *Router) Floodfill() bool {
+	return r.floodfill.Floodfill()
+}
+
+// UpdateFloodfill feeds the state of the router to the floodfill monitor and
+// returns whether the router now serves as a floodfill, and whether that
+// changed so the RouterInfo needs republishing. Uptime and KnownFloodfills
+// are filled in from the router when left at 0.
+func (r *Router) UpdateFloodfill(status netdb.FloodfillStatus) (floodfill, changed bool) {
+	now := time.Now()
+	if status.Uptime == 0 && !r.started.IsZero() {
+		status.Uptime = now.Sub(r.started)
+	}
+	if status.KnownFloodfills == 0 {
+		status.KnownFloodfills = r.knownFloodfills()
+	}
+	return r.floodfill.Update(status, now)
+}
+
+// SetRouterInfo sets the RouterInfo the router publishes. Its caps get the
+// f capability while the router serves as a floodfill, and the floodfill
+// monitor decides from the rest of them. It has to be called before Start.
+func (r *Router) SetRouterInfo(owned *router_info.OwnedRouterInfo) {
+	r.routerInfo = owned
+}
+
+// floodfillStatus returns what the floodfill monitor decides from: the caps
+// we publish, without f. Uptime and the known floodfills are filled in by
+// UpdateFloodfill.
+func (r *Router) floodfillStatus() netdb.FloodfillStatus {
+	var status netdb.FloodfillStatus
+	if r.routerInfo != nil {
+		status.Capabilities = r.routerInfo.RouterInfo().Capabilities()
+		status.Capabilities.Floodfill = false
+	}
+	return status
+}
+
+// checkFloodfill feeds the router state to the floodfill monitor and
+// republishes the RouterInfo if its f capability no longer matches
+func (r *Router) checkFloodfill() {
+	floodfill, changed := r.UpdateFloodfill(r.floodfillStatus())
+	if changed {
+		log.WithField("floodfill", floodfill).Info("Floodfill duty changed")
+	}
+	if err := r.publishFloodfillCap(floodfill); err != nil {
+		r.countError("netdb")
+		log.WithError(err).Error("Failed to republish RouterInfo with new floodfill capability")
+	}
+}
+
+// publishFloodfillCap sets or clears f in the caps of our RouterInfo, which
+// re-signs and republishes it if that changed
+func (r *Router) publishFloodfillCap(floodfill bool) error {
+	if r.routerInfo == nil {
+		return nil
+	}
+	caps := r.routerInfo.RouterInfo().Capabilities()
+	if caps.Floodfill == floodfill {
+		return nil
+	}
+	caps.Floodfill = floodfill
+	log.WithFields(logrus.Fields{
+		"caps":      caps.String(),
+		"floodfill": floodfill,
+	}).Debug("Republishing RouterInfo caps")
+	return r.routerInfo.SetOption("caps", caps.String())
+}
+
+// floodfillCheckDue runs checkFloodfill if floodfillCheckInterval passed
+// since last, and returns the time of the latest check
+func (r *Router) floodfillCheckDue(last time.Time) time.Time {
+	now := time.Now()
+	if now.Sub(last) < floodfillCheckInterval {
+		return last
+	}
+	r.checkFloodfill()
+	return now
+}
+
+// knownFloodfills counts the floodfills in the netdb
+func (r *Router) knownFloodfills() (count int) {
+	for _, info := range r.ndb.KnownRouterInfos() {
+		if info.Capabilities().Floodfill {
+			count++
+		}
+	}
+	return
+}
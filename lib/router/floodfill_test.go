@@ -0,0 +1,92 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFloodfillMode(t *testing.T) {
+	workers := &config.WorkerConfig{NetDb: 1, TunnelBuild: 1}
+	r, err := FromConfig(&config.RouterConfig{
+		NetDb:   &config.NetDbConfig{Floodfill: netdb.FLOODFILL_ON},
+		Workers: workers,
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	assert.True(t, r.Floodfill())
+	floodfill, changed := r.UpdateFloodfill(netdb.FloodfillStatus{})
+	assert.True(t, floodfill)
+	assert.False(t, changed)
+
+	// a fresh router in auto mode waits for uptime
+	auto := newStatsTestRouter(t)
+	assert.Equal(t, netdb.FLOODFILL_AUTO, auto.floodfill.Mode())
+	floodfill, _ = auto.UpdateFloodfill(netdb.FloodfillStatus{})
+	assert.False(t, floodfill)
+
+	_, err = FromConfig(&config.RouterConfig{
+		NetDb:   &config.NetDbConfig{Floodfill: "sometimes"},
+		Workers: workers,
+	})
+	assert.ErrorIs(t, err, netdb.ErrInvalidFloodfillMode)
+}
+
+func newOwnedRouterInfo(t *testing.T, caps string) *router_info.OwnedRouterInfo {
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	owned, err := router_info.NewOwnedRouterInfo(identity, keys.SigningPrivateKey, nil, map[string]string{"caps": caps})
+	require.NoError(t, err)
+	return owned
+}
+
+func TestFloodfillRepublishesCaps(t *testing.T) {
+	r := newStatsTestRouter(t)
+	owned := newOwnedRouterInfo(t, "OR")
+	r.SetRouterInfo(owned)
+	updates := owned.Subscribe()
+
+	// not up long enough yet
+	r.started = time.Now()
+	r.checkFloodfill()
+	assert.False(t, r.Floodfill())
+	assert.Equal(t, "OR", owned.RouterInfo().RouterCapabilities())
+	assert.Empty(t, updates)
+
+	r.started = time.Now().Add(-netdb.DEFAULT_FLOODFILL_MIN_UPTIME - time.Minute)
+	r.checkFloodfill()
+	assert.True(t, r.Floodfill())
+	published := <-updates
+	assert.Equal(t, "OfR", published.RouterCapabilities())
+	assert.NoError(t, published.Verify())
+
+	// congestion steps down at once and drops f again
+	require.NoError(t, owned.SetOption("caps", "OfRG"))
+	<-updates
+	r.checkFloodfill()
+	assert.False(t, r.Floodfill())
+	assert.Equal(t, "ORG", (<-updates).RouterCapabilities())
+}
+
+func TestFloodfillForcedModeIsPublished(t *testing.T) {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		NetDb:      &config.NetDbConfig{Floodfill: netdb.FLOODFILL_ON},
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	owned := newOwnedRouterInfo(t, "LU")
+	r.SetRouterInfo(owned)
+
+	last := r.floodfillCheckDue(time.Time{})
+	assert.Equal(t, "LfU", owned.RouterInfo().RouterCapabilities())
+	assert.Equal(t, last, r.floodfillCheckDue(last), "checks wait for the interval")
+}
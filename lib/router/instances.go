@@ -0,0 +1,87 @@
+package router
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/sirupsen/logrus"
+)
+
+// Instances are several routers run from one binary, each with its own
+// working directory, netDb and transport port
+type Instances []*Router
+
+// CreateInstances creates a router for each instance configured in cfg.
+// With a single instance it creates one router from cfg itself.
+func CreateInstances(cfg *config.RouterConfig) (Instances, error) {
+	configs := cfg.InstanceConfigs()
+	instances := make(Instances, 0, len(configs))
+	for index, instanceConfig := range configs {
+		r, err := FromConfig(instanceConfig)
+		if err != nil {
+			instances.closeWorkers()
+			return nil, fmt.Errorf("router instance %d: %w", index, err)
+		}
+		log.WithFields(logrus.Fields{
+			"instance":    index,
+			"working_dir": instanceConfig.WorkingDir,
+			"port":        r.transportConfig().Port,
+		}).Debug("Created router instance")
+		instances = append(instances, r)
+	}
+	return instances, nil
+}
+
+// Start starts every router
+func (instances Instances) Start() {
+	for _, r := range instances {
+		r.Start()
+	}
+}
+
+// Stop stops every router
+func (instances Instances) Stop() {
+	for _, r := range instances {
+		r.Stop()
+	}
+}
+
+// Wait blocks until every router has stopped
+func (instances Instances) Wait() {
+	var wg sync.WaitGroup
+	for _, r := range instances {
+		wg.Add(1)
+		go func(r *Router) {
+			defer wg.Done()
+			r.Wait()
+		}(r)
+	}
+	wg.Wait()
+}
+
+// Close closes every router, returning the first error
+func (instances Instances) Close() (err error) {
+	for _, r := range instances {
+		if closeErr := r.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
+// ApplyWorkerConfig resizes the worker pools of every router
+func (instances Instances) ApplyWorkerConfig(cfg config.WorkerConfig) (err error) {
+	for _, r := range instances {
+		if applyErr := r.ApplyWorkerConfig(cfg); applyErr != nil && err == nil {
+			err = applyErr
+		}
+	}
+	return
+}
+
+func (instances Instances) closeWorkers() {
+	for _, r := range instances {
+		r.closeWorkers()
+	}
+}
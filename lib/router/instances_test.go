@@ -0,0 +1,45 @@
+package router
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateInstancesIsolatesEachRouter(t *testing.T) {
+	dir := t.TempDir()
+	instances, err := CreateInstances(&config.RouterConfig{
+		WorkingDir: dir,
+		NetDb:      &config.NetDbConfig{Path: filepath.Join(dir, "netDb")},
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Transport:  &config.TransportConfig{Port: 12000},
+		Instances:  &config.InstanceConfig{Count: 3, SharedNetDb: true},
+	})
+	require.NoError(t, err)
+	t.Cleanup(instances.closeWorkers)
+	require.Len(t, instances, 3)
+
+	for index, r := range instances {
+		working := filepath.Join(dir, config.INSTANCES_DIR, strconv.Itoa(index))
+		assert.Equal(t, working, r.cfg.WorkingDir)
+		assert.Equal(t, filepath.Join(working, "netDb"), r.cfg.NetDb.Path)
+		assert.Equal(t, filepath.Join(dir, "netDb"), r.cfg.NetDb.SharedPath)
+		assert.Equal(t, 12000+index, r.transportConfig().Port)
+	}
+}
+
+func TestCreateInstancesSingleRouter(t *testing.T) {
+	cfg := &config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+	}
+	instances, err := CreateInstances(cfg)
+	require.NoError(t, err)
+	t.Cleanup(instances.closeWorkers)
+	require.Len(t, instances, 1)
+	assert.Same(t, cfg, instances[0].cfg)
+}
@@ -0,0 +1,21 @@
+package router
+
+import (
+	"path/filepath"
+
+	"github.com/go-i2p/go-i2p/lib/util/keychain"
+)
+
+// keyPassphrase returns the passphrase the private key file name is sealed
+// with, from the OS keychain, or nil if keys are stored unsealed.
+func (r *Router) keyPassphrase(name string) ([]byte, error) {
+	if r.cfg == nil || r.cfg.Keychain == nil || !r.cfg.Keychain.Enabled {
+		return nil, nil
+	}
+	service := r.cfg.Keychain.Service
+	if service == "" {
+		service = keychain.DEFAULT_SERVICE
+	}
+	// passphrases are per working directory, so instances don't share them
+	return keychain.Passphrase(r.keychain, service, filepath.Join(r.cfg.WorkingDir, name))
+}
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/research"
+	"github.com/go-i2p/go-i2p/lib/util/keychain"
+)
+
+func TestKeyPassphraseDisabled(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.keychain = keychain.NewMemory()
+	passphrase, err := r.keyPassphrase(research.KEY_FILE_NAME)
+	require.NoError(t, err)
+	assert.Nil(t, passphrase)
+}
+
+func TestKeyPassphraseFromKeychain(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.cfg.Keychain = &config.KeychainConfig{Enabled: true}
+	memory := keychain.NewMemory()
+	r.keychain = memory
+
+	passphrase, err := r.keyPassphrase(research.KEY_FILE_NAME)
+	require.NoError(t, err)
+	assert.NotEmpty(t, passphrase)
+	again, err := r.keyPassphrase(research.KEY_FILE_NAME)
+	require.NoError(t, err)
+	assert.Equal(t, passphrase, again)
+	other, err := r.keyPassphrase("other.key")
+	require.NoError(t, err)
+	assert.NotEqual(t, passphrase, other)
+}
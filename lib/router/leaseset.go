@@ -0,0 +1,74 @@
+package router
+
+import (
+	"context"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/util/features"
+)
+
+// stats reporting the outcome of our LeaseSet publications
+const (
+	STAT_LEASESETS_PUBLISHED      = "router.leasesets.published"
+	STAT_LEASESETS_PUBLISH_FAILED = "router.leasesets.publishFailed"
+)
+
+// publishOptions returns the configured LeaseSet publication options, and
+// publishes LeaseSet2 if the ls2_publish feature is enabled
+func (r *Router) publishOptions() netdb.PublishOptions {
+	cfg := config.DefaultNetDbConfig
+	if r.cfg != nil && r.cfg.NetDb != nil {
+		cfg = *r.cfg.NetDb
+	}
+	return netdb.PublishOptions{
+		Floodfills: cfg.PublishFloodfills,
+		Quorum:     cfg.PublishQuorum,
+		LeaseSet2:  features.Enabled(features.LS2_PUBLISH),
+	}
+}
+
+// initLeaseSetPublishing checks the publication options and registers the
+// publication stats
+func (r *Router) initLeaseSetPublishing() error {
+	if _, err := netdb.NewLeaseSetPublisher(nil, nil, r.publishOptions()); err != nil {
+		return err
+	}
+	r.RegisterStat(STAT_LEASESETS_PUBLISHED, func() interface{} {
+		return r.leaseSetsPublished.Load()
+	})
+	r.RegisterStat(STAT_LEASESETS_PUBLISH_FAILED, func() interface{} {
+		return r.leaseSetPublishFailures.Load()
+	})
+	return nil
+}
+
+// NewLeaseSetPublisher returns a publisher storing LeaseSets with store and
+// verifying them with verify, to the configured number of floodfills and
+// with the configured quorum. The stores run on the netdb workers and their
+// outcomes are counted in the router stats.
+func (r *Router) NewLeaseSetPublisher(store netdb.StoreFunc, verify netdb.VerifyFunc) (*netdb.LeaseSetPublisher, error) {
+	publisher, err := netdb.NewLeaseSetPublisher(store, verify, r.publishOptions())
+	if err != nil {
+		return nil, err
+	}
+	publisher.SetSpawn(r.spawner(WORKERS_NETDB))
+	publisher.OnPublish(func(event netdb.PublishEvent) {
+		if event.Success {
+			r.leaseSetsPublished.Add(1)
+		} else {
+			r.leaseSetPublishFailures.Add(1)
+			r.countError("netdb")
+		}
+	})
+	return publisher, nil
+}
+
+// PublishLeaseSet publishes the LeaseSet with key through publisher to the
+// floodfills of the netdb closest to it. Before the netdb is loaded there are
+// none to publish to.
+func (r *Router) PublishLeaseSet(ctx context.Context, publisher *netdb.LeaseSetPublisher, key common.Hash) (netdb.PublishEvent, error) {
+	return publisher.Publish(ctx, key, r.ndb.KnownRouterInfos(), time.Now())
+}
@@ -0,0 +1,57 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/util/features"
+)
+
+func TestRouterLeaseSetPublisherOptions(t *testing.T) {
+	r := newStatsTestRouter(t)
+	publisher, err := r.NewLeaseSetPublisher(func(ctx context.Context, floodfill common.Hash, storeType byte) error { return nil }, nil)
+	require.NoError(t, err)
+	assert.Equal(t, config.DefaultNetDbConfig.PublishFloodfills, publisher.Options().Floodfills)
+	assert.Equal(t, config.DefaultNetDbConfig.PublishQuorum, publisher.Options().Quorum)
+}
+
+func TestRouterPublishesLeaseSet2WithFeature(t *testing.T) {
+	t.Cleanup(features.Default.Reset)
+	r := newStatsTestRouter(t)
+	assert.False(t, r.publishOptions().LeaseSet2)
+	require.NoError(t, features.Default.Set(features.LS2_PUBLISH, true))
+	assert.True(t, r.publishOptions().LeaseSet2)
+}
+
+func TestRouterPublishLeaseSetCountsFailures(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.ndb = netdb.NewStdNetDB(t.TempDir())
+	publisher, err := r.NewLeaseSetPublisher(func(ctx context.Context, floodfill common.Hash, storeType byte) error { return nil }, nil)
+	require.NoError(t, err)
+
+	event, err := r.PublishLeaseSet(context.Background(), publisher, common.Hash{1})
+	assert.ErrorIs(t, err, netdb.ErrPublishQuorum)
+	assert.False(t, event.Success)
+
+	stats := r.Stats(STAT_LEASESETS_PUBLISHED, STAT_LEASESETS_PUBLISH_FAILED)
+	assert.Equal(t, uint64(0), stats[STAT_LEASESETS_PUBLISHED])
+	assert.Equal(t, uint64(1), stats[STAT_LEASESETS_PUBLISH_FAILED])
+}
+
+func TestRouterRejectsInvalidPublishQuorum(t *testing.T) {
+	netDb := config.DefaultNetDbConfig
+	netDb.PublishFloodfills = 2
+	netDb.PublishQuorum = 3
+	_, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		NetDb:      &netDb,
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+	})
+	assert.ErrorIs(t, err, netdb.ErrInvalidPublishQuorum)
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/clientauth"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/util/chaos"
+	"github.com/sirupsen/logrus"
+)
+
+// how long Close waits for management requests in progress
+const managementShutdownTimeout = 5 * time.Second
+
+// managementConfig returns the management API configuration
+func (r *Router) managementConfig() config.ManagementConfig {
+	if r.cfg != nil && r.cfg.Management != nil {
+		return *r.cfg.Management
+	}
+	return config.DefaultManagementConfig
+}
+
+// ManagementHandler returns the management API:
+//
+//	/workers         worker pool sizes, see WorkersHandler
+//	/stats           router stats under the Java router's names, see StatsHandler
+//	/peer            everything known of one peer, see PeerHandler
+//	/support-bundle  the support bundle for download, see SupportBundleHandler
+//	/debug/chaos     fault injection, see chaos.Handler, only if DebugChaos is
+//	                 set and clients have to log in
+func (r *Router) ManagementHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/workers", r.WorkersHandler())
+	mux.Handle("/stats", r.StatsHandler())
+	mux.Handle("/peer", r.PeerHandler())
+	mux.Handle("/support-bundle", r.SupportBundleHandler())
+	if r.managementConfig().DebugChaos {
+		if r.cfg != nil && r.cfg.Clients != nil && r.cfg.Clients.AuthRequired {
+			log.Warn("Serving the chaos debug API, faults can be injected into the transports")
+			mux.Handle("/debug/chaos", chaos.Handler())
+		} else {
+			log.Warn("Not serving the chaos debug API, it requires client authentication")
+		}
+	}
+	return mux
+}
+
+// WorkersHandler serves the size of each worker pool as a JSON object. A
+// POST with "pool" and "size" form values resizes that pool while the
+// router runs and answers the new sizes.
+func (r *Router) WorkersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			size, err := strconv.Atoi(req.FormValue("size"))
+			if err != nil {
+				http.Error(w, "invalid size", http.StatusBadRequest)
+				return
+			}
+			pool := req.FormValue("pool")
+			if err := r.SetWorkerSize(pool, size); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.WithFields(logrus.Fields{
+				"pool":   pool,
+				"size":   size,
+				"remote": req.RemoteAddr,
+			}).Info("Resized worker pool from the management API")
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.WorkerSizes()); err != nil {
+			log.WithError(err).Error("Failed to write worker sizes")
+		}
+	})
+}
+
+// sameOriginOnly refuses requests which web pages the operator visits could
+// have sent: requests naming another host than address or localhost, as DNS
+// rebinding does, and requests changing state from another origin. Without
+// a login required for the management API, these would otherwise reach it
+// from any browser on the machine.
+func sameOriginOnly(address string, next http.Handler) http.Handler {
+	bindHost, _, err := net.SplitHostPort(address)
+	if err != nil {
+		bindHost = address
+	}
+	bindIP := net.ParseIP(bindHost)
+	allowedHost := func(host string) bool {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if strings.EqualFold(host, "localhost") || strings.EqualFold(host, bindHost) {
+			return true
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return false
+		}
+		// a wildcard bind answers on every address of the machine, and DNS
+		// rebinding always sends a name
+		return ip.IsLoopback() || bindIP != nil && (bindIP.IsUnspecified() || bindIP.Equal(ip))
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !allowedHost(req.Host) {
+			log.WithFields(logrus.Fields{
+				"host":   req.Host,
+				"remote": req.RemoteAddr,
+			}).Warn("Refused management request for another host")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		if req.Method != http.MethodGet && req.Method != http.MethodHead && crossOrigin(req) {
+			log.WithFields(logrus.Fields{
+				"origin": req.Header.Get("Origin"),
+				"remote": req.RemoteAddr,
+			}).Warn("Refused cross-origin management request")
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// crossOrigin reports whether a browser sent req from another origin
+func crossOrigin(req *http.Request) bool {
+	switch req.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	parsed, err := url.Parse(origin)
+	return err != nil || !strings.EqualFold(parsed.Host, req.Host)
+}
+
+// startManagement serves the management API on the configured address, if
+// enabled. The address has to pass the client bind checks, and requests log
+// in as a client user allowed to use the management API. Requests from web
+// pages are refused, see sameOriginOnly.
+func (r *Router) startManagement() error {
+	cfg := r.managementConfig()
+	if !cfg.Enabled || r.management != nil {
+		return nil
+	}
+	auth, err := r.clientAuthenticator()
+	if err != nil {
+		return err
+	}
+	listener, err := auth.Listen(cfg.Address)
+	if err != nil {
+		return err
+	}
+	r.management = &http.Server{
+		Handler:           sameOriginOnly(cfg.Address, auth.RequireHTTP(clientauth.PROTOCOL_MANAGEMENT, r.ManagementHandler())),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	r.managementAddr = listener.Addr()
+	log.WithField("address", r.managementAddr).Info("Serving management API")
+	go func(server *http.Server) {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.countError("management")
+			log.WithError(err).Error("Management API stopped")
+		}
+	}(r.management)
+	return nil
+}
+
+// stopManagement stops the server started by startManagement
+func (r *Router) stopManagement() {
+	if r.management == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), managementShutdownTimeout)
+	defer cancel()
+	if err := r.management.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("Failed to stop management API cleanly")
+	}
+	r.management = nil
+}
+
+// ManagementAddr returns the address the management API listens on, or nil
+// if it is not served
+func (r *Router) ManagementAddr() net.Addr {
+	return r.managementAddr
+}
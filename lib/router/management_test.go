@@ -0,0 +1,279 @@
+package router
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/clientauth"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/util/chaos"
+	"github.com/go-i2p/go-i2p/lib/util/tlsconfig"
+	"github.com/go-i2p/go-i2p/lib/util/workers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkersHandler(t *testing.T) {
+	r := newStatsTestRouter(t)
+	handler := r.ManagementHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/workers", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var sizes map[string]int
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &sizes))
+	assert.Equal(t, 1, sizes[WORKERS_NETDB])
+
+	form := url.Values{"pool": {WORKERS_NETDB}, "size": {"3"}}
+	req := httptest.NewRequest("POST", "/workers", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &sizes))
+	assert.Equal(t, 3, sizes[WORKERS_NETDB])
+	assert.Equal(t, 3, r.WorkerPool(WORKERS_NETDB).Size())
+
+	for _, form := range []url.Values{
+		{"pool": {"nope"}, "size": {"1"}},
+		{"pool": {WORKERS_NETDB}, "size": {"0"}},
+		{"pool": {WORKERS_NETDB}, "size": {"many"}},
+	} {
+		req := httptest.NewRequest("POST", "/workers", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, form.Encode())
+	}
+}
+
+func TestManagementHandlerMountsReports(t *testing.T) {
+	r := newStatsTestRouter(t)
+	handler := r.ManagementHandler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/stats?stat="+STAT_STATUS, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var stats map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stats))
+	assert.Equal(t, "Stopped", stats[STAT_STATUS])
+
+	var hash common.Hash
+	hash[0] = 5
+	r.PeerProfiles().RecordSuccess(hash)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/peer?hash="+base64.EncodeToString(hash[:]), nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var peer map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &peer))
+	assert.Contains(t, peer, "profile")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/support-bundle", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/gzip", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, readBundle(t, recorder.Body.Bytes()), SUPPORT_BUNDLE_CONFIG)
+}
+
+func TestManagementHandlerDebugChaos(t *testing.T) {
+	r := newStatsTestRouter(t)
+	recorder := httptest.NewRecorder()
+	r.ManagementHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/chaos", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "not mounted by default")
+
+	newRouter := func(clients *config.ClientConfig) *Router {
+		r, err := FromConfig(&config.RouterConfig{
+			WorkingDir: t.TempDir(),
+			Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+			Clients:    clients,
+			Management: &config.ManagementConfig{DebugChaos: true},
+		})
+		require.NoError(t, err)
+		t.Cleanup(r.closeWorkers)
+		return r
+	}
+	r = newRouter(nil)
+	recorder = httptest.NewRecorder()
+	r.ManagementHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/chaos", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code, "not mounted without a login required")
+
+	r = newRouter(&config.ClientConfig{AuthRequired: true})
+	t.Cleanup(chaos.Reset)
+	req := httptest.NewRequest("POST", "/debug/chaos", strings.NewReader(`{"transport.send": {"drop": 0.5}}`))
+	recorder = httptest.NewRecorder()
+	r.ManagementHandler().ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 0.5, chaos.Current()[chaos.TRANSPORT_SEND].Drop)
+}
+
+func TestSameOriginOnly(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	for _, test := range []struct {
+		bind, host, method string
+		headers            map[string]string
+		code               int
+	}{
+		{"127.0.0.1:7651", "127.0.0.1:7651", "GET", nil, http.StatusOK},
+		{"127.0.0.1:7651", "localhost:7651", "POST", nil, http.StatusOK},
+		{"127.0.0.1:7651", "[::1]:7651", "GET", nil, http.StatusOK},
+		{"127.0.0.1:7651", "evil.example:7651", "GET", nil, http.StatusForbidden},
+		{"192.0.2.1:7651", "192.0.2.1:7651", "GET", nil, http.StatusOK},
+		{"192.0.2.1:7651", "192.0.2.2:7651", "GET", nil, http.StatusForbidden},
+		{"0.0.0.0:7651", "192.0.2.2:7651", "GET", nil, http.StatusOK},
+		{"0.0.0.0:7651", "evil.example:7651", "GET", nil, http.StatusForbidden},
+		{"127.0.0.1:7651", "127.0.0.1:7651", "POST", map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+		{"127.0.0.1:7651", "127.0.0.1:7651", "POST", map[string]string{"Origin": "null"}, http.StatusForbidden},
+		{"127.0.0.1:7651", "127.0.0.1:7651", "POST", map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"127.0.0.1:7651", "127.0.0.1:7651", "GET", map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusOK},
+		{"127.0.0.1:7651", "127.0.0.1:7651", "POST", map[string]string{
+			"Origin":         "http://127.0.0.1:7651",
+			"Sec-Fetch-Site": "same-origin",
+		}, http.StatusOK},
+	} {
+		req := httptest.NewRequest(test.method, "/workers", nil)
+		req.Host = test.host
+		for name, value := range test.headers {
+			req.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		sameOriginOnly(test.bind, ok).ServeHTTP(recorder, req)
+		assert.Equal(t, test.code, recorder.Code, "%s %s on %s %v", test.method, test.host, test.bind, test.headers)
+	}
+}
+
+func TestManagementServer(t *testing.T) {
+	hash, err := clientauth.HashPassword("hunter2")
+	require.NoError(t, err)
+	clients := &config.ClientConfig{
+		AuthRequired: true,
+		Users: []*config.ClientUser{
+			{Name: "admin", PasswordHash: hash, Management: true},
+			{Name: "client", PasswordHash: hash, SAM: true},
+		},
+	}
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Clients:    clients,
+		Management: &config.ManagementConfig{Enabled: true, Address: "127.0.0.1:0"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	require.NoError(t, r.startManagement())
+	t.Cleanup(r.stopManagement)
+	require.NotNil(t, r.ManagementAddr())
+
+	get := func(path, username string) int {
+		req, err := http.NewRequest("GET", "http://"+r.ManagementAddr().String()+path, nil)
+		require.NoError(t, err)
+		if username != "" {
+			req.SetBasicAuth(username, "hunter2")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	assert.Equal(t, http.StatusOK, get("/workers", "admin"))
+	assert.Equal(t, http.StatusForbidden, get("/workers", "client"))
+	assert.Equal(t, http.StatusUnauthorized, get("/workers", ""))
+	assert.Equal(t, http.StatusOK, get("/support-bundle", "admin"))
+	assert.Equal(t, http.StatusUnauthorized, get("/support-bundle", ""), "the support bundle needs a login like the rest")
+
+	lan, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Management: &config.ManagementConfig{Enabled: true, Address: "192.0.2.1:7651"},
+	})
+	require.NoError(t, err)
+	t.Cleanup(lan.closeWorkers)
+	assert.ErrorIs(t, lan.startManagement(), clientauth.ErrBindNotAllowed)
+}
+
+func TestRunOnWorkerPool(t *testing.T) {
+	r := newStatsTestRouter(t)
+	ran := false
+	require.NoError(t, r.runOn(WORKERS_NETDB, func() { ran = true }))
+	assert.True(t, ran)
+
+	// resizing while other goroutines read the sizes and submit work
+	var wg sync.WaitGroup
+	for i := 1; i <= 4; i++ {
+		size := i
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, r.SetWorkerSize(WORKERS_NETDB, size))
+		}()
+		go func() {
+			defer wg.Done()
+			r.WorkerSizes()
+			assert.NoError(t, r.runOn(WORKERS_NETDB, func() {}))
+		}()
+	}
+	wg.Wait()
+
+	r.closeWorkers()
+	ran = false
+	require.NoError(t, r.runOn(WORKERS_NETDB, func() { ran = true }), "without the pool the task runs inline")
+	assert.True(t, ran)
+}
+
+func TestRunOnClosedPool(t *testing.T) {
+	r := newStatsTestRouter(t)
+	pool := r.WorkerPool(WORKERS_NETDB)
+	require.NoError(t, pool.Close())
+	assert.ErrorIs(t, r.runOn(WORKERS_NETDB, func() {}), workers.ErrPoolClosed)
+}
+
+func TestManagementServerTLS(t *testing.T) {
+	workingDir, stateDir := t.TempDir(), t.TempDir()
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: workingDir,
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Clients:    &config.ClientConfig{TLS: true},
+		Management: &config.ManagementConfig{Enabled: true, Address: "127.0.0.1:0"},
+		Storage:    &config.StorageConfig{ReadOnly: true, StateDir: stateDir},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	require.NoError(t, r.startManagement())
+	t.Cleanup(r.stopManagement)
+
+	// the self-signed certificate is kept with the private keys, in the
+	// state directory of a read only router
+	keyFile := filepath.Join(stateDir, tlsconfig.SELF_SIGNED_KEY_FILE)
+	info, err := os.Stat(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.Contains(t, r.keyFiles(), keyFile)
+	assert.NoFileExists(t, filepath.Join(workingDir, tlsconfig.SELF_SIGNED_KEY_FILE))
+	assert.NoFileExists(t, filepath.Join(workingDir, tlsconfig.SELF_SIGNED_CERT_FILE))
+
+	pem, err := os.ReadFile(filepath.Join(stateDir, tlsconfig.SELF_SIGNED_CERT_FILE))
+	require.NoError(t, err)
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM(pem))
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	resp, err := client.Get("https://" + r.ManagementAddr().String() + "/workers")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// plain HTTP is not served, the TLS listener answers it with 400
+	resp, err = http.Get("http://" + r.ManagementAddr().String() + "/workers")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/garlic"
+	"github.com/go-i2p/go-i2p/lib/util/memory"
+)
+
+// names of the caches the router accounts
+const (
+	MEMORY_NETDB        = "netdb"
+	MEMORY_SESSION_KEYS = "session_keys"
+	MEMORY_QUEUE_PREFIX = "queue."
+)
+
+// prefix of the stats reporting the accounted bytes of each cache
+const STAT_MEMORY_PREFIX = "router.memory."
+
+// memoryConfig returns the memory accounting configuration
+func (r *Router) memoryConfig() config.MemoryConfig {
+	if r.cfg != nil && r.cfg.Memory != nil {
+		return *r.cfg.Memory
+	}
+	return config.DefaultMemoryConfig
+}
+
+// RegisterMemoryCache accounts cache under name, with a ceiling of limit
+// bytes or none if limit is 0, and exposes its size as the stat
+// router.memory.<name>. Subsystems register their caches so they show in
+// Stats and the state report and are evicted from when over their ceiling.
+func (r *Router) RegisterMemoryCache(name string, cache memory.Cache, limit int64) {
+	r.memory.Register(name, cache, limit)
+	r.RegisterStat(STAT_MEMORY_PREFIX+name, func() interface{} {
+		usage, _ := r.memory.CacheUsage(name)
+		return usage.Bytes
+	})
+}
+
+// MemoryUsage returns the accounted memory of every registered cache.
+func (r *Router) MemoryUsage() map[string]memory.Usage {
+	return r.memory.Usage()
+}
+
+// registerWorkerQueues accounts the queued tasks of the worker pools. Tasks
+// are closures of unknown size, so their stat router.memory.queue.<name>
+// reports their number rather than bytes.
+func (r *Router) registerWorkerQueues() {
+	r.workersMutex.RLock()
+	defer r.workersMutex.RUnlock()
+	for name, pool := range r.workers {
+		pool := pool
+		r.memory.Register(MEMORY_QUEUE_PREFIX+name, memory.GaugeFunc(func() (int, int64) {
+			return pool.Queued(), 0
+		}), 0)
+		r.RegisterStat(STAT_MEMORY_PREFIX+MEMORY_QUEUE_PREFIX+name, func() interface{} {
+			return pool.Queued()
+		})
+	}
+}
+
+// SessionKeys returns the keys and tags of the router's ElGamal/AES+SessionTag
+// garlic sessions, accounted as the session_keys cache.
+func (r *Router) SessionKeys() *garlic.SessionKeyManager {
+	return r.sessionKeys
+}
+
+// registerSessionKeyMemory accounts the garlic session keys and tags. They
+// expire on their own and the inbound tags are capped, so there is no
+// ceiling.
+func (r *Router) registerSessionKeyMemory() {
+	r.RegisterMemoryCache(MEMORY_SESSION_KEYS, memory.GaugeFunc(r.sessionKeys.MemoryUsage), 0)
+}
+
+// registerNetDbMemory accounts the in-memory netdb with the configured
+// ceiling
+func (r *Router) registerNetDbMemory() {
+	limit := int64(r.memoryConfig().NetDb) * 1024 * 1024
+	r.RegisterMemoryCache(MEMORY_NETDB, &r.ndb, limit)
+}
+
+// memoryCheckDue drops expired session tags and enforces the cache ceilings
+// if the check interval passed since last, and returns the time of the latest check
+func (r *Router) memoryCheckDue(last time.Time) time.Time {
+	interval := r.memoryConfig().CheckInterval
+	if interval <= 0 {
+		interval = config.DefaultMemoryConfig.CheckInterval
+	}
+	now := time.Now()
+	if now.Sub(last) < interval {
+		return last
+	}
+	r.sessionKeys.Expire(now)
+	r.memory.Enforce()
+	return now
+}
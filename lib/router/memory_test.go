@@ -0,0 +1,28 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/util/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterMemoryAccounting(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.ndb = netdb.NewStdNetDB(t.TempDir())
+	r.registerNetDbMemory()
+
+	usage := r.MemoryUsage()
+	assert.Contains(t, usage, MEMORY_QUEUE_PREFIX+WORKERS_NETDB)
+	assert.Contains(t, usage, MEMORY_SESSION_KEYS)
+	stats := r.Stats()
+	assert.Equal(t, 0, stats[STAT_MEMORY_PREFIX+MEMORY_QUEUE_PREFIX+WORKERS_NETDB], "queues report their length")
+	assert.Equal(t, int64(0), stats[STAT_MEMORY_PREFIX+MEMORY_SESSION_KEYS])
+	assert.Equal(t, int64(32*1024*1024), usage[MEMORY_NETDB].Limit)
+	assert.Equal(t, memory.Usage{Limit: usage[MEMORY_NETDB].Limit}, usage[MEMORY_NETDB])
+
+	r.RegisterMemoryCache("test", memory.GaugeFunc(func() (int, int64) { return 2, 2048 }), 0)
+	assert.Equal(t, int64(2048), r.Stats(STAT_MEMORY_PREFIX + "test")[STAT_MEMORY_PREFIX+"test"])
+	assert.Equal(t, 2, r.Report().Memory["test"].Entries)
+}
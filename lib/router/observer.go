@@ -0,0 +1,32 @@
+package router
+
+import "errors"
+
+// ErrObserverMode is returned for requests an observer router refuses.
+var ErrObserverMode = errors.New("router is in observer mode")
+
+// Observer reports whether the router runs in netdb-only observer mode. An
+// observer reseeds, explores and stores netdb entries like any other router,
+// but it builds no tunnels, takes part in none, and accepts no client
+// sessions. It is meant for research and network health monitoring.
+func (r *Router) Observer() bool {
+	return r.cfg != nil && r.cfg.Observer
+}
+
+// AllowTunnels returns ErrObserverMode if the router must not build or
+// participate in tunnels.
+func (r *Router) AllowTunnels() error {
+	if r.Observer() {
+		return ErrObserverMode
+	}
+	return nil
+}
+
+// AllowClientSessions returns ErrObserverMode if the router must not accept
+// client sessions.
+func (r *Router) AllowClientSessions() error {
+	if r.Observer() {
+		return ErrObserverMode
+	}
+	return nil
+}
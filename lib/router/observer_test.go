@@ -0,0 +1,36 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserverMode(t *testing.T) {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Observer:   true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+
+	assert.True(t, r.Observer())
+	assert.ErrorIs(t, r.AllowTunnels(), ErrObserverMode)
+	assert.ErrorIs(t, r.AllowClientSessions(), ErrObserverMode)
+	assert.Nil(t, r.WorkerPool(WORKERS_TUNNEL_BUILD))
+	assert.NotNil(t, r.WorkerPool(WORKERS_NETDB))
+	_, err = r.ClientAuthenticator()
+	assert.ErrorIs(t, err, ErrObserverMode)
+
+	// reloading the worker configuration resizes the pools observers have
+	require.NoError(t, r.ApplyWorkerConfig(config.WorkerConfig{NetDb: 3, TunnelBuild: 4}))
+	assert.Equal(t, map[string]int{WORKERS_NETDB: 3}, r.WorkerSizes())
+
+	normal := newStatsTestRouter(t)
+	assert.False(t, normal.Observer())
+	assert.NoError(t, normal.AllowTunnels())
+	assert.NotNil(t, normal.WorkerPool(WORKERS_TUNNEL_BUILD))
+}
@@ -0,0 +1,186 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/peer"
+	"github.com/sirupsen/logrus"
+)
+
+// number of errors kept per peer for PeerReport
+const maxPeerErrors = 16
+
+var (
+	ErrInvalidPeerHash = errors.New("invalid router hash")
+	ErrUnknownPeer     = errors.New("nothing is known about this router")
+)
+
+// PeerError is an error recorded while dealing with a peer
+type PeerError struct {
+	Time      time.Time `json:"time"`
+	Subsystem string    `json:"subsystem"`
+	Error     string    `json:"error"`
+}
+
+// PeerProfileReport is the profile we keep of a peer
+type PeerProfileReport struct {
+	Successes uint64    `json:"successes"`
+	Failures  uint64    `json:"failures"`
+	LastSeen  time.Time `json:"last_seen"`
+	// Score is the score used for peer selection, including external scores
+	Score float64 `json:"score"`
+}
+
+// PeerReport is everything the router knows about a single peer
+type PeerReport struct {
+	Hash       string                  `json:"hash"`
+	RouterInfo *router_info.RouterInfo `json:"router_info,omitempty"`
+	Profile    *PeerProfileReport      `json:"profile,omitempty"`
+	// Sessions lists the transport styles with an open session to the peer,
+	// as recorded by the transports in the connection history
+	Sessions []string `json:"sessions"`
+	// Tunnels lists the IDs of the warm tunnels the peer is a hop in
+	Tunnels []uint32    `json:"tunnels"`
+	Errors  []PeerError `json:"errors"`
+	// History lists the recent connects, disconnects and bans of the peer
+	History []peer.PeerEvent `json:"history"`
+}
+
+// RecordPeerError remembers err as one of the recent errors of the peer and
+// counts it against subsystem. Only the last few errors of each peer are kept.
+func (r *Router) RecordPeerError(hash common.Hash, subsystem string, err error) {
+	r.countError(subsystem)
+	r.errorsMutex.Lock()
+	defer r.errorsMutex.Unlock()
+	if r.peerErrors == nil {
+		r.peerErrors = make(map[common.Hash][]PeerError)
+	}
+	errs := append(r.peerErrors[hash], PeerError{
+		Time:      time.Now(),
+		Subsystem: subsystem,
+		Error:     err.Error(),
+	})
+	if len(errs) > maxPeerErrors {
+		errs = errs[len(errs)-maxPeerErrors:]
+	}
+	r.peerErrors[hash] = errs
+}
+
+// PeerReport collects what the router knows about the peer with hash. It
+// returns ErrUnknownPeer if there is no RouterInfo, profile, error,
+// connection history or warm tunnel for it.
+func (r *Router) PeerReport(hash common.Hash) (PeerReport, error) {
+	known := false
+	report := PeerReport{
+		Hash:     hash.Base64(),
+		Sessions: r.history.Open(hash),
+		Tunnels:  []uint32{},
+		Errors:   []PeerError{},
+		History:  []peer.PeerEvent{},
+	}
+	if r.warmPool != nil {
+		report.Tunnels = r.warmPool.Through(hash)
+		known = len(report.Tunnels) > 0
+	}
+	if ri := r.lookupRouterInfo(hash); ri != nil {
+		report.RouterInfo = ri
+		known = true
+	}
+	if r.profiles != nil {
+		if profile, ok := r.profiles.Profile(hash); ok {
+			report.Profile = &PeerProfileReport{
+				Successes: profile.Successes,
+				Failures:  profile.Failures,
+				LastSeen:  profile.LastSeen,
+				Score:     r.profiles.Score(hash),
+			}
+			known = true
+		}
+	}
+	r.errorsMutex.Lock()
+	if errs, ok := r.peerErrors[hash]; ok {
+		report.Errors = append(report.Errors, errs...)
+		known = true
+	}
+	r.errorsMutex.Unlock()
+	if events, ok := r.history.Events(hash); ok {
+		report.History = events
+		known = true
+	}
+	if !known {
+		return report, ErrUnknownPeer
+	}
+	return report, nil
+}
+
+// lookupRouterInfo returns the RouterInfo of hash from the netDb cache or
+// its skiplist file, or nil
+func (r *Router) lookupRouterInfo(hash common.Hash) *router_info.RouterInfo {
+	if info := r.ndb.LookupRouterInfo(hash); info != nil {
+		return info
+	}
+	if r.ndb.Path() == "" {
+		return nil
+	}
+	fname := r.ndb.SkiplistFile(hash)
+	if _, err := os.Stat(fname); err != nil {
+		return nil
+	}
+	ri, err := router_info.ReadRouterInfoFromFile(fname)
+	if err != nil {
+		log.WithError(err).WithField("path", fname).Warn("Failed to read RouterInfo for peer report")
+		return nil
+	}
+	return &ri
+}
+
+// ParsePeerHash parses a router hash given in I2P base64, or in base32 with
+// or without the .b32.i2p suffix.
+func ParsePeerHash(s string) (common.Hash, error) {
+	s = strings.TrimSpace(s)
+	hash, err := common.ParseHashBase32(s)
+	if err != nil {
+		hash, err = common.ParseHashBase64(s)
+	}
+	if err != nil {
+		return hash, fmt.Errorf("%w: %q", ErrInvalidPeerHash, s)
+	}
+	return hash, nil
+}
+
+// PeerHandler serves PeerReport as JSON for the router named by the "hash"
+// query parameter.
+func (r *Router) PeerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		hash, err := ParsePeerHash(req.URL.Query().Get("hash"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		report, err := r.PeerReport(hash)
+		if errors.Is(err, ErrUnknownPeer) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.WithFields(logrus.Fields{
+			"hash":   report.Hash,
+			"remote": req.RemoteAddr,
+		}).Debug("Serving peer report")
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			log.WithError(err).Error("Failed to write peer report")
+		}
+	})
+}
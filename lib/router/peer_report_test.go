@@ -0,0 +1,126 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/base32"
+	"github.com/go-i2p/go-i2p/lib/common/base64"
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/peer"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePeerHash(t *testing.T) {
+	var hash common.Hash
+	hash[0], hash[31] = 0xab, 0xcd
+	b32 := base32.EncodeToString(hash[:])[:52]
+
+	for _, s := range []string{base64.EncodeToString(hash[:]), b32, b32 + ".b32.i2p"} {
+		parsed, err := ParsePeerHash(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, hash, parsed, s)
+	}
+	_, err := ParsePeerHash("not a hash")
+	assert.ErrorIs(t, err, ErrInvalidPeerHash)
+}
+
+func TestPeerReport(t *testing.T) {
+	r := newStatsTestRouter(t)
+	var hash common.Hash
+	hash[0] = 1
+
+	_, err := r.PeerReport(hash)
+	assert.ErrorIs(t, err, ErrUnknownPeer)
+
+	r.PeerProfiles().RecordSuccess(hash)
+	for i := 0; i < maxPeerErrors+2; i++ {
+		r.RecordPeerError(hash, "transport", errors.New("handshake failed"))
+	}
+	report, err := r.PeerReport(hash)
+	require.NoError(t, err)
+	require.NotNil(t, report.Profile)
+	assert.Equal(t, uint64(1), report.Profile.Successes)
+	assert.Len(t, report.Errors, maxPeerErrors)
+	assert.Equal(t, "transport", report.Errors[0].Subsystem)
+	assert.Nil(t, report.RouterInfo)
+	assert.Equal(t, maxPeerErrors+2, r.Report().Errors["transport"])
+
+	recorder := httptest.NewRecorder()
+	r.PeerHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/peer?hash="+report.Hash, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	assert.Equal(t, report.Hash, decoded["hash"])
+	assert.Contains(t, decoded, "profile")
+	assert.Contains(t, decoded, "sessions")
+
+	recorder = httptest.NewRecorder()
+	r.PeerHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/peer?hash=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	hash[0] = 2
+	recorder = httptest.NewRecorder()
+	r.PeerHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/peer?hash="+base64.EncodeToString(hash[:]), nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestPeerReportHistory(t *testing.T) {
+	r := newStatsTestRouter(t)
+	var hash common.Hash
+	hash[0] = 2
+	start := time.Now()
+	r.PeerHistory().Connected(hash, "NTCP2", start)
+	r.PeerHistory().Disconnected(hash, "NTCP2", "reset", start.Add(time.Second))
+
+	report, err := r.PeerReport(hash)
+	require.NoError(t, err)
+	require.Len(t, report.History, 2)
+	assert.Equal(t, peer.EVENT_DISCONNECT, report.History[1].Type)
+	assert.Equal(t, time.Second, report.History[1].Duration)
+
+	recorder := httptest.NewRecorder()
+	r.PeerHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/peer?hash="+report.Hash, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var decoded struct {
+		History []map[string]string `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	require.Len(t, decoded.History, 2)
+	assert.Equal(t, "1s", decoded.History[1]["duration"])
+}
+
+func TestPeerReportSessionsAndTunnels(t *testing.T) {
+	r := newStatsTestRouter(t)
+	warmTunnel := func(id uint32, hop common.Hash) {
+		r.warmPool = tunnel.NewWarmPool(1, func() (*tunnel.BuiltTunnel, error) {
+			return &tunnel.BuiltTunnel{ID: id, Hops: []common.Hash{hop}, Expiration: time.Now().Add(tunnel.TUNNEL_LIFETIME)}, nil
+		})
+		r.warmPool.Start()
+		t.Cleanup(r.warmPool.Stop)
+		require.Eventually(t, func() bool { return r.warmPool.Ready() == 1 }, time.Second, time.Millisecond)
+	}
+	var hash common.Hash
+	hash[0] = 3
+	r.PeerHistory().Connected(hash, "SSU2", time.Now())
+	r.PeerHistory().Connected(hash, "NTCP2", time.Now())
+	warmTunnel(42, hash)
+
+	report, err := r.PeerReport(hash)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NTCP2", "SSU2"}, report.Sessions)
+	assert.Equal(t, []uint32{42}, report.Tunnels)
+
+	hash[0] = 4
+	warmTunnel(43, hash)
+	report, err = r.PeerReport(hash)
+	require.NoError(t, err, "a peer only known as a hop of a warm tunnel is known")
+	assert.Equal(t, []uint32{43}, report.Tunnels)
+	assert.Empty(t, report.Sessions)
+}
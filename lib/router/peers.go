@@ -0,0 +1,85 @@
+package router
+
+import (
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/peer"
+)
+
+// PeerProfiles returns the profiles the router ranks peers by.
+func (r *Router) PeerProfiles() *peer.Profiles {
+	return r.profiles
+}
+
+// PeerHistory returns the connection history of peers. Transports record
+// the sessions they open and close in it, and bans are recorded where they
+// are decided.
+func (r *Router) PeerHistory() *peer.History {
+	return r.history
+}
+
+// SetPeerScoreSource lets an embedding application feed its own peer scores
+// into peer selection. They are blended with the local profiles using the
+// configured peers.external_score_weight. Pass nil to stop using them.
+func (r *Router) SetPeerScoreSource(source peer.ScoreSource) {
+	weight := config.DefaultPeerConfig.ExternalScoreWeight
+	if r.cfg != nil && r.cfg.Peers != nil {
+		weight = r.cfg.Peers.ExternalScoreWeight
+	}
+	r.profiles.SetScoreSource(source, weight)
+}
+
+// SetProfileStore sets where peer profiles are kept between runs. They are
+// loaded on Start and saved on Close. It has to be called before Start,
+// without a store profiles are not kept.
+func (r *Router) SetProfileStore(store peer.ProfileStore) {
+	r.profileStore = store
+}
+
+// loadProfiles loads the profiles kept from earlier runs
+func (r *Router) loadProfiles() {
+	if r.profileStore == nil {
+		return
+	}
+	if err := r.profiles.Load(r.profileStore); err != nil {
+		r.countError("peers")
+	}
+}
+
+// saveProfiles keeps the profiles for the next run
+func (r *Router) saveProfiles() {
+	if r.profileStore == nil {
+		return
+	}
+	if err := r.profiles.Save(r.profileStore); err != nil {
+		r.countError("peers")
+	}
+}
+
+// initVersionPolicy checks the configured router version policy and applies
+// it to the netdb and peer selection
+func (r *Router) initVersionPolicy() error {
+	cfg := config.DefaultVersionConfig
+	if r.cfg != nil && r.cfg.Versions != nil {
+		cfg = *r.cfg.Versions
+	}
+	policy := &router_info.VersionPolicy{
+		Min:           cfg.Min,
+		Max:           cfg.Max,
+		Blocked:       cfg.Blocked,
+		ZeroMajorOnly: cfg.ZeroMajorOnly,
+	}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	r.ndb.VersionPolicy = policy
+	r.profiles.SetVersionPolicy(policy)
+	return nil
+}
+
+// SelectPeers returns up to n of the routers in the netdb, best scoring
+// first, leaving out those whose router version is not accepted.
+func (r *Router) SelectPeers(n int) []common.Hash {
+	return r.profiles.SelectRouters(r.ndb.KnownRouterInfos(), n)
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/config"
+)
+
+func TestVersionPolicyFromConfig(t *testing.T) {
+	_, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Versions:   &config.VersionConfig{Min: "latest"},
+	})
+	assert.ErrorIs(t, err, router_info.ErrInvalidVersion)
+
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Versions:   &config.VersionConfig{Min: "0.9.58", Blocked: []string{"0.9.62"}},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+
+	current := newOwnedRouterInfo(t, "LR")
+	require.NoError(t, current.SetOption("router.version", "0.9.64"))
+	blocked := newOwnedRouterInfo(t, "LR")
+	require.NoError(t, blocked.SetOption("router.version", "0.9.62"))
+	assert.True(t, r.ndb.StoreRouterInfo(current.RouterInfo(), false))
+	assert.False(t, r.ndb.StoreRouterInfo(blocked.RouterInfo(), false), "the netdb does not store blocked versions")
+
+	// peer selection applies the policy to RouterInfos from elsewhere too
+	assert.Equal(t, []common.Hash{current.RouterInfo().IdentHash()}, r.SelectPeers(-1))
+	assert.Empty(t, r.profiles.SelectRouters([]*router_info.RouterInfo{blocked.RouterInfo()}, -1))
+}
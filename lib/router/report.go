@@ -0,0 +1,165 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/go-i2p/go-i2p/lib/util/features"
+	"github.com/go-i2p/go-i2p/lib/util/memory"
+)
+
+// name of the file the shutdown report is written to in the working directory
+const ShutdownReportFileName = "shutdown-report.json"
+
+// how long to wait for the report endpoint to accept a report
+const reportPostTimeout = 10 * time.Second
+
+// NetDbReport summarizes the local network database
+type NetDbReport struct {
+	Path        string `json:"path"`
+	RouterInfos int    `json:"router_infos"`
+	LeaseSets   int    `json:"lease_sets"`
+}
+
+// PeersReport summarizes the peers the router knows of and talks to
+type PeersReport struct {
+	// RouterInfos in the netdb, and how many of them are floodfills
+	Known      int `json:"known"`
+	Floodfills int `json:"floodfills"`
+	// peers with a session open
+	Connected int `json:"connected"`
+	// peers with a profile, and with a connection history
+	Profiled int `json:"profiled"`
+	History  int `json:"history"`
+}
+
+// TunnelsReport summarizes the client tunnels and LeaseSets
+type TunnelsReport struct {
+	// size of the warm pool and tunnels in it ready to be adopted
+	WarmPoolSize            int                        `json:"warm_pool_size"`
+	WarmReady               int                        `json:"warm_ready"`
+	LeaseSelection          tunnel.LeaseSelectionStats `json:"lease_selection"`
+	LeaseSetsPublished      uint64                     `json:"leasesets_published"`
+	LeaseSetPublishFailures uint64                     `json:"leaseset_publish_failures"`
+}
+
+// StateReport is a snapshot of router state suitable for fleet monitoring
+type StateReport struct {
+	Started       time.Time               `json:"started"`
+	Generated     time.Time               `json:"generated"`
+	UptimeSeconds int64                   `json:"uptime_seconds"`
+	Running       bool                    `json:"running"`
+	NetDb         NetDbReport             `json:"netdb"`
+	Peers         PeersReport             `json:"peers"`
+	Tunnels       TunnelsReport           `json:"tunnels"`
+	Workers       map[string]int          `json:"workers"`
+	Errors        map[string]int          `json:"errors"`
+	Memory        map[string]memory.Usage `json:"memory"`
+	Features      []features.Feature      `json:"features"`
+}
+
+// countError increments the error counter for subsystem
+func (r *Router) countError(subsystem string) {
+	r.errorsMutex.Lock()
+	defer r.errorsMutex.Unlock()
+	if r.errorCounts == nil {
+		r.errorCounts = make(map[string]int)
+	}
+	r.errorCounts[subsystem]++
+}
+
+// Report takes a snapshot of the current router state
+func (r *Router) Report() StateReport {
+	now := time.Now()
+	routerInfos, leaseSets := r.ndb.Counts()
+	report := StateReport{
+		Started:   r.started,
+		Generated: now,
+		Running:   r.running,
+		NetDb: NetDbReport{
+			Path:        r.ndb.Path(),
+			RouterInfos: routerInfos,
+			LeaseSets:   leaseSets,
+		},
+		Peers: PeersReport{
+			Known:      routerInfos,
+			Floodfills: r.knownFloodfills(),
+			Connected:  r.history.ConnectedPeers(),
+			Profiled:   len(r.profiles.All()),
+			History:    r.history.Peers(),
+		},
+		Tunnels:  r.tunnelsReport(),
+		Workers:  r.WorkerSizes(),
+		Errors:   make(map[string]int),
+		Memory:   r.memory.Usage(),
+		Features: r.Features(),
+	}
+	if !r.started.IsZero() {
+		report.UptimeSeconds = int64(now.Sub(r.started).Seconds())
+	}
+	r.errorsMutex.Lock()
+	for subsystem, count := range r.errorCounts {
+		report.Errors[subsystem] = count
+	}
+	r.errorsMutex.Unlock()
+	return report
+}
+
+// tunnelsReport summarizes the warm pool, lease selection and LeaseSet
+// publication
+func (r *Router) tunnelsReport() TunnelsReport {
+	report := TunnelsReport{
+		LeaseSetsPublished:      r.leaseSetsPublished.Load(),
+		LeaseSetPublishFailures: r.leaseSetPublishFailures.Load(),
+	}
+	if r.warmPool != nil {
+		report.WarmPoolSize = r.warmPool.Size()
+		report.WarmReady = r.warmPool.Ready()
+	}
+	if r.leaseSelector != nil {
+		report.LeaseSelection = r.leaseSelector.Stats()
+	}
+	return report
+}
+
+// writeShutdownReport writes the state report to the state directory and
+// POSTs it to the configured endpoint, if there is one
+func (r *Router) writeShutdownReport() error {
+	if r.cfg == nil || r.cfg.Report == nil || !r.cfg.Report.Enabled {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.Report(), "", "  ")
+	if err != nil {
+		return err
+	}
+	path := r.cfg.StatePath(ShutdownReportFileName)
+	if err := os.MkdirAll(r.cfg.StateDir(), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	log.WithField("path", path).Debug("Wrote shutdown report")
+	if err := r.writeShutdownLog(); err != nil {
+		return err
+	}
+	if r.cfg.Report.Endpoint == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: reportPostTimeout}
+	resp, err := client.Post(r.cfg.Report.Endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("report endpoint returned %s", resp.Status)
+	}
+	log.WithField("endpoint", r.cfg.Report.Endpoint).Debug("Posted shutdown report")
+	return nil
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdownReport(t *testing.T) {
+	received := make(chan StateReport, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var report StateReport
+		json.Unmarshal(body, &report)
+		received <- report
+	}))
+	defer server.Close()
+
+	workingDir := t.TempDir()
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: workingDir,
+		NetDb:      &config.NetDbConfig{Path: filepath.Join(workingDir, "netDb")},
+		Workers:    &config.WorkerConfig{NetDb: 2, TunnelBuild: 1},
+		Report:     &config.ReportConfig{Enabled: true, Endpoint: server.URL},
+	})
+	require.NoError(t, err)
+	r.countError("netdb")
+	r.countError("netdb")
+	require.NoError(t, r.Close())
+
+	data, err := os.ReadFile(filepath.Join(workingDir, ShutdownReportFileName))
+	require.NoError(t, err)
+	var report StateReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 2, report.Errors["netdb"])
+	assert.Equal(t, 2, report.Workers[WORKERS_NETDB])
+
+	posted := <-received
+	assert.Equal(t, report.Errors, posted.Errors)
+}
+
+func TestStateReportPeersAndTunnels(t *testing.T) {
+	r := newStatsTestRouter(t)
+	var connected, profiled common.Hash
+	connected[0], profiled[0] = 1, 2
+	r.PeerHistory().Connected(connected, "NTCP2", time.Now())
+	r.PeerHistory().Disconnected(profiled, "SSU2", "timeout", time.Now())
+	r.PeerProfiles().RecordSuccess(profiled)
+	r.warmPool = tunnel.NewWarmPool(1, func() (*tunnel.BuiltTunnel, error) {
+		return &tunnel.BuiltTunnel{ID: 1, Expiration: time.Now().Add(tunnel.TUNNEL_LIFETIME)}, nil
+	})
+	r.warmPool.Start()
+	t.Cleanup(r.warmPool.Stop)
+	require.Eventually(t, func() bool { return r.warmPool.Ready() == 1 }, time.Second, time.Millisecond)
+	r.leaseSetPublishFailures.Add(1)
+
+	report := r.Report()
+	assert.Equal(t, PeersReport{Connected: 1, Profiled: 1, History: 2}, report.Peers)
+	assert.Equal(t, 1, report.Tunnels.WarmPoolSize)
+	assert.Equal(t, 1, report.Tunnels.WarmReady)
+	assert.Equal(t, uint64(1), report.Tunnels.LeaseSetPublishFailures)
+
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Contains(t, decoded, "peers")
+	assert.Contains(t, decoded, "tunnels")
+}
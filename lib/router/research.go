@@ -0,0 +1,120 @@
+package router
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/research"
+	"github.com/go-i2p/go-i2p/lib/update"
+)
+
+// how long publishing a single research report may take
+const researchPublishTimeout = 5 * time.Minute
+
+// stats reported as tunnel counts, by the name used in the report
+var researchTunnelStats = map[string]string{
+	"participating": STAT_TUNNELS_PART,
+	"warm":          STAT_WARM_TUNNELS,
+}
+
+// startResearchReports periodically publishes research statistics, if the
+// operator opted in
+func (r *Router) startResearchReports() {
+	if r.cfg == nil || r.cfg.Research == nil || !r.cfg.Research.Enabled || r.cfg.Research.Collector == "" {
+		return
+	}
+	passphrase, err := r.keyPassphrase(research.KEY_FILE_NAME)
+	if err != nil {
+		log.WithError(err).Error("Failed to get research key passphrase from keychain, research statistics disabled")
+		return
+	}
+	key, err := research.LoadOrCreateSealedKey(r.cfg.KeyPath(research.KEY_FILE_NAME), passphrase)
+	if err != nil {
+		log.WithError(err).Error("Failed to load research key, research statistics disabled")
+		return
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if r.cfg.Research.Proxy != "" {
+		proxy, err := url.Parse(r.cfg.Research.Proxy)
+		if err != nil {
+			log.WithError(err).Error("Invalid research proxy, research statistics disabled")
+			return
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	publisher := &research.Publisher{
+		URL:    r.cfg.Research.Collector,
+		Client: &http.Client{Transport: transport},
+		Key:    key,
+	}
+	interval := r.cfg.Research.Interval
+	if interval <= 0 {
+		interval = config.DefaultResearchConfig.Interval
+	}
+	r.researchStop = make(chan struct{})
+	go r.researchLoop(publisher, interval, r.researchStop)
+}
+
+// stopResearchReports stops the loop started by startResearchReports
+func (r *Router) stopResearchReports() {
+	if r.researchStop != nil {
+		close(r.researchStop)
+		r.researchStop = nil
+	}
+}
+
+func (r *Router) researchLoop(publisher *research.Publisher, interval time.Duration, stop chan struct{}) {
+	// the first report waits a random part of the interval, so publication
+	// times do not give away when the router started
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval))))
+	defer timer.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-timer.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), researchPublishTimeout)
+		if err := publisher.Publish(ctx, r.researchReport()); err != nil {
+			r.countError("research")
+		}
+		cancel()
+		timer.Reset(interval)
+	}
+}
+
+// researchReport builds the anonymized report of the router's statistics
+func (r *Router) researchReport() research.Report {
+	var uptime time.Duration
+	if !r.started.IsZero() {
+		uptime = time.Since(r.started)
+	}
+	tunnels := make(map[string]int)
+	for kind, name := range researchTunnelStats {
+		for _, value := range r.Stats(name) {
+			if count, ok := statCount(value); ok {
+				tunnels[kind] = count
+			}
+		}
+	}
+	return research.NewReport(update.Version, uptime, tunnels, time.Now())
+}
+
+// statCount returns a numeric stat value as an int
+func statCount(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case uint32:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,23 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/research"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResearchReportUsesRegisteredStats(t *testing.T) {
+	r := newWarmPoolTestRouter(t, 0, false)
+	r.RegisterStat(STAT_TUNNELS_PART, func() interface{} { return int64(57) })
+
+	report := r.researchReport()
+	assert.Equal(t, research.UPTIME_HOUR, report.Uptime)
+	assert.Equal(t, map[string]int{"participating": 50}, report.Tunnels)
+}
+
+func TestResearchReportsAreOptIn(t *testing.T) {
+	r := newWarmPoolTestRouter(t, 0, false)
+	r.startResearchReports()
+	assert.Nil(t, r.researchStop)
+}
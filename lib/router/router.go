@@ -1,23 +1,86 @@
 package router
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-i2p/go-i2p/lib/util/keychain"
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 	"github.com/sirupsen/logrus"
 
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
 	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/garlic"
+	"github.com/go-i2p/go-i2p/lib/i2np"
 	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/peer"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/go-i2p/go-i2p/lib/util/memory"
+	"github.com/go-i2p/go-i2p/lib/util/workers"
 )
 
 var log = logger.GetGoI2PLogger()
 
 // i2p router type
 type Router struct {
-	cfg       *config.RouterConfig
-	ndb       netdb.StdNetDB
-	closeChnl chan bool
-	running   bool
+	cfg *config.RouterConfig
+	ndb netdb.StdNetDB
+	// closed by Stop
+	closeChnl chan struct{}
+	closeOnce sync.Once
+	// closed when the mainloop has returned
+	mainloopDone chan struct{}
+	running      bool
+	// worker pools by name, resized from other goroutines
+	workersMutex sync.RWMutex
+	workers      map[string]*workers.Pool
+	started      time.Time
+	// stops checking for updates, and updateDone is closed once it has
+	updateCancel context.CancelFunc
+	updateDone   chan struct{}
+	// closed to stop publishing research statistics
+	researchStop chan struct{}
+	// error counters by subsystem, reported on shutdown
+	errorsMutex sync.Mutex
+	errorCounts map[string]int
+	// recent errors by peer, reported by PeerReport
+	peerErrors map[common.Hash][]PeerError
+	// stats exposed under the Java router's names
+	stats statRegistry
+	// replay filter shared by all message receivers
+	messageValidator *i2np.MessageValidator
+	// peer profiles used for peer selection, and where they are kept
+	// between runs
+	profiles     *peer.Profiles
+	profileStore peer.ProfileStore
+	// connection history of peers, reported by PeerReport
+	history *peer.History
+	// keeps the passphrases of sealed private key files, if enabled
+	keychain keychain.Keychain
+	// decides whether we serve as a floodfill
+	floodfill *netdb.FloodfillMonitor
+	// the RouterInfo we publish, if set
+	routerInfo *router_info.OwnedRouterInfo
+	// memory accounts of the caches
+	memory *memory.Accountant
+	// keys and tags of the ElGamal/AES+SessionTag garlic sessions
+	sessionKeys *garlic.SessionKeyManager
+	// builds the warm client tunnels, and the pool holding them
+	tunnelBuilder tunnel.BuildFunc
+	warmPool      *tunnel.WarmPool
+	// chooses the leases of remote destinations outbound messages go to
+	leaseSelector *tunnel.LeaseSelector
+	// outcomes of our LeaseSet publications
+	leaseSetsPublished      atomic.Uint64
+	leaseSetPublishFailures atomic.Uint64
+	// the management API server, and where it listens
+	management     *http.Server
+	managementAddr net.Addr
 }
 
 // CreateRouter creates a router with the provided configuration
@@ -37,22 +100,76 @@ func FromConfig(c *config.RouterConfig) (r *Router, err error) {
 	log.WithField("config", c).Debug("Creating router from configuration")
 	r = new(Router)
 	r.cfg = c
-	r.closeChnl = make(chan bool)
+	r.closeChnl = make(chan struct{})
+	r.messageValidator = i2np.NewMessageValidator(i2np.DEFAULT_EXPECTED_MESSAGES, i2np.DEFAULT_REPLAY_FALSE_POSITIVE_RATE)
+	r.profiles = peer.NewProfiles()
+	r.history = peer.NewHistory()
+	r.keychain = keychain.System()
+	r.memory = memory.NewAccountant()
+	r.sessionKeys = garlic.NewSessionKeyManager()
+	r.initFeatures()
+	if err = r.initStorage(); err != nil {
+		log.WithError(err).Error("Invalid storage configuration")
+		return nil, err
+	}
+	if err = r.initVersionPolicy(); err != nil {
+		log.WithError(err).Error("Invalid router version policy")
+		return nil, err
+	}
+	if err = r.initFloodfill(); err != nil {
+		log.WithError(err).Error("Invalid floodfill mode")
+		return nil, err
+	}
+	if _, err = r.SocketOptions(); err != nil {
+		log.WithError(err).Error("Invalid transport socket options")
+		return nil, err
+	}
+	if err = r.initLeaseSelector(); err != nil {
+		log.WithError(err).Error("Invalid lease selection strategy")
+		return nil, err
+	}
+	if err = r.initLeaseSetPublishing(); err != nil {
+		log.WithError(err).Error("Invalid LeaseSet publishing options")
+		return nil, err
+	}
+	if err = r.initWorkers(); err != nil {
+		log.WithError(err).Error("Failed to create worker pools")
+		return nil, err
+	}
+	r.registerWorkerQueues()
+	r.registerSessionKeyMemory()
+	r.registerRouterStats()
+	if r.Observer() {
+		log.Info("Router is in observer mode, tunnels and client sessions are disabled")
+	}
 	log.Debug("Router created successfully from configuration")
 	return
 }
 
-// Wait blocks until router is fully stopped
+// MessageValidator returns the router-wide filter for expired and replayed
+// I2NP messages.
+func (r *Router) MessageValidator() *i2np.MessageValidator {
+	return r.messageValidator
+}
+
+// Wait blocks until router is fully stopped: Stop was called and the
+// mainloop, if started, has returned
 func (r *Router) Wait() {
 	log.Debug("Waiting for router to stop")
 	<-r.closeChnl
+	if done := r.mainloopDone; done != nil {
+		<-done
+	}
 	log.Debug("Router has stopped")
 }
 
-// Stop starts stopping internal state of router
+// Stop starts stopping internal state of router, it can be called more than
+// once and does not block
 func (r *Router) Stop() {
 	log.Debug("Stopping router")
-	r.closeChnl <- true
+	r.closeOnce.Do(func() {
+		close(r.closeChnl)
+	})
 	r.running = false
 	log.Debug("Router stop signal sent")
 }
@@ -60,6 +177,15 @@ func (r *Router) Stop() {
 // Close closes any internal state and finallizes router resources so that nothing can start up again
 func (r *Router) Close() error {
 	log.Warn("Closing router not implemented(?)")
+	r.stopUpdateChecks()
+	r.stopResearchReports()
+	r.stopWarmPool()
+	r.stopManagement()
+	r.saveProfiles()
+	if err := r.writeShutdownReport(); err != nil {
+		log.WithError(err).Error("Failed to write shutdown report")
+	}
+	r.closeWorkers()
 	return nil
 }
 
@@ -74,32 +200,63 @@ func (r *Router) Start() {
 	}
 	log.Debug("Starting router")
 	r.running = true
+	r.started = time.Now()
+	r.startUpdateChecks()
+	r.startResearchReports()
+	r.loadProfiles()
+	r.startWarmPool()
+	if err := r.startManagement(); err != nil {
+		r.countError("management")
+		log.WithError(err).Error("Failed to start management API")
+	}
+	r.mainloopDone = make(chan struct{})
 	go r.mainloop()
 }
 
-// run i2p router mainloop
-func (r *Router) mainloop() {
-	log.Debug("Entering router mainloop")
-	r.ndb = netdb.NewStdNetDB(r.cfg.NetDb.Path)
-	log.WithField("netdb_path", r.cfg.NetDb.Path).Debug("Created StdNetDB")
-	// make sure the netdb is ready
-	var e error
+// loadNetDb makes sure the netdb directory exists and reads the RouterInfos
+// in it
+func (r *Router) loadNetDb() error {
 	if err := r.ndb.Ensure(); err != nil {
-		e = err
+		r.countError("netdb")
 		log.WithError(err).Error("Failed to ensure NetDB")
+		return err
 	}
 	if sz := r.ndb.Size(); sz >= 0 {
 		log.WithField("size", sz).Debug("NetDB Size")
 	} else {
 		log.Warn("Unable to determine NetDB size")
 	}
+	return nil
+}
+
+// run i2p router mainloop
+func (r *Router) mainloop() {
+	defer close(r.mainloopDone)
+	log.Debug("Entering router mainloop")
+	r.registerNetDbMemory()
+	// make sure the netdb is ready
+	// loading the netdb reads the RouterInfo files on the netdb workers
+	e := r.loadNetDb()
 	if e == nil {
 		// netdb ready
 		log.WithFields(logrus.Fields{
 			"at": "(Router) mainloop",
 		}).Debug("Router ready")
-		for e == nil {
-			time.Sleep(time.Second)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var memoryChecked time.Time
+		// publishes the configured floodfill mode at once
+		floodfillChecked := r.floodfillCheckDue(time.Time{})
+	loop:
+		for {
+			select {
+			case <-r.closeChnl:
+				log.Debug("Router stop signal received")
+				break loop
+			case <-ticker.C:
+				memoryChecked = r.memoryCheckDue(memoryChecked)
+				floodfillChecked = r.floodfillCheckDue(floodfillChecked)
+			}
 		}
 	} else {
 		// netdb failed
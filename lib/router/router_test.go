@@ -0,0 +1,42 @@
+package router
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStopEndsMainloop(t *testing.T) {
+	dir := t.TempDir()
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: dir,
+		NetDb:      &config.NetDbConfig{Path: filepath.Join(dir, "netDb")},
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+
+	r.Start()
+	r.Stop()
+	r.Stop()
+	stopped := make(chan struct{})
+	go func() {
+		r.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait did not return after Stop")
+	}
+	assert.False(t, r.running)
+	select {
+	case <-r.mainloopDone:
+	default:
+		t.Fatal("Wait returned before the mainloop")
+	}
+}
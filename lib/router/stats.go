@@ -0,0 +1,137 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/update"
+)
+
+// Stat names shared with the Java router, so that monitoring templates written
+// against its I2PControl RouterInfo method and console stats keep working.
+const (
+	STAT_UPTIME           = "i2p.router.uptime"
+	STAT_VERSION          = "i2p.router.version"
+	STAT_STATUS           = "i2p.router.status"
+	STAT_KNOWN_PEERS      = "i2p.router.netdb.knownpeers"
+	STAT_ACTIVE_PEERS     = "i2p.router.netdb.activepeers"
+	STAT_FAST_PEERS       = "i2p.router.netdb.fastpeers"
+	STAT_HIGH_CAP_PEERS   = "i2p.router.netdb.highcapacitypeers"
+	STAT_LEASESETS        = "i2p.router.netdb.leasesets"
+	STAT_BW_INBOUND_1S    = "i2p.router.net.bw.inbound.1s"
+	STAT_BW_OUTBOUND_1S   = "i2p.router.net.bw.outbound.1s"
+	STAT_TUNNELS_PART     = "i2p.router.net.tunnels.participating"
+	STAT_ACTIVE_PEERS_RAW = "router.activePeers"
+	STAT_BW_SEND_RATE     = "bw.sendRate"
+	STAT_BW_RECV_RATE     = "bw.recvRate"
+	STAT_EXPL_BUILD_OK    = "tunnel.buildExploratorySuccess"
+	STAT_CLIENT_BUILD_OK  = "tunnel.buildClientSuccess"
+	STAT_DUPLICATE_MSG_ID = "router.duplicateMessageId"
+)
+
+// StatFunc returns the current value of a stat, a number or a string
+type StatFunc func() interface{}
+
+// statRegistry maps stat names onto the functions providing them
+type statRegistry struct {
+	mutex sync.RWMutex
+	stats map[string]StatFunc
+}
+
+// RegisterStat makes a stat available under name, replacing any previous
+// provider. Subsystems register the stats they can measure, stats nothing
+// has registered are left out of Stats rather than reported as zero.
+func (r *Router) RegisterStat(name string, fn StatFunc) {
+	r.stats.mutex.Lock()
+	defer r.stats.mutex.Unlock()
+	if r.stats.stats == nil {
+		r.stats.stats = make(map[string]StatFunc)
+	}
+	r.stats.stats[name] = fn
+}
+
+// registerRouterStats registers the stats the router itself can provide
+func (r *Router) registerRouterStats() {
+	r.RegisterStat(STAT_UPTIME, func() interface{} {
+		if r.started.IsZero() {
+			return int64(0)
+		}
+		// I2PControl reports uptime in milliseconds
+		return time.Since(r.started).Milliseconds()
+	})
+	r.RegisterStat(STAT_VERSION, func() interface{} {
+		return update.Version
+	})
+	r.RegisterStat(STAT_STATUS, func() interface{} {
+		if r.running {
+			return "Running"
+		}
+		return "Stopped"
+	})
+	r.RegisterStat(STAT_KNOWN_PEERS, func() interface{} {
+		routerInfos, _ := r.ndb.Counts()
+		return routerInfos
+	})
+	r.RegisterStat(STAT_LEASESETS, func() interface{} {
+		_, leaseSets := r.ndb.Counts()
+		return leaseSets
+	})
+	r.RegisterStat(STAT_DUPLICATE_MSG_ID, func() interface{} {
+		return r.messageValidator.Stats().Duplicates
+	})
+}
+
+// Stats returns the current value of every registered stat, or only of the
+// named ones if names are given
+func (r *Router) Stats(names ...string) map[string]interface{} {
+	r.stats.mutex.RLock()
+	defer r.stats.mutex.RUnlock()
+	values := make(map[string]interface{})
+	if len(names) == 0 {
+		for name, fn := range r.stats.stats {
+			values[name] = fn()
+		}
+		return values
+	}
+	for _, name := range names {
+		if fn, ok := r.stats.stats[name]; ok {
+			values[name] = fn()
+		}
+	}
+	return values
+}
+
+// StatNames returns the names of the registered stats in sorted order
+func (r *Router) StatNames() []string {
+	r.stats.mutex.RLock()
+	defer r.stats.mutex.RUnlock()
+	names := make([]string, 0, len(r.stats.stats))
+	for name := range r.stats.stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StatsHandler serves the router stats as a JSON object. A comma separated
+// "stat" query parameter selects individual stats.
+func (r *Router) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var names []string
+		for _, param := range req.URL.Query()["stat"] {
+			names = append(names, strings.Split(param, ",")...)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(r.Stats(names...)); err != nil {
+			log.WithError(err).Error("Failed to write stats")
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStatsTestRouter(t *testing.T) *Router {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	return r
+}
+
+func TestRouterStats(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.RegisterStat(STAT_BW_SEND_RATE, func() interface{} { return 1024.0 })
+
+	stats := r.Stats()
+	assert.Equal(t, "Stopped", stats[STAT_STATUS])
+	assert.Equal(t, 0, stats[STAT_KNOWN_PEERS])
+	assert.Equal(t, 1024.0, stats[STAT_BW_SEND_RATE])
+	assert.NotContains(t, stats, STAT_EXPL_BUILD_OK)
+	assert.Contains(t, r.StatNames(), STAT_UPTIME)
+
+	selected := r.Stats(STAT_BW_SEND_RATE, STAT_EXPL_BUILD_OK)
+	assert.Equal(t, map[string]interface{}{STAT_BW_SEND_RATE: 1024.0}, selected)
+}
+
+func TestRouterStatsHandler(t *testing.T) {
+	r := newStatsTestRouter(t)
+	r.RegisterStat(STAT_BW_RECV_RATE, func() interface{} { return 512 })
+
+	recorder := httptest.NewRecorder()
+	r.StatsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/stats?stat=bw.recvRate,i2p.router.status", nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	var stats map[string]interface{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &stats))
+	assert.Equal(t, map[string]interface{}{
+		STAT_BW_RECV_RATE: 512.0,
+		STAT_STATUS:       "Stopped",
+	}, stats)
+
+	recorder = httptest.NewRecorder()
+	r.StatsHandler().ServeHTTP(recorder, httptest.NewRequest("POST", "/stats", nil))
+	assert.Equal(t, 405, recorder.Code)
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/research"
+	"github.com/go-i2p/go-i2p/lib/util"
+	"github.com/go-i2p/go-i2p/lib/util/tlsconfig"
+	"github.com/sirupsen/logrus"
+)
+
+// initStorage checks the storage configuration, creates the state directory
+// of a read only router and checks the permissions of its private keys
+func (r *Router) initStorage() error {
+	if err := r.cfg.CheckStorage(); err != nil {
+		return err
+	}
+	if r.cfg.ReadOnly() {
+		if err := os.MkdirAll(r.cfg.StateDir(), 0o700); err != nil {
+			return err
+		}
+		log.WithFields(logrus.Fields{
+			"working_dir": r.cfg.WorkingDir,
+			"state_dir":   r.cfg.StateDir(),
+		}).Info("Storage is read only, writing state to the state directory")
+	}
+	r.checkKeyPermissions()
+	// created here rather than in the mainloop so reports and stats read
+	// from other goroutines never see it replaced
+	path, shared := r.cfg.NetDbPaths()
+	r.ndb = netdb.NewStdNetDB(path)
+	r.ndb.Shared = shared
+	r.ndb.Spawn = r.spawner(WORKERS_NETDB)
+	log.WithField("netdb_path", path).Debug("Created StdNetDB")
+	return nil
+}
+
+// keyFiles returns the private key files the router may use
+func (r *Router) keyFiles() []string {
+	files := []string{
+		filepath.Join(r.cfg.WorkingDir, research.KEY_FILE_NAME),
+		filepath.Join(r.cfg.WorkingDir, tlsconfig.SELF_SIGNED_KEY_FILE),
+	}
+	if r.cfg.ReadOnly() {
+		files = append(files,
+			r.cfg.StatePath(research.KEY_FILE_NAME),
+			r.cfg.StatePath(tlsconfig.SELF_SIGNED_KEY_FILE))
+	}
+	if r.cfg.Clients != nil && r.cfg.Clients.TLSKeyFile != "" {
+		files = append(files, r.cfg.Clients.TLSKeyFile)
+	}
+	return files
+}
+
+// checkKeyPermissions warns about private key files other users can access,
+// restricting them if strict permissions are enabled and the file may be
+// written
+func (r *Router) checkKeyPermissions() {
+	strict := r.cfg.Storage == nil || r.cfg.Storage.StrictPermissions
+	for _, path := range r.keyFiles() {
+		fix := strict && !(r.cfg.ReadOnly() && r.inWorkingDir(path))
+		tooOpen, err := util.CheckKeyPermissions(path, fix)
+		switch {
+		case err != nil:
+			log.WithError(err).WithField("path", path).Warn("Failed to check permissions of private key file")
+		case tooOpen && fix:
+			log.WithField("path", path).Warn("Private key file was accessible by other users, restricted it to 0600")
+		case tooOpen:
+			log.WithField("path", path).Warn("Private key file is accessible by other users, it should have mode 0600")
+		}
+	}
+}
+
+// inWorkingDir reports whether path is in the working directory
+func (r *Router) inWorkingDir(path string) bool {
+	rel, err := filepath.Rel(r.cfg.WorkingDir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
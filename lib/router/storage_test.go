@@ -0,0 +1,93 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/research"
+	"github.com/go-i2p/go-i2p/lib/util/tlsconfig"
+)
+
+func newStorageTestConfig(t *testing.T, storage *config.StorageConfig) *config.RouterConfig {
+	workingDir := t.TempDir()
+	return &config.RouterConfig{
+		WorkingDir: workingDir,
+		NetDb:      &config.NetDbConfig{Path: filepath.Join(workingDir, "netDb")},
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Report:     &config.ReportConfig{Enabled: true},
+		Storage:    storage,
+	}
+}
+
+func TestReadOnlyStorageRequiresStateDir(t *testing.T) {
+	_, err := FromConfig(newStorageTestConfig(t, &config.StorageConfig{ReadOnly: true}))
+	assert.ErrorIs(t, err, config.ErrNoStateDir)
+}
+
+func TestReadOnlyStorageWritesToStateDir(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "state")
+	cfg := newStorageTestConfig(t, &config.StorageConfig{ReadOnly: true, StateDir: stateDir})
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.WorkingDir, tlsconfig.SELF_SIGNED_KEY_FILE), []byte("key"), 0o600))
+
+	r, err := FromConfig(cfg)
+	require.NoError(t, err)
+	assert.DirExists(t, stateDir)
+
+	path, shared := cfg.NetDbPaths()
+	assert.Equal(t, filepath.Join(stateDir, "netDb"), path)
+	assert.Equal(t, cfg.NetDb.Path, shared)
+	assert.Equal(t, filepath.Join(cfg.WorkingDir, tlsconfig.SELF_SIGNED_KEY_FILE), cfg.KeyPath(tlsconfig.SELF_SIGNED_KEY_FILE))
+	assert.Equal(t, filepath.Join(stateDir, research.KEY_FILE_NAME), cfg.KeyPath(research.KEY_FILE_NAME))
+
+	require.NoError(t, r.Close())
+	assert.FileExists(t, filepath.Join(stateDir, ShutdownReportFileName))
+	assert.NoFileExists(t, filepath.Join(cfg.WorkingDir, ShutdownReportFileName))
+
+	bundle, err := OfflineSupportBundle(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, bundle.State)
+}
+
+func TestStorageRestrictsKeyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't reflect access on Windows")
+	}
+	cfg := newStorageTestConfig(t, &config.StorageConfig{StrictPermissions: true})
+	key := filepath.Join(cfg.WorkingDir, research.KEY_FILE_NAME)
+	require.NoError(t, os.WriteFile(key, []byte("seed"), 0o644))
+	require.NoError(t, os.Chmod(key, 0o644))
+
+	r, err := FromConfig(cfg)
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	info, err := os.Stat(key)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestReadOnlyStorageLeavesKeyPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes don't reflect access on Windows")
+	}
+	cfg := newStorageTestConfig(t, &config.StorageConfig{
+		ReadOnly:          true,
+		StateDir:          t.TempDir(),
+		StrictPermissions: true,
+	})
+	key := filepath.Join(cfg.WorkingDir, research.KEY_FILE_NAME)
+	require.NoError(t, os.WriteFile(key, []byte("seed"), 0o644))
+	require.NoError(t, os.Chmod(key, 0o644))
+
+	r, err := FromConfig(cfg)
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	info, err := os.Stat(key)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+}
@@ -0,0 +1,204 @@
+package router
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/go-i2p/go-i2p/lib/netdb/analytics"
+	"github.com/go-i2p/go-i2p/lib/update"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"gopkg.in/yaml.v3"
+)
+
+// name of the file the recent log lines are written to in the working
+// directory on shutdown, next to the shutdown report
+const ShutdownLogFileName = "shutdown.log"
+
+// names of the files in a support bundle
+const (
+	SUPPORT_BUNDLE_VERSION = "version.txt"
+	SUPPORT_BUNDLE_CONFIG  = "config.yaml"
+	SUPPORT_BUNDLE_STATE   = "state.json"
+	SUPPORT_BUNDLE_NETDB   = "netdb.json"
+	SUPPORT_BUNDLE_LOGS    = "recent.log"
+)
+
+// SupportBundle is what users attach to bug reports: the configuration
+// without secrets, a state snapshot, netdb statistics and recent log lines.
+// RouterInfos and LeaseSets themselves are left out.
+type SupportBundle struct {
+	Generated time.Time
+	// sanitized configuration
+	Config *config.RouterConfig
+	// state of the running router, or the last shutdown report; nil if
+	// neither is known
+	State *StateReport
+	NetDb *analytics.Report
+	Logs  []string
+}
+
+// SupportBundle collects the support bundle of the running router.
+func (r *Router) SupportBundle() SupportBundle {
+	state := r.Report()
+	bundle := SupportBundle{
+		Generated: time.Now(),
+		State:     &state,
+		NetDb:     analytics.AnalyzeNetDB(&r.ndb, nil),
+		Logs:      logger.Recent(),
+	}
+	if r.cfg != nil {
+		bundle.Config = r.cfg.Sanitized()
+	}
+	bundle.sanitize()
+	return bundle
+}
+
+// OfflineSupportBundle collects a support bundle from the files of a
+// router which is not running: the shutdown report and log lines of its
+// last run, and statistics over its netdb on disk.
+func OfflineSupportBundle(cfg *config.RouterConfig) (SupportBundle, error) {
+	bundle := SupportBundle{
+		Generated: time.Now(),
+		Config:    cfg.Sanitized(),
+	}
+	data, err := os.ReadFile(cfg.StatePath(ShutdownReportFileName))
+	if err == nil {
+		var state StateReport
+		if err := json.Unmarshal(data, &state); err != nil {
+			return bundle, fmt.Errorf("%s: %w", ShutdownReportFileName, err)
+		}
+		bundle.State = &state
+	} else if !os.IsNotExist(err) {
+		return bundle, err
+	}
+	data, err = os.ReadFile(cfg.StatePath(ShutdownLogFileName))
+	if err == nil {
+		bundle.Logs = strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(bundle.Logs) > 0 {
+			bundle.Logs[len(bundle.Logs)-1] += "\n"
+		}
+	} else if !os.IsNotExist(err) {
+		return bundle, err
+	}
+	path, shared := cfg.NetDbPaths()
+	db := netdb.NewStdNetDB(path)
+	db.Shared = shared
+	if db.Exists() {
+		if err := db.RecalculateSize(); err != nil {
+			return bundle, err
+		}
+	}
+	bundle.NetDb = analytics.AnalyzeNetDB(&db, nil)
+	bundle.sanitize()
+	return bundle, nil
+}
+
+// sanitize replaces the user's home directory in the paths of the state
+// report and log lines with ~, as the configuration's Sanitized does
+func (bundle *SupportBundle) sanitize() {
+	if bundle.State != nil {
+		state := *bundle.State
+		state.NetDb.Path = config.SanitizePath(state.NetDb.Path)
+		bundle.State = &state
+	}
+	for i, line := range bundle.Logs {
+		bundle.Logs[i] = config.SanitizeText(line)
+	}
+}
+
+// Write writes the bundle as a gzipped tar archive, its files in a
+// directory named after the time it was generated.
+func (bundle SupportBundle) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	dir := "go-i2p-support-" + bundle.Generated.UTC().Format("20060102-150405")
+	add := func(name string, data []byte) error {
+		if err := archive.WriteHeader(&tar.Header{
+			Name:    dir + "/" + name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: bundle.Generated,
+		}); err != nil {
+			return err
+		}
+		_, err := archive.Write(data)
+		return err
+	}
+
+	if err := add(SUPPORT_BUNDLE_VERSION, []byte(update.Version+"\n")); err != nil {
+		return err
+	}
+	if bundle.Config != nil {
+		data, err := yaml.Marshal(bundle.Config)
+		if err != nil {
+			return err
+		}
+		if err := add(SUPPORT_BUNDLE_CONFIG, data); err != nil {
+			return err
+		}
+	}
+	if bundle.State != nil {
+		data, err := json.MarshalIndent(bundle.State, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := add(SUPPORT_BUNDLE_STATE, data); err != nil {
+			return err
+		}
+	}
+	if bundle.NetDb != nil {
+		var data strings.Builder
+		if err := bundle.NetDb.WriteJSON(&data); err != nil {
+			return err
+		}
+		if err := add(SUPPORT_BUNDLE_NETDB, []byte(data.String())); err != nil {
+			return err
+		}
+	}
+	if err := add(SUPPORT_BUNDLE_LOGS, []byte(strings.Join(bundle.Logs, ""))); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeShutdownLog writes the recent log lines to the state directory,
+// for OfflineSupportBundle
+func (r *Router) writeShutdownLog() error {
+	path := r.cfg.StatePath(ShutdownLogFileName)
+	if err := os.WriteFile(path, []byte(strings.Join(logger.Recent(), "")), 0o600); err != nil {
+		return err
+	}
+	log.WithField("path", path).Debug("Wrote shutdown log")
+	return nil
+}
+
+// SupportBundleHandler serves the support bundle of the running router as
+// a download. The management API mounts it at /support-bundle.
+func (r *Router) SupportBundleHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		bundle := r.SupportBundle()
+		log.WithField("remote", req.RemoteAddr).Debug("Serving support bundle")
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="go-i2p-support-%s.tar.gz"`,
+			bundle.Generated.UTC().Format("20060102-150405")))
+		if err := bundle.Write(w); err != nil {
+			log.WithError(err).Error("Failed to write support bundle")
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package router
+
+import (
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/transport/sockopt"
+)
+
+// transportConfig returns the transport socket configuration
+func (r *Router) transportConfig() config.TransportConfig {
+	if r.cfg != nil && r.cfg.Transport != nil {
+		return *r.cfg.Transport
+	}
+	return config.DefaultTransportConfig
+}
+
+// SocketOptions returns the socket options configured for the transports,
+// and an error if they are out of range or not supported on this platform.
+func (r *Router) SocketOptions() (sockopt.Options, error) {
+	cfg := r.transportConfig()
+	options := sockopt.Options{
+		DSCP:      cfg.DSCP,
+		ReusePort: cfg.ReusePort,
+		Interface: cfg.Interface,
+	}
+	return options, options.Validate()
+}
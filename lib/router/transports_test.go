@@ -0,0 +1,32 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/transport/sockopt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouterSocketOptions(t *testing.T) {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Transport:  &config.TransportConfig{DSCP: 46},
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	options, err := r.SocketOptions()
+	require.NoError(t, err)
+	assert.Equal(t, sockopt.Options{DSCP: 46}, options)
+}
+
+func TestRouterRejectsInvalidDSCP(t *testing.T) {
+	_, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Transport:  &config.TransportConfig{DSCP: 64},
+	})
+	assert.ErrorIs(t, err, sockopt.ErrInvalidDSCP)
+}
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+)
+
+// stat reporting the warm tunnels ready to be adopted
+const STAT_WARM_TUNNELS = "router.tunnels.warm"
+
+// stats reporting how remote leases were chosen
+const (
+	STAT_LEASE_SELECTIONS = "router.leases.selections"
+	STAT_LEASE_STICKY     = "router.leases.sticky"
+	STAT_LEASE_SWITCHES   = "router.leases.switches"
+)
+
+// tunnelConfig returns the client tunnel configuration
+func (r *Router) tunnelConfig() config.TunnelConfig {
+	if r.cfg != nil && r.cfg.Tunnels != nil {
+		return *r.cfg.Tunnels
+	}
+	return config.DefaultTunnelConfig
+}
+
+// SetTunnelBuilder sets how outbound client tunnels are built for the warm
+// pool. It has to be called before Start, without a builder the warm pool
+// stays empty.
+func (r *Router) SetTunnelBuilder(build tunnel.BuildFunc) {
+	r.tunnelBuilder = build
+}
+
+// startWarmPool starts building the warm client tunnels, if configured
+func (r *Router) startWarmPool() {
+	size := r.tunnelConfig().WarmPool
+	if size <= 0 || r.AllowTunnels() != nil {
+		return
+	}
+	if r.tunnelBuilder == nil {
+		log.WithField("size", size).Warn("No tunnel builder, warm tunnel pool disabled")
+		return
+	}
+	r.warmPool = tunnel.NewWarmPool(size, r.poolBuilder(r.tunnelBuilder))
+	r.RegisterStat(STAT_WARM_TUNNELS, func() interface{} {
+		return r.warmPool.Ready()
+	})
+	r.warmPool.Start()
+}
+
+// poolBuilder returns build run on the tunnel build workers. The warm pool
+// starts all missing builds at once, the pool size bounds how many run.
+func (r *Router) poolBuilder(build tunnel.BuildFunc) tunnel.BuildFunc {
+	return func() (built *tunnel.BuiltTunnel, err error) {
+		if poolErr := r.runOn(WORKERS_TUNNEL_BUILD, func() {
+			built, err = build()
+		}); poolErr != nil {
+			return nil, poolErr
+		}
+		return built, err
+	}
+}
+
+// stopWarmPool stops the warm pool started by startWarmPool
+func (r *Router) stopWarmPool() {
+	if r.warmPool != nil {
+		r.warmPool.Stop()
+	}
+}
+
+// AdoptWarmTunnel hands a new client session a tunnel from the warm pool, so
+// it can send before its own tunnels are built. It returns false if the warm
+// pool is disabled or empty.
+func (r *Router) AdoptWarmTunnel() (*tunnel.BuiltTunnel, bool) {
+	if r.warmPool == nil {
+		return nil, false
+	}
+	return r.warmPool.Adopt()
+}
+
+// initLeaseSelector creates the lease selector from the configured strategy
+func (r *Router) initLeaseSelector() error {
+	cfg := r.tunnelConfig()
+	strategy := cfg.LeaseStrategy
+	if strategy == "" {
+		strategy = config.DefaultTunnelConfig.LeaseStrategy
+	}
+	selector, err := tunnel.NewLeaseSelector(strategy, cfg.LeaseStickiness)
+	if err != nil {
+		return err
+	}
+	selector.SetGrace(cfg.LeaseGrace)
+	r.leaseSelector = selector
+	r.RegisterStat(STAT_LEASE_SELECTIONS, func() interface{} {
+		return selector.Stats().Selections
+	})
+	r.RegisterStat(STAT_LEASE_STICKY, func() interface{} {
+		return selector.Stats().StickyHits
+	})
+	r.RegisterStat(STAT_LEASE_SWITCHES, func() interface{} {
+		return selector.Stats().Switches
+	})
+	return nil
+}
+
+// LeaseSelector returns the selector choosing which lease of a remote
+// LeaseSet outbound messages go to. Send paths share it, so a destination
+// keeps its lease across sessions.
+func (r *Router) LeaseSelector() *tunnel.LeaseSelector {
+	return r.leaseSelector
+}
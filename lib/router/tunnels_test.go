@@ -0,0 +1,69 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWarmPoolTestRouter(t *testing.T, size int, observer bool) *Router {
+	r, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Workers:    &config.WorkerConfig{NetDb: 1, TunnelBuild: 1},
+		Tunnels:    &config.TunnelConfig{WarmPool: size},
+		Observer:   observer,
+	})
+	require.NoError(t, err)
+	t.Cleanup(r.closeWorkers)
+	return r
+}
+
+func TestWarmTunnelPool(t *testing.T) {
+	var id uint32
+	build := func() (*tunnel.BuiltTunnel, error) {
+		id++
+		return &tunnel.BuiltTunnel{ID: id, Expiration: time.Now().Add(tunnel.TUNNEL_LIFETIME)}, nil
+	}
+
+	r := newWarmPoolTestRouter(t, 2, false)
+	r.SetTunnelBuilder(build)
+	r.startWarmPool()
+	t.Cleanup(r.stopWarmPool)
+	require.Eventually(t, func() bool { return r.Stats(STAT_WARM_TUNNELS)[STAT_WARM_TUNNELS] == 2 }, time.Second, time.Millisecond)
+	warm, ok := r.AdoptWarmTunnel()
+	require.True(t, ok)
+	assert.NotZero(t, warm.ID)
+
+	// disabled without a builder, in observer mode and by default
+	for _, r := range []*Router{
+		newWarmPoolTestRouter(t, 2, false),
+		newWarmPoolTestRouter(t, 2, true),
+		newWarmPoolTestRouter(t, 0, false),
+	} {
+		if r.Observer() {
+			r.SetTunnelBuilder(build)
+		}
+		r.startWarmPool()
+		_, ok := r.AdoptWarmTunnel()
+		assert.False(t, ok)
+		assert.Nil(t, r.warmPool)
+	}
+}
+
+func TestLeaseSelectorFromConfig(t *testing.T) {
+	// an empty strategy falls back to the default
+	r := newWarmPoolTestRouter(t, 0, false)
+	require.NotNil(t, r.LeaseSelector())
+	assert.Equal(t, config.DefaultTunnelConfig.LeaseStrategy, r.LeaseSelector().Strategy())
+	assert.Equal(t, uint64(0), r.Stats(STAT_LEASE_SELECTIONS)[STAT_LEASE_SELECTIONS])
+
+	_, err := FromConfig(&config.RouterConfig{
+		WorkingDir: t.TempDir(),
+		Tunnels:    &config.TunnelConfig{LeaseStrategy: "fastest"},
+	})
+	assert.ErrorIs(t, err, tunnel.ErrUnknownLeaseStrategy)
+}
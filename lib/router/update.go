@@ -0,0 +1,98 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/update"
+)
+
+// how long a single update check may take, fetching over I2P is slow
+const updateCheckTimeout = 10 * time.Minute
+
+// startUpdateChecks periodically checks for router updates and stages them
+// for the next restart, if updates are enabled
+func (r *Router) startUpdateChecks() {
+	if r.cfg == nil || r.cfg.Update == nil || !r.cfg.Update.Enabled || r.cfg.Update.URL == "" {
+		return
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		log.WithError(err).Error("Cannot find router executable, updates disabled")
+		return
+	}
+	keys, err := update.EmbeddedKeys()
+	if err != nil {
+		r.countError("update")
+		log.WithError(err).Error("Failed to load release signing keys, updates disabled")
+		return
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if r.cfg.Update.Proxy != "" {
+		proxy, err := url.Parse(r.cfg.Update.Proxy)
+		if err != nil {
+			log.WithError(err).Error("Invalid update proxy, updates disabled")
+			return
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	updater := &update.Updater{
+		URL:    r.cfg.Update.URL,
+		Client: &http.Client{Transport: transport},
+		Keys:   keys,
+	}
+	interval := r.cfg.Update.CheckInterval
+	if interval <= 0 {
+		interval = config.DefaultUpdateConfig.CheckInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.updateCancel = cancel
+	r.updateDone = make(chan struct{})
+	go r.updateLoop(ctx, updater, executable, interval, r.updateDone)
+}
+
+// stopUpdateChecks stops the update loop started by startUpdateChecks,
+// aborting a check in progress, and waits for it to exit so nothing is
+// staged after the router closed
+func (r *Router) stopUpdateChecks() {
+	if r.updateCancel != nil {
+		r.updateCancel()
+		<-r.updateDone
+		r.updateCancel = nil
+	}
+}
+
+func (r *Router) updateLoop(ctx context.Context, updater *update.Updater, executable string, interval time.Duration, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, updateCheckTimeout)
+		available, err := updater.Check(checkCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			r.countError("update")
+			continue
+		}
+		if available == nil {
+			continue
+		}
+		if err := update.Stage(executable, available); err != nil {
+			r.countError("update")
+			continue
+		}
+		log.WithField("version", available.Version).Info("Router update will be installed on restart")
+	}
+}
@@ -0,0 +1,50 @@
+package router
+
+import (
+	"context"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-i2p/go-i2p/lib/update"
+)
+
+func TestStopUpdateChecksCancelsCheck(t *testing.T) {
+	r := newStatsTestRouter(t)
+	requested := make(chan struct{})
+	cancelled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		close(requested)
+		<-req.Context().Done()
+		close(cancelled)
+	}))
+	defer server.Close()
+	updater := &update.Updater{
+		URL:  server.URL,
+		Keys: map[string]*rsa.PublicKey{"release@mail.i2p": {}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.updateCancel = cancel
+	r.updateDone = make(chan struct{})
+	go r.updateLoop(ctx, updater, t.TempDir()+"/go-i2p", time.Millisecond, r.updateDone)
+	<-requested
+
+	stopped := make(chan struct{})
+	go func() {
+		r.stopUpdateChecks()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stopping did not abort the update check")
+	}
+	<-cancelled
+	assert.Nil(t, r.updateCancel)
+	assert.Zero(t, r.Report().Errors["update"], "an aborted check is not an error")
+}
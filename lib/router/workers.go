@@ -0,0 +1,145 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/util/workers"
+	"github.com/sirupsen/logrus"
+)
+
+// names of the router's worker pools
+const (
+	// reads the netdb files on startup and stores LeaseSets to floodfills
+	WORKERS_NETDB = "netdb"
+	// builds the warm client tunnels
+	WORKERS_TUNNEL_BUILD = "tunnel_build"
+)
+
+// queue length per worker for each pool
+const workerQueueFactor = 16
+
+// workerSizes maps the names of the router's pools onto the sizes in cfg.
+// Observers have no tunnel build pool, they never build or take part in
+// tunnels.
+func (r *Router) workerSizes(cfg config.WorkerConfig) map[string]int {
+	sizes := map[string]int{
+		WORKERS_NETDB: cfg.NetDb,
+	}
+	if !r.Observer() {
+		sizes[WORKERS_TUNNEL_BUILD] = cfg.TunnelBuild
+	}
+	return sizes
+}
+
+// initWorkers creates the worker pools sized from the router configuration
+func (r *Router) initWorkers() error {
+	cfg := config.DefaultWorkersConfig
+	if r.cfg != nil && r.cfg.Workers != nil {
+		cfg = *r.cfg.Workers
+	}
+	r.workersMutex.Lock()
+	defer r.workersMutex.Unlock()
+	r.workers = make(map[string]*workers.Pool)
+	for name, size := range r.workerSizes(cfg) {
+		pool, err := workers.NewPool(name, size, size*workerQueueFactor)
+		if err != nil {
+			r.closeWorkersLocked()
+			return fmt.Errorf("creating %s worker pool: %w", name, err)
+		}
+		r.workers[name] = pool
+	}
+	return nil
+}
+
+// closeWorkers stops every worker pool
+func (r *Router) closeWorkers() {
+	r.workersMutex.Lock()
+	defer r.workersMutex.Unlock()
+	r.closeWorkersLocked()
+}
+
+// closeWorkersLocked stops every worker pool, the caller holds the lock
+func (r *Router) closeWorkersLocked() {
+	for name, pool := range r.workers {
+		pool.Close()
+		delete(r.workers, name)
+	}
+}
+
+// WorkerPool returns the named worker pool, or nil if there is none
+func (r *Router) WorkerPool(name string) *workers.Pool {
+	r.workersMutex.RLock()
+	defer r.workersMutex.RUnlock()
+	return r.workers[name]
+}
+
+// spawner returns a function starting tasks on the named worker pool without
+// waiting for them, for the netdb to run its reads and stores on. The pool is
+// looked up for every task, so it follows the router closing its pools.
+func (r *Router) spawner(name string) func(task func()) error {
+	return func(task func()) error {
+		pool := r.WorkerPool(name)
+		if pool == nil {
+			return fmt.Errorf("no %s worker pool", name)
+		}
+		return pool.Submit(task)
+	}
+}
+
+// runOn runs task on the named worker pool and waits for it to finish, for
+// callers which each run on their own goroutine and need the result. Without
+// such a pool, as for tunnel builds of an observer, task runs on the calling
+// goroutine. Returns workers.ErrPoolClosed once the router was closed.
+func (r *Router) runOn(name string, task func()) error {
+	pool := r.WorkerPool(name)
+	if pool == nil {
+		task()
+		return nil
+	}
+	done := make(chan struct{})
+	if err := pool.Submit(func() {
+		defer close(done)
+		task()
+	}); err != nil {
+		return err
+	}
+	<-done
+	return nil
+}
+
+// WorkerSizes returns the current number of workers in each pool
+func (r *Router) WorkerSizes() map[string]int {
+	r.workersMutex.RLock()
+	defer r.workersMutex.RUnlock()
+	sizes := make(map[string]int, len(r.workers))
+	for name, pool := range r.workers {
+		sizes[name] = pool.Size()
+	}
+	return sizes
+}
+
+// SetWorkerSize resizes the named worker pool while the router is running
+func (r *Router) SetWorkerSize(name string, size int) error {
+	r.workersMutex.RLock()
+	pool, ok := r.workers[name]
+	r.workersMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown worker pool: %s", name)
+	}
+	log.WithFields(logrus.Fields{
+		"pool": name,
+		"size": size,
+	}).Debug("Resizing worker pool")
+	return pool.Resize(size)
+}
+
+// ApplyWorkerConfig resizes all worker pools to match cfg
+func (r *Router) ApplyWorkerConfig(cfg config.WorkerConfig) error {
+	for name, size := range r.workerSizes(cfg) {
+		if err := r.SetWorkerSize(name, size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package sim runs many router nodes in a single process against a virtual
+// clock and an in-memory network, so tunnel building, netdb convergence and
+// congestion can be tested at scale and reproduced exactly from a seed.
+//
+// Simulations are event driven and single threaded: nodes react to delivered
+// messages and timers from within Run, and nothing happens between events.
+// The same seed and inputs always give the same sequence of events.
+package sim
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Clock reports the current time. Code which should run in simulations reads
+// time from a Clock instead of calling time.Now.
+type Clock interface {
+	Now() time.Time
+}
+
+// RealClock is the wall clock.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// event is a function scheduled to run at a virtual time
+type event struct {
+	at time.Time
+	// seq orders events scheduled for the same time by when they were scheduled
+	seq uint64
+	fn  func()
+}
+
+type eventQueue []*event
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].at.Before(q[j].at)
+}
+func (q eventQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*event)) }
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// VirtualClock is a Clock which only moves when its scheduled events run.
+type VirtualClock struct {
+	now    time.Time
+	seq    uint64
+	events eventQueue
+}
+
+// NewVirtualClock returns a clock standing at start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+// Now returns the virtual time.
+func (clock *VirtualClock) Now() time.Time {
+	return clock.now
+}
+
+// AfterFunc schedules fn to run d after the current virtual time. A negative
+// d runs fn at the current time, after the events already due.
+func (clock *VirtualClock) AfterFunc(d time.Duration, fn func()) {
+	if d < 0 {
+		d = 0
+	}
+	clock.seq++
+	heap.Push(&clock.events, &event{at: clock.now.Add(d), seq: clock.seq, fn: fn})
+}
+
+// Pending returns the number of scheduled events.
+func (clock *VirtualClock) Pending() int {
+	return len(clock.events)
+}
+
+// Step runs the next event, moving the clock to its time, and returns false
+// if there was none.
+func (clock *VirtualClock) Step() bool {
+	if len(clock.events) == 0 {
+		return false
+	}
+	e := heap.Pop(&clock.events).(*event)
+	clock.now = e.at
+	e.fn()
+	return true
+}
+
+// RunUntil runs the events due up to end and leaves the clock at end.
+// Returns the number of events run.
+func (clock *VirtualClock) RunUntil(end time.Time) (ran int) {
+	for len(clock.events) > 0 && !clock.events[0].at.After(end) {
+		clock.Step()
+		ran++
+	}
+	if end.After(clock.now) {
+		clock.now = end
+	}
+	return
+}
+
+// Run runs events until none are left or limit events ran, 0 for no limit,
+// and returns the number of events run. Nodes rescheduling timers forever
+// need a limit or RunUntil.
+func (clock *VirtualClock) Run(limit int) (ran int) {
+	for (limit <= 0 || ran < limit) && clock.Step() {
+		ran++
+	}
+	return
+}
@@ -0,0 +1,186 @@
+package sim
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+var (
+	ErrUnknownNode   = errors.New("unknown simulated node")
+	ErrDuplicateNode = errors.New("simulated node already exists")
+)
+
+// Link describes the path between two nodes.
+type Link struct {
+	// Latency is the one way delay of every message
+	Latency time.Duration
+	// Jitter adds a uniformly random delay of up to Jitter
+	Jitter time.Duration
+	// Loss is the probability from 0 to 1 a message is dropped
+	Loss float64
+	// Bandwidth is the sender's uplink in bytes per second, 0 for unlimited.
+	// Messages queue behind each other on a limited uplink.
+	Bandwidth int
+}
+
+// Handler receives the messages delivered to a node.
+type Handler interface {
+	// HandleMessage is called with a message from another node
+	HandleMessage(from common.Hash, data []byte)
+}
+
+// HandlerFunc adapts a function to a Handler.
+type HandlerFunc func(from common.Hash, data []byte)
+
+// HandleMessage calls f.
+func (f HandlerFunc) HandleMessage(from common.Hash, data []byte) {
+	f(from, data)
+}
+
+// NetworkStats counts the messages passed through a Network.
+type NetworkStats struct {
+	Sent      uint64
+	Delivered uint64
+	Dropped   uint64
+	Bytes     uint64
+}
+
+type node struct {
+	handler Handler
+	// the uplink is busy sending until then
+	busyUntil time.Time
+	down      bool
+}
+
+type linkKey struct {
+	from, to common.Hash
+}
+
+// Network is an in-memory network of nodes driven by a VirtualClock. All
+// randomness comes from the seed, so a simulation replays exactly.
+type Network struct {
+	clock    *VirtualClock
+	rand     *rand.Rand
+	defaults Link
+	links    map[linkKey]Link
+	nodes    map[common.Hash]*node
+	stats    NetworkStats
+}
+
+// NewNetwork returns an empty network on clock using defaults for every link
+// not set with SetLink.
+func NewNetwork(clock *VirtualClock, seed int64, defaults Link) *Network {
+	return &Network{
+		clock:    clock,
+		rand:     rand.New(rand.NewSource(seed)),
+		defaults: defaults,
+		links:    make(map[linkKey]Link),
+		nodes:    make(map[common.Hash]*node),
+	}
+}
+
+// Clock returns the clock of the network.
+func (network *Network) Clock() *VirtualClock {
+	return network.clock
+}
+
+// Rand returns the seeded source of the network, for nodes which need
+// randomness and must stay reproducible.
+func (network *Network) Rand() *rand.Rand {
+	return network.rand
+}
+
+// AddNode attaches handler to the network as hash.
+func (network *Network) AddNode(hash common.Hash, handler Handler) error {
+	if _, ok := network.nodes[hash]; ok {
+		return ErrDuplicateNode
+	}
+	network.nodes[hash] = &node{handler: handler}
+	return nil
+}
+
+// SetDown takes a node off the network, messages to and from it are dropped,
+// or puts it back.
+func (network *Network) SetDown(hash common.Hash, down bool) error {
+	n, ok := network.nodes[hash]
+	if !ok {
+		return ErrUnknownNode
+	}
+	n.down = down
+	return nil
+}
+
+// SetLink overrides the link from one node to another. Links are one way.
+func (network *Network) SetLink(from, to common.Hash, link Link) {
+	network.links[linkKey{from, to}] = link
+}
+
+// Send queues data from one node to another. Delivery happens from the
+// clock's event loop after the link's delay, unless the message is lost or
+// either node is down. data must not be modified after the call.
+func (network *Network) Send(from, to common.Hash, data []byte) error {
+	sender, ok := network.nodes[from]
+	if !ok {
+		return ErrUnknownNode
+	}
+	if _, ok := network.nodes[to]; !ok {
+		return ErrUnknownNode
+	}
+	network.stats.Sent++
+	network.stats.Bytes += uint64(len(data))
+	link, ok := network.links[linkKey{from, to}]
+	if !ok {
+		link = network.defaults
+	}
+	// draw the random numbers for every message, so a message being dropped
+	// does not shift those of the messages after it
+	lost := network.rand.Float64() < link.Loss
+	var jitter time.Duration
+	if link.Jitter > 0 {
+		jitter = time.Duration(network.rand.Int63n(int64(link.Jitter)))
+	}
+	if sender.down || lost {
+		network.drop(from, to, "lost")
+		return nil
+	}
+	now := network.clock.Now()
+	departure := now
+	if link.Bandwidth > 0 {
+		if sender.busyUntil.After(departure) {
+			departure = sender.busyUntil
+		}
+		departure = departure.Add(time.Duration(len(data)) * time.Second / time.Duration(link.Bandwidth))
+		sender.busyUntil = departure
+	}
+	network.clock.AfterFunc(departure.Sub(now)+link.Latency+jitter, func() {
+		receiver := network.nodes[to]
+		if receiver.down {
+			network.drop(from, to, "receiver down")
+			return
+		}
+		network.stats.Delivered++
+		receiver.handler.HandleMessage(from, data)
+	})
+	return nil
+}
+
+func (network *Network) drop(from, to common.Hash, reason string) {
+	network.stats.Dropped++
+	if log.TraceEnabled() {
+		log.WithFields(logrus.Fields{
+			"from":   from,
+			"to":     to,
+			"reason": reason,
+		}).Trace("Dropped simulated message")
+	}
+}
+
+// Stats returns the message counters of the network.
+func (network *Network) Stats() NetworkStats {
+	return network.stats
+}
@@ -0,0 +1,166 @@
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func nodeHash(i int) common.Hash {
+	return common.HashData([]byte{byte(i >> 8), byte(i)})
+}
+
+func TestVirtualClockOrdersEvents(t *testing.T) {
+	clock := NewVirtualClock(epoch)
+	var order []int
+	clock.AfterFunc(2*time.Second, func() { order = append(order, 3) })
+	clock.AfterFunc(time.Second, func() { order = append(order, 1) })
+	clock.AfterFunc(time.Second, func() {
+		order = append(order, 2)
+		clock.AfterFunc(0, func() { order = append(order, 4) })
+	})
+
+	// events scheduled for now by a running event still run in this pass
+	assert.Equal(t, 3, clock.RunUntil(epoch.Add(time.Second)))
+	assert.Equal(t, []int{1, 2, 4}, order)
+	assert.Equal(t, epoch.Add(time.Second), clock.Now())
+	assert.Equal(t, 1, clock.Run(0))
+	assert.Equal(t, []int{1, 2, 4, 3}, order)
+	assert.Equal(t, epoch.Add(2*time.Second), clock.Now())
+	assert.False(t, clock.Step())
+}
+
+func TestNetworkLatencyAndBandwidth(t *testing.T) {
+	clock := NewVirtualClock(epoch)
+	network := NewNetwork(clock, 1, Link{Latency: 50 * time.Millisecond, Bandwidth: 1000})
+	var arrivals []time.Duration
+	require.NoError(t, network.AddNode(nodeHash(0), HandlerFunc(func(common.Hash, []byte) {})))
+	require.NoError(t, network.AddNode(nodeHash(1), HandlerFunc(func(from common.Hash, data []byte) {
+		assert.Equal(t, nodeHash(0), from)
+		arrivals = append(arrivals, clock.Now().Sub(epoch))
+	})))
+	assert.ErrorIs(t, network.AddNode(nodeHash(1), nil), ErrDuplicateNode)
+
+	// two 100 byte messages queue on a 1000 B/s uplink
+	require.NoError(t, network.Send(nodeHash(0), nodeHash(1), make([]byte, 100)))
+	require.NoError(t, network.Send(nodeHash(0), nodeHash(1), make([]byte, 100)))
+	assert.ErrorIs(t, network.Send(nodeHash(0), nodeHash(2), nil), ErrUnknownNode)
+	clock.Run(0)
+	assert.Equal(t, []time.Duration{150 * time.Millisecond, 250 * time.Millisecond}, arrivals)
+
+	require.NoError(t, network.SetDown(nodeHash(1), true))
+	require.NoError(t, network.Send(nodeHash(0), nodeHash(1), []byte{1}))
+	clock.Run(0)
+	assert.Equal(t, NetworkStats{Sent: 3, Delivered: 2, Dropped: 1, Bytes: 201}, network.Stats())
+}
+
+// floodNode gossips every hash it learns to its peers, a stand-in for
+// netdb flooding. Every second it also sends all it knows to a random peer,
+// repairing what was lost.
+type floodNode struct {
+	hash    common.Hash
+	network *Network
+	peers   []common.Hash
+	known   map[common.Hash]bool
+	// known hashes in the order learnt, maps iterate randomly
+	order []common.Hash
+	// called once the node knows every hash
+	complete func()
+	total    int
+}
+
+func (node *floodNode) learn(hash common.Hash) {
+	if node.known[hash] {
+		return
+	}
+	node.known[hash] = true
+	node.order = append(node.order, hash)
+	if len(node.order) == node.total {
+		node.complete()
+	}
+	for _, peer := range node.peers {
+		node.network.Send(node.hash, peer, hash[:])
+	}
+}
+
+func (node *floodNode) HandleMessage(_ common.Hash, data []byte) {
+	for len(data) >= len(common.Hash{}) {
+		var hash common.Hash
+		copy(hash[:], data)
+		node.learn(hash)
+		data = data[len(hash):]
+	}
+}
+
+func (node *floodNode) exchange() {
+	var data []byte
+	for _, hash := range node.order {
+		data = append(data, hash[:]...)
+	}
+	peer := node.peers[node.network.Rand().Intn(len(node.peers))]
+	node.network.Send(node.hash, peer, data)
+	node.network.Clock().AfterFunc(time.Second, node.exchange)
+}
+
+// runFlood simulates n nodes each flooding its own hash to its ring
+// neighbour and 3 random peers, and returns when every node knew every hash
+// and the network stats
+func runFlood(t *testing.T, seed int64, n int) (time.Duration, NetworkStats) {
+	clock := NewVirtualClock(epoch)
+	network := NewNetwork(clock, seed, Link{
+		Latency: 20 * time.Millisecond,
+		Jitter:  80 * time.Millisecond,
+		Loss:    0.05,
+	})
+	nodes := make([]*floodNode, n)
+	completed := 0
+	var converged time.Duration
+	for i := range nodes {
+		nodes[i] = &floodNode{
+			hash:    nodeHash(i),
+			network: network,
+			known:   make(map[common.Hash]bool),
+			total:   n,
+			complete: func() {
+				if completed++; completed == n {
+					converged = clock.Now().Sub(epoch)
+				}
+			},
+		}
+		require.NoError(t, network.AddNode(nodes[i].hash, nodes[i]))
+	}
+	for i, node := range nodes {
+		node.peers = append(node.peers, nodes[(i+1)%n].hash)
+		for len(node.peers) < 4 {
+			peer := nodes[network.Rand().Intn(n)].hash
+			if peer != node.hash {
+				node.peers = append(node.peers, peer)
+			}
+		}
+	}
+	for _, node := range nodes {
+		node.learn(node.hash)
+		clock.AfterFunc(time.Second, node.exchange)
+	}
+	for converged == 0 && clock.Now().Before(epoch.Add(time.Minute)) && clock.Step() {
+	}
+	require.NotZero(t, converged, "netdb did not converge")
+	return converged, network.Stats()
+}
+
+func TestFloodConvergenceIsReproducible(t *testing.T) {
+	converged, stats := runFlood(t, 42, 200)
+	again, againStats := runFlood(t, 42, 200)
+	assert.Equal(t, converged, again)
+	assert.Equal(t, stats, againStats)
+	assert.NotZero(t, stats.Dropped)
+
+	_, otherStats := runFlood(t, 7, 200)
+	assert.NotEqual(t, stats, otherStats)
+}
@@ -0,0 +1,257 @@
+package streaming
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
+)
+
+// Access list modes of a server tunnel
+const (
+	// ACCESS_MODE_OFF accepts every client
+	ACCESS_MODE_OFF = "off"
+	// ACCESS_MODE_WHITELIST accepts only the listed clients
+	ACCESS_MODE_WHITELIST = "whitelist"
+	// ACCESS_MODE_BLACKLIST accepts every client but the listed ones
+	ACCESS_MODE_BLACKLIST = "blacklist"
+)
+
+// Server tunnel options read by ParseAccessOptions, named as in the
+// tunnel configuration of the Java router
+const (
+	OPTION_ENABLE_ACCESS_LIST = "i2cp.enableAccessList"
+	OPTION_ENABLE_BLACK_LIST  = "i2cp.enableBlackList"
+	OPTION_ACCESS_LIST        = "i2cp.accessList"
+	OPTION_MAX_CONNS_PER_MIN  = "i2p.streaming.maxConnsPerMinute"
+	OPTION_MAX_CONNS_PER_HOUR = "i2p.streaming.maxConnsPerHour"
+)
+
+// windows of the connection rate limits, and how often clients which went
+// quiet are forgotten
+const (
+	accessRateWindowMinute = time.Minute
+	accessRateWindowHour   = time.Hour
+	accessSweepInterval    = time.Minute
+)
+
+var (
+	ErrAccessDenied      = errors.New("client destination is not allowed")
+	ErrRateLimited       = errors.New("client destination exceeded its connection rate")
+	ErrUnknownAccessMode = errors.New("unknown access list mode")
+)
+
+// AccessList decides which client destinations a server tunnel accepts
+// streams from: a whitelist or blacklist of destination hashes, and limits
+// on how many streams each client may open per minute and per hour. It is a
+// gate for the accept path of server tunnels, which must call Allow for every
+// incoming SYN; this package has no accept path of its own yet. The list,
+// mode and limits can be changed through its methods while a tunnel runs;
+// exposing them to operators, on the management API or elsewhere, is left to
+// the server tunnels. It is safe for concurrent use.
+type AccessList struct {
+	mutex   sync.Mutex
+	mode    string
+	hashes  map[common.Hash]bool
+	perMin  int
+	perHour int
+	// accepted holds the accept times of each client within the last hour
+	accepted  map[common.Hash][]time.Time
+	lastSweep time.Time
+}
+
+// NewAccessList returns an access list with one of the ACCESS_MODE_* modes
+// over hashes, and no rate limits.
+func NewAccessList(mode string, hashes []common.Hash) (*AccessList, error) {
+	list := &AccessList{
+		hashes:   make(map[common.Hash]bool),
+		accepted: make(map[common.Hash][]time.Time),
+	}
+	if err := list.SetMode(mode); err != nil {
+		return nil, err
+	}
+	for _, hash := range hashes {
+		list.hashes[hash] = true
+	}
+	return list, nil
+}
+
+// ParseAccessOptions builds the access list of a server tunnel from its
+// options, without the tunnel.N.option. prefix. The access list takes
+// base32 or base64 hashes separated by commas or spaces.
+func ParseAccessOptions(options map[string]string) (*AccessList, error) {
+	mode := ACCESS_MODE_OFF
+	if options[OPTION_ENABLE_ACCESS_LIST] == "true" {
+		mode = ACCESS_MODE_WHITELIST
+	} else if options[OPTION_ENABLE_BLACK_LIST] == "true" {
+		mode = ACCESS_MODE_BLACKLIST
+	}
+	var hashes []common.Hash
+	for _, entry := range strings.FieldsFunc(options[OPTION_ACCESS_LIST], func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		hash, err := ParseAccessHash(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", OPTION_ACCESS_LIST, err)
+		}
+		hashes = append(hashes, hash)
+	}
+	list, err := NewAccessList(mode, hashes)
+	if err != nil {
+		return nil, err
+	}
+	perMin, err := parseRateOption(options, OPTION_MAX_CONNS_PER_MIN)
+	if err != nil {
+		return nil, err
+	}
+	perHour, err := parseRateOption(options, OPTION_MAX_CONNS_PER_HOUR)
+	if err != nil {
+		return nil, err
+	}
+	list.SetRateLimits(perMin, perHour)
+	return list, nil
+}
+
+// parseRateOption reads a connection limit, 0 if unset
+func parseRateOption(options map[string]string, name string) (int, error) {
+	value, ok := options[name]
+	if !ok || value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", name, value)
+	}
+	return limit, nil
+}
+
+// ParseAccessHash parses a client destination hash in base32, with or
+// without the .b32.i2p suffix, or in base64.
+func ParseAccessHash(s string) (common.Hash, error) {
+	if hash, err := common.ParseHashBase32(s); err == nil {
+		return hash, nil
+	}
+	return common.ParseHashBase64(s)
+}
+
+// Mode returns the ACCESS_MODE_* mode in use.
+func (list *AccessList) Mode() string {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	return list.mode
+}
+
+// SetMode switches between the ACCESS_MODE_* modes, keeping the list.
+func (list *AccessList) SetMode(mode string) error {
+	switch mode {
+	case ACCESS_MODE_OFF, ACCESS_MODE_WHITELIST, ACCESS_MODE_BLACKLIST:
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAccessMode, mode)
+	}
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	list.mode = mode
+	return nil
+}
+
+// Add puts hash on the list.
+func (list *AccessList) Add(hash common.Hash) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	list.hashes[hash] = true
+}
+
+// Remove takes hash off the list.
+func (list *AccessList) Remove(hash common.Hash) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	delete(list.hashes, hash)
+}
+
+// Hashes returns the listed hashes, sorted by their base32 form.
+func (list *AccessList) Hashes() []common.Hash {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	hashes := make([]common.Hash, 0, len(list.hashes))
+	for hash := range list.hashes {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return hashes[i].Base32() < hashes[j].Base32()
+	})
+	return hashes
+}
+
+// SetRateLimits sets how many streams each client may open per minute and
+// per hour, 0 for no limit.
+func (list *AccessList) SetRateLimits(perMinute, perHour int) {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	list.perMin = perMinute
+	list.perHour = perHour
+}
+
+// Allow decides whether a stream from the client destination is accepted
+// at now, returning ErrAccessDenied or ErrRateLimited if not. Accepted
+// streams count towards the rate limits.
+func (list *AccessList) Allow(client common.Hash, now time.Time) error {
+	list.mutex.Lock()
+	defer list.mutex.Unlock()
+	listed := list.hashes[client]
+	if (list.mode == ACCESS_MODE_WHITELIST && !listed) || (list.mode == ACCESS_MODE_BLACKLIST && listed) {
+		log.WithFields(logrus.Fields{
+			"client": client.Base32Address(),
+			"mode":   list.mode,
+		}).Debug("Rejected stream from client not allowed by the access list")
+		return ErrAccessDenied
+	}
+	if list.perMin <= 0 && list.perHour <= 0 {
+		return nil
+	}
+	list.sweep(now)
+	accepted := recentAccepts(list.accepted[client], now.Add(-accessRateWindowHour))
+	if exceeds(accepted, now.Add(-accessRateWindowMinute), list.perMin) ||
+		exceeds(accepted, now.Add(-accessRateWindowHour), list.perHour) {
+		list.accepted[client] = accepted
+		log.WithField("client", client.Base32Address()).Debug("Rejected stream from client over its connection rate")
+		return ErrRateLimited
+	}
+	list.accepted[client] = append(accepted, now)
+	return nil
+}
+
+// sweep drops clients which opened no stream within the last hour, at most
+// once per accessSweepInterval. Callers must hold the lock.
+func (list *AccessList) sweep(now time.Time) {
+	if now.Sub(list.lastSweep) < accessSweepInterval {
+		return
+	}
+	list.lastSweep = now
+	since := now.Add(-accessRateWindowHour)
+	for client, accepted := range list.accepted {
+		if len(recentAccepts(accepted, since)) == 0 {
+			delete(list.accepted, client)
+		}
+	}
+}
+
+// recentAccepts returns the accept times after since
+func recentAccepts(accepted []time.Time, since time.Time) []time.Time {
+	for i, t := range accepted {
+		if t.After(since) {
+			return accepted[i:]
+		}
+	}
+	return nil
+}
+
+// exceeds reports whether limit streams were accepted after since
+func exceeds(accepted []time.Time, since time.Time, limit int) bool {
+	return limit > 0 && len(recentAccepts(accepted, since)) >= limit
+}
@@ -0,0 +1,99 @@
+package streaming
+
+import (
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func accessHash(b byte) common.Hash {
+	var hash common.Hash
+	hash[0] = b
+	return hash
+}
+
+func TestAccessListModes(t *testing.T) {
+	now := time.Now()
+	friend, stranger := accessHash(1), accessHash(2)
+
+	list, err := NewAccessList(ACCESS_MODE_WHITELIST, []common.Hash{friend})
+	require.NoError(t, err)
+	assert.NoError(t, list.Allow(friend, now))
+	assert.ErrorIs(t, list.Allow(stranger, now), ErrAccessDenied)
+
+	require.NoError(t, list.SetMode(ACCESS_MODE_BLACKLIST))
+	assert.ErrorIs(t, list.Allow(friend, now), ErrAccessDenied)
+	assert.NoError(t, list.Allow(stranger, now))
+
+	// managed at runtime
+	list.Add(stranger)
+	list.Remove(friend)
+	assert.Equal(t, []common.Hash{stranger}, list.Hashes())
+	assert.NoError(t, list.Allow(friend, now))
+	assert.ErrorIs(t, list.Allow(stranger, now), ErrAccessDenied)
+
+	require.NoError(t, list.SetMode(ACCESS_MODE_OFF))
+	assert.NoError(t, list.Allow(stranger, now))
+
+	assert.ErrorIs(t, list.SetMode("greylist"), ErrUnknownAccessMode)
+	assert.Equal(t, ACCESS_MODE_OFF, list.Mode())
+	_, err = NewAccessList("greylist", nil)
+	assert.ErrorIs(t, err, ErrUnknownAccessMode)
+}
+
+func TestAccessListRateLimits(t *testing.T) {
+	now := time.Now()
+	client, other := accessHash(1), accessHash(2)
+	list, err := NewAccessList(ACCESS_MODE_OFF, nil)
+	require.NoError(t, err)
+	list.SetRateLimits(2, 3)
+
+	assert.NoError(t, list.Allow(client, now))
+	assert.NoError(t, list.Allow(client, now.Add(time.Second)))
+	assert.ErrorIs(t, list.Allow(client, now.Add(2*time.Second)), ErrRateLimited)
+	assert.NoError(t, list.Allow(other, now.Add(2*time.Second)), "limits are per client")
+
+	// a minute later the per minute limit allows one more, the hourly
+	// limit then stops the client
+	assert.NoError(t, list.Allow(client, now.Add(time.Minute)))
+	assert.ErrorIs(t, list.Allow(client, now.Add(2*time.Minute)), ErrRateLimited)
+	assert.NoError(t, list.Allow(client, now.Add(time.Hour+time.Second)))
+
+	list.SetRateLimits(0, 0)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, list.Allow(client, now.Add(time.Hour+time.Second)))
+	}
+}
+
+func TestParseAccessOptions(t *testing.T) {
+	friend, other := accessHash(1), accessHash(2)
+	list, err := ParseAccessOptions(map[string]string{
+		OPTION_ENABLE_ACCESS_LIST: "true",
+		OPTION_ACCESS_LIST:        friend.Base64() + ", " + other.Base32Address(),
+		OPTION_MAX_CONNS_PER_MIN:  "5",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ACCESS_MODE_WHITELIST, list.Mode())
+	assert.ElementsMatch(t, []common.Hash{friend, other}, list.Hashes())
+	assert.NoError(t, list.Allow(friend, time.Now()))
+	assert.ErrorIs(t, list.Allow(accessHash(3), time.Now()), ErrAccessDenied)
+
+	list, err = ParseAccessOptions(map[string]string{
+		OPTION_ENABLE_BLACK_LIST: "true",
+		OPTION_ACCESS_LIST:       friend.Base32(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, ACCESS_MODE_BLACKLIST, list.Mode())
+
+	list, err = ParseAccessOptions(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ACCESS_MODE_OFF, list.Mode())
+
+	_, err = ParseAccessOptions(map[string]string{OPTION_ACCESS_LIST: "not-a-hash"})
+	assert.ErrorIs(t, err, common.ErrInvalidHash)
+	_, err = ParseAccessOptions(map[string]string{OPTION_MAX_CONNS_PER_HOUR: "-1"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,240 @@
+package streaming
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// Proof-of-work admission parameters
+const (
+	// POW_SEED_SIZE is the size of the random part of a challenge
+	POW_SEED_SIZE = 16
+	// POW_CHALLENGE_SIZE is the size of a serialized challenge: seed,
+	// difficulty and the expiration in milliseconds
+	POW_CHALLENGE_SIZE = POW_SEED_SIZE + 1 + 8
+	// POW_CHALLENGE_LIFETIME is how long a client has to answer a challenge
+	POW_CHALLENGE_LIFETIME = time.Minute
+	// MAX_POW_DIFFICULTY is the most leading zero bits a challenge may
+	// demand, around 2^24 hashes for the client
+	MAX_POW_DIFFICULTY = 24
+)
+
+// accept queue fill above which the difficulty is raised, and below which
+// it is lowered
+const (
+	powRaisePressure = 0.75
+	powLowerPressure = 0.25
+)
+
+var (
+	ErrPowInvalid          = errors.New("invalid proof of work")
+	ErrPowExpired          = errors.New("proof of work challenge expired")
+	ErrPowReplayed         = errors.New("proof of work already used")
+	ErrPowDifficulty       = errors.New("invalid proof of work difficulty")
+	ErrInvalidPowChallenge = errors.New("invalid proof of work challenge")
+)
+
+// PowChallenge is sent to a client in reply to its SYN while the service is
+// under load. The client opens the stream again with a solution: a counter
+// for which the SHA256 of the seed, its destination hash and the counter has
+// Difficulty leading zero bits.
+type PowChallenge struct {
+	Seed       [POW_SEED_SIZE]byte
+	Difficulty uint8
+	Expires    time.Time
+}
+
+// Bytes serializes the challenge.
+func (challenge PowChallenge) Bytes() []byte {
+	b := make([]byte, POW_CHALLENGE_SIZE)
+	copy(b, challenge.Seed[:])
+	b[POW_SEED_SIZE] = challenge.Difficulty
+	binary.BigEndian.PutUint64(b[POW_SEED_SIZE+1:], uint64(challenge.Expires.UnixMilli()))
+	return b
+}
+
+// ReadPowChallenge parses a challenge serialized by Bytes.
+func ReadPowChallenge(data []byte) (PowChallenge, error) {
+	var challenge PowChallenge
+	if len(data) != POW_CHALLENGE_SIZE {
+		return challenge, ErrInvalidPowChallenge
+	}
+	copy(challenge.Seed[:], data)
+	challenge.Difficulty = data[POW_SEED_SIZE]
+	if challenge.Difficulty > MAX_POW_DIFFICULTY {
+		return challenge, ErrPowDifficulty
+	}
+	challenge.Expires = time.UnixMilli(int64(binary.BigEndian.Uint64(data[POW_SEED_SIZE+1:])))
+	return challenge, nil
+}
+
+// powWork returns the hash a solution is judged by
+func powWork(seed [POW_SEED_SIZE]byte, client common.Hash, counter uint64) [32]byte {
+	input := make([]byte, 0, POW_SEED_SIZE+len(client)+8)
+	input = append(input, seed[:]...)
+	input = append(input, client[:]...)
+	input = binary.BigEndian.AppendUint64(input, counter)
+	return crypto.SHA256(input)
+}
+
+// leadingZeroBits counts the leading zero bits of hash
+func leadingZeroBits(hash [32]byte) int {
+	zeros := 0
+	for _, b := range hash {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+// SolvePowChallenge finds the counter answering challenge for the client
+// destination. It gives up when ctx is done.
+func SolvePowChallenge(ctx context.Context, challenge PowChallenge, client common.Hash) (uint64, error) {
+	if challenge.Difficulty > MAX_POW_DIFFICULTY {
+		return 0, ErrPowDifficulty
+	}
+	for counter := uint64(0); ; counter++ {
+		if counter%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+		if leadingZeroBits(powWork(challenge.Seed, client, counter)) >= int(challenge.Difficulty) {
+			return counter, nil
+		}
+	}
+}
+
+// PowAdmission asks clients of a busy service for proof of work before
+// accepting their streams. Challenges are derived from a secret, so they
+// need no state until solved; solved challenges are remembered until they
+// expire, so a solution admits one stream only.
+//
+// The difficulty follows the pressure on the accept queue, see Tune. While
+// it is 0 no work is asked for. It is safe for concurrent use.
+type PowAdmission struct {
+	mutex      sync.Mutex
+	secret     [32]byte
+	difficulty uint8
+	min, max   uint8
+	solved     map[[POW_SEED_SIZE]byte]time.Time
+}
+
+// NewPowAdmission returns admission control whose difficulty is tuned
+// between min and max leading zero bits, starting at min.
+func NewPowAdmission(min, max uint8) (*PowAdmission, error) {
+	if min > max || max > MAX_POW_DIFFICULTY {
+		return nil, ErrPowDifficulty
+	}
+	admission := &PowAdmission{
+		difficulty: min,
+		min:        min,
+		max:        max,
+		solved:     make(map[[POW_SEED_SIZE]byte]time.Time),
+	}
+	if _, err := rand.Read(admission.secret[:]); err != nil {
+		return nil, err
+	}
+	return admission, nil
+}
+
+// Required reports whether clients have to solve a challenge before their
+// streams are accepted.
+func (admission *PowAdmission) Required() bool {
+	return admission.Difficulty() > 0
+}
+
+// Difficulty returns the leading zero bits asked for now.
+func (admission *PowAdmission) Difficulty() uint8 {
+	admission.mutex.Lock()
+	defer admission.mutex.Unlock()
+	return admission.difficulty
+}
+
+// Tune adjusts the difficulty to the accept queue, holding queued of
+// capacity streams: one bit up when it is more than three quarters full,
+// one bit down when it is less than a quarter full.
+func (admission *PowAdmission) Tune(queued, capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	pressure := float64(queued) / float64(capacity)
+	admission.mutex.Lock()
+	defer admission.mutex.Unlock()
+	previous := admission.difficulty
+	switch {
+	case pressure > powRaisePressure && admission.difficulty < admission.max:
+		admission.difficulty++
+	case pressure < powLowerPressure && admission.difficulty > admission.min:
+		admission.difficulty--
+	}
+	if admission.difficulty != previous {
+		log.WithFields(logrus.Fields{
+			"difficulty": admission.difficulty,
+			"pressure":   pressure,
+		}).Debug("Adjusted proof of work difficulty")
+	}
+}
+
+// seed derives the seed of a challenge for client from the secret
+func (admission *PowAdmission) seed(client common.Hash, difficulty uint8, expires time.Time) (seed [POW_SEED_SIZE]byte) {
+	mac := hmac.New(sha256.New, admission.secret[:])
+	mac.Write(client[:])
+	mac.Write([]byte{difficulty})
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(expires.UnixMilli())))
+	copy(seed[:], mac.Sum(nil))
+	return seed
+}
+
+// Challenge returns a challenge at the current difficulty for the client
+// destination.
+func (admission *PowAdmission) Challenge(client common.Hash, now time.Time) PowChallenge {
+	difficulty := admission.Difficulty()
+	expires := time.UnixMilli(now.Add(POW_CHALLENGE_LIFETIME).UnixMilli())
+	return PowChallenge{
+		Seed:       admission.seed(client, difficulty, expires),
+		Difficulty: difficulty,
+		Expires:    expires,
+	}
+}
+
+// Verify checks the solution of a challenge issued to the client
+// destination, returning ErrPowInvalid, ErrPowExpired or ErrPowReplayed if
+// it does not admit a stream.
+func (admission *PowAdmission) Verify(client common.Hash, challenge PowChallenge, solution uint64, now time.Time) error {
+	if !now.Before(challenge.Expires) {
+		return ErrPowExpired
+	}
+	expected := admission.seed(client, challenge.Difficulty, challenge.Expires)
+	if !hmac.Equal(expected[:], challenge.Seed[:]) {
+		return ErrPowInvalid
+	}
+	if leadingZeroBits(powWork(challenge.Seed, client, solution)) < int(challenge.Difficulty) {
+		return ErrPowInvalid
+	}
+	admission.mutex.Lock()
+	defer admission.mutex.Unlock()
+	for seed, expires := range admission.solved {
+		if !now.Before(expires) {
+			delete(admission.solved, seed)
+		}
+	}
+	if _, ok := admission.solved[challenge.Seed]; ok {
+		return ErrPowReplayed
+	}
+	admission.solved[challenge.Seed] = challenge.Expires
+	return nil
+}
@@ -0,0 +1,89 @@
+package streaming
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPowAdmission(t *testing.T) {
+	now := time.Now()
+	client, other := accessHash(1), accessHash(2)
+	admission, err := NewPowAdmission(8, 10)
+	require.NoError(t, err)
+	require.True(t, admission.Required())
+
+	challenge := admission.Challenge(client, now)
+	assert.Equal(t, uint8(8), challenge.Difficulty)
+	solution, err := SolvePowChallenge(context.Background(), challenge, client)
+	require.NoError(t, err)
+
+	// a solution is bound to the client and admits one stream
+	assert.ErrorIs(t, admission.Verify(other, challenge, solution, now), ErrPowInvalid)
+	require.NoError(t, admission.Verify(client, challenge, solution, now))
+	assert.ErrorIs(t, admission.Verify(client, challenge, solution, now), ErrPowReplayed)
+
+	// solutions must be sent before the challenge expires
+	challenge = admission.Challenge(client, now)
+	solution, err = SolvePowChallenge(context.Background(), challenge, client)
+	require.NoError(t, err)
+	assert.ErrorIs(t, admission.Verify(client, challenge, solution, now.Add(POW_CHALLENGE_LIFETIME)), ErrPowExpired)
+
+	// the client may not lower the difficulty
+	cheap := challenge
+	cheap.Difficulty = 0
+	assert.ErrorIs(t, admission.Verify(client, cheap, 0, now), ErrPowInvalid)
+}
+
+func TestPowAdmissionTune(t *testing.T) {
+	admission, err := NewPowAdmission(0, 2)
+	require.NoError(t, err)
+	assert.False(t, admission.Required())
+
+	admission.Tune(8, 10)
+	admission.Tune(9, 10)
+	admission.Tune(10, 10)
+	assert.Equal(t, uint8(2), admission.Difficulty(), "capped at the maximum")
+	admission.Tune(5, 10)
+	assert.Equal(t, uint8(2), admission.Difficulty())
+	admission.Tune(1, 10)
+	admission.Tune(0, 10)
+	admission.Tune(0, 10)
+	assert.Equal(t, uint8(0), admission.Difficulty(), "stops at the minimum")
+	assert.False(t, admission.Required())
+
+	_, err = NewPowAdmission(4, 2)
+	assert.ErrorIs(t, err, ErrPowDifficulty)
+	_, err = NewPowAdmission(0, MAX_POW_DIFFICULTY+1)
+	assert.ErrorIs(t, err, ErrPowDifficulty)
+}
+
+func TestPowChallengeBytes(t *testing.T) {
+	admission, err := NewPowAdmission(4, 4)
+	require.NoError(t, err)
+	challenge := admission.Challenge(accessHash(1), time.Now())
+
+	data := challenge.Bytes()
+	require.Len(t, data, POW_CHALLENGE_SIZE)
+	parsed, err := ReadPowChallenge(data)
+	require.NoError(t, err)
+	assert.Equal(t, challenge.Seed, parsed.Seed)
+	assert.Equal(t, challenge.Difficulty, parsed.Difficulty)
+	assert.True(t, challenge.Expires.Equal(parsed.Expires))
+
+	_, err = ReadPowChallenge(data[1:])
+	assert.ErrorIs(t, err, ErrInvalidPowChallenge)
+	data[POW_SEED_SIZE] = MAX_POW_DIFFICULTY + 1
+	_, err = ReadPowChallenge(data)
+	assert.ErrorIs(t, err, ErrPowDifficulty)
+}
+
+func TestSolvePowChallengeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := SolvePowChallenge(ctx, PowChallenge{Difficulty: MAX_POW_DIFFICULTY}, accessHash(1))
+	assert.ErrorIs(t, err, context.Canceled)
+}
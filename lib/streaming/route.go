@@ -0,0 +1,194 @@
+package streaming
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/lease"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrNoLease = errors.New("remote destination has no usable lease")
+
+// Route tracks the leases of the remote destination of a stream and which of
+// them packets are sent to. The remote end rotates its tunnels and publishes
+// new LeaseSets while a stream is open; Update follows those changes without
+// touching the stream itself. It is safe for concurrent use.
+type Route struct {
+	mutex  sync.Mutex
+	leases []lease.Lease
+	// current is the lease packets go to while it stays usable
+	current    lease.Lease
+	hasCurrent bool
+	// how long past their end date leases are used if no other is left
+	grace time.Duration
+	// selector, if set, chooses leases for dest instead of the route
+	selector *tunnel.LeaseSelector
+	dest     common.Hash
+}
+
+// NewRoute returns a route over the leases of the remote LeaseSet.
+func NewRoute(leases []lease.Lease) *Route {
+	route := &Route{grace: lease.DEFAULT_LEASE_GRACE}
+	route.Update(leases)
+	return route
+}
+
+// NewSelectedRoute returns a route to dest whose leases are chosen by
+// selector, which is normally shared by every sender to the destination so
+// its strategy and stickiness apply across streams.
+func NewSelectedRoute(dest common.Hash, leases []lease.Lease, selector *tunnel.LeaseSelector) *Route {
+	route := &Route{grace: lease.DEFAULT_LEASE_GRACE, selector: selector, dest: dest}
+	route.Update(leases)
+	return route
+}
+
+// SetGrace sets how long past their end date leases are still sent to, when
+// no unexpired lease is left. It defaults to lease.DEFAULT_LEASE_GRACE. A
+// route with a selector uses the grace of the selector instead.
+func (route *Route) SetGrace(grace time.Duration) {
+	route.mutex.Lock()
+	defer route.mutex.Unlock()
+	route.grace = grace
+}
+
+// sameTunnel reports whether a and b name the same inbound tunnel
+func sameTunnel(a, b lease.Lease) bool {
+	return a.TunnelGateway() == b.TunnelGateway() && a.TunnelID() == b.TunnelID()
+}
+
+// Update replaces the known leases with those of a newer LeaseSet. The
+// current lease is kept if the new set still contains its tunnel, so a
+// republished LeaseSet does not move traffic needlessly.
+func (route *Route) Update(leases []lease.Lease) {
+	route.mutex.Lock()
+	defer route.mutex.Unlock()
+	route.leases = append([]lease.Lease(nil), leases...)
+	if !route.hasCurrent {
+		return
+	}
+	for _, l := range route.leases {
+		if sameTunnel(l, route.current) {
+			route.current = l
+			return
+		}
+	}
+	log.WithField("tunnel_id", route.current.TunnelID()).Debug("Current lease left the remote LeaseSet")
+	route.hasCurrent = false
+}
+
+// Lease returns the lease to send to at now. Without a selector the current
+// lease is used until it expires or is dropped, then the lease lasting
+// longest is chosen, which may be within the grace period if no unexpired
+// lease is left. ErrNoLease is returned if every lease has expired.
+func (route *Route) Lease(now time.Time) (lease.Lease, error) {
+	route.mutex.Lock()
+	defer route.mutex.Unlock()
+	if route.selector != nil {
+		return route.selectLease(now)
+	}
+	if route.hasCurrent && !route.current.Expired(now, 0) {
+		return route.current, nil
+	}
+	var best lease.Lease
+	found := false
+	for _, l := range route.leases {
+		if !l.Expired(now, route.grace) && (!found || l.Date().Time().After(best.Date().Time())) {
+			best, found = l, true
+		}
+	}
+	if !found {
+		route.hasCurrent = false
+		return best, ErrNoLease
+	}
+	if !route.hasCurrent || !sameTunnel(best, route.current) {
+		log.WithFields(logrus.Fields{
+			"tunnel_id": best.TunnelID(),
+			"expires":   best.Date().Time(),
+		}).Debug("Switching stream to new lease")
+	}
+	route.current, route.hasCurrent = best, true
+	return best, nil
+}
+
+// selectLease asks the selector for the lease. Callers must hold the lock.
+func (route *Route) selectLease(now time.Time) (lease.Lease, error) {
+	l, err := route.selector.Select(route.dest, route.leases, now)
+	if err != nil {
+		route.hasCurrent = false
+		return l, ErrNoLease
+	}
+	route.current, route.hasCurrent = l, true
+	return l, nil
+}
+
+// ObserveLatency passes a round trip time measured through the lease on to
+// the selector, if the route has one.
+func (route *Route) ObserveLatency(l lease.Lease, rtt time.Duration) {
+	if route.selector != nil {
+		route.selector.ObserveLatency(l, rtt)
+	}
+}
+
+// Drop removes a lease sending through failed, until a LeaseSet containing
+// it again is passed to Update.
+func (route *Route) Drop(failed lease.Lease) {
+	route.mutex.Lock()
+	defer route.mutex.Unlock()
+	leases := route.leases[:0]
+	for _, l := range route.leases {
+		if !sameTunnel(l, failed) {
+			leases = append(leases, l)
+		}
+	}
+	route.leases = leases
+	if route.hasCurrent && sameTunnel(route.current, failed) {
+		route.hasCurrent = false
+		if route.selector != nil {
+			route.selector.Forget(route.dest)
+		}
+	}
+}
+
+// RoutedSender is a Sender which picks the lease of each packet from a Route.
+// A packet whose send fails is retried once through another lease, so a
+// tunnel disappearing mid-stream does not reset the stream.
+type RoutedSender struct {
+	Route *Route
+	// SendTo transmits a packet through lease, SendCloseTo sends CLOSE
+	SendTo      func(l lease.Lease, payload []byte) error
+	SendCloseTo func(l lease.Lease) error
+}
+
+// Send implements Sender.
+func (sender *RoutedSender) Send(payload []byte) error {
+	return sender.send(func(l lease.Lease) error {
+		return sender.SendTo(l, payload)
+	})
+}
+
+// SendClose implements Sender.
+func (sender *RoutedSender) SendClose() error {
+	return sender.send(sender.SendCloseTo)
+}
+
+// send calls fn with the current lease, and once more with the next lease
+// if it fails
+func (sender *RoutedSender) send(fn func(lease.Lease) error) (err error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		var l lease.Lease
+		l, err = sender.Route.Lease(time.Now())
+		if err != nil {
+			return err
+		}
+		if err = fn(l); err == nil {
+			return nil
+		}
+		log.WithError(err).WithField("tunnel_id", l.TunnelID()).Warn("Sending through lease failed")
+		sender.Route.Drop(l)
+	}
+	return err
+}
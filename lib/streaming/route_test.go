@@ -0,0 +1,199 @@
+package streaming
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/lease"
+	"github.com/go-i2p/go-i2p/lib/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLease(t *testing.T, id uint32, expires time.Time) lease.Lease {
+	var gateway common.Hash
+	gateway[0] = byte(id)
+	l, err := lease.NewLease(gateway, id, expires)
+	require.NoError(t, err)
+	return *l
+}
+
+func TestRouteKeepsCurrentLease(t *testing.T) {
+	now := time.Now()
+	a := testLease(t, 1, now.Add(5*time.Minute))
+	b := testLease(t, 2, now.Add(10*time.Minute))
+	route := NewRoute([]lease.Lease{a, b})
+
+	l, err := route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	// a new LeaseSet with a longer lease still containing the current one
+	c := testLease(t, 3, now.Add(15*time.Minute))
+	route.Update([]lease.Lease{b, c})
+	l, err = route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	// the current tunnel is gone from the next LeaseSet
+	route.Update([]lease.Lease{a, c})
+	l, err = route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), l.TunnelID())
+
+	// and expires
+	l, err = route.Lease(now.Add(20 * time.Minute))
+	assert.ErrorIs(t, err, ErrNoLease)
+}
+
+func TestRoutedSenderFailsOver(t *testing.T) {
+	now := time.Now()
+	route := NewRoute([]lease.Lease{
+		testLease(t, 1, now.Add(5*time.Minute)),
+		testLease(t, 2, now.Add(10*time.Minute)),
+	})
+	var used []uint32
+	sender := &RoutedSender{
+		Route: route,
+		SendTo: func(l lease.Lease, payload []byte) error {
+			used = append(used, l.TunnelID())
+			if l.TunnelID() == 2 {
+				return errors.New("tunnel gone")
+			}
+			return nil
+		},
+	}
+	s := NewStream(sender, 1000)
+	_, err := s.Write([]byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{2, 1}, used)
+
+	// the failed lease is not tried again
+	_, err = s.Write([]byte("again"))
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{2, 1, 1}, used)
+}
+
+func TestStreamSurvivesLeaseChurn(t *testing.T) {
+	var mutex sync.Mutex
+	live := map[uint32]bool{}
+	var received []byte
+
+	now := time.Now()
+	route := NewRoute(nil)
+	publish := func(ids ...uint32) {
+		leases := make([]lease.Lease, len(ids))
+		mutex.Lock()
+		live = map[uint32]bool{}
+		for i, id := range ids {
+			leases[i] = testLease(t, id, now.Add(time.Duration(id)*time.Second+time.Hour))
+			live[id] = true
+		}
+		mutex.Unlock()
+		route.Update(leases)
+	}
+	publish(1, 2, 3)
+
+	sender := &RoutedSender{
+		Route: route,
+		SendTo: func(l lease.Lease, payload []byte) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			// tunnels dropped from the LeaseSet are torn down
+			if !live[l.TunnelID()] {
+				return errors.New("tunnel gone")
+			}
+			received = append(received, payload...)
+			return nil
+		},
+	}
+	s := NewStream(sender, 1<<20)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// each LeaseSet replaces one tunnel, keeping an overlap like a
+		// tunnel pool rotating its tunnels
+		for id := uint32(4); id < 200; id++ {
+			publish(id-2, id-1, id)
+		}
+	}()
+	sent := 0
+	for i := 0; i < 500; i++ {
+		n, err := s.Write([]byte{byte(i)})
+		require.NoError(t, err, "write %d", i)
+		sent += n
+	}
+	<-done
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, 500, sent)
+	require.Len(t, received, 500)
+	for i, b := range received {
+		assert.Equal(t, byte(i), b)
+	}
+}
+
+func TestSelectedRouteUsesSelector(t *testing.T) {
+	now := time.Now()
+	leases := []lease.Lease{
+		testLease(t, 1, now.Add(5*time.Minute)),
+		testLease(t, 2, now.Add(10*time.Minute)),
+		testLease(t, 3, now.Add(15*time.Minute)),
+	}
+	selector, err := tunnel.NewLeaseSelector(tunnel.LEASE_STRATEGY_ROUND_ROBIN, time.Minute)
+	require.NoError(t, err)
+	var dest common.Hash
+	route := NewSelectedRoute(dest, leases, selector)
+
+	// the selector keeps the destination on its lease
+	for i := 0; i < 3; i++ {
+		l, err := route.Lease(now)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), l.TunnelID())
+	}
+
+	// and a second route to the destination shares the choice
+	other := NewSelectedRoute(dest, leases, selector)
+	l, err := other.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), l.TunnelID())
+
+	// a failed lease releases the destination
+	route.Drop(leases[0])
+	l, err = route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	_, err = route.Lease(now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrNoLease)
+	assert.Equal(t, uint64(3), selector.Stats().StickyHits)
+}
+
+func TestRouteLeaseGrace(t *testing.T) {
+	now := time.Now()
+	skewed := testLease(t, 1, now.Add(-30*time.Second))
+	route := NewRoute([]lease.Lease{skewed})
+
+	l, err := route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), l.TunnelID())
+
+	// a new LeaseSet with an unexpired lease moves the stream
+	route.Update([]lease.Lease{skewed, testLease(t, 2, now.Add(10*time.Minute))})
+	l, err = route.Lease(now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	route.Update([]lease.Lease{skewed})
+	_, err = route.Lease(now.Add(lease.DEFAULT_LEASE_GRACE))
+	assert.ErrorIs(t, err, ErrNoLease)
+
+	route.SetGrace(0)
+	_, err = route.Lease(now)
+	assert.ErrorIs(t, err, ErrNoLease)
+}
@@ -0,0 +1,27 @@
+package streaming
+
+import (
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/offline_signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+// PacketVerifier returns the verifier for signed packets of a remote
+// destination with signing key destinationKey. If its SYN carried an offline
+// signature, that is checked against destinationKey at now and packets are
+// verified with the transient key it delegates to instead.
+func PacketVerifier(destinationKey crypto.SigningPublicKey, offline *offline_signature.OfflineSignature, now time.Time) (crypto.Verifier, error) {
+	if offline == nil {
+		return destinationKey.NewVerifier()
+	}
+	if err := offline.Verify(destinationKey, now); err != nil {
+		log.WithError(err).Warn("Rejecting stream with invalid offline signature")
+		return nil, err
+	}
+	transientKey, err := offline.TransientPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return transientKey.NewVerifier()
+}
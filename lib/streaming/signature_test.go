@@ -0,0 +1,46 @@
+package streaming
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/common/offline_signature"
+	"github.com/go-i2p/go-i2p/lib/common/signature"
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketVerifierOffline(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	destinationSigner, err := crypto.Ed25519PrivateKey(private).NewSigner()
+	require.NoError(t, err)
+	transientPublic, transientPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	transientSigner, err := crypto.Ed25519PrivateKey(transientPrivate).NewSigner()
+	require.NoError(t, err)
+
+	now := time.Now()
+	offline, err := offline_signature.NewOfflineSignature(now.Add(time.Hour),
+		signature.SIGNATURE_TYPE_EDDSA_SHA512_ED25519, transientPublic, destinationSigner)
+	require.NoError(t, err)
+
+	packet := []byte("SYN packet")
+	sig, err := transientSigner.Sign(packet)
+	require.NoError(t, err)
+
+	verifier, err := PacketVerifier(crypto.Ed25519PublicKey(public), offline, now)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.Verify(packet, sig))
+
+	// without the offline signature the transient signature does not verify
+	verifier, err = PacketVerifier(crypto.Ed25519PublicKey(public), nil, now)
+	require.NoError(t, err)
+	assert.Error(t, verifier.Verify(packet, sig))
+
+	_, err = PacketVerifier(crypto.Ed25519PublicKey(public), offline, now.Add(2*time.Hour))
+	assert.ErrorIs(t, err, offline_signature.ErrOfflineSignatureExpired)
+}
@@ -0,0 +1,346 @@
+// Package streaming provides the io side of I2P streaming connections: the
+// blocking, deadline and half-close behaviour applications expect from a
+// net.Conn, on top of a packet layer that sends data, reports acks and
+// delivers received payloads.
+//
+// https://geti2p.net/spec/streaming
+package streaming
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// DEFAULT_MAX_PAYLOAD is the largest payload handed to the packet layer at
+// once, the streaming default MTU
+const DEFAULT_MAX_PAYLOAD = 1730
+
+// ErrDeadline is returned by Read and Write when their deadline passed. It
+// is a timeout net.Error and matches os.ErrDeadlineExceeded.
+var ErrDeadline error = deadlineError{}
+
+var (
+	ErrStreamClosed = errors.New("stream closed")
+	ErrWriteClosed  = errors.New("stream closed for writing")
+)
+
+type deadlineError struct{}
+
+func (deadlineError) Error() string   { return "i/o timeout" }
+func (deadlineError) Timeout() bool   { return true }
+func (deadlineError) Temporary() bool { return true }
+func (deadlineError) Is(target error) bool {
+	return target == os.ErrDeadlineExceeded
+}
+
+// Sender is the packet layer under a Stream.
+type Sender interface {
+	// Send transmits payload as one packet. It is not called with the
+	// stream locked and may block.
+	Send(payload []byte) error
+	// SendClose sends the CLOSE flag once the stream is closed for writing.
+	SendClose() error
+}
+
+// Stream is one end of a streaming connection. Writes are held back while
+// the bytes sent but not acknowledged fill the congestion window, reads wait
+// for data delivered by the packet layer. It is safe for concurrent use.
+type Stream struct {
+	sender     Sender
+	maxPayload int
+
+	mutex sync.Mutex
+	// changed is closed and replaced whenever waiters should re-check
+	changed chan struct{}
+	// window is the congestion window in bytes, inFlight the bytes sent
+	// but not yet acknowledged
+	window   int
+	inFlight int
+	received []byte
+
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	readClosed   bool
+	writeClosed  bool
+	remoteClosed bool
+	closed       bool
+	// err is set when the stream was reset
+	err error
+}
+
+// NewStream returns a stream sending through sender with an initial
+// congestion window of window bytes.
+func NewStream(sender Sender, window int) *Stream {
+	return &Stream{
+		sender:     sender,
+		maxPayload: DEFAULT_MAX_PAYLOAD,
+		changed:    make(chan struct{}),
+		window:     window,
+	}
+}
+
+// notify wakes all waiters, the caller holds the mutex
+func (s *Stream) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+// wait releases the mutex until the stream changes or deadline passes and
+// returns ErrDeadline in the latter case. The caller holds the mutex.
+func (s *Stream) wait(deadline time.Time) error {
+	changed := s.changed
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return ErrDeadline
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	s.mutex.Unlock()
+	defer s.mutex.Lock()
+	select {
+	case <-changed:
+		return nil
+	case <-timeout:
+		return ErrDeadline
+	}
+}
+
+// Read reads delivered data. It blocks until data arrives, the remote end
+// closes the stream (io.EOF), the read deadline passes (ErrDeadline) or the
+// stream is closed locally.
+func (s *Stream) Read(p []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for {
+		if s.closed || s.readClosed {
+			return 0, ErrStreamClosed
+		}
+		if len(s.received) > 0 {
+			n := copy(p, s.received)
+			s.received = s.received[n:]
+			return n, nil
+		}
+		if s.err != nil {
+			return 0, s.err
+		}
+		if s.remoteClosed {
+			return 0, io.EOF
+		}
+		if err := s.wait(s.readDeadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write sends p, split into packets of at most the maximum payload. It
+// blocks while the congestion window is full and returns ErrDeadline with
+// the number of bytes already sent if the write deadline passes first.
+func (s *Stream) Write(p []byte) (int, error) {
+	written := 0
+	for written < len(p) {
+		s.mutex.Lock()
+		for {
+			if err := s.writeErr(); err != nil {
+				s.mutex.Unlock()
+				return written, err
+			}
+			if s.inFlight < s.window {
+				break
+			}
+			if err := s.wait(s.writeDeadline); err != nil {
+				s.mutex.Unlock()
+				log.WithFields(logrus.Fields{
+					"written":   written,
+					"in_flight": s.inFlight,
+					"window":    s.window,
+				}).Debug("Stream write deadline passed with full window")
+				return written, err
+			}
+		}
+		n := len(p) - written
+		if n > s.maxPayload {
+			n = s.maxPayload
+		}
+		if room := s.window - s.inFlight; n > room {
+			n = room
+		}
+		s.inFlight += n
+		s.mutex.Unlock()
+		if err := s.sender.Send(p[written : written+n]); err != nil {
+			s.Ack(n)
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// writeErr returns why the stream cannot be written to, the caller holds
+// the mutex
+func (s *Stream) writeErr() error {
+	switch {
+	case s.closed:
+		return ErrStreamClosed
+	case s.writeClosed:
+		return ErrWriteClosed
+	case s.err != nil:
+		return s.err
+	}
+	return nil
+}
+
+// Ack is called by the packet layer when n bytes were acknowledged, making
+// room in the congestion window.
+func (s *Stream) Ack(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inFlight -= n
+	if s.inFlight < 0 {
+		s.inFlight = 0
+	}
+	s.notify()
+}
+
+// SetWindow changes the congestion window to window bytes.
+func (s *Stream) SetWindow(window int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.window = window
+	s.notify()
+}
+
+// Deliver is called by the packet layer with in-order received data. Data
+// arriving after CloseRead is dropped.
+func (s *Stream) Deliver(payload []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.readClosed || s.closed {
+		return
+	}
+	s.received = append(s.received, payload...)
+	s.notify()
+}
+
+// RemoteClose is called by the packet layer when the remote end closed the
+// stream, reads return io.EOF once the delivered data is drained.
+func (s *Stream) RemoteClose() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.remoteClosed = true
+	s.notify()
+}
+
+// Reset is called by the packet layer when the stream was reset. Blocked
+// and later reads and writes return err.
+func (s *Stream) Reset(err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.err = err
+	s.notify()
+}
+
+// SetDeadline sets the read and write deadlines.
+func (s *Stream) SetDeadline(t time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.readDeadline, s.writeDeadline = t, t
+	s.notify()
+	return nil
+}
+
+// SetReadDeadline sets the deadline for blocked and future Read calls, a
+// zero time means none.
+func (s *Stream) SetReadDeadline(t time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.readDeadline = t
+	s.notify()
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for blocked and future Write calls, a
+// zero time means none.
+func (s *Stream) SetWriteDeadline(t time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.writeDeadline = t
+	s.notify()
+	return nil
+}
+
+// CloseRead shuts down the reading side, like net.TCPConn. Buffered and
+// later delivered data is discarded.
+func (s *Stream) CloseRead() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return ErrStreamClosed
+	}
+	s.readClosed = true
+	s.received = nil
+	s.notify()
+	return nil
+}
+
+// CloseWrite shuts down the writing side and sends CLOSE, like
+// net.TCPConn. The remote end reads io.EOF while data can still be read
+// from it.
+func (s *Stream) CloseWrite() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrStreamClosed
+	}
+	if s.writeClosed {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.writeClosed = true
+	s.notify()
+	s.mutex.Unlock()
+	return s.sender.SendClose()
+}
+
+// Close closes both directions. Blocked reads and writes return
+// ErrStreamClosed.
+func (s *Stream) Close() error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return ErrStreamClosed
+	}
+	sendClose := !s.writeClosed
+	s.closed = true
+	s.received = nil
+	s.notify()
+	s.mutex.Unlock()
+	if sendClose {
+		return s.sender.SendClose()
+	}
+	return nil
+}
+
+var _ interface {
+	io.ReadWriteCloser
+	CloseRead() error
+	CloseWrite() error
+	SetDeadline(time.Time) error
+	SetReadDeadline(time.Time) error
+	SetWriteDeadline(time.Time) error
+} = (*Stream)(nil)
+
+var _ net.Error = deadlineError{}
@@ -0,0 +1,166 @@
+package streaming
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSender records what a stream sends
+type testSender struct {
+	mutex   sync.Mutex
+	packets [][]byte
+	closes  int
+}
+
+func (sender *testSender) Send(payload []byte) error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	sender.packets = append(sender.packets, append([]byte(nil), payload...))
+	return nil
+}
+
+func (sender *testSender) SendClose() error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	sender.closes++
+	return nil
+}
+
+func (sender *testSender) sent() int {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+	n := 0
+	for _, packet := range sender.packets {
+		n += len(packet)
+	}
+	return n
+}
+
+func TestStreamWriteBlocksOnFullWindow(t *testing.T) {
+	sender := &testSender{}
+	s := NewStream(sender, 4000)
+
+	done := make(chan int)
+	go func() {
+		n, err := s.Write(make([]byte, 6000))
+		assert.NoError(t, err)
+		done <- n
+	}()
+	require.Eventually(t, func() bool { return sender.sent() == 4000 }, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("write returned with a full window")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	s.Ack(4000)
+	assert.Equal(t, 6000, <-done)
+	assert.Equal(t, 6000, sender.sent())
+	for _, packet := range sender.packets {
+		assert.LessOrEqual(t, len(packet), DEFAULT_MAX_PAYLOAD)
+	}
+}
+
+func TestStreamWriteDeadline(t *testing.T) {
+	sender := &testSender{}
+	s := NewStream(sender, 100)
+	require.NoError(t, s.SetWriteDeadline(time.Now().Add(20*time.Millisecond)))
+
+	n, err := s.Write(make([]byte, 300))
+	assert.Equal(t, 100, n)
+	assert.ErrorIs(t, err, ErrDeadline)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr))
+	assert.True(t, netErr.Timeout())
+
+	// clearing the deadline lets the write continue once acked
+	require.NoError(t, s.SetWriteDeadline(time.Time{}))
+	s.Ack(100)
+	n, err = s.Write(make([]byte, 50))
+	assert.NoError(t, err)
+	assert.Equal(t, 50, n)
+}
+
+func TestStreamReadDeadline(t *testing.T) {
+	s := NewStream(&testSender{}, 100)
+	require.NoError(t, s.SetReadDeadline(time.Now().Add(10*time.Millisecond)))
+	_, err := s.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, ErrDeadline)
+
+	// moving the deadline wakes a blocked reader
+	require.NoError(t, s.SetReadDeadline(time.Time{}))
+	result := make(chan error)
+	go func() {
+		_, err := s.Read(make([]byte, 10))
+		result <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, s.SetReadDeadline(time.Now()))
+	assert.ErrorIs(t, <-result, ErrDeadline)
+}
+
+func TestStreamHalfClose(t *testing.T) {
+	sender := &testSender{}
+	s := NewStream(sender, 100)
+
+	require.NoError(t, s.CloseWrite())
+	_, err := s.Write([]byte("x"))
+	assert.ErrorIs(t, err, ErrWriteClosed)
+	assert.Equal(t, 1, sender.closes)
+
+	// reading still works until the remote end closes
+	s.Deliver([]byte("hello"))
+	s.RemoteClose()
+	data, err := io.ReadAll(s)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+
+	// close does not send a second CLOSE
+	require.NoError(t, s.Close())
+	assert.Equal(t, 1, sender.closes)
+	assert.ErrorIs(t, s.Close(), ErrStreamClosed)
+}
+
+func TestStreamCloseRead(t *testing.T) {
+	s := NewStream(&testSender{}, 100)
+	s.Deliver([]byte("dropped"))
+	require.NoError(t, s.CloseRead())
+	s.Deliver([]byte("also dropped"))
+	_, err := s.Read(make([]byte, 10))
+	assert.ErrorIs(t, err, ErrStreamClosed)
+
+	n, err := s.Write([]byte("still writable"))
+	assert.NoError(t, err)
+	assert.Equal(t, 14, n)
+}
+
+func TestStreamCloseWakesWriter(t *testing.T) {
+	s := NewStream(&testSender{}, 10)
+	result := make(chan error)
+	go func() {
+		_, err := s.Write(make([]byte, 20))
+		result <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, s.Close())
+	assert.ErrorIs(t, <-result, ErrStreamClosed)
+}
+
+func TestStreamReset(t *testing.T) {
+	s := NewStream(&testSender{}, 10)
+	reset := errors.New("reset by peer")
+	s.Reset(reset)
+	_, err := s.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, reset)
+	_, err = s.Write([]byte("x"))
+	assert.ErrorIs(t, err, reset)
+}
@@ -0,0 +1,155 @@
+package su3
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PluginConfigFile is the name of the metadata file at the root of every plugin zip.
+const PluginConfigFile = "plugin.config"
+
+var (
+	ErrNotPlugin           = errors.New("su3 content is not a plugin")
+	ErrMissingPluginConfig = errors.New("plugin archive has no plugin.config")
+	ErrInvalidPluginConfig = errors.New("invalid plugin.config")
+	ErrPluginSignerID      = errors.New("plugin signer does not match su3 signer ID")
+)
+
+// PluginConfig is the metadata from a plugin's plugin.config file.
+//
+// See: https://geti2p.net/spec/plugin#pluginconfig
+type PluginConfig struct {
+	Name                  string
+	Signer                string
+	Version               string
+	Description           string
+	Author                string
+	License               string
+	WebsiteURL            string
+	UpdateURL             string
+	ConsoleLinkName       string
+	ConsoleLinkURL        string
+	DontStartAtInstall    bool
+	RouterRestartRequired bool
+	UpdateOnly            bool
+	InstallOnly           bool
+	DisableStop           bool
+	// Properties holds every key from the file, including the ones above.
+	Properties map[string]string
+}
+
+// ParsePluginConfig reads a plugin.config file. Lines are key=value pairs,
+// blank lines and lines starting with # are ignored. The name, signer and
+// version keys are required.
+func ParsePluginConfig(reader io.Reader) (*PluginConfig, error) {
+	log.Debug("Parsing plugin.config")
+	config := &PluginConfig{Properties: make(map[string]string)}
+	scanner := bufio.NewScanner(reader)
+	// icon-code lines hold a whole base64 encoded PNG
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			log.WithField("line", line).Error("Malformed plugin.config line")
+			return nil, fmt.Errorf("%w: malformed line %q", ErrInvalidPluginConfig, line)
+		}
+		config.Properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		log.WithError(err).Error("Failed to read plugin.config")
+		return nil, fmt.Errorf("reading plugin.config: %w", err)
+	}
+
+	p := config.Properties
+	config.Name = p["name"]
+	config.Signer = p["signer"]
+	config.Version = p["version"]
+	config.Description = p["description"]
+	config.Author = p["author"]
+	config.License = p["license"]
+	config.WebsiteURL = p["websiteURL"]
+	config.UpdateURL = p["updateURL"]
+	config.ConsoleLinkName = p["consoleLinkName"]
+	config.ConsoleLinkURL = p["consoleLinkURL"]
+	config.DontStartAtInstall = p["dont-start-at-install"] == "true"
+	config.RouterRestartRequired = p["router-restart-required"] == "true"
+	config.UpdateOnly = p["update-only"] == "true"
+	config.InstallOnly = p["install-only"] == "true"
+	config.DisableStop = p["disableStop"] == "true"
+
+	for _, required := range []string{"name", "signer", "version"} {
+		if p[required] == "" {
+			log.WithField("key", required).Error("plugin.config is missing a required key")
+			return nil, fmt.Errorf("%w: missing %s", ErrInvalidPluginConfig, required)
+		}
+	}
+	if strings.ContainsAny(config.Name, `/\`) || config.Name == "." || config.Name == ".." {
+		log.WithField("name", config.Name).Error("Invalid plugin name")
+		return nil, fmt.Errorf("%w: invalid name %q", ErrInvalidPluginConfig, config.Name)
+	}
+	log.WithFields(logrus.Fields{
+		"name":    config.Name,
+		"version": config.Version,
+		"signer":  config.Signer,
+	}).Debug("Parsed plugin.config")
+	return config, nil
+}
+
+// Plugin is the verified content of a plugin SU3 file.
+type Plugin struct {
+	Config  *PluginConfig
+	Archive *zip.Reader
+}
+
+// Plugin reads and verifies the content of a plugin SU3 file and parses its
+// plugin.config. The whole archive is buffered in memory, since zip files
+// cannot be read as a stream. The signer in plugin.config must match the
+// SU3 signer ID.
+func (su3 *SU3) Plugin(publicKey interface{}) (*Plugin, error) {
+	log.WithField("signer_id", su3.SignerID).Debug("Reading SU3 plugin")
+	if su3.ContentType != PLUGIN || su3.FileType != ZIP {
+		log.WithFields(logrus.Fields{
+			"content_type": su3.ContentType,
+			"file_type":    su3.FileType,
+		}).Error("SU3 file is not a plugin")
+		return nil, ErrNotPlugin
+	}
+	content, err := io.ReadAll(su3.Content(publicKey))
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		log.WithError(err).Error("Failed to open plugin archive")
+		return nil, fmt.Errorf("opening plugin archive: %w", err)
+	}
+	f, err := archive.Open(PluginConfigFile)
+	if err != nil {
+		log.WithError(err).Error("Plugin archive has no plugin.config")
+		return nil, ErrMissingPluginConfig
+	}
+	defer f.Close()
+	config, err := ParsePluginConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	if config.Signer != su3.SignerID {
+		log.WithFields(logrus.Fields{
+			"plugin_signer": config.Signer,
+			"su3_signer":    su3.SignerID,
+		}).Error("Plugin signer does not match SU3 signer ID")
+		return nil, ErrPluginSignerID
+	}
+	return &Plugin{Config: config, Archive: archive}, nil
+}
@@ -0,0 +1,66 @@
+package su3
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSU3Plugin(t *testing.T) {
+	su3File, err := Read(fileReader(t, "testdata/snowflake-linux.su3"))
+	require.NoError(t, err)
+
+	plugin, err := su3File.Plugin(fileRSAPubKey(t, "./testdata/snowflake-hankhill19580_at_gmail.com.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, "snowflake-linux", plugin.Config.Name)
+	assert.Equal(t, "0.0.47", plugin.Config.Version)
+	assert.Equal(t, "hankhill19580@gmail.com", plugin.Config.Signer)
+	assert.Equal(t, "http://127.0.0.1:7676", plugin.Config.ConsoleLinkURL)
+	assert.False(t, plugin.Config.DisableStop)
+
+	_, err = plugin.Archive.Open("lib/snowflake-linux")
+	assert.NoError(t, err)
+}
+
+func TestSU3PluginWrongContentType(t *testing.T) {
+	su3File, err := Read(fileReader(t, "testdata/reseed-i2pgit.su3"))
+	require.NoError(t, err)
+
+	_, err = su3File.Plugin(fileRSAPubKey(t, "./testdata/reseed-hankhill19580_at_gmail.com.crt"))
+	assert.ErrorIs(t, err, ErrNotPlugin)
+}
+
+func TestParsePluginConfig(t *testing.T) {
+	config, err := ParsePluginConfig(strings.NewReader(`
+# a comment
+name=example
+signer=someone@mail.i2p
+version = 1.2.3
+dont-start-at-install=true
+disableStop=true
+custom=value=with=equals
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "example", config.Name)
+	assert.Equal(t, "1.2.3", config.Version)
+	assert.True(t, config.DontStartAtInstall)
+	assert.True(t, config.DisableStop)
+	assert.False(t, config.UpdateOnly)
+	assert.Equal(t, "value=with=equals", config.Properties["custom"])
+}
+
+func TestParsePluginConfigInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":  "signer=a\nversion=1\n",
+		"missing value": "name=a\nsigner\nversion=1\n",
+		"path in name":  "name=../a\nsigner=a\nversion=1\n",
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParsePluginConfig(strings.NewReader(config))
+			assert.ErrorIs(t, err, ErrInvalidPluginConfig)
+		})
+	}
+}
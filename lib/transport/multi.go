@@ -3,11 +3,25 @@ package transport
 import (
 	"github.com/go-i2p/go-i2p/lib/common/router_identity"
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/transport/ssu"
+	"github.com/go-i2p/go-i2p/lib/util/features"
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 )
 
 var log = logger.GetGoI2PLogger()
 
+// transportFeatures maps the names of transports still being rolled out to
+// the feature flag enabling them
+var transportFeatures = map[string]string{
+	ssu.SSU2_PROTOCOL_NAME: features.SSU2,
+}
+
+// enabled reports whether t may be used, it is not if its feature flag is off
+func enabled(t Transport) bool {
+	feature, ok := transportFeatures[t.Name()]
+	return !ok || features.Enabled(feature)
+}
+
 // muxes multiple transports into 1 Transport
 // implements transport.Transport
 type TransportMuxer struct {
@@ -76,7 +90,7 @@ func (tmux *TransportMuxer) GetSession(routerInfo router_info.RouterInfo) (s Tra
 	log.WithField("router_info", routerInfo.String()).Debug("TransportMuxer: Attempting to get session")
 	for i, t := range tmux.trans {
 		// pick the first one that is compatable
-		if t.Compatible(routerInfo) {
+		if enabled(t) && t.Compatible(routerInfo) {
 			log.WithField("transport_index", i).Debug("TransportMuxer: Found compatible transport, attempting to get session")
 			// try to get a session
 			s, err = t.GetSession(routerInfo)
@@ -101,7 +115,7 @@ func (tmux *TransportMuxer) GetSession(routerInfo router_info.RouterInfo) (s Tra
 func (tmux *TransportMuxer) Compatible(routerInfo router_info.RouterInfo) (compat bool) {
 	log.WithField("router_info", routerInfo.String()).Debug("TransportMuxer: Checking compatibility")
 	for i, t := range tmux.trans {
-		if t.Compatible(routerInfo) {
+		if enabled(t) && t.Compatible(routerInfo) {
 			log.WithField("transport_index", i).Debug("TransportMuxer: Found compatible transport")
 			compat = true
 			return
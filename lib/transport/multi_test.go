@@ -0,0 +1,49 @@
+package transport
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/common/key_certificate"
+	"github.com/go-i2p/go-i2p/lib/common/router_identity"
+	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/transport/ssu"
+	"github.com/go-i2p/go-i2p/lib/util/features"
+)
+
+// namedTransport is compatible with every router and has no sessions
+type namedTransport struct {
+	name string
+}
+
+func (t *namedTransport) Accept() (net.Conn, error)                        { return nil, nil }
+func (t *namedTransport) Addr() net.Addr                                   { return nil }
+func (t *namedTransport) SetIdentity(router_identity.RouterIdentity) error { return nil }
+func (t *namedTransport) Compatible(router_info.RouterInfo) bool           { return true }
+func (t *namedTransport) Close() error                                     { return nil }
+func (t *namedTransport) Name() string                                     { return t.name }
+func (t *namedTransport) GetSession(router_info.RouterInfo) (TransportSession, error) {
+	return nil, nil
+}
+
+func TestMuxerSkipsDisabledTransports(t *testing.T) {
+	t.Cleanup(features.Default.Reset)
+	identity, keys, err := router_identity.GenerateRouterIdentity(key_certificate.KEYCERT_SIGN_ED25519, key_certificate.KEYCERT_CRYPTO_X25519)
+	require.NoError(t, err)
+	owned, err := router_info.NewOwnedRouterInfo(identity, keys.SigningPrivateKey, nil, nil)
+	require.NoError(t, err)
+	ri := *owned.RouterInfo()
+
+	tmux := Mux(&namedTransport{name: ssu.SSU2_PROTOCOL_NAME})
+	assert.False(t, tmux.Compatible(ri))
+	_, err = tmux.GetSession(ri)
+	assert.ErrorIs(t, err, ErrNoTransportAvailable)
+
+	require.NoError(t, features.Default.Set(features.SSU2, true))
+	assert.True(t, tmux.Compatible(ri))
+	_, err = tmux.GetSession(ri)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,189 @@
+package noise
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-i2p/go-i2p/lib/util/chaos"
+)
+
+const (
+	// macSize is the size of the ChaCha20-Poly1305/AES-GCM authentication tag
+	macSize = 16
+	// MaxFramePayloadSize is the largest plaintext which still fits a frame
+	// with a 2-byte length prefix once the MAC is appended
+	MaxFramePayloadSize = 65535 - macSize
+)
+
+var ErrFrameTooLarge = errors.New("frame exceeds maximum payload size")
+
+// encryptPacketBatch encrypts every frame with the session CipherState and
+// appends them to dst as length-prefixed packets. All frames share a single
+// output buffer and a single pass over the cipher state, which avoids the
+// per-frame allocations and logging of encryptPacket on the data phase.
+func (c *NoiseSession) encryptPacketBatch(dst []byte, frames [][]byte) ([]byte, int, error) {
+	if c.CipherState == nil {
+		log.Error("NoiseSession: encryptPacketBatch - CipherState is nil")
+		return dst, 0, errors.New("CipherState is nil")
+	}
+	var n, size int
+	for _, frame := range frames {
+		if len(frame) > MaxFramePayloadSize {
+			return dst, 0, ErrFrameTooLarge
+		}
+		size += uint16Size + len(frame) + macSize
+	}
+	if cap(dst)-len(dst) < size {
+		grown := make([]byte, len(dst), len(dst)+size)
+		copy(grown, dst)
+		dst = grown
+	}
+	for _, frame := range frames {
+		prefix := len(dst)
+		dst = append(dst, 0, 0)
+		var err error
+		dst, err = c.CipherState.Encrypt(dst, nil, frame)
+		if err != nil {
+			log.WithError(err).Error("NoiseSession: encryptPacketBatch - failed to encrypt frame")
+			return dst[:prefix], n, fmt.Errorf("failed to encrypt: '%w'", err)
+		}
+		binary.BigEndian.PutUint16(dst[prefix:], uint16(len(dst)-prefix-uint16Size))
+		n += len(frame)
+	}
+	log.WithFields(logrus.Fields{
+		"frames":        len(frames),
+		"payload_bytes": n,
+		"packet_bytes":  size,
+	}).Debug("NoiseSession: encryptPacketBatch - frames encrypted")
+	return dst, n, nil
+}
+
+// decryptPacketBatch decrypts consecutive length-prefixed packets from data,
+// appending the plaintext of each to dst. It returns the extended buffer, the
+// plaintext length of every frame in order, and the number of bytes of data
+// consumed. A trailing partial packet is left unconsumed. Packets the
+// transport receive faults drop are consumed without adding their plaintext.
+func (c *NoiseSession) decryptPacketBatch(dst, data []byte) ([]byte, []int, int, error) {
+	if c.CipherState == nil {
+		log.Error("NoiseSession: decryptPacketBatch - CipherState is nil")
+		return dst, nil, 0, errors.New("CipherState is nil")
+	}
+	var lengths []int
+	consumed := 0
+	for len(data)-consumed >= uint16Size {
+		length := int(binary.BigEndian.Uint16(data[consumed:]))
+		if len(data)-consumed-uint16Size < length {
+			break
+		}
+		ciphertext := data[consumed+uint16Size : consumed+uint16Size+length]
+		drop := false
+		if chaos.Enabled() {
+			ciphertext, drop = chaos.Frame(chaos.TRANSPORT_RECV, ciphertext)
+		}
+		before := len(dst)
+		var err error
+		dst, err = c.CipherState.Decrypt(dst, nil, ciphertext)
+		if err != nil {
+			log.WithError(err).Error("NoiseSession: decryptPacketBatch - failed to decrypt frame")
+			return dst[:before], lengths, consumed, err
+		}
+		consumed += uint16Size + length
+		if drop {
+			// decrypted anyway to keep the nonce in step with the sender
+			dst = dst[:before]
+			continue
+		}
+		lengths = append(lengths, len(dst)-before)
+	}
+	log.WithFields(logrus.Fields{
+		"frames":         len(lengths),
+		"consumed_bytes": consumed,
+	}).Debug("NoiseSession: decryptPacketBatch - frames decrypted")
+	return dst, lengths, consumed, nil
+}
+
+// WriteBatch encrypts several frames and writes them to the connection with
+// a single call, reusing the session's cipher state for the whole batch.
+// It returns the number of plaintext bytes written.
+func (c *NoiseSession) WriteBatch(frames [][]byte) (int, error) {
+	log.WithField("frames", len(frames)).Debug("NoiseSession: Starting WriteBatch operation")
+	// interlock with Close below
+	for {
+		x := atomic.LoadInt32(&c.activeCall)
+		if x&1 != 0 {
+			log.WithFields(logrus.Fields{
+				"at":     "(NoiseSession) WriteBatch",
+				"reason": "session is closed",
+			}).Error("session is closed")
+			return 0, errors.New("session is closed")
+		}
+		if atomic.CompareAndSwapInt32(&c.activeCall, x, x+2) {
+			defer atomic.AddInt32(&c.activeCall, -2)
+			break
+		}
+	}
+	if !c.handshakeComplete {
+		if err := c.RunOutgoingHandshake(); err != nil {
+			log.WithError(err).Error("NoiseSession: WriteBatch - failed to run outgoing handshake")
+			return 0, err
+		}
+	}
+	c.Mutex.Lock()
+	defer c.Mutex.Unlock()
+	if !c.handshakeComplete {
+		log.Error("NoiseSession: WriteBatch - internal error, handshake still not complete")
+		return 0, errors.New("internal error")
+	}
+	return c.writeFramesLocked(frames)
+}
+
+// splitFrames splits data into frames of at most MaxFramePayloadSize bytes
+func splitFrames(data []byte) [][]byte {
+	frames := make([][]byte, 0, (len(data)+MaxFramePayloadSize-1)/MaxFramePayloadSize)
+	for len(data) > MaxFramePayloadSize {
+		frames = append(frames, data[:MaxFramePayloadSize])
+		data = data[MaxFramePayloadSize:]
+	}
+	if len(data) > 0 {
+		frames = append(frames, data)
+	}
+	return frames
+}
+
+// writeFramesLocked encrypts frames in one batch into the session's send
+// buffer and writes them to the connection with a single call. It returns the
+// number of plaintext bytes written.
+func (c *NoiseSession) writeFramesLocked(frames [][]byte) (int, error) {
+	packets, n, err := c.encryptPacketBatch(c.sent[:0], frames)
+	if err != nil {
+		return 0, err
+	}
+	c.sent = packets
+	if chaos.Enabled() {
+		packets = chaosPackets(packets)
+	}
+	if _, err := c.Conn.Write(packets); err != nil {
+		log.WithError(err).Error("NoiseSession: writeFramesLocked - failed to write to connection")
+		return 0, err
+	}
+	return n, nil
+}
+
+// chaosPackets passes each packet of a batch through the transport send
+// faults, returning the packets which are not dropped
+func chaosPackets(packets []byte) []byte {
+	var out []byte
+	for len(packets) >= uint16Size {
+		size := uint16Size + int(binary.BigEndian.Uint16(packets))
+		packet, drop := chaos.Frame(chaos.TRANSPORT_SEND, packets[:size])
+		if !drop {
+			out = append(out, packet...)
+		}
+		packets = packets[size:]
+	}
+	return out
+}
@@ -0,0 +1,308 @@
+package noise
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/flynn/noise"
+	"github.com/go-i2p/go-i2p/lib/util/chaos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchTestSessions() (*NoiseSession, *NoiseSession) {
+	var key [32]byte
+	rand.Read(key[:])
+	suite := noise.NewCipherSuite(noise.DH25519, noise.CipherChaChaPoly, noise.HashSHA256)
+	sender := &NoiseSession{CipherState: noise.UnsafeNewCipherState(suite, key, 0)}
+	receiver := &NoiseSession{CipherState: noise.UnsafeNewCipherState(suite, key, 0)}
+	return sender, receiver
+}
+
+func batchTestFrames(count, size int) [][]byte {
+	frames := make([][]byte, count)
+	for i := range frames {
+		frames[i] = make([]byte, size)
+		rand.Read(frames[i])
+	}
+	return frames
+}
+
+func TestEncryptDecryptPacketBatch(t *testing.T) {
+	sender, receiver := newBatchTestSessions()
+	frames := batchTestFrames(8, 1024)
+	frames = append(frames, []byte{}, []byte("short"))
+
+	packets, n, err := sender.encryptPacketBatch(nil, frames)
+	require.NoError(t, err)
+	assert.Equal(t, 8*1024+5, n)
+
+	plaintext, lengths, consumed, err := receiver.decryptPacketBatch(nil, packets)
+	require.NoError(t, err)
+	assert.Equal(t, len(packets), consumed)
+	require.Len(t, lengths, len(frames))
+	offset := 0
+	for i, frame := range frames {
+		assert.Equal(t, len(frame), lengths[i])
+		assert.Equal(t, frame, plaintext[offset:offset+lengths[i]])
+		offset += lengths[i]
+	}
+}
+
+func TestEncryptPacketBatchMatchesSingleFrames(t *testing.T) {
+	batched, single := newBatchTestSessions()
+	frames := batchTestFrames(4, 512)
+
+	packets, _, err := batched.encryptPacketBatch(nil, frames)
+	require.NoError(t, err)
+
+	var expected []byte
+	for _, frame := range frames {
+		_, packet, err := single.encryptPacket(frame)
+		require.NoError(t, err)
+		expected = append(expected, packet...)
+	}
+	assert.Equal(t, expected, packets)
+}
+
+func TestDecryptPacketBatchPartial(t *testing.T) {
+	sender, receiver := newBatchTestSessions()
+	packets, _, err := sender.encryptPacketBatch(nil, batchTestFrames(2, 100))
+	require.NoError(t, err)
+
+	_, lengths, consumed, err := receiver.decryptPacketBatch(nil, packets[:len(packets)-1])
+	require.NoError(t, err)
+	assert.Len(t, lengths, 1)
+	assert.Equal(t, uint16Size+100+macSize, consumed)
+}
+
+func TestEncryptPacketBatchTooLarge(t *testing.T) {
+	sender, _ := newBatchTestSessions()
+	_, _, err := sender.encryptPacketBatch(nil, [][]byte{make([]byte, MaxFramePayloadSize+1)})
+	assert.ErrorIs(t, err, ErrFrameTooLarge)
+}
+
+// newPipeTestSessions returns two sessions past the handshake, connected by
+// an in-memory pipe
+func newPipeTestSessions(t *testing.T) (*NoiseSession, *NoiseSession) {
+	sender, receiver := newBatchTestSessions()
+	senderConn, receiverConn := net.Pipe()
+	t.Cleanup(func() {
+		senderConn.Close()
+		receiverConn.Close()
+	})
+	for session, conn := range map[*NoiseSession]net.Conn{sender: senderConn, receiver: receiverConn} {
+		session.NoiseTransport = &NoiseTransport{}
+		session.HandshakeState = &HandshakeState{handshakeComplete: true}
+		session.Conn = conn
+	}
+	return sender, receiver
+}
+
+func TestSessionWriteReadBatches(t *testing.T) {
+	sender, receiver := newPipeTestSessions(t)
+	// larger than a frame, so Write sends a batch of two
+	large := make([]byte, MaxFramePayloadSize+1000)
+	rand.Read(large)
+	frames := batchTestFrames(3, 1028)
+
+	written := make(chan error, 1)
+	go func() {
+		if _, err := sender.Write(large); err != nil {
+			written <- err
+			return
+		}
+		n, err := sender.WriteBatch(frames)
+		if err == nil && n != 3*1028 {
+			err = io.ErrShortWrite
+		}
+		written <- err
+	}()
+
+	expected := append(append([]byte(nil), large...), bytes.Join(frames, nil)...)
+	received := make([]byte, len(expected))
+	// small reads are served from the plaintext of the last batch
+	buf := make([]byte, 700)
+	for offset := 0; offset < len(received); {
+		n, err := receiver.Read(buf)
+		require.NoError(t, err)
+		offset += copy(received[offset:], buf[:n])
+	}
+	require.NoError(t, <-written)
+	assert.Equal(t, expected, received)
+}
+
+func TestSplitFrames(t *testing.T) {
+	assert.Empty(t, splitFrames(nil))
+	assert.Len(t, splitFrames(make([]byte, MaxFramePayloadSize)), 1)
+	frames := splitFrames(make([]byte, 2*MaxFramePayloadSize+1))
+	require.Len(t, frames, 3)
+	assert.Len(t, frames[2], 1)
+}
+
+func benchmarkEncryptSingle(b *testing.B, count, size int) {
+	sender, _ := newBatchTestSessions()
+	frames := batchTestFrames(count, size)
+	b.SetBytes(int64(count * size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, frame := range frames {
+			if _, _, err := sender.encryptPacket(frame); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchmarkEncryptBatch(b *testing.B, count, size int) {
+	sender, _ := newBatchTestSessions()
+	frames := batchTestFrames(count, size)
+	buf := make([]byte, 0, count*(uint16Size+size+macSize))
+	b.SetBytes(int64(count * size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := sender.encryptPacketBatch(buf[:0], frames); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Tunnel messages are 1028 bytes, so batches of those dominate the data phase
+func BenchmarkEncryptPacketSingle_16x1028(b *testing.B) { benchmarkEncryptSingle(b, 16, 1028) }
+func BenchmarkEncryptPacketBatch_16x1028(b *testing.B)  { benchmarkEncryptBatch(b, 16, 1028) }
+func BenchmarkEncryptPacketSingle_4x16K(b *testing.B)   { benchmarkEncryptSingle(b, 4, 16384) }
+func BenchmarkEncryptPacketBatch_4x16K(b *testing.B)    { benchmarkEncryptBatch(b, 4, 16384) }
+
+func benchmarkDecrypt(b *testing.B, count, size int, batch bool) {
+	var key [32]byte
+	rand.Read(key[:])
+	suite := noise.NewCipherSuite(noise.DH25519, noise.CipherChaChaPoly, noise.HashSHA256)
+	sender := &NoiseSession{CipherState: noise.UnsafeNewCipherState(suite, key, 0)}
+	// encrypt every packet with the nonce the receiver expects in each round
+	rounds := make([][]byte, 8)
+	for i := range rounds {
+		packets, _, err := sender.encryptPacketBatch(nil, batchTestFrames(count, size))
+		if err != nil {
+			b.Fatal(err)
+		}
+		rounds[i] = packets
+	}
+	buf := make([]byte, 0, count*size)
+	var receiver *NoiseSession
+	b.SetBytes(int64(count * size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i%len(rounds) == 0 {
+			// start over at the first nonce
+			b.StopTimer()
+			receiver = &NoiseSession{CipherState: noise.UnsafeNewCipherState(suite, key, 0)}
+			b.StartTimer()
+		}
+		packets := rounds[i%len(rounds)]
+		if batch {
+			if _, _, _, err := receiver.decryptPacketBatch(buf[:0], packets); err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+		for len(packets) > 0 {
+			size := uint16Size + int(binary.BigEndian.Uint16(packets))
+			if _, _, err := receiver.decryptPacket(packets[uint16Size:size]); err != nil {
+				b.Fatal(err)
+			}
+			packets = packets[size:]
+		}
+	}
+}
+
+func BenchmarkDecryptPacketSingle_16x1028(b *testing.B) { benchmarkDecrypt(b, 16, 1028, false) }
+func BenchmarkDecryptPacketBatch_16x1028(b *testing.B)  { benchmarkDecrypt(b, 16, 1028, true) }
+
+// chunkConn serves each chunk from one Read, the last one along with err.
+// Reading past it fails with io.ErrClosedPipe.
+type chunkConn struct {
+	net.Conn
+	chunks [][]byte
+	err    error
+}
+
+func (conn *chunkConn) Read(b []byte) (int, error) {
+	if len(conn.chunks) == 0 {
+		return 0, io.ErrClosedPipe
+	}
+	n := copy(b, conn.chunks[0])
+	conn.chunks = conn.chunks[1:]
+	if len(conn.chunks) == 0 {
+		return n, conn.err
+	}
+	return n, nil
+}
+
+func newChunkTestSession(t *testing.T, err error, frames ...[]byte) *NoiseSession {
+	sender, receiver := newBatchTestSessions()
+	conn := &chunkConn{err: err}
+	for _, frame := range frames {
+		packets, _, encErr := sender.encryptPacketBatch(nil, [][]byte{frame})
+		require.NoError(t, encErr)
+		conn.chunks = append(conn.chunks, packets)
+	}
+	receiver.NoiseTransport = &NoiseTransport{}
+	receiver.HandshakeState = &HandshakeState{handshakeComplete: true}
+	receiver.Conn = conn
+	return receiver
+}
+
+func TestSessionReadReturnsErrorAfterPlaintext(t *testing.T) {
+	frame := batchTestFrames(1, 100)[0]
+	// the packet arrives in the same read as the error
+	reset := errors.New("connection reset")
+	receiver := newChunkTestSession(t, reset, frame)
+
+	buf := make([]byte, 60)
+	n, err := receiver.Read(buf)
+	require.NoError(t, err)
+	received := append([]byte(nil), buf[:n]...)
+	n, err = receiver.Read(buf)
+	require.NoError(t, err)
+	received = append(received, buf[:n]...)
+	assert.Equal(t, frame, received)
+
+	_, err = receiver.Read(buf)
+	assert.ErrorIs(t, err, reset)
+}
+
+func TestSessionReadDropsChaosFrames(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	chaos.Set(chaos.TRANSPORT_RECV, chaos.Faults{Drop: 1})
+	receiver := newChunkTestSession(t, io.EOF, batchTestFrames(1, 100)[0])
+
+	_, err := receiver.Read(make([]byte, 100))
+	assert.ErrorIs(t, err, io.EOF)
+	assert.Equal(t, uint64(1), chaos.Stats()[chaos.TRANSPORT_RECV].Dropped)
+}
+
+func TestSessionReadContinuesAfterChaosDrop(t *testing.T) {
+	t.Cleanup(chaos.Reset)
+	frames := batchTestFrames(2, 100)
+	receiver := newChunkTestSession(t, io.EOF, frames...)
+
+	chaos.Set(chaos.TRANSPORT_RECV, chaos.Faults{Drop: 1})
+	receiver.Mutex.Lock()
+	require.NoError(t, receiver.receivePacketsLocked())
+	receiver.Mutex.Unlock()
+	assert.Empty(t, receiver.plaintext)
+	chaos.Reset()
+
+	buf := make([]byte, 100)
+	n, err := receiver.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, frames[1], buf[:n])
+}
@@ -0,0 +1,51 @@
+package noise
+
+import (
+	"crypto/rand"
+	"io"
+	"sync"
+
+	"github.com/flynn/noise"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+// EPHEMERAL_KEY_POOL_SIZE is the number of Elligator2-encodable ephemeral
+// keys kept ready for handshakes
+const EPHEMERAL_KEY_POOL_SIZE = 16
+
+var (
+	ephemeralKeysOnce sync.Once
+	ephemeralKeys     *crypto.Elligator2KeyPool
+)
+
+// ephemeralKeyPool returns the pool shared by all handshakes, starting it on
+// first use.
+func ephemeralKeyPool() *crypto.Elligator2KeyPool {
+	ephemeralKeysOnce.Do(func() {
+		ephemeralKeys = crypto.NewElligator2KeyPool(EPHEMERAL_KEY_POOL_SIZE)
+	})
+	return ephemeralKeys
+}
+
+// pooledDH25519 is noise.DH25519 with ephemeral keys taken from the
+// Elligator2 key pool, so that handshakes do not wait for key generation.
+// Handshakes given a fixed ephemeral key through their random source still
+// derive it from that source.
+type pooledDH25519 struct {
+	noise.DHFunc
+}
+
+var dh25519 noise.DHFunc = pooledDH25519{noise.DH25519}
+
+func (dh pooledDH25519) GenerateKeypair(random io.Reader) (noise.DHKey, error) {
+	if random != nil && random != rand.Reader {
+		return dh.DHFunc.GenerateKeypair(random)
+	}
+	key, err := ephemeralKeyPool().Get()
+	if err != nil {
+		log.WithError(err).Error("Failed to get ephemeral key from pool")
+		return noise.DHKey{}, err
+	}
+	return noise.DHKey{Private: key.PrivateKey, Public: key.PublicKey}, nil
+}
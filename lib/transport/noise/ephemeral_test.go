@@ -0,0 +1,67 @@
+package noise
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/flynn/noise"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+func TestHandshakeUsesPooledEphemeralKeys(t *testing.T) {
+	initiatorStatic, err := noise.DH25519.GenerateKeypair(rand.Reader)
+	require.NoError(t, err)
+	responderStatic, err := noise.DH25519.GenerateKeypair(rand.Reader)
+	require.NoError(t, err)
+
+	suite := noise.NewCipherSuite(dh25519, noise.CipherAESGCM, noise.HashSHA256)
+	initiator, err := noise.NewHandshakeState(noise.Config{
+		CipherSuite:   suite,
+		Pattern:       noise.HandshakeXK,
+		Initiator:     true,
+		StaticKeypair: initiatorStatic,
+		PeerStatic:    responderStatic.Public,
+	})
+	require.NoError(t, err)
+	msg1, _, _, err := initiator.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	// the pool only hands out keys whose public key has an Elligator2 representative
+	ephemeral := msg1[:noise.DH25519.DHLen()]
+	_, err = crypto.Elligator2Encode(ephemeral, 0)
+	assert.NoError(t, err)
+
+	responder, err := noise.NewHandshakeState(noise.Config{
+		CipherSuite:   suite,
+		Pattern:       noise.HandshakeXK,
+		StaticKeypair: responderStatic,
+	})
+	require.NoError(t, err)
+	_, _, _, err = responder.ReadMessage(nil, msg1)
+	require.NoError(t, err)
+	msg2, _, _, err := responder.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	_, err = crypto.Elligator2Encode(msg2[:noise.DH25519.DHLen()], 0)
+	assert.NoError(t, err)
+
+	_, _, _, err = initiator.ReadMessage(nil, msg2)
+	require.NoError(t, err)
+	msg3, _, _, err := initiator.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	_, _, _, err = responder.ReadMessage(nil, msg3)
+	require.NoError(t, err)
+	assert.Equal(t, initiator.ChannelBinding(), responder.ChannelBinding())
+}
+
+func TestFixedEphemeralKeyBypassesPool(t *testing.T) {
+	ePrivate := make([]byte, noise.DH25519.DHLen())
+	ePrivate[0] = 1
+	want, err := noise.DH25519.GenerateKeypair(bytes.NewReader(ePrivate))
+	require.NoError(t, err)
+	got, err := dh25519.GenerateKeypair(bytes.NewReader(ePrivate))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/util/chaos"
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 
 	"github.com/flynn/noise"
@@ -24,7 +25,7 @@ func NewHandshakeState(staticKey noise.DHKey, isInitiator bool) (*HandshakeState
 	}
 
 	config := noise.Config{
-		CipherSuite:   noise.NewCipherSuite(noise.DH25519, noise.CipherAESGCM, noise.HashSHA256),
+		CipherSuite:   noise.NewCipherSuite(dh25519, noise.CipherAESGCM, noise.HashSHA256),
 		Pattern:       hs.pattern,
 		Initiator:     isInitiator,
 		StaticKeypair: staticKey,
@@ -45,7 +46,7 @@ func (h *HandshakeState) GenerateEphemeral() (*noise.DHKey, error) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
-	dhKey, err := noise.DH25519.GenerateKeypair(nil)
+	dhKey, err := dh25519.GenerateKeypair(nil)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +81,7 @@ var log = logger.GetGoI2PLogger()
 
 func (c *NoiseTransport) Handshake(routerInfo router_info.RouterInfo) error {
 	log.WithField("router_info", routerInfo.IdentHash()).Debug("Starting Noise handshake")
+	chaos.Wait(chaos.HANDSHAKE)
 	c.Mutex.Lock()
 	defer c.Mutex.Unlock()
 	session, err := c.getSession(routerInfo)
@@ -64,7 +64,7 @@ func ComposeReceiverHandshakeMessage(s noise.DHKey, rs []byte, payload []byte, e
 	}
 
 	config := noise.Config{
-		CipherSuite:   noise.NewCipherSuite(noise.DH25519, noise.CipherAESGCM, noise.HashSHA256),
+		CipherSuite:   noise.NewCipherSuite(dh25519, noise.CipherAESGCM, noise.HashSHA256),
 		Pattern:       pattern,
 		Initiator:     false,
 		StaticKeypair: s,
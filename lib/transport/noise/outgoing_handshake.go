@@ -65,7 +65,7 @@ func ComposeInitiatorHandshakeMessage(s noise.DHKey, rs []byte, payload []byte,
 	}
 
 	config := noise.Config{
-		CipherSuite:   noise.NewCipherSuite(noise.DH25519, noise.CipherAESGCM, noise.HashSHA256),
+		CipherSuite:   noise.NewCipherSuite(dh25519, noise.CipherAESGCM, noise.HashSHA256),
 		Pattern:       pattern,
 		Initiator:     true,
 		StaticKeypair: s,
@@ -47,6 +47,8 @@ func (c *NoiseSession) Read(b []byte) (int, error) {
 	return n, err
 }
 
+// decryptPacket decrypts a single packet without its length prefix. The data
+// phase batches packets with decryptPacketBatch instead.
 func (c *NoiseSession) decryptPacket(data []byte) (int, []byte, error) {
 	log.WithField("data_length", len(data)).Debug("Starting packet decryption")
 
@@ -84,42 +86,62 @@ func (c *NoiseSession) decryptPacket(data []byte) (int, []byte, error) {
 	//c.freeBlock(packet)
 }
 
+// readPacketLocked fills data with plaintext, reading and decrypting more
+// packets if none is left from the previous batch
 func (c *NoiseSession) readPacketLocked(data []byte) (int, error) {
 	log.WithField("data_length", len(data)).Debug("Starting readPacketLocked")
 
-	var n int
 	if len(data) == 0 { // special case to answer when everything is ok during handshake
 		log.Debug("readPacketLocked: special case - reading 2 bytes during handshake")
 		if _, err := c.Conn.Read(make([]byte, 2)); err != nil {
 			log.WithError(err).Error("readPacketLocked: failed to read 2 bytes during handshake")
 			return 0, err
 		}
+		return 0, nil
 	}
-	for len(data) > 0 {
-		m, b, err := c.encryptPacket(data)
-		if err != nil {
-			log.WithError(err).Error("readPacketLocked: failed to encrypt packet")
-			return 0, err
+	for len(c.plaintext) == 0 {
+		if c.readErr != nil {
+			log.WithError(c.readErr).Debug("readPacketLocked: returning deferred read error")
+			return 0, c.readErr
 		}
-		/*
-			if n, err := c.Conn.Read(b); err != nil {
-				return n, err
-			} else {
-				n += m
-				data = data[m:]
-			}
-		*/
-		n, err := c.Conn.Read(b)
-		if err != nil {
-			log.WithError(err).WithField("bytes_read (aka n)", n).Error("readPacketLocked: failed to read from connection")
-			return n, err
+		if err := c.receivePacketsLocked(); err != nil {
+			log.WithError(err).Error("readPacketLocked: failed to receive packets")
+			return 0, err
 		}
-		n += m
-		data = data[m:]
-		log.WithFields(logrus.Fields{
-			"bytes_read":     n,
-			"remaining_data": len(data),
-		}).Debug("readPacketLocked: read packet chunk")
 	}
+	n := copy(data, c.plaintext)
+	c.plaintext = c.plaintext[n:]
+	log.WithFields(logrus.Fields{
+		"bytes_read":      n,
+		"plaintext_left":  len(c.plaintext),
+		"ciphertext_left": len(c.received),
+	}).Debug("readPacketLocked: read plaintext")
 	return n, nil
 }
+
+// receivePacketsLocked reads once from the connection and decrypts every
+// complete packet received so far in one batch. A trailing partial packet is
+// kept for the next call. A read error is returned right away if nothing was
+// decrypted, otherwise kept until the plaintext has been read.
+func (c *NoiseSession) receivePacketsLocked() error {
+	if c.received == nil {
+		// room for a whole packet after a partial one
+		c.received = make([]byte, 0, 2*MaxPayloadSize)
+	}
+	start := len(c.received)
+	n, readErr := c.Conn.Read(c.received[start:cap(c.received)])
+	c.received = c.received[:start+n]
+
+	plaintext, _, consumed, err := c.decryptPacketBatch(c.plaintextBuf[:0], c.received)
+	c.plaintextBuf = plaintext
+	c.plaintext = plaintext
+	c.received = c.received[:copy(c.received, c.received[consumed:])]
+	if err != nil {
+		return err
+	}
+	if len(c.plaintext) == 0 {
+		return readErr
+	}
+	c.readErr = readErr
+	return nil
+}
@@ -26,6 +26,16 @@ type NoiseSession struct {
 	VerifyCallback VerifyCallbackFunc
 	activeCall     int32
 	Conn           net.Conn
+	// data phase buffers reused across calls: the packets last written,
+	// received bytes not decrypted yet, and decrypted plaintext with the
+	// part of it not read yet
+	sent         []byte
+	received     []byte
+	plaintextBuf []byte
+	plaintext    []byte
+	// readErr is the error of a connection read which also returned
+	// plaintext, returned once that plaintext has been read
+	readErr error
 }
 
 // RemoteAddr implements net.Conn
@@ -101,11 +111,17 @@ func newBlock() []byte {
 type VerifyCallbackFunc func(publicKey []byte, data []byte) error
 
 func NewNoiseTransportSession(ri router_info.RouterInfo) (transport.TransportSession, error) {
+	return dialNoiseTransportSession(ri, &net.Dialer{})
+}
+
+// dialNoiseTransportSession creates a session with the router, connecting
+// with dialer so its socket options are applied
+func dialNoiseTransportSession(ri router_info.RouterInfo, dialer *net.Dialer) (transport.TransportSession, error) {
 	log.WithField("router_info", ri.String()).Debug("Creating new NoiseTransportSession")
 	// socket, err := DialNoise("noise", ri)
 	for _, addr := range ri.RouterAddresses() {
 		log.WithField("address", string(addr.Bytes())).Debug("Attempting to dial")
-		socket, err := net.Dial("tcp", string(addr.Bytes()))
+		socket, err := dialer.Dial("tcp", string(addr.Bytes()))
 		if err != nil {
 			log.WithError(err).Error("Failed to dial address")
 			return nil, err
@@ -7,6 +7,7 @@ package noise
 **/
 
 import (
+	"context"
 	"errors"
 	"net"
 	"sync"
@@ -17,6 +18,7 @@ import (
 	"github.com/go-i2p/go-i2p/lib/common/router_identity"
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
 	"github.com/go-i2p/go-i2p/lib/transport"
+	"github.com/go-i2p/go-i2p/lib/transport/sockopt"
 )
 
 type NoiseTransport struct {
@@ -24,6 +26,8 @@ type NoiseTransport struct {
 	router_identity.RouterIdentity
 	Listener        net.Listener
 	peerConnections map[data.Hash]transport.TransportSession
+	// dials outgoing sessions, with the transport's socket options
+	dialer *net.Dialer
 }
 
 func (noopt *NoiseTransport) Compatible(routerInfo router_info.RouterInfo) bool {
@@ -103,7 +107,7 @@ func (noopt *NoiseTransport) GetSession(routerInfo router_info.RouterInfo) (tran
 	}
 	log.Debug("NoiseTransport: Creating new session")
 	var err error
-	if noopt.peerConnections[hash], err = NewNoiseTransportSession(routerInfo); err != nil {
+	if noopt.peerConnections[hash], err = dialNoiseTransportSession(routerInfo, noopt.dialer); err != nil {
 		log.WithError(err).Error("NoiseTransport: Failed to create new session")
 		return noopt.peerConnections[hash], err
 	}
@@ -158,6 +162,7 @@ func NewNoiseTransport(netSocket net.Listener) *NoiseTransport {
 	return &NoiseTransport{
 		peerConnections: make(map[data.Hash]transport.TransportSession),
 		Listener:        netSocket,
+		dialer:          &net.Dialer{},
 	}
 }
 
@@ -175,3 +180,23 @@ func NewNoiseTransportSocket() (*NoiseTransport, error) {
 	log.WithField("addr", netSocket.Addr().String()).Debug("Created new NoiseTransportSocket")
 	return _transport, nil
 }
+
+// ListenNoiseTransport creates a Noise transport listening on address, with
+// the socket options set on the listener and on outgoing sessions.
+func ListenNoiseTransport(address string, options sockopt.Options) (*NoiseTransport, error) {
+	log.WithFields(logrus.Fields{
+		"address": address,
+		"options": options,
+	}).Debug("Creating new NoiseTransport with socket options")
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+	netSocket, err := options.ListenConfig().Listen(context.Background(), "tcp", address)
+	if err != nil {
+		log.WithError(err).Error("Failed to create listener for NoiseTransport")
+		return nil, err
+	}
+	_transport := NewNoiseTransport(netSocket)
+	_transport.dialer = options.Dialer()
+	return _transport, nil
+}
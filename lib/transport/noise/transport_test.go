@@ -1,8 +1,11 @@
 package noise
 
 import (
+	"errors"
 	"net"
 	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/transport/sockopt"
 )
 
 func TestTransport(t *testing.T) {
@@ -31,3 +34,15 @@ func TestTransport(t *testing.T) {
 	t.Log(ntt.Name())
 	// ntt.GetSession()
 }
+
+func TestListenNoiseTransportRejectsInvalidOptions(t *testing.T) {
+	_, err := ListenNoiseTransport("127.0.0.1:0", sockopt.Options{DSCP: 64})
+	if !errors.Is(err, sockopt.ErrInvalidDSCP) {
+		t.Errorf("expected ErrInvalidDSCP, got %v", err)
+	}
+	nt, err := ListenNoiseTransport("127.0.0.1:0", sockopt.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nt.Listener.Close()
+}
@@ -49,6 +49,8 @@ func (c *NoiseSession) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// encryptPacket encrypts data as a single length-prefixed packet. The data
+// phase batches frames with encryptPacketBatch instead.
 func (c *NoiseSession) encryptPacket(data []byte) (int, []byte, error) {
 	log.WithField("data_length", len(data)).Debug("NoiseSession: Starting packet encryption")
 
@@ -98,36 +100,23 @@ func (c *NoiseSession) encryptPacket(data []byte) (int, []byte, error) {
 	//c.freeBlock(packet)
 }
 
+// writePacketLocked splits data into frames and writes them as one batch
 func (c *NoiseSession) writePacketLocked(data []byte) (int, error) {
 	log.WithField("data_length", len(data)).Debug("NoiseSession: Starting writePacketLocked")
 
-	var n int
 	if len(data) == 0 { // special case to answer when everything is ok during handshake
 		log.Debug("NoiseSession: writePacketLocked - special case, writing 2 empty bytes")
 		if _, err := c.Conn.Write(make([]byte, 2)); err != nil {
 			log.WithError(err).Error("NoiseSession: writePacketLocked - failed to write empty bytes")
 			return 0, err
 		}
+		return 0, nil
 	}
-	for len(data) > 0 {
-		m, b, err := c.encryptPacket(data)
-		if err != nil {
-			log.WithError(err).Error("NoiseSession: writePacketLocked - failed to encrypt packet")
-			return 0, err
-		}
-		if n, err := c.Conn.Write(b); err != nil {
-			log.WithError(err).WithField("bytes_written", n).Error("NoiseSession: writePacketLocked - failed to write to connection")
-			return n, err
-		} else {
-			n += m
-			data = data[m:]
-			log.WithFields(logrus.Fields{
-				"bytes_written":  n,
-				"remaining_data": len(data),
-			}).Debug("NoiseSession: writePacketLocked - wrote packet chunk")
-		}
+	n, err := c.writeFramesLocked(splitFrames(data))
+	if err != nil {
+		log.WithError(err).Error("NoiseSession: writePacketLocked - failed to write frames")
+		return 0, err
 	}
-
 	log.WithField("total_bytes_written", n).Debug("NoiseSession: writePacketLocked - completed writing all packets")
 	return n, nil
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/go-i2p/go-i2p/lib/common/router_info"
+	"github.com/go-i2p/go-i2p/lib/crypto"
 	"github.com/go-i2p/go-i2p/lib/transport/noise"
 )
 
@@ -68,7 +69,7 @@ func (s *NTCP2Session) ObfuscateEphemeral(key []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	block, err := aes.NewCipher(static[:])
+	block, err := crypto.NewAESCipher(static[:])
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +88,7 @@ func (s *NTCP2Session) DeobfuscateEphemeral(obfuscated []byte) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	block, err := aes.NewCipher(static[:])
+	block, err := crypto.NewAESCipher(static[:])
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,71 @@
+package ntcp
+
+import (
+	"encoding/binary"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+)
+
+// SIPKEYS_SIZE is the size of the SipHash keys and IV of one direction of
+// the data phase: k1, k2 and the IV, 8 bytes each
+const SIPKEYS_SIZE = 24
+
+// SipKeys are the SipHash keys and initial IV obfuscating the frame lengths
+// sent in one direction of the data phase.
+type SipKeys [SIPKEYS_SIZE]byte
+
+// DeriveSipKeys derives the SipHash keys of the data phase from the chaining
+// key ck and the handshake hash h at the end of the handshake, for Alice to
+// Bob and Bob to Alice:
+//
+//	ask_master = HKDF(ck, zerolen, info="ask")
+//	sip_master = HKDF(ask_master, h || "siphash")
+//	sipkeys_ab, sipkeys_ba = HKDF(sip_master, zerolen)
+func DeriveSipKeys(ck, h [32]byte) (aliceToBob, bobToAlice SipKeys) {
+	askMaster := crypto.HKDF(ck[:], nil, "ask", 32)
+	sipMaster := crypto.HKDF(askMaster, append(h[:], "siphash"...), "", 32)
+	ab, ba := crypto.HKDF2(sipMaster, nil, "")
+	copy(aliceToBob[:], ab[:])
+	copy(bobToAlice[:], ba[:])
+	return
+}
+
+// LengthObfuscator obfuscates the 2 byte frame lengths of one direction of
+// the data phase. Every length is XORed with a mask taken from the next IV,
+// IV[n] = SipHash-2-4(k1, k2, IV[n-1]), so the sender and receiver of a
+// direction each keep one, fed the frames in the same order.
+type LengthObfuscator struct {
+	k0, k1 uint64
+	iv     [8]byte
+}
+
+// NewLengthObfuscator returns the obfuscator of the direction keys are
+// derived for.
+func NewLengthObfuscator(keys SipKeys) *LengthObfuscator {
+	var key [crypto.SIPHASH_KEY_SIZE]byte
+	copy(key[:], keys[:crypto.SIPHASH_KEY_SIZE])
+	obfuscator := &LengthObfuscator{}
+	obfuscator.k0, obfuscator.k1 = crypto.SipHashKey(key)
+	copy(obfuscator.iv[:], keys[crypto.SIPHASH_KEY_SIZE:])
+	return obfuscator
+}
+
+// nextMask advances the IV and returns the mask of the next length: its
+// first 2 bytes, read as a little endian integer
+func (obfuscator *LengthObfuscator) nextMask() uint16 {
+	binary.LittleEndian.PutUint64(obfuscator.iv[:], crypto.SipHash24(obfuscator.k0, obfuscator.k1, obfuscator.iv[:]))
+	return binary.LittleEndian.Uint16(obfuscator.iv[:])
+}
+
+// Obfuscate returns the obfuscated length field of the next frame sent.
+func (obfuscator *LengthObfuscator) Obfuscate(length uint16) [2]byte {
+	var field [2]byte
+	binary.BigEndian.PutUint16(field[:], length^obfuscator.nextMask())
+	return field
+}
+
+// Deobfuscate returns the length of the next frame received from its
+// obfuscated length field.
+func (obfuscator *LengthObfuscator) Deobfuscate(field [2]byte) uint16 {
+	return binary.BigEndian.Uint16(field[:]) ^ obfuscator.nextMask()
+}
@@ -0,0 +1,69 @@
+package ntcp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/go-i2p/go-i2p/lib/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func hmacSHA256(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func TestDeriveSipKeys(t *testing.T) {
+	var ck, h [32]byte
+	ck[0], h[0] = 1, 2
+	ab, ba := DeriveSipKeys(ck, h)
+	assert.NotEqual(t, ab, ba)
+
+	// the KDF as the spec spells it out
+	tempKey := hmacSHA256(ck[:], nil)
+	askMaster := hmacSHA256(tempKey, []byte("ask"), []byte{0x01})
+	tempKey = hmacSHA256(askMaster, h[:], []byte("siphash"))
+	sipMaster := hmacSHA256(tempKey, []byte{0x01})
+	tempKey = hmacSHA256(sipMaster, nil)
+	sipkeysAB := hmacSHA256(tempKey, []byte{0x01})
+	sipkeysBA := hmacSHA256(tempKey, sipkeysAB, []byte{0x02})
+	assert.Equal(t, sipkeysAB[:SIPKEYS_SIZE], ab[:])
+	assert.Equal(t, sipkeysBA[:SIPKEYS_SIZE], ba[:])
+
+	ab2, ba2 := DeriveSipKeys(ck, h)
+	assert.Equal(t, ab, ab2)
+	assert.Equal(t, ba, ba2)
+
+	h[0] = 3
+	ab3, _ := DeriveSipKeys(ck, h)
+	assert.NotEqual(t, ab, ab3, "keys depend on the handshake hash")
+}
+
+func TestLengthObfuscation(t *testing.T) {
+	var ck, h [32]byte
+	ck[0] = 1
+	keys, _ := DeriveSipKeys(ck, h)
+	sender := NewLengthObfuscator(keys)
+	receiver := NewLengthObfuscator(keys)
+
+	lengths := []uint16{0, 1, 16, 1500, MaxPayloadSize}
+	fields := make([][2]byte, len(lengths))
+	for i, length := range lengths {
+		fields[i] = sender.Obfuscate(length)
+		assert.Equal(t, length, receiver.Deobfuscate(fields[i]))
+	}
+	fresh := NewLengthObfuscator(keys)
+	assert.NotEqual(t, fresh.Obfuscate(1500), fresh.Obfuscate(1500), "the mask changes with every frame")
+
+	// the first length is masked with IV[1], the SipHash of the initial IV
+	k0 := binary.LittleEndian.Uint64(keys[0:8])
+	k1 := binary.LittleEndian.Uint64(keys[8:16])
+	iv1 := crypto.SipHash24(k0, k1, keys[16:24])
+	expected := uint16(1500) ^ uint16(iv1)
+	assert.Equal(t, [2]byte{byte(expected >> 8), byte(expected)}, NewLengthObfuscator(keys).Obfuscate(1500))
+}
@@ -0,0 +1,92 @@
+// Package sockopt applies the socket options operators set for the
+// transports: traffic class marking for QoS, SO_REUSEPORT and binding to a
+// network interface for running several routers on one host.
+package sockopt
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// highest DSCP code point, it is a 6 bit field
+const MAX_DSCP = 63
+
+var (
+	ErrInvalidDSCP = errors.New("DSCP code point out of range")
+	ErrUnsupported = errors.New("socket option not supported on this platform")
+)
+
+// Options are the socket options set on transport sockets. The zero value
+// leaves sockets as the operating system creates them.
+type Options struct {
+	// DSCP code point marked on outgoing packets, 0 for none. It is written
+	// to the upper 6 bits of the IPv4 TOS or IPv6 traffic class byte.
+	DSCP int
+	// ReusePort sets SO_REUSEPORT, so several processes can listen on the
+	// same port and the kernel balances connections between them
+	ReusePort bool
+	// Interface binds sockets to the named network interface
+	Interface string
+}
+
+// IsZero reports whether no option is set
+func (options Options) IsZero() bool {
+	return options == Options{}
+}
+
+// Validate checks the options are in range and supported on this platform.
+func (options Options) Validate() error {
+	if options.DSCP < 0 || options.DSCP > MAX_DSCP {
+		return fmt.Errorf("%w: %d", ErrInvalidDSCP, options.DSCP)
+	}
+	return options.supported()
+}
+
+// Control sets the options on a socket before it is bound or connected. It
+// has the signature of net.ListenConfig.Control and net.Dialer.Control.
+func (options Options) Control(network, address string, c syscall.RawConn) error {
+	if options.IsZero() {
+		return nil
+	}
+	var err error
+	if controlErr := c.Control(func(fd uintptr) {
+		err = options.apply(fd, ipv6Network(network))
+	}); controlErr != nil {
+		return controlErr
+	}
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"network": network,
+			"address": address,
+		}).WithError(err).Error("Failed to set socket options")
+	}
+	return err
+}
+
+// ListenConfig returns a net.ListenConfig setting the options on listeners
+func (options Options) ListenConfig() *net.ListenConfig {
+	return &net.ListenConfig{Control: options.Control}
+}
+
+// Dialer returns a net.Dialer setting the options on outgoing connections
+func (options Options) Dialer() *net.Dialer {
+	return &net.Dialer{Control: options.Control}
+}
+
+// trafficClass returns the TOS or traffic class byte of the DSCP code point,
+// leaving the ECN bits clear
+func (options Options) trafficClass() int {
+	return options.DSCP << 2
+}
+
+func ipv6Network(network string) bool {
+	return strings.HasSuffix(network, "6")
+}
@@ -0,0 +1,37 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package sockopt
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+func (options Options) supported() error {
+	if options.Interface != "" {
+		return fmt.Errorf("%w: binding to an interface", ErrUnsupported)
+	}
+	return nil
+}
+
+func (options Options) apply(fd uintptr, ipv6 bool) error {
+	if err := options.supported(); err != nil {
+		return err
+	}
+	if options.ReusePort {
+		if err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+			return err
+		}
+	}
+	if options.DSCP != 0 {
+		level, option := unix.IPPROTO_IP, unix.IP_TOS
+		if ipv6 {
+			level, option = unix.IPPROTO_IPV6, unix.IPV6_TCLASS
+		}
+		if err := unix.SetsockoptInt(int(fd), level, option, options.trafficClass()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
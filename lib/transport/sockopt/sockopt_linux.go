@@ -0,0 +1,35 @@
+package sockopt
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func (options Options) supported() error {
+	return nil
+}
+
+func (options Options) apply(fd uintptr, ipv6 bool) error {
+	if options.ReusePort {
+		if err := unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1); err != nil {
+			return err
+		}
+	}
+	if options.DSCP != 0 {
+		if ipv6 {
+			if err := unix.SetsockoptInt(int(fd), unix.IPPROTO_IPV6, unix.IPV6_TCLASS, options.trafficClass()); err != nil {
+				return err
+			}
+			// dual stack sockets send IPv4 packets with the TOS byte, which
+			// IPv6 only sockets do not have
+			_ = unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TOS, options.trafficClass())
+		} else if err := unix.SetsockoptInt(int(fd), unix.IPPROTO_IP, unix.IP_TOS, options.trafficClass()); err != nil {
+			return err
+		}
+	}
+	if options.Interface != "" {
+		if err := unix.BindToDevice(int(fd), options.Interface); err != nil {
+			return err
+		}
+	}
+	return nil
+}
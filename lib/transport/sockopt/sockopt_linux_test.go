@@ -0,0 +1,49 @@
+package sockopt
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func getsockopt(t *testing.T, conn syscall.Conn, level, option int) int {
+	raw, err := conn.SyscallConn()
+	require.NoError(t, err)
+	var value int
+	var sockErr error
+	require.NoError(t, raw.Control(func(fd uintptr) {
+		value, sockErr = unix.GetsockoptInt(int(fd), level, option)
+	}))
+	require.NoError(t, sockErr)
+	return value
+}
+
+func TestListenSetsOptions(t *testing.T) {
+	options := Options{DSCP: 46, ReusePort: true}
+	ln, err := options.ListenConfig().Listen(context.Background(), "tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	tcp := ln.(*net.TCPListener)
+	assert.Equal(t, 1, getsockopt(t, tcp, unix.SOL_SOCKET, unix.SO_REUSEPORT))
+	assert.Equal(t, 0xb8, getsockopt(t, tcp, unix.IPPROTO_IP, unix.IP_TOS))
+
+	// a second listener shares the port
+	second, err := options.ListenConfig().Listen(context.Background(), "tcp4", ln.Addr().String())
+	require.NoError(t, err)
+	second.Close()
+}
+
+func TestDialerSetsOptions(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	conn, err := Options{DSCP: 10}.Dialer().Dial("tcp4", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, 40, getsockopt(t, conn.(*net.TCPConn), unix.IPPROTO_IP, unix.IP_TOS))
+}
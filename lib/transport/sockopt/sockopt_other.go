@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package sockopt
+
+func (options Options) supported() error {
+	if !options.IsZero() {
+		return ErrUnsupported
+	}
+	return nil
+}
+
+func (options Options) apply(fd uintptr, ipv6 bool) error {
+	return options.supported()
+}
@@ -0,0 +1,27 @@
+package sockopt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Options{}.Validate())
+	assert.ErrorIs(t, Options{DSCP: -1}.Validate(), ErrInvalidDSCP)
+	assert.ErrorIs(t, Options{DSCP: MAX_DSCP + 1}.Validate(), ErrInvalidDSCP)
+}
+
+func TestZeroOptionsListen(t *testing.T) {
+	assert.True(t, Options{}.IsZero())
+	ln, err := Options{}.ListenConfig().Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if assert.NoError(t, err) {
+		ln.Close()
+	}
+}
+
+func TestTrafficClass(t *testing.T) {
+	// expedited forwarding is TOS byte 0xb8
+	assert.Equal(t, 0xb8, Options{DSCP: 46}.trafficClass())
+}
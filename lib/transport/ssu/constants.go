@@ -0,0 +1,5 @@
+package ssu
+
+// SSU2_PROTOCOL_NAME is the transport style of SSU2 router addresses and the
+// name of the SSU2 transport
+const SSU2_PROTOCOL_NAME = "SSU2"
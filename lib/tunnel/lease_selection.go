@@ -0,0 +1,244 @@
+package tunnel
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/lease"
+	"github.com/sirupsen/logrus"
+)
+
+// Strategies choosing which lease of a remote LeaseSet outbound messages go to
+const (
+	// LEASE_STRATEGY_RANDOM picks an unexpired lease at random
+	LEASE_STRATEGY_RANDOM = "random"
+	// LEASE_STRATEGY_LATENCY picks the lease with the lowest round trip time
+	// learned so far, trying leases without measurements first
+	LEASE_STRATEGY_LATENCY = "latency"
+	// LEASE_STRATEGY_ROUND_ROBIN cycles through the leases of each
+	// destination in turn
+	LEASE_STRATEGY_ROUND_ROBIN = "round-robin"
+)
+
+// weight of a new round trip time in the learned average
+const LATENCY_SMOOTHING = 0.25
+
+var (
+	ErrUnknownLeaseStrategy = errors.New("unknown lease selection strategy")
+	ErrNoUsableLease        = errors.New("remote LeaseSet has no usable lease")
+)
+
+// LeaseSelectionStats counts the choices a LeaseSelector made.
+type LeaseSelectionStats struct {
+	// Selections counts every lease chosen
+	Selections uint64
+	// StickyHits counts choices which kept the destination's previous lease
+	StickyHits uint64
+	// Switches counts choices which moved a destination to another lease
+	Switches uint64
+}
+
+// leaseKey identifies the inbound tunnel a lease points to
+type leaseKey struct {
+	gateway  common.Hash
+	tunnelID uint32
+}
+
+func keyOf(l lease.Lease) leaseKey {
+	return leaseKey{gateway: l.TunnelGateway(), tunnelID: l.TunnelID()}
+}
+
+// learnedLatency is the average round trip time through a lease, kept until
+// the lease expires
+type learnedLatency struct {
+	rtt     time.Duration
+	expires time.Time
+}
+
+// stickyLease is the lease a destination is kept on until
+type stickyLease struct {
+	lease lease.Lease
+	until time.Time
+}
+
+// LeaseSelector chooses the lease outbound messages to a destination are
+// sent to. Once chosen, a destination keeps its lease for the stickiness
+// period while the lease stays in its LeaseSet and unexpired, so messages of
+// one conversation follow the same path. It is safe for concurrent use.
+type LeaseSelector struct {
+	strategy   string
+	stickiness time.Duration
+	grace      time.Duration
+
+	mutex   sync.Mutex
+	sticky  map[common.Hash]stickyLease
+	next    map[common.Hash]int
+	latency map[leaseKey]learnedLatency
+	stats   LeaseSelectionStats
+	random  *rand.Rand
+}
+
+// NewLeaseSelector returns a selector using one of the LEASE_STRATEGY_*
+// strategies. A stickiness of 0 chooses anew for every message.
+func NewLeaseSelector(strategy string, stickiness time.Duration) (*LeaseSelector, error) {
+	switch strategy {
+	case LEASE_STRATEGY_RANDOM, LEASE_STRATEGY_LATENCY, LEASE_STRATEGY_ROUND_ROBIN:
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLeaseStrategy, strategy)
+	}
+	return &LeaseSelector{
+		strategy:   strategy,
+		stickiness: stickiness,
+		grace:      lease.DEFAULT_LEASE_GRACE,
+		sticky:     make(map[common.Hash]stickyLease),
+		next:       make(map[common.Hash]int),
+		latency:    make(map[leaseKey]learnedLatency),
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}, nil
+}
+
+// Strategy returns the name of the strategy in use.
+func (selector *LeaseSelector) Strategy() string {
+	return selector.strategy
+}
+
+// SetGrace sets how long past their end date leases are still used, when a
+// LeaseSet has no unexpired lease left. It defaults to
+// lease.DEFAULT_LEASE_GRACE.
+func (selector *LeaseSelector) SetGrace(grace time.Duration) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	selector.grace = grace
+}
+
+// Select returns the lease to send the next message for dest to, out of
+// the leases of its LeaseSet. Unexpired leases are preferred; leases within
+// the grace period are used only if none is left.
+func (selector *LeaseSelector) Select(dest common.Hash, leases []lease.Lease, now time.Time) (lease.Lease, error) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	usable := usableLeases(leases, now, 0)
+	if len(usable) == 0 {
+		usable = usableLeases(leases, now, selector.grace)
+		if len(usable) > 0 {
+			log.WithField("leases", len(usable)).Debug("Using leases within the expiration grace period")
+		}
+	}
+	if len(usable) == 0 {
+		delete(selector.sticky, dest)
+		return lease.Lease{}, ErrNoUsableLease
+	}
+
+	previous, hadPrevious := selector.sticky[dest]
+	if hadPrevious && now.Before(previous.until) {
+		for _, l := range usable {
+			if keyOf(l) == keyOf(previous.lease) {
+				selector.stats.Selections++
+				selector.stats.StickyHits++
+				return l, nil
+			}
+		}
+	}
+
+	chosen := selector.choose(dest, usable)
+	selector.stats.Selections++
+	if hadPrevious && keyOf(chosen) != keyOf(previous.lease) {
+		selector.stats.Switches++
+		log.WithFields(logrus.Fields{
+			"strategy":  selector.strategy,
+			"tunnel_id": chosen.TunnelID(),
+		}).Debug("Switched destination to another lease")
+	}
+	if selector.stickiness > 0 {
+		selector.sticky[dest] = stickyLease{lease: chosen, until: now.Add(selector.stickiness)}
+	} else {
+		selector.sticky[dest] = stickyLease{lease: chosen}
+	}
+	return chosen, nil
+}
+
+// usableLeases returns the leases not expired at now, allowing grace
+func usableLeases(leases []lease.Lease, now time.Time, grace time.Duration) []lease.Lease {
+	usable := make([]lease.Lease, 0, len(leases))
+	for _, l := range leases {
+		if !l.Expired(now, grace) {
+			usable = append(usable, l)
+		}
+	}
+	return usable
+}
+
+// choose applies the strategy. Callers must hold the lock.
+func (selector *LeaseSelector) choose(dest common.Hash, usable []lease.Lease) lease.Lease {
+	switch selector.strategy {
+	case LEASE_STRATEGY_ROUND_ROBIN:
+		index := selector.next[dest] % len(usable)
+		selector.next[dest] = index + 1
+		return usable[index]
+	case LEASE_STRATEGY_LATENCY:
+		best := usable[0]
+		bestLatency, measured := selector.latency[keyOf(best)]
+		if !measured {
+			return best
+		}
+		for _, l := range usable[1:] {
+			latency, ok := selector.latency[keyOf(l)]
+			if !ok {
+				return l
+			}
+			if latency.rtt < bestLatency.rtt {
+				best, bestLatency = l, latency
+			}
+		}
+		return best
+	default:
+		return usable[selector.random.Intn(len(usable))]
+	}
+}
+
+// ObserveLatency records a round trip time measured through the lease, for
+// the latency strategy. What was learned about expired leases is dropped.
+func (selector *LeaseSelector) ObserveLatency(l lease.Lease, rtt time.Duration) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	now := time.Now()
+	for key, learned := range selector.latency {
+		if !learned.expires.After(now) {
+			delete(selector.latency, key)
+		}
+	}
+	key := keyOf(l)
+	if learned, ok := selector.latency[key]; ok {
+		rtt = time.Duration((1-LATENCY_SMOOTHING)*float64(learned.rtt) + LATENCY_SMOOTHING*float64(rtt))
+	}
+	selector.latency[key] = learnedLatency{rtt: rtt, expires: l.Date().Time()}
+}
+
+// Latency returns the round trip time learned for the lease, and false if
+// none was observed.
+func (selector *LeaseSelector) Latency(l lease.Lease) (time.Duration, bool) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	learned, ok := selector.latency[keyOf(l)]
+	return learned.rtt, ok
+}
+
+// Forget drops what the selector keeps for dest, for example when the
+// conversation with it ends.
+func (selector *LeaseSelector) Forget(dest common.Hash) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	delete(selector.sticky, dest)
+	delete(selector.next, dest)
+}
+
+// Stats returns the choices counted so far.
+func (selector *LeaseSelector) Stats() LeaseSelectionStats {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	return selector.stats
+}
@@ -0,0 +1,157 @@
+package tunnel
+
+import (
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/go-i2p/go-i2p/lib/common/lease"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func selectionLeases(t *testing.T, expires time.Time, ids ...uint32) []lease.Lease {
+	leases := make([]lease.Lease, 0, len(ids))
+	for _, id := range ids {
+		var gateway common.Hash
+		gateway[0] = byte(id)
+		l, err := lease.NewLease(gateway, id, expires)
+		require.NoError(t, err)
+		leases = append(leases, *l)
+	}
+	return leases
+}
+
+func TestNewLeaseSelectorRejectsUnknownStrategy(t *testing.T) {
+	_, err := NewLeaseSelector("fastest", 0)
+	assert.ErrorIs(t, err, ErrUnknownLeaseStrategy)
+}
+
+func TestLeaseSelectorRoundRobin(t *testing.T) {
+	now := time.Now()
+	leases := selectionLeases(t, now.Add(10*time.Minute), 1, 2, 3)
+	selector, err := NewLeaseSelector(LEASE_STRATEGY_ROUND_ROBIN, 0)
+	require.NoError(t, err)
+
+	var a, b common.Hash
+	b[0] = 1
+	var chosen []uint32
+	for i := 0; i < 4; i++ {
+		l, err := selector.Select(a, leases, now)
+		require.NoError(t, err)
+		chosen = append(chosen, l.TunnelID())
+	}
+	assert.Equal(t, []uint32{1, 2, 3, 1}, chosen)
+
+	l, err := selector.Select(b, leases, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), l.TunnelID(), "each destination has its own turn")
+
+	stats := selector.Stats()
+	assert.Equal(t, uint64(5), stats.Selections)
+	assert.Equal(t, uint64(3), stats.Switches)
+	assert.Zero(t, stats.StickyHits)
+}
+
+func TestLeaseSelectorStickiness(t *testing.T) {
+	now := time.Now()
+	leases := selectionLeases(t, now.Add(10*time.Minute), 1, 2, 3)
+	selector, err := NewLeaseSelector(LEASE_STRATEGY_ROUND_ROBIN, time.Minute)
+	require.NoError(t, err)
+	var dest common.Hash
+
+	first, err := selector.Select(dest, leases, now)
+	require.NoError(t, err)
+	again, err := selector.Select(dest, leases, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, first.TunnelID(), again.TunnelID())
+
+	// the sticky lease left the LeaseSet
+	again, err = selector.Select(dest, leases[1:], now.Add(40*time.Second))
+	require.NoError(t, err)
+	assert.NotEqual(t, first.TunnelID(), again.TunnelID())
+
+	// the stickiness period ran out
+	later, err := selector.Select(dest, leases[1:], now.Add(2*time.Minute))
+	require.NoError(t, err)
+	assert.NotEqual(t, again.TunnelID(), later.TunnelID())
+
+	stats := selector.Stats()
+	assert.Equal(t, uint64(4), stats.Selections)
+	assert.Equal(t, uint64(1), stats.StickyHits)
+	assert.Equal(t, uint64(2), stats.Switches)
+}
+
+func TestLeaseSelectorLatency(t *testing.T) {
+	now := time.Now()
+	leases := selectionLeases(t, now.Add(10*time.Minute), 1, 2, 3)
+	selector, err := NewLeaseSelector(LEASE_STRATEGY_LATENCY, 0)
+	require.NoError(t, err)
+	var dest common.Hash
+
+	// leases without measurements are tried first
+	selector.ObserveLatency(leases[0], 300*time.Millisecond)
+	l, err := selector.Select(dest, leases, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	selector.ObserveLatency(leases[1], 500*time.Millisecond)
+	selector.ObserveLatency(leases[2], 100*time.Millisecond)
+	l, err = selector.Select(dest, leases, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), l.TunnelID())
+
+	// a slow measurement moves the average, not the whole value
+	selector.ObserveLatency(leases[2], 900*time.Millisecond)
+	latency, ok := selector.Latency(leases[2])
+	require.True(t, ok)
+	assert.Equal(t, 300*time.Millisecond, latency)
+	l, err = selector.Select(dest, leases, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), l.TunnelID(), "ties keep the earlier lease")
+}
+
+func TestLeaseSelectorSkipsExpiredLeases(t *testing.T) {
+	now := time.Now()
+	expired := selectionLeases(t, now.Add(-5*time.Minute), 1)
+	live := selectionLeases(t, now.Add(time.Minute), 2)
+	selector, err := NewLeaseSelector(LEASE_STRATEGY_RANDOM, time.Minute)
+	require.NoError(t, err)
+	var dest common.Hash
+
+	for i := 0; i < 10; i++ {
+		l, err := selector.Select(dest, append(expired, live...), now)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(2), l.TunnelID())
+	}
+	_, err = selector.Select(dest, expired, now)
+	assert.ErrorIs(t, err, ErrNoUsableLease)
+}
+
+func TestLeaseSelectorGrace(t *testing.T) {
+	now := time.Now()
+	skewed := selectionLeases(t, now.Add(-30*time.Second), 1)
+	live := selectionLeases(t, now.Add(10*time.Minute), 2)
+	selector, err := NewLeaseSelector(LEASE_STRATEGY_ROUND_ROBIN, time.Minute)
+	require.NoError(t, err)
+	var dest common.Hash
+
+	// only a lease within the grace period is left
+	l, err := selector.Select(dest, skewed, now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), l.TunnelID())
+
+	// an unexpired lease is preferred, even over the sticky one
+	l, err = selector.Select(dest, append(skewed, live...), now)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), l.TunnelID())
+
+	// past the grace period
+	_, err = selector.Select(dest, skewed, now.Add(lease.DEFAULT_LEASE_GRACE))
+	assert.ErrorIs(t, err, ErrNoUsableLease)
+
+	// without grace
+	selector.SetGrace(0)
+	_, err = selector.Select(dest, skewed, now)
+	assert.ErrorIs(t, err, ErrNoUsableLease)
+}
@@ -0,0 +1,253 @@
+package tunnel
+
+import (
+	"sync"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/sirupsen/logrus"
+)
+
+// how long a tunnel lives once built
+const TUNNEL_LIFETIME = 10 * time.Minute
+
+// a warm tunnel must have this much of its lifetime left to be adopted, so
+// the session has time to build its own tunnels
+const WARM_MIN_REMAINING = 3 * time.Minute
+
+// how long the warm pool waits after a failed build before trying again
+const warmRetryDelay = 5 * time.Second
+
+// BuiltTunnel is an outbound client tunnel which has been built
+type BuiltTunnel struct {
+	// tunnel ID at the gateway
+	ID uint32
+	// router hashes of the hops, gateway first
+	Hops []common.Hash
+	// when the tunnel expires
+	Expiration time.Time
+}
+
+// Remaining returns how long the tunnel has left at now
+func (t *BuiltTunnel) Remaining(now time.Time) time.Duration {
+	return t.Expiration.Sub(now)
+}
+
+// BuildFunc builds an outbound client tunnel
+type BuildFunc func() (*BuiltTunnel, error)
+
+// WarmPool keeps a few outbound client tunnels built ahead of time, shared by
+// all clients. A new session adopts one so it can send at once while its own
+// pool is built, and the warm pool builds a replacement.
+type WarmPool struct {
+	mutex   sync.Mutex
+	size    int
+	build   BuildFunc
+	tunnels []*BuiltTunnel
+	// signalled when a tunnel was taken or dropped
+	refill chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	// for tests
+	now func() time.Time
+}
+
+// NewWarmPool returns a pool keeping size tunnels from build ready. It builds
+// nothing until Start is called.
+func NewWarmPool(size int, build BuildFunc) *WarmPool {
+	return &WarmPool{
+		size:   size,
+		build:  build,
+		refill: make(chan struct{}, 1),
+		now:    time.Now,
+	}
+}
+
+// Size returns the number of tunnels the pool keeps ready
+func (pool *WarmPool) Size() int {
+	return pool.size
+}
+
+// Start builds tunnels in the background until the pool is full, all missing
+// tunnels at once, and again whenever a tunnel is adopted or is too close to
+// expiring to be adopted.
+func (pool *WarmPool) Start() {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if pool.stop != nil || pool.size <= 0 {
+		return
+	}
+	log.WithField("size", pool.size).Debug("Starting warm tunnel pool")
+	pool.stop = make(chan struct{})
+	pool.done = make(chan struct{})
+	go pool.loop(pool.stop, pool.done)
+}
+
+// Stop stops building tunnels and drops the ones not adopted. It waits for
+// the builds in progress to finish.
+func (pool *WarmPool) Stop() {
+	pool.mutex.Lock()
+	stop, done := pool.stop, pool.done
+	pool.stop, pool.done = nil, nil
+	pool.tunnels = nil
+	pool.mutex.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+	log.Debug("Stopped warm tunnel pool")
+}
+
+// Adopt hands a warm tunnel over to a new session. It returns false if none
+// with at least WARM_MIN_REMAINING left is ready, the session then has to
+// wait for its own tunnels.
+func (pool *WarmPool) Adopt() (*BuiltTunnel, bool) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.expireLocked()
+	if len(pool.tunnels) == 0 {
+		return nil, false
+	}
+	// the newest tunnel lives the longest, builds finish in any order
+	newest := 0
+	for i, tunnel := range pool.tunnels {
+		if !tunnel.Expiration.Before(pool.tunnels[newest].Expiration) {
+			newest = i
+		}
+	}
+	tunnel := pool.tunnels[newest]
+	pool.tunnels = append(pool.tunnels[:newest], pool.tunnels[newest+1:]...)
+	pool.signalRefill()
+	log.WithFields(logrus.Fields{
+		"tunnel_id": tunnel.ID,
+		"remaining": tunnel.Remaining(pool.now()),
+	}).Debug("Adopted warm tunnel")
+	return tunnel, true
+}
+
+// Through returns the IDs of the tunnels ready to be adopted which have hash
+// as one of their hops. Adopted tunnels belong to their session and are not
+// included.
+func (pool *WarmPool) Through(hash common.Hash) []uint32 {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	ids := []uint32{}
+	for _, tunnel := range pool.tunnels {
+		for _, hop := range tunnel.Hops {
+			if hop == hash {
+				ids = append(ids, tunnel.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// Ready returns the number of tunnels which can be adopted
+func (pool *WarmPool) Ready() int {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.expireLocked()
+	return len(pool.tunnels)
+}
+
+// expireLocked drops tunnels with less than WARM_MIN_REMAINING left
+func (pool *WarmPool) expireLocked() {
+	now := pool.now()
+	kept := pool.tunnels[:0]
+	for _, tunnel := range pool.tunnels {
+		if tunnel.Remaining(now) >= WARM_MIN_REMAINING {
+			kept = append(kept, tunnel)
+		}
+	}
+	if len(kept) != len(pool.tunnels) {
+		pool.signalRefill()
+	}
+	pool.tunnels = kept
+}
+
+func (pool *WarmPool) signalRefill() {
+	select {
+	case pool.refill <- struct{}{}:
+	default:
+	}
+}
+
+// missing returns how many tunnels the pool is short of
+func (pool *WarmPool) missing() int {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	pool.expireLocked()
+	return pool.size - len(pool.tunnels)
+}
+
+func (pool *WarmPool) loop(stop, done chan struct{}) {
+	defer close(done)
+	// tunnels are checked for expiry at least this often
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if !pool.fill(stop) {
+			return
+		}
+		select {
+		case <-stop:
+			return
+		case <-pool.refill:
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildResult is the outcome of one warm tunnel build
+type buildResult struct {
+	tunnel *BuiltTunnel
+	err    error
+}
+
+// fill builds all missing tunnels at once, retrying after warmRetryDelay
+// while builds fail. It waits for every build it started and returns false
+// once the pool was stopped.
+func (pool *WarmPool) fill(stop chan struct{}) bool {
+	for {
+		missing := pool.missing()
+		if missing <= 0 {
+			return true
+		}
+		results := make(chan buildResult, missing)
+		for i := 0; i < missing; i++ {
+			go func() {
+				tunnel, err := pool.build()
+				results <- buildResult{tunnel: tunnel, err: err}
+			}()
+		}
+		failed, stopped := false, false
+		for i := 0; i < missing; i++ {
+			result := <-results
+			if result.err != nil {
+				log.WithError(result.err).Warn("Failed to build warm tunnel")
+				failed = true
+				continue
+			}
+			pool.mutex.Lock()
+			if pool.stop != stop {
+				stopped = true
+			} else {
+				pool.tunnels = append(pool.tunnels, result.tunnel)
+			}
+			pool.mutex.Unlock()
+			log.WithField("tunnel_id", result.tunnel.ID).Debug("Built warm tunnel")
+		}
+		if stopped {
+			return false
+		}
+		if failed {
+			select {
+			case <-stop:
+				return false
+			case <-time.After(warmRetryDelay):
+			}
+		}
+	}
+}
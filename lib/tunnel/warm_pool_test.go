@@ -0,0 +1,105 @@
+package tunnel
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	common "github.com/go-i2p/go-i2p/lib/common/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBuilder struct {
+	mutex sync.Mutex
+	next  uint32
+	fail  bool
+	now   func() time.Time
+}
+
+func (b *testBuilder) build() (*BuiltTunnel, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.fail {
+		return nil, errors.New("no peers")
+	}
+	b.next++
+	return &BuiltTunnel{ID: b.next, Expiration: b.now().Add(TUNNEL_LIFETIME)}, nil
+}
+
+func TestWarmPoolFillsAndRefills(t *testing.T) {
+	builder := &testBuilder{now: time.Now}
+	pool := NewWarmPool(2, builder.build)
+	assert.Equal(t, 0, pool.Ready())
+	pool.Start()
+	defer pool.Stop()
+
+	require.Eventually(t, func() bool { return pool.Ready() == 2 }, time.Second, time.Millisecond)
+	tunnel, ok := pool.Adopt()
+	require.True(t, ok)
+	assert.Equal(t, uint32(2), tunnel.ID, "the newest tunnel is adopted first")
+	require.Eventually(t, func() bool { return pool.Ready() == 2 }, time.Second, time.Millisecond)
+}
+
+func TestWarmPoolDropsTunnelsNearExpiry(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+	builder := &testBuilder{now: clock}
+	pool := NewWarmPool(1, builder.build)
+	pool.now = clock
+	pool.tunnels = []*BuiltTunnel{{ID: 7, Expiration: now.Add(WARM_MIN_REMAINING - time.Second)}}
+
+	_, ok := pool.Adopt()
+	assert.False(t, ok)
+	assert.Equal(t, 1, pool.missing())
+}
+
+func TestWarmPoolStop(t *testing.T) {
+	builder := &testBuilder{now: time.Now, fail: true}
+	pool := NewWarmPool(1, builder.build)
+	pool.Start()
+	pool.Stop()
+	pool.Stop()
+	_, ok := pool.Adopt()
+	assert.False(t, ok)
+
+	// a pool of size 0 never builds
+	empty := NewWarmPool(0, builder.build)
+	empty.Start()
+	assert.Nil(t, empty.stop)
+}
+
+func TestWarmPoolThrough(t *testing.T) {
+	now := time.Now()
+	pool := NewWarmPool(2, (&testBuilder{now: time.Now}).build)
+	var hop, other common.Hash
+	hop[0], other[0] = 1, 2
+	pool.tunnels = []*BuiltTunnel{
+		{ID: 7, Hops: []common.Hash{other, hop}, Expiration: now.Add(TUNNEL_LIFETIME)},
+		{ID: 8, Hops: []common.Hash{other}, Expiration: now.Add(TUNNEL_LIFETIME)},
+	}
+
+	assert.Equal(t, []uint32{7}, pool.Through(hop))
+	assert.Equal(t, []uint32{7, 8}, pool.Through(other))
+	_, ok := pool.Adopt()
+	require.True(t, ok)
+	assert.Equal(t, []uint32{7}, pool.Through(other), "adopted tunnels are not the pool's")
+}
+
+func TestWarmPoolBuildsConcurrently(t *testing.T) {
+	const size = 3
+	var started sync.WaitGroup
+	started.Add(size)
+	builder := &testBuilder{now: time.Now}
+	pool := NewWarmPool(size, func() (*BuiltTunnel, error) {
+		// every build waits for the others to start
+		started.Done()
+		started.Wait()
+		return builder.build()
+	})
+	pool.Start()
+	defer pool.Stop()
+
+	require.Eventually(t, func() bool { return pool.Ready() == size }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,87 @@
+package update
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"embed"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+//go:embed certs
+var embeddedCerts embed.FS
+
+var ErrNoReleaseKeys = errors.New("no release signing keys, add the signer certificates to lib/update/certs")
+
+// EmbeddedKeys returns the release signing keys compiled into the router,
+// indexed by signer ID. It returns ErrNoReleaseKeys if the router was built
+// without any, as no update could ever be verified.
+func EmbeddedKeys() (map[string]*rsa.PublicKey, error) {
+	certs, err := fs.Sub(embeddedCerts, "certs")
+	if err != nil {
+		return nil, err
+	}
+	return releaseKeys(certs)
+}
+
+func releaseKeys(fsys fs.FS) (map[string]*rsa.PublicKey, error) {
+	keys, err := LoadKeys(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		log.Error("No release signing keys compiled into the router")
+		return nil, ErrNoReleaseKeys
+	}
+	return keys, nil
+}
+
+// LoadKeys reads every .crt file in the root of fsys as a PEM encoded
+// certificate. The signer ID is the file name without the extension and
+// with "_at_" replaced by "@".
+func LoadKeys(fsys fs.FS) (map[string]*rsa.PublicKey, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".crt" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseCertificateKey(data)
+		if err != nil {
+			log.WithError(err).WithField("file", name).Error("Failed to load release signing key")
+			return nil, fmt.Errorf("loading %s: %w", name, err)
+		}
+		signer := strings.ReplaceAll(strings.TrimSuffix(name, ".crt"), "_at_", "@")
+		keys[signer] = key
+	}
+	log.WithField("count", len(keys)).Debug("Loaded release signing keys")
+	return keys, nil
+}
+
+func parseCertificateKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate does not hold an RSA key")
+	}
+	return key, nil
+}
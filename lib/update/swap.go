@@ -0,0 +1,133 @@
+package update
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+// ConfirmDelay is how long an updated router has to keep running before the
+// update is considered good and the previous binary is removed.
+const ConfirmDelay = time.Minute
+
+var ErrRolledBack = errors.New("updated router failed to start, rolled back to the previous version")
+
+// pending marker contents
+const (
+	pendingSwapped   = "swapped"
+	pendingAttempted = "attempted"
+)
+
+// StagedPath is where Stage writes the update for executable.
+func StagedPath(executable string) string {
+	return executable + ".new"
+}
+
+// BackupPath is where the previous binary is kept until the update is confirmed.
+func BackupPath(executable string) string {
+	return executable + ".old"
+}
+
+// PendingPath is the marker file for an update which has not been confirmed yet.
+func PendingPath(executable string) string {
+	return executable + ".pending"
+}
+
+// ApplyStaged moves a staged update into place, keeping the running binary as
+// a backup. It is meant to be called as the router shuts down, and reports
+// whether an update was applied.
+func ApplyStaged(executable string) (bool, error) {
+	staged := StagedPath(executable)
+	if _, err := os.Stat(staged); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	log.WithField("path", executable).Info("Applying staged router update")
+	backup := BackupPath(executable)
+	if err := os.Remove(backup); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+	if err := os.Rename(executable, backup); err != nil {
+		log.WithError(err).Error("Failed to back up current router binary")
+		return false, err
+	}
+	if err := os.Rename(staged, executable); err != nil {
+		log.WithError(err).Error("Failed to move staged update into place")
+		if restoreErr := os.Rename(backup, executable); restoreErr != nil {
+			log.WithError(restoreErr).Error("Failed to restore router binary")
+		}
+		return false, err
+	}
+	if err := os.WriteFile(PendingPath(executable), []byte(pendingSwapped), 0o600); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// CheckPending is called at startup. The first start after an update is
+// recorded, and if the previous start of the updated binary neither called
+// ConfirmStart nor shut down cleanly with ResetPending the backup is restored and ErrRolledBack returned, in which
+// case the caller should exit so the previous version is started instead.
+func CheckPending(executable string) error {
+	pending := PendingPath(executable)
+	data, err := os.ReadFile(pending)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if string(data) == pendingSwapped {
+		log.WithField("version", Version).Info("Starting updated router")
+		return os.WriteFile(pending, []byte(pendingAttempted), 0o600)
+	}
+	log.Warn("Updated router did not start successfully, rolling back")
+	if err := Rollback(executable); err != nil {
+		return err
+	}
+	return ErrRolledBack
+}
+
+// ConfirmStart marks the running update as good and removes the backup.
+func ConfirmStart(executable string) error {
+	pending := PendingPath(executable)
+	if _, err := os.Stat(pending); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	log.WithField("version", Version).Info("Router update confirmed")
+	if err := os.Remove(BackupPath(executable)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return os.Remove(pending)
+}
+
+// ResetPending is called when the router shuts down cleanly before
+// ConfirmStart. The updated binary did not fail, so its next start is
+// treated as its first again instead of rolling back.
+func ResetPending(executable string) error {
+	pending := PendingPath(executable)
+	data, err := os.ReadFile(pending)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if string(data) != pendingAttempted {
+		return nil
+	}
+	log.Debug("Router stopped before confirming the update, confirming on the next start")
+	return os.WriteFile(pending, []byte(pendingSwapped), 0o600)
+}
+
+// Rollback restores the binary which was replaced by the last update.
+func Rollback(executable string) error {
+	if err := os.Rename(BackupPath(executable), executable); err != nil {
+		log.WithError(err).Error("Failed to restore previous router binary")
+		return err
+	}
+	if err := os.Remove(PendingPath(executable)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	log.WithField("path", executable).Info("Rolled back router update")
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package update checks for signed go-i2p releases and stages them to replace
+// the running binary on the next restart.
+//
+// Releases are SU3 files with the router update content type. The content is
+// a zip archive holding one executable per platform, named
+// go-i2p-<GOOS>-<GOARCH> (with a .exe suffix on Windows). Files are only
+// trusted when they are signed by one of the keys compiled into the router.
+//
+// The binary swap happens in three steps. Stage writes the new executable
+// next to the running one. ApplyStaged, run when the router shuts down,
+// moves it into place and keeps the old binary as a backup. On the next
+// start CheckPending notices the swap, and ConfirmStart removes the backup
+// once the router has been running for a while. If the new binary neither
+// gets that far nor shuts down cleanly, the following start rolls back to
+// the backup.
+package update
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/su3"
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Version of this build. Release builds set it at link time with
+// -ldflags "-X github.com/go-i2p/go-i2p/lib/update.Version=<version>".
+var Version = "0.0.1"
+
+var (
+	ErrNotRouterUpdate = errors.New("su3 file is not a router update")
+	ErrUnknownSigner   = errors.New("update signed by unknown signer")
+	ErrNoPlatformBuild = errors.New("update has no executable for this platform")
+)
+
+// Update is a verified release which is newer than the running version.
+type Update struct {
+	Version    string
+	SignerID   string
+	Executable []byte
+}
+
+// Updater fetches and verifies release SU3 files.
+type Updater struct {
+	// URL of the release SU3 file
+	URL string
+	// Client used to fetch URL. To fetch over I2P it should be configured
+	// to use the router's HTTP proxy.
+	Client *http.Client
+	// Keys trusted to sign releases, indexed by signer ID
+	Keys map[string]*rsa.PublicKey
+	// CurrentVersion is the running version, Version if empty
+	CurrentVersion string
+}
+
+// Check fetches the release file and returns the update it holds, or nil if
+// it is not newer than the running version.
+func (u *Updater) Check(ctx context.Context) (*Update, error) {
+	if len(u.Keys) == 0 {
+		return nil, ErrNoReleaseKeys
+	}
+	log.WithField("url", u.URL).Debug("Checking for router update")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		log.WithError(err).Error("Failed to fetch router update")
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		log.WithField("status", response.Status).Error("Unexpected response fetching router update")
+		return nil, fmt.Errorf("fetching update: %s", response.Status)
+	}
+	return u.Read(response.Body)
+}
+
+// Read verifies a release SU3 file and extracts the executable for this
+// platform. It returns nil if the release is not newer than the running version.
+func (u *Updater) Read(reader io.Reader) (*Update, error) {
+	file, err := su3.Read(reader)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"version":   file.Version,
+		"signer_id": file.SignerID,
+	}).Debug("Read router update header")
+	if file.ContentType != su3.ROUTER_UPDATE || file.FileType != su3.ZIP {
+		return nil, ErrNotRouterUpdate
+	}
+	current := u.CurrentVersion
+	if current == "" {
+		current = Version
+	}
+	if !NewerVersion(file.Version, current) {
+		log.WithFields(logrus.Fields{
+			"available": file.Version,
+			"current":   current,
+		}).Debug("No newer router version available")
+		return nil, nil
+	}
+	key, ok := u.Keys[file.SignerID]
+	if !ok {
+		log.WithField("signer_id", file.SignerID).Error("Router update signed by unknown signer")
+		return nil, ErrUnknownSigner
+	}
+	// the signature is checked once the content has been read to the end
+	content, err := io.ReadAll(file.Content(key))
+	if err != nil {
+		log.WithError(err).Error("Failed to verify router update")
+		return nil, err
+	}
+	executable, err := platformExecutable(content)
+	if err != nil {
+		return nil, err
+	}
+	log.WithField("version", file.Version).Info("Router update available")
+	return &Update{
+		Version:    file.Version,
+		SignerID:   file.SignerID,
+		Executable: executable,
+	}, nil
+}
+
+// PlatformExecutableName is the name of the executable for this platform in
+// a release archive.
+func PlatformExecutableName() string {
+	name := "go-i2p-" + runtime.GOOS + "-" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+func platformExecutable(content []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("opening update archive: %w", err)
+	}
+	f, err := archive.Open(PlatformExecutableName())
+	if err != nil {
+		return nil, ErrNoPlatformBuild
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// NewerVersion reports whether version a is newer than version b. Versions
+// are compared as dot separated numbers, missing components count as 0 and
+// non-numeric components compare lexically.
+func NewerVersion(a, b string) bool {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y string
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xerr := strconv.Atoi(orZero(x))
+		yn, yerr := strconv.Atoi(orZero(y))
+		if xerr == nil && yerr == nil {
+			if xn != yn {
+				return xn > yn
+			}
+			continue
+		}
+		if x != y {
+			return x > y
+		}
+	}
+	return false
+}
+
+func orZero(s string) string {
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+// Stage writes the update next to executable so that ApplyStaged can swap
+// it in when the router shuts down.
+func Stage(executable string, update *Update) error {
+	path := StagedPath(executable)
+	log.WithFields(logrus.Fields{
+		"path":    path,
+		"version": update.Version,
+	}).Info("Staging router update")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, update.Executable, 0o755); err != nil {
+		log.WithError(err).Error("Failed to write staged update")
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
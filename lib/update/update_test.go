@@ -0,0 +1,226 @@
+package update
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/su3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSigner = "release@mail.i2p"
+
+// signedUpdate builds a router update su3 file signed with key
+func signedUpdate(t *testing.T, key *rsa.PrivateKey, version string, files map[string][]byte) []byte {
+	var content bytes.Buffer
+	zw := zip.NewWriter(&content)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	versionBytes := make([]byte, 16)
+	copy(versionBytes, version)
+	var header bytes.Buffer
+	header.WriteString("I2Psu3")
+	header.Write([]byte{0x00, 0x00})
+	header.Write([]byte{0x00, 0x04}) // RSA_SHA256_2048
+	binary.Write(&header, binary.BigEndian, uint16(256))
+	header.Write([]byte{0x00, byte(len(versionBytes)), 0x00, byte(len(testSigner))})
+	binary.Write(&header, binary.BigEndian, uint64(content.Len()))
+	header.Write([]byte{0x00, 0x00, 0x00, 0x01}) // zip, router update
+	header.Write(make([]byte, 12))
+	header.Write(versionBytes)
+	header.WriteString(testSigner)
+	header.Write(content.Bytes())
+
+	sum := sha256.Sum256(header.Bytes())
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, 0, sum[:])
+	require.NoError(t, err)
+	header.Write(sig)
+	return header.Bytes()
+}
+
+func newTestUpdater(t *testing.T) (*Updater, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &Updater{
+		Keys:           map[string]*rsa.PublicKey{testSigner: &key.PublicKey},
+		CurrentVersion: "0.0.1",
+	}, key
+}
+
+func TestUpdaterRead(t *testing.T) {
+	updater, key := newTestUpdater(t)
+	file := signedUpdate(t, key, "0.0.2", map[string][]byte{PlatformExecutableName(): []byte("new binary")})
+
+	available, err := updater.Read(bytes.NewReader(file))
+	require.NoError(t, err)
+	require.NotNil(t, available)
+	assert.Equal(t, "0.0.2", available.Version)
+	assert.Equal(t, testSigner, available.SignerID)
+	assert.Equal(t, []byte("new binary"), available.Executable)
+}
+
+func TestUpdaterReadNotNewer(t *testing.T) {
+	updater, key := newTestUpdater(t)
+	file := signedUpdate(t, key, "0.0.1", map[string][]byte{PlatformExecutableName(): []byte("same binary")})
+
+	available, err := updater.Read(bytes.NewReader(file))
+	require.NoError(t, err)
+	assert.Nil(t, available)
+}
+
+func TestUpdaterReadRejects(t *testing.T) {
+	updater, key := newTestUpdater(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, err = updater.Read(bytes.NewReader(signedUpdate(t, otherKey, "0.0.2", map[string][]byte{PlatformExecutableName(): {1}})))
+	assert.ErrorIs(t, err, su3.ErrInvalidSignature)
+
+	_, err = updater.Read(bytes.NewReader(signedUpdate(t, key, "0.0.2", map[string][]byte{"go-i2p-plan9-mips": {1}})))
+	assert.ErrorIs(t, err, ErrNoPlatformBuild)
+
+	updater.Keys = nil
+	_, err = updater.Read(bytes.NewReader(signedUpdate(t, key, "0.0.2", map[string][]byte{PlatformExecutableName(): {1}})))
+	assert.ErrorIs(t, err, ErrUnknownSigner)
+}
+
+func TestUpdaterCheck(t *testing.T) {
+	updater, key := newTestUpdater(t)
+	file := signedUpdate(t, key, "0.1.0", map[string][]byte{PlatformExecutableName(): []byte("new binary")})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(file)
+	}))
+	defer server.Close()
+	updater.URL = server.URL
+
+	available, err := updater.Check(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, available)
+	assert.Equal(t, "0.1.0", available.Version)
+
+	updater.Keys = nil
+	_, err = updater.Check(context.Background())
+	assert.ErrorIs(t, err, ErrNoReleaseKeys)
+}
+
+func TestNewerVersion(t *testing.T) {
+	assert.True(t, NewerVersion("0.0.2", "0.0.1"))
+	assert.True(t, NewerVersion("0.10.0", "0.9.64"))
+	assert.True(t, NewerVersion("1.0", "0.99.99"))
+	assert.True(t, NewerVersion("0.0.1.1", "0.0.1"))
+	assert.True(t, NewerVersion("v0.1.0", "0.0.9"))
+	assert.False(t, NewerVersion("0.0.1", "0.0.1"))
+	assert.False(t, NewerVersion("0.0.1", "0.0.1.0"))
+	assert.False(t, NewerVersion("0.9.9", "0.10.0"))
+}
+
+func TestStageApplyConfirm(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "go-i2p")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+
+	applied, err := ApplyStaged(executable)
+	require.NoError(t, err)
+	assert.False(t, applied)
+
+	require.NoError(t, Stage(executable, &Update{Version: "0.0.2", Executable: []byte("new")}))
+	applied, err = ApplyStaged(executable)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assertFile(t, executable, "new")
+	assertFile(t, BackupPath(executable), "old")
+
+	require.NoError(t, CheckPending(executable))
+	require.NoError(t, ConfirmStart(executable))
+	assert.NoFileExists(t, BackupPath(executable))
+	assert.NoFileExists(t, PendingPath(executable))
+	require.NoError(t, CheckPending(executable))
+	assertFile(t, executable, "new")
+}
+
+func TestRollbackAfterFailedStart(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "go-i2p")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+	require.NoError(t, Stage(executable, &Update{Version: "0.0.2", Executable: []byte("new")}))
+	_, err := ApplyStaged(executable)
+	require.NoError(t, err)
+
+	// the first start never confirms
+	require.NoError(t, CheckPending(executable))
+	assert.ErrorIs(t, CheckPending(executable), ErrRolledBack)
+	assertFile(t, executable, "old")
+	assert.NoFileExists(t, PendingPath(executable))
+	require.NoError(t, CheckPending(executable))
+}
+
+func TestCleanShutdownKeepsUpdate(t *testing.T) {
+	executable := filepath.Join(t.TempDir(), "go-i2p")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0o755))
+	require.NoError(t, Stage(executable, &Update{Version: "0.0.2", Executable: []byte("new")}))
+	_, err := ApplyStaged(executable)
+	require.NoError(t, err)
+
+	// the first start shuts down cleanly before confirming
+	require.NoError(t, CheckPending(executable))
+	require.NoError(t, ResetPending(executable))
+	require.NoError(t, CheckPending(executable))
+	assertFile(t, executable, "new")
+	require.NoError(t, ConfirmStart(executable))
+	assert.NoFileExists(t, BackupPath(executable))
+
+	require.NoError(t, ResetPending(executable))
+	assert.NoFileExists(t, PendingPath(executable))
+}
+
+func TestLoadKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: testSigner},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keys, err := LoadKeys(fstest.MapFS{
+		"release_at_mail.i2p.crt": {Data: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})},
+		"README.md":               {Data: []byte("not a key")},
+	})
+	require.NoError(t, err)
+	require.Contains(t, keys, testSigner)
+	assert.True(t, key.PublicKey.Equal(keys[testSigner]))
+
+	_, err = releaseKeys(fstest.MapFS{"README.md": {Data: []byte("not a key")}})
+	assert.ErrorIs(t, err, ErrNoReleaseKeys)
+}
+
+func assertFile(t *testing.T, path, content string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
@@ -0,0 +1,216 @@
+// Package bloom implements the decaying bloom filter the router uses to
+// detect replayed messages.
+package bloom
+
+import (
+	"hash/maphash"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// filter is a plain bloom filter of m bits probed k times
+type filter struct {
+	bits  []uint64
+	m     uint64
+	k     int
+	count uint64
+}
+
+func newFilter(m uint64, k int) *filter {
+	return &filter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// test reports whether the hashed key may be present, and adds it if add is set
+func (f *filter) test(h1, h2 uint64, add bool) bool {
+	present := true
+	for i := 0; i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		word, mask := bit/64, uint64(1)<<(bit%64)
+		if f.bits[word]&mask == 0 {
+			present = false
+			if !add {
+				return false
+			}
+			f.bits[word] |= mask
+		}
+	}
+	if add && !present {
+		f.count++
+	}
+	return present
+}
+
+// DecayingFilter remembers keys for between one and two periods. It keeps a
+// current and a previous bloom filter, keys go into the current one and are
+// looked up in both, and every period the previous filter is dropped.
+//
+// Like any bloom filter it can report a key it has not seen. The filter is
+// sized so that happens at the requested rate when no more than the expected
+// number of keys are added per period.
+type DecayingFilter struct {
+	mutex    sync.Mutex
+	period   time.Duration
+	m        uint64
+	k        int
+	seed     maphash.Seed
+	current  *filter
+	previous *filter
+	rotated  time.Time
+	// now is replaced in tests
+	now func() time.Time
+
+	added      uint64
+	duplicates uint64
+	rotations  uint64
+}
+
+// FilterStats are counters of a DecayingFilter since it was created.
+type FilterStats struct {
+	// Added is the number of new keys
+	Added uint64
+	// Duplicates is the number of keys reported as already seen, including
+	// false positives
+	Duplicates uint64
+	Rotations  uint64
+	// FillRatio is the fraction of bits set in the current filter
+	FillRatio float64
+	// FalsePositiveRate is the estimated chance of reporting an unseen key
+	// with the filters as they are now
+	FalsePositiveRate float64
+}
+
+// NewDecayingFilter returns a filter remembering keys for at least period,
+// sized for expected keys per period at falsePositiveRate.
+func NewDecayingFilter(period time.Duration, expected int, falsePositiveRate float64) *DecayingFilter {
+	if expected < 1 {
+		expected = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.0001
+	}
+	// optimal size and number of probes for a standard bloom filter
+	m := uint64(math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	k := int(math.Round(float64(m) / float64(expected) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	f := &DecayingFilter{
+		period: period,
+		m:      m,
+		k:      k,
+		seed:   maphash.MakeSeed(),
+		now:    time.Now,
+	}
+	f.current = newFilter(m, k)
+	f.previous = newFilter(m, k)
+	f.rotated = time.Now()
+	log.WithFields(logrus.Fields{
+		"period":              period,
+		"expected":            expected,
+		"false_positive_rate": falsePositiveRate,
+		"bits":                m,
+		"probes":              k,
+	}).Debug("Created decaying bloom filter")
+	return f
+}
+
+// Add adds key to the filter and reports whether it was already present.
+func (f *DecayingFilter) Add(key []byte) bool {
+	h1, h2 := f.hash(key)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.decay()
+	if f.previous.test(h1, h2, false) || f.current.test(h1, h2, true) {
+		f.duplicates++
+		return true
+	}
+	f.added++
+	return false
+}
+
+// Contains reports whether key may have been added within the last period.
+func (f *DecayingFilter) Contains(key []byte) bool {
+	h1, h2 := f.hash(key)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.decay()
+	return f.current.test(h1, h2, false) || f.previous.test(h1, h2, false)
+}
+
+// Rotate drops the previous filter and starts a new current one.
+func (f *DecayingFilter) Rotate() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.rotate()
+}
+
+// Stats returns the filter counters.
+func (f *DecayingFilter) Stats() FilterStats {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.decay()
+	fill := f.current.fillRatio()
+	previousFill := f.previous.fillRatio()
+	return FilterStats{
+		Added:      f.added,
+		Duplicates: f.duplicates,
+		Rotations:  f.rotations,
+		FillRatio:  fill,
+		// a key is reported if all probes hit in either filter
+		FalsePositiveRate: 1 - (1-math.Pow(fill, float64(f.k)))*(1-math.Pow(previousFill, float64(f.k))),
+	}
+}
+
+// decay rotates once per elapsed period. Callers must hold the mutex.
+func (f *DecayingFilter) decay() {
+	if f.period <= 0 {
+		return
+	}
+	elapsed := f.now().Sub(f.rotated)
+	if elapsed < f.period {
+		return
+	}
+	f.rotate()
+	if elapsed >= 2*f.period {
+		// nothing added before the last period is worth keeping either
+		f.rotate()
+	}
+}
+
+func (f *DecayingFilter) rotate() {
+	f.previous = f.current
+	f.current = newFilter(f.m, f.k)
+	f.rotated = f.now()
+	f.rotations++
+	log.WithField("previous_entries", f.previous.count).Debug("Rotated decaying bloom filter")
+}
+
+func (f *DecayingFilter) hash(key []byte) (uint64, uint64) {
+	var h maphash.Hash
+	h.SetSeed(f.seed)
+	h.Write(key)
+	h1 := h.Sum64()
+	h.WriteByte(0xff)
+	// an odd second hash visits distinct bits when m is a power of two
+	h2 := h.Sum64() | 1
+	return h1, h2
+}
+
+func (f *filter) fillRatio() float64 {
+	var set int
+	for _, word := range f.bits {
+		set += bits.OnesCount64(word)
+	}
+	return float64(set) / float64(f.m)
+}
@@ -0,0 +1,64 @@
+package bloom
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func key(i uint32) []byte {
+	var k [4]byte
+	binary.BigEndian.PutUint32(k[:], i)
+	return k[:]
+}
+
+func TestDecayingFilterAddReportsDuplicates(t *testing.T) {
+	f := NewDecayingFilter(time.Minute, 1000, 0.001)
+	assert.False(t, f.Add(key(1)))
+	assert.True(t, f.Add(key(1)))
+	assert.True(t, f.Contains(key(1)))
+	assert.False(t, f.Contains(key(2)))
+
+	stats := f.Stats()
+	assert.Equal(t, uint64(1), stats.Added)
+	assert.Equal(t, uint64(1), stats.Duplicates)
+	assert.Greater(t, stats.FillRatio, 0.0)
+}
+
+func TestDecayingFilterDecays(t *testing.T) {
+	now := time.Now()
+	f := NewDecayingFilter(time.Minute, 1000, 0.001)
+	f.now = func() time.Time { return now }
+	f.rotated = now
+	f.Add(key(1))
+
+	// still remembered for one more period after a rotation
+	now = now.Add(time.Minute)
+	assert.True(t, f.Contains(key(1)))
+	now = now.Add(time.Minute)
+	assert.False(t, f.Contains(key(1)))
+
+	f.Add(key(2))
+	now = now.Add(5 * time.Minute)
+	assert.False(t, f.Contains(key(2)))
+	assert.Equal(t, uint64(4), f.Stats().Rotations)
+}
+
+func TestDecayingFilterFalsePositiveRate(t *testing.T) {
+	const expected = 10000
+	f := NewDecayingFilter(time.Hour, expected, 0.01)
+	for i := uint32(0); i < expected; i++ {
+		f.Add(key(i))
+	}
+	falsePositives := 0
+	for i := uint32(expected); i < 2*expected; i++ {
+		if f.Contains(key(i)) {
+			falsePositives++
+		}
+	}
+	// allow for chance, the target is 1%
+	assert.Less(t, falsePositives, expected*3/100)
+	assert.InDelta(t, 0.01, f.Stats().FalsePositiveRate, 0.01)
+}
@@ -0,0 +1,166 @@
+// Package chaos injects faults at the transport boundary: dropped and
+// corrupted frames and delayed handshakes. Faults are off unless
+// a test or the debug Handler sets them, and cost a single atomic load per
+// frame while off.
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Point names a place faults can be injected.
+type Point string
+
+const (
+	// TRANSPORT_SEND is every encrypted frame written to a transport connection
+	TRANSPORT_SEND Point = "transport.send"
+	// TRANSPORT_RECV is every encrypted frame read from a transport connection;
+	// a dropped frame is still decrypted to keep the nonces in step, then
+	// discarded
+	TRANSPORT_RECV Point = "transport.recv"
+	// HANDSHAKE is the start of every outgoing transport handshake
+	HANDSHAKE Point = "transport.handshake"
+)
+
+// Faults are injected at a Point.
+type Faults struct {
+	// Drop is the probability from 0 to 1 a frame is dropped
+	Drop float64
+	// Corrupt is the probability from 0 to 1 a bit of a frame is flipped
+	Corrupt float64
+	// Delay holds every frame or handshake this long
+	Delay time.Duration
+}
+
+// Counters count the faults injected at a Point.
+type Counters struct {
+	Dropped   uint64 `json:"dropped"`
+	Corrupted uint64 `json:"corrupted"`
+	Delayed   uint64 `json:"delayed"`
+}
+
+var (
+	enabled  atomic.Bool
+	mutex    sync.Mutex
+	faults   = make(map[Point]Faults)
+	counters = make(map[Point]*Counters)
+	random   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// Set injects faults at point, replacing those set before.
+func Set(point Point, f Faults) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	faults[point] = f
+	if counters[point] == nil {
+		counters[point] = &Counters{}
+	}
+	enabled.Store(true)
+	log.WithFields(logrus.Fields{
+		"point":   point,
+		"drop":    f.Drop,
+		"corrupt": f.Corrupt,
+		"delay":   f.Delay,
+	}).Warn("Chaos faults enabled")
+}
+
+// Clear stops injecting faults at point.
+func Clear(point Point) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	delete(faults, point)
+	enabled.Store(len(faults) > 0)
+}
+
+// Reset stops injecting faults everywhere and zeroes the counters.
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	faults = make(map[Point]Faults)
+	counters = make(map[Point]*Counters)
+	enabled.Store(false)
+}
+
+// Seed makes the faults injected from now on reproducible.
+func Seed(seed int64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	random = rand.New(rand.NewSource(seed))
+}
+
+// Enabled reports whether faults are injected anywhere.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Current returns the faults injected at each point.
+func Current() map[Point]Faults {
+	mutex.Lock()
+	defer mutex.Unlock()
+	current := make(map[Point]Faults, len(faults))
+	for point, f := range faults {
+		current[point] = f
+	}
+	return current
+}
+
+// Stats returns the faults injected at each point so far.
+func Stats() map[Point]Counters {
+	mutex.Lock()
+	defer mutex.Unlock()
+	stats := make(map[Point]Counters, len(counters))
+	for point, c := range counters {
+		stats[point] = *c
+	}
+	return stats
+}
+
+// Frame passes frame through the faults at point. It returns drop true if
+// the frame must be dropped, otherwise the frame to use, a corrupted copy if
+// a bit was flipped. frame itself is never modified. The delay, if any, is
+// slept before returning.
+func Frame(point Point, frame []byte) (out []byte, drop bool) {
+	if !enabled.Load() {
+		return frame, false
+	}
+	mutex.Lock()
+	f, ok := faults[point]
+	if !ok {
+		mutex.Unlock()
+		return frame, false
+	}
+	c := counters[point]
+	out = frame
+	switch {
+	case f.Drop > 0 && random.Float64() < f.Drop:
+		c.Dropped++
+		drop = true
+	case f.Corrupt > 0 && len(frame) > 0 && random.Float64() < f.Corrupt:
+		c.Corrupted++
+		out = append([]byte(nil), frame...)
+		bit := random.Intn(len(out) * 8)
+		out[bit/8] ^= 1 << (bit % 8)
+	}
+	if f.Delay > 0 {
+		c.Delayed++
+	}
+	mutex.Unlock()
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+	return out, drop
+}
+
+// Wait sleeps for the delay set at point, for handshakes and other steps
+// without a frame.
+func Wait(point Point) {
+	Frame(point, nil)
+}
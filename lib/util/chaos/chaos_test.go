@@ -0,0 +1,90 @@
+package chaos
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/bits"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameDisabled(t *testing.T) {
+	Reset()
+	frame := []byte{1, 2, 3}
+	out, drop := Frame(TRANSPORT_SEND, frame)
+	assert.False(t, drop)
+	assert.Equal(t, frame, out)
+	assert.False(t, Enabled())
+}
+
+func TestFrameDrop(t *testing.T) {
+	Reset()
+	defer Reset()
+	Set(TRANSPORT_SEND, Faults{Drop: 1})
+	_, drop := Frame(TRANSPORT_SEND, []byte{1})
+	assert.True(t, drop)
+	// other points are untouched
+	_, drop = Frame(TRANSPORT_RECV, []byte{1})
+	assert.False(t, drop)
+	assert.Equal(t, uint64(1), Stats()[TRANSPORT_SEND].Dropped)
+}
+
+func TestFrameCorruptFlipsOneBitOfACopy(t *testing.T) {
+	Reset()
+	defer Reset()
+	Seed(1)
+	Set(TRANSPORT_RECV, Faults{Corrupt: 1})
+	frame := bytes.Repeat([]byte{0xaa}, 32)
+	original := append([]byte(nil), frame...)
+	out, drop := Frame(TRANSPORT_RECV, frame)
+	require.False(t, drop)
+	assert.Equal(t, original, frame, "input modified")
+	flipped := 0
+	for i := range out {
+		flipped += bits.OnesCount8(out[i] ^ frame[i])
+	}
+	assert.Equal(t, 1, flipped)
+}
+
+func TestWaitDelays(t *testing.T) {
+	Reset()
+	defer Reset()
+	Set(HANDSHAKE, Faults{Delay: 20 * time.Millisecond})
+	start := time.Now()
+	Wait(HANDSHAKE)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	Clear(HANDSHAKE)
+	assert.False(t, Enabled())
+}
+
+func TestHandler(t *testing.T) {
+	Reset()
+	defer Reset()
+	handler := Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"transport.send": {"drop": 0.5, "delay": "10ms"}}`)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, Faults{Drop: 0.5, Delay: 10 * time.Millisecond}, Current()[TRANSPORT_SEND])
+
+	var state struct {
+		Faults map[Point]faultsJSON `json:"faults"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.Equal(t, "10ms", state.Faults[TRANSPORT_SEND].Delay)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"transport.send": {"delay": "soon"}}`)))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, Enabled())
+}
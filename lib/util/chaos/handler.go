@@ -0,0 +1,66 @@
+package chaos
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// faultsJSON is Faults with the delay as a Go duration string
+type faultsJSON struct {
+	Drop    float64 `json:"drop"`
+	Corrupt float64 `json:"corrupt"`
+	Delay   string  `json:"delay,omitempty"`
+}
+
+// Handler is the debug API of the fault injection. The router management API
+// mounts it at /debug/chaos only when management.debug_chaos is set and
+// clients have to log in.
+//
+//	GET     the faults at each point and the counters
+//	POST    {"transport.send": {"drop": 0.1, "delay": "50ms"}} sets faults
+//	DELETE  removes all faults
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var update map[Point]faultsJSON
+			if err := json.NewDecoder(req.Body).Decode(&update); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			parsed := make(map[Point]Faults, len(update))
+			for point, f := range update {
+				var delay time.Duration
+				if f.Delay != "" {
+					var err error
+					if delay, err = time.ParseDuration(f.Delay); err != nil {
+						http.Error(w, err.Error(), http.StatusBadRequest)
+						return
+					}
+				}
+				parsed[point] = Faults{Drop: f.Drop, Corrupt: f.Corrupt, Delay: delay}
+			}
+			for point, f := range parsed {
+				Set(point, f)
+			}
+		case http.MethodDelete:
+			Reset()
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		current := make(map[Point]faultsJSON)
+		for point, f := range Current() {
+			current[point] = faultsJSON{Drop: f.Drop, Corrupt: f.Corrupt, Delay: f.Delay.String()}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(struct {
+			Faults map[Point]faultsJSON `json:"faults"`
+			Stats  map[Point]Counters   `json:"stats"`
+		}{current, Stats()}); err != nil {
+			log.WithError(err).Error("Failed to write chaos state")
+		}
+	})
+}
@@ -0,0 +1,135 @@
+// Package features is the registry of feature flags, which stage the rollout
+// of new protocol features: a feature lands disabled, subsystems check its
+// flag, and it is enabled by configuration until it becomes the default.
+package features
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Features known to the router. The names are the keys of the features
+// configuration section.
+const (
+	// SSU2 enables the SSU2 UDP transport
+	SSU2 = "ssu2"
+	// LS2_PUBLISH publishes LeaseSet2 instead of LeaseSet for clients
+	LS2_PUBLISH = "ls2_publish"
+)
+
+var ErrUnknownFeature = errors.New("unknown feature")
+
+// Feature is a flag and its state.
+type Feature struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// Registry holds feature flags. It is safe for concurrent use.
+type Registry struct {
+	mutex    sync.RWMutex
+	features map[string]*Feature
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{features: make(map[string]*Feature)}
+}
+
+// Define adds a feature, enabled if def is true. Defining a feature again
+// replaces its description and default and resets it to the default.
+func (registry *Registry) Define(name, description string, def bool) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.features[name] = &Feature{
+		Name:        name,
+		Description: description,
+		Default:     def,
+		Enabled:     def,
+	}
+}
+
+// Enabled reports whether the feature is enabled. Unknown features are not.
+func (registry *Registry) Enabled(name string) bool {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	feature, ok := registry.features[name]
+	return ok && feature.Enabled
+}
+
+// Set enables or disables a feature. It returns ErrUnknownFeature if the
+// feature was never defined, so a typo in the configuration is not ignored.
+func (registry *Registry) Set(name string, enabled bool) error {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	feature, ok := registry.features[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownFeature, name)
+	}
+	if feature.Enabled != enabled {
+		log.WithFields(logrus.Fields{
+			"feature": name,
+			"enabled": enabled,
+		}).Info("Feature flag changed")
+	}
+	feature.Enabled = enabled
+	return nil
+}
+
+// Apply sets every feature in settings. Unknown features are skipped and
+// returned together as one error after the known ones are set.
+func (registry *Registry) Apply(settings map[string]bool) error {
+	var errs []error
+	for name, enabled := range settings {
+		if err := registry.Set(name, enabled); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reset returns every feature to its default.
+func (registry *Registry) Reset() {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	for _, feature := range registry.features {
+		feature.Enabled = feature.Default
+	}
+}
+
+// All returns the features sorted by name.
+func (registry *Registry) All() []Feature {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	all := make([]Feature, 0, len(registry.features))
+	for _, feature := range registry.features {
+		all = append(all, *feature)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// Default is the registry subsystems check, with the features known to the
+// router defined.
+var Default = NewRegistry()
+
+func init() {
+	Default.Define(SSU2, "SSU2 UDP transport", false)
+	Default.Define(LS2_PUBLISH, "LeaseSet2 publishing for client destinations", false)
+}
+
+// Enabled reports whether the feature is enabled in the Default registry.
+func Enabled(name string) bool {
+	return Default.Enabled(name)
+}
@@ -0,0 +1,44 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry()
+	registry.Define("b", "second", true)
+	registry.Define("a", "first", false)
+
+	assert.True(t, registry.Enabled("b"))
+	assert.False(t, registry.Enabled("a"))
+	assert.False(t, registry.Enabled("unknown"))
+
+	require.NoError(t, registry.Set("a", true))
+	assert.True(t, registry.Enabled("a"))
+	assert.ErrorIs(t, registry.Set("unknown", true), ErrUnknownFeature)
+
+	all := registry.All()
+	require.Len(t, all, 2)
+	assert.Equal(t, Feature{Name: "a", Description: "first", Default: false, Enabled: true}, all[0])
+	assert.Equal(t, "b", all[1].Name)
+
+	registry.Reset()
+	assert.False(t, registry.Enabled("a"))
+}
+
+func TestApplySetsKnownFeatures(t *testing.T) {
+	registry := NewRegistry()
+	registry.Define(SSU2, "", false)
+	err := registry.Apply(map[string]bool{SSU2: true, "ssu3": true})
+	assert.ErrorIs(t, err, ErrUnknownFeature)
+	assert.True(t, registry.Enabled(SSU2))
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	for _, name := range []string{SSU2, LS2_PUBLISH} {
+		assert.False(t, Enabled(name), name)
+	}
+}
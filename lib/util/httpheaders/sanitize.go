@@ -0,0 +1,179 @@
+// Package httpheaders filters the HTTP headers passing through the HTTP
+// proxy and HTTP server tunnels, removing anything that could identify the
+// client or the server behind a destination.
+package httpheaders
+
+import (
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+const (
+	// DEFAULT_USER_AGENT is sent instead of the browser's User-Agent, the
+	// same value the Java router's HTTP proxy uses
+	DEFAULT_USER_AGENT = "MYOB/6.66 (AN/ON)"
+	// DEFAULT_ACCEPT_LANGUAGE replaces Accept-Language when accept headers
+	// are normalized
+	DEFAULT_ACCEPT_LANGUAGE = "en-US,en;q=0.5"
+	// DEFAULT_ACCEPT_ENCODING replaces Accept-Encoding when accept headers
+	// are normalized
+	DEFAULT_ACCEPT_ENCODING = "gzip, deflate"
+)
+
+// forwardingHeaders reveal the address of the client or of proxies on the way
+var forwardingHeaders = []string{
+	"Via",
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Forwarded-Server",
+	"X-Real-Ip",
+	"Client-Ip",
+	"From",
+}
+
+// hopByHopHeaders only apply to a single connection and must not be forwarded,
+// see RFC 9110 section 7.6.1
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// serverHeaders name the software of the server behind a destination
+var serverHeaders = []string{
+	"Server",
+	"X-Powered-By",
+	"X-AspNet-Version",
+	"X-AspNetMvc-Version",
+	"X-Runtime",
+	"X-Generator",
+}
+
+// Sanitizer holds the rules applied to headers passing through a tunnel.
+// Both the HTTP proxy and HTTP server tunnels use it, with the ProxySanitizer
+// and ServerSanitizer defaults.
+type Sanitizer struct {
+	// UserAgent replaces the User-Agent of requests, empty keeps it
+	UserAgent string
+	// NormalizeAccept replaces Accept-Language and Accept-Encoding with
+	// fixed values and drops Accept-Charset, which otherwise fingerprint
+	// the browser and its locale
+	NormalizeAccept bool
+	// StripI2PHeaders removes X-I2P-* request headers, so a client cannot
+	// forge the ones a server tunnel adds about it
+	StripI2PHeaders bool
+	// KeepUpgrade forwards Connection: Upgrade, needed for websockets
+	KeepUpgrade bool
+}
+
+// ProxySanitizer is the default for the client side HTTP proxy.
+var ProxySanitizer = Sanitizer{
+	UserAgent:       DEFAULT_USER_AGENT,
+	NormalizeAccept: true,
+	StripI2PHeaders: true,
+}
+
+// ServerSanitizer is the default for HTTP server tunnels. The client side
+// already normalized the request, the server only has to distrust it.
+var ServerSanitizer = Sanitizer{
+	StripI2PHeaders: true,
+}
+
+// Request filters the headers of a request in place.
+func (sanitizer Sanitizer) Request(header http.Header) {
+	removed := sanitizer.removeHopByHop(header)
+	removed += remove(header, forwardingHeaders...)
+	if sanitizer.StripI2PHeaders {
+		for key := range header {
+			if strings.HasPrefix(key, "X-I2p-") {
+				header.Del(key)
+				removed++
+			}
+		}
+	}
+	if sanitizer.UserAgent != "" {
+		header.Set("User-Agent", sanitizer.UserAgent)
+	}
+	if sanitizer.NormalizeAccept {
+		removed += remove(header, "Accept-Charset")
+		header.Set("Accept-Language", DEFAULT_ACCEPT_LANGUAGE)
+		if header.Get("Accept-Encoding") != "" {
+			header.Set("Accept-Encoding", DEFAULT_ACCEPT_ENCODING)
+		}
+	}
+	log.WithFields(logrus.Fields{
+		"removed": removed,
+	}).Debug("Sanitized request headers")
+}
+
+// Response filters the headers of a response in place.
+func (sanitizer Sanitizer) Response(header http.Header) {
+	removed := sanitizer.removeHopByHop(header)
+	removed += remove(header, "Via")
+	removed += remove(header, serverHeaders...)
+	log.WithFields(logrus.Fields{
+		"removed": removed,
+	}).Debug("Sanitized response headers")
+}
+
+// removeHopByHop removes the hop-by-hop headers and any header the Connection
+// header names, keeping an upgrade if allowed. Returns the number removed.
+func (sanitizer Sanitizer) removeHopByHop(header http.Header) int {
+	upgrade := ""
+	if sanitizer.KeepUpgrade && connectionHas(header, "Upgrade") {
+		upgrade = header.Get("Upgrade")
+	}
+	removed := 0
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = textproto.TrimString(name); name != "" {
+				removed += remove(header, name)
+			}
+		}
+	}
+	removed += remove(header, hopByHopHeaders...)
+	if upgrade != "" {
+		header.Set("Connection", "Upgrade")
+		header.Set("Upgrade", upgrade)
+	}
+	return removed
+}
+
+// connectionHas reports whether the Connection header lists option
+func connectionHas(header http.Header, option string) bool {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(textproto.TrimString(name), option) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remove deletes the named headers and returns how many were present
+func remove(header http.Header, names ...string) int {
+	removed := 0
+	for _, name := range names {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if _, ok := header[key]; ok {
+			delete(header, key)
+			removed++
+		}
+	}
+	return removed
+}
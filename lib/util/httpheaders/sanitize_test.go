@@ -0,0 +1,81 @@
+package httpheaders
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxySanitizerRequest(t *testing.T) {
+	header := http.Header{}
+	header.Set("Host", "example.i2p")
+	header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0")
+	header.Set("Accept", "text/html")
+	header.Set("Accept-Language", "de-DE,de;q=0.9")
+	header.Set("Accept-Charset", "utf-8")
+	header.Set("Accept-Encoding", "gzip, deflate, br, zstd")
+	header.Set("Via", "1.1 squid")
+	header.Set("X-Forwarded-For", "192.0.2.1")
+	header.Set("Forwarded", "for=192.0.2.1")
+	header.Set("X-I2P-DestHash", "forged")
+	header.Set("Proxy-Connection", "keep-alive")
+	header.Set("Connection", "keep-alive, X-Secret")
+	header.Set("X-Secret", "1")
+
+	ProxySanitizer.Request(header)
+
+	assert.Equal(t, http.Header{
+		"Host":            {"example.i2p"},
+		"User-Agent":      {DEFAULT_USER_AGENT},
+		"Accept":          {"text/html"},
+		"Accept-Language": {DEFAULT_ACCEPT_LANGUAGE},
+		"Accept-Encoding": {DEFAULT_ACCEPT_ENCODING},
+	}, header)
+}
+
+func TestServerSanitizerRequest(t *testing.T) {
+	header := http.Header{}
+	header.Set("User-Agent", DEFAULT_USER_AGENT)
+	header.Set("Accept-Language", "de-DE")
+	header.Set("X-Forwarded-For", "192.0.2.1")
+	header.Set("X-I2P-DestB32", "forged.b32.i2p")
+
+	ServerSanitizer.Request(header)
+
+	assert.Equal(t, http.Header{
+		"User-Agent":      {DEFAULT_USER_AGENT},
+		"Accept-Language": {"de-DE"},
+	}, header)
+}
+
+func TestSanitizerKeepUpgrade(t *testing.T) {
+	header := http.Header{}
+	header.Set("Connection", "Upgrade")
+	header.Set("Upgrade", "websocket")
+
+	Sanitizer{}.Request(header)
+	assert.Empty(t, header)
+
+	header.Set("Connection", "keep-alive, Upgrade")
+	header.Set("Upgrade", "websocket")
+	Sanitizer{KeepUpgrade: true}.Request(header)
+	assert.Equal(t, http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, header)
+}
+
+func TestSanitizerResponse(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Type", "text/html")
+	header.Set("Set-Cookie", "session=1")
+	header.Set("Server", "nginx/1.24.0")
+	header.Set("X-Powered-By", "PHP/8.3")
+	header.Set("Via", "1.1 varnish")
+	header.Set("Keep-Alive", "timeout=5")
+
+	ServerSanitizer.Response(header)
+
+	assert.Equal(t, http.Header{
+		"Content-Type": {"text/html"},
+		"Set-Cookie":   {"session=1"},
+	}, header)
+}
@@ -0,0 +1,72 @@
+//go:build darwin
+// +build darwin
+
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// exit status of security(1) when the item does not exist
+const errSecItemNotFound = 44
+
+// macKeychain stores secrets as generic passwords in the login keychain,
+// through security(1)
+type macKeychain struct{}
+
+// System returns the keychain of the operating system.
+func System() Keychain {
+	return macKeychain{}
+}
+
+func securityError(err error) error {
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && exit.ExitCode() == errSecItemNotFound {
+		return ErrNotFound
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrUnsupported
+	}
+	return err
+}
+
+func (macKeychain) Get(service, account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", securityError(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set runs the command in the interactive mode of security(1), which reads
+// it from stdin, so the secret does not show up in the process list as
+// an argument to -w would.
+func (macKeychain) Set(service, account, secret string) error {
+	if strings.ContainsAny(secret, "\n\x00") {
+		return errors.New("secret contains a line break or NUL byte")
+	}
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader("add-generic-password -U -s " + securityQuote(service) +
+		" -a " + securityQuote(account) + " -w " + securityQuote(secret) + "\n")
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return securityError(err)
+	}
+	// failed commands are reported on stderr, not always in the exit status
+	if stderr.Len() > 0 {
+		return errors.New(strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// securityQuote quotes an argument for the interactive mode of security(1)
+func securityQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (macKeychain) Delete(service, account string) error {
+	return securityError(exec.Command("security", "delete-generic-password", "-s", service, "-a", account).Run())
+}
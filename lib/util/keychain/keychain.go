@@ -0,0 +1,103 @@
+// Package keychain stores secrets, such as the passphrases private key files
+// are sealed with, in the keyring of the operating system: the Keychain on
+// macOS, DPAPI on Windows and the Secret Service elsewhere. This lets the
+// router start unattended without plaintext passphrases on disk.
+package keychain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// DEFAULT_SERVICE is the service secrets of the router are stored under
+const DEFAULT_SERVICE = "go-i2p"
+
+// PASSPHRASE_SIZE is how many random bytes a generated passphrase encodes
+const PASSPHRASE_SIZE = 32
+
+var (
+	ErrNotFound    = errors.New("secret not found in keychain")
+	ErrUnsupported = errors.New("no keychain available on this system")
+)
+
+// Keychain stores secrets by service and account.
+type Keychain interface {
+	// Get returns the secret, or ErrNotFound.
+	Get(service, account string) (string, error)
+	// Set stores the secret, replacing any previous one.
+	Set(service, account, secret string) error
+	// Delete removes the secret, or returns ErrNotFound.
+	Delete(service, account string) error
+}
+
+// Passphrase returns the passphrase stored for account, generating and
+// storing a random one if there is none yet.
+func Passphrase(keychain Keychain, service, account string) ([]byte, error) {
+	secret, err := keychain.Get(service, account)
+	if err == nil {
+		return []byte(secret), nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	random := make([]byte, PASSPHRASE_SIZE)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	secret = base64.RawURLEncoding.EncodeToString(random)
+	if err := keychain.Set(service, account, secret); err != nil {
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"service": service,
+		"account": account,
+	}).Info("Stored new passphrase in keychain")
+	return []byte(secret), nil
+}
+
+// Memory is a Keychain keeping secrets in memory, for tests and for
+// embedders managing secrets themselves.
+type Memory struct {
+	mutex   sync.Mutex
+	secrets map[[2]string]string
+}
+
+// NewMemory returns an empty in-memory keychain.
+func NewMemory() *Memory {
+	return &Memory{secrets: make(map[[2]string]string)}
+}
+
+func (memory *Memory) Get(service, account string) (string, error) {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	secret, ok := memory.secrets[[2]string{service, account}]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (memory *Memory) Set(service, account, secret string) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	memory.secrets[[2]string{service, account}] = secret
+	return nil
+}
+
+func (memory *Memory) Delete(service, account string) error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	key := [2]string{service, account}
+	if _, ok := memory.secrets[key]; !ok {
+		return ErrNotFound
+	}
+	delete(memory.secrets, key)
+	return nil
+}
@@ -0,0 +1,49 @@
+package keychain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory(t *testing.T) {
+	keychain := NewMemory()
+	_, err := keychain.Get(DEFAULT_SERVICE, "router")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, keychain.Set(DEFAULT_SERVICE, "router", "secret"))
+	secret, err := keychain.Get(DEFAULT_SERVICE, "router")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+	_, err = keychain.Get("other", "router")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, keychain.Delete(DEFAULT_SERVICE, "router"))
+	assert.ErrorIs(t, keychain.Delete(DEFAULT_SERVICE, "router"), ErrNotFound)
+}
+
+func TestPassphraseIsCreatedOnce(t *testing.T) {
+	keychain := NewMemory()
+	created, err := Passphrase(keychain, DEFAULT_SERVICE, "research.key")
+	require.NoError(t, err)
+	assert.NotEmpty(t, created)
+
+	loaded, err := Passphrase(keychain, DEFAULT_SERVICE, "research.key")
+	require.NoError(t, err)
+	assert.Equal(t, created, loaded)
+
+	other, err := Passphrase(keychain, DEFAULT_SERVICE, "other.key")
+	require.NoError(t, err)
+	assert.NotEqual(t, created, other)
+}
+
+// failing is a keychain which cannot be reached
+type failing struct{ *Memory }
+
+func (failing) Get(service, account string) (string, error) { return "", ErrUnsupported }
+
+func TestPassphraseKeychainUnavailable(t *testing.T) {
+	_, err := Passphrase(failing{NewMemory()}, DEFAULT_SERVICE, "research.key")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
@@ -0,0 +1,55 @@
+//go:build !darwin && !windows
+// +build !darwin,!windows
+
+package keychain
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// secretService stores secrets through the freedesktop.org Secret Service,
+// GNOME Keyring or KWallet, using secret-tool(1)
+type secretService struct{}
+
+// System returns the keychain of the operating system.
+func System() Keychain {
+	return secretService{}
+}
+
+func secretTool(stdin string, args ...string) (string, error) {
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", ErrUnsupported
+	}
+	return string(out), err
+}
+
+func (secretService) Get(service, account string) (string, error) {
+	secret, err := secretTool("", "lookup", "service", service, "account", account)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(exit.Stderr) == 0 {
+		// secret-tool fails silently when nothing matches
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+func (secretService) Set(service, account, secret string) error {
+	_, err := secretTool(secret, "store", "--label="+service+" "+account, "service", service, "account", account)
+	return err
+}
+
+func (secretService) Delete(service, account string) error {
+	if _, err := (secretService{}).Get(service, account); err != nil {
+		return err
+	}
+	_, err := secretTool("", "clear", "service", service, "account", account)
+	return err
+}
@@ -0,0 +1,103 @@
+//go:build windows
+// +build windows
+
+package keychain
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// dpapi stores secrets in files in the user's configuration directory,
+// encrypted with DPAPI so only the same Windows user can read them
+type dpapi struct{}
+
+// System returns the keychain of the operating system.
+func System() Keychain {
+	return dpapi{}
+}
+
+func dpapiPath(service, account string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", ErrUnsupported
+	}
+	return filepath.Join(dir, "go-i2p", "keychain", url.PathEscape(service), url.PathEscape(account)+".dpapi"), nil
+}
+
+func blob(data []byte) *windows.DataBlob {
+	if len(data) == 0 {
+		return &windows.DataBlob{}
+	}
+	return &windows.DataBlob{Size: uint32(len(data)), Data: &data[0]}
+}
+
+// dpapiCall runs CryptProtectData or CryptUnprotectData, binding the secret
+// to its service and account as entropy
+func dpapiCall(protect bool, service, account string, in []byte) ([]byte, error) {
+	entropy := blob([]byte(service + "\x00" + account))
+	var out windows.DataBlob
+	var err error
+	if protect {
+		err = windows.CryptProtectData(blob(in), nil, entropy, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	} else {
+		err = windows.CryptUnprotectData(blob(in), nil, entropy, 0, nil, windows.CRYPTPROTECT_UI_FORBIDDEN, &out)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer windows.LocalFree(windows.Handle(unsafe.Pointer(out.Data)))
+	return append([]byte(nil), unsafe.Slice(out.Data, out.Size)...), nil
+}
+
+func (dpapi) Get(service, account string) (string, error) {
+	path, err := dpapiPath(service, account)
+	if err != nil {
+		return "", err
+	}
+	protected, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	secret, err := dpapiCall(false, service, account, protected)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+func (dpapi) Set(service, account, secret string) error {
+	path, err := dpapiPath(service, account)
+	if err != nil {
+		return err
+	}
+	protected, err := dpapiCall(true, service, account, []byte(secret))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, protected, 0o600)
+}
+
+func (dpapi) Delete(service, account string) error {
+	path, err := dpapiPath(service, account)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
@@ -28,7 +28,7 @@ type Entry struct {
 
 // Warn wraps logrus.Warn and logs a fatal error if failFast is set
 func (l *Logger) Warn(args ...interface{}) {
-	warnFatal(args)
+	warnFatal(args...)
 	l.Logger.Warn(args...)
 }
 
@@ -40,7 +40,7 @@ func (l *Logger) Warnf(format string, args ...interface{}) {
 
 // Error wraps logrus.Error and logs a fatal error if failFast is set
 func (l *Logger) Error(args ...interface{}) {
-	warnFatal(args)
+	warnFatal(args...)
 	l.Logger.Error(args...)
 }
 
@@ -70,7 +70,7 @@ func (l *Logger) WithError(err error) *Entry {
 
 func warnFatal(args ...interface{}) {
 	if failFast != "" {
-		log.Fatal(args)
+		log.Fatal(args...)
 	}
 }
 
@@ -91,9 +91,11 @@ func InitializeGoI2PLogger() {
 	once.Do(func() {
 		log = &Logger{}
 		log.Logger = logrus.New()
-		// We do not want to log by default
+		log.AddHook(recent)
+		// We do not want to log by default, warnings and errors are only
+		// kept for Recent
 		log.SetOutput(io.Discard)
-		log.SetLevel(logrus.PanicLevel)
+		log.SetLevel(logrus.WarnLevel)
 		// Check if DEBUG_I2P is set
 		if logLevel := os.Getenv("DEBUG_I2P"); logLevel != "" {
 			failFast = os.Getenv("WARNFAIL_I2P")
@@ -102,6 +104,8 @@ func InitializeGoI2PLogger() {
 			}
 			log.SetOutput(os.Stdout)
 			switch strings.ToLower(logLevel) {
+			case "trace":
+				log.SetLevel(logrus.TraceLevel)
 			case "debug":
 				log.SetLevel(logrus.DebugLevel)
 			case "warn":
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RECENT_LOG_LINES is how many log lines Recent keeps
+const RECENT_LOG_LINES = 1000
+
+// recentHook keeps the last lines logged in a ring, for support bundles.
+// Without DEBUG_I2P nothing is printed, but warnings and errors still reach
+// it.
+type recentHook struct {
+	mutex     sync.Mutex
+	formatter logrus.Formatter
+	lines     []string
+	next      int
+}
+
+var recent = newRecentHook(RECENT_LOG_LINES)
+
+func newRecentHook(size int) *recentHook {
+	return &recentHook{
+		formatter: &logrus.TextFormatter{DisableColors: true, FullTimestamp: true},
+		lines:     make([]string, 0, size),
+	}
+}
+
+// Levels implements logrus.Hook.
+func (h *recentHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook.
+func (h *recentHook) Fire(entry *logrus.Entry) error {
+	line, err := h.formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.lines) < cap(h.lines) {
+		h.lines = append(h.lines, string(line))
+		return nil
+	}
+	h.lines[h.next] = string(line)
+	h.next = (h.next + 1) % len(h.lines)
+	return nil
+}
+
+// snapshot returns the kept lines, oldest first
+func (h *recentHook) snapshot() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	lines := make([]string, 0, len(h.lines))
+	lines = append(lines, h.lines[h.next:]...)
+	return append(lines, h.lines[:h.next]...)
+}
+
+// Recent returns the last RECENT_LOG_LINES lines logged, oldest first, each
+// ending in a newline.
+func Recent() []string {
+	return recent.snapshot()
+}
@@ -0,0 +1,36 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestRecentKeepsLastLines(t *testing.T) {
+	hook := newRecentHook(3)
+	l := logrus.New()
+	l.SetOutput(&strings.Builder{})
+	l.AddHook(hook)
+	for i := 0; i < 5; i++ {
+		l.Warn(fmt.Sprintf("message %d", i))
+	}
+	lines := hook.snapshot()
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf("message %d", i+2)) || !strings.HasSuffix(line, "\n") {
+			t.Fatalf("unexpected line %d: %q", i, line)
+		}
+	}
+}
+
+func TestRecentKeepsWarningsWithoutOutput(t *testing.T) {
+	GetGoI2PLogger().Error("recent test error")
+	lines := Recent()
+	if len(lines) == 0 || !strings.Contains(lines[len(lines)-1], "recent test error") {
+		t.Fatalf("error not kept: %q", lines)
+	}
+}
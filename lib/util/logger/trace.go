@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TraceEnabled reports whether hot path statements, logged at trace level
+// from accessors and parsers that run in tight loops, should be built and
+// logged. Guard them with it:
+//
+//	if log.TraceEnabled() {
+//		log.WithField("length", length).Trace("Retrieved certificate length")
+//	}
+//
+// Builds with the notrace tag compile the guarded statements out, other
+// builds log them when DEBUG_I2P=trace.
+func (l *Logger) TraceEnabled() bool {
+	return traceCompiled && l.IsLevelEnabled(logrus.TraceLevel)
+}
+
+// Throttle limits a log statement to once per interval, for warnings that
+// can repeat on every packet. It is safe for concurrent use.
+type Throttle struct {
+	interval time.Duration
+	last     atomic.Int64
+	// suppressed counts calls refused since the last allowed one
+	suppressed atomic.Uint64
+}
+
+// NewThrottle returns a Throttle allowing one statement per interval.
+func NewThrottle(interval time.Duration) *Throttle {
+	return &Throttle{interval: interval}
+}
+
+// Allow reports whether the statement may be logged now, and how many were
+// suppressed since the last one that was.
+func (t *Throttle) Allow() (bool, uint64) {
+	now := time.Now().UnixNano()
+	last := t.last.Load()
+	if last != 0 && now-last < int64(t.interval) {
+		t.suppressed.Add(1)
+		return false, 0
+	}
+	if !t.last.CompareAndSwap(last, now) {
+		t.suppressed.Add(1)
+		return false, 0
+	}
+	return true, t.suppressed.Swap(0)
+}
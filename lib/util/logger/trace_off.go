@@ -0,0 +1,6 @@
+//go:build notrace
+
+package logger
+
+// hot path trace statements are compiled out
+const traceCompiled = false
@@ -0,0 +1,5 @@
+//go:build !notrace
+
+package logger
+
+const traceCompiled = true
@@ -0,0 +1,37 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestThrottle(t *testing.T) {
+	throttle := NewThrottle(time.Hour)
+	if ok, _ := throttle.Allow(); !ok {
+		t.Fatal("first statement should be allowed")
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := throttle.Allow(); ok {
+			t.Fatal("statement within the interval should be suppressed")
+		}
+	}
+	throttle.last.Store(time.Now().Add(-2 * time.Hour).UnixNano())
+	ok, suppressed := throttle.Allow()
+	if !ok || suppressed != 3 {
+		t.Fatalf("expected allowed after interval with 3 suppressed, got %v %d", ok, suppressed)
+	}
+}
+
+func TestTraceEnabledFollowsLevel(t *testing.T) {
+	l := &Logger{Logger: logrus.New()}
+	l.SetLevel(logrus.DebugLevel)
+	if l.TraceEnabled() {
+		t.Fatal("trace should be off at debug level")
+	}
+	l.SetLevel(logrus.TraceLevel)
+	if l.TraceEnabled() != traceCompiled {
+		t.Fatalf("trace should be %v at trace level", traceCompiled)
+	}
+}
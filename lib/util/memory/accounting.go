@@ -0,0 +1,150 @@
+// Package memory keeps approximate accounts of the memory held by the
+// router's caches and evicts from those which grow past their ceiling.
+package memory
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+// Cache is a store whose memory is accounted. Sizes are estimates of the
+// bytes held by the entries, not exact heap usage.
+type Cache interface {
+	// MemoryUsage returns the number of entries held and their size in bytes
+	MemoryUsage() (entries int, bytes int64)
+	// Evict drops entries until at least bytes were freed or the cache is
+	// empty, least valuable first, and returns the bytes freed
+	Evict(bytes int64) (freed int64)
+}
+
+// GaugeFunc accounts a store which cannot evict, such as a queue, reporting
+// the number of entries and their size in bytes.
+type GaugeFunc func() (entries int, bytes int64)
+
+// MemoryUsage calls f.
+func (f GaugeFunc) MemoryUsage() (int, int64) {
+	return f()
+}
+
+// Evict frees nothing.
+func (f GaugeFunc) Evict(int64) int64 {
+	return 0
+}
+
+// Usage is the accounted memory of a cache.
+type Usage struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	// Limit is the ceiling in bytes, 0 for none
+	Limit int64 `json:"limit"`
+	// Evicted is the number of bytes evicted since the cache was registered
+	Evicted int64 `json:"evicted"`
+}
+
+type account struct {
+	cache   Cache
+	limit   int64
+	evicted int64
+}
+
+// Accountant tracks the caches of the router by name.
+type Accountant struct {
+	mutex    sync.Mutex
+	accounts map[string]*account
+}
+
+// NewAccountant returns an Accountant with no caches.
+func NewAccountant() *Accountant {
+	return &Accountant{accounts: make(map[string]*account)}
+}
+
+// Register accounts cache under name, replacing any cache registered under
+// it before. A limit above 0 is the ceiling in bytes Enforce evicts down to.
+func (accountant *Accountant) Register(name string, cache Cache, limit int64) {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	accountant.accounts[name] = &account{cache: cache, limit: limit}
+}
+
+// Unregister stops accounting the cache registered under name.
+func (accountant *Accountant) Unregister(name string) {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	delete(accountant.accounts, name)
+}
+
+// Names returns the names of the registered caches in sorted order.
+func (accountant *Accountant) Names() []string {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	names := make([]string, 0, len(accountant.accounts))
+	for name := range accountant.accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Usage returns the accounted memory of every registered cache.
+func (accountant *Accountant) Usage() map[string]Usage {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	usage := make(map[string]Usage, len(accountant.accounts))
+	for name, account := range accountant.accounts {
+		usage[name] = account.usage()
+	}
+	return usage
+}
+
+// CacheUsage returns the accounted memory of the cache registered under
+// name, and false if there is none.
+func (accountant *Accountant) CacheUsage(name string) (Usage, bool) {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	account, ok := accountant.accounts[name]
+	if !ok {
+		return Usage{}, false
+	}
+	return account.usage(), true
+}
+
+// Enforce evicts from every cache above its limit until it is back under it,
+// and returns the bytes freed in total.
+func (accountant *Accountant) Enforce() (freed int64) {
+	accountant.mutex.Lock()
+	defer accountant.mutex.Unlock()
+	for name, account := range accountant.accounts {
+		if account.limit <= 0 {
+			continue
+		}
+		_, bytes := account.cache.MemoryUsage()
+		if bytes <= account.limit {
+			continue
+		}
+		evicted := account.cache.Evict(bytes - account.limit)
+		account.evicted += evicted
+		freed += evicted
+		log.WithFields(logrus.Fields{
+			"cache":   name,
+			"bytes":   bytes,
+			"limit":   account.limit,
+			"evicted": evicted,
+		}).Info("Cache over memory limit, evicted entries")
+	}
+	return
+}
+
+func (account *account) usage() Usage {
+	entries, bytes := account.cache.MemoryUsage()
+	return Usage{
+		Entries: entries,
+		Bytes:   bytes,
+		Limit:   account.limit,
+		Evicted: account.evicted,
+	}
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testCache holds entries of 10 bytes each
+type testCache struct {
+	entries int
+}
+
+func (cache *testCache) MemoryUsage() (int, int64) {
+	return cache.entries, int64(cache.entries * 10)
+}
+
+func (cache *testCache) Evict(bytes int64) (freed int64) {
+	for freed < bytes && cache.entries > 0 {
+		cache.entries--
+		freed += 10
+	}
+	return
+}
+
+func TestAccountantEnforcesLimits(t *testing.T) {
+	accountant := NewAccountant()
+	limited := &testCache{entries: 10}
+	unlimited := &testCache{entries: 10}
+	accountant.Register("limited", limited, 75)
+	accountant.Register("unlimited", unlimited, 0)
+	accountant.Register("queue", GaugeFunc(func() (int, int64) { return 3, 300 }), 100)
+
+	assert.Equal(t, []string{"limited", "queue", "unlimited"}, accountant.Names())
+	assert.Equal(t, int64(30), accountant.Enforce())
+	assert.Equal(t, 7, limited.entries)
+	assert.Equal(t, 10, unlimited.entries)
+
+	usage := accountant.Usage()
+	assert.Equal(t, Usage{Entries: 7, Bytes: 70, Limit: 75, Evicted: 30}, usage["limited"])
+	assert.Equal(t, Usage{Entries: 3, Bytes: 300, Limit: 100}, usage["queue"])
+
+	// under the limit nothing more is evicted
+	assert.Zero(t, accountant.Enforce())
+
+	accountant.Unregister("limited")
+	_, ok := accountant.CacheUsage("limited")
+	assert.False(t, ok)
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"io/fs"
+	"os"
+	"runtime"
+)
+
+// KEY_FILE_MODE is the mode of private key files, readable by their owner
+// only
+const KEY_FILE_MODE fs.FileMode = 0o600
+
+// CheckKeyPermissions reports whether the private key file at fpath is
+// accessible by users other than its owner. If it is and fix is set its
+// mode is restricted to KEY_FILE_MODE. A missing file is not an error. On
+// Windows, where file modes don't reflect access, it reports false.
+func CheckKeyPermissions(fpath string, fix bool) (tooOpen bool, err error) {
+	if runtime.GOOS == "windows" {
+		return false, nil
+	}
+	info, err := os.Stat(fpath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.Mode().Perm()&0o077 == 0 {
+		return false, nil
+	}
+	if fix {
+		err = os.Chmod(fpath, KEY_FILE_MODE)
+	}
+	return true, err
+}
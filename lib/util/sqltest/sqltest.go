@@ -0,0 +1,400 @@
+// Package sqltest provides an in-memory database/sql driver for testing the
+// SQL backed stores without linking an SQLite driver into the module.
+//
+// The driver understands just the statements those stores issue:
+//
+//	CREATE TABLE IF NOT EXISTS t (col TYPE [PRIMARY KEY] [NOT NULL], ...)
+//	INSERT INTO t (cols) VALUES (?, ...) [ON CONFLICT(col) DO UPDATE SET ...]
+//	SELECT cols FROM t [WHERE col = ?] [ORDER BY col]
+//	DELETE FROM t WHERE col = ?
+//
+// An upsert replaces every column of the conflicting row, which is what the
+// stores' "SET col = excluded.col" clauses amount to. Anything else fails with
+// ErrUnsupported.
+package sqltest
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrUnsupported is returned for statements the driver does not understand.
+	ErrUnsupported = errors.New("sqltest: unsupported statement")
+	// ErrNoTable is returned for statements on a table that was not created.
+	ErrNoTable = errors.New("sqltest: no such table")
+	// ErrConstraint is returned for an insert that conflicts with an existing
+	// row and has no ON CONFLICT clause.
+	ErrConstraint = errors.New("sqltest: UNIQUE constraint failed")
+)
+
+var (
+	createPattern = regexp.MustCompile(`^CREATE TABLE IF NOT EXISTS (\w+) \((.*)\)$`)
+	insertPattern = regexp.MustCompile(`^INSERT INTO (\w+) \(([^)]*)\) VALUES \(([^)]*)\)( ON CONFLICT\((\w+)\) DO UPDATE SET .*)?$`)
+	selectPattern = regexp.MustCompile(`^SELECT (.+) FROM (\w+)( WHERE (\w+) = \?)?( ORDER BY (\w+))?$`)
+	deletePattern = regexp.MustCompile(`^DELETE FROM (\w+) WHERE (\w+) = \?$`)
+)
+
+// DB is the state behind a database opened with Open.
+type DB struct {
+	mutex  sync.Mutex
+	tables map[string]*table
+	saved  map[string]*table
+	fail   func(query string, args []driver.Value) error
+}
+
+type table struct {
+	columns []string
+	key     int
+	rows    map[string][]driver.Value
+}
+
+// Open returns a database backed by a fresh in-memory DB.
+func Open() (*sql.DB, *DB) {
+	state := &DB{tables: make(map[string]*table)}
+	return sql.OpenDB(connector{state}), state
+}
+
+// Fail installs fn to be consulted before every statement; a non-nil error
+// from fn fails the statement. Pass nil to stop injecting failures.
+func (state *DB) Fail(fn func(query string, args []driver.Value) error) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.fail = fn
+}
+
+// Rows returns the number of rows in the named table.
+func (state *DB) Rows(name string) int {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if t, ok := state.tables[name]; ok {
+		return len(t.rows)
+	}
+	return 0
+}
+
+// exec runs query and returns the selected rows, if any.
+func (state *DB) exec(query string, args []driver.Value) (*rows, int64, error) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.fail != nil {
+		if err := state.fail(query, args); err != nil {
+			return nil, 0, err
+		}
+	}
+	if m := createPattern.FindStringSubmatch(query); m != nil {
+		return nil, 0, state.create(m[1], m[2])
+	}
+	if m := insertPattern.FindStringSubmatch(query); m != nil {
+		return nil, 1, state.insert(m[1], splitList(m[2]), args, m[4] != "")
+	}
+	if m := selectPattern.FindStringSubmatch(query); m != nil {
+		selected, err := state.selectRows(m[2], splitList(m[1]), m[4], m[6], args)
+		return selected, 0, err
+	}
+	if m := deletePattern.FindStringSubmatch(query); m != nil {
+		return state.delete(m[1], m[2], args)
+	}
+	return nil, 0, fmt.Errorf("%w: %s", ErrUnsupported, query)
+}
+
+func (state *DB) create(name, definition string) error {
+	if _, ok := state.tables[name]; ok {
+		return nil
+	}
+	t := &table{rows: make(map[string][]driver.Value)}
+	for i, column := range splitList(definition) {
+		fields := strings.Fields(column)
+		t.columns = append(t.columns, fields[0])
+		if strings.Contains(column, "PRIMARY KEY") {
+			t.key = i
+		}
+	}
+	state.tables[name] = t
+	return nil
+}
+
+func (state *DB) insert(name string, columns []string, args []driver.Value, upsert bool) error {
+	t, ok := state.tables[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+	if len(columns) != len(args) {
+		return fmt.Errorf("sqltest: %d values for %d columns", len(args), len(columns))
+	}
+	row := make([]driver.Value, len(t.columns))
+	for i, column := range columns {
+		index, err := t.column(column)
+		if err != nil {
+			return err
+		}
+		row[index] = clone(args[i])
+	}
+	key := keyOf(row[t.key])
+	if _, exists := t.rows[key]; exists && !upsert {
+		return fmt.Errorf("%w: %s.%s", ErrConstraint, name, t.columns[t.key])
+	}
+	t.rows[key] = row
+	return nil
+}
+
+func (state *DB) selectRows(name string, columns []string, where, order string, args []driver.Value) (*rows, error) {
+	t, ok := state.tables[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+	indexes := make([]int, len(columns))
+	for i, column := range columns {
+		index, err := t.column(column)
+		if err != nil {
+			return nil, err
+		}
+		indexes[i] = index
+	}
+	matching, err := t.match(where, args)
+	if err != nil {
+		return nil, err
+	}
+	sortBy := t.key
+	if order != "" {
+		if sortBy, err = t.column(order); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		return keyOf(matching[i][sortBy]) < keyOf(matching[j][sortBy])
+	})
+	selected := &rows{columns: columns}
+	for _, row := range matching {
+		values := make([]driver.Value, len(indexes))
+		for i, index := range indexes {
+			values[i] = clone(row[index])
+		}
+		selected.values = append(selected.values, values)
+	}
+	return selected, nil
+}
+
+func (state *DB) delete(name, where string, args []driver.Value) (*rows, int64, error) {
+	t, ok := state.tables[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: %s", ErrNoTable, name)
+	}
+	matching, err := t.match(where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, row := range matching {
+		delete(t.rows, keyOf(row[t.key]))
+	}
+	return nil, int64(len(matching)), nil
+}
+
+// begin snapshots the tables so rollback can restore them.
+func (state *DB) begin() error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.saved != nil {
+		return errors.New("sqltest: transaction already open")
+	}
+	state.saved = make(map[string]*table, len(state.tables))
+	for name, t := range state.tables {
+		copied := &table{columns: t.columns, key: t.key, rows: make(map[string][]driver.Value, len(t.rows))}
+		for key, row := range t.rows {
+			copied.rows[key] = append([]driver.Value(nil), row...)
+		}
+		state.saved[name] = copied
+	}
+	return nil
+}
+
+func (state *DB) end(rollback bool) error {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.saved == nil {
+		return errors.New("sqltest: no transaction open")
+	}
+	if rollback {
+		state.tables = state.saved
+	}
+	state.saved = nil
+	return nil
+}
+
+func (t *table) column(name string) (int, error) {
+	for i, column := range t.columns {
+		if column == name {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("sqltest: no such column: %s", name)
+}
+
+// match returns the rows whose where column equals the single argument, or
+// every row when there is no where clause.
+func (t *table) match(where string, args []driver.Value) ([][]driver.Value, error) {
+	var matching [][]driver.Value
+	if where == "" {
+		for _, row := range t.rows {
+			matching = append(matching, row)
+		}
+		return matching, nil
+	}
+	index, err := t.column(where)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != 1 {
+		return nil, fmt.Errorf("sqltest: %d values for 1 parameter", len(args))
+	}
+	want := keyOf(args[0])
+	for _, row := range t.rows {
+		if keyOf(row[index]) == want {
+			matching = append(matching, row)
+		}
+	}
+	return matching, nil
+}
+
+// normalize collapses the whitespace of query so statements written over
+// several lines match the patterns.
+func normalize(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	query = strings.ReplaceAll(query, "( ", "(")
+	return strings.ReplaceAll(query, " )", ")")
+}
+
+func splitList(list string) []string {
+	parts := strings.Split(list, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+func keyOf(value driver.Value) string {
+	if b, ok := value.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(value)
+}
+
+func clone(value driver.Value) driver.Value {
+	if b, ok := value.([]byte); ok {
+		return append([]byte(nil), b...)
+	}
+	return value
+}
+
+type connector struct {
+	state *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{state: c.state}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return sqlDriver{c.state}
+}
+
+type sqlDriver struct {
+	state *DB
+}
+
+func (d sqlDriver) Open(string) (driver.Conn, error) {
+	return &conn{state: d.state}, nil
+}
+
+type conn struct {
+	state *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{state: c.state, query: normalize(query)}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	if err := c.state.begin(); err != nil {
+		return nil, err
+	}
+	return tx{c.state}, nil
+}
+
+type tx struct {
+	state *DB
+}
+
+func (t tx) Commit() error {
+	return t.state.end(false)
+}
+
+func (t tx) Rollback() error {
+	return t.state.end(true)
+}
+
+type stmt struct {
+	state *DB
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	_, affected, err := s.state.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(affected), nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	selected, _, err := s.state.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	if selected == nil {
+		selected = &rows{}
+	}
+	return selected, nil
+}
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
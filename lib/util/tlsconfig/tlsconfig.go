@@ -0,0 +1,139 @@
+// Package tlsconfig builds the TLS configuration for the router's local
+// service ports, I2CP, SAM and the management API, from a provided
+// certificate or a generated self-signed one.
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+const (
+	// SELF_SIGNED_CERT_FILE and SELF_SIGNED_KEY_FILE are the names the
+	// generated certificate is stored under
+	SELF_SIGNED_CERT_FILE = "client-tls.crt"
+	SELF_SIGNED_KEY_FILE  = "client-tls.key"
+	// SELF_SIGNED_VALIDITY is how long a generated certificate is valid
+	SELF_SIGNED_VALIDITY = 10 * 365 * 24 * time.Hour
+)
+
+var ErrIncompleteKeyPair = errors.New("TLS certificate and key files must be given together")
+
+// ServerConfig returns a TLS server configuration using the certificate and
+// key in certFile and keyFile. If both are empty a self-signed certificate
+// for hosts is used instead: keyPath gives the paths of the files named
+// SELF_SIGNED_CERT_FILE and SELF_SIGNED_KEY_FILE, normally where the router
+// keeps its private keys. They are generated on first use and loaded after,
+// so clients can pin the certificate. The SHA-256 fingerprint of the
+// certificate is logged.
+func ServerConfig(certFile, keyFile string, keyPath func(name string) string, hosts []string) (*tls.Config, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, ErrIncompleteKeyPair
+	}
+	if certFile == "" {
+		certFile = keyPath(SELF_SIGNED_CERT_FILE)
+		keyFile = keyPath(SELF_SIGNED_KEY_FILE)
+		_, certErr := os.Stat(certFile)
+		_, keyErr := os.Stat(keyFile)
+		if os.IsNotExist(certErr) || os.IsNotExist(keyErr) {
+			if err := GenerateSelfSigned(certFile, keyFile, hosts); err != nil {
+				return nil, err
+			}
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		log.WithError(err).WithField("cert_file", certFile).Error("Failed to load TLS key pair")
+		return nil, err
+	}
+	log.WithFields(logrus.Fields{
+		"cert_file":   certFile,
+		"fingerprint": Fingerprint(cert.Certificate[0]),
+	}).Info("Loaded TLS certificate for client ports")
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// GenerateSelfSigned writes a self-signed ECDSA P-256 certificate valid for
+// hosts, IP addresses or DNS names, and its private key as PEM files.
+// localhost and the loopback addresses are always included.
+func GenerateSelfSigned(certFile, keyFile string, hosts []string) error {
+	log.WithField("cert_file", certFile).Debug("Generating self-signed TLS certificate")
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "go-i2p client ports"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(SELF_SIGNED_VALIDITY),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a DER certificate as
+// colon separated hex, the form browsers and openssl show.
+func Fingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = strings.ToUpper(hex.EncodeToString([]byte{b}))
+	}
+	return strings.Join(parts, ":")
+}
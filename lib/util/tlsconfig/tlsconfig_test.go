@@ -0,0 +1,47 @@
+package tlsconfig
+
+import (
+	"crypto/x509"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerConfigSelfSigned(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := func(name string) string { return filepath.Join(dir, name) }
+	tlsConfig, err := ServerConfig("", "", keyPath, []string{"192.168.1.10", "router.lan"})
+	require.NoError(t, err)
+	require.Len(t, tlsConfig.Certificates, 1)
+
+	cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+	require.NoError(t, err)
+	assert.NoError(t, cert.VerifyHostname("192.168.1.10"))
+	assert.NoError(t, cert.VerifyHostname("router.lan"))
+	assert.NoError(t, cert.VerifyHostname("localhost"))
+	assert.True(t, cert.IPAddresses[0].Equal(net.IPv4(127, 0, 0, 1)))
+
+	// the generated certificate is reused
+	again, err := ServerConfig("", "", keyPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tlsConfig.Certificates[0].Certificate, again.Certificates[0].Certificate)
+
+	provided, err := ServerConfig(filepath.Join(dir, SELF_SIGNED_CERT_FILE), filepath.Join(dir, SELF_SIGNED_KEY_FILE), nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, tlsConfig.Certificates[0].Certificate, provided.Certificates[0].Certificate)
+}
+
+func TestServerConfigErrors(t *testing.T) {
+	_, err := ServerConfig("cert.pem", "", nil, nil)
+	assert.ErrorIs(t, err, ErrIncompleteKeyPair)
+	_, err = ServerConfig("missing.crt", "missing.key", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := Fingerprint([]byte("certificate"))
+	assert.Regexp(t, `^([0-9A-F]{2}:){31}[0-9A-F]{2}$`, fingerprint)
+}
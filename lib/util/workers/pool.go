@@ -0,0 +1,148 @@
+// Package workers implements fixed-size goroutine pools which can be resized while running
+package workers
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-i2p/go-i2p/lib/util/logger"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logger.GetGoI2PLogger()
+
+var (
+	ErrPoolClosed  = errors.New("worker pool is closed")
+	ErrInvalidSize = errors.New("worker pool size must be at least 1")
+)
+
+// Pool runs submitted tasks on a set of worker goroutines.
+// The number of workers can be changed with Resize while tasks are running,
+// workers which are removed finish their current task before exiting.
+type Pool struct {
+	mutex  sync.RWMutex
+	name   string
+	tasks  chan func()
+	stops  []chan struct{}
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// NewPool creates a pool called name with size workers and room for queue
+// pending tasks.
+func NewPool(name string, size, queue int) (*Pool, error) {
+	if size < 1 {
+		return nil, ErrInvalidSize
+	}
+	if queue < 0 {
+		queue = 0
+	}
+	pool := &Pool{
+		name:  name,
+		tasks: make(chan func(), queue),
+	}
+	pool.grow(size)
+	log.WithFields(logrus.Fields{
+		"pool":  name,
+		"size":  size,
+		"queue": queue,
+	}).Debug("Created worker pool")
+	return pool, nil
+}
+
+// Name returns the name the pool was created with.
+func (pool *Pool) Name() string {
+	return pool.name
+}
+
+// Size returns the current number of workers.
+func (pool *Pool) Size() int {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+	return len(pool.stops)
+}
+
+// Queued returns the number of tasks waiting for a worker.
+func (pool *Pool) Queued() int {
+	return len(pool.tasks)
+}
+
+// Submit queues task for execution, blocking while the queue is full.
+func (pool *Pool) Submit(task func()) error {
+	pool.mutex.RLock()
+	defer pool.mutex.RUnlock()
+	if pool.closed {
+		return ErrPoolClosed
+	}
+	pool.tasks <- task
+	return nil
+}
+
+// Resize changes the number of workers to size.
+func (pool *Pool) Resize(size int) error {
+	if size < 1 {
+		return ErrInvalidSize
+	}
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	if pool.closed {
+		return ErrPoolClosed
+	}
+	current := len(pool.stops)
+	switch {
+	case size > current:
+		pool.grow(size - current)
+	case size < current:
+		for _, stop := range pool.stops[size:] {
+			close(stop)
+		}
+		pool.stops = pool.stops[:size]
+	}
+	log.WithFields(logrus.Fields{
+		"pool":     pool.name,
+		"old_size": current,
+		"new_size": size,
+	}).Debug("Resized worker pool")
+	return nil
+}
+
+// Close stops accepting tasks, waits for queued tasks to finish and stops all workers.
+func (pool *Pool) Close() error {
+	pool.mutex.Lock()
+	if pool.closed {
+		pool.mutex.Unlock()
+		return nil
+	}
+	pool.closed = true
+	close(pool.tasks)
+	pool.stops = nil
+	pool.mutex.Unlock()
+	pool.wg.Wait()
+	log.WithField("pool", pool.name).Debug("Closed worker pool")
+	return nil
+}
+
+// grow starts n workers, the caller must hold the write lock or own the pool exclusively.
+func (pool *Pool) grow(n int) {
+	for i := 0; i < n; i++ {
+		stop := make(chan struct{})
+		pool.stops = append(pool.stops, stop)
+		pool.wg.Add(1)
+		go pool.work(stop)
+	}
+}
+
+func (pool *Pool) work(stop chan struct{}) {
+	defer pool.wg.Done()
+	for {
+		select {
+		case <-stop:
+			return
+		case task, ok := <-pool.tasks:
+			if !ok {
+				return
+			}
+			task()
+		}
+	}
+}
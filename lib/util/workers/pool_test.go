@@ -0,0 +1,62 @@
+package workers
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolRunsTasks(t *testing.T) {
+	pool, err := NewPool("test", 4, 16)
+	require.NoError(t, err)
+
+	var count int32
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		require.NoError(t, pool.Submit(func() {
+			atomic.AddInt32(&count, 1)
+			wg.Done()
+		}))
+	}
+	wg.Wait()
+	assert.Equal(t, int32(100), atomic.LoadInt32(&count))
+	assert.NoError(t, pool.Close())
+}
+
+func TestPoolResizeWhileRunning(t *testing.T) {
+	pool, err := NewPool("test", 2, 0)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pool.Submit(func() {})
+			if i%10 == 0 {
+				pool.Resize(1 + i/10)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	require.NoError(t, pool.Resize(8))
+	assert.Equal(t, 8, pool.Size())
+	require.NoError(t, pool.Resize(1))
+	assert.Equal(t, 1, pool.Size())
+	assert.ErrorIs(t, pool.Resize(0), ErrInvalidSize)
+}
+
+func TestPoolClosed(t *testing.T) {
+	pool, err := NewPool("test", 1, 0)
+	require.NoError(t, err)
+	require.NoError(t, pool.Close())
+	assert.ErrorIs(t, pool.Submit(func() {}), ErrPoolClosed)
+	assert.ErrorIs(t, pool.Resize(2), ErrPoolClosed)
+	assert.NoError(t, pool.Close())
+}
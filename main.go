@@ -1,11 +1,15 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/i18n"
 	"github.com/go-i2p/go-i2p/lib/router"
+	"github.com/go-i2p/go-i2p/lib/update"
 	"github.com/go-i2p/go-i2p/lib/util/logger"
 	"github.com/go-i2p/go-i2p/lib/util/signals"
 	"github.com/spf13/cobra"
@@ -20,7 +24,7 @@ var (
 
 var RootCmd = &cobra.Command{
 	Use:   "go-i2p",
-	Short: "I2P Router implementation in Go",
+	Short: i18n.T("I2P Router implementation in Go"),
 	Run: func(cmd *cobra.Command, args []string) {
 		runRouter()
 	},
@@ -30,60 +34,264 @@ func init() {
 	cobra.OnInitialize(config.InitConfig)
 
 	// Global flags
-	RootCmd.PersistentFlags().StringVar(&config.CfgFile, "config", "", "config file (default is $HOME/.go-i2p/config.yaml)")
+	RootCmd.PersistentFlags().StringVar(&config.CfgFile, "config", "", i18n.T("config file (default is $HOME/.go-i2p/config.yaml)"))
 
 	// Router configuration flags
-	RootCmd.PersistentFlags().String("base-dir", config.DefaultRouterConfig().BaseDir, "Base directory for I2P router")
-	RootCmd.PersistentFlags().String("working-dir", config.DefaultRouterConfig().WorkingDir, "Working directory for I2P router")
+	RootCmd.PersistentFlags().String("base-dir", config.DefaultRouterConfig().BaseDir, i18n.T("Base directory for I2P router"))
+	RootCmd.PersistentFlags().String("working-dir", config.DefaultRouterConfig().WorkingDir, i18n.T("Working directory for I2P router"))
+	RootCmd.PersistentFlags().Bool("observer", config.DefaultRouterConfig().Observer, i18n.T("Run in netdb-only observer mode, without tunnels or clients"))
 
 	// NetDb flags
-	RootCmd.PersistentFlags().String("netdb.path", config.DefaultNetDbConfig.Path, "Path to the netDb")
+	RootCmd.PersistentFlags().String("netdb.path", config.DefaultNetDbConfig.Path, i18n.T("Path to the netDb"))
+	RootCmd.PersistentFlags().String("netdb.floodfill", config.DefaultNetDbConfig.Floodfill,
+		i18n.T("Floodfill mode: auto, true or false"))
+	RootCmd.PersistentFlags().String("netdb.shared-path", config.DefaultNetDbConfig.SharedPath,
+		i18n.T("Path to a netDb read but never written, for entries missing from netdb.path"))
+	RootCmd.PersistentFlags().Int("netdb.publish-floodfills", config.DefaultNetDbConfig.PublishFloodfills,
+		i18n.T("Number of floodfills closest to our LeaseSets they are stored to"))
+	RootCmd.PersistentFlags().Int("netdb.publish-quorum", config.DefaultNetDbConfig.PublishQuorum,
+		i18n.T("Number of floodfills which have to store a LeaseSet for its publication to succeed"))
 
 	// Bootstrap flags
 	RootCmd.PersistentFlags().Int("bootstrap.low-peer-threshold", config.DefaultBootstrapConfig.LowPeerThreshold,
-		"Minimum number of peers before reseeding")
+		i18n.T("Minimum number of peers before reseeding"))
+
+	// Worker pool flags
+	RootCmd.PersistentFlags().String("workers.bandwidth-class", config.DefaultWorkersConfig.BandwidthClass,
+		i18n.T("Bandwidth class used to size worker pools (K, L, M, N, O, P, X)"))
+	RootCmd.PersistentFlags().Int("workers.netdb", 0, i18n.T("Number of netdb workers (0 sizes from CPU count)"))
+	RootCmd.PersistentFlags().Int("workers.tunnel-build", 0, i18n.T("Number of tunnel build workers (0 sizes from CPU count)"))
+
+	// Shutdown report flags
+	RootCmd.PersistentFlags().Bool("report.enabled", config.DefaultReportConfig.Enabled, i18n.T("Write a JSON state snapshot on shutdown"))
+	RootCmd.PersistentFlags().String("report.endpoint", config.DefaultReportConfig.Endpoint, i18n.T("URL to POST the shutdown report to"))
+
+	// Router update flags
+	RootCmd.PersistentFlags().Bool("update.enabled", config.DefaultUpdateConfig.Enabled, i18n.T("Check for and stage signed router updates"))
+	RootCmd.PersistentFlags().String("update.url", config.DefaultUpdateConfig.URL, i18n.T("URL of the router update su3 file"))
+	RootCmd.PersistentFlags().String("update.proxy", config.DefaultUpdateConfig.Proxy, i18n.T("HTTP proxy router updates are fetched through"))
+
+	// Peer selection flags
+	RootCmd.PersistentFlags().Float64("peers.external-score-weight", config.DefaultPeerConfig.ExternalScoreWeight,
+		i18n.T("Weight of external peer scores against local profiles (0 to 1)"))
+
+	// Router version policy flags
+	RootCmd.PersistentFlags().String("versions.min", config.DefaultVersionConfig.Min,
+		i18n.T("Oldest router version the netDb stores and peer selection uses, empty for any"))
+	RootCmd.PersistentFlags().String("versions.max", config.DefaultVersionConfig.Max,
+		i18n.T("Newest router version the netDb stores and peer selection uses, empty for any"))
+	RootCmd.PersistentFlags().StringSlice("versions.blocked", config.DefaultVersionConfig.Blocked,
+		i18n.T("Router versions rejected even if they are in range"))
+	RootCmd.PersistentFlags().Bool("versions.zero-major-only", config.DefaultVersionConfig.ZeroMajorOnly,
+		i18n.T("Reject router versions which do not start with 0"))
+
+	// Client access flags
+	RootCmd.PersistentFlags().Bool("clients.auth-required", config.DefaultClientConfig.AuthRequired,
+		i18n.T("Require I2CP, SAM and management API clients to log in"))
+	RootCmd.PersistentFlags().StringSlice("clients.allowed-bind-addresses", config.DefaultClientConfig.AllowedBindAddresses,
+		i18n.T("Addresses or CIDR ranges the I2CP, SAM and management API ports may listen on (default loopback only)"))
+	RootCmd.PersistentFlags().Bool("clients.tls", config.DefaultClientConfig.TLS, i18n.T("Serve I2CP, SAM and the management API over TLS"))
+	RootCmd.PersistentFlags().String("clients.tls-cert-file", config.DefaultClientConfig.TLSCertFile,
+		i18n.T("TLS certificate for the client ports (default self-signed)"))
+	RootCmd.PersistentFlags().String("clients.tls-key-file", config.DefaultClientConfig.TLSKeyFile, i18n.T("TLS key for the client ports"))
+	RootCmd.PersistentFlags().Int("clients.inbound-limit", config.DefaultClientConfig.InboundLimit,
+		i18n.T("Inbound bandwidth limit of each client session in KBps, 0 for the router limit"))
+	RootCmd.PersistentFlags().Int("clients.outbound-limit", config.DefaultClientConfig.OutboundLimit,
+		i18n.T("Outbound bandwidth limit of each client session in KBps, 0 for the router limit"))
+
+	// Management API flags
+	RootCmd.PersistentFlags().Bool("management.enabled", config.DefaultManagementConfig.Enabled,
+		i18n.T("Serve the management API"))
+	RootCmd.PersistentFlags().String("management.address", config.DefaultManagementConfig.Address,
+		i18n.T("Address the management API listens on"))
+	RootCmd.PersistentFlags().Bool("management.debug-chaos", config.DefaultManagementConfig.DebugChaos,
+		i18n.T("Serve the fault injection debug API at /debug/chaos on the management API"))
+
+	// Memory accounting flags
+	RootCmd.PersistentFlags().Int("memory.netdb", config.DefaultMemoryConfig.NetDb,
+		i18n.T("Ceiling of the in-memory netDb in MB, 0 for none"))
+
+	// Client tunnel flags
+	RootCmd.PersistentFlags().Int("tunnels.warm-pool", config.DefaultTunnelConfig.WarmPool,
+		i18n.T("Outbound client tunnels to build at startup for new sessions, 0 for none"))
+	RootCmd.PersistentFlags().String("tunnels.lease-strategy", config.DefaultTunnelConfig.LeaseStrategy,
+		i18n.T("How leases of remote destinations are chosen: random, latency or round-robin"))
+	RootCmd.PersistentFlags().Duration("tunnels.lease-stickiness", config.DefaultTunnelConfig.LeaseStickiness,
+		i18n.T("How long a remote destination keeps its chosen lease, 0 to choose per message"))
+	RootCmd.PersistentFlags().Duration("tunnels.lease-grace", config.DefaultTunnelConfig.LeaseGrace,
+		i18n.T("How long past expiration leases are used when no unexpired lease is left, for clock skew"))
+
+	// Transport flags
+	RootCmd.PersistentFlags().Int("transport.port", config.DefaultTransportConfig.Port,
+		i18n.T("Port the transports listen on, 0 for a random port"))
+	RootCmd.PersistentFlags().Int("transport.dscp", config.DefaultTransportConfig.DSCP,
+		i18n.T("DSCP code point to mark transport packets with, 0 for none"))
+	RootCmd.PersistentFlags().Bool("transport.reuse-port", config.DefaultTransportConfig.ReusePort,
+		i18n.T("Set SO_REUSEPORT on transport sockets"))
+	RootCmd.PersistentFlags().String("transport.interface", config.DefaultTransportConfig.Interface,
+		i18n.T("Network interface to bind transport sockets to"))
+
+	// Instance flags
+	RootCmd.PersistentFlags().Int("instances.count", config.DefaultInstanceConfig.Count,
+		i18n.T("Routers to run, each in its own working directory and on its own port"))
+	RootCmd.PersistentFlags().Bool("instances.shared-netdb", config.DefaultInstanceConfig.SharedNetDb,
+		i18n.T("Share netdb.path read only between the instances"))
+
+	// Research statistics flags
+	RootCmd.PersistentFlags().Bool("research.enabled", config.DefaultResearchConfig.Enabled,
+		i18n.T("Publish signed, anonymized statistics for network research"))
+	RootCmd.PersistentFlags().String("research.collector", config.DefaultResearchConfig.Collector,
+		i18n.T("URL of the collector research statistics are posted to"))
+	RootCmd.PersistentFlags().String("research.proxy", config.DefaultResearchConfig.Proxy,
+		i18n.T("HTTP proxy research statistics are posted through"))
+
+	// Keychain flags
+	RootCmd.PersistentFlags().Bool("keychain.enabled", config.DefaultKeychainConfig.Enabled,
+		i18n.T("Seal private key files with passphrases kept in the OS keychain"))
+	RootCmd.PersistentFlags().String("keychain.service", config.DefaultKeychainConfig.Service,
+		i18n.T("Keychain service the passphrases are stored under"))
+
+	// Storage flags
+	RootCmd.PersistentFlags().Bool("storage.read-only", config.DefaultStorageConfig.ReadOnly,
+		i18n.T("Treat the working directory and netDb as read only, e.g. in an immutable container image"))
+	RootCmd.PersistentFlags().String("storage.state-dir", config.DefaultStorageConfig.StateDir,
+		i18n.T("Writable directory for state when storage is read only"))
+	RootCmd.PersistentFlags().Bool("storage.strict-permissions", config.DefaultStorageConfig.StrictPermissions,
+		i18n.T("Restrict private key files readable by other users to 0600"))
+
+	// Feature flags
+	RootCmd.PersistentFlags().StringToString("features", nil,
+		i18n.T("Feature flags to enable or disable, e.g. ssu2=true,ls2_publish=false"))
 
 	// Bind flags to viper
 	viper.BindPFlag("base_dir", RootCmd.PersistentFlags().Lookup("base-dir"))
 	viper.BindPFlag("working_dir", RootCmd.PersistentFlags().Lookup("working-dir"))
+	viper.BindPFlag("observer", RootCmd.PersistentFlags().Lookup("observer"))
 	viper.BindPFlag("netdb.path", RootCmd.PersistentFlags().Lookup("netdb.path"))
+	viper.BindPFlag("netdb.floodfill", RootCmd.PersistentFlags().Lookup("netdb.floodfill"))
+	viper.BindPFlag("netdb.shared_path", RootCmd.PersistentFlags().Lookup("netdb.shared-path"))
+	viper.BindPFlag("netdb.publish_floodfills", RootCmd.PersistentFlags().Lookup("netdb.publish-floodfills"))
+	viper.BindPFlag("netdb.publish_quorum", RootCmd.PersistentFlags().Lookup("netdb.publish-quorum"))
 	viper.BindPFlag("bootstrap.low_peer_threshold", RootCmd.PersistentFlags().Lookup("bootstrap.low-peer-threshold"))
+	viper.BindPFlag("workers.bandwidth_class", RootCmd.PersistentFlags().Lookup("workers.bandwidth-class"))
+	viper.BindPFlag("workers.netdb", RootCmd.PersistentFlags().Lookup("workers.netdb"))
+	viper.BindPFlag("workers.tunnel_build", RootCmd.PersistentFlags().Lookup("workers.tunnel-build"))
+	viper.BindPFlag("report.enabled", RootCmd.PersistentFlags().Lookup("report.enabled"))
+	viper.BindPFlag("report.endpoint", RootCmd.PersistentFlags().Lookup("report.endpoint"))
+	viper.BindPFlag("update.enabled", RootCmd.PersistentFlags().Lookup("update.enabled"))
+	viper.BindPFlag("update.url", RootCmd.PersistentFlags().Lookup("update.url"))
+	viper.BindPFlag("update.proxy", RootCmd.PersistentFlags().Lookup("update.proxy"))
+	viper.BindPFlag("peers.external_score_weight", RootCmd.PersistentFlags().Lookup("peers.external-score-weight"))
+	viper.BindPFlag("versions.min", RootCmd.PersistentFlags().Lookup("versions.min"))
+	viper.BindPFlag("versions.max", RootCmd.PersistentFlags().Lookup("versions.max"))
+	viper.BindPFlag("versions.blocked", RootCmd.PersistentFlags().Lookup("versions.blocked"))
+	viper.BindPFlag("versions.zero_major_only", RootCmd.PersistentFlags().Lookup("versions.zero-major-only"))
+	viper.BindPFlag("clients.auth_required", RootCmd.PersistentFlags().Lookup("clients.auth-required"))
+	viper.BindPFlag("clients.allowed_bind_addresses", RootCmd.PersistentFlags().Lookup("clients.allowed-bind-addresses"))
+	viper.BindPFlag("clients.tls", RootCmd.PersistentFlags().Lookup("clients.tls"))
+	viper.BindPFlag("clients.tls_cert_file", RootCmd.PersistentFlags().Lookup("clients.tls-cert-file"))
+	viper.BindPFlag("clients.tls_key_file", RootCmd.PersistentFlags().Lookup("clients.tls-key-file"))
+	viper.BindPFlag("clients.inbound_limit", RootCmd.PersistentFlags().Lookup("clients.inbound-limit"))
+	viper.BindPFlag("clients.outbound_limit", RootCmd.PersistentFlags().Lookup("clients.outbound-limit"))
+	viper.BindPFlag("management.enabled", RootCmd.PersistentFlags().Lookup("management.enabled"))
+	viper.BindPFlag("management.address", RootCmd.PersistentFlags().Lookup("management.address"))
+	viper.BindPFlag("management.debug_chaos", RootCmd.PersistentFlags().Lookup("management.debug-chaos"))
+	viper.BindPFlag("memory.netdb", RootCmd.PersistentFlags().Lookup("memory.netdb"))
+	viper.BindPFlag("tunnels.warm_pool", RootCmd.PersistentFlags().Lookup("tunnels.warm-pool"))
+	viper.BindPFlag("tunnels.lease_strategy", RootCmd.PersistentFlags().Lookup("tunnels.lease-strategy"))
+	viper.BindPFlag("tunnels.lease_stickiness", RootCmd.PersistentFlags().Lookup("tunnels.lease-stickiness"))
+	viper.BindPFlag("tunnels.lease_grace", RootCmd.PersistentFlags().Lookup("tunnels.lease-grace"))
+	viper.BindPFlag("transport.port", RootCmd.PersistentFlags().Lookup("transport.port"))
+	viper.BindPFlag("transport.dscp", RootCmd.PersistentFlags().Lookup("transport.dscp"))
+	viper.BindPFlag("transport.reuse_port", RootCmd.PersistentFlags().Lookup("transport.reuse-port"))
+	viper.BindPFlag("transport.interface", RootCmd.PersistentFlags().Lookup("transport.interface"))
+	viper.BindPFlag("instances.count", RootCmd.PersistentFlags().Lookup("instances.count"))
+	viper.BindPFlag("instances.shared_netdb", RootCmd.PersistentFlags().Lookup("instances.shared-netdb"))
+	viper.BindPFlag("research.enabled", RootCmd.PersistentFlags().Lookup("research.enabled"))
+	viper.BindPFlag("research.collector", RootCmd.PersistentFlags().Lookup("research.collector"))
+	viper.BindPFlag("research.proxy", RootCmd.PersistentFlags().Lookup("research.proxy"))
+	viper.BindPFlag("keychain.enabled", RootCmd.PersistentFlags().Lookup("keychain.enabled"))
+	viper.BindPFlag("keychain.service", RootCmd.PersistentFlags().Lookup("keychain.service"))
+	viper.BindPFlag("storage.read_only", RootCmd.PersistentFlags().Lookup("storage.read-only"))
+	viper.BindPFlag("storage.state_dir", RootCmd.PersistentFlags().Lookup("storage.state-dir"))
+	viper.BindPFlag("storage.strict_permissions", RootCmd.PersistentFlags().Lookup("storage.strict-permissions"))
+	viper.BindPFlag("features", RootCmd.PersistentFlags().Lookup("features"))
 }
 
 // configCmd shows current configuration
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Show current configuration",
+	Short: i18n.T("Show current configuration"),
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Configuration file: %s\n", viper.ConfigFileUsed())
-		fmt.Printf("\nRouter Configuration:")
-		fmt.Printf("  Base Directory: %s", config.RouterConfigProperties.BaseDir)
-		fmt.Printf("  Working Directory: %s", config.RouterConfigProperties.WorkingDir)
+		fmt.Println(i18n.T("Configuration file: %s", viper.ConfigFileUsed()))
+		fmt.Printf("\n%s\n", i18n.T("Router Configuration:"))
+		fmt.Printf("  %s\n", i18n.T("Base Directory: %s", config.RouterConfigProperties.BaseDir))
+		fmt.Printf("  %s\n", i18n.T("Working Directory: %s", config.RouterConfigProperties.WorkingDir))
 
-		fmt.Printf("\nNetDb Configuration:")
-		fmt.Printf("  Path: %s", config.RouterConfigProperties.NetDb.Path)
+		fmt.Printf("\n%s\n", i18n.T("NetDb Configuration:"))
+		fmt.Printf("  %s\n", i18n.T("Path: %s", config.RouterConfigProperties.NetDb.Path))
 
-		fmt.Printf("\nBootstrap Configuration:")
-		fmt.Printf("  Low Peer Threshold: %d", config.RouterConfigProperties.Bootstrap.LowPeerThreshold)
-		fmt.Printf("  Reseed Servers:")
+		fmt.Printf("\n%s\n", i18n.T("Worker Configuration:"))
+		fmt.Printf("  %s\n", i18n.T("Bandwidth Class: %s", config.RouterConfigProperties.Workers.BandwidthClass))
+		fmt.Printf("  %s\n", i18n.T("NetDb: %d", config.RouterConfigProperties.Workers.NetDb))
+		fmt.Printf("  %s\n", i18n.T("Tunnel Build: %d", config.RouterConfigProperties.Workers.TunnelBuild))
+
+		fmt.Printf("\n%s\n", i18n.T("Update Configuration:"))
+		fmt.Printf("  %s\n", i18n.T("Enabled: %t", config.RouterConfigProperties.Update.Enabled))
+		fmt.Printf("  %s\n", i18n.T("URL: %s", config.RouterConfigProperties.Update.URL))
+		fmt.Printf("  %s\n", i18n.T("Proxy: %s", config.RouterConfigProperties.Update.Proxy))
+
+		fmt.Printf("\n%s\n", i18n.T("Bootstrap Configuration:"))
+		fmt.Printf("  %s\n", i18n.T("Low Peer Threshold: %d", config.RouterConfigProperties.Bootstrap.LowPeerThreshold))
+		fmt.Printf("  %s\n", i18n.T("Reseed Servers:"))
 		for _, server := range config.RouterConfigProperties.Bootstrap.ReseedServers {
-			fmt.Printf("    - URL: %s", server.Url)
-			fmt.Printf("      SU3 Fingerprint: %s", server.SU3Fingerprint)
+			fmt.Printf("    - %s\n", i18n.T("URL: %s", server.Url))
+			fmt.Printf("      %s\n", i18n.T("SU3 Fingerprint: %s", server.SU3Fingerprint))
 		}
 	},
 }
 
 func debugPrintConfig() {
 	currentConfig := struct {
-		BaseDir    string                 `yaml:"base_dir"`
-		WorkingDir string                 `yaml:"working_dir"`
-		NetDB      config.NetDbConfig     `yaml:"netdb"`
-		Bootstrap  config.BootstrapConfig `yaml:"bootstrap"`
+		BaseDir    string                  `yaml:"base_dir"`
+		WorkingDir string                  `yaml:"working_dir"`
+		NetDB      config.NetDbConfig      `yaml:"netdb"`
+		Bootstrap  config.BootstrapConfig  `yaml:"bootstrap"`
+		Workers    config.WorkerConfig     `yaml:"workers"`
+		Report     config.ReportConfig     `yaml:"report"`
+		Update     config.UpdateConfig     `yaml:"update"`
+		Peers      config.PeerConfig       `yaml:"peers"`
+		Versions   config.VersionConfig    `yaml:"versions"`
+		Clients    config.ClientConfig     `yaml:"clients"`
+		Management config.ManagementConfig `yaml:"management"`
+		Memory     config.MemoryConfig     `yaml:"memory"`
+		Tunnels    config.TunnelConfig     `yaml:"tunnels"`
+		Transport  config.TransportConfig  `yaml:"transport"`
+		Instances  config.InstanceConfig   `yaml:"instances"`
+		Research   config.ResearchConfig   `yaml:"research"`
+		Keychain   config.KeychainConfig   `yaml:"keychain"`
+		Storage    config.StorageConfig    `yaml:"storage"`
+		Features   map[string]bool         `yaml:"features"`
 	}{
 		BaseDir:    config.RouterConfigProperties.BaseDir,
 		WorkingDir: config.RouterConfigProperties.WorkingDir,
 		NetDB:      *config.RouterConfigProperties.NetDb,
 		Bootstrap:  *config.RouterConfigProperties.Bootstrap,
+		Workers:    *config.RouterConfigProperties.Workers,
+		Report:     *config.RouterConfigProperties.Report,
+		Update:     *config.RouterConfigProperties.Update,
+		Peers:      *config.RouterConfigProperties.Peers,
+		Versions:   *config.RouterConfigProperties.Versions,
+		Clients:    *config.RouterConfigProperties.Clients,
+		Management: *config.RouterConfigProperties.Management,
+		Memory:     *config.RouterConfigProperties.Memory,
+		Tunnels:    *config.RouterConfigProperties.Tunnels,
+		Transport:  *config.RouterConfigProperties.Transport,
+		Instances:  *config.RouterConfigProperties.Instances,
+		Research:   *config.RouterConfigProperties.Research,
+		Keychain:   *config.RouterConfigProperties.Keychain,
+		Storage:    *config.RouterConfigProperties.Storage,
+		Features:   config.RouterConfigProperties.Features,
 	}
 
 	yamlData, err := yaml.Marshal(currentConfig)
@@ -102,7 +310,21 @@ func runRouter() {
 	log.Debug("using netDb in:", config.RouterConfigProperties.NetDb.Path)
 	log.Debug("starting up i2p router")
 
-	var err error
+	executable, err := os.Executable()
+	if err != nil {
+		log.Errorf("failed to find router executable: %s", err)
+	} else if err := update.CheckPending(executable); errors.Is(err, update.ErrRolledBack) {
+		log.Error(err)
+		os.Exit(1)
+	} else if err != nil {
+		log.Errorf("failed to check pending router update: %s", err)
+	}
+
+	if config.RouterConfigProperties.Instances.Count > 1 {
+		runInstances()
+		return
+	}
+
 	routerInstance, err = router.CreateRouter(config.RouterConfigProperties)
 	if err == nil {
 		signals.RegisterReloadHandler(func() {
@@ -111,6 +333,9 @@ func runRouter() {
 				return
 			}
 			config.UpdateRouterConfig()
+			if err := routerInstance.ApplyWorkerConfig(*config.RouterConfigProperties.Workers); err != nil {
+				log.Errorf("failed to resize worker pools: %s", err)
+			}
 		})
 
 		signals.RegisterInterruptHandler(func() {
@@ -120,15 +345,67 @@ func runRouter() {
 		})
 
 		routerInstance.Start()
+		var confirm *time.Timer
+		if executable != "" {
+			confirm = time.AfterFunc(update.ConfirmDelay, func() {
+				if err := update.ConfirmStart(executable); err != nil {
+					log.Errorf("failed to confirm router update: %s", err)
+				}
+			})
+		}
 		routerInstance.Wait()
 		routerInstance.Close()
+		if executable != "" {
+			// a clean shutdown before the update was confirmed is not a
+			// failed start, the next start gets another chance to confirm it
+			if confirm.Stop() {
+				if err := update.ResetPending(executable); err != nil {
+					log.Errorf("failed to reset pending router update: %s", err)
+				}
+			}
+			if applied, err := update.ApplyStaged(executable); err != nil {
+				log.Errorf("failed to apply router update: %s", err)
+			} else if applied {
+				log.Info("router update installed, restart to run the new version")
+			}
+		}
 	} else {
 		log.Errorf("failed to create i2p router: %s", err)
 	}
 }
 
+// runInstances runs the configured router instances until interrupted. Staged
+// updates are left for a single router run to confirm and apply.
+func runInstances() {
+	instances, err := router.CreateInstances(config.RouterConfigProperties)
+	if err != nil {
+		log.Errorf("failed to create i2p router instances: %s", err)
+		return
+	}
+	signals.RegisterReloadHandler(func() {
+		if err := viper.ReadInConfig(); err != nil {
+			log.Errorf("failed to reload config: %s", err)
+			return
+		}
+		config.UpdateRouterConfig()
+		if err := instances.ApplyWorkerConfig(*config.RouterConfigProperties.Workers); err != nil {
+			log.Errorf("failed to resize worker pools: %s", err)
+		}
+	})
+	signals.RegisterInterruptHandler(instances.Stop)
+
+	log.Infof("starting %d i2p router instances", len(instances))
+	instances.Start()
+	instances.Wait()
+	if err := instances.Close(); err != nil {
+		log.Errorf("failed to close i2p router instances: %s", err)
+	}
+}
+
 func main() {
 	RootCmd.AddCommand(configCmd)
+	RootCmd.AddCommand(netdbCmd)
+	RootCmd.AddCommand(supportBundleCmd)
 	if err := RootCmd.Execute(); err != nil {
 		log.Error(err)
 		debugPrintConfig()
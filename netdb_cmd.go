@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/i18n"
+	"github.com/go-i2p/go-i2p/lib/netdb"
+	"github.com/spf13/cobra"
+)
+
+// netdbCmd groups the offline netDb maintenance commands
+var netdbCmd = &cobra.Command{
+	Use:   "netdb",
+	Short: i18n.T("Manage the local netDb"),
+}
+
+// netdbExportCmd writes the netDb to a snapshot archive
+var netdbExportCmd = &cobra.Command{
+	Use:   "export <file.tar.zst>",
+	Short: i18n.T("Export the netDb to a tar.zst snapshot"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		maxAge, err := cmd.Flags().GetDuration("max-age")
+		if err != nil {
+			return err
+		}
+		caps, err := cmd.Flags().GetString("caps")
+		if err != nil {
+			return err
+		}
+		f, err := os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		db := netdb.NewStdNetDB(config.RouterConfigProperties.NetDb.Path)
+		count, err := db.ExportSnapshot(f, netdb.SnapshotFilter{MaxAge: maxAge, Caps: caps})
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(args[0])
+			return err
+		}
+		fmt.Println(i18n.T("Exported %d router infos to %s", count, args[0]))
+		return nil
+	},
+}
+
+// netdbImportCmd stores the RouterInfos of a snapshot archive in the netDb
+var netdbImportCmd = &cobra.Command{
+	Use:   "import <file.tar.zst>",
+	Short: i18n.T("Import a tar.zst snapshot into the netDb"),
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		db := netdb.NewStdNetDB(config.RouterConfigProperties.NetDb.Path)
+		count, err := db.ImportSnapshot(f)
+		if err != nil {
+			return err
+		}
+		fmt.Println(i18n.T("Imported %d router infos into %s", count, db.Path()))
+		return nil
+	},
+}
+
+func init() {
+	netdbExportCmd.Flags().Duration("max-age", 0, i18n.T("Only export router infos published within this long (0 exports all)"))
+	netdbExportCmd.Flags().String("caps", "", i18n.T("Only export router infos advertising all of these capabilities"))
+	netdbCmd.AddCommand(netdbExportCmd, netdbImportCmd)
+}
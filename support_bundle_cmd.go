@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-i2p/go-i2p/lib/config"
+	"github.com/go-i2p/go-i2p/lib/i18n"
+	"github.com/go-i2p/go-i2p/lib/router"
+	"github.com/spf13/cobra"
+)
+
+// supportBundleCmd writes a support bundle of the router's last run
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle [file.tar.gz]",
+	Short: i18n.T("Write a sanitized support bundle to attach to bug reports"),
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		bundle, err := router.OfflineSupportBundle(config.RouterConfigProperties)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("go-i2p-support-%s.tar.gz", bundle.Generated.UTC().Format("20060102-150405"))
+		if len(args) > 0 {
+			name = args[0]
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		err = bundle.Write(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(name)
+			return err
+		}
+		fmt.Println(i18n.T("Wrote support bundle to %s", name))
+		return nil
+	},
+}